@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/bridge"
+	"github.com/user/opencode-telegram/internal/config"
+	"github.com/user/opencode-telegram/internal/events"
+	"github.com/user/opencode-telegram/internal/features"
+	"github.com/user/opencode-telegram/internal/health"
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// botOptions are the settings every bot instance shares: runBridge reads
+// them from the environment once and adds the shared servers, event bus,
+// outbox, audit log and health monitor
+type botOptions struct {
+	servers       []bridge.Server
+	bus           *events.Bus
+	outbox        *state.Outbox
+	auditLog      *state.AuditLog
+	healthMonitor *health.HealthMonitor
+
+	stateBackend string
+	proxyURL     string // default Telegram proxy of accounts without their own
+	webhookURL   string
+	webhookPort  string
+	sendInterval time.Duration
+	entryTTL     time.Duration
+	feats        features.Set
+
+	quickKeyboard     bool
+	language          i18n.Lang
+	transcriber       bridge.Transcriber
+	frameExtractor    bridge.FrameExtractor
+	successReaction   string
+	failureReaction   string
+	notifyPolicy      bridge.NotificationPolicy
+	deletePlaceholder bool
+	perUserSessions   bool
+	showMore          bool
+	responseActions   bool
+	sessionBanner     bool
+	showReasoning     bool
+	photoPrompt       string
+	feedbackChatID    int64
+}
+
+// botSpec is what a bot instance is started with. When the configuration is
+// reloaded, instances whose spec changed are restarted and the others keep
+// running.
+type botSpec struct {
+	account       config.AccountConfig
+	fallback      bool // gets the events of sessions no chat tracks
+	offsetFile    string
+	stateFile     string
+	webhookSecret string
+}
+
+// debounce returns the account's debounce window, or def when it has none
+func (spec botSpec) debounce(def time.Duration) time.Duration {
+	if spec.account.DebounceMs > 0 {
+		return time.Duration(spec.account.DebounceMs) * time.Millisecond
+	}
+	return def
+}
+
+// botSpecs returns the spec of each account's bot
+func botSpecs(accounts []config.AccountConfig, offsetFile, stateFile, webhookSecret string) []botSpec {
+	specs := make([]botSpec, len(accounts))
+	for i, acc := range accounts {
+		specs[i] = botSpec{
+			account:       acc,
+			fallback:      i == 0,
+			offsetFile:    offsetFile,
+			stateFile:     stateFile,
+			webhookSecret: webhookSecret,
+		}
+		// With several accounts each bot keeps its own session state and
+		// update offset
+		if len(accounts) > 1 {
+			specs[i].offsetFile, specs[i].stateFile = acc.FileFor(offsetFile), acc.FileFor(stateFile)
+		}
+	}
+	return specs
+}
+
+// adoptSharedFiles carries the shared state and offset files over to the
+// first account's own files, once accounts are added to a single-bot setup,
+// so it keeps its sessions and does not replay updates. Files it already has
+// are kept; the shared ones are left in place for going back to one account.
+func (spec botSpec) adoptSharedFiles(offsetFile, stateFile string) {
+	if !spec.fallback {
+		return
+	}
+	for _, f := range []struct{ shared, own string }{{stateFile, spec.stateFile}, {offsetFile, spec.offsetFile}} {
+		if f.own == f.shared {
+			continue
+		}
+		adopted, err := state.AdoptFile(f.shared, f.own)
+		if err != nil {
+			logger.Warn("Cannot adopt the shared file", "file", f.shared, "error", err)
+		} else if adopted {
+			logger.Info("Adopted the shared file", "file", f.shared, "as", f.own)
+		}
+	}
+}
+
+// botInstance is a running bot and its bridge
+type botInstance struct {
+	spec        botSpec
+	bridge      *bridge.Bridge
+	stop        context.CancelFunc
+	stopUpdates context.CancelFunc
+	done        <-chan struct{} // closed once the bot stopped and saved its state
+}
+
+// needsRestart reports whether the bot must restart to run with spec; a new
+// debounce window is applied while it runs
+func (inst *botInstance) needsRestart(spec botSpec) bool {
+	running := inst.spec
+	running.account.DebounceMs, spec.account.DebounceMs = 0, 0
+	return running != spec
+}
+
+// shutdown stops the bot's updates, hands its in-flight prompts over to
+// OpenCode and stops the rest of it
+func (inst *botInstance) shutdown(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	inst.stopUpdates()
+	if err := inst.bridge.Drain(ctx); err != nil {
+		logger.Warn("Draining bridge", "chat", inst.spec.account.ChatID, "error", err)
+	}
+	inst.stop()
+	select {
+	case <-inst.done:
+	case <-ctx.Done():
+	}
+}
+
+// botSet is the running bot instances, by account token
+type botSet struct {
+	mu        sync.Mutex
+	instances map[string]*botInstance
+	trackers  *sessionTrackers
+
+	// start runs a bot; idx is the account's position in the configuration
+	start func(idx int, spec botSpec, debounce time.Duration) *botInstance
+}
+
+func newBotSet(trackers *sessionTrackers, start func(idx int, spec botSpec, debounce time.Duration) *botInstance) *botSet {
+	return &botSet{instances: make(map[string]*botInstance), trackers: trackers, start: start}
+}
+
+// run starts a bot and adds it to the set
+func (s *botSet) run(idx int, spec botSpec, debounce time.Duration) {
+	inst := s.start(idx, spec, debounce)
+	s.trackers.add(inst.bridge)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instances[spec.account.Token] = inst
+}
+
+// reload brings the running bots in line with specs: bots of new accounts
+// start, those of removed accounts stop, and those whose spec changed
+// restart. The rest keep running, with the new default debounce window.
+func (s *botSet) reload(specs []botSpec, debounce, drainTimeout time.Duration) {
+	wanted := make(map[string]botSpec, len(specs))
+	for _, spec := range specs {
+		wanted[spec.account.Token] = spec
+	}
+
+	// Stop first: a restarted bot must not poll while its old instance does
+	s.mu.Lock()
+	var stale []*botInstance
+	for token, inst := range s.instances {
+		if spec, ok := wanted[token]; !ok || inst.needsRestart(spec) {
+			stale = append(stale, inst)
+			delete(s.instances, token)
+		}
+	}
+	s.mu.Unlock()
+	for _, inst := range stale {
+		if _, ok := wanted[inst.spec.account.Token]; ok {
+			logger.Info("Restarting bot: its configuration changed", "chat", inst.spec.account.ChatID)
+		} else {
+			logger.Info("Stopping bot: account removed", "chat", inst.spec.account.ChatID)
+		}
+		s.trackers.remove(inst.bridge)
+		inst.shutdown(drainTimeout)
+	}
+
+	for idx, spec := range specs {
+		s.mu.Lock()
+		inst, running := s.instances[spec.account.Token]
+		if running {
+			inst.spec = spec
+		}
+		s.mu.Unlock()
+		if running {
+			inst.bridge.SetDebounce(spec.debounce(debounce))
+			continue
+		}
+		s.run(idx, spec, debounce)
+	}
+}
+
+// wait waits until every bot stopped receiving updates, up to timeout
+func (s *botSet) wait(timeout time.Duration) bool {
+	s.mu.Lock()
+	instances := make([]*botInstance, 0, len(s.instances))
+	for _, inst := range s.instances {
+		instances = append(instances, inst)
+	}
+	s.mu.Unlock()
+
+	deadline := time.After(timeout)
+	for _, inst := range instances {
+		select {
+		case <-inst.done:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/buildinfo"
+	"github.com/user/opencode-telegram/internal/config"
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// cliTimeout bounds the requests of one-off commands
+const cliTimeout = 30 * time.Second
+
+// command is a subcommand of the CLI. setup adds the command's own flags
+// and returns what runs it, once the flags are parsed.
+type command struct {
+	name    string
+	args    string
+	summary string
+	setup   func(fs *flag.FlagSet) func(args []string) error
+}
+
+var commands = []command{
+	{
+		name:    "run",
+		summary: "Run the bridge (the default command)",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			return func(args []string) error {
+				runBridge()
+				return nil
+			}
+		},
+	},
+	{
+		name:    "validate",
+		summary: "Check the configuration and connectivity without starting the bots",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			return func(args []string) error { return validateConfig(os.Stdout) }
+		},
+	},
+	{
+		name:    "send",
+		args:    "<text>",
+		summary: "Send a message to an account's chat (the text is read from stdin when not given)",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			account := fs.String("account", "", "Name of the account sending the message (default: the first one)")
+			return func(args []string) error { return sendMessage(*account, args) }
+		},
+	},
+	{
+		name:    "sessions",
+		summary: "List the sessions of each OpenCode server",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			return func(args []string) error { return listSessions(os.Stdout) }
+		},
+	},
+	{
+		name:    "encrypt",
+		args:    "[file]",
+		summary: "Encrypt a file with STATE_ENCRYPTION_KEY, by default the credentials file read on SIGHUP",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			return func(args []string) error { return encryptFile(args) }
+		},
+	},
+	{
+		name:    "decrypt",
+		args:    "[file]",
+		summary: "Print a file encrypted with STATE_ENCRYPTION_KEY, by default the credentials file",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			return func(args []string) error { return decryptFile(os.Stdout, args) }
+		},
+	},
+	{
+		name:    "version",
+		summary: "Print the version",
+		setup: func(fs *flag.FlagSet) func(args []string) error {
+			return func(args []string) error {
+				fmt.Printf("opencode-telegram %s\n", buildinfo.Get())
+				return nil
+			}
+		},
+	},
+}
+
+func main() {
+	err := execute(os.Args[1:])
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// errUsage reports a command line that could not be parsed; the usage has
+// been printed
+var errUsage = errors.New("invalid usage")
+
+// execute runs the command named by the first argument, "run" when there
+// is none or it is a flag
+func execute(args []string) error {
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage(os.Stdout)
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		fs.SetOutput(os.Stderr)
+		run := cmd.setup(fs)
+		addEnvFlags(fs)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: opencode-telegram %s [flags] %s\n\n%s.\n\n", cmd.name, cmd.args, cmd.summary)
+			fmt.Fprintln(fs.Output(), "Flags (each setting flag overrides its environment variable):")
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return errUsage
+		}
+		// Secrets given as files, e.g. TELEGRAM_BOT_TOKEN_FILE
+		if _, err := config.LoadSecretFiles(); err != nil {
+			return fmt.Errorf("read secret files: %w", err)
+		}
+		return run(fs.Args())
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	return errUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: opencode-telegram [command] [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun \"opencode-telegram <command> --help\" for the flags of a command.")
+}
+
+// sendMessage sends text to the chat of the named account
+func sendMessage(accountName string, args []string) error {
+	text := strings.Join(args, " ")
+	if text == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("read stdin: %w", err)
+		}
+		text = string(data)
+	}
+	if text = strings.TrimSpace(text); text == "" {
+		return fmt.Errorf("nothing to send")
+	}
+
+	accounts, err := config.ParseAccountConfigs()
+	if err != nil {
+		return fmt.Errorf("invalid accounts: %w", err)
+	}
+	if len(accounts) == 0 {
+		return fmt.Errorf("no bot accounts configured")
+	}
+	account := accounts[0]
+	if accountName != "" {
+		found := false
+		for _, acc := range accounts {
+			if acc.Name == accountName {
+				account, found = acc, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no account named %q", accountName)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+	client, err := telegramClient(telegramProxy(account, os.Getenv("TELEGRAM_PROXY")))
+	if err != nil {
+		return fmt.Errorf("invalid proxy: %w", err)
+	}
+	tgBot := telegram.NewBotWithClient(account.Token, account.ChatID, 0, client)
+	if _, err := tgBot.SendMessage(ctx, html.EscapeString(text)); err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+	return nil
+}
+
+// encryptFile encrypts the named file, or the credentials file, in place
+func encryptFile(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("encrypt takes one file")
+	}
+	file := credentialsFile()
+	if len(args) == 1 {
+		file = args[0]
+	}
+	if os.Getenv("STATE_ENCRYPTION_KEY") == "" && os.Getenv("STATE_ENCRYPTION_KEY_FILE") == "" {
+		return fmt.Errorf("set STATE_ENCRYPTION_KEY or STATE_ENCRYPTION_KEY_FILE")
+	}
+	if err := setupEncryption(os.Getenv("STATE_ENCRYPTION_KEY"), os.Getenv("STATE_ENCRYPTION_KEY_FILE")); err != nil {
+		return fmt.Errorf("STATE_ENCRYPTION_KEY/STATE_ENCRYPTION_KEY_FILE: %w", err)
+	}
+	if err := state.EncryptFile(file); err != nil {
+		return err
+	}
+	fmt.Printf("Encrypted %s\n", file)
+	return nil
+}
+
+// decryptFile writes the plaintext of file to w, leaving the file encrypted.
+// A plaintext file is printed as is.
+func decryptFile(w io.Writer, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("decrypt takes one file")
+	}
+	file := credentialsFile()
+	if len(args) == 1 {
+		file = args[0]
+	}
+	if os.Getenv("STATE_ENCRYPTION_KEY") == "" && os.Getenv("STATE_ENCRYPTION_KEY_FILE") == "" {
+		return fmt.Errorf("set STATE_ENCRYPTION_KEY or STATE_ENCRYPTION_KEY_FILE")
+	}
+	if err := setupEncryption(os.Getenv("STATE_ENCRYPTION_KEY"), os.Getenv("STATE_ENCRYPTION_KEY_FILE")); err != nil {
+		return fmt.Errorf("STATE_ENCRYPTION_KEY/STATE_ENCRYPTION_KEY_FILE: %w", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	plain, err := state.Decrypt(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	_, err = w.Write(plain)
+	return err
+}
+
+// listSessions prints the sessions of each OpenCode server
+func listSessions(w io.Writer) error {
+	servers, err := openCodeServers()
+	if err != nil {
+		return err
+	}
+
+	transport, err := proxyTransport(openCodeProxy())
+	if err != nil {
+		return fmt.Errorf("invalid OpenCode proxy: %w", err)
+	}
+	if transport == nil {
+		transport = opencode.NewTransport()
+	}
+	tlsFiles := opencode.TLSFiles{
+		CAFile:   os.Getenv("OPENCODE_CA_FILE"),
+		CertFile: os.Getenv("OPENCODE_CLIENT_CERT"),
+		KeyFile:  os.Getenv("OPENCODE_CLIENT_KEY"),
+	}
+	if tlsFiles.Enabled() {
+		if transport.TLSClientConfig, err = opencode.NewTLSConfig(tlsFiles); err != nil {
+			return fmt.Errorf("load OpenCode TLS files: %w", err)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	for _, srv := range servers {
+		client := opencode.NewClientWithTransport(opencode.Config{
+			BaseURL:   srv.BaseURL,
+			Directory: srv.Directory,
+			APIKey:    srv.APIKey,
+		}, transport)
+		sessions, err := client.ListSessions()
+		if err != nil {
+			return fmt.Errorf("list sessions on %s: %w", srv.Name, err)
+		}
+		if len(servers) > 1 {
+			fmt.Fprintf(tw, "# %s (%s)\n", srv.Name, srv.BaseURL)
+		}
+		for _, sess := range sessions {
+			updated := time.UnixMilli(sess.Time.Updated).Format("2006-01-02 15:04")
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", sess.ID, updated, sess.Title)
+		}
+	}
+	return nil
+}
+
+// openCodeServers returns OPENCODE_SERVERS, or the single server of
+// OPENCODE_BASE_URL, with the shared directory and API key filled in
+func openCodeServers() ([]config.ServerConfig, error) {
+	servers, err := config.ParseServerConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("invalid OPENCODE_SERVERS: %w", err)
+	}
+	if len(servers) == 0 {
+		servers = []config.ServerConfig{{Name: "default", BaseURL: getenv("OPENCODE_BASE_URL", "http://localhost:54321")}}
+	}
+	for i := range servers {
+		if servers[i].Directory == "" {
+			servers[i].Directory = getenv("OPENCODE_DIRECTORY", ".")
+		}
+		if servers[i].APIKey == "" {
+			servers[i].APIKey = os.Getenv("OPENCODE_API_KEY")
+		}
+	}
+	return servers, nil
+}
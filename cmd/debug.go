@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/bridge"
+)
+
+// debugHandler serves net/http/pprof under /debug/pprof/ and a snapshot of
+// the bridges under /debug/state, to callers with the DEBUG_TOKEN bearer
+// token. The token is read on every request, so a rotated one applies at
+// once.
+func debugHandler(bridges func() []*bridge.Bridge) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/state", func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		states := []bridge.DebugState{}
+		for _, b := range bridges() {
+			states = append(states, b.DebugState())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"goroutines":   runtime.NumGoroutine(),
+			"heap_alloc":   mem.HeapAlloc,
+			"heap_objects": mem.HeapObjects,
+			"sys":          mem.Sys,
+			"gc_cycles":    mem.NumGC,
+			"bridges":      states,
+		})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := os.Getenv("DEBUG_TOKEN")
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if want == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/config"
+)
+
+// envSetting is an environment variable the bridge reads. Every command
+// takes it as a flag too, e.g. --telegram-debounce-ms for
+// TELEGRAM_DEBOUNCE_MS, which overrides the environment.
+type envSetting struct {
+	name    string
+	usage   string
+	boolean bool
+}
+
+var envSettings = []envSetting{
+	// Telegram
+	{name: "TELEGRAM_BOT_TOKEN", usage: "Bot token of the single account"},
+	{name: "TELEGRAM_CHAT_ID", usage: "Chat ID of the single account"},
+	{name: "TELEGRAM_ACCOUNTS", usage: "JSON list of accounts ({token, chat_id, name, directory, agent, debounce_ms, webhook_port, proxy}), instead of the single account"},
+	{name: "TELEGRAM_DEBOUNCE_MS", usage: "Window merging consecutive messages into one prompt"},
+	{name: "TELEGRAM_SEND_INTERVAL_MS", usage: "Minimum spacing between sends/edits per chat"},
+	{name: "TELEGRAM_OFFSET_FILE", usage: "Update offset file"},
+	{name: "TELEGRAM_STATE_FILE", usage: "Session state file"},
+	{name: "TELEGRAM_OUTBOX_FILE", usage: "Queue of messages whose sending failed"},
+	{name: "TELEGRAM_PROXY", usage: "HTTP/SOCKS5 proxy of Telegram requests and file downloads"},
+	{name: "TELEGRAM_WEBHOOK_URL", usage: "Receive updates through this webhook instead of polling"},
+	{name: "TELEGRAM_WEBHOOK_PORT", usage: "Port of the Telegram webhook"},
+	{name: "TELEGRAM_WEBHOOK_SECRET", usage: "Secret token of the Telegram webhook"},
+	{name: "TELEGRAM_QUICK_KEYBOARD", usage: "Show the quick action keyboard", boolean: true},
+	{name: "TELEGRAM_LANGUAGE", usage: "Default bot language (en, zh)"},
+	{name: "TELEGRAM_NOTIFY", usage: "Notification policy (all, final)"},
+	{name: "TELEGRAM_DELETE_PLACEHOLDER", usage: "Send final responses as new messages", boolean: true},
+	{name: "TELEGRAM_PER_USER_SESSIONS", usage: "Give each user of a group chat their own session", boolean: true},
+	{name: "TELEGRAM_SHOW_MORE", usage: "Paginate long responses", boolean: true},
+	{name: "TELEGRAM_SHOW_REASONING", usage: "Show the model's reasoning collapsed", boolean: true},
+	{name: "TELEGRAM_PHOTO_PROMPT", usage: "Prompt sent with photos that have no caption"},
+	{name: "TELEGRAM_FEEDBACK_CHAT_ID", usage: "Chat receiving response feedback"},
+	{name: "TELEGRAM_ALERT_CHAT_ID", usage: "Admin chat alerted when the bridge turns unhealthy or degraded"},
+	{name: "ALERT_AFTER_SEC", usage: "How long a health problem lasts before it is alerted"},
+	{name: "TELEGRAM_RESPONSE_ACTIONS", usage: "Add action buttons under responses", boolean: true},
+	{name: "TELEGRAM_SESSION_BANNER", usage: "Pin a banner with the current session", boolean: true},
+	{name: "TELEGRAM_COMPLETION_REACTIONS", usage: "React to the prompt when a response completes", boolean: true},
+	{name: "TELEGRAM_REACTION_SUCCESS", usage: "Reaction for completed responses"},
+	{name: "TELEGRAM_REACTION_ERROR", usage: "Reaction for failed responses"},
+
+	// OpenCode
+	{name: "OPENCODE_BASE_URL", usage: "OpenCode server URL"},
+	{name: "OPENCODE_DIRECTORY", usage: "Project directory of new sessions"},
+	{name: "OPENCODE_API_KEY", usage: "OpenCode API key"},
+	{name: "OPENCODE_PROXY", usage: "HTTP/SOCKS5 proxy of OpenCode requests, none to connect directly (default: TELEGRAM_PROXY)"},
+	{name: "OPENCODE_SERVERS", usage: "JSON list of servers chats can switch between ({name, base_url, directory, api_key})"},
+	{name: "OPENCODE_CA_FILE", usage: "CA certificate of the OpenCode server"},
+	{name: "OPENCODE_CLIENT_CERT", usage: "Client certificate for OpenCode"},
+	{name: "OPENCODE_CLIENT_KEY", usage: "Client key for OpenCode"},
+	{name: "OPENCODE_MAX_RETRIES", usage: "Retries of idempotent OpenCode requests"},
+	{name: "OPENCODE_BREAKER_THRESHOLD", usage: "Failures opening the circuit breaker (0: disabled)"},
+	{name: "OPENCODE_BREAKER_COOLDOWN_SEC", usage: "Time the circuit breaker stays open"},
+	{name: "OPENCODE_TIMEOUT_DEFAULT_SEC", usage: "Timeout of OpenCode requests"},
+	{name: "OPENCODE_TIMEOUT_HEALTH_SEC", usage: "Timeout of OpenCode health checks"},
+	{name: "OPENCODE_HEALTH_POLL_SEC", usage: "How often OpenCode's /health is polled (0: never)"},
+	{name: "OPENCODE_TIMEOUT_PROMPT_SEC", usage: "Timeout of prompt submissions"},
+	{name: "OPENCODE_TIMEOUT_MESSAGES_SEC", usage: "Timeout of message listings"},
+	{name: "OPENCODE_MAX_IDLE_CONNS", usage: "Idle connections kept to OpenCode"},
+	{name: "OPENCODE_IDLE_CONN_TIMEOUT_SEC", usage: "How long idle connections to OpenCode are kept"},
+	{name: "OPENCODE_SSE_STALE_SEC", usage: "Silence after which the event stream is reconnected"},
+	{name: "OPENCODE_SSE_SESSION_FILTER", usage: "Drop events of sessions no chat uses", boolean: true},
+	{name: "OPENCODE_SSE_EVENT_BACKLOG", usage: "Events buffered before streaming updates are dropped"},
+	{name: "OPENCODE_SSE_WITH_PLUGIN", usage: "Read the event stream in plugin mode too", boolean: true},
+	{name: "OPENCODE_EVENT_WEBSOCKET_PATH", usage: "Read events over a WebSocket at this path"},
+	{name: "OPENCODE_EVENT_STRICT", usage: "Report events that do not match the known schema", boolean: true},
+
+	// Plugin webhook
+	{name: "USE_PLUGIN_MODE", usage: "Receive events from the OpenCode plugin (the plugin_mode feature)", boolean: true},
+	{name: "PLUGIN_WEBHOOK_PORT", usage: "Port of the plugin webhook"},
+	{name: "PLUGIN_WEBHOOK_TOKEN", usage: "Bearer token required by the plugin webhook"},
+	{name: "PLUGIN_WEBHOOK_ALLOWED_CIDRS", usage: "Networks allowed to call the plugin webhook"},
+	{name: "PLUGIN_WEBHOOK_RATE_LIMIT", usage: "Requests per second per address"},
+	{name: "PLUGIN_WEBHOOK_MAX_BODY_BYTES", usage: "Maximum request size"},
+	{name: "PLUGIN_WEBHOOK_WORKERS", usage: "Workers publishing webhook events"},
+	{name: "PLUGIN_WEBHOOK_DEAD_LETTER_FILE", usage: "Events whose handling failed"},
+	{name: "PLUGIN_WEBHOOK_TLS_CERT", usage: "TLS certificate of the plugin webhook"},
+	{name: "PLUGIN_WEBHOOK_TLS_KEY", usage: "TLS key of the plugin webhook"},
+
+	// Media
+	{name: "TRANSCRIPTION_API_URL", usage: "OpenAI-compatible transcription API"},
+	{name: "TRANSCRIPTION_API_KEY", usage: "Transcription API key"},
+	{name: "TRANSCRIPTION_MODEL", usage: "Transcription model"},
+	{name: "TRANSCRIPTION_LANGUAGE", usage: "Language of voice notes"},
+	{name: "FFMPEG_PATH", usage: "ffmpeg binary for video keyframes"},
+
+	// Operations
+	{name: "HEALTH_PORT", usage: "Port of /health and /metrics"},
+	{name: "HEALTH_TLS_CERT", usage: "TLS certificate of the health server"},
+	{name: "HEALTH_TLS_KEY", usage: "TLS key of the health server"},
+	{name: "DEBUG_TOKEN", usage: "Bearer token enabling /debug/pprof/ and /debug/state"},
+	{name: "SENTRY_DSN", usage: "Sentry project that panics and errors are reported to"},
+	{name: "ERROR_REPORT_URL", usage: "Webhook that panics and errors are posted to, without Sentry"},
+	{name: "SHUTDOWN_TIMEOUT_SEC", usage: "Time spent draining on shutdown"},
+	{name: "BRIDGE_ENTRY_TTL_SEC", usage: "Lifetime of in-flight entries (0: forever)"},
+	{name: "AUDIT_LOG_FILE", usage: "Append-only log of sensitive actions"},
+	{name: "STATE_BACKEND", usage: "Storage of the state files (file, bolt)"},
+	{name: "STATE_ENCRYPTION_KEY", usage: "Base64 AES-256 key encrypting the state files"},
+	{name: "STATE_ENCRYPTION_KEY_FILE", usage: "File holding the state encryption key"},
+	{name: "LOG_LEVEL", usage: "Minimum level of the log lines (debug, info, warn, error)"},
+	{name: "LOG_FORMAT", usage: "Format of the log lines (text, json)"},
+	{name: "FEATURES", usage: "Experimental features to turn on or off, e.g. streaming_edits=false,reactions=false"},
+	{name: "STATE_MIGRATE_DRY_RUN", usage: "Log the state migrations and exit", boolean: true},
+	{name: "SECRET_FILES_POLL_SEC", usage: "How often the *_FILE secrets are checked for rotation (0: never)"},
+}
+
+// flagName is the flag of an environment variable, e.g.
+// "telegram-debounce-ms" for TELEGRAM_DEBOUNCE_MS
+func flagName(env string) string {
+	return strings.ToLower(strings.ReplaceAll(env, "_", "-"))
+}
+
+// addEnvFlags adds a flag for each environment variable and each secret's
+// file; a flag given on the command line sets its variable
+func addEnvFlags(fs *flag.FlagSet) {
+	for _, setting := range envSettings {
+		name := setting.name
+		usage := fmt.Sprintf("%s (%s)", setting.usage, name)
+		set := func(value string) error { return os.Setenv(name, value) }
+		if setting.boolean {
+			fs.BoolFunc(flagName(name), usage, set)
+		} else {
+			fs.Func(flagName(name), usage, set)
+		}
+	}
+	for _, name := range config.SecretVars {
+		name += "_FILE"
+		fs.Func(flagName(name), fmt.Sprintf("File holding %s (%s)", strings.TrimSuffix(name, "_FILE"), name), func(value string) error { return os.Setenv(name, value) })
+	}
+}
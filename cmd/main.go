@@ -3,21 +3,30 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"html"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/user/opencode-telegram/internal/bridge"
+	"github.com/user/opencode-telegram/internal/buildinfo"
 	"github.com/user/opencode-telegram/internal/config"
+	"github.com/user/opencode-telegram/internal/errreport"
+	"github.com/user/opencode-telegram/internal/events"
+	"github.com/user/opencode-telegram/internal/features"
 	"github.com/user/opencode-telegram/internal/health"
+	"github.com/user/opencode-telegram/internal/httplog"
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/logging"
 	"github.com/user/opencode-telegram/internal/metrics"
 	"github.com/user/opencode-telegram/internal/opencode"
 	"github.com/user/opencode-telegram/internal/state"
@@ -25,95 +34,39 @@ import (
 	"github.com/user/opencode-telegram/internal/webhook"
 )
 
-func main() {
-	// Read shared configuration
-	ocBaseURL := getenv("OPENCODE_BASE_URL", "http://localhost:54321")
-	ocDirectory := getenv("OPENCODE_DIRECTORY", ".")
-	debounceStr := getenv("TELEGRAM_DEBOUNCE_MS", "1000")
-	offsetFile := getenv("TELEGRAM_OFFSET_FILE", "~/.opencode-telegram-offset")
-	stateFile := getenv("TELEGRAM_STATE_FILE", "~/.opencode-telegram-state")
-	proxyURL := os.Getenv("TELEGRAM_PROXY")
-
-	// Webhook mode variables
-	webhookURL := os.Getenv("TELEGRAM_WEBHOOK_URL")
-	webhookPort := getenv("TELEGRAM_WEBHOOK_PORT", "8443")
-	webhookSecret := os.Getenv("TELEGRAM_WEBHOOK_SECRET")
-
-	// OpenCode plugin webhook variables
-	pluginWebhookPort := getenv("PLUGIN_WEBHOOK_PORT", "8888")
-	usePlugin := getenv("USE_PLUGIN_MODE", "true") == "true"
-
-	// Parse bot accounts
-	accounts, err := config.ParseAccountConfigs()
-	if err != nil {
-		log.Fatalf("Failed to parse account configs: %v", err)
-	}
+// outboxRetryInterval is how often queued Telegram messages are retried
+const outboxRetryInterval = 30 * time.Second
 
-	if len(accounts) == 0 {
-		log.Fatal("No bot accounts configured. Set TELEGRAM_BOT_TOKEN + TELEGRAM_CHAT_ID or TELEGRAM_ACCOUNTS")
-	}
+// telegramProbeInterval is how often each bot checks that the Bot API answers
+const telegramProbeInterval = time.Minute
 
-	// Parse debounce with validation
-	debounceMs, err := strconv.ParseInt(debounceStr, 10, 64)
-	if err != nil || debounceMs < 0 || debounceMs > 3000 {
-		debounceMs = 1000
-	}
-	debounceDuration := time.Duration(debounceMs) * time.Millisecond
+// sendFailureLimit is how many requests to a chat may fail in a row before
+// its bot counts as unable to reach Telegram
+const sendFailureLimit = 5
 
-	log.Printf("Starting OpenCode-Telegram Bridge...")
-	log.Printf("OpenCode URL: %s", ocBaseURL)
-	log.Printf("OpenCode Directory: %s", ocDirectory)
-	log.Printf("Debounce Duration: %dms", debounceMs)
-	log.Printf("Active Accounts: %d", len(accounts))
-	log.Printf("Plugin Mode: %v (webhook port: %s)", usePlugin, pluginWebhookPort)
-	if proxyURL != "" {
-		log.Printf("Proxy URL: %s", proxyURL)
-	}
-	if webhookURL != "" {
-		log.Printf("Webhook Mode: URL=%s, Port=%s", webhookURL, webhookPort)
-	} else {
-		log.Printf("Polling Mode enabled")
-	}
+var logger = logging.For("main")
 
-	// Create shared HTTP transport with proxy support
-	var transport *http.Transport
-	if proxyURL != "" {
-		var err error
-		transport, err = opencode.NewProxyTransport(proxyURL)
-		if err != nil {
-			log.Fatalf("Failed to create proxy transport: %v", err)
-		}
-		log.Printf("Proxy transport created: %s", proxyURL)
-	}
+// runBridge runs the bridge until it is stopped, the "run" command
+func runBridge() {
+	s := loadSettings()
 
-	ocConfig := opencode.Config{
-		BaseURL:   ocBaseURL,
-		Directory: ocDirectory,
+	// Dry run: report the state migrations the next start would apply
+	if getenv("STATE_MIGRATE_DRY_RUN", "false") == "true" {
+		planStateMigrations(s.bot.stateBackend, s.stateFile, s.accounts)
+		return
 	}
 
-	// Create shared OpenCode client (one per bridge)
-	var ocClient *opencode.Client
-	if transport != nil {
-		ocClient = opencode.NewClientWithTransport(ocConfig, transport)
-	} else {
-		ocClient = opencode.NewClient(ocConfig)
-	}
+	logSettings(s)
 
-	// Create shared SSE consumer (only if not using plugin mode)
-	var sseConsumer *opencode.SSEConsumer
-	if !usePlugin {
-		if transport != nil {
-			sseConsumer = opencode.NewSSEConsumerWithTransport(ocConfig, transport)
-		} else {
-			sseConsumer = opencode.NewSSEConsumer(ocConfig)
-		}
-	}
+	var trackers sessionTrackers
+	servers, ocClients, sseConsumers := newOpenCodeServers(s, &trackers)
+	streamedAccounts := s.accounts
 
-	// Create shared HTTP client for media downloads
+	// Create shared HTTP client for media downloads, through TELEGRAM_PROXY
 	var mediaClient *http.Client
-	if transport != nil {
+	if s.transport != nil {
 		mediaClient = &http.Client{
-			Transport: transport,
+			Transport: s.transport,
 			Timeout:   30 * time.Second,
 		}
 	} else {
@@ -127,188 +80,691 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	// Panics and error lines are reported, e.g. to Sentry, if enabled
+	if s.errorSink != nil {
+		reporter := errreport.New(s.errorSink)
+		go reporter.Run(ctx)
+		logging.SetErrorHook(reporter.Capture)
+		defer logging.SetErrorHook(nil)
+		logger.Info("Error reporting enabled", "sentry", os.Getenv("SENTRY_DSN") != "")
+	}
 
-	// Create health monitor
-	healthMonitor := health.NewHealthMonitor()
+	// Telegram updates stop first on shutdown, while the rest drains
+	updatesCtx, stopUpdates := context.WithCancel(ctx)
+	defer stopUpdates()
 
-	// Start health endpoint
-	healthPort := getenv("HEALTH_PORT", "8080")
-	healthMux := http.NewServeMux()
-	healthMux.Handle("/health", healthMonitor)
-	healthMux.Handle("/metrics", promhttp.Handler())
-	healthServer := &http.Server{
-		Addr:    ":" + healthPort,
-		Handler: healthMux,
-	}
-	go func() {
-		log.Printf("Health endpoint listening on :%s/health", healthPort)
-		log.Printf("Metrics endpoint listening on :%s/metrics", healthPort)
-		if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Health server error: %v", err)
-		}
-	}()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
+
+	healthMonitor := newHealthMonitor(ctx, servers, ocClients)
+	healthServer := startHealthServer(s, healthMonitor, trackers.all)
 	defer func() {
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer shutdownCancel()
 		healthServer.Shutdown(shutdownCtx)
 	}()
 
+	// Health changes are posted to the admin chat by the first account's bot
+	if s.alertChatID != 0 {
+		alertClient, err := telegramClient(telegramProxy(s.accounts[0], s.bot.proxyURL))
+		if err != nil {
+			logger.Warn("Invalid proxy, alerts connect directly", "error", err)
+		}
+		alertBot := telegram.NewBotWithClient(s.accounts[0].Token, s.alertChatID, 0, alertClient)
+		alertBot.SetAccount("alerts")
+		alerter := health.NewAlerter(healthMonitor, s.alertAfter, func(ctx context.Context, alert health.Alert) error {
+			_, err := alertBot.SendMessage(ctx, healthAlertText(s.bot.language, alert))
+			return err
+		})
+		go alerter.Run(ctx)
+	}
+
 	// Initialize Prometheus metrics
+	build := buildinfo.Get()
 	_ = metrics.SSEEventProcessingLatency
 	_ = metrics.TelegramMessageSendLatency
 	_ = metrics.ActiveSSEConnections
 	_ = metrics.SSEConnectionErrors
+	metrics.BuildInfo.WithLabelValues(build.Version, build.Commit, build.Date, build.GoVersion).Set(1)
+
+	// Events from the SSE streams or the plugin webhook reach every bridge
+	// through the bus
+	bus := events.NewBus()
+	bus.Subscribe(ctx, events.Handlers{events.AnyType: func(event opencode.Event) error {
+		healthMonitor.RecordEvent(event.Type)
+		return nil
+	}})
+
+	// With both sources running, each event is published by whichever
+	// delivers it first
+	var ssePublisher, pluginPublisher opencode.Publisher = bus, bus
+	if s.usePlugin && s.useSSE {
+		dedup := events.NewDedup(bus, events.DefaultDedupWindow)
+		ssePublisher, pluginPublisher = dedup.Source("sse"), dedup.Source("plugin")
+	}
 
-	// Start plugin webhook server if enabled
-	var pluginWebhook *webhook.Server
-	var firstBridge *bridge.Bridge
-	if usePlugin {
-		log.Printf("Plugin mode enabled, will start webhook server after bridge initialization")
-	} else {
-		// Connect SSE consumer (shared) if not using plugin
-		if err := sseConsumer.Connect(ctx); err != nil {
-			log.Fatalf("Failed to connect SSE consumer: %v", err)
+	pluginWebhook := newPluginWebhook(s, pluginPublisher, bus)
+	if s.useSSE {
+		// Connect SSE consumers (shared); the stream counts as connected
+		// while any of them is
+		var connectedStreams atomic.Int32
+		for _, sseConsumer := range sseConsumers {
+			sseConsumer.OnConnectionChange(func(connected bool) {
+				if connected {
+					connectedStreams.Add(1)
+				} else {
+					connectedStreams.Add(-1)
+				}
+				healthMonitor.SetSSEConnected(connectedStreams.Load() > 0)
+			})
+			if err := sseConsumer.Connect(ctx); err != nil {
+				logger.Error("Failed to connect SSE consumer", "error", err)
+				os.Exit(1)
+			}
+			defer sseConsumer.Close()
 		}
-		defer sseConsumer.Close()
-		healthMonitor.SetSSEConnected(true)
 	}
 
-	// Create and start bot instances (one per account)
-	var wg sync.WaitGroup
-	bridgeChan := make(chan *bridge.Bridge, 1)
+	// Failed sends/edits are queued here and retried, shared by all accounts
+	outbox, err := state.LoadOutbox(s.outboxFile)
+	if err != nil {
+		logger.Warn("Failed to load outbox, starting empty", "error", err)
+	}
+
+	// Sensitive actions for /audit, shared by all accounts; also appended to
+	// AUDIT_LOG_FILE when set
+	auditLog, err := state.LoadAuditLog(s.auditLogFile)
+	if err != nil {
+		logger.Warn("Failed to load audit log, keeping it in memory only", "error", err)
+	}
 
-	for i, account := range accounts {
-		wg.Add(1)
-		go func(idx int, acc config.AccountConfig) {
-			defer wg.Done()
-			bridgeInst := runBotInstance(ctx, idx, acc, ocClient, sseConsumer, healthMonitor, debounceDuration, offsetFile, stateFile, webhookURL, webhookPort, webhookSecret)
-			if idx == 0 && usePlugin {
-				bridgeChan <- bridgeInst
+	s.bot.servers = servers
+	s.bot.bus = bus
+	s.bot.outbox = outbox
+	s.bot.auditLog = auditLog
+	s.bot.healthMonitor = healthMonitor
+	bots := startBots(ctx, updatesCtx, s, &trackers)
+
+	if s.usePlugin {
+		go func() {
+			healthMonitor.SetWebhookListening(true)
+			defer healthMonitor.SetWebhookListening(false)
+			if err := pluginWebhook.Start(ctx); err != nil {
+				logger.Error("Plugin webhook server error", "error", err)
 			}
-		}(i, account)
+		}()
+	}
+	for _, sseConsumer := range sseConsumers {
+		sseConsumer.PublishTo(ctx, ssePublisher)
 	}
 
-	if usePlugin {
-		select {
-		case firstBridge = <-bridgeChan:
-			pluginWebhook = webhook.NewServer(":"+pluginWebhookPort, firstBridge)
-			go func() {
-				if err := pluginWebhook.Start(ctx); err != nil {
-					log.Printf("Plugin webhook server error: %v", err)
-				}
-			}()
-		case <-time.After(5 * time.Second):
-			log.Printf("Warning: Timeout waiting for first bridge instance")
-		}
+	waitForShutdown(ctx, s, sigChan, bots, pluginWebhook, streamedAccounts)
+
+	drain(s.shutdownTimeout, stopUpdates, pluginWebhook, sseConsumers, trackers.all(), bus)
+	cancel()
+
+	// Wait up to 5 seconds for all bots to finish
+	if bots.wait(5 * time.Second) {
+		logger.Info("All bots shut down gracefully")
+	} else {
+		logger.Warn("Shutdown timeout exceeded")
 	}
 
-	// Wait for shutdown signal or reload
-	for {
-		sig := <-sigChan
-		log.Printf("Received signal: %v", sig)
+}
 
-		if sig == syscall.SIGHUP {
-			log.Println("Reloading configuration...")
-			if err := reloadConfig(&ocDirectory); err != nil {
-				log.Printf("Config reload failed: %v", err)
+// newOpenCodeServers creates the shared OpenCode clients and, when reading
+// the event stream, SSE consumers (one per server and project directory)
+func newOpenCodeServers(s *settings, trackers *sessionTrackers) ([]bridge.Server, []*opencode.Client, []*opencode.SSEConsumer) {
+	// OpenCode gets its own transport (through OPENCODE_PROXY, if any), so
+	// its connection pool and TLS files do not affect Telegram requests
+	var ocTransport *http.Transport
+	if s.ocProxyTransport != nil {
+		ocTransport = s.ocProxyTransport
+	} else {
+		ocTransport = opencode.NewTransport()
+	}
+	opencode.TuneTransport(ocTransport, s.ocKeepAlive)
+	if s.ocTLSConfig != nil {
+		ocTransport.TLSClientConfig = s.ocTLSConfig
+		logger.Info("OpenCode TLS", "ca", s.ocTLSFiles.CAFile, "client_certificate", s.ocTLSFiles.CertFile != "")
+	}
+
+	var servers []bridge.Server
+	var ocClients []*opencode.Client
+	var sseConsumers []*opencode.SSEConsumer
+	for _, srv := range s.serverConfigs {
+		ocConfig := opencode.Config{
+			BaseURL:   srv.BaseURL,
+			Directory: s.ocDirectory,
+			APIKey:    s.ocAPIKey,
+		}
+		if srv.Directory != "" {
+			ocConfig.Directory = srv.Directory
+		}
+		if srv.APIKey != "" {
+			ocConfig.APIKey = srv.APIKey
+		}
+
+		ocClient := opencode.NewClientWithTransport(ocConfig, ocTransport)
+		ocClient.SetRetryPolicy(s.retryPolicy)
+		ocClient.SetTimeouts(s.ocTimeouts)
+		ocClient.SetCircuitBreaker(s.breakerThreshold, s.breakerCooldown)
+
+		// Learn each existing session's directory, so a session restored from
+		// state is prompted in its own project rather than OPENCODE_DIRECTORY
+		if _, err := ocClient.ListSessions(); err != nil {
+			logger.Warn("Could not list OpenCode sessions", "server", srv.Name, "error", err)
+		}
+
+		servers = append(servers, bridge.Server{Name: srv.Name, BaseURL: srv.BaseURL, Client: ocClient})
+		ocClients = append(ocClients, ocClient)
+
+		if !s.useSSE {
+			continue
+		}
+		// OpenCode streams the events of one directory, so accounts with
+		// their own project need a consumer of their own
+		for _, dir := range eventDirectories(ocConfig.Directory, s.accounts) {
+			dirConfig := ocConfig
+			dirConfig.Directory = dir
+			var sseConsumer *opencode.SSEConsumer
+			if s.eventWebSocketPath != "" {
+				sseConsumer = opencode.NewWebSocketConsumer(dirConfig, ocTransport, s.eventWebSocketPath)
 			} else {
-				log.Println("Configuration reloaded successfully")
+				sseConsumer = opencode.NewSSEConsumerWithTransport(dirConfig, ocTransport)
 			}
-			continue
+			sseConsumer.SetStaleTimeout(s.sseStaleTimeout)
+			sseConsumer.SetEventBacklog(s.sseBacklog)
+			sseConsumer.SetStrictDecoding(s.eventStrict)
+			if s.sseSessionFilter {
+				sseConsumer.SetSessionFilter(trackers.tracks)
+			}
+			sseConsumers = append(sseConsumers, sseConsumer)
 		}
-
-		log.Println("Shutting down gracefully...")
-		break
 	}
+	return servers, ocClients, sseConsumers
+}
 
-	cancel()
+// newHealthMonitor creates the health monitor, following the OpenCode
+// circuit breakers and polling each server's /health
+func newHealthMonitor(ctx context.Context, servers []bridge.Server, ocClients []*opencode.Client) *health.HealthMonitor {
+	healthMonitor := health.NewHealthMonitor()
+	var openCircuits atomic.Int32
+	for _, ocClient := range ocClients {
+		ocClient.OnCircuitChange(func(open bool) {
+			if open {
+				openCircuits.Add(1)
+			} else {
+				openCircuits.Add(-1)
+			}
+			healthMonitor.SetOpenCodeAvailable(openCircuits.Load() == 0)
+		})
+	}
+	// Polling /health notices an unreachable server while no chat uses it
+	if interval := getenvSeconds("OPENCODE_HEALTH_POLL_SEC", 30*time.Second); interval > 0 {
+		for _, srv := range servers {
+			go healthMonitor.PollOpenCode(ctx, srv.Name, interval, srv.Client.Health)
+		}
+	}
+	return healthMonitor
+}
 
-	// Wait for all bots to finish
-	done := make(chan struct{})
+// startHealthServer serves the health, metrics and debug endpoints
+func startHealthServer(s *settings, healthMonitor *health.HealthMonitor, bridges func() []*bridge.Bridge) *http.Server {
+	healthMux := http.NewServeMux()
+	healthMux.Handle("/health", healthMonitor)
+	healthMux.Handle("/livez", healthMonitor.LivenessHandler())
+	healthMux.Handle("/readyz", healthMonitor.ReadinessHandler())
+	healthMux.Handle("/metrics", promhttp.Handler())
+	// pprof and /debug/state only with a token, as they expose internals
+	if os.Getenv("DEBUG_TOKEN") != "" {
+		healthMux.Handle("/debug/", debugHandler(bridges))
+		logger.Info("Debug endpoints enabled", "port", s.healthPort)
+	}
+	healthServer := &http.Server{
+		Addr:      ":" + s.healthPort,
+		Handler:   httplog.Handler("health", healthMux),
+		TLSConfig: s.healthTLS,
+	}
 	go func() {
-		wg.Wait()
-		close(done)
+		logger.Info("Health and metrics endpoints listening", "port", s.healthPort, "https", s.healthTLS != nil)
+		var err error
+		if s.healthTLS != nil {
+			err = healthServer.ListenAndServeTLS("", "")
+		} else {
+			err = healthServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error("Health server error", "error", err)
+		}
 	}()
+	return healthServer
+}
+
+// newPluginWebhook creates the plugin webhook server, nil outside plugin
+// mode. It is started once the bridges subscribed to the bus.
+func newPluginWebhook(s *settings, publisher opencode.Publisher, bus *events.Bus) *webhook.Server {
+	if !s.usePlugin {
+		return nil
+	}
+	logger.Info("Plugin mode enabled, will start webhook server after bridge initialization")
+	pluginWebhook := webhook.NewServer(":"+s.pluginWebhookPort, publisher)
+	pluginWebhook.SetToken(s.pluginWebhookToken)
+	pluginWebhook.SetAllowedNetworks(s.pluginWebhookNetworks)
+	pluginWebhook.SetTLSConfig(s.pluginWebhookTLS)
+	pluginWebhook.SetRateLimit(s.pluginWebhookRate)
+	pluginWebhook.SetMaxBodyBytes(s.pluginWebhookMaxBody)
+	pluginWebhook.SetWorkers(s.pluginWebhookWorkers)
+
+	// Webhook events whose handling fails are kept for /webhook/replay
+	deadLetters, err := state.LoadDeadLetters(s.deadLetterFile)
+	if err != nil {
+		logger.Warn("Failed to load dead letters, starting empty", "error", err)
+	}
+	pluginWebhook.SetDeadLetters(deadLetters)
+	bus.SetFailureHandler(pluginWebhook.DeadLetter)
+	return pluginWebhook
+}
 
-	// Wait up to 5 seconds for graceful shutdown
+// startBots creates and starts the bot instances (one per account) and
+// waits for their bridges to subscribe. Each can be stopped on its own,
+// when a reload removes or changes its account.
+func startBots(ctx, updatesCtx context.Context, s *settings, trackers *sessionTrackers) *botSet {
+	bots := newBotSet(trackers, func(idx int, spec botSpec, debounce time.Duration) *botInstance {
+		botCtx, stop := context.WithCancel(ctx)
+		botUpdatesCtx, stopBotUpdates := context.WithCancel(updatesCtx)
+		spec.adoptSharedFiles(s.offsetFile, s.stateFile)
+		s.bot.healthMonitor.BotStarted()
+		bridgeInst, done := runBotInstance(botCtx, botUpdatesCtx, idx, spec, debounce, &s.bot)
+		go func() {
+			<-done
+			s.bot.healthMonitor.BotStopped()
+		}()
+		return &botInstance{spec: spec, bridge: bridgeInst, stop: stop, stopUpdates: stopBotUpdates, done: done}
+	})
+
+	var ready sync.WaitGroup
+	debounce := s.debounceDuration
+	for i, spec := range botSpecs(s.accounts, s.offsetFile, s.stateFile, webhookSecretFor(s.bot.webhookURL)) {
+		ready.Add(1)
+		go func(idx int, spec botSpec) {
+			defer ready.Done()
+			bots.run(idx, spec, debounce)
+		}(i, spec)
+	}
+
+	// Publish events once the bridges have subscribed, so none are missed
+	bridgesReady := make(chan struct{})
+	go func() {
+		ready.Wait()
+		close(bridgesReady)
+	}()
 	select {
-	case <-done:
-		log.Println("All bots shut down gracefully")
+	case <-bridgesReady:
 	case <-time.After(5 * time.Second):
-		log.Println("Shutdown timeout exceeded")
+		logger.Warn("Timeout waiting for bridge instances")
 	}
-
-	log.Println("Shutdown complete")
+	return bots
 }
 
-// runBotInstance runs a single bot instance for one account
-func runBotInstance(
-	ctx context.Context,
-	accountIdx int,
-	account config.AccountConfig,
-	ocClient *opencode.Client,
-	sseConsumer *opencode.SSEConsumer,
-	healthMonitor *health.HealthMonitor,
-	debounceDuration time.Duration,
-	offsetFile string,
-	stateFile string,
-	webhookURL, webhookPort, webhookSecret string,
-) *bridge.Bridge {
-	// Load offset for this account
-	currentOffset, err := state.LoadOffset(offsetFile)
-	if err != nil {
-		log.Printf("[Account %d] Warning: Failed to load offset: %v. Starting from beginning.", accountIdx, err)
-		currentOffset = 0
+// waitForShutdown handles signals and secret file changes until the bridge
+// is told to stop: SIGHUP and changed secrets reload the configuration, and
+// SIGUSR1 toggles debug logging
+func waitForShutdown(ctx context.Context, s *settings, sigChan <-chan os.Signal, bots *botSet, pluginWebhook *webhook.Server, streamedAccounts []config.AccountConfig) {
+	// applyConfig brings the bots and the plugin webhook in line with the
+	// environment after a reload
+	applyConfig := func() {
+		accounts, err := config.ParseAccountConfigs()
+		if err == nil && len(accounts) == 0 {
+			err = fmt.Errorf("no bot accounts configured")
+		}
+		if err != nil {
+			logger.Error("Config reload failed, keeping the running bots", "error", err)
+			return
+		}
+		addLogSecrets(accounts, nil)
+		if s.useSSE {
+			warnUnstreamedDirectories(accounts, streamedAccounts)
+		}
+		s.debounceDuration = parseDebounce(os.Getenv("TELEGRAM_DEBOUNCE_MS"))
+		if level, err := parseLogLevel(); err != nil {
+			logger.Warn("Keeping the log level", "level", logging.CurrentLevel(), "error", err)
+		} else {
+			s.logLevel = level
+			logging.SetLevel(level)
+		}
+		if pluginWebhook != nil {
+			pluginWebhook.SetToken(os.Getenv("PLUGIN_WEBHOOK_TOKEN"))
+		}
+		bots.reload(botSpecs(accounts, s.offsetFile, s.stateFile, webhookSecretFor(s.bot.webhookURL)), s.debounceDuration, s.shutdownTimeout)
+		logger.Info("Configuration reloaded", "accounts", len(accounts), "debounce", s.debounceDuration)
+	}
+
+	// Secret files are polled, as mounted secrets rotate without a signal
+	var secretChanges <-chan []string
+	if interval := getenvSeconds("SECRET_FILES_POLL_SEC", 30*time.Second); interval > 0 {
+		secretChanges = config.WatchSecretFiles(ctx, interval)
 	}
 
+	// Wait for shutdown signal or reload
+	for {
+		var sig os.Signal
+		select {
+		case sig = <-sigChan:
+		case changed := <-secretChanges:
+			logger.Info("Secret files changed", "settings", strings.Join(changed, ","))
+			for _, name := range changed {
+				switch name {
+				case "OPENCODE_API_KEY", "OPENCODE_SERVERS", "TRANSCRIPTION_API_KEY":
+					logger.Warn("Setting changed, restart the bridge to apply it", "setting", name)
+				}
+			}
+			applyConfig()
+			continue
+		}
+		logger.Info("Received signal", "signal", sig.String())
+
+		// SIGUSR1 toggles debug logging
+		if sig == syscall.SIGUSR1 {
+			level := logging.LevelDebug
+			if logging.CurrentLevel() == logging.LevelDebug {
+				level = max(s.logLevel, logging.DefaultLevel)
+			}
+			logging.SetLevel(level)
+			logger.Info("Log level set", "level", level)
+			continue
+		}
+
+		if sig == syscall.SIGHUP {
+			logger.Info("Reloading configuration")
+			if err := reloadConfig(); err != nil {
+				logger.Error("Config reload failed", "error", err)
+				continue
+			}
+			// Secret files take precedence over the credentials file
+			if _, err := config.LoadSecretFiles(); err != nil {
+				logger.Error("Config reload failed", "error", err)
+				continue
+			}
+			applyConfig()
+			continue
+		}
+
+		logger.Info("Shutting down gracefully")
+		break
+	}
+}
+
+// runBotInstance runs the bot instance of spec's account, debounce being
+// the default window. The returned channel is closed once the bot stopped
+// receiving updates and, after ctx ends, saved what its ID registry and state
+// still had pending.
+func runBotInstance(ctx, updatesCtx context.Context, accountIdx int, spec botSpec, debounce time.Duration, opts *botOptions) (*bridge.Bridge, <-chan struct{}) {
+	account := spec.account
+	account.Proxy = telegramProxy(account, opts.proxyURL)
+	webhookPort := opts.webhookPort
+	servers := opts.servers
+
 	accountName := account.Name
 	if accountName == "" {
 		accountName = "account-" + strconv.Itoa(accountIdx)
 	}
+	accountLog := logger.With("account", accountName, "chat", account.ChatID)
 
-	log.Printf("[%s] Starting bot instance (ChatID: %d)", accountName, account.ChatID)
+	// Load offset for this account
+	currentOffset, err := state.LoadOffset(spec.offsetFile)
+	if err != nil {
+		accountLog.Warn("Failed to load offset, starting from the beginning", "error", err)
+		currentOffset = 0
+	}
+
+	accountLog.Info("Starting bot instance", "state_file", spec.stateFile, "state_backend", opts.stateBackend, "offset_file", spec.offsetFile)
+
+	// The account's own settings override the global ones
+	if account.WebhookPort != "" {
+		webhookPort = account.WebhookPort
+	}
+	if account.Directory != "" {
+		accountLog.Info("OpenCode directory", "directory", account.Directory)
+		servers = serversIn(servers, account.Directory)
+	}
+	tgClient, err := telegramClient(account.Proxy)
+	if err != nil {
+		accountLog.Warn("Invalid proxy, connecting directly", "error", err)
+		tgClient = directTelegramClient()
+	} else if account.Proxy != "" {
+		accountLog.Info("Telegram proxy", "proxy", account.Proxy)
+	}
 
 	// Create bot instance (one per account)
-	tgBot := telegram.NewBot(account.Token, account.ChatID, currentOffset)
-	tgBot.SetOffset(offsetFile)
+	tgBot := telegram.NewBotWithClient(account.Token, account.ChatID, currentOffset, tgClient)
+	tgBot.SetAccount(accountName)
+	tgBot.SetOffset(spec.offsetFile)
+	tgBot.SetSendInterval(opts.sendInterval)
+	tgBot.SetOutbox(opts.outbox)
+	go tgBot.RunOutbox(ctx, outboxRetryInterval)
+	go func() {
+		defer opts.healthMonitor.RemoveTelegramStatus(accountName)
+		tgBot.RunProbe(ctx, telegramProbeInterval, func(err error) {
+			if n := tgBot.SendFailures(); err == nil && n >= sendFailureLimit {
+				err = fmt.Errorf("the last %d requests to the chat failed", n)
+			}
+			if err != nil {
+				accountLog.Warn("Telegram API unreachable", "error", err)
+			}
+			opts.healthMonitor.SetTelegramStatus(accountName, err == nil, tgBot.LastSend())
+		})
+	}()
 
-	// Set bot commands for auto-completion
-	if err := tgBot.SetMyCommands(ctx); err != nil {
-		log.Printf("[%s] Warning: failed to set commands: %v", accountName, err)
+	store, err := state.OpenStore(opts.stateBackend, spec.stateFile)
+	if err != nil {
+		accountLog.Error("Cannot open the state, keeping it in memory only", "error", err)
 	}
+	appState := state.NewAppStateWithStore(store)
 
-	appState := state.NewAppState(stateFile)
+	// Set bot commands for auto-completion, in the language /lang picked for
+	// the chat if any
+	chatLang, _ := i18n.Parse(appState.GetChatLanguage(strconv.FormatInt(account.ChatID, 10)))
+	if err := tgBot.SetMyCommands(ctx, opts.language, chatLang); err != nil {
+		accountLog.Warn("Failed to set commands", "error", err)
+	}
+	if account.Agent != "" {
+		appState.SetCurrentAgent(account.Agent)
+	}
 	registry := state.NewIDRegistry()
+	registry.SetStore(appState)
+
+	// With several servers the chat talks to them through a switch that
+	// follows /server (one per account, so chats switch independently)
+	var ocClient bridge.OpenCodeClient = servers[0].Client
+	var serverSwitch *bridge.ServerSwitch
+	if len(servers) > 1 {
+		serverSwitch = bridge.NewServerSwitch(servers)
+		serverSwitch.LearnSessions()
+		ocClient = serverSwitch
+	}
 
 	// Create bridge instance (one per account)
-	bridgeInstance := bridge.NewBridge(ocClient, tgBot, appState, registry, debounceDuration)
-	bridgeInstance.SetHealthMonitor(healthMonitor)
-
-	// Start bridge (only if SSE consumer exists)
-	if sseConsumer != nil {
-		bridgeInstance.Start(ctx, sseConsumer)
+	bridgeInstance := bridge.NewBridge(ocClient, tgBot, appState, registry, spec.debounce(debounce))
+	bridgeInstance.SetAccount(accountName)
+	if serverSwitch != nil {
+		bridgeInstance.SetServerSwitch(serverSwitch)
+	}
+	bridgeInstance.SetQuickActionKeyboard(opts.quickKeyboard)
+	bridgeInstance.SetDefaultLanguage(opts.language)
+	bridgeInstance.SetCompletionReactions(opts.successReaction, opts.failureReaction)
+	bridgeInstance.SetNotificationPolicy(opts.notifyPolicy)
+	bridgeInstance.SetFreshFinalMessage(opts.deletePlaceholder)
+	bridgeInstance.SetPerUserSessions(opts.perUserSessions)
+	bridgeInstance.SetShowMore(opts.showMore)
+	bridgeInstance.SetResponseActions(opts.responseActions)
+	bridgeInstance.SetSessionBanner(opts.sessionBanner)
+	bridgeInstance.SetShowReasoning(opts.showReasoning)
+	bridgeInstance.SetStreamingEdits(opts.feats.Enabled(features.StreamingEdits))
+	bridgeInstance.SetReactions(opts.feats.Enabled(features.Reactions))
+	bridgeInstance.SetPhotoPrompt(opts.photoPrompt)
+	bridgeInstance.SetFeedbackChat(opts.feedbackChatID)
+	bridgeInstance.SetAuditLog(opts.auditLog)
+	bridgeInstance.SetEntryTTL(opts.entryTTL)
+	if opts.transcriber != nil {
+		bridgeInstance.SetTranscriber(opts.transcriber)
 	}
+	if opts.frameExtractor != nil {
+		bridgeInstance.SetFrameExtractor(opts.frameExtractor)
+	}
+
+	// Events of sessions no chat tracks (e.g. TUI sessions) go to the first account
+	bridgeInstance.Subscribe(ctx, opts.bus, accountIdx == 0)
 	bridgeInstance.RegisterHandlers()
 
+	// Re-post permission/question keyboards that were pending before a restart
+	if opts.feats.Enabled(features.PendingQuestions) {
+		go bridgeInstance.ReconcilePending(ctx)
+	}
+	// Sessions restored as busy may have finished while the bridge was down
+	go bridgeInstance.ReconcileSessionStatus(ctx)
+
 	// Start registry cleanup
 	registry.StartCleanup(ctx)
+	bridgeInstance.StartJanitor(ctx)
+	bridgeInstance.StartBufferMetrics(ctx)
 
+	done := make(chan struct{})
 	go func() {
-		if webhookURL != "" {
-			log.Printf("[%s] Starting in webhook mode on port %s", accountName, webhookPort)
-			if err := tgBot.StartWebhook(ctx, webhookURL, webhookPort, webhookSecret); err != nil {
-				log.Printf("[%s] Webhook error: %v", accountName, err)
+		defer close(done)
+		if opts.webhookURL != "" {
+			accountLog.Info("Starting in webhook mode", "port", webhookPort)
+			if err := tgBot.StartWebhook(updatesCtx, opts.webhookURL, webhookPort, spec.webhookSecret); err != nil {
+				accountLog.Error("Webhook error", "error", err)
 			}
 		} else {
-			log.Printf("[%s] Starting in polling mode", accountName)
-			tgBot.Start(ctx)
+			accountLog.Info("Starting in polling mode")
+			tgBot.Start(updatesCtx)
+		}
+		accountLog.Info("Bot instance shut down")
+
+		// Registry saves are batched; write the last ones
+		<-ctx.Done()
+		registry.Flush()
+		if err := appState.Close(); err != nil {
+			accountLog.Warn("Closing state", "error", err)
 		}
-		log.Printf("[%s] Bot instance shut down", accountName)
 	}()
 
-	return bridgeInstance
+	return bridgeInstance, done
+}
+
+// eventDirectories returns the directories whose events are streamed from a
+// server: its own, plus those of the accounts with a project of their own
+func eventDirectories(serverDir string, accounts []config.AccountConfig) []string {
+	dirs := []string{serverDir}
+	for _, account := range accounts {
+		if account.Directory != "" && !slices.Contains(dirs, account.Directory) {
+			dirs = append(dirs, account.Directory)
+		}
+	}
+	return dirs
+}
+
+// warnUnstreamedDirectories logs the reloaded accounts whose directory has
+// no event consumer: consumers are only started with the process
+func warnUnstreamedDirectories(accounts, streamed []config.AccountConfig) {
+	for _, account := range accounts {
+		if account.Directory == "" || slices.ContainsFunc(streamed, func(acc config.AccountConfig) bool {
+			return acc.Directory == account.Directory
+		}) {
+			continue
+		}
+		logger.Warn("No events for the account's directory until restart", "chat", account.ChatID, "directory", account.Directory)
+	}
+}
+
+// serversIn returns the servers with clients working in directory, for an
+// account with its own project
+func serversIn(servers []bridge.Server, directory string) []bridge.Server {
+	scoped := make([]bridge.Server, len(servers))
+	for i, srv := range servers {
+		scoped[i] = srv
+		if client, ok := srv.Client.(*opencode.Client); ok {
+			dirClient := client.WithDirectory(directory)
+			// Learn the sessions' directories, like the shared clients
+			if _, err := dirClient.ListSessions(); err != nil {
+				logger.Warn("Could not list OpenCode sessions", "server", srv.Name, "directory", directory, "error", err)
+			}
+			scoped[i].Client = dirClient
+		}
+	}
+	return scoped
+}
+
+// drain hands over what is in flight before shutdown: Telegram updates
+// stop, the event sources pass on what they received, debounced messages
+// are submitted, and every event is handled, including its Telegram sends
+func drain(timeout time.Duration, stopUpdates context.CancelFunc, pluginWebhook *webhook.Server, sseConsumers []*opencode.SSEConsumer, bridges []*bridge.Bridge, bus *events.Bus) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	started := time.Now()
+
+	stopUpdates()
+	if pluginWebhook != nil {
+		if err := pluginWebhook.Drain(ctx); err != nil {
+			logger.Warn("Draining plugin webhook", "error", err)
+		}
+	}
+	for _, sseConsumer := range sseConsumers {
+		if err := sseConsumer.Drain(ctx); err != nil {
+			logger.Warn("Draining SSE consumer", "error", err)
+		}
+	}
+	for _, bridgeInst := range bridges {
+		if err := bridgeInst.Drain(ctx); err != nil {
+			logger.Warn("Draining bridge", "error", err)
+		}
+	}
+	if err := bus.Drain(ctx); err != nil {
+		logger.Warn("Draining events", "error", err)
+	}
+	logger.Info("Drained in-flight work", "took", time.Since(started).Round(time.Millisecond))
+}
+
+// sessionTrackers is the SSE session filter over all account bridges
+type sessionTrackers struct {
+	mu      sync.RWMutex
+	bridges []*bridge.Bridge
+}
+
+func (t *sessionTrackers) add(b *bridge.Bridge) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bridges = append(t.bridges, b)
+}
+
+func (t *sessionTrackers) remove(b *bridge.Bridge) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bridges = slices.DeleteFunc(t.bridges, func(tracked *bridge.Bridge) bool { return tracked == b })
+}
+
+func (t *sessionTrackers) all() []*bridge.Bridge {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]*bridge.Bridge(nil), t.bridges...)
+}
+
+// tracks reports whether any bridge uses the session. Until the first bridge
+// is up, every session passes so nothing is lost during startup.
+func (t *sessionTrackers) tracks(sessionID string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.bridges) == 0 {
+		return true
+	}
+	for _, b := range t.bridges {
+		if b.TracksSession(sessionID) {
+			return true
+		}
+	}
+	return false
 }
 
 func getenv(key, defaultValue string) string {
@@ -318,10 +774,160 @@ func getenv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func reloadConfig(currentDirectory *string) error {
-	credFile := os.ExpandEnv("$HOME/.opencode-telegram-credentials")
-	data, err := os.ReadFile(credFile)
+// parseDebounce reads TELEGRAM_DEBOUNCE_MS, 1s when invalid or over 3s
+func parseDebounce(value string) time.Duration {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms < 0 || ms > 3000 {
+		ms = 1000
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// parseFeatures reads FEATURES over the defaults. USE_PLUGIN_MODE, which
+// predates it, still sets plugin_mode when given.
+func parseFeatures() (features.Set, error) {
+	feats, err := features.Parse(os.Getenv("FEATURES"))
 	if err != nil {
+		return features.Defaults(), err
+	}
+	if usePlugin := os.Getenv("USE_PLUGIN_MODE"); usePlugin != "" {
+		feats.Set(features.PluginMode, usePlugin == "true")
+	}
+	return feats, nil
+}
+
+// parseLogLevel reads LOG_LEVEL, info when unset
+func parseLogLevel() (logging.Level, error) {
+	value := os.Getenv("LOG_LEVEL")
+	if value == "" {
+		return logging.DefaultLevel, nil
+	}
+	level, ok := logging.ParseLevel(value)
+	if !ok {
+		return logging.DefaultLevel, fmt.Errorf("invalid LOG_LEVEL %q, expected debug, info, warn or error", value)
+	}
+	return level, nil
+}
+
+// parseLogFormat reads LOG_FORMAT, text when unset
+func parseLogFormat() (string, error) {
+	switch value := os.Getenv("LOG_FORMAT"); value {
+	case "", logging.FormatText:
+		return logging.FormatText, nil
+	case logging.FormatJSON:
+		return value, nil
+	default:
+		return logging.FormatText, fmt.Errorf("invalid LOG_FORMAT %q, expected text or json", value)
+	}
+}
+
+// webhookSecretFor returns TELEGRAM_WEBHOOK_SECRET in webhook mode; polling
+// bots do not use it
+func webhookSecretFor(webhookURL string) string {
+	if webhookURL == "" {
+		return ""
+	}
+	return os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+}
+
+// addLogSecrets keeps the secrets of the configuration out of log lines and
+// chat messages; bot tokens are recognized without it
+func addLogSecrets(accounts []config.AccountConfig, servers []config.ServerConfig) {
+	for _, name := range config.SecretVars {
+		logging.AddSecret(os.Getenv(name))
+	}
+	logging.AddSecret(os.Getenv("STATE_ENCRYPTION_KEY"))
+	for _, account := range accounts {
+		logging.AddSecret(account.Token)
+	}
+	for _, server := range servers {
+		logging.AddSecret(server.APIKey)
+	}
+}
+
+// getenvSeconds reads a non-negative number of seconds, falling back to
+// defaultValue when unset or invalid
+func getenvSeconds(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	sec, err := strconv.ParseFloat(value, 64)
+	if err != nil || sec < 0 {
+		logger.Warn("Invalid setting, using the default", "setting", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return time.Duration(sec * float64(time.Second))
+}
+
+// planStateMigrations logs the migrations loading each account's state file
+// would apply, without changing the files
+func planStateMigrations(backend, stateFile string, accounts []config.AccountConfig) {
+	files := []string{stateFile}
+	if len(accounts) > 1 {
+		files = files[:0]
+		for _, acc := range accounts {
+			files = append(files, acc.FileFor(stateFile))
+		}
+	}
+	for _, file := range files {
+		steps, err := state.PlanStateMigration(backend, file)
+		switch {
+		case err != nil:
+			logger.Error("Cannot plan state migration", "file", file, "error", err)
+		case len(steps) == 0:
+			logger.Info("State file up to date", "file", file, "schema", state.SchemaVersion)
+		default:
+			for _, step := range steps {
+				logger.Info("State file would migrate", "file", file, "step", step)
+			}
+		}
+	}
+}
+
+// setupEncryption encrypts persisted files with the key given inline or in
+// keyFile; neither leaves them in plaintext
+func setupEncryption(key, keyFile string) error {
+	var raw []byte
+	var err error
+	switch {
+	case key != "" && keyFile != "":
+		return fmt.Errorf("set only one of them")
+	case key != "":
+		raw, err = state.ParseKey(key)
+	case keyFile != "":
+		raw, err = state.LoadKeyFile(keyFile)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c, err := state.NewCipher(raw)
+	if err != nil {
+		return err
+	}
+	state.SetCipher(c)
+	logger.Info("State files are encrypted")
+	return nil
+}
+
+// credentialsFile is the file of KEY=value settings read on SIGHUP
+func credentialsFile() string {
+	return os.ExpandEnv("$HOME/.opencode-telegram-credentials")
+}
+
+// reloadConfig applies the settings of the credentials file to the
+// environment, for a SIGHUP to pick up. Values are not logged, as the file
+// holds secrets.
+func reloadConfig() error {
+	data, err := os.ReadFile(credentialsFile())
+	if err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+	// The credentials file may be encrypted with the state files' key, see
+	// the encrypt command
+	if data, err = state.Decrypt(data); err != nil {
 		return fmt.Errorf("read credentials: %w", err)
 	}
 
@@ -337,16 +943,47 @@ func reloadConfig(currentDirectory *string) error {
 			continue
 		}
 
-		key := strings.TrimSpace(parts[0])
+		key := strings.TrimPrefix(strings.TrimSpace(parts[0]), "export ")
 		value := strings.Trim(strings.TrimSpace(parts[1]), "\"'")
 
-		if key == "OPENCODE_DIRECTORY" && value != "" {
-			if *currentDirectory != value {
-				log.Printf("Updated OPENCODE_DIRECTORY: %s -> %s", *currentDirectory, value)
-				*currentDirectory = value
-			}
+		if key != "" && os.Getenv(key) != value {
+			logger.Info("Updated setting", "setting", key)
+			os.Setenv(key, value)
 		}
 	}
 
 	return nil
 }
+
+// healthAlertText formats a health alert for the admin chat
+func healthAlertText(lang i18n.Lang, alert health.Alert) string {
+	duration := alert.Duration.Round(time.Second).String()
+	if alert.Status == health.StatusHealthy {
+		return i18n.T(lang, "alert.recovered", duration)
+	}
+	lines := make([]string, len(alert.Problems))
+	for i, problem := range alert.Problems {
+		lines[i] = "• " + html.EscapeString(problem)
+	}
+	key := "alert.degraded"
+	if alert.Status == health.StatusUnhealthy {
+		key = "alert.unhealthy"
+	}
+	return i18n.T(lang, key, duration, strings.Join(lines, "\n"))
+}
+
+// errorReportSink is where panics and error lines are reported: Sentry if
+// SENTRY_DSN is set, else the ERROR_REPORT_URL webhook, else nowhere (nil)
+func errorReportSink() (errreport.Sink, error) {
+	if dsn := os.Getenv("SENTRY_DSN"); dsn != "" {
+		sentry, err := errreport.NewSentry(dsn, nil)
+		if err != nil {
+			return nil, err
+		}
+		return sentry, nil
+	}
+	if url := os.Getenv("ERROR_REPORT_URL"); url != "" {
+		return errreport.NewWebhook(url, nil), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/config"
+)
+
+// configProblems collects what is wrong with the configuration, so that
+// startup reports every problem at once instead of only the first
+type configProblems []string
+
+// add records err, if any, against setting. Joined errors are recorded one
+// by one.
+func (p *configProblems) add(setting string, err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			p.add(setting, err)
+		}
+		return
+	}
+	*p = append(*p, fmt.Sprintf("%s: %v", setting, err))
+}
+
+// fatal logs every problem and exits when there is any
+func (p configProblems) fatal() {
+	if len(p) == 0 {
+		return
+	}
+	for _, problem := range p {
+		logger.Error("Configuration problem", "problem", problem)
+	}
+	logger.Error("Invalid configuration (run \"opencode-telegram validate\" for hints)", "problems", len(p))
+	os.Exit(1)
+}
+
+// listener is a server the bridge listens with
+type listener struct {
+	name string
+	port string
+}
+
+// serverListeners returns the servers the bridge would start: the health
+// server, the plugin webhook and, in webhook mode, the Telegram webhook of
+// each account
+func serverListeners(healthPort, pluginPort string, usePlugin bool, webhookURL, webhookPort string, accounts []config.AccountConfig) []listener {
+	listeners := []listener{{"HEALTH_PORT", healthPort}}
+	if usePlugin {
+		listeners = append(listeners, listener{"PLUGIN_WEBHOOK_PORT", pluginPort})
+	}
+	if webhookURL == "" {
+		return listeners
+	}
+	// Accounts without their own port share TELEGRAM_WEBHOOK_PORT
+	for i, acc := range accounts {
+		l := listener{"TELEGRAM_WEBHOOK_PORT", webhookPort}
+		if acc.WebhookPort != "" {
+			l = listener{fmt.Sprintf("webhook_port of account %d", i), acc.WebhookPort}
+		}
+		if !slices.Contains(listeners, l) {
+			listeners = append(listeners, l)
+		}
+	}
+	return listeners
+}
+
+// checkPorts reports invalid ports and ports used by several servers
+func checkPorts(listeners []listener) error {
+	var errs []error
+	byPort := make(map[int][]string)
+	var ports []int
+	for _, l := range listeners {
+		port, err := strconv.Atoi(l.port)
+		if err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("%s: invalid port %q", l.name, l.port))
+			continue
+		}
+		if byPort[port] == nil {
+			ports = append(ports, port)
+		}
+		byPort[port] = append(byPort[port], l.name)
+	}
+	for _, port := range ports {
+		if names := byPort[port]; len(names) > 1 {
+			errs = append(errs, fmt.Errorf("%s all use port %d", strings.Join(names, ", "), port))
+		}
+	}
+	return errors.Join(errs...)
+}
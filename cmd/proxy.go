@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/config"
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// noProxy turns off a proxy set more broadly, e.g. an account's
+// "proxy": "none" under TELEGRAM_PROXY, or OPENCODE_PROXY=none
+const noProxy = "none"
+
+// proxyTransport returns a transport through proxyURL, nil to connect
+// directly
+func proxyTransport(proxyURL string) (*http.Transport, error) {
+	if proxyURL == "" || proxyURL == noProxy {
+		return nil, nil
+	}
+	return opencode.NewProxyTransport(proxyURL)
+}
+
+// openCodeProxy returns the proxy of OpenCode requests: OPENCODE_PROXY, or
+// TELEGRAM_PROXY when unset, which OpenCode requests used to go through
+func openCodeProxy() string {
+	return getenv("OPENCODE_PROXY", os.Getenv("TELEGRAM_PROXY"))
+}
+
+// telegramProxy returns the proxy of an account's Telegram requests: its
+// own, otherwise TELEGRAM_PROXY
+func telegramProxy(account config.AccountConfig, defaultProxy string) string {
+	if account.Proxy != "" {
+		return account.Proxy
+	}
+	return defaultProxy
+}
+
+// telegramClient returns the client of a bot's API requests and file
+// downloads through proxyURL, nil to use the defaults. A bot opting out of
+// TELEGRAM_PROXY gets a direct client of its own, as without one its file
+// downloads go through the shared media client, which uses TELEGRAM_PROXY
+func telegramClient(proxyURL string) (*http.Client, error) {
+	if proxyURL == noProxy {
+		return directTelegramClient(), nil
+	}
+	transport, err := proxyTransport(proxyURL)
+	if transport == nil || err != nil {
+		return nil, err
+	}
+	// As long as the bot's long polling requests
+	return &http.Client{Transport: transport, Timeout: time.Minute}, nil
+}
+
+// directTelegramClient returns a client of a bot's requests bypassing
+// TELEGRAM_PROXY
+func directTelegramClient() *http.Client {
+	return &http.Client{Timeout: time.Minute}
+}
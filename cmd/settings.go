@@ -0,0 +1,475 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/bridge"
+	"github.com/user/opencode-telegram/internal/buildinfo"
+	"github.com/user/opencode-telegram/internal/config"
+	"github.com/user/opencode-telegram/internal/errreport"
+	"github.com/user/opencode-telegram/internal/features"
+	"github.com/user/opencode-telegram/internal/health"
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/keyframes"
+	"github.com/user/opencode-telegram/internal/logging"
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+	"github.com/user/opencode-telegram/internal/transcription"
+	"github.com/user/opencode-telegram/internal/webhook"
+)
+
+// settings is the bridge's configuration, read from the environment at
+// startup. Reloads update the debounce window and log level in place.
+type settings struct {
+	accounts         []config.AccountConfig
+	serverConfigs    []config.ServerConfig
+	logLevel         logging.Level
+	debounceDuration time.Duration
+	shutdownTimeout  time.Duration
+
+	// OpenCode
+	ocDirectory        string
+	ocAPIKey           string
+	ocTLSFiles         opencode.TLSFiles
+	ocTLSConfig        *tls.Config
+	ocProxyURL         string
+	ocProxyTransport   *http.Transport
+	retryPolicy        opencode.RetryPolicy
+	breakerThreshold   int
+	breakerCooldown    time.Duration
+	ocTimeouts         opencode.Timeouts
+	ocKeepAlive        opencode.KeepAlive
+	useSSE             bool
+	sseStaleTimeout    time.Duration
+	sseSessionFilter   bool
+	sseBacklog         int
+	eventWebSocketPath string
+	eventStrict        bool
+
+	// Telegram
+	offsetFile     string
+	stateFile      string
+	outboxFile     string
+	auditLogFile   string
+	transport      *http.Transport
+	sendIntervalMs int64
+
+	// Plugin webhook
+	usePlugin             bool
+	pluginWebhookPort     string
+	pluginWebhookToken    string
+	pluginWebhookRate     float64
+	pluginWebhookWorkers  int
+	pluginWebhookMaxBody  int64
+	pluginWebhookNetworks []netip.Prefix
+	pluginWebhookTLS      *tls.Config
+	deadLetterFile        string
+
+	// Health endpoint, alerts and error reports
+	healthPort  string
+	healthTLS   *tls.Config
+	alertChatID int64
+	alertAfter  time.Duration
+	errorSink   errreport.Sink
+
+	// bot is what every bot instance starts with
+	bot botOptions
+}
+
+// loadSettings reads the configuration from the environment. Problems are
+// reported together, and exit.
+func loadSettings() *settings {
+	ocBaseURL := getenv("OPENCODE_BASE_URL", "http://localhost:54321")
+	ocDirectory := getenv("OPENCODE_DIRECTORY", ".")
+	ocAPIKey := os.Getenv("OPENCODE_API_KEY")
+	ocTLSFiles := opencode.TLSFiles{
+		CAFile:   os.Getenv("OPENCODE_CA_FILE"),
+		CertFile: os.Getenv("OPENCODE_CLIENT_CERT"),
+		KeyFile:  os.Getenv("OPENCODE_CLIENT_KEY"),
+	}
+	ocMaxRetriesStr := getenv("OPENCODE_MAX_RETRIES", strconv.Itoa(opencode.DefaultRetryPolicy.MaxRetries))
+	breakerThresholdStr := getenv("OPENCODE_BREAKER_THRESHOLD", strconv.Itoa(opencode.DefaultBreakerThreshold))
+	breakerCooldownStr := getenv("OPENCODE_BREAKER_COOLDOWN_SEC", strconv.Itoa(int(opencode.DefaultBreakerCooldown.Seconds())))
+	debounceStr := getenv("TELEGRAM_DEBOUNCE_MS", "1000")
+	sendIntervalStr := getenv("TELEGRAM_SEND_INTERVAL_MS", "1000")
+	offsetFile := getenv("TELEGRAM_OFFSET_FILE", "~/.opencode-telegram-offset")
+	stateFile := getenv("TELEGRAM_STATE_FILE", "~/.opencode-telegram-state")
+	stateBackend := getenv("STATE_BACKEND", state.BackendFile)
+	outboxFile := getenv("TELEGRAM_OUTBOX_FILE", "~/.opencode-telegram-outbox")
+	auditLogFile := os.Getenv("AUDIT_LOG_FILE")
+	proxyURL := os.Getenv("TELEGRAM_PROXY")
+	ocProxyURL := openCodeProxy()
+	quickKeyboard := getenv("TELEGRAM_QUICK_KEYBOARD", "false") == "true"
+	languageStr := getenv("TELEGRAM_LANGUAGE", string(i18n.Default))
+	notifyStr := getenv("TELEGRAM_NOTIFY", string(bridge.NotifyAll))
+	deletePlaceholder := getenv("TELEGRAM_DELETE_PLACEHOLDER", "false") == "true"
+	perUserSessions := getenv("TELEGRAM_PER_USER_SESSIONS", "false") == "true"
+	showMore := getenv("TELEGRAM_SHOW_MORE", "false") == "true"
+	showReasoning := getenv("TELEGRAM_SHOW_REASONING", "false") == "true"
+	photoPrompt := os.Getenv("TELEGRAM_PHOTO_PROMPT")
+	feedbackChatStr := os.Getenv("TELEGRAM_FEEDBACK_CHAT_ID")
+	alertChatStr := os.Getenv("TELEGRAM_ALERT_CHAT_ID")
+	alertAfter := getenvSeconds("ALERT_AFTER_SEC", health.DefaultAlertAfter)
+	responseActions := getenv("TELEGRAM_RESPONSE_ACTIONS", "false") == "true"
+	sessionBanner := getenv("TELEGRAM_SESSION_BANNER", "false") == "true"
+
+	// Completion reactions on the user's message (must be emoji from Telegram's reaction set)
+	var successReaction, failureReaction string
+	if getenv("TELEGRAM_COMPLETION_REACTIONS", "false") == "true" {
+		successReaction = getenv("TELEGRAM_REACTION_SUCCESS", "👍")
+		failureReaction = getenv("TELEGRAM_REACTION_ERROR", "👎")
+	}
+
+	// Transcription backend for voice notes and audio files (OpenAI-compatible)
+	transcriptionConfig := transcription.Config{
+		BaseURL:  os.Getenv("TRANSCRIPTION_API_URL"),
+		APIKey:   os.Getenv("TRANSCRIPTION_API_KEY"),
+		Model:    getenv("TRANSCRIPTION_MODEL", "whisper-1"),
+		Language: os.Getenv("TRANSCRIPTION_LANGUAGE"),
+	}
+	var transcriber bridge.Transcriber
+	if transcriptionConfig.BaseURL != "" || transcriptionConfig.APIKey != "" {
+		transcriber = transcription.NewClient(transcriptionConfig)
+	}
+
+	// ffmpeg extracts keyframes from GIFs and videos; without it only thumbnails are sent
+	var frameExtractor bridge.FrameExtractor
+	if extractor := keyframes.NewExtractor(getenv("FFMPEG_PATH", "ffmpeg")); extractor.Available() {
+		frameExtractor = extractor
+	}
+
+	// Webhook mode variables
+	webhookURL := os.Getenv("TELEGRAM_WEBHOOK_URL")
+	webhookPort := getenv("TELEGRAM_WEBHOOK_PORT", "8443")
+
+	// OpenCode plugin webhook variables
+	pluginWebhookPort := getenv("PLUGIN_WEBHOOK_PORT", "8888")
+	feats, featsErr := parseFeatures()
+	usePlugin := feats.Enabled(features.PluginMode)
+	// Plugin mode can read the SSE stream as well, for redundancy
+	useSSE := !usePlugin || getenv("OPENCODE_SSE_WITH_PLUGIN", "false") == "true"
+	pluginWebhookToken := os.Getenv("PLUGIN_WEBHOOK_TOKEN")
+	deadLetterFile := getenv("PLUGIN_WEBHOOK_DEAD_LETTER_FILE", "~/.opencode-telegram-deadletters")
+	pluginWebhookRate := float64(webhook.DefaultRateLimit)
+	if rate, err := strconv.ParseFloat(os.Getenv("PLUGIN_WEBHOOK_RATE_LIMIT"), 64); err == nil && rate >= 0 {
+		pluginWebhookRate = rate
+	}
+	pluginWebhookWorkers := webhook.DefaultWorkers
+	if n, err := strconv.Atoi(os.Getenv("PLUGIN_WEBHOOK_WORKERS")); err == nil && n >= 0 {
+		pluginWebhookWorkers = n
+	}
+	pluginWebhookMaxBody := int64(webhook.DefaultMaxBodyBytes)
+	if n, err := strconv.ParseInt(os.Getenv("PLUGIN_WEBHOOK_MAX_BODY_BYTES"), 10, 64); err == nil && n >= 0 {
+		pluginWebhookMaxBody = n
+	}
+	// Configuration problems are collected and reported together below
+	var problems configProblems
+	problems.add("FEATURES", featsErr)
+
+	// Lines are written as text or JSON (LOG_FORMAT); those below LOG_LEVEL
+	// are dropped, and /loglevel and SIGUSR1 change it while running
+	logFormat, err := parseLogFormat()
+	problems.add("LOG_FORMAT", err)
+	logging.Setup(os.Stderr, logFormat)
+	logLevel, err := parseLogLevel()
+	problems.add("LOG_LEVEL", err)
+	logging.SetLevel(logLevel)
+
+	pluginWebhookNetworks, err := webhook.ParseNetworks(os.Getenv("PLUGIN_WEBHOOK_ALLOWED_CIDRS"))
+	problems.add("PLUGIN_WEBHOOK_ALLOWED_CIDRS", err)
+
+	// HTTPS for the plugin webhook and the health server, e.g. when the
+	// plugin runs on another host
+	var pluginWebhookTLS, healthTLS *tls.Config
+	if certFile, keyFile := os.Getenv("PLUGIN_WEBHOOK_TLS_CERT"), os.Getenv("PLUGIN_WEBHOOK_TLS_KEY"); certFile != "" || keyFile != "" {
+		pluginWebhookTLS, err = webhook.NewServerTLSConfig(certFile, keyFile)
+		problems.add("PLUGIN_WEBHOOK_TLS_CERT/PLUGIN_WEBHOOK_TLS_KEY", err)
+	}
+	if certFile, keyFile := os.Getenv("HEALTH_TLS_CERT"), os.Getenv("HEALTH_TLS_KEY"); certFile != "" || keyFile != "" {
+		healthTLS, err = webhook.NewServerTLSConfig(certFile, keyFile)
+		problems.add("HEALTH_TLS_CERT/HEALTH_TLS_KEY", err)
+	}
+
+	// Optional AES-GCM encryption of the state, outbox, dead letter and audit
+	// log files
+	err = setupEncryption(os.Getenv("STATE_ENCRYPTION_KEY"), os.Getenv("STATE_ENCRYPTION_KEY_FILE"))
+	problems.add("STATE_ENCRYPTION_KEY/STATE_ENCRYPTION_KEY_FILE", err)
+	problems.add("STATE_BACKEND", state.CheckBackend(stateBackend))
+
+	// Parse bot accounts
+	accounts, err := config.ParseAccountConfigs()
+	if err == nil && len(accounts) == 0 {
+		err = fmt.Errorf("no bot accounts configured, set TELEGRAM_BOT_TOKEN + TELEGRAM_CHAT_ID or TELEGRAM_ACCOUNTS")
+	}
+	problems.add("Accounts", err)
+
+	// OpenCode servers chats can switch between with /server; the first is
+	// the default. Without OPENCODE_SERVERS there is only OPENCODE_BASE_URL.
+	serverConfigs, err := config.ParseServerConfigs()
+	problems.add("OPENCODE_SERVERS", err)
+	if len(serverConfigs) == 0 {
+		serverConfigs = []config.ServerConfig{{Name: "default", BaseURL: ocBaseURL}}
+	}
+	addLogSecrets(accounts, serverConfigs)
+
+	language, ok := i18n.Parse(languageStr)
+	if !ok {
+		logger.Warn("Unsupported TELEGRAM_LANGUAGE, using the default", "value", languageStr, "default", i18n.Default)
+		language = i18n.Default
+	}
+
+	notifyPolicy, ok := bridge.ParseNotificationPolicy(notifyStr)
+	if !ok {
+		logger.Warn("Unsupported TELEGRAM_NOTIFY, using the default", "value", notifyStr, "default", bridge.NotifyAll)
+	}
+
+	debounceDuration := parseDebounce(debounceStr)
+
+	// Minimum spacing between send/edit requests per chat (Telegram flood limits)
+	sendIntervalMs, err := strconv.ParseInt(sendIntervalStr, 10, 64)
+	if err != nil || sendIntervalMs < 0 {
+		sendIntervalMs = telegram.DefaultSendInterval.Milliseconds()
+	}
+	sendInterval := time.Duration(sendIntervalMs) * time.Millisecond
+
+	// Retries for idempotent OpenCode requests and the circuit breaker that
+	// fails fast after consecutive failures (threshold 0 disables it)
+	retryPolicy := opencode.DefaultRetryPolicy
+	if n, err := strconv.Atoi(ocMaxRetriesStr); err == nil && n >= 0 {
+		retryPolicy.MaxRetries = n
+	}
+	breakerThreshold, err := strconv.Atoi(breakerThresholdStr)
+	if err != nil || breakerThreshold < 0 {
+		breakerThreshold = opencode.DefaultBreakerThreshold
+	}
+	breakerCooldown := opencode.DefaultBreakerCooldown
+	if sec, err := strconv.Atoi(breakerCooldownStr); err == nil && sec > 0 {
+		breakerCooldown = time.Duration(sec) * time.Second
+	}
+
+	// Per-endpoint OpenCode timeouts in seconds (0 = no limit)
+	ocTimeouts := opencode.Timeouts{
+		Default:  getenvSeconds("OPENCODE_TIMEOUT_DEFAULT_SEC", opencode.DefaultTimeouts.Default),
+		Health:   getenvSeconds("OPENCODE_TIMEOUT_HEALTH_SEC", opencode.DefaultTimeouts.Health),
+		Prompt:   getenvSeconds("OPENCODE_TIMEOUT_PROMPT_SEC", opencode.DefaultTimeouts.Prompt),
+		Messages: getenvSeconds("OPENCODE_TIMEOUT_MESSAGES_SEC", opencode.DefaultTimeouts.Messages),
+	}
+
+	// Connection reuse to OpenCode (gzip responses are always requested)
+	ocKeepAlive := opencode.DefaultKeepAlive
+	if n, err := strconv.Atoi(os.Getenv("OPENCODE_MAX_IDLE_CONNS")); err == nil && n > 0 {
+		ocKeepAlive.MaxIdleConnsPerHost = n
+	}
+	ocKeepAlive.IdleConnTimeout = getenvSeconds("OPENCODE_IDLE_CONN_TIMEOUT_SEC", ocKeepAlive.IdleConnTimeout)
+
+	// Silence after which the SSE stream is considered dead and reconnected
+	sseStaleTimeout := getenvSeconds("OPENCODE_SSE_STALE_SEC", opencode.DefaultSSEStaleTimeout)
+
+	// Drop SSE events of sessions no chat is using (e.g. TUI sessions on the
+	// same server) instead of processing their delta storms
+	sseSessionFilter := getenv("OPENCODE_SSE_SESSION_FILTER", "false") == "true"
+
+	// Read the event stream over a WebSocket at this path instead, where
+	// proxies buffer SSE responses
+	eventWebSocketPath := os.Getenv("OPENCODE_EVENT_WEBSOCKET_PATH")
+
+	// Debugging aid: report events that no longer match the known schema
+	eventStrict := getenv("OPENCODE_EVENT_STRICT", "false") == "true"
+
+	// How long shutdown may take to hand over buffered messages and events
+	shutdownTimeout := getenvSeconds("SHUTDOWN_TIMEOUT_SEC", 10*time.Second)
+
+	// In-flight entries left behind by sessions that errored are dropped
+	// after this long (0: never)
+	entryTTL := getenvSeconds("BRIDGE_ENTRY_TTL_SEC", time.Hour)
+
+	// Events waiting for the bridge before streaming updates get dropped
+	sseBacklog := opencode.DefaultEventBacklog
+	if n, err := strconv.Atoi(os.Getenv("OPENCODE_SSE_EVENT_BACKLOG")); err == nil {
+		sseBacklog = n
+	}
+
+	var feedbackChatID int64
+	if feedbackChatStr != "" {
+		if feedbackChatID, err = strconv.ParseInt(feedbackChatStr, 10, 64); err != nil {
+			problems.add("TELEGRAM_FEEDBACK_CHAT_ID", fmt.Errorf("invalid chat ID %q", feedbackChatStr))
+		}
+	}
+	var alertChatID int64
+	if alertChatStr != "" {
+		if alertChatID, err = strconv.ParseInt(alertChatStr, 10, 64); err != nil {
+			problems.add("TELEGRAM_ALERT_CHAT_ID", fmt.Errorf("invalid chat ID %q", alertChatStr))
+		}
+	}
+	errorSink, err := errorReportSink()
+	problems.add("SENTRY_DSN", err)
+
+	// Telegram and OpenCode requests each go through their own proxy, if any
+	transport, err := proxyTransport(proxyURL)
+	problems.add("TELEGRAM_PROXY", err)
+	ocProxyTransport, err := proxyTransport(ocProxyURL)
+	problems.add("OPENCODE_PROXY", err)
+	var ocTLSConfig *tls.Config
+	if ocTLSFiles.Enabled() {
+		ocTLSConfig, err = opencode.NewTLSConfig(ocTLSFiles)
+		problems.add("OPENCODE_CA_FILE/OPENCODE_CLIENT_CERT/OPENCODE_CLIENT_KEY", err)
+	}
+
+	healthPort := getenv("HEALTH_PORT", "8080")
+	problems.add("Ports", checkPorts(serverListeners(healthPort, pluginWebhookPort, usePlugin, webhookURL, webhookPort, accounts)))
+
+	problems.fatal()
+
+	return &settings{
+		accounts:              accounts,
+		serverConfigs:         serverConfigs,
+		logLevel:              logLevel,
+		debounceDuration:      debounceDuration,
+		shutdownTimeout:       shutdownTimeout,
+		ocDirectory:           ocDirectory,
+		ocAPIKey:              ocAPIKey,
+		ocTLSFiles:            ocTLSFiles,
+		ocTLSConfig:           ocTLSConfig,
+		ocProxyURL:            ocProxyURL,
+		ocProxyTransport:      ocProxyTransport,
+		retryPolicy:           retryPolicy,
+		breakerThreshold:      breakerThreshold,
+		breakerCooldown:       breakerCooldown,
+		ocTimeouts:            ocTimeouts,
+		ocKeepAlive:           ocKeepAlive,
+		useSSE:                useSSE,
+		sseStaleTimeout:       sseStaleTimeout,
+		sseSessionFilter:      sseSessionFilter,
+		sseBacklog:            sseBacklog,
+		eventWebSocketPath:    eventWebSocketPath,
+		eventStrict:           eventStrict,
+		offsetFile:            offsetFile,
+		stateFile:             stateFile,
+		outboxFile:            outboxFile,
+		auditLogFile:          auditLogFile,
+		transport:             transport,
+		sendIntervalMs:        sendIntervalMs,
+		usePlugin:             usePlugin,
+		pluginWebhookPort:     pluginWebhookPort,
+		pluginWebhookToken:    pluginWebhookToken,
+		pluginWebhookRate:     pluginWebhookRate,
+		pluginWebhookWorkers:  pluginWebhookWorkers,
+		pluginWebhookMaxBody:  pluginWebhookMaxBody,
+		pluginWebhookNetworks: pluginWebhookNetworks,
+		pluginWebhookTLS:      pluginWebhookTLS,
+		deadLetterFile:        deadLetterFile,
+		healthPort:            healthPort,
+		healthTLS:             healthTLS,
+		alertChatID:           alertChatID,
+		alertAfter:            alertAfter,
+		errorSink:             errorSink,
+		bot: botOptions{
+			quickKeyboard:     quickKeyboard,
+			language:          language,
+			transcriber:       transcriber,
+			frameExtractor:    frameExtractor,
+			successReaction:   successReaction,
+			failureReaction:   failureReaction,
+			notifyPolicy:      notifyPolicy,
+			deletePlaceholder: deletePlaceholder,
+			perUserSessions:   perUserSessions,
+			sendInterval:      sendInterval,
+			entryTTL:          entryTTL,
+			showMore:          showMore,
+			responseActions:   responseActions,
+			sessionBanner:     sessionBanner,
+			showReasoning:     showReasoning,
+			photoPrompt:       photoPrompt,
+			feedbackChatID:    feedbackChatID,
+			feats:             feats,
+			stateBackend:      stateBackend,
+			webhookURL:        webhookURL,
+			webhookPort:       webhookPort,
+			proxyURL:          proxyURL,
+		},
+	}
+}
+
+// logSettings logs the configuration the bridge starts with; secrets only
+// as whether they are set
+func logSettings(s *settings) {
+	build := buildinfo.Get()
+	logger.Info("Starting OpenCode-Telegram Bridge", "version", build.Version, "commit", build.Commit, "built", build.Date)
+	for _, srv := range s.serverConfigs {
+		logger.Info("OpenCode server", "server", srv.Name, "url", srv.BaseURL)
+	}
+	logger.Info("OpenCode settings",
+		"directory", s.ocDirectory,
+		"api_key", s.ocAPIKey != "",
+		"retries", s.retryPolicy.MaxRetries,
+		"breaker_failures", s.breakerThreshold,
+		"breaker_cooldown", s.breakerCooldown,
+		"idle_conns", s.ocKeepAlive.MaxIdleConnsPerHost,
+		"idle_timeout", s.ocKeepAlive.IdleConnTimeout,
+		"timeout_default", s.ocTimeouts.Default,
+		"timeout_health", s.ocTimeouts.Health,
+		"timeout_prompt", s.ocTimeouts.Prompt,
+		"timeout_messages", s.ocTimeouts.Messages)
+	logger.Info("Bridge settings",
+		"accounts", len(s.accounts),
+		"debounce", s.debounceDuration,
+		"send_interval_ms", s.sendIntervalMs,
+		"shutdown_timeout", s.shutdownTimeout,
+		"entry_ttl", s.bot.entryTTL,
+		"features", s.bot.feats.String())
+	logger.Info("Event sources", "plugin", s.usePlugin, "plugin_port", s.pluginWebhookPort, "sse", s.useSSE)
+	if s.usePlugin {
+		logger.Info("Plugin webhook",
+			"token", s.pluginWebhookToken != "",
+			"allowed_networks", s.pluginWebhookNetworks,
+			"https", s.pluginWebhookTLS != nil,
+			"rate", s.pluginWebhookRate,
+			"max_body_bytes", s.pluginWebhookMaxBody,
+			"workers", s.pluginWebhookWorkers)
+	}
+	if s.usePlugin && s.useSSE {
+		logger.Info("SSE with plugin: duplicate events dropped")
+	}
+	if s.useSSE {
+		logger.Info("SSE",
+			"stale_timeout", s.sseStaleTimeout,
+			"session_filter", s.sseSessionFilter,
+			"backlog", s.sseBacklog,
+			"websocket", s.eventWebSocketPath,
+			"strict", s.eventStrict)
+	}
+	logger.Info("Chat settings",
+		"quick_keyboard", s.bot.quickKeyboard,
+		"language", s.bot.language,
+		"notifications", s.bot.notifyPolicy,
+		"delete_placeholder", s.bot.deletePlaceholder,
+		"per_user_sessions", s.bot.perUserSessions,
+		"show_more", s.bot.showMore,
+		"show_reasoning", s.bot.showReasoning,
+		"photo_prompt", s.bot.photoPrompt,
+		"feedback_chat", s.bot.feedbackChatID,
+		"alert_chat", s.alertChatID,
+		"response_actions", s.bot.responseActions,
+		"session_banner", s.bot.sessionBanner,
+		"completion_reactions", s.bot.successReaction != "" || s.bot.failureReaction != "",
+		"transcription", s.bot.transcriber != nil,
+		"video_keyframes", s.bot.frameExtractor != nil)
+	if s.transport != nil {
+		logger.Info("Telegram proxy", "proxy", s.bot.proxyURL)
+	}
+	if s.ocProxyTransport != nil {
+		logger.Info("OpenCode proxy", "proxy", s.ocProxyURL)
+	}
+	if s.bot.webhookURL != "" {
+		logger.Info("Webhook mode", "url", s.bot.webhookURL, "port", s.bot.webhookPort)
+	} else {
+		logger.Info("Polling mode")
+	}
+}
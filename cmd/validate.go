@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/user/opencode-telegram/internal/config"
+	"github.com/user/opencode-telegram/internal/features"
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+	"github.com/user/opencode-telegram/internal/webhook"
+)
+
+// validation prints the outcome of each check and counts the failed ones
+type validation struct {
+	w      io.Writer
+	failed int
+}
+
+// check reports a check: ok with detail, or the error and a hint to fix it
+func (v *validation) check(name string, err error, detail, hint string) bool {
+	if err != nil {
+		v.failed++
+		fmt.Fprintf(v.w, "✗ %s: %v\n", name, err)
+		if hint != "" {
+			fmt.Fprintf(v.w, "  → %s\n", hint)
+		}
+		return false
+	}
+	if detail != "" {
+		fmt.Fprintf(v.w, "✓ %s: %s\n", name, detail)
+	} else {
+		fmt.Fprintf(v.w, "✓ %s\n", name)
+	}
+	return true
+}
+
+// validateConfig checks the configuration the bridge would start with: it
+// parses the settings, reaches OpenCode and Telegram, and checks the files
+// the bridge writes. Every problem is reported, not only the first.
+func validateConfig(w io.Writer) error {
+	v := &validation{w: w}
+
+	encryption := "off"
+	if os.Getenv("STATE_ENCRYPTION_KEY") != "" || os.Getenv("STATE_ENCRYPTION_KEY_FILE") != "" {
+		encryption = "on"
+	}
+	err := setupEncryption(os.Getenv("STATE_ENCRYPTION_KEY"), os.Getenv("STATE_ENCRYPTION_KEY_FILE"))
+	v.check("State encryption", err, encryption, "give a base64 32-byte key, e.g. from openssl rand -base64 32")
+
+	accounts, err := config.ParseAccountConfigs()
+	if err == nil && len(accounts) == 0 {
+		err = fmt.Errorf("no bot accounts configured")
+	}
+	v.check("Accounts", err, fmt.Sprintf("%d", len(accounts)), "set TELEGRAM_BOT_TOKEN + TELEGRAM_CHAT_ID, or TELEGRAM_ACCOUNTS as a JSON list of {token, chat_id, name}")
+
+	if feedbackChat := os.Getenv("TELEGRAM_FEEDBACK_CHAT_ID"); feedbackChat != "" {
+		_, err = strconv.ParseInt(feedbackChat, 10, 64)
+		v.check("TELEGRAM_FEEDBACK_CHAT_ID", err, feedbackChat, "use a numeric chat ID, e.g. from @userinfobot")
+	}
+	if alertChat := os.Getenv("TELEGRAM_ALERT_CHAT_ID"); alertChat != "" {
+		_, err = strconv.ParseInt(alertChat, 10, 64)
+		v.check("TELEGRAM_ALERT_CHAT_ID", err, alertChat, "use a numeric chat ID, e.g. from @userinfobot")
+	}
+
+	if os.Getenv("SENTRY_DSN") != "" {
+		_, err = errorReportSink()
+		v.check("SENTRY_DSN", err, "set", "copy the DSN from the Sentry project's Client Keys settings")
+	}
+
+	level, err := parseLogLevel()
+	v.check("LOG_LEVEL", err, level.String(), "use debug, info, warn or error")
+
+	format, err := parseLogFormat()
+	v.check("LOG_FORMAT", err, format, "use text or json")
+
+	feats, err := parseFeatures()
+	v.check("FEATURES", err, feats.String(), "list name=true or name=false pairs separated by commas")
+
+	listeners := serverListeners(getenv("HEALTH_PORT", "8080"), getenv("PLUGIN_WEBHOOK_PORT", "8888"), feats.Enabled(features.PluginMode),
+		os.Getenv("TELEGRAM_WEBHOOK_URL"), getenv("TELEGRAM_WEBHOOK_PORT", "8443"), accounts)
+	v.check("Ports", checkPorts(listeners), fmt.Sprintf("%d servers", len(listeners)), "give HEALTH_PORT, PLUGIN_WEBHOOK_PORT and the webhook ports distinct values")
+
+	_, err = webhook.ParseNetworks(os.Getenv("PLUGIN_WEBHOOK_ALLOWED_CIDRS"))
+	v.check("PLUGIN_WEBHOOK_ALLOWED_CIDRS", err, "", "list CIDRs or addresses separated by commas")
+
+	// OpenCode and Telegram are reached through their proxies, like the
+	// bridge does
+	if proxyURL := os.Getenv("TELEGRAM_PROXY"); proxyURL != "" {
+		_, err := proxyTransport(proxyURL)
+		v.check("TELEGRAM_PROXY", err, proxyURL, "use an http://, https:// or socks5:// URL")
+	}
+	transport := opencode.NewTransport()
+	if proxyURL := openCodeProxy(); proxyURL != "" {
+		ocTransport, err := proxyTransport(proxyURL)
+		if v.check("OpenCode proxy", err, proxyURL, "use an http://, https:// or socks5:// URL, or none, in OPENCODE_PROXY") && ocTransport != nil {
+			transport = ocTransport
+		}
+	}
+	tlsFiles := opencode.TLSFiles{
+		CAFile:   os.Getenv("OPENCODE_CA_FILE"),
+		CertFile: os.Getenv("OPENCODE_CLIENT_CERT"),
+		KeyFile:  os.Getenv("OPENCODE_CLIENT_KEY"),
+	}
+	if tlsFiles.Enabled() {
+		tlsConfig, err := opencode.NewTLSConfig(tlsFiles)
+		if v.check("OpenCode TLS files", err, "", "check OPENCODE_CA_FILE, OPENCODE_CLIENT_CERT and OPENCODE_CLIENT_KEY") {
+			transport.TLSClientConfig = tlsConfig
+		}
+	}
+
+	servers, err := openCodeServers()
+	if v.check("OpenCode servers", err, fmt.Sprintf("%d", len(servers)), "OPENCODE_SERVERS must be a JSON list of {name, base_url, directory, api_key}") {
+		for _, srv := range servers {
+			client := opencode.NewClientWithTransport(opencode.Config{BaseURL: srv.BaseURL, Directory: srv.Directory, APIKey: srv.APIKey}, transport)
+			_, err := client.Health()
+			v.check(fmt.Sprintf("OpenCode %s (%s)", srv.Name, srv.BaseURL), err, "healthy",
+				"start OpenCode with `opencode serve` and check the URL"+apiKeyHint(err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+	for i, acc := range accounts {
+		name := acc.Name
+		if name == "" {
+			name = fmt.Sprintf("account-%d", i)
+		}
+		client, err := telegramClient(telegramProxy(acc, os.Getenv("TELEGRAM_PROXY")))
+		if err != nil {
+			// Reported by the proxy checks above
+			continue
+		}
+		username, err := telegram.CheckToken(ctx, acc.Token, client)
+		v.check(fmt.Sprintf("Bot %s", name), err, "@"+username, "check the token with @BotFather and that api.telegram.org is reachable")
+	}
+
+	offsetFile := getenv("TELEGRAM_OFFSET_FILE", "~/.opencode-telegram-offset")
+	stateFile := getenv("TELEGRAM_STATE_FILE", "~/.opencode-telegram-state")
+	files := []string{
+		getenv("TELEGRAM_OUTBOX_FILE", "~/.opencode-telegram-outbox"),
+		getenv("PLUGIN_WEBHOOK_DEAD_LETTER_FILE", "~/.opencode-telegram-deadletters"),
+	}
+	if auditLog := os.Getenv("AUDIT_LOG_FILE"); auditLog != "" {
+		files = append(files, auditLog)
+	}
+	for _, spec := range botSpecs(accounts, offsetFile, stateFile, "") {
+		files = append(files, spec.offsetFile)
+		_, err := state.PlanStateMigration(getenv("STATE_BACKEND", state.BackendFile), spec.stateFile)
+		if err == nil {
+			err = state.CheckWritable(spec.stateFile)
+		}
+		v.check("State file "+spec.stateFile, err, "writable", "check the file's permissions and STATE_ENCRYPTION_KEY")
+	}
+	for _, file := range files {
+		v.check("File "+file, state.CheckWritable(file), "writable", "check the directory's permissions")
+	}
+
+	if v.failed > 0 {
+		return fmt.Errorf("%d problems found", v.failed)
+	}
+	fmt.Fprintln(w, "Configuration is valid")
+	return nil
+}
+
+// apiKeyHint adds advice for a rejected API key
+func apiKeyHint(err error) string {
+	var apiErr *opencode.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return "; the server rejected OPENCODE_API_KEY"
+	}
+	return ""
+}
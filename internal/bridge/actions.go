@@ -0,0 +1,59 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// responseActionPrefix prefixes callback_data of the response action buttons
+const responseActionPrefix = "act:"
+
+// SetResponseActions attaches Continue / Retry / New session / Explain more
+// buttons to the last message of every completed response
+func (b *Bridge) SetResponseActions(enabled bool) {
+	b.responseActions = enabled
+}
+
+// responseActionsKeyboard returns the keyboard for the end of a response,
+// or nil when response actions are disabled
+func (b *Bridge) responseActionsKeyboard() *models.InlineKeyboardMarkup {
+	if !b.responseActions {
+		return nil
+	}
+	return telegram.BuildResponseActionsKeyboard(b.lang())
+}
+
+// sendChunk sends a response chunk, with keyboard attached when non-nil
+func (b *Bridge) sendChunk(ctx context.Context, text string, keyboard *models.InlineKeyboardMarkup) (int, error) {
+	if keyboard != nil {
+		return b.tgBot.SendMessageWithKeyboard(ctx, text, keyboard)
+	}
+	return b.tgBot.SendMessage(ctx, text)
+}
+
+// HandleResponseAction runs a response action button press.
+// Canned prompts go through HandleUserMessage like typed text.
+func (b *Bridge) HandleResponseAction(ctx context.Context, data string) error {
+	switch action := strings.TrimPrefix(data, responseActionPrefix); action {
+	case telegram.ResponseActionContinue:
+		return b.HandleUserMessage(ctx, b.t("action.prompt.continue"))
+	case telegram.ResponseActionExplain:
+		return b.HandleUserMessage(ctx, b.t("action.prompt.explain"))
+	case telegram.ResponseActionRetry:
+		prompt, ok := b.lastPrompts.Load(b.sessions.current(ctx))
+		if !ok {
+			_, err := b.tgBot.SendMessage(ctx, b.t("action.retry_none"))
+			return err
+		}
+		return b.HandleUserMessage(ctx, prompt.(string))
+	case telegram.ResponseActionNew:
+		return b.cmdHandler.HandleNewSession(ctx, nil)
+	default:
+		return fmt.Errorf("unknown response action: %s", action)
+	}
+}
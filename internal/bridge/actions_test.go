@@ -0,0 +1,87 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// bufferedPrompts returns the debounced messages waiting for a session
+func bufferedPrompts(b *Bridge, sessionID string) []string {
+	val, ok := b.debounceBuffers.Load(sessionID)
+	if !ok {
+		return nil
+	}
+	buf := val.(*DebounceBuffer)
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if buf.timer != nil {
+		buf.timer.Stop()
+	}
+	return buf.messages
+}
+
+func TestDeliverFinalAttachesResponseActions(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetResponseActions(true)
+	ctx := context.Background()
+
+	isActions := mock.MatchedBy(func(kb *models.InlineKeyboardMarkup) bool {
+		return len(kb.InlineKeyboard) == 2 && kb.InlineKeyboard[0][0].CallbackData == "act:continue"
+	})
+
+	// Single chunk: the placeholder edit carries the buttons
+	mockTG.On("EditMessageWithKeyboard", ctx, 5, "done", isActions).Return(nil).Once()
+	bridge.deliverFinal(ctx, "ses_1", 5, []string{"done"})
+
+	// Several chunks: only the last one carries the buttons
+	mockTG.On("EditMessage", ctx, 6, "part 1").Return(nil).Once()
+	mockTG.On("SendMessage", ctx, "part 2").Return(7, nil).Once()
+	mockTG.On("SendMessageWithKeyboard", ctx, "part 3", isActions).Return(8, nil).Once()
+	bridge.deliverFinal(ctx, "ses_1", 6, []string{"part 1", "part 2", "part 3"})
+
+	mockTG.AssertExpectations(t)
+}
+
+func TestResponseActionCannedPrompts(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_1")
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, bridge.HandleResponseAction(ctx, "act:continue"))
+	require.NoError(t, bridge.HandleResponseAction(ctx, "act:explain"))
+
+	assert.Equal(t, []string{"Continue", "Explain your last answer in more detail"}, bufferedPrompts(bridge, "ses_1"))
+}
+
+func TestResponseActionRetry(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_1")
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "⚠️ Nothing to retry in this session").Return(1, nil).Once()
+	require.NoError(t, bridge.HandleResponseAction(ctx, "act:retry"))
+	mockTG.AssertExpectations(t)
+
+	bridge.lastPrompts.Store("ses_1", "fix the build")
+	require.NoError(t, bridge.HandleResponseAction(ctx, "act:retry"))
+	assert.Equal(t, []string{"fix the build"}, bufferedPrompts(bridge, "ses_1"))
+}
+
+func TestResponseActionUnknown(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+
+	assert.Error(t, bridge.HandleResponseAction(context.Background(), "act:dance"))
+}
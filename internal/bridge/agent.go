@@ -33,6 +33,7 @@ type AgentHandler struct {
 	ocClient agentOpenCodeClient
 	tgBot    agentTelegramBot
 	appState agentAppState
+	translator
 }
 
 // NewAgentHandler creates a new AgentHandler
@@ -59,7 +60,7 @@ func (h *AgentHandler) HandleSwitch(ctx context.Context, agent *string) error {
 		// Build keyboard with agent buttons
 		keyboard := buildAgentKeyboard(agents)
 
-		msg := "🤖 Select an OHO Agent:\n\n"
+		msg := h.t("agent.select")
 		for i, a := range agents {
 			msg += fmt.Sprintf("%d. %s\n", i+1, a)
 		}
@@ -76,7 +77,7 @@ func (h *AgentHandler) HandleSwitch(ctx context.Context, agent *string) error {
 
 	if !isValidAgent(*agent, agents) {
 		// Show error with available options
-		msg := fmt.Sprintf("❌ Unknown agent: %s\n\nAvailable agents:\n", *agent)
+		msg := h.t("agent.unknown", *agent)
 		for _, a := range agents {
 			msg += fmt.Sprintf("• %s\n", a)
 		}
@@ -89,7 +90,7 @@ func (h *AgentHandler) HandleSwitch(ctx context.Context, agent *string) error {
 	h.appState.SetCurrentAgent(*agent)
 
 	// Confirm
-	msg := fmt.Sprintf("🔄 Switched to %s", *agent)
+	msg := h.t("agent.switched", *agent)
 	_, err = h.tgBot.SendMessage(ctx, msg)
 	return err
 }
@@ -110,29 +111,36 @@ func (h *AgentHandler) HandleAgentCallback(ctx context.Context, msgID int, agent
 	h.appState.SetCurrentAgent(agentName)
 
 	// Edit message to confirm
-	msg := fmt.Sprintf("🔄 Switched to %s", agentName)
+	msg := h.t("agent.switched", agentName)
 	return h.tgBot.EditMessage(ctx, msgID, msg)
 }
 
 // GetAvailableAgents returns the list of available OHO agents
-// Tries to fetch from OpenCode client first, then oh-my-opencode.json, then hardcoded list
 func (h *AgentHandler) GetAvailableAgents(ctx context.Context) ([]string, error) {
-	// Try to fetch from OpenCode client (if it implements GetAgents)
-	if h.ocClient != nil {
-		agents, err := h.ocClient.GetAgents()
+	return availableAgents(h.ocClient), nil
+}
+
+// availableAgents fetches the agent list from the OpenCode API first,
+// then oh-my-opencode.json, then the hardcoded list
+func availableAgents(ocClient agentOpenCodeClient) []string {
+	if ocClient != nil {
+		agents, err := ocClient.GetAgents()
 		if err == nil && len(agents) > 0 {
-			return agents, nil
+			return agents
+		}
+		if err != nil {
+			logger.Warn("Failed to fetch agents from OpenCode, using config", "error", err)
 		}
 	}
 
 	// Try to fetch from oh-my-opencode.json
 	agents, err := loadAgentsFromConfig()
 	if err == nil && len(agents) > 0 {
-		return agents, nil
+		return agents
 	}
 
 	// Fallback: return hardcoded list
-	return getDefaultAgents(), nil
+	return getDefaultAgents()
 }
 
 // buildAgentKeyboard creates an Inline Keyboard from agent list
@@ -2,7 +2,14 @@ package bridge
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/state"
 )
 
 // Mock clients for agent tests
@@ -231,6 +238,26 @@ func TestListAgents(t *testing.T) {
 	}
 }
 
+func TestSwitchCommandUsesOpenCodeAgents(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("GetAgents").Return([]string{"build", "plan"}, nil)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+
+	bridge.handleSwitchCommand(ctx, "plan")
+	assert.Equal(t, "plan", appState.GetCurrentAgent())
+
+	bridge.handleSwitchCommand(ctx, "oracle")
+	assert.Equal(t, "plan", appState.GetCurrentAgent())
+	mockTG.AssertCalled(t, "SendMessage", ctx, mock.MatchedBy(func(msg string) bool {
+		return strings.Contains(msg, "• build\n• plan")
+	}))
+}
+
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) >= len(substr))
 }
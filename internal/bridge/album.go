@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// albumCollectWindow is how long to wait for the remaining photos of an album.
+// Telegram delivers each album item as a separate update, usually within a few hundred ms.
+const albumCollectWindow = 1 * time.Second
+
+// AlbumBuffer collects the photos of one media group until the album is complete
+type AlbumBuffer struct {
+	photos    [][]models.PhotoSize
+	caption   string
+	messageID int   // latest album item, used as the triggering message
+	userID    int64 // sender, whose session the album goes to
+	timer     *time.Timer
+	mu        sync.Mutex
+}
+
+// HandleAlbumPhoto buffers a photo that belongs to a media group.
+// The album is sent as a single prompt once no new photo arrived for albumCollectWindow.
+func (b *Bridge) HandleAlbumPhoto(ctx context.Context, mediaGroupID string, photos []models.PhotoSize, caption string, botToken string) {
+	val, _ := b.albums.LoadOrStore(mediaGroupID, &AlbumBuffer{})
+	buf := val.(*AlbumBuffer)
+
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+
+	buf.photos = append(buf.photos, photos)
+	if messageID, ok := telegram.MessageIDFromContext(ctx); ok {
+		buf.messageID = messageID
+	}
+	if userID, ok := telegram.UserIDFromContext(ctx); ok {
+		buf.userID = userID
+	}
+	// Telegram attaches the album caption to a single item, typically the first
+	if buf.caption == "" {
+		buf.caption = caption
+	}
+
+	if buf.timer != nil {
+		buf.timer.Stop()
+	}
+	buf.timer = time.AfterFunc(albumCollectWindow, func() {
+		b.flushAlbum(mediaGroupID, botToken)
+	})
+}
+
+func (b *Bridge) flushAlbum(mediaGroupID string, botToken string) {
+	val, ok := b.albums.LoadAndDelete(mediaGroupID)
+	if !ok {
+		return
+	}
+
+	buf := val.(*AlbumBuffer)
+	buf.mu.Lock()
+	photos := buf.photos
+	caption := buf.caption
+	messageID := buf.messageID
+	userID := buf.userID
+	buf.mu.Unlock()
+
+	b.logger.Info("Album complete", "media_group", mediaGroupID, "photos", len(photos))
+
+	ctx := context.Background()
+	if messageID != 0 {
+		ctx = telegram.WithMessageID(ctx, messageID)
+	}
+	if userID != 0 {
+		ctx = telegram.WithUserID(ctx, userID)
+	}
+	if err := b.handlePhotos(ctx, photos, caption, botToken); err != nil {
+		b.tgBot.SendMessage(ctx, b.errorText(err))
+	}
+}
@@ -0,0 +1,68 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestAlbumPhotosSentAsSinglePrompt(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_album")
+	appState.SetChatAgent("", "plan")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.downloadFile = func(ctx context.Context, botToken, fileID string) ([]byte, error) {
+		return []byte(fileID), nil
+	}
+
+	sent := make(chan []interface{}, 1)
+	var userID int64
+	var opts opencode.PromptOptions
+	mockTG.On("SendMessage", mock.Anything, "🖼️ Processing 3 images...").
+		Run(func(args mock.Arguments) { userID, _ = telegram.UserIDFromContext(args.Get(0).(context.Context)) }).
+		Return(1, nil).Once()
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockOC.On("SendPromptWithParts", "ses_album", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			opts = args.Get(2).(opencode.PromptOptions)
+			sent <- args.Get(1).([]interface{})
+		}).
+		Return(&opencode.SendPromptResponse{}, nil).Once()
+
+	photo := func(id string) []models.PhotoSize {
+		return []models.PhotoSize{{FileID: id + "_small", Width: 90, Height: 90}, {FileID: id, Width: 800, Height: 600}}
+	}
+	ctx := telegram.WithUserID(context.Background(), 42)
+	bridge.HandleAlbumPhoto(ctx, "grp1", photo("p1"), "", "token")
+	bridge.HandleAlbumPhoto(ctx, "grp1", photo("p2"), "Compare these screenshots", "token")
+	bridge.HandleAlbumPhoto(ctx, "grp1", photo("p3"), "", "token")
+
+	select {
+	case parts := <-sent:
+		assert.Len(t, parts, 4)
+		for i, id := range []string{"p1", "p2", "p3"} {
+			img, ok := parts[i].(opencode.ImagePartInput)
+			if assert.True(t, ok, "part %d should be an image", i) {
+				assert.Equal(t, "image", img.Type)
+				assert.NotEmpty(t, img.Image, id)
+			}
+		}
+		assert.Equal(t, opencode.TextPartInput{Type: "text", Text: "Compare these screenshots"}, parts[3])
+		assert.Equal(t, "plan", opts.Agent, "expected the chat's agent")
+	case <-time.After(3 * time.Second):
+		t.Fatal("album was not flushed")
+	}
+
+	mockTG.AssertNumberOfCalls(t, "SendMessage", 1)
+	assert.Equal(t, int64(42), userID, "expected the album to be sent as its sender")
+}
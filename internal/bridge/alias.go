@@ -0,0 +1,170 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// aliasNamePattern restricts alias names to what Telegram accepts as a command
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// registerCommand registers a built-in command with the bot and keeps its
+// handler so aliases can dispatch to it
+func (b *Bridge) registerCommand(name string, handler telegram.CommandHandler) {
+	if b.commands == nil {
+		b.commands = make(map[string]telegram.CommandHandler)
+	}
+	b.commands[name] = handler
+	b.tgBot.(*telegram.Bot).RegisterCommandHandler(name, handler)
+}
+
+// isAlias reports whether command is an alias defined in this chat
+func (b *Bridge) isAlias(command string) bool {
+	_, ok := b.state.GetAlias(b.chatID, command)
+	return ok
+}
+
+// HandleAliasCommand manages the chat's command aliases.
+// Usage:
+//
+//	/alias add <name> <expansion>
+//	/alias rm <name>
+//	/alias list
+//
+// An expansion is one or more steps separated by ";". A step is either a
+// built-in command ("/switch build") or text sent as a prompt ("continue").
+func (b *Bridge) HandleAliasCommand(ctx context.Context, args string) error {
+	sub, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+
+	var msg string
+	switch sub {
+	case "add":
+		name, expansion, _ := strings.Cut(rest, " ")
+		name = strings.TrimPrefix(name, "/")
+		expansion = strings.TrimSpace(expansion)
+		if name == "" || expansion == "" {
+			msg = b.t("alias.usage")
+			break
+		}
+		if !aliasNamePattern.MatchString(name) {
+			msg = b.t("alias.invalid_name", name)
+			break
+		}
+		if b.shadowsCommand(name) {
+			msg = b.t("alias.reserved", name)
+			break
+		}
+		if cmd, ok := b.unknownStep(expansion); !ok {
+			msg = b.t("alias.unknown_command", cmd)
+			break
+		}
+		b.state.SetAlias(b.chatID, name, expansion)
+		msg = b.t("alias.added", name, html.EscapeString(expansion))
+	case "rm":
+		name := strings.TrimPrefix(rest, "/")
+		if !b.state.RemoveAlias(b.chatID, name) {
+			msg = b.t("alias.not_found", name)
+			break
+		}
+		msg = b.t("alias.removed", name)
+	case "list":
+		msg = b.formatAliases()
+	default:
+		msg = b.t("alias.usage")
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, msg)
+	return err
+}
+
+// shadowsCommand reports whether an alias name would collide with a built-in
+// command. Commands are matched by prefix, so "/statusx" already runs /status
+// and such names are rejected too.
+func (b *Bridge) shadowsCommand(name string) bool {
+	for command := range b.commands {
+		if strings.HasPrefix(name, command) {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownStep returns the first command step of an expansion that is not a
+// built-in command. Aliases cannot call other aliases.
+func (b *Bridge) unknownStep(expansion string) (string, bool) {
+	for _, step := range strings.Split(expansion, ";") {
+		command, _, ok := telegram.ParseCommand(strings.TrimSpace(step))
+		if !ok {
+			continue
+		}
+		if _, known := b.commands[command]; !known {
+			return command, false
+		}
+	}
+	return "", true
+}
+
+func (b *Bridge) formatAliases() string {
+	aliases := b.state.ListAliases(b.chatID)
+	if len(aliases) == 0 {
+		return b.t("alias.list_empty")
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(b.t("alias.list_title"))
+	for _, name := range names {
+		fmt.Fprintf(&sb, "\n/%s → %s", name, html.EscapeString(aliases[name]))
+	}
+	return sb.String()
+}
+
+// runAlias expands an alias and runs its steps in order. Arguments given to
+// the alias are appended to the last step, so with "/r → /session" the
+// message "/r ses_1" runs "/session ses_1".
+func (b *Bridge) runAlias(ctx context.Context, name string, args string) {
+	expansion, ok := b.state.GetAlias(b.chatID, name)
+	if !ok {
+		return
+	}
+
+	steps := strings.Split(expansion, ";")
+	if args != "" {
+		steps[len(steps)-1] += " " + args
+	}
+
+	for _, step := range steps {
+		step = strings.TrimSpace(step)
+		if step == "" {
+			continue
+		}
+
+		command, stepArgs, isCommand := telegram.ParseCommand(step)
+		if !isCommand {
+			if err := b.HandleUserMessage(ctx, step); err != nil {
+				b.tgBot.SendMessage(ctx, b.errorText(err))
+				return
+			}
+			continue
+		}
+
+		handler, known := b.commands[command]
+		if !known {
+			b.tgBot.SendMessage(ctx, b.t("alias.unknown_command", command))
+			return
+		}
+		handler(ctx, stepArgs)
+	}
+}
@@ -0,0 +1,88 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// newAliasBridge returns a bridge with /switch and /status stubbed as
+// built-in commands, recording the calls they receive
+func newAliasBridge(t *testing.T) (*Bridge, *MockTelegramBot, *[]string) {
+	t.Helper()
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_1")
+	b := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), time.Second)
+
+	var calls []string
+	b.commands = map[string]telegram.CommandHandler{
+		"switch": func(ctx context.Context, args string) { calls = append(calls, "switch "+args) },
+		"status": func(ctx context.Context, args string) { calls = append(calls, "status") },
+	}
+	return b, mockTG, &calls
+}
+
+func TestAliasAddAndRun(t *testing.T) {
+	b, mockTG, calls := newAliasBridge(t)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "✅ Alias /b → /switch build; continue").Return(1, nil).Once()
+	require.NoError(t, b.HandleAliasCommand(ctx, "add b /switch build; continue"))
+	mockTG.AssertExpectations(t)
+
+	assert.True(t, b.isAlias("b"))
+	b.runAlias(ctx, "b", "")
+
+	assert.Equal(t, []string{"switch build"}, *calls)
+	assert.Equal(t, []string{"continue"}, bufferedPrompts(b, "ses_1"))
+}
+
+func TestAliasAppendsArgsToLastStep(t *testing.T) {
+	b, _, calls := newAliasBridge(t)
+	b.state.SetAlias(b.chatID, "sw", "/status; /switch")
+
+	b.runAlias(context.Background(), "sw", "plan")
+
+	assert.Equal(t, []string{"status", "switch plan"}, *calls)
+}
+
+func TestAliasRejectsConflictsAndUnknownCommands(t *testing.T) {
+	b, mockTG, _ := newAliasBridge(t)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "❌ /statusx conflicts with a built-in command").Return(1, nil).Once()
+	mockTG.On("SendMessage", ctx, "❌ Unknown command: /dance").Return(2, nil).Once()
+	mockTG.On("SendMessage", ctx, "❌ Invalid alias name: B! (use a-z, 0-9 and _, up to 32 characters)").Return(3, nil).Once()
+
+	require.NoError(t, b.HandleAliasCommand(ctx, "add statusx hello"))
+	require.NoError(t, b.HandleAliasCommand(ctx, "add d /dance"))
+	require.NoError(t, b.HandleAliasCommand(ctx, "add B! hello"))
+
+	mockTG.AssertExpectations(t)
+	assert.Empty(t, b.state.ListAliases(b.chatID))
+}
+
+func TestAliasListAndRemove(t *testing.T) {
+	b, mockTG, _ := newAliasBridge(t)
+	ctx := context.Background()
+	b.state.SetAlias(b.chatID, "s", "/status")
+	b.state.SetAlias(b.chatID, "c", "continue")
+
+	mockTG.On("SendMessage", ctx, "🔖 Aliases:\n/c → continue\n/s → /status").Return(1, nil).Once()
+	mockTG.On("SendMessage", ctx, "🗑 Alias /s removed").Return(2, nil).Once()
+	mockTG.On("SendMessage", ctx, "❌ No alias named /s").Return(3, nil).Once()
+
+	require.NoError(t, b.HandleAliasCommand(ctx, "list"))
+	require.NoError(t, b.HandleAliasCommand(ctx, "rm s"))
+	require.NoError(t, b.HandleAliasCommand(ctx, "rm s"))
+
+	mockTG.AssertExpectations(t)
+	assert.False(t, b.isAlias("s"))
+}
@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// Transcriber converts recorded audio into text
+type Transcriber interface {
+	Transcribe(ctx context.Context, filename string, data []byte) (string, error)
+}
+
+// SetTranscriber enables voice note and audio file support.
+// Without a transcriber, audio is answered with the unsupported media reply.
+func (b *Bridge) SetTranscriber(transcriber Transcriber) {
+	b.transcriber = transcriber
+}
+
+// HandleAudioMessage transcribes a voice note or audio file and sends the
+// transcript (with the caption, if any) to OpenCode as a prompt
+func (b *Bridge) HandleAudioMessage(ctx context.Context, audio telegram.AudioFile, caption string, botToken string) error {
+	if b.transcriber == nil {
+		return b.HandleUnsupportedMedia(ctx)
+	}
+
+	if audio.FileSize > telegram.MaxDownloadSize {
+		_, err := b.tgBot.SendMessage(ctx, b.t("audio.too_large", telegram.MaxDownloadSize/(1024*1024)))
+		return err
+	}
+
+	sessionID, thinkingMsgID, started, err := b.beginMediaPrompt(ctx, b.t("processing.audio"))
+	if !started {
+		return err
+	}
+
+	go b.transcribeAndPrompt(context.Background(), sessionID, audio, caption, botToken, thinkingMsgID)
+	return nil
+}
+
+func (b *Bridge) transcribeAndPrompt(ctx context.Context, sessionID string, audio telegram.AudioFile, caption string, botToken string, thinkingMsgID int) {
+	data, err := b.downloadFile(ctx, botToken, audio.FileID)
+	if err != nil {
+		b.failPrompt(sessionID, thinkingMsgID, b.t("audio.download_failed", err.Error()))
+		return
+	}
+
+	transcript, err := b.transcriber.Transcribe(ctx, audio.FileName, data)
+	if err != nil {
+		b.failPrompt(sessionID, thinkingMsgID, b.t("audio.transcribe_failed", err.Error()))
+		return
+	}
+	if transcript == "" {
+		b.failPrompt(sessionID, thinkingMsgID, b.t("audio.no_speech"))
+		return
+	}
+
+	b.logger.Info("Transcribed audio", "session", sessionID, "file", audio.FileName, "duration", audio.Duration, "chars", len(transcript))
+
+	b.sendPromptAsync(ctx, sessionID, formatTranscriptPrompt(audio, caption, transcript), thinkingMsgID)
+}
+
+// failPrompt reports an error in place of the thinking message and releases the session
+func (b *Bridge) failPrompt(sessionID string, thinkingMsgID int, errorMsg string) {
+	if b.freshFinal() {
+		_ = b.tgBot.DeleteMessage(context.Background(), thinkingMsgID)
+		b.tgBot.SendMessagePlain(context.Background(), errorMsg)
+	} else if editErr := b.tgBot.EditMessagePlain(context.Background(), thinkingMsgID, errorMsg); !delivered(editErr) {
+		b.logger.Error("Failed to edit error message", "session", sessionID, "error", editErr)
+		b.tgBot.SendMessagePlain(context.Background(), errorMsg)
+	}
+	b.state.SetSessionStatus(sessionID, state.SessionError)
+	b.recordError(sessionID)
+	b.clearThinking(sessionID)
+	b.reactCompletion(sessionID, false)
+}
+
+// formatTranscriptPrompt builds the prompt text sent to OpenCode
+// Example:
+//
+//	Summarise the action items
+//
+//	[Transcript of audio file "standup.m4a" (12:04)]
+//	Okay, let's get started...
+func formatTranscriptPrompt(audio telegram.AudioFile, caption string, transcript string) string {
+	var header string
+	if audio.Voice {
+		header = "[Transcript of voice note"
+	} else {
+		header = fmt.Sprintf("[Transcript of audio file %q", audio.FileName)
+	}
+	if audio.Duration > 0 {
+		header += fmt.Sprintf(" (%d:%02d)", audio.Duration/60, audio.Duration%60)
+	}
+	header += "]"
+
+	var sb strings.Builder
+	if caption = strings.TrimSpace(caption); caption != "" {
+		sb.WriteString(caption)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(header)
+	sb.WriteString("\n")
+	sb.WriteString(transcript)
+	return sb.String()
+}
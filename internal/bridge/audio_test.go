@@ -0,0 +1,64 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestFormatTranscriptPromptVoiceNote(t *testing.T) {
+	audio := telegram.AudioFile{FileName: "voice.ogg", Duration: 7, Voice: true}
+
+	text := formatTranscriptPrompt(audio, "", "fix the login bug")
+
+	assert.Equal(t, "[Transcript of voice note (0:07)]\nfix the login bug", text)
+}
+
+func TestFormatTranscriptPromptAudioWithCaption(t *testing.T) {
+	audio := telegram.AudioFile{FileName: "standup.m4a", Duration: 724}
+
+	text := formatTranscriptPrompt(audio, " Summarise the action items ", "Okay, let's get started.")
+
+	assert.Equal(t, "Summarise the action items\n\n[Transcript of audio file \"standup.m4a\" (12:04)]\nOkay, let's get started.", text)
+}
+
+func TestHandleAudioWithoutTranscriber(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "⚠️ This media type is not supported yet").Return(1, nil)
+
+	err := bridge.HandleAudioMessage(ctx, telegram.AudioFile{FileID: "v1", Voice: true}, "", "token")
+
+	assert.NoError(t, err)
+	mockTG.AssertExpectations(t)
+	mockOC.AssertNotCalled(t, "CreateSession")
+}
+
+type stubTranscriber struct{}
+
+func (stubTranscriber) Transcribe(ctx context.Context, filename string, data []byte) (string, error) {
+	return "", nil
+}
+
+func TestHandleAudioTooLarge(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetTranscriber(stubTranscriber{})
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "❌ Audio file is too large (max 20 MB)").Return(1, nil)
+
+	err := bridge.HandleAudioMessage(ctx, telegram.AudioFile{FileID: "a1", FileSize: 50 * 1024 * 1024}, "", "token")
+
+	assert.NoError(t, err)
+	mockTG.AssertExpectations(t)
+}
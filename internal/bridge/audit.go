@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// Entries /audit shows by default, and at most
+const (
+	defaultAuditEntries = 10
+	maxAuditEntries     = 50
+)
+
+// auditTrail records sensitive actions into the audit log. It is shared by
+// the bridge and its handlers; without a log nothing is recorded.
+type auditTrail struct {
+	log *state.AuditLog
+}
+
+// record logs an action of a chat, taken by the user who sent the update
+func (a *auditTrail) record(ctx context.Context, chatID string, action string, detail string) {
+	if a == nil || a.log == nil {
+		return
+	}
+	entry := state.AuditEntry{ChatID: chatID, Action: action, Detail: detail}
+	entry.UserID, _ = telegram.UserIDFromContext(ctx)
+	entry.User, _ = telegram.UserNameFromContext(ctx)
+	if err := a.log.Record(entry); err != nil {
+		logger.Error("Failed to record audit entry", "chat", chatID, "action", action, "error", err)
+	}
+}
+
+// SetAuditLog records permission replies, session deletions, agent and
+// model switches and the shell commands run for the chat's prompts into log
+func (b *Bridge) SetAuditLog(log *state.AuditLog) {
+	b.audit.log = log
+}
+
+// HandleAuditCommand handles /audit [n], showing the chat's latest audited
+// actions
+func (b *Bridge) HandleAuditCommand(ctx context.Context, args string) error {
+	n := defaultAuditEntries
+	if args = strings.TrimSpace(args); args != "" {
+		parsed, err := strconv.Atoi(args)
+		if err != nil || parsed < 1 || parsed > maxAuditEntries {
+			_, err := b.tgBot.SendMessage(ctx, b.t("audit.usage", maxAuditEntries))
+			return err
+		}
+		n = parsed
+	}
+
+	var entries []state.AuditEntry
+	if b.audit.log != nil {
+		entries = b.audit.log.Entries(b.chatID, n)
+	}
+	if len(entries) == 0 {
+		_, err := b.tgBot.SendMessage(ctx, b.t("audit.empty"))
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.t("audit.title"))
+	for _, entry := range entries {
+		user := entry.User
+		if user == "" && entry.UserID != 0 {
+			user = strconv.FormatInt(entry.UserID, 10)
+		}
+		if user == "" {
+			user = "—"
+		}
+		fmt.Fprintf(&sb, "\n<code>%s</code> %s · %s · %s",
+			entry.Time.Format("2006-01-02 15:04"), html.EscapeString(user), entry.Action, html.EscapeString(entry.Detail))
+	}
+	_, err := b.tgBot.SendMessage(ctx, sb.String())
+	return err
+}
@@ -0,0 +1,116 @@
+package bridge
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestAuditRecordsSensitiveActions(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	registry := state.NewIDRegistry()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), registry, time.Second)
+	auditLog, err := state.LoadAuditLog(filepath.Join(t.TempDir(), "audit"))
+	require.NoError(t, err)
+	bridge.SetAuditLog(auditLog)
+	ctx := telegram.WithUserName(telegram.WithUserID(context.Background(), 7), "@alice")
+
+	shortKey := registry.Register("perm_1", "p", "")
+	bridge.permissions.Store(shortKey, PermissionState{
+		PermissionID: "perm_1", SessionID: "ses_1", MessageID: 42, Request: "bash (rm -rf build)",
+	})
+	mockOC.On("ReplyPermission", "ses_1", "perm_1", opencode.PermissionReject).Return(nil)
+	mockTG.On("EditMessage", ctx, 42, mock.Anything).Return(nil)
+	require.NoError(t, bridge.HandlePermissionCallback(ctx, shortKey, "reject"))
+
+	mockOC.On("ListSessions").Return([]opencode.Session{{ID: "ses_2", Title: "Old"}}, nil)
+	mockOC.On("DeleteSession", "ses_2").Return(nil)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+	require.NoError(t, bridge.cmdHandler.HandleDeleteSession(ctx, "ses_2"))
+
+	entries := auditLog.Entries("", 10)
+	require.Len(t, entries, 2)
+	assert.Equal(t, state.AuditEntry{
+		Time: entries[0].Time, UserID: 7, User: "@alice",
+		Action: state.AuditPermission, Detail: "reject: bash (rm -rf build) in ses_1",
+	}, entries[0])
+	assert.Equal(t, state.AuditSessionDelete, entries[1].Action)
+	assert.Equal(t, "ses_2", entries[1].Detail)
+
+	require.NoError(t, bridge.HandleAuditCommand(ctx, "1"))
+	report := mockTG.sentMessages[len(mockTG.sentMessages)-1]
+	assert.True(t, strings.HasPrefix(report, "🔐 Audit log, newest last:\n<code>"))
+	assert.True(t, strings.HasSuffix(report, " @alice · session_delete · ses_2"), report)
+}
+
+func TestAuditRecordsModelSwitch(t *testing.T) {
+	auditLog, _ := state.LoadAuditLog("")
+	handler := NewModelHandler(&mockModelTelegramBot{}, &mockModelAppState{}, &mockModelOpenCodeClient{err: assert.AnError})
+	handler.chatID = "-100"
+	handler.audit = &auditTrail{log: auditLog}
+	model := handler.GetAvailableModels(context.Background())[0]
+
+	require.NoError(t, handler.HandleModelCallback(context.Background(), 0, "mdl:sel:"+model))
+	entries := auditLog.Entries("-100", 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, state.AuditModelSwitch, entries[0].Action)
+	assert.Equal(t, model, entries[0].Detail)
+}
+
+func TestAuditRecordsShellCommands(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	auditLog, _ := state.LoadAuditLog("")
+	bridge.SetAuditLog(auditLog)
+	ctx := telegram.WithUserName(telegram.WithUserID(context.Background(), 7), "@alice")
+	bridge.progress.Store("ses_1", &ProgressTracker{started: time.Now(), changed: make(chan struct{}, 1), askedBy: ctx})
+
+	bash := func(status string) map[string]interface{} {
+		return map[string]interface{}{
+			"sessionID": "ses_1",
+			"type":      "tool",
+			"tool":      "bash",
+			"state":     map[string]interface{}{"status": status, "input": map[string]interface{}{"command": "rm -rf build"}},
+		}
+	}
+	bridge.trackPartProgress(bash("running"))
+	bridge.trackPartProgress(bash("completed"))
+	// Other tools are not shell commands
+	bridge.trackPartProgress(map[string]interface{}{
+		"sessionID": "ses_1",
+		"type":      "tool",
+		"tool":      "read",
+		"state":     map[string]interface{}{"status": "completed"},
+	})
+
+	entries := auditLog.Entries("", 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, state.AuditEntry{
+		Time: entries[0].Time, UserID: 7, User: "@alice",
+		Action: state.AuditShell, Detail: "completed: rm -rf build in ses_1",
+	}, entries[0])
+}
+
+func TestAuditCommandUsage(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+
+	require.NoError(t, bridge.HandleAuditCommand(ctx, ""))
+	require.NoError(t, bridge.HandleAuditCommand(ctx, "500"))
+	assert.Equal(t, []string{
+		"No audited actions in this chat yet.",
+		"❌ Usage: /audit [n], with n between 1 and 50",
+	}, mockTG.sentMessages)
+}
@@ -0,0 +1,110 @@
+package bridge
+
+import (
+	"context"
+	"html"
+	"strings"
+	"sync"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// sessionBanner is the pinned message showing the active session
+type sessionBanner struct {
+	mu        sync.Mutex
+	enabled   bool
+	messageID int
+	text      string
+}
+
+// SetSessionBanner keeps one pinned message in the chat showing the current
+// session title, agent, model, and status. It is edited whenever one of
+// them changes.
+func (b *Bridge) SetSessionBanner(enabled bool) {
+	b.banner.mu.Lock()
+	defer b.banner.mu.Unlock()
+	b.banner.enabled = enabled
+}
+
+// refreshBanner edits the pinned banner to the current state, posting and
+// pinning a new one when there is none yet (or the old one was deleted)
+func (b *Bridge) refreshBanner(ctx context.Context) {
+	b.banner.mu.Lock()
+	defer b.banner.mu.Unlock()
+
+	if !b.banner.enabled {
+		return
+	}
+
+	text := b.bannerText(ctx)
+	if text == b.banner.text {
+		return
+	}
+
+	if b.banner.messageID != 0 {
+		err := b.tgBot.EditMessage(ctx, b.banner.messageID, text)
+		if err == nil {
+			b.banner.text = text
+			return
+		}
+		b.logger.Warn("Banner edit failed, posting a new banner", "error", err)
+	}
+
+	msgID, err := b.tgBot.SendMessageSilent(ctx, text)
+	if err != nil {
+		b.logger.Error("Banner send failed", "error", err)
+		return
+	}
+	if err := b.tgBot.PinMessage(ctx, msgID); err != nil {
+		b.logger.Warn("Banner pin failed (bot needs pin rights in groups)", "error", err)
+	}
+	b.banner.messageID = msgID
+	b.banner.text = text
+}
+
+// bannerText renders the banner for the session of the user behind ctx
+// Example:
+//
+//	📌 Active session
+//	Session: Fix login bug
+//	Agent: build
+//	Model: anthropic/claude-sonnet
+//	Status: idle
+func (b *Bridge) bannerText(ctx context.Context) string {
+	sessionID := b.sessions.current(ctx)
+
+	title := b.t("status.none")
+	if sessionID != "" {
+		title = sessionID
+		if sessions, err := b.ocClient.ListSessions(); err == nil {
+			for _, s := range sessions {
+				if s.ID == sessionID && s.Title != "" {
+					title = s.Title
+					break
+				}
+			}
+		}
+	}
+
+	model := b.state.GetModelForChat(b.chatID)
+	if model == "" {
+		model = b.t("status.unknown")
+	}
+
+	status := b.t("status.idle")
+	switch b.state.GetSessionStatus(sessionID) {
+	case state.SessionBusy:
+		status = b.t("status.processing")
+	case state.SessionError:
+		status = b.t("status.error")
+	}
+
+	lines := []string{
+		b.t("banner.title"),
+		b.t("status.session", html.EscapeString(title)),
+		b.t("status.agent", html.EscapeString(b.getEffectiveAgent())),
+		b.t("status.model", html.EscapeString(model)),
+		b.t("status.status", status),
+	}
+	return strings.Join(lines, "\n")
+}
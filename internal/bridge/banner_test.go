@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestSessionBannerPinnedAndEdited(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentAgent("build")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetSessionBanner(true)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{{ID: "ses_1", Title: "Fix <login>"}}, nil)
+
+	first := "📌 Active session\nSession: Fix &lt;login&gt;\nAgent: build\nModel: (unknown)\nStatus: idle"
+	mockTG.On("SendMessageSilent", ctx, first).Return(1, nil).Once()
+	mockTG.On("PinMessage", ctx, 1).Return(nil).Once()
+
+	bridge.sessions.set(ctx, "ses_1")
+	mockTG.AssertExpectations(t)
+
+	// Unchanged state does not touch the banner
+	bridge.refreshBanner(ctx)
+	mockTG.AssertNumberOfCalls(t, "SendMessageSilent", 1)
+
+	// A model change edits the pinned message in place
+	appState.SetCurrentModel("anthropic/claude-sonnet")
+	second := "📌 Active session\nSession: Fix &lt;login&gt;\nAgent: build\nModel: anthropic/claude-sonnet\nStatus: idle"
+	mockTG.On("EditMessage", ctx, 1, second).Return(nil).Once()
+
+	bridge.refreshBanner(ctx)
+	mockTG.AssertExpectations(t)
+}
+
+func TestSessionBannerReplacedWhenDeleted(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetSessionBanner(true)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{}, nil)
+	mockTG.On("SendMessageSilent", ctx, mock.Anything).Return(1, nil).Once()
+	mockTG.On("PinMessage", ctx, 1).Return(nil).Once()
+	bridge.sessions.set(ctx, "ses_1")
+
+	mockTG.On("EditMessage", ctx, 1, mock.Anything).Return(errors.New("message to edit not found")).Once()
+	mockTG.On("SendMessageSilent", ctx, mock.Anything).Return(2, nil).Once()
+	mockTG.On("PinMessage", ctx, 2).Return(nil).Once()
+	bridge.sessions.set(ctx, "ses_2")
+
+	mockTG.AssertExpectations(t)
+	assert.Equal(t, 2, bridge.banner.messageID)
+}
+
+func TestSessionBannerDisabledByDefault(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+
+	bridge.sessions.set(context.Background(), "ses_1")
+
+	mockOC.AssertNotCalled(t, "ListSessions")
+	mockTG.AssertNotCalled(t, "SendMessageSilent", mock.Anything, mock.Anything)
+}
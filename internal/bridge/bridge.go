@@ -2,53 +2,76 @@ package bridge
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-telegram/bot/models"
 
-	"github.com/user/opencode-telegram/internal/health"
+	"github.com/user/opencode-telegram/internal/events"
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/logging"
 	"github.com/user/opencode-telegram/internal/metrics"
 	"github.com/user/opencode-telegram/internal/opencode"
 	"github.com/user/opencode-telegram/internal/state"
 	"github.com/user/opencode-telegram/internal/telegram"
 )
 
+var logger = logging.For("bridge")
+
 type TelegramBot interface {
 	SendMessage(ctx context.Context, text string) (int, error)
 	SendMessagePlain(ctx context.Context, text string) (int, error)
 	SendMessageWithKeyboard(ctx context.Context, text string, keyboard *models.InlineKeyboardMarkup) (int, error)
+	SendMessageWithReplyMarkup(ctx context.Context, text string, markup models.ReplyMarkup) (int, error)
 	EditMessage(ctx context.Context, messageID int, text string) error
 	EditMessageWithKeyboard(ctx context.Context, messageID int, text string, keyboard *models.InlineKeyboardMarkup) error
 	EditMessagePlain(ctx context.Context, messageID int, text string) error
 	AnswerCallback(ctx context.Context, callbackID string) error
 	SendTyping(ctx context.Context) error
+	SendPhoto(ctx context.Context, data []byte, filename string, caption string) (int, error)
+	SetReaction(ctx context.Context, messageID int, emoji string) error
+	SendMessageSilent(ctx context.Context, text string) (int, error)
+	DeleteMessage(ctx context.Context, messageID int) error
+	SendMessageReply(ctx context.Context, text string, replyTo int) (int, error)
+	PinMessage(ctx context.Context, messageID int) error
+	SendMessageToChat(ctx context.Context, chatID int64, text string) (int, error)
+	SetChatCommands(ctx context.Context, lang i18n.Lang) error
 }
 
 type OpenCodeClient interface {
 	CreateSession(title *string, parentID *string) (*opencode.Session, error)
 	ListSessions() ([]opencode.Session, error)
 	DeleteSession(sessionID string) error
-	SendPrompt(sessionID, text string, agent *string) (*opencode.SendPromptResponse, error)
-	SendPromptWithParts(sessionID string, parts []interface{}, agent *string) (*opencode.SendPromptResponse, error)
-	TriggerPrompt(sessionID, text string, agent *string) error
+	SendPrompt(sessionID, text string, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error)
+	SendPromptWithParts(sessionID string, parts []interface{}, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error)
+	TriggerPrompt(sessionID, text string, opts opencode.PromptOptions) error
 	AbortSession(sessionID string) error
+	InitSession(sessionID, providerID, modelID string) error
 	Health() (map[string]interface{}, error)
 	GetConfig() (map[string]interface{}, error)
 	GetMessages(sessionID string, limit int) ([]opencode.Message, error)
+	GetSessionSummary(sessionID string) (*opencode.SessionSummary, error)
 	GetMessage(sessionID string, messageID string) (*opencode.Message, error)
 	ReplyPermission(sessionID, permissionID string, response opencode.PermissionResponse) error
 	ReplyQuestion(requestID string, answers []opencode.QuestionAnswer) error
+	ListPermissions() ([]opencode.PermissionRequest, error)
+	ListQuestions() ([]opencode.QuestionRequest, error)
+	ListSessionStatuses() (map[string]opencode.SessionStatus, error)
 	GetProviders() (*opencode.ProvidersResponse, error)
+	GetFileContent(path string) (*opencode.FileContent, error)
+	GetAgents() ([]string, error)
 }
 
 type PermissionState struct {
 	PermissionID string
 	SessionID    string
 	MessageID    int
+	Request      string // what was asked, e.g. "bash (rm -rf build)", for the audit log
 }
 
 type QuestionState struct {
@@ -59,6 +82,8 @@ type QuestionState struct {
 	QuestionInfo    opencode.QuestionInfo
 	SelectedOptions map[int]bool // For multi-select tracking
 	WaitingCustom   bool         // True when waiting for custom text input
+	CustomSince     time.Time    // When custom input was asked for; the latest one gets the next message
+	Draft           string       // Custom answer typed but not yet accepted by OpenCode
 }
 
 type DebounceBuffer struct {
@@ -66,6 +91,9 @@ type DebounceBuffer struct {
 	lastReceived time.Time
 	timer        *time.Timer
 	mu           sync.Mutex
+
+	// flushed is set once the messages have been handed to OpenCode
+	flushed bool
 }
 
 type StreamBuffer struct {
@@ -87,7 +115,7 @@ type Bridge struct {
 	chatID          string
 	state           *state.AppState
 	registry        *state.IDRegistry
-	debounceMs      time.Duration
+	debounceMs      atomic.Int64 // default debounce window, see SetDebounce
 	debounceBuffers sync.Map
 
 	thinkingMsgs  sync.Map
@@ -98,98 +126,216 @@ type Bridge struct {
 	lastUpdate    sync.Map
 	updateMu      sync.Mutex
 	idleProcessed sync.Map
-
-	healthMonitor *health.HealthMonitor
+	progress      sync.Map
+
+	cmdHandler    *CommandHandler
+	commands      map[string]telegram.CommandHandler
+	sessions      *sessionScope
+	quickKeyboard bool
+	notifyPolicy  NotificationPolicy
+	freshMessage  bool
+	showMore      bool
+	showReasoning bool
+	noStreaming   bool // placeholders are not edited as text streams in
+	noReactions   bool // user reactions are not forwarded to OpenCode
+	transcriber   Transcriber
+	frames        FrameExtractor
+	albums        sync.Map
+	banner        sessionBanner
+
+	// Admin chat that /feedback forwards to (0: disabled)
+	feedbackChatID int64
+
+	// Buttons under completed responses (see actions.go); lastPrompts
+	// holds each session's last text prompt for Retry
+	responseActions bool
+	lastPrompts     sync.Map
+
+	// User message that started each session's work, for completion
+	// reactions and fresh-message replies (see completion.go)
+	triggerMsgs     sync.Map
+	successReaction string
+	failureReaction string
+
+	// downloadFile fetches Telegram files by ID; replaced in tests
+	downloadFile func(ctx context.Context, botToken, fileID string) ([]byte, error)
+
+	// promptRetryBase is the backoff step for retryable prompt errors
+	promptRetryBase time.Duration
+
+	// OpenCode servers for /server (nil: single server); models is the
+	// /model handler, whose cached list is dropped on a switch
+	servers *ServerSwitch
+	models  *ModelHandler
+
+	// Prompts still being submitted to OpenCode (see drain.go)
+	submitting atomic.Int64
+
+	// When each session's pending prompt was sent, for /stats latency
+	promptStarts sync.Map
+
+	// Sensitive actions for /audit (see audit.go)
+	audit *auditTrail
+
+	// Expires the in-flight maps above (see janitor.go)
+	janitor janitor
+
+	// Lines about this bridge carry its chat, and account (see SetAccount)
+	logger *slog.Logger
+
+	// Labels of the bridge's metrics
+	account metrics.Account
 }
 
 func NewBridge(ocClient OpenCodeClient, tgBot TelegramBot, appState *state.AppState, registry *state.IDRegistry, debounceMs time.Duration) *Bridge {
-	if debounceMs <= 0 || debounceMs > 3000*time.Millisecond {
-		debounceMs = 1000 * time.Millisecond
-	}
-
 	var chatID string
 	if bot, ok := tgBot.(*telegram.Bot); ok {
 		chatID = fmt.Sprintf("%d", bot.ChatID())
 	}
 
-	return &Bridge{
+	b := &Bridge{
 		ocClient:   ocClient,
 		tgBot:      tgBot,
 		chatID:     chatID,
 		state:      appState,
 		registry:   registry,
-		debounceMs: debounceMs,
+		cmdHandler: NewCommandHandler(ocClient, tgBot, appState),
+		sessions:   &sessionScope{state: appState, chatID: chatID},
+
+		downloadFile:    telegram.DownloadFile,
+		promptRetryBase: 5 * time.Second,
+		audit:           &auditTrail{},
+		logger:          logger,
+		account:         metrics.Account{Chat: chatID},
 	}
+	if chatID != "" {
+		b.logger = logger.With("chat", chatID)
+	}
+	b.SetDebounce(debounceMs)
+	if bot, ok := tgBot.(*telegram.Bot); ok {
+		// Files come through the bot's own client, e.g. its account's proxy
+		b.downloadFile = bot.DownloadFile
+	}
+	b.cmdHandler.translator = translator{lang: b.lang}
+	b.cmdHandler.audit = b.audit
+	b.cmdHandler.sessions = b.sessions
+	b.cmdHandler.logger = b.logger
+	b.sessions.onSwitch = b.refreshBanner
+	b.restorePending()
+	if b.chatID != "" {
+		b.publishStats(b.state.GetChatStats(b.chatID))
+	}
+	return b
 }
 
 func (b *Bridge) getEffectiveAgent() string {
 	return b.state.GetAgentForChat(b.chatID)
 }
 
-func (b *Bridge) SetHealthMonitor(monitor *health.HealthMonitor) {
-	b.healthMonitor = monitor
+// promptOptions has agent answer a prompt with the model picked for this
+// chat with /model (if any)
+func (b *Bridge) promptOptions(agent string) opencode.PromptOptions {
+	return opencode.PromptOptions{Agent: agent, Model: b.state.GetModelForChat(b.chatID)}
+}
+
+// SetAccount labels the bridge's log lines and metrics with the Telegram
+// account it serves
+func (b *Bridge) SetAccount(name string) {
+	b.account.Name = name
+	b.logger = b.logger.With("account", name)
+	b.cmdHandler.logger = b.logger
+	if b.models != nil {
+		b.models.logger = b.logger
+	}
+}
+
+// SetQuickActionKeyboard enables the persistent reply keyboard with quick action buttons
+func (b *Bridge) SetQuickActionKeyboard(enabled bool) {
+	b.quickKeyboard = enabled
+}
+
+// SetShowReasoning includes the model's reasoning parts in responses, as a
+// collapsed blockquote above the answer
+func (b *Bridge) SetShowReasoning(enabled bool) {
+	b.showReasoning = enabled
+}
+
+// SetStreamingEdits edits the placeholder as the response streams in (the
+// default); disabled, the placeholder stays until the final response
+func (b *Bridge) SetStreamingEdits(enabled bool) {
+	b.noStreaming = !enabled
+}
+
+// SetReactions forwards the user's emoji reactions on responses to OpenCode
+// (the default)
+func (b *Bridge) SetReactions(enabled bool) {
+	b.noReactions = !enabled
 }
 
 func (b *Bridge) HandleUserMessage(ctx context.Context, text string) error {
-	sessionID := b.state.GetCurrentSession()
-	log.Printf("[BRIDGE] HandleUserMessage: currentSession=%q, statePtr=%p", sessionID, b.state)
+	sessionID := b.sessions.current(ctx)
+	b.logger.Debug("HandleUserMessage", "session", sessionID)
 
 	if sessionID == "" {
-		log.Printf("[BRIDGE] No session found, creating new one...")
+		b.logger.Info("No session found, creating new one")
 		title := "Telegram Chat"
 		session, err := b.ocClient.CreateSession(&title, nil)
 		if err != nil {
 			return fmt.Errorf("create session: %w", err)
 		}
 		sessionID = session.ID
-		b.state.SetCurrentSession(sessionID)
-		log.Printf("[BRIDGE] Created and set session: %s", sessionID)
+		b.sessions.set(ctx, sessionID)
+		b.logger.Info("Created and set session", "session", sessionID)
 	}
 
-	// Check if we have a buffer for this session
-	bufVal, ok := b.debounceBuffers.Load(sessionID)
-	if ok {
-		// Buffer exists - add message and extend timer
-		buf := bufVal.(*DebounceBuffer)
-		buf.mu.Lock()
-		buf.messages = append(buf.messages, text)
-		buf.lastReceived = time.Now()
-		buf.mu.Unlock()
+	// Refuse new prompts while the previous one is still generating
+	if b.state.GetSessionStatus(sessionID) == state.SessionBusy {
+		_, err := b.tgBot.SendMessage(ctx, b.t("busy"))
+		return err
+	}
+
+	b.rememberTrigger(ctx, sessionID)
 
-		// Stop and restart timer
-		if buf.timer != nil {
-			buf.timer.Stop()
+	// Add the message to this session's buffer (creating it if needed) and
+	// restart the debounce timer
+	var buf *DebounceBuffer
+	for {
+		val, _ := b.debounceBuffers.LoadOrStore(sessionID, &DebounceBuffer{})
+		buf = val.(*DebounceBuffer)
+		buf.mu.Lock()
+		if !buf.flushed {
+			break
 		}
-		buf.timer = time.AfterFunc(b.debounceMs, func() {
-			b.flushDebounceBuffer(sessionID)
-		})
-		return nil
+		// The buffer was flushed while we waited for it: start a new one
+		buf.mu.Unlock()
+		b.debounceBuffers.CompareAndDelete(sessionID, buf)
 	}
-
-	// Create first buffer and start debouncing
-	buf := &DebounceBuffer{
-		messages:     []string{text},
-		lastReceived: time.Now(),
+	buf.messages = append(buf.messages, text)
+	buf.lastReceived = time.Now()
+	if buf.timer != nil {
+		buf.timer.Stop()
 	}
-	b.debounceBuffers.Store(sessionID, buf)
-	buf.timer = time.AfterFunc(b.debounceMs, func() {
-		b.flushDebounceBuffer(sessionID)
+	buf.timer = time.AfterFunc(b.debounceWindow(), func() {
+		b.flushDebounceBuffer(sessionID, buf)
 	})
+	buf.mu.Unlock()
 
 	return nil
 }
 
-func (b *Bridge) flushDebounceBuffer(sessionID string) {
-	bufVal, ok := b.debounceBuffers.Load(sessionID)
-	if !ok {
+// flushDebounceBuffer sends the buffered messages as one prompt. A timer that
+// fired while being replaced can call it twice for the same buffer; only the
+// first call sends, so a prompt is never submitted twice.
+func (b *Bridge) flushDebounceBuffer(sessionID string, buf *DebounceBuffer) {
+	buf.mu.Lock()
+	if buf.flushed {
+		buf.mu.Unlock()
 		return
 	}
-	defer b.debounceBuffers.Delete(sessionID)
-
-	buf := bufVal.(*DebounceBuffer)
-	buf.mu.Lock()
+	buf.flushed = true
 	messages := buf.messages
 	buf.mu.Unlock()
+	b.debounceBuffers.CompareAndDelete(sessionID, buf)
 
 	if len(messages) == 0 {
 		return
@@ -197,37 +343,65 @@ func (b *Bridge) flushDebounceBuffer(sessionID string) {
 
 	// Merge messages with newline separator
 	mergedText := strings.Join(messages, "\n")
+	b.lastPrompts.Store(sessionID, mergedText)
 
 	b.state.SetSessionStatus(sessionID, state.SessionBusy)
 
 	ctx := context.Background()
-	thinkingMsgID, err := b.tgBot.SendMessage(ctx, "⏳ Processing...")
+	go b.refreshBanner(ctx)
+	thinkingMsgID, err := b.sendPlaceholder(ctx, b.t("processing"))
 	if err != nil {
 		b.state.SetSessionStatus(sessionID, state.SessionIdle)
 		return
 	}
 
 	b.thinkingMsgs.Store(sessionID, thinkingMsgID)
+	b.startProgress(ctx, sessionID, thinkingMsgID, b.t("processing"))
 
 	// Send initial typing indicator before launching async processing
 	_ = b.tgBot.SendTyping(ctx)
 
-	go b.sendPromptAsync(context.Background(), sessionID, mergedText, thinkingMsgID)
+	b.sendPromptAsync(context.Background(), sessionID, mergedText, thinkingMsgID)
 }
 
 func (b *Bridge) sendPromptAsync(ctx context.Context, sessionID, text string, thinkingMsgID int) {
-	agent := b.getEffectiveAgent()
+	// Every attempt reuses one message ID, so OpenCode runs a prompt whose
+	// first attempt did get through only once
+	opts := b.promptOptions(b.getEffectiveAgent())
+	opts.MessageID = opencode.NewMessageID()
 
+	b.submitting.Add(1)
 	go func() {
-		err := b.ocClient.TriggerPrompt(sessionID, text, &agent)
-		if err != nil {
-			errorMsg := fmt.Sprintf("❌ Error: %s", err.Error())
-			if editErr := b.tgBot.EditMessagePlain(context.Background(), thinkingMsgID, errorMsg); editErr != nil {
-				log.Printf("[ERROR] Failed to edit error message: %v", editErr)
-				b.tgBot.SendMessagePlain(context.Background(), errorMsg)
+		defer b.submitting.Add(-1)
+		for attempt := 1; ; attempt++ {
+			err := b.ocClient.TriggerPrompt(sessionID, text, opts)
+			if err == nil {
+				b.recordPrompt(sessionID)
+				return
+			}
+			if attempt > promptRetries || !opencode.IsRetryable(err) {
+				b.failPrompt(sessionID, thinkingMsgID, b.errorText(err))
+				return
+			}
+
+			b.logger.Warn("Prompt failed, retrying", "session", sessionID, "attempt", attempt, "error", err)
+			label := b.t("opencode.retrying")
+			var apiErr *opencode.APIError
+			if errors.As(err, &apiErr) && apiErr.RateLimited() {
+				label = b.t("opencode.retrying.rate_limited")
+			}
+			b.setProgressLabel(sessionID, label)
+			// Outdated once the retry ends, like the progress frames
+			_ = b.tgBot.EditMessagePlain(telegram.WithoutOutbox(ctx), thinkingMsgID, label)
+
+			timer := time.NewTimer(b.promptRetryDelay(err, attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				b.failPrompt(sessionID, thinkingMsgID, b.errorText(err))
+				return
+			case <-timer.C:
 			}
-			b.state.SetSessionStatus(sessionID, state.SessionError)
-			b.thinkingMsgs.Delete(sessionID)
 		}
 	}()
 
@@ -249,6 +423,19 @@ func (b *Bridge) sendPromptAsync(ctx context.Context, sessionID, text string, th
 	}()
 }
 
+// promptRetries is how often a prompt rejected with a retryable OpenCode
+// error (rate limit, 5xx) is sent again
+const promptRetries = 2
+
+// promptRetryDelay honours Retry-After and otherwise backs off linearly
+func (b *Bridge) promptRetryDelay(err error, attempt int) time.Duration {
+	var apiErr *opencode.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	return time.Duration(attempt) * b.promptRetryBase
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -273,70 +460,100 @@ func (b *Bridge) extractResponseText(resp *opencode.SendPromptResponse) string {
 	return result
 }
 
+// HandleSSEEvent handles one OpenCode event, from the SSE stream or the plugin webhook
 func (b *Bridge) HandleSSEEvent(event opencode.Event) {
-	start := time.Now()
-	defer func() {
-		metrics.ObserveSSEEventProcessing(event.Type, start)
-	}()
-
-	if b.healthMonitor != nil {
-		b.healthMonitor.RecordEvent(event.Type)
+	if handle := b.eventHandlers()[event.Type]; handle != nil {
+		if err := handle(event); err != nil {
+			metrics.EventErrors.WithLabelValues(b.account.Name, b.account.Chat, event.Type).Inc()
+			b.logger.Error("Handling event failed", "type", event.Type, "session", event.SessionID(), "error", err)
+		}
 	}
+}
 
-	switch event.Type {
-	case "session.idle":
-		b.handleSessionIdle(event)
-
-	case "session.error":
-		b.handleSessionError(event)
-
-	case "question.asked":
-		if qaEvent, ok := event.Properties.(*opencode.EventQuestionAsked); ok {
-			if err := b.handleQuestionAsked(*qaEvent); err != nil {
-				b.tgBot.SendMessage(context.Background(), fmt.Sprintf("❌ Error handling question: %v", err))
-			}
+// eventHandlers maps the OpenCode event types the bridge reacts to onto
+// their handlers
+func (b *Bridge) eventHandlers() events.Handlers {
+	handlers := events.Handlers{
+		"session.idle":         b.handleSessionIdle,
+		"session.error":        infallible(b.handleSessionError),
+		"question.asked":       b.handleQuestionAskedEvent,
+		"permission.asked":     b.handlePermissionAsked,
+		"permission.replied":   infallible(b.handlePermissionReplied),
+		"question.replied":     infallible(b.handleQuestionReplied),
+		"question.rejected":    infallible(b.handleQuestionRejected),
+		"session.deleted":      infallible(b.handleSessionDeleted),
+		"session.updated":      infallible(b.handleSessionUpdated),
+		"message.part.updated": infallible(b.handleMessagePartUpdated),
+		"message.updated":      b.handleMessageUpdated,
+	}
+	for eventType, handle := range handlers {
+		handlers[eventType] = func(event opencode.Event) error {
+			defer metrics.ObserveSSEEventProcessing(b.account, event.Type, time.Now())
+			return handle(event)
 		}
+	}
+	return handlers
+}
 
-	case "permission.asked":
-		b.handlePermissionAsked(event)
+// infallible adapts a handler whose failures are not worth a replay, such
+// as streaming updates that the final message supersedes
+func infallible(handle func(opencode.Event)) events.Handler {
+	return func(event opencode.Event) error {
+		handle(event)
+		return nil
+	}
+}
 
-	case "message.part.updated":
-		b.handleMessagePartUpdated(event)
+// Subscribe handles the events published on bus until ctx is done: those
+// of this chat's sessions, plus, as the fallback bridge, those of sessions
+// no chat tracks
+func (b *Bridge) Subscribe(ctx context.Context, bus *events.Bus, fallback bool) {
+	bus.SubscribeSessions(ctx, b.eventHandlers(), b.TracksSession, fallback)
+}
 
-	case "message.updated":
-		b.handleMessageUpdated(event)
+func (b *Bridge) handleQuestionAskedEvent(event opencode.Event) error {
+	qaEvent, ok := event.Properties.(*opencode.EventQuestionAsked)
+	if !ok {
+		return nil
+	}
+	if err := b.handleQuestionAsked(*qaEvent); err != nil {
+		b.tgBot.SendMessage(context.Background(), b.t("question.error", err))
+		return err
 	}
+	return nil
 }
 
-func (b *Bridge) handleSessionIdle(event opencode.Event) {
+func (b *Bridge) handleSessionIdle(event opencode.Event) error {
 	evtData, ok := event.Properties.(*opencode.EventSessionIdle)
 	if !ok {
-		return
+		return nil
 	}
 
 	sessionID := evtData.Properties.SessionID
 	b.state.SetSessionStatus(sessionID, state.SessionIdle)
+	go b.refreshBanner(context.Background())
 
 	if evtData.Properties.Content != nil && *evtData.Properties.Content != "" {
 		content := *evtData.Properties.Content
-		log.Printf("[INFO] handleSessionIdle: sending response for session %s, content length=%d", sessionID, len(content))
+		b.logger.Info("handleSessionIdle: sending response", "session", sessionID, "length", len(content))
 
 		// Fetch latest message to get messageID for unified deduplication
-		go func() {
-			messages, err := b.ocClient.GetMessages(sessionID, 1)
-			if err != nil {
-				log.Printf("[ERROR] handleSessionIdle: failed to get messageID: %v", err)
-				return
-			}
+		messages, err := b.ocClient.GetMessages(sessionID, 1)
+		if err != nil {
+			return fmt.Errorf("get messageID of session %s: %w", sessionID, err)
+		}
 
-			if len(messages) > 0 && messages[0].Info.Role == "assistant" {
-				messageID := messages[0].Info.ID
-				b.sendCompletedMessageFromWebhook(sessionID, messageID, content)
-			} else {
-				log.Printf("[WARN] handleSessionIdle: no assistant message found for session %s", sessionID)
+		if len(messages) > 0 && messages[0].Info.Role == "assistant" {
+			messageID := messages[0].Info.ID
+			if err := b.sendCompletedMessageFromWebhook(sessionID, messageID, content, messageTokens(&messages[0])); err != nil {
+				return err
 			}
-		}()
+			b.sendGeneratedImages(sessionID, &messages[0])
+		} else {
+			b.logger.Warn("handleSessionIdle: no assistant message found", "session", sessionID)
+		}
 	}
+	return nil
 }
 
 func (b *Bridge) handleSessionError(event opencode.Event) {
@@ -351,17 +568,19 @@ func (b *Bridge) handleSessionError(event opencode.Event) {
 	}
 
 	b.state.SetSessionStatus(sessionID, state.SessionError)
+	b.recordError(sessionID)
+	b.reactCompletion(sessionID, false)
 }
 
-func (b *Bridge) handleMessageUpdated(event opencode.Event) {
+func (b *Bridge) handleMessageUpdated(event opencode.Event) error {
 	if event.Type != "message.updated" {
-		return
+		return nil
 	}
 
 	msgEvent, ok := event.Properties.(*opencode.EventMessageUpdated)
 	if !ok {
-		log.Printf("[WARN] handleMessageUpdated: failed to cast event properties")
-		return
+		b.logger.Warn("handleMessageUpdated: failed to cast event properties")
+		return nil
 	}
 
 	var sessionID string
@@ -371,46 +590,80 @@ func (b *Bridge) handleMessageUpdated(event opencode.Event) {
 
 		if msgEvent.Properties.Info.Time.Completed != nil {
 			b.state.SetSessionStatus(sessionID, state.SessionIdle)
-			log.Printf("[INFO] handleMessageUpdated: message complete for session %s, messageID=%s", sessionID, messageID)
-			go b.fetchAndSendCompletedMessage(sessionID, messageID)
+			b.logger.Info("handleMessageUpdated: message complete", "session", sessionID, "message", messageID)
+			return b.fetchAndSendCompletedMessage(sessionID, messageID)
 		}
 	}
+	return nil
 }
 
-func (b *Bridge) fetchAndSendCompletedMessage(sessionID string, targetMessageID string) {
+func (b *Bridge) fetchAndSendCompletedMessage(sessionID string, targetMessageID string) error {
 	msg, err := b.ocClient.GetMessage(sessionID, targetMessageID)
 	if err != nil {
-		log.Printf("[ERROR] fetchAndSendCompletedMessage: failed to get message %s: %v", targetMessageID, err)
-		return
+		return fmt.Errorf("get message %s of session %s: %w", targetMessageID, sessionID, err)
 	}
 
 	if msg.Info.Role != "assistant" {
-		log.Printf("[WARN] fetchAndSendCompletedMessage: message %s is not assistant message (role=%s)", targetMessageID, msg.Info.Role)
-		return
+		b.logger.Warn("fetchAndSendCompletedMessage: not an assistant message", "session", sessionID, "message", targetMessageID, "role", msg.Info.Role)
+		return nil
 	}
 
 	var textParts []string
 	for _, part := range msg.Parts {
-		if part.Type == "text" && part.Text != "" {
+		if part.Text == "" {
+			continue
+		}
+		switch {
+		case part.Type == "text":
 			textParts = append(textParts, part.Text)
+		case part.Type == "reasoning" && b.showReasoning:
+			// FormatHTML collapses <think> sections into an expandable blockquote
+			textParts = append(textParts, "<think>"+part.Text+"</think>")
 		}
 	}
 
 	if len(textParts) > 0 {
 		content := strings.Join(textParts, "\n")
-		log.Printf("[INFO] fetchAndSendCompletedMessage: sending response for session %s, messageID=%s, content length=%d", sessionID, targetMessageID, len(content))
-		b.sendCompletedMessageFromWebhook(sessionID, targetMessageID, content)
+		b.logger.Info("fetchAndSendCompletedMessage: sending response", "session", sessionID, "message", targetMessageID, "length", len(content))
+		err = b.sendCompletedMessageFromWebhook(sessionID, targetMessageID, content, messageTokens(msg))
+	} else if hasImageParts(msg) {
+		err = b.sendCompletedMessageFromWebhook(sessionID, targetMessageID, b.t("response.completed"), messageTokens(msg))
 	} else {
-		log.Printf("[WARN] fetchAndSendCompletedMessage: message %s has no text content", targetMessageID)
+		b.logger.Warn("fetchAndSendCompletedMessage: message has no text content", "session", sessionID, "message", targetMessageID)
 	}
+	if err != nil {
+		return err
+	}
+
+	b.sendGeneratedImages(sessionID, msg)
+	return nil
+}
+
+func hasImageParts(msg *opencode.Message) bool {
+	for _, part := range msg.Parts {
+		if part.IsImage() {
+			return true
+		}
+	}
+	return false
 }
 
-func (b *Bridge) sendCompletedMessageFromWebhook(sessionID string, messageID string, content string) {
-	// Deduplication check - use messageID for precise dedup
+// sendCompletedMessageFromWebhook delivers a finished response once. The
+// message counts as processed only after it reached Telegram: on a failed
+// send the claim is dropped and the error returned, so the event can be
+// dead-lettered and its replay is not skipped as a duplicate.
+func (b *Bridge) sendCompletedMessageFromWebhook(sessionID string, messageID string, content string, tokens int) error {
+	// Deduplication check - use messageID for precise dedup. The claim also
+	// keeps a concurrent delivery of the same message out while this one sends
 	cacheKey := fmt.Sprintf("msg:%s", messageID)
 	if _, exists := b.idleProcessed.LoadOrStore(cacheKey, time.Now()); exists {
-		log.Printf("[INFO] sendCompletedMessageFromWebhook: skipping duplicate message %s", messageID)
-		return
+		b.logger.Info("sendCompletedMessageFromWebhook: skipping duplicate message", "session", sessionID, "message", messageID)
+		return nil
+	}
+
+	if err := b.sendToTelegram(sessionID, messageID, content); err != nil {
+		b.idleProcessed.Delete(cacheKey)
+		return fmt.Errorf("send message %s of session %s: %w", messageID, sessionID, err)
 	}
 
 	// Auto-cleanup after 60 seconds (long enough for any response)
@@ -418,48 +671,77 @@ func (b *Bridge) sendCompletedMessageFromWebhook(sessionID string, messageID str
 		b.idleProcessed.Delete(cacheKey)
 	})
 
-	b.sendToTelegram(sessionID, content)
+	b.recordResponse(sessionID, tokens)
+	return nil
 }
 
-func (b *Bridge) sendToTelegram(sessionID string, content string) {
+// sendToTelegram delivers the content of an OpenCode message. It returns the
+// first send that did not reach Telegram.
+func (b *Bridge) sendToTelegram(sessionID string, messageID string, content string) error {
 	ctx := context.Background()
 
 	thinkingMsgIDInterface, ok := b.thinkingMsgs.Load(sessionID)
 	if !ok {
-		log.Printf("[INFO] sendToTelegram: creating new message for session %s", sessionID)
+		b.logger.Info("sendToTelegram: creating new message", "session", sessionID)
 		formattedText := telegram.FormatHTML(content)
 		chunks := telegram.SplitMessage(formattedText, 4096)
+		if b.showMore && len(chunks) > 1 {
+			msgIDs, err := b.deliverPaged(ctx, 0, chunks)
+			if err != nil {
+				return err
+			}
+			b.recordMessages(sessionID, messageID, msgIDs)
+			b.reactCompletion(sessionID, true)
+			return nil
+		}
 
+		keyboard := b.responseActionsKeyboard()
+		var sendErr error
 		for i, chunk := range chunks {
-			msgID, err := b.tgBot.SendMessage(ctx, chunk)
-			if err != nil {
-				log.Printf("[ERROR] sendToTelegram: send chunk %d failed: %v", i, err)
+			var msgID int
+			var err error
+			if i == len(chunks)-1 {
+				msgID, err = b.sendChunk(ctx, chunk, keyboard)
 			} else {
-				log.Printf("[SUCCESS] sendToTelegram: sent chunk %d, msgID=%d", i, msgID)
+				msgID, err = b.tgBot.SendMessage(ctx, chunk)
+			}
+			if !delivered(err) {
+				b.logger.Error("sendToTelegram: send chunk failed", "session", sessionID, "chunk", i, "error", err)
+				if sendErr == nil {
+					sendErr = fmt.Errorf("send chunk %d: %w", i, err)
+				}
+			} else {
+				b.logger.Debug("sendToTelegram: sent chunk", "session", sessionID, "chunk", i, "telegram_message", msgID)
+				b.recordMessages(sessionID, messageID, []int{msgID})
 			}
 		}
-		return
+		if sendErr != nil {
+			return sendErr
+		}
+		b.reactCompletion(sessionID, true)
+		return nil
 	}
 
 	thinkingMsgID := thinkingMsgIDInterface.(int)
 
 	formattedText := telegram.FormatHTML(content)
-	chunks := telegram.SplitMessage(formattedText, 4096)
+	msgIDs, err := b.deliverFinal(ctx, sessionID, thinkingMsgID, telegram.SplitMessage(formattedText, 4096))
+	b.recordMessages(sessionID, messageID, msgIDs)
 
-	if len(chunks) > 0 {
-		if err := b.tgBot.EditMessage(ctx, thinkingMsgID, chunks[0]); err != nil {
-			log.Printf("[ERROR] sendToTelegram: edit failed: %v", err)
-		}
-
-		for i := 1; i < len(chunks); i++ {
-			if _, err := b.tgBot.SendMessage(ctx, chunks[i]); err != nil {
-				log.Printf("[ERROR] sendToTelegram: send chunk %d failed: %v", i, err)
-			}
-		}
+	b.clearThinking(sessionID)
+	if err != nil {
+		return err
 	}
+	b.reactCompletion(sessionID, true)
+	b.logger.Info("sendToTelegram: sent final message", "session", sessionID, "length", len(content))
+	return nil
+}
 
+// clearThinking drops the thinking message and its progress/stream state for a session
+func (b *Bridge) clearThinking(sessionID string) {
 	b.thinkingMsgs.Delete(sessionID)
-	log.Printf("[INFO] sendToTelegram: sent final message for session %s, content length=%d", sessionID, len(content))
+	b.streamBuffers.Delete(sessionID)
+	b.stopProgress(sessionID)
 }
 
 func (b *Bridge) sendCompletedMessage(sessionID string) {
@@ -467,15 +749,15 @@ func (b *Bridge) sendCompletedMessage(sessionID string) {
 
 	thinkingMsgIDInterface, ok := b.thinkingMsgs.Load(sessionID)
 	if !ok {
-		log.Printf("[INFO] sendCompletedMessage: no thinking message for session %s", sessionID)
+		b.logger.Info("sendCompletedMessage: no thinking message", "session", sessionID)
 		return
 	}
 	thinkingMsgID := thinkingMsgIDInterface.(int)
 
 	bufInterface, ok := b.msgBuffers.Load(sessionID)
 	if !ok {
-		log.Printf("[WARN] sendCompletedMessage: no buffer for session %s", sessionID)
-		b.tgBot.EditMessage(ctx, thinkingMsgID, "✅ Response completed (no content)")
+		b.logger.Warn("sendCompletedMessage: no buffer", "session", sessionID)
+		b.tgBot.EditMessage(ctx, thinkingMsgID, b.t("response.empty"))
 		return
 	}
 
@@ -485,67 +767,63 @@ func (b *Bridge) sendCompletedMessage(sessionID string) {
 	buf.mu.Unlock()
 
 	if finalText == "" {
-		finalText = "✅ Response completed"
+		finalText = b.t("response.completed")
 	}
 
 	formattedText := telegram.FormatHTML(finalText)
-	chunks := telegram.SplitMessage(formattedText, 4096)
-
-	if len(chunks) > 0 {
-		if err := b.tgBot.EditMessage(ctx, thinkingMsgID, chunks[0]); err != nil {
-			log.Printf("[ERROR] sendCompletedMessage: edit failed: %v", err)
-		}
-
-		for i := 1; i < len(chunks); i++ {
-			if _, err := b.tgBot.SendMessage(ctx, chunks[i]); err != nil {
-				log.Printf("[ERROR] sendCompletedMessage: send chunk %d failed: %v", i, err)
-			}
-		}
-	}
+	b.deliverFinal(ctx, sessionID, thinkingMsgID, telegram.SplitMessage(formattedText, 4096))
 
 	b.msgBuffers.Delete(sessionID)
-	b.thinkingMsgs.Delete(sessionID)
-	log.Printf("[INFO] sendCompletedMessage: sent final message for session %s", sessionID)
+	b.clearThinking(sessionID)
+	b.reactCompletion(sessionID, true)
+	b.logger.Info("sendCompletedMessage: sent final message", "session", sessionID)
 }
 
 func (b *Bridge) handleMessagePartUpdated(event opencode.Event) {
 	partEvent, ok := event.Properties.(*opencode.EventMessagePartUpdated)
 	if !ok {
-		log.Printf("[WARN] handleMessagePartUpdated: failed to cast event properties")
+		b.logger.Warn("handleMessagePartUpdated: failed to cast event properties")
 		return
 	}
 
+	if partData, ok := partEvent.Properties.Part.(map[string]interface{}); ok {
+		b.trackPartProgress(partData)
+	}
+
 	if partEvent.Properties.Delta == nil {
-		log.Printf("[DEBUG] handleMessagePartUpdated: delta is nil")
+		b.logger.Debug("handleMessagePartUpdated: delta is nil")
+		return
+	}
+	if b.noStreaming {
 		return
 	}
 	delta := *partEvent.Properties.Delta
 
 	partData, ok := partEvent.Properties.Part.(map[string]interface{})
 	if !ok {
-		log.Printf("[WARN] handleMessagePartUpdated: part is not a map")
+		b.logger.Warn("handleMessagePartUpdated: part is not a map")
 		return
 	}
 
 	sessionID, ok := partData["sessionID"].(string)
 	if !ok {
-		log.Printf("[WARN] handleMessagePartUpdated: sessionID not found in part")
+		b.logger.Warn("handleMessagePartUpdated: sessionID not found in part")
 		return
 	}
 
 	thinkingMsgIDInterface, ok := b.thinkingMsgs.Load(sessionID)
 	if !ok {
-		log.Printf("[WARN] handleMessagePartUpdated: no thinking message for session %s", sessionID)
+		b.logger.Warn("handleMessagePartUpdated: no thinking message", "session", sessionID)
 		return
 	}
 
 	thinkingMsgID, ok := thinkingMsgIDInterface.(int)
 	if !ok {
-		log.Printf("[WARN] handleMessagePartUpdated: thinking message ID is not int")
+		b.logger.Warn("handleMessagePartUpdated: thinking message ID is not int", "session", sessionID)
 		return
 	}
 
-	log.Printf("[DEBUG] handleMessagePartUpdated: session=%s, delta_len=%d, thinkingMsgID=%d", sessionID, len(delta), thinkingMsgID)
+	b.logger.Debug("handleMessagePartUpdated", "session", sessionID, "delta_len", len(delta), "telegram_message", thinkingMsgID)
 
 	// Get or create stream buffer
 	bufInterface, _ := b.streamBuffers.LoadOrStore(sessionID, &StreamBuffer{
@@ -575,7 +853,9 @@ func (b *Bridge) handleMessagePartUpdated(event opencode.Event) {
 
 		// Edit message asynchronously
 		go func() {
-			ctx := context.Background()
+			// Partial text is outdated by the next edit, so it is never
+			// queued to be replayed over the final answer
+			ctx := telegram.WithoutOutbox(context.Background())
 			formattedText := telegram.FormatHTML(textToSend)
 			chunks := telegram.SplitMessage(formattedText, 4096)
 
@@ -589,60 +869,45 @@ func (b *Bridge) handleMessagePartUpdated(event opencode.Event) {
 	}
 }
 
-func (b *Bridge) Start(ctx context.Context, sseConsumer *opencode.SSEConsumer) {
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case event, ok := <-sseConsumer.Events():
-				if !ok {
-					return
-				}
-				b.HandleSSEEvent(event)
-			}
-		}
-	}()
-}
-
-func (b *Bridge) handlePermissionAsked(event opencode.Event) {
+func (b *Bridge) handlePermissionAsked(event opencode.Event) error {
 	permEvent, ok := event.Properties.(*opencode.EventPermissionAsked)
 	if !ok {
-		return
+		return nil
 	}
 
 	props := permEvent.Properties
 
 	shortKey := b.registry.Register(props.ID, "p", "")
 
-	msgContent := fmt.Sprintf(
-		"🔐 **Permission Request**\n\n"+
-			"**Permission:** %s\n"+
-			"**Patterns:** %s",
-		props.Permission,
-		strings.Join(props.Patterns, ", "),
-	)
+	msgContent := b.t("permission.title") + "\n\n" +
+		b.t("permission.body", props.Permission, strings.Join(props.Patterns, ", "))
 
 	if len(props.Metadata) > 0 {
-		msgContent += "\n\n**Details:**"
+		msgContent += "\n\n" + b.t("permission.details")
 		for key, value := range props.Metadata {
 			msgContent += fmt.Sprintf("\n• %s: %v", key, value)
 		}
 	}
 
-	keyboard := telegram.BuildPermissionKeyboard(shortKey)
+	keyboard := telegram.BuildPermissionKeyboard(shortKey, b.lang())
 
 	ctx := context.Background()
 	msgID, err := b.tgBot.SendMessageWithKeyboard(ctx, msgContent, keyboard)
 	if err != nil {
-		return
+		return fmt.Errorf("send permission %s: %w", props.ID, err)
 	}
 
-	b.permissions.Store(shortKey, PermissionState{
+	request := props.Permission
+	if len(props.Patterns) > 0 {
+		request += " (" + strings.Join(props.Patterns, ", ") + ")"
+	}
+	b.storePermission(shortKey, PermissionState{
 		PermissionID: props.ID,
 		SessionID:    props.SessionID,
 		MessageID:    msgID,
+		Request:      request,
 	})
+	return nil
 }
 
 func (b *Bridge) HandlePermissionCallback(ctx context.Context, shortKey string, response string) error {
@@ -665,102 +930,158 @@ func (b *Bridge) HandlePermissionCallback(ctx context.Context, shortKey string,
 		return fmt.Errorf("invalid permission response: %s", response)
 	}
 
+	// Forget the prompt before replying, so the permission.replied event
+	// for this reply is not taken for an answer from elsewhere
+	b.deletePermission(shortKey)
 	err := b.ocClient.ReplyPermission(permState.SessionID, permState.PermissionID, permResponse)
 	if err != nil {
+		b.storePermission(shortKey, permState)
 		return fmt.Errorf("reply permission: %w", err)
 	}
+	b.audit.record(ctx, b.chatID, state.AuditPermission,
+		fmt.Sprintf("%s: %s in %s", response, permState.Request, permState.SessionID))
+
+	editedMsg := b.t("permission.title") + "\n\n" + b.permissionStatus(permResponse)
+	err = b.tgBot.EditMessage(ctx, permState.MessageID, editedMsg)
+	if err != nil {
+		return fmt.Errorf("edit message: %w", err)
+	}
 
-	var statusMsg string
-	switch permResponse {
+	return nil
+}
+
+func (b *Bridge) permissionStatus(response opencode.PermissionResponse) string {
+	switch response {
 	case opencode.PermissionOnce:
-		statusMsg = "✅ Allowed (once)"
+		return b.t("permission.allowed_once")
 	case opencode.PermissionAlways:
-		statusMsg = "✅ Allowed (always)"
+		return b.t("permission.allowed_always")
 	case opencode.PermissionReject:
-		statusMsg = "❌ Rejected"
+		return b.t("permission.rejected")
 	}
+	return string(response)
+}
 
-	editedMsg := fmt.Sprintf("🔐 **Permission Request**\n\n%s", statusMsg)
-	err = b.tgBot.EditMessage(ctx, permState.MessageID, editedMsg)
-	if err != nil {
-		return fmt.Errorf("edit message: %w", err)
+// handlePermissionReplied closes a permission prompt answered outside
+// Telegram, e.g. in the TUI, so its keyboard does not linger
+func (b *Bridge) handlePermissionReplied(event opencode.Event) {
+	replied, ok := event.Properties.(*opencode.EventPermissionReplied)
+	if !ok {
+		return
 	}
 
-	b.permissions.Delete(shortKey)
+	var permState PermissionState
+	var found bool
+	b.permissions.Range(func(key, value interface{}) bool {
+		if value.(PermissionState).PermissionID == replied.Properties.RequestID {
+			_, found = b.permissions.LoadAndDelete(key)
+			permState = value.(PermissionState)
+			b.state.RemovePending(key.(string))
+			return false
+		}
+		return true
+	})
+	if !found {
+		return
+	}
 
-	return nil
+	editedMsg := b.t("permission.title") + "\n\n" + b.permissionStatus(replied.Properties.Reply) +
+		"\n" + b.t("permission.answered_elsewhere")
+	if err := b.tgBot.EditMessage(context.Background(), permState.MessageID, editedMsg); err != nil {
+		b.logger.Warn("Failed to close permission prompt", "permission", replied.Properties.RequestID, "session", permState.SessionID, "error", err)
+	}
 }
 
 // HandlePhotoMessage handles photo messages with vision API integration
 func (b *Bridge) HandlePhotoMessage(ctx context.Context, photos []models.PhotoSize, caption string, botToken string) error {
-	sessionID := b.state.GetCurrentSession()
+	return b.handlePhotos(ctx, [][]models.PhotoSize{photos}, caption, botToken)
+}
+
+// handlePhotos sends one or more photos (each given as its available sizes) in a single prompt
+func (b *Bridge) handlePhotos(ctx context.Context, photoSets [][]models.PhotoSize, caption string, botToken string) error {
+	label := b.t("processing.image")
+	if len(photoSets) > 1 {
+		label = b.t("processing.images", len(photoSets))
+	}
+
+	sessionID, thinkingMsgID, started, err := b.beginMediaPrompt(ctx, label)
+	if !started {
+		return err
+	}
+
+	go b.sendPhotoPromptAsync(context.Background(), sessionID, photoSets, caption, botToken, thinkingMsgID)
+	return nil
+}
+
+// beginMediaPrompt resolves (or creates) the current session, applies the busy
+// guard, and posts the thinking message with progress. started is false when
+// the request was turned away or failed; err is set only for failures.
+func (b *Bridge) beginMediaPrompt(ctx context.Context, label string) (sessionID string, thinkingMsgID int, started bool, err error) {
+	sessionID = b.sessions.current(ctx)
 
 	if sessionID == "" {
 		title := "Telegram Chat"
 		session, err := b.ocClient.CreateSession(&title, nil)
 		if err != nil {
-			return fmt.Errorf("create session: %w", err)
+			return "", 0, false, fmt.Errorf("create session: %w", err)
 		}
 		sessionID = session.ID
-		b.state.SetCurrentSession(sessionID)
+		b.sessions.set(ctx, sessionID)
 	}
 
 	// Check if session is busy
 	if b.state.GetSessionStatus(sessionID) == state.SessionBusy {
-		_, err := b.tgBot.SendMessage(ctx, "⏳ Still processing your previous request...")
-		return err
+		_, err := b.tgBot.SendMessage(ctx, b.t("busy"))
+		return sessionID, 0, false, err
 	}
 
 	b.state.SetSessionStatus(sessionID, state.SessionBusy)
+	b.rememberTrigger(ctx, sessionID)
 
-	thinkingMsgID, err := b.tgBot.SendMessage(ctx, "🖼️ Processing image...")
+	thinkingMsgID, err = b.sendPlaceholder(ctx, label)
 	if err != nil {
 		b.state.SetSessionStatus(sessionID, state.SessionIdle)
-		return err
+		return sessionID, 0, false, err
 	}
 
 	b.thinkingMsgs.Store(sessionID, thinkingMsgID)
+	b.startProgress(context.Background(), sessionID, thinkingMsgID, label)
 	_ = b.tgBot.SendTyping(ctx)
 
-	go b.sendPhotoPromptAsync(context.Background(), sessionID, photos, caption, botToken, thinkingMsgID)
-	return nil
+	return sessionID, thinkingMsgID, true, nil
 }
 
-func (b *Bridge) sendPhotoPromptAsync(ctx context.Context, sessionID string, photos []models.PhotoSize, caption string, botToken string, thinkingMsgID int) {
-	agent := b.state.GetCurrentAgent()
-
-	largestPhoto := telegram.GetLargestPhoto(photos)
-	if largestPhoto == nil {
-		errorMsg := "❌ Error: No valid photo found"
-		if editErr := b.tgBot.EditMessagePlain(context.Background(), thinkingMsgID, errorMsg); editErr != nil {
-			log.Printf("[ERROR] Failed to edit error message: %v", editErr)
-			b.tgBot.SendMessagePlain(context.Background(), errorMsg)
+func (b *Bridge) sendPhotoPromptAsync(ctx context.Context, sessionID string, photoSets [][]models.PhotoSize, caption string, botToken string, thinkingMsgID int) {
+	images := make([][]byte, 0, len(photoSets))
+	for _, photos := range photoSets {
+		largestPhoto := telegram.GetLargestPhoto(photos)
+		if largestPhoto == nil {
+			b.failPrompt(sessionID, thinkingMsgID, b.t("photo.invalid"))
+			return
 		}
-		b.state.SetSessionStatus(sessionID, state.SessionError)
-		b.thinkingMsgs.Delete(sessionID)
-		return
-	}
 
-	photoData, err := telegram.DownloadPhoto(ctx, botToken, largestPhoto.FileID)
-	if err != nil {
-		errorMsg := fmt.Sprintf("❌ Error downloading image: %s", err.Error())
-		if editErr := b.tgBot.EditMessagePlain(context.Background(), thinkingMsgID, errorMsg); editErr != nil {
-			log.Printf("[ERROR] Failed to edit error message: %v", editErr)
-			b.tgBot.SendMessagePlain(context.Background(), errorMsg)
+		photoData, err := b.downloadFile(ctx, botToken, largestPhoto.FileID)
+		if err != nil {
+			b.failPrompt(sessionID, thinkingMsgID, b.t("photo.download_failed", err.Error()))
+			return
 		}
-		b.state.SetSessionStatus(sessionID, state.SessionError)
-		b.thinkingMsgs.Delete(sessionID)
-		return
+		images = append(images, photoData)
 	}
 
-	base64Image := telegram.EncodeBase64(photoData)
+	b.sendImagePromptAsync(ctx, sessionID, images, b.photoCaption(caption), thinkingMsgID)
+}
+
+// sendImagePromptAsync sends images plus an optional text part as one prompt
+func (b *Bridge) sendImagePromptAsync(ctx context.Context, sessionID string, images [][]byte, caption string, thinkingMsgID int) {
+	agent := b.getEffectiveAgent()
 
-	parts := []interface{}{
-		opencode.ImagePartInput{
+	parts := make([]interface{}, 0, len(images)+1)
+	for _, data := range images {
+		parts = append(parts, opencode.ImagePartInput{
 			Type:     "image",
-			Image:    base64Image,
-			MimeType: "image/jpeg",
-		},
+			Image:    telegram.EncodeBase64(data),
+			MimeType: telegram.ImageMimeType(data),
+		})
 	}
 
 	if caption != "" {
@@ -771,16 +1092,12 @@ func (b *Bridge) sendPhotoPromptAsync(ctx context.Context, sessionID string, pho
 	}
 
 	go func() {
-		_, err := b.ocClient.SendPromptWithParts(sessionID, parts, &agent)
+		_, err := b.ocClient.SendPromptWithParts(sessionID, parts, b.promptOptions(agent))
 		if err != nil {
-			errorMsg := fmt.Sprintf("❌ Error: %s", err.Error())
-			if editErr := b.tgBot.EditMessagePlain(context.Background(), thinkingMsgID, errorMsg); editErr != nil {
-				log.Printf("[ERROR] Failed to edit error message: %v", editErr)
-				b.tgBot.SendMessagePlain(context.Background(), errorMsg)
-			}
-			b.state.SetSessionStatus(sessionID, state.SessionError)
-			b.thinkingMsgs.Delete(sessionID)
+			b.failPrompt(sessionID, thinkingMsgID, b.errorText(err))
+			return
 		}
+		b.recordPrompt(sessionID)
 	}()
 
 	go func() {
@@ -803,12 +1120,15 @@ func (b *Bridge) sendPhotoPromptAsync(ctx context.Context, sessionID string, pho
 
 // HandleUnsupportedMedia handles unsupported media types
 func (b *Bridge) HandleUnsupportedMedia(ctx context.Context) error {
-	_, err := b.tgBot.SendMessage(ctx, "⚠️ 目前僅支援圖片分析,其他媒體類型暫不支援")
+	_, err := b.tgBot.SendMessage(ctx, b.t("media.unsupported"))
 	return err
 }
 
 func (b *Bridge) HandleReaction(ctx context.Context, messageID int, userID int64, newReaction []models.ReactionType) error {
-	sessionID := b.state.GetCurrentSession()
+	if b.noReactions {
+		return nil
+	}
+	sessionID := b.sessions.current(telegram.WithUserID(ctx, userID))
 	if sessionID == "" {
 		return nil
 	}
@@ -826,147 +1146,270 @@ func (b *Bridge) HandleReaction(ctx context.Context, messageID int, userID int64
 	}
 
 	notificationText := fmt.Sprintf("[User reacted with %s to your previous response]", reactionStr)
-	agent := b.state.GetCurrentAgent()
-	_, err := b.ocClient.SendPrompt(sessionID, notificationText, &agent)
+	if ref, ok := b.state.LookupMessage(b.chatID, messageID); ok {
+		// The reaction is on a known response, which may be older or from
+		// another session than the current one
+		sessionID = ref.SessionID
+		notificationText = fmt.Sprintf("[User reacted with %s to your response %s]", reactionStr, ref.MessageID)
+	}
+	agent := b.getEffectiveAgent()
+	_, err := b.ocClient.SendPrompt(sessionID, notificationText, b.promptOptions(agent))
 	return err
 }
 
+// handleSwitchCommand handles /switch [agent]
+func (b *Bridge) handleSwitchCommand(ctx context.Context, args string) {
+	agents := availableAgents(b.ocClient)
+
+	agent := strings.TrimSpace(args)
+	if agent == "" {
+		msg := b.t("agent.select")
+		for i, a := range agents {
+			msg += fmt.Sprintf("%d. %s\n", i+1, a)
+		}
+		b.tgBot.SendMessage(ctx, msg)
+		return
+	}
+
+	if !isValidAgent(agent, agents) {
+		msg := b.t("agent.unknown", agent)
+		for _, a := range agents {
+			msg += fmt.Sprintf("• %s\n", a)
+		}
+		b.tgBot.SendMessage(ctx, msg)
+		return
+	}
+
+	b.state.SetCurrentAgent(agent)
+	b.audit.record(ctx, b.chatID, state.AuditAgentSwitch, agent)
+	b.tgBot.SendMessage(ctx, b.t("agent.switched", agent))
+}
+
 func (b *Bridge) RegisterHandlers() {
 	b.tgBot.(*telegram.Bot).RegisterTextHandler(func(ctx context.Context, text string) {
 		if b.HandleQuestionCustomInput(ctx, text) {
 			return
 		}
+		if handled, err := b.HandleQuickAction(ctx, text); handled {
+			if err != nil {
+				b.tgBot.SendMessage(ctx, b.errorText(err))
+			}
+			return
+		}
 		if err := b.HandleUserMessage(ctx, text); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	cmdHandler := NewCommandHandler(b.ocClient, b.tgBot, b.state)
+	cmdHandler := b.cmdHandler
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("newsession", func(ctx context.Context, args string) {
+	b.registerCommand("newsession", func(ctx context.Context, args string) {
 		var title *string
 		if args != "" {
 			title = &args
 		}
 		if err := cmdHandler.HandleNewSession(ctx, title); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("fork", func(ctx context.Context, args string) {
+		var title *string
+		if args = strings.TrimSpace(args); args != "" {
+			title = &args
+		}
+		if err := cmdHandler.HandleForkSession(ctx, title); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("sessions", func(ctx context.Context, args string) {
+	b.registerCommand("sessions", func(ctx context.Context, args string) {
 		if err := cmdHandler.HandleListSessions(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("session", func(ctx context.Context, args string) {
+	b.registerCommand("session", func(ctx context.Context, args string) {
 		sessionID := strings.TrimSpace(args)
 		if sessionID == "" {
-			b.tgBot.SendMessage(ctx, "❌ Please provide a session ID: /session <id>")
+			b.tgBot.SendMessage(ctx, b.t("session.id_required"))
 			return
 		}
 		if err := cmdHandler.HandleSwitchSession(ctx, sessionID); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("selectsession", func(ctx context.Context, args string) {
+	b.registerCommand("selectsession", func(ctx context.Context, args string) {
 		if err := cmdHandler.HandleSelectSession(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("abort", func(ctx context.Context, args string) {
+	b.registerCommand("abort", func(ctx context.Context, args string) {
 		if err := cmdHandler.HandleAbortSession(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("deletesession", func(ctx context.Context, args string) {
+	b.registerCommand("deletesession", func(ctx context.Context, args string) {
 		sessionID := strings.TrimSpace(args)
 		if sessionID == "" {
 			if err := cmdHandler.HandleDeleteSessionMenu(ctx); err != nil {
-				b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+				b.tgBot.SendMessage(ctx, b.errorText(err))
 			}
 			return
 		}
 		if err := cmdHandler.HandleDeleteSession(ctx, sessionID); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("deletesessions", func(ctx context.Context, args string) {
+	b.registerCommand("deletesessions", func(ctx context.Context, args string) {
 		if err := cmdHandler.HandleDeleteSessionMenu(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("status", func(ctx context.Context, args string) {
+	b.registerCommand("status", func(ctx context.Context, args string) {
 		if err := cmdHandler.HandleStatus(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("help", func(ctx context.Context, args string) {
+	b.registerCommand("help", func(ctx context.Context, args string) {
 		if err := cmdHandler.HandleHelp(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("switch", func(ctx context.Context, args string) {
-		var agent *string
-		if args != "" {
-			agent = &args
-		}
-		agents, _ := b.ocClient.(interface{ GetAgents() ([]string, error) })
-		if getter, ok := agents.(interface{ GetAgents() ([]string, error) }); ok {
-			availableAgents, _ := getter.GetAgents()
-			if agent != nil && *agent != "" {
-				valid := false
-				for _, a := range availableAgents {
-					if a == *agent {
-						valid = true
-						break
-					}
-				}
-				if valid {
-					b.state.SetCurrentAgent(*agent)
-					b.tgBot.SendMessage(ctx, fmt.Sprintf("🔄 Switched to %s", *agent))
-				} else {
-					msg := fmt.Sprintf("❌ Unknown agent: %s\n\nAvailable agents:\n", *agent)
-					for _, a := range availableAgents {
-						msg += fmt.Sprintf("• %s\n", a)
-					}
-					b.tgBot.SendMessage(ctx, msg)
-				}
-			} else {
-				msg := "🤖 Select an OHO Agent:\n\n"
-				for i, a := range availableAgents {
-					msg += fmt.Sprintf("%d. %s\n", i+1, a)
-				}
-				b.tgBot.SendMessage(ctx, msg)
-			}
+	b.registerCommand("switch", func(ctx context.Context, args string) {
+		b.handleSwitchCommand(ctx, args)
+		b.refreshBanner(ctx)
+	})
+
+	b.registerCommand("keyboard", func(ctx context.Context, args string) {
+		if err := b.HandleKeyboardCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("lang", func(ctx context.Context, args string) {
+		if err := b.HandleLangCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("photoprompt", func(ctx context.Context, args string) {
+		if err := b.HandlePhotoPromptCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("audit", func(ctx context.Context, args string) {
+		if err := b.HandleAuditCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("fav", func(ctx context.Context, args string) {
+		if err := b.HandleFavCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("debounce", func(ctx context.Context, args string) {
+		if err := b.HandleDebounceCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("loglevel", func(ctx context.Context, args string) {
+		if err := b.HandleLogLevelCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("version", func(ctx context.Context, args string) {
+		if err := b.HandleVersionCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("feedback", func(ctx context.Context, args string) {
+		if err := b.HandleFeedbackCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("stats", func(ctx context.Context, args string) {
+		if err := b.HandleStatsCommand(ctx); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("init", func(ctx context.Context, args string) {
+		if err := b.HandleInitCommand(ctx); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.registerCommand("server", func(ctx context.Context, args string) {
+		if err := b.HandleServerCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("srv:", func(ctx context.Context, callbackID string, data string, messageID int) {
+		b.tgBot.AnswerCallback(ctx, callbackID)
+		if b.servers == nil {
+			return
+		}
+		if err := b.switchServer(ctx, strings.TrimPrefix(data, "srv:")); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
 	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("agent:", func(ctx context.Context, callbackID string, data string, messageID int) {
 		agentName := strings.TrimPrefix(data, "agent:")
 		b.state.SetCurrentAgent(agentName)
+		b.audit.record(ctx, b.chatID, state.AuditAgentSwitch, agentName)
 		b.tgBot.AnswerCallback(ctx, callbackID)
+		b.refreshBanner(ctx)
 	})
 
 	modelHandler := NewModelHandler(b.tgBot, b.state, b.ocClient)
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("model", func(ctx context.Context, args string) {
-		log.Println("[BRIDGE] /model command handler called")
+	modelHandler.chatID = b.chatID
+	modelHandler.translator = translator{lang: b.lang}
+	modelHandler.audit = b.audit
+	modelHandler.logger = b.logger
+	b.models = modelHandler
+	b.registerCommand("model", func(ctx context.Context, args string) {
+		b.logger.Debug("/model command handler called")
 		if err := modelHandler.HandleModelCommand(ctx); err != nil {
-			log.Printf("[BRIDGE] ModelHandler error: %v", err)
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.logger.Error("ModelHandler error", "error", err)
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
 	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("mdl:", func(ctx context.Context, callbackID string, data string, messageID int) {
 		if err := modelHandler.HandleModelCallback(ctx, messageID, data); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+		b.tgBot.AnswerCallback(ctx, callbackID)
+		b.refreshBanner(ctx)
+	})
+
+	b.tgBot.(*telegram.Bot).RegisterCallbackHandler(responseActionPrefix, func(ctx context.Context, callbackID string, data string, messageID int) {
+		b.tgBot.AnswerCallback(ctx, callbackID)
+		if err := b.HandleResponseAction(ctx, data); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	b.tgBot.(*telegram.Bot).RegisterCallbackHandler(pagesPrefix+":", func(ctx context.Context, callbackID string, data string, messageID int) {
+		if err := b.HandleShowMore(ctx, messageID, data); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
@@ -974,7 +1417,7 @@ func (b *Bridge) RegisterHandlers() {
 	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("sess:", func(ctx context.Context, callbackID string, data string, messageID int) {
 		sessionID := strings.TrimPrefix(data, "sess:")
 		if err := cmdHandler.HandleSwitchSession(ctx, sessionID); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
@@ -984,7 +1427,7 @@ func (b *Bridge) RegisterHandlers() {
 		page := 0
 		fmt.Sscanf(pageStr, "%d", &page)
 		if err := cmdHandler.HandleSessionPageCallback(ctx, page); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
@@ -992,7 +1435,7 @@ func (b *Bridge) RegisterHandlers() {
 	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("del:", func(ctx context.Context, callbackID string, data string, messageID int) {
 		sessionID := strings.TrimPrefix(data, "del:")
 		if err := cmdHandler.HandleDeleteConfirmCallback(ctx, sessionID); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
@@ -1002,7 +1445,7 @@ func (b *Bridge) RegisterHandlers() {
 		page := 0
 		fmt.Sscanf(pageStr, "%d", &page)
 		if err := cmdHandler.HandleDeleteSessionPageCallback(ctx, page); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
@@ -1010,13 +1453,13 @@ func (b *Bridge) RegisterHandlers() {
 	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("delconfirm:", func(ctx context.Context, callbackID string, data string, messageID int) {
 		sessionID := strings.TrimPrefix(data, "delconfirm:")
 		if err := cmdHandler.HandleDeleteExecuteCallback(ctx, sessionID); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
 
 	b.tgBot.(*telegram.Bot).RegisterCallbackHandler("delcancel", func(ctx context.Context, callbackID string, data string, messageID int) {
-		b.tgBot.SendMessage(ctx, "❌ Deletion cancelled")
+		b.tgBot.SendMessage(ctx, b.t("delete.cancelled"))
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
 
@@ -1025,7 +1468,7 @@ func (b *Bridge) RegisterHandlers() {
 		// e.g., "q:2:0:0" or "q:2:0:submit"
 		parts := strings.SplitN(data, ":", 4)
 		if len(parts) < 4 {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Invalid callback data: %s", data))
+			b.tgBot.SendMessage(ctx, b.t("callback.invalid", data))
 			b.tgBot.AnswerCallback(ctx, callbackID)
 			return
 		}
@@ -1034,39 +1477,87 @@ func (b *Bridge) RegisterHandlers() {
 		action := parts[3]
 
 		if err := b.HandleQuestionCallback(ctx, shortKey, action); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 		b.tgBot.AnswerCallback(ctx, callbackID)
 	})
 
-	b.tgBot.(*telegram.Bot).RegisterPhotoHandler(func(ctx context.Context, photos []models.PhotoSize, caption string, botToken string) {
+	b.tgBot.(*telegram.Bot).RegisterPhotoHandler(func(ctx context.Context, photos []models.PhotoSize, caption string, mediaGroupID string, botToken string) {
+		if mediaGroupID != "" {
+			b.HandleAlbumPhoto(ctx, mediaGroupID, photos, caption, botToken)
+			return
+		}
 		if err := b.HandlePhotoMessage(ctx, photos, caption, botToken); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
 	stickerHandler := NewStickerHandler(b.ocClient, b.tgBot, b.state)
-	b.tgBot.(*telegram.Bot).RegisterStickerHandler(func(ctx context.Context, emoji string, setName string) {
-		if err := stickerHandler.HandleSticker(ctx, emoji, setName); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+	stickerHandler.translator = translator{lang: b.lang}
+	stickerHandler.sessionFor = b.sessions.current
+	stickerHandler.promptOptions = func() opencode.PromptOptions {
+		return b.promptOptions(b.getEffectiveAgent())
+	}
+	b.tgBot.(*telegram.Bot).RegisterStickerHandler(func(ctx context.Context, emoji string, setName string, frame *models.PhotoSize, botToken string) {
+		var err error
+		if frame != nil {
+			err = b.HandleStickerFrame(ctx, *frame, emoji, setName, botToken)
+		} else {
+			err = stickerHandler.HandleSticker(ctx, emoji, setName)
+		}
+		if err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	if b.transcriber != nil {
+		b.tgBot.(*telegram.Bot).RegisterAudioHandler(func(ctx context.Context, audio telegram.AudioFile, caption string, botToken string) {
+			if err := b.HandleAudioMessage(ctx, audio, caption, botToken); err != nil {
+				b.tgBot.SendMessage(ctx, b.errorText(err))
+			}
+		})
+	}
+
+	b.tgBot.(*telegram.Bot).RegisterVideoHandler(func(ctx context.Context, video telegram.VideoFile, caption string, botToken string) {
+		if err := b.HandleVideoMessage(ctx, video, caption, botToken); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
 	b.tgBot.(*telegram.Bot).RegisterUnsupportedMediaHandler(func(ctx context.Context) {
 		if err := b.HandleUnsupportedMedia(ctx); err != nil {
-			b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Error: %v", err))
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
 
 	b.tgBot.(*telegram.Bot).RegisterReactionHandler(func(ctx context.Context, messageID int, userID int64, newReaction []models.ReactionType) {
 		if err := b.HandleReaction(ctx, messageID, userID, newReaction); err != nil {
-			fmt.Printf("[BRIDGE] Error handling reaction: %v\n", err)
+			b.logger.Error("Error handling reaction", "telegram_message", messageID, "error", err)
+		}
+	})
+
+	// Before /alias, which would otherwise match by prefix
+	b.registerCommand("alias-session", func(ctx context.Context, args string) {
+		if err := b.HandleAliasSessionCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+		}
+	})
+
+	// Alias names and steps are checked against b.commands when /alias runs,
+	// so every built-in command counts wherever it is registered
+	b.registerCommand("alias", func(ctx context.Context, args string) {
+		if err := b.HandleAliasCommand(ctx, args); err != nil {
+			b.tgBot.SendMessage(ctx, b.errorText(err))
 		}
 	})
+	b.tgBot.(*telegram.Bot).RegisterDynamicCommandHandler(b.isAlias, b.runAlias)
 
 	routingHandler := NewRoutingHandler(b.state, b.tgBot)
-	b.tgBot.(*telegram.Bot).RegisterCommandHandler("route", func(ctx context.Context, args string) {
+	routingHandler.translator = translator{lang: b.lang}
+	routingHandler.audit = b.audit
+	b.registerCommand("route", func(ctx context.Context, args string) {
 		routingHandler.HandleRouteCommand(ctx, b.chatID, args)
+		b.refreshBanner(ctx)
 	})
 
 }
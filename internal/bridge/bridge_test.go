@@ -2,6 +2,7 @@ package bridge
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/user/opencode-telegram/internal/i18n"
 	"github.com/user/opencode-telegram/internal/opencode"
 	"github.com/user/opencode-telegram/internal/state"
 )
@@ -28,16 +30,16 @@ func (m *MockOpenCodeClient) CreateSession(title *string, parentID *string) (*op
 	return args.Get(0).(*opencode.Session), args.Error(1)
 }
 
-func (m *MockOpenCodeClient) SendPrompt(sessionID, text string, agent *string) (*opencode.SendPromptResponse, error) {
-	args := m.Called(sessionID, text, agent)
+func (m *MockOpenCodeClient) SendPrompt(sessionID, text string, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error) {
+	args := m.Called(sessionID, text, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*opencode.SendPromptResponse), args.Error(1)
 }
 
-func (m *MockOpenCodeClient) SendPromptWithParts(sessionID string, parts []interface{}, agent *string) (*opencode.SendPromptResponse, error) {
-	args := m.Called(sessionID, parts, agent)
+func (m *MockOpenCodeClient) SendPromptWithParts(sessionID string, parts []interface{}, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error) {
+	args := m.Called(sessionID, parts, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -49,6 +51,11 @@ func (m *MockOpenCodeClient) AbortSession(sessionID string) error {
 	return args.Error(0)
 }
 
+func (m *MockOpenCodeClient) InitSession(sessionID, providerID, modelID string) error {
+	args := m.Called(sessionID, providerID, modelID)
+	return args.Error(0)
+}
+
 func (m *MockOpenCodeClient) ListSessions() ([]opencode.Session, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -75,6 +82,30 @@ func (m *MockOpenCodeClient) ReplyQuestion(requestID string, answers []opencode.
 	return args.Error(0)
 }
 
+func (m *MockOpenCodeClient) ListPermissions() ([]opencode.PermissionRequest, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]opencode.PermissionRequest), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) ListQuestions() ([]opencode.QuestionRequest, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]opencode.QuestionRequest), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) ListSessionStatuses() (map[string]opencode.SessionStatus, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]opencode.SessionStatus), args.Error(1)
+}
+
 func (m *MockOpenCodeClient) GetConfig() (map[string]interface{}, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -83,11 +114,64 @@ func (m *MockOpenCodeClient) GetConfig() (map[string]interface{}, error) {
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
-func (m *MockOpenCodeClient) TriggerPrompt(sessionID, text string, agent *string) error {
-	args := m.Called(sessionID, text, agent)
+func (m *MockOpenCodeClient) TriggerPrompt(sessionID, text string, opts opencode.PromptOptions) error {
+	args := m.Called(sessionID, text, opts)
 	return args.Error(0)
 }
 
+func (m *MockOpenCodeClient) DeleteSession(sessionID string) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
+func (m *MockOpenCodeClient) GetSessionSummary(sessionID string) (*opencode.SessionSummary, error) {
+	args := m.Called(sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opencode.SessionSummary), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) GetMessages(sessionID string, limit int) ([]opencode.Message, error) {
+	args := m.Called(sessionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]opencode.Message), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) GetMessage(sessionID string, messageID string) (*opencode.Message, error) {
+	args := m.Called(sessionID, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opencode.Message), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) GetProviders() (*opencode.ProvidersResponse, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opencode.ProvidersResponse), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) GetAgents() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockOpenCodeClient) GetFileContent(path string) (*opencode.FileContent, error) {
+	args := m.Called(path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opencode.FileContent), args.Error(1)
+}
+
 type MockTelegramBot struct {
 	mock.Mock
 	mu             sync.Mutex
@@ -122,6 +206,16 @@ func (m *MockTelegramBot) SendMessageWithKeyboard(ctx context.Context, text stri
 	return m.lastMessageID, args.Error(1)
 }
 
+func (m *MockTelegramBot) SendMessageWithReplyMarkup(ctx context.Context, text string, markup models.ReplyMarkup) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.Called(ctx, text, markup)
+	m.lastMessageID++
+	m.sentMessages = append(m.sentMessages, text)
+	return m.lastMessageID, args.Error(1)
+}
+
 func (m *MockTelegramBot) EditMessage(ctx context.Context, messageID int, text string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -131,6 +225,15 @@ func (m *MockTelegramBot) EditMessage(ctx context.Context, messageID int, text s
 	return args.Error(0)
 }
 
+func (m *MockTelegramBot) EditMessageWithKeyboard(ctx context.Context, messageID int, text string, keyboard *models.InlineKeyboardMarkup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.Called(ctx, messageID, text, keyboard)
+	m.editedMessages[messageID] = append(m.editedMessages[messageID], text)
+	return args.Error(0)
+}
+
 func (m *MockTelegramBot) AnswerCallback(ctx context.Context, callbackID string) error {
 	args := m.Called(ctx, callbackID)
 	return args.Error(0)
@@ -141,6 +244,60 @@ func (m *MockTelegramBot) SendTyping(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockTelegramBot) SendPhoto(ctx context.Context, data []byte, filename string, caption string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.Called(ctx, data, filename, caption)
+	m.lastMessageID++
+	return m.lastMessageID, args.Error(1)
+}
+
+func (m *MockTelegramBot) SetReaction(ctx context.Context, messageID int, emoji string) error {
+	args := m.Called(ctx, messageID, emoji)
+	return args.Error(0)
+}
+
+func (m *MockTelegramBot) SendMessageSilent(ctx context.Context, text string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.Called(ctx, text)
+	m.lastMessageID++
+	m.sentMessages = append(m.sentMessages, text)
+	return m.lastMessageID, args.Error(1)
+}
+
+func (m *MockTelegramBot) SendMessageReply(ctx context.Context, text string, replyTo int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.Called(ctx, text, replyTo)
+	m.lastMessageID++
+	m.sentMessages = append(m.sentMessages, text)
+	return m.lastMessageID, args.Error(1)
+}
+
+func (m *MockTelegramBot) DeleteMessage(ctx context.Context, messageID int) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockTelegramBot) PinMessage(ctx context.Context, messageID int) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockTelegramBot) SendMessageToChat(ctx context.Context, chatID int64, text string) (int, error) {
+	args := m.Called(ctx, chatID, text)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTelegramBot) SetChatCommands(ctx context.Context, lang i18n.Lang) error {
+	args := m.Called(ctx, lang)
+	return args.Error(0)
+}
+
 func (m *MockTelegramBot) SendMessagePlain(ctx context.Context, text string) (int, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -187,14 +344,66 @@ func TestBridgeHandleUserMessage_CreatesSessionIfNotExists(t *testing.T) {
 	}
 	mockOC.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
 	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).Return(nil)
-	mockTG.On("SendMessage", ctx, "⏳ Processing...").Return(1, nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
 	mockTG.On("SendTyping", ctx).Return(nil)
 	mockTG.On("EditMessage", ctx, 1, mock.Anything).Return(nil)
 
 	err := bridge.HandleUserMessage(ctx, "Hello")
 
 	assert.NoError(t, err)
-	assert.Equal(t, "ses_123", appState.GetCurrentSession())
+	assert.Equal(t, "ses_123", appState.GetSessionForChat(""))
+}
+
+func TestBridgeFlushDebounceBufferOnce(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Hour)
+	ctx := context.Background()
+
+	triggered := make(chan struct{}, 2)
+	mockOC.On("TriggerPrompt", "ses_123", "Hello\nWorld", mock.Anything).
+		Run(func(mock.Arguments) { triggered <- struct{}{} }).Return(nil)
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "Hello"))
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "World"))
+
+	// A stale timer firing alongside the current one must not resend
+	val, _ := bridge.debounceBuffers.Load("ses_123")
+	buf := val.(*DebounceBuffer)
+	buf.timer.Stop()
+	bridge.flushDebounceBuffer("ses_123", buf)
+	bridge.flushDebounceBuffer("ses_123", buf)
+
+	<-triggered
+	time.Sleep(50 * time.Millisecond)
+	mockOC.AssertNumberOfCalls(t, "TriggerPrompt", 1)
+	_, pending := bridge.debounceBuffers.Load("ses_123")
+	assert.False(t, pending)
+}
+
+func TestBridgeHandleUserMessage_FlushedBufferStartsNewOne(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Hour)
+
+	// A buffer flushed while the message waited for it
+	stale := &DebounceBuffer{messages: []string{"Hello"}, flushed: true}
+	bridge.debounceBuffers.Store("ses_123", stale)
+
+	assert.NoError(t, bridge.HandleUserMessage(context.Background(), "World"))
+
+	val, _ := bridge.debounceBuffers.Load("ses_123")
+	buf := val.(*DebounceBuffer)
+	buf.timer.Stop()
+	assert.NotSame(t, stale, buf)
+	assert.Equal(t, []string{"World"}, buf.messages)
+	mockTG.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
 }
 
 func TestBridgeHandleUserMessage_BusySession(t *testing.T) {
@@ -240,7 +449,7 @@ func TestBridgeHandleUserMessage_LongResponse(t *testing.T) {
 
 	mockOC.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
 	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).Return(nil)
-	mockTG.On("SendMessage", ctx, "⏳ Processing...").Return(1, nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
 	mockTG.On("EditMessage", ctx, 1, mock.Anything).Return(nil)
 	mockTG.On("SendTyping", ctx).Return(nil)
 	mockTG.On("SendMessage", ctx, mock.Anything).Return(2, nil)
@@ -248,7 +457,7 @@ func TestBridgeHandleUserMessage_LongResponse(t *testing.T) {
 	err := bridge.HandleUserMessage(ctx, "Hello")
 
 	assert.NoError(t, err)
-	assert.Equal(t, "ses_123", appState.GetCurrentSession())
+	assert.Equal(t, "ses_123", appState.GetSessionForChat(""))
 }
 
 func TestBridgeHandleUserMessage_NoSession(t *testing.T) {
@@ -267,16 +476,16 @@ func TestBridgeHandleUserMessage_NoSession(t *testing.T) {
 
 	mockOC.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
 	mockOC.On("TriggerPrompt", "ses_new", "First message", mock.Anything).Return(nil)
-	mockTG.On("SendMessage", ctx, "⏳ Processing...").Return(1, nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
 	mockTG.On("SendTyping", ctx).Return(nil)
 	mockTG.On("EditMessage", ctx, 1, mock.Anything).Return(nil)
 
-	assert.Equal(t, "", appState.GetCurrentSession())
+	assert.Equal(t, "", appState.GetSessionForChat(""))
 
 	err := bridge.HandleUserMessage(ctx, "First message")
 
 	assert.NoError(t, err)
-	assert.Equal(t, "ses_new", appState.GetCurrentSession())
+	assert.Equal(t, "ses_new", appState.GetSessionForChat(""))
 }
 
 func TestBridgeHandleUserMessage_SessionError(t *testing.T) {
@@ -293,7 +502,7 @@ func TestBridgeHandleUserMessage_SessionError(t *testing.T) {
 
 	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).Return(fmt.Errorf("connection failed"))
 	mockTG.On("SendTyping", ctx).Return(nil)
-	mockTG.On("SendMessage", ctx, "⏳ Processing...").Return(1, nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
 	mockTG.On("EditMessagePlain", mock.Anything, 1, mock.MatchedBy(func(msg string) bool {
 		return strings.Contains(msg, "Error") && strings.Contains(msg, "connection failed")
 	})).Return(nil)
@@ -303,6 +512,47 @@ func TestBridgeHandleUserMessage_SessionError(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBridgeRetriesRateLimitedPrompt(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.promptRetryBase = time.Millisecond
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	var messageIDs []string
+	recordID := func(args mock.Arguments) {
+		messageIDs = append(messageIDs, args.Get(2).(opencode.PromptOptions).MessageID)
+	}
+	rateLimited := &opencode.APIError{Op: "trigger prompt", StatusCode: 429, Message: "rate limit exceeded", Retryable: true}
+	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).Run(recordID).Return(rateLimited).Once()
+	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).Run(func(args mock.Arguments) {
+		recordID(args)
+		close(done)
+	}).Return(nil).Once()
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
+	mockTG.On("EditMessagePlain", mock.Anything, 1, "⏳ Model rate limited, retrying...").Return(nil).Once()
+
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "Hello"))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("prompt was not retried")
+	}
+	mockTG.AssertExpectations(t)
+	assert.Equal(t, state.SessionBusy, appState.GetSessionStatus("ses_123"))
+
+	// The retry is the same message, so OpenCode runs the prompt once
+	assert.Len(t, messageIDs, 2)
+	assert.NotEmpty(t, messageIDs[0])
+	assert.Equal(t, messageIDs[0], messageIDs[1])
+}
+
 func TestBridgeHandleSSEEvent_SessionIdle(t *testing.T) {
 	mockOC := new(MockOpenCodeClient)
 	mockTG := NewMockTelegramBot()
@@ -316,10 +566,14 @@ func TestBridgeHandleSSEEvent_SessionIdle(t *testing.T) {
 
 	event := opencode.Event{
 		Type: "session.idle",
-		Properties: &struct {
-			SessionID string `json:"sessionID"`
-		}{
-			SessionID: "ses_123",
+		Properties: &opencode.EventSessionIdle{
+			Type: "session.idle",
+			Properties: struct {
+				SessionID string  `json:"sessionID"`
+				Content   *string `json:"content,omitempty"`
+			}{
+				SessionID: "ses_123",
+			},
 		},
 	}
 
@@ -375,7 +629,7 @@ func TestBridgeThinkingIndicator(t *testing.T) {
 	mockOC.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
 	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).Return(nil)
 
-	mockTG.On("SendMessage", ctx, "⏳ Processing...").Return(1, nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
 	mockTG.On("SendTyping", ctx).Return(nil)
 	mockTG.On("EditMessage", ctx, 1, mock.Anything).Return(nil)
 
@@ -386,5 +640,54 @@ func TestBridgeThinkingIndicator(t *testing.T) {
 	// Wait for debounce timer to fire (100ms + buffer)
 	time.Sleep(150 * time.Millisecond)
 
-	mockTG.AssertCalled(t, "SendMessage", ctx, "⏳ Processing...")
+	mockTG.AssertCalled(t, "SendMessage", mock.Anything, "⏳ Processing...")
+}
+
+func TestCompletedMessageReasoning(t *testing.T) {
+	msg := &opencode.Message{
+		Info: opencode.MessageInfo{ID: "msg_r", SessionID: "ses_r", Role: "assistant"},
+		Parts: []opencode.MessagePart{
+			{Type: "reasoning", Text: "Check the config first"},
+			{Type: "text", Text: "Fixed it"},
+		},
+	}
+	ctx := context.Background()
+
+	// Hidden by default
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	mockOC.On("GetMessage", "ses_r", "msg_r").Return(msg, nil)
+	mockTG.On("SendMessage", ctx, "Fixed it").Return(1, nil).Once()
+
+	bridge.fetchAndSendCompletedMessage("ses_r", "msg_r")
+	mockTG.AssertExpectations(t)
+
+	// Collapsed above the answer when enabled
+	mockOC = new(MockOpenCodeClient)
+	mockTG = NewMockTelegramBot()
+	bridge = NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetShowReasoning(true)
+	mockOC.On("GetMessage", "ses_r", "msg_r").Return(msg, nil)
+	mockTG.On("SendMessage", ctx, "<blockquote expandable>💭 Check the config first</blockquote>\nFixed it").Return(1, nil).Once()
+
+	bridge.fetchAndSendCompletedMessage("ses_r", "msg_r")
+	mockTG.AssertExpectations(t)
+}
+
+func TestCompletedMessageFailedSendIsNotDeduplicated(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+
+	mockTG.On("SendMessage", mock.Anything, "Answer").Return(0, errors.New("network down")).Once()
+	assert.Error(t, bridge.sendCompletedMessageFromWebhook("ses_f", "msg_f", "Answer", 0))
+
+	// The replay is delivered, and only then counts as processed
+	mockTG.On("SendMessage", mock.Anything, "Answer").Return(1, nil).Once()
+	assert.NoError(t, bridge.sendCompletedMessageFromWebhook("ses_f", "msg_f", "Answer", 0))
+	assert.NoError(t, bridge.sendCompletedMessageFromWebhook("ses_f", "msg_f", "Answer", 0))
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNumberOfCalls(t, "SendMessage", 2)
 }
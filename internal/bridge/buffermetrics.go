@@ -0,0 +1,81 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+// bufferMetricsInterval is how often a bridge reports the size of its buffers
+const bufferMetricsInterval = 15 * time.Second
+
+// StartBufferMetrics reports the bridge's debounce and stream buffers and
+// its pending permissions and questions as gauges every 15 seconds. Its
+// gauges are removed once ctx is done.
+func (b *Bridge) StartBufferMetrics(ctx context.Context) {
+	ticker := time.NewTicker(bufferMetricsInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				metrics.DeleteBridgeBuffers(b.account)
+				return
+			case <-ticker.C:
+				b.recordBufferMetrics()
+			}
+		}
+	}()
+}
+
+// recordBufferMetrics sets the buffer gauges from the bridge's maps
+func (b *Bridge) recordBufferMetrics() {
+	var buffers, messages int
+	b.debounceBuffers.Range(func(key, value any) bool {
+		buf := value.(*DebounceBuffer)
+		buf.mu.Lock()
+		if !buf.flushed {
+			buffers++
+			messages += len(buf.messages)
+		}
+		buf.mu.Unlock()
+		return true
+	})
+
+	var streams, bytes int
+	b.streamBuffers.Range(func(key, value any) bool {
+		buf := value.(*StreamBuffer)
+		buf.mu.Lock()
+		streams++
+		bytes += len(buf.text)
+		buf.mu.Unlock()
+		return true
+	})
+	b.msgBuffers.Range(func(key, value any) bool {
+		buf := value.(*MessageBuffer)
+		buf.mu.Lock()
+		bytes += len(buf.text)
+		buf.mu.Unlock()
+		return true
+	})
+
+	account := b.account
+	metrics.BridgeDebounceBuffers.WithLabelValues(account.Name, account.Chat).Set(float64(buffers))
+	metrics.BridgeDebounceMessages.WithLabelValues(account.Name, account.Chat).Set(float64(messages))
+	metrics.BridgeStreamBuffers.WithLabelValues(account.Name, account.Chat).Set(float64(streams))
+	metrics.BridgeStreamBufferBytes.WithLabelValues(account.Name, account.Chat).Set(float64(bytes))
+	metrics.BridgePendingPermissions.WithLabelValues(account.Name, account.Chat).Set(float64(mapLen(&b.permissions)))
+	metrics.BridgePendingQuestions.WithLabelValues(account.Name, account.Chat).Set(float64(mapLen(&b.questions)))
+}
+
+// mapLen counts the entries of m
+func mapLen(m *sync.Map) int {
+	n := 0
+	m.Range(func(key, value any) bool {
+		n++
+		return true
+	})
+	return n
+}
@@ -3,12 +3,15 @@ package bridge
 import (
 	"context"
 	"fmt"
-	"log"
+	"html"
+	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-telegram/bot/models"
 
+	"github.com/user/opencode-telegram/internal/i18n"
 	"github.com/user/opencode-telegram/internal/opencode"
 	"github.com/user/opencode-telegram/internal/state"
 )
@@ -17,8 +20,13 @@ type CommandHandler struct {
 	ocClient        OpenCodeClient
 	tgBot           TelegramBot
 	appState        *state.AppState
+	sessionCacheMu  sync.Mutex
 	sessionCache    []opencode.Session
 	sessionCacheKey string
+	sessions        *sessionScope
+	audit           *auditTrail
+	logger          *slog.Logger
+	translator
 }
 
 func NewCommandHandler(ocClient OpenCodeClient, tgBot TelegramBot, appState *state.AppState) *CommandHandler {
@@ -26,6 +34,8 @@ func NewCommandHandler(ocClient OpenCodeClient, tgBot TelegramBot, appState *sta
 		ocClient: ocClient,
 		tgBot:    tgBot,
 		appState: appState,
+		sessions: &sessionScope{state: appState},
+		logger:   logger,
 	}
 }
 
@@ -40,9 +50,9 @@ func (h *CommandHandler) HandleNewSession(ctx context.Context, title *string) er
 		return fmt.Errorf("create session: %w", err)
 	}
 
-	h.appState.SetCurrentSession(session.ID)
+	h.sessions.set(ctx, session.ID)
 
-	msg := fmt.Sprintf("✅ New session created: %s (%s)", session.ID, session.Title)
+	msg := h.t("session.created", session.ID, session.Title)
 	_, err = h.tgBot.SendMessage(ctx, msg)
 	return err
 }
@@ -54,34 +64,49 @@ func (h *CommandHandler) HandleListSessions(ctx context.Context) error {
 	}
 
 	if len(sessions) == 0 {
-		_, err := h.tgBot.SendMessage(ctx, "No sessions found. Use /newsession to create one.")
+		_, err := h.tgBot.SendMessage(ctx, h.t("sessions.none"))
 		return err
 	}
 
-	primarySessions := []opencode.Session{}
-	for _, sess := range sessions {
-		if sess.ParentID == nil {
-			primarySessions = append(primarySessions, sess)
+	// Forked sessions are listed under their parent
+	tree := sessionTree(sessions)
+	var roots int
+	for _, node := range tree {
+		if node.depth == 0 {
+			roots++
 		}
 	}
 
-	if len(primarySessions) == 0 {
-		_, err := h.tgBot.SendMessage(ctx, "No primary sessions found.")
-		return err
-	}
-
-	currentID := h.appState.GetCurrentSession()
+	currentID := h.sessions.current(ctx)
+	aliases := h.sessionAliasNames()
 
 	const maxDisplay = 15
-	displaySessions := primarySessions
-	if len(primarySessions) > maxDisplay {
-		displaySessions = primarySessions[:maxDisplay]
+	shownRoots := roots
+	if shownRoots > maxDisplay {
+		shownRoots = maxDisplay
+	}
+
+	displayed := 0
+	var shown []sessionNode
+	var shownIDs []string
+	for _, node := range tree {
+		if node.depth == 0 {
+			if displayed == maxDisplay {
+				break
+			}
+			displayed++
+		}
+		shown = append(shown, node)
+		shownIDs = append(shownIDs, node.ID)
 	}
+	summaries := h.sessionSummaries(shownIDs)
 
 	var lines []string
-	lines = append(lines, fmt.Sprintf("📋 <b>Primary Sessions</b> (showing %d of %d)\n", len(displaySessions), len(primarySessions)))
+	lines = append(lines, h.t("sessions.header", shownRoots, roots))
+
+	for _, node := range shown {
+		sess := node.Session
 
-	for _, sess := range displaySessions {
 		var statusIcon string
 		if sess.ID == currentID {
 			statusIcon = "🟢"
@@ -98,27 +123,50 @@ func (h *CommandHandler) HandleListSessions(ctx context.Context) error {
 		}
 
 		lastUsed := time.Unix(0, sess.Time.Updated*int64(time.Millisecond))
-		timeAgo := formatTimeAgo(time.Since(lastUsed))
+		timeAgo := formatTimeAgo(h.language(), time.Since(lastUsed))
 
-		lines = append(lines, fmt.Sprintf("%s <b>%s</b> (%s)", statusIcon, displayTitle, sess.Slug))
-		lines = append(lines, fmt.Sprintf("   <code>%s</code>", sess.ID))
-		lines = append(lines, fmt.Sprintf("   🕐 %s\n", timeAgo))
+		indent := ""
+		if node.depth > 0 {
+			indent = strings.Repeat("   ", node.depth-1) + "↳ "
+		}
+		pad := strings.Repeat("   ", node.depth)
+
+		lines = append(lines, fmt.Sprintf("%s%s <b>%s</b> (%s)", indent, statusIcon, displayTitle, sess.Slug))
+		if alias := aliases[sess.ID]; alias != "" {
+			lines = append(lines, fmt.Sprintf("%s   <code>%s</code> · 🏷 %s", pad, sess.ID, alias))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s   <code>%s</code>", pad, sess.ID))
+		}
+		summary := summaries[sess.ID]
+		if summary == nil {
+			lines = append(lines, fmt.Sprintf("%s   🕐 %s\n", pad, timeAgo))
+			continue
+		}
+		count, tokens := fmt.Sprint(summary.MessageCount), formatTokenCount(summary.TotalTokens())
+		if summary.Truncated {
+			count, tokens = count+"+", tokens+"+"
+		}
+		lines = append(lines, fmt.Sprintf("%s   🕐 %s · %s", pad, timeAgo, h.t("sessions.stats", count, tokens)))
+		if summary.LastAssistant != "" {
+			lines = append(lines, fmt.Sprintf("%s   💭 <i>%s</i>", pad, html.EscapeString(summary.LastAssistant)))
+		}
+		lines[len(lines)-1] += "\n"
 	}
 
-	if len(primarySessions) > maxDisplay {
-		lines = append(lines, fmt.Sprintf("💡 <i>... and %d more sessions</i>", len(primarySessions)-maxDisplay))
+	if roots > maxDisplay {
+		lines = append(lines, h.t("sessions.more", roots-maxDisplay))
 	}
 
-	lines = append(lines, "\n<b>Tip:</b> Use <code>/session &lt;id&gt;</code> or <code>/selectsession</code> for menu")
+	lines = append(lines, h.t("sessions.tip"))
 
 	_, err = h.tgBot.SendMessage(ctx, strings.Join(lines, "\n"))
 	return err
 }
 
 func (h *CommandHandler) HandleAbortSession(ctx context.Context) error {
-	currentID := h.appState.GetCurrentSession()
+	currentID := h.sessions.current(ctx)
 	if currentID == "" {
-		_, err := h.tgBot.SendMessage(ctx, "❌ No active session to abort")
+		_, err := h.tgBot.SendMessage(ctx, h.t("abort.none"))
 		return err
 	}
 
@@ -127,15 +175,15 @@ func (h *CommandHandler) HandleAbortSession(ctx context.Context) error {
 		return fmt.Errorf("abort session: %w", err)
 	}
 
-	h.appState.SetCurrentSession("")
+	h.sessions.set(ctx, "")
 
-	_, err = h.tgBot.SendMessage(ctx, fmt.Sprintf("🛑 Session %s aborted", currentID))
+	_, err = h.tgBot.SendMessage(ctx, h.t("abort.done", currentID))
 	return err
 }
 
 func (h *CommandHandler) HandleDeleteSession(ctx context.Context, sessionID string) error {
 	if sessionID == "" {
-		_, err := h.tgBot.SendMessage(ctx, "❌ Please provide session ID: /deletesession &lt;id&gt;")
+		_, err := h.tgBot.SendMessage(ctx, h.t("delete.id_required"))
 		return err
 	}
 
@@ -153,24 +201,37 @@ func (h *CommandHandler) HandleDeleteSession(ctx context.Context, sessionID stri
 	}
 
 	if targetSession == nil {
-		_, err := h.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Session %s not found", sessionID))
+		_, err := h.tgBot.SendMessage(ctx, h.t("session.not_found", sessionID))
 		return err
 	}
 
-	if err := h.ocClient.DeleteSession(sessionID); err != nil {
-		return fmt.Errorf("delete session: %w", err)
-	}
-
-	currentID := h.appState.GetCurrentSession()
-	if currentID == sessionID {
-		h.appState.SetCurrentSession("")
+	if err := h.deleteSession(ctx, sessionID); err != nil {
+		return err
 	}
 
-	msg := fmt.Sprintf("🗑️ Deleted session: %s\n📝 Title: %s", sessionID, targetSession.Title)
+	msg := h.t("delete.done", sessionID, targetSession.Title)
 	_, err = h.tgBot.SendMessage(ctx, msg)
 	return err
 }
 
+// deleteSession deletes a session, leaving it first if it is the current
+// one, so the session.deleted event that follows is not reported as a
+// deletion from outside Telegram
+func (h *CommandHandler) deleteSession(ctx context.Context, sessionID string) error {
+	wasCurrent := h.sessions.current(ctx) == sessionID
+	if wasCurrent {
+		h.sessions.set(ctx, "")
+	}
+	if err := h.ocClient.DeleteSession(sessionID); err != nil {
+		if wasCurrent {
+			h.sessions.set(ctx, sessionID)
+		}
+		return fmt.Errorf("delete session: %w", err)
+	}
+	h.audit.record(ctx, h.sessions.chatID, state.AuditSessionDelete, sessionID)
+	return nil
+}
+
 func (h *CommandHandler) HandleDeleteSessionMenu(ctx context.Context) error {
 	sessions, err := h.ocClient.ListSessions()
 	if err != nil {
@@ -185,14 +246,13 @@ func (h *CommandHandler) HandleDeleteSessionMenu(ctx context.Context) error {
 	}
 
 	if len(primarySessions) == 0 {
-		_, err := h.tgBot.SendMessage(ctx, "No sessions found.")
+		_, err := h.tgBot.SendMessage(ctx, h.t("sessions.none_short"))
 		return err
 	}
 
-	h.sessionCache = primarySessions
-	h.sessionCacheKey = fmt.Sprintf("delcache_%d", time.Now().Unix())
+	h.cacheSessions(primarySessions, fmt.Sprintf("delcache_%d", time.Now().Unix()))
 
-	currentID := h.appState.GetCurrentSession()
+	currentID := h.sessions.current(ctx)
 
 	const sessionsPerPage = 8
 	totalPages := (len(primarySessions) + sessionsPerPage - 1) / sessionsPerPage
@@ -201,16 +261,17 @@ func (h *CommandHandler) HandleDeleteSessionMenu(ctx context.Context) error {
 }
 
 func (h *CommandHandler) HandleDeleteSessionPageCallback(ctx context.Context, page int) error {
-	if h.sessionCache == nil || len(h.sessionCache) == 0 {
-		_, err := h.tgBot.SendMessage(ctx, "❌ Session list expired. Please use /deletesessions again.")
+	cached := h.cachedSessions()
+	if len(cached) == 0 {
+		_, err := h.tgBot.SendMessage(ctx, h.t("delete.expired"))
 		return err
 	}
 
-	currentID := h.appState.GetCurrentSession()
+	currentID := h.sessions.current(ctx)
 	const sessionsPerPage = 8
-	totalPages := (len(h.sessionCache) + sessionsPerPage - 1) / sessionsPerPage
+	totalPages := (len(cached) + sessionsPerPage - 1) / sessionsPerPage
 
-	return h.showDeleteSessionPage(ctx, h.sessionCache, currentID, page, totalPages)
+	return h.showDeleteSessionPage(ctx, cached, currentID, page, totalPages)
 }
 
 func (h *CommandHandler) showDeleteSessionPage(ctx context.Context, sessions []opencode.Session, currentID string, page, totalPages int) error {
@@ -224,7 +285,7 @@ func (h *CommandHandler) showDeleteSessionPage(ctx context.Context, sessions []o
 	pageSessions := sessions[start:end]
 	keyboard := h.buildDeleteKeyboard(pageSessions, currentID, page, totalPages)
 
-	text := fmt.Sprintf("🗑️ Select session to delete (Page %d/%d):", page+1, totalPages)
+	text := h.t("delete.select_page", page+1, totalPages)
 	_, err := h.tgBot.SendMessageWithKeyboard(ctx, text, keyboard)
 	return err
 }
@@ -260,13 +321,13 @@ func (h *CommandHandler) buildDeleteKeyboard(sessions []opencode.Session, curren
 	var navRow []models.InlineKeyboardButton
 	if page > 0 {
 		navRow = append(navRow, models.InlineKeyboardButton{
-			Text:         "⬅️ Previous",
+			Text:         h.t("delete.previous"),
 			CallbackData: fmt.Sprintf("delpage:%d", page-1),
 		})
 	}
 	if page < totalPages-1 {
 		navRow = append(navRow, models.InlineKeyboardButton{
-			Text:         "Next ➡️",
+			Text:         h.t("delete.next"),
 			CallbackData: fmt.Sprintf("delpage:%d", page+1),
 		})
 	}
@@ -276,7 +337,7 @@ func (h *CommandHandler) buildDeleteKeyboard(sessions []opencode.Session, curren
 
 	cancelRow := []models.InlineKeyboardButton{
 		{
-			Text:         "❌ Cancel",
+			Text:         h.t("cancel"),
 			CallbackData: "delcancel",
 		},
 	}
@@ -302,18 +363,17 @@ func (h *CommandHandler) HandleDeleteConfirmCallback(ctx context.Context, sessio
 	}
 
 	if targetSession == nil {
-		_, err := h.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Session %s not found", sessionID))
+		_, err := h.tgBot.SendMessage(ctx, h.t("session.not_found", sessionID))
 		return err
 	}
 
-	confirmText := fmt.Sprintf("⚠️ Confirm deletion?\n\n📝 Title: %s\n🆔 ID: %s\n📂 Directory: %s",
-		targetSession.Title, sessionID, targetSession.Directory)
+	confirmText := h.t("delete.confirm", targetSession.Title, sessionID, targetSession.Directory)
 
 	keyboard := &models.InlineKeyboardMarkup{
 		InlineKeyboard: [][]models.InlineKeyboardButton{
 			{
-				{Text: "✅ Yes, delete", CallbackData: fmt.Sprintf("delconfirm:%s", sessionID)},
-				{Text: "❌ Cancel", CallbackData: "delcancel"},
+				{Text: h.t("delete.yes"), CallbackData: fmt.Sprintf("delconfirm:%s", sessionID)},
+				{Text: h.t("cancel"), CallbackData: "delcancel"},
 			},
 		},
 	}
@@ -337,26 +397,22 @@ func (h *CommandHandler) HandleDeleteExecuteCallback(ctx context.Context, sessio
 	}
 
 	if targetSession == nil {
-		_, err := h.tgBot.SendMessage(ctx, "❌ Session not found or already deleted")
+		_, err := h.tgBot.SendMessage(ctx, h.t("delete.gone"))
 		return err
 	}
 
-	if err := h.ocClient.DeleteSession(sessionID); err != nil {
-		return fmt.Errorf("delete session: %w", err)
-	}
-
-	currentID := h.appState.GetCurrentSession()
-	if currentID == sessionID {
-		h.appState.SetCurrentSession("")
+	if err := h.deleteSession(ctx, sessionID); err != nil {
+		return err
 	}
 
-	msg := fmt.Sprintf("✅ Deleted successfully!\n\n📝 Title: %s\n🆔 ID: %s", targetSession.Title, sessionID)
+	msg := h.t("delete.success", targetSession.Title, sessionID)
 	_, err = h.tgBot.SendMessage(ctx, msg)
 	return err
 }
 
 func (h *CommandHandler) HandleSwitchSession(ctx context.Context, sessionID string) error {
-	log.Printf("[CMD] HandleSwitchSession: switching to %s, statePtr=%p", sessionID, h.appState)
+	sessionID = h.resolveSessionAlias(sessionID)
+	h.logger.Debug("HandleSwitchSession: switching", "session", sessionID)
 	sessions, err := h.ocClient.ListSessions()
 	if err != nil {
 		return fmt.Errorf("list sessions: %w", err)
@@ -373,64 +429,64 @@ func (h *CommandHandler) HandleSwitchSession(ctx context.Context, sessionID stri
 	}
 
 	if !found {
-		_, err := h.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Session %s not found", sessionID))
+		_, err := h.tgBot.SendMessage(ctx, h.t("session.not_found", sessionID))
 		return err
 	}
 
-	h.appState.SetCurrentSession(sessionID)
-	log.Printf("[CMD] SetCurrentSession done, verifying: %s", h.appState.GetCurrentSession())
-	msg := fmt.Sprintf("✅ Switched to session: %s (%s)", selectedSession.Slug, selectedSession.Title)
+	h.sessions.set(ctx, sessionID)
+	h.logger.Debug("SetCurrentSession done", "session", h.sessions.current(ctx))
+	msg := h.t("session.switched", selectedSession.Slug, selectedSession.Title)
 	_, err = h.tgBot.SendMessage(ctx, msg)
 	return err
 }
 
 func (h *CommandHandler) HandleSelectSession(ctx context.Context) error {
-	log.Printf("[CMD] HandleSelectSession: started")
+	h.logger.Debug("HandleSelectSession: started")
 	sessions, err := h.ocClient.ListSessions()
 	if err != nil {
-		log.Printf("[CMD] HandleSelectSession: ListSessions error: %v", err)
+		h.logger.Error("HandleSelectSession: ListSessions failed", "error", err)
 		return fmt.Errorf("list sessions: %w", err)
 	}
-	log.Printf("[CMD] HandleSelectSession: got %d total sessions", len(sessions))
+	h.logger.Debug("HandleSelectSession: got sessions", "total", len(sessions))
 
-	primarySessions := []opencode.Session{}
-	for _, sess := range sessions {
-		if sess.ParentID == nil {
-			primarySessions = append(primarySessions, sess)
-		}
+	// Children follow their parent so forks can be selected too
+	var primarySessions []opencode.Session
+	for _, node := range sessionTree(sessions) {
+		primarySessions = append(primarySessions, node.Session)
 	}
-	log.Printf("[CMD] HandleSelectSession: found %d primary sessions", len(primarySessions))
+	h.logger.Debug("HandleSelectSession: found primary sessions", "count", len(primarySessions))
+	primarySessions = favoritesFirst(primarySessions, h.appState.GetFavorites(h.sessions.chatID))
 
 	if len(primarySessions) == 0 {
-		log.Printf("[CMD] HandleSelectSession: no primary sessions, sending error")
-		_, err := h.tgBot.SendMessage(ctx, "No primary sessions found.")
+		h.logger.Info("HandleSelectSession: no sessions, sending error")
+		_, err := h.tgBot.SendMessage(ctx, h.t("sessions.no_primary"))
 		return err
 	}
 
-	h.sessionCache = primarySessions
-	h.sessionCacheKey = fmt.Sprintf("cache_%d", time.Now().Unix())
+	h.cacheSessions(primarySessions, fmt.Sprintf("cache_%d", time.Now().Unix()))
 
-	currentID := h.appState.GetCurrentSession()
-	log.Printf("[CMD] HandleSelectSession: currentID=%s", currentID)
+	currentID := h.sessions.current(ctx)
+	h.logger.Debug("HandleSelectSession", "session", currentID)
 
 	const sessionsPerPage = 8
 	totalPages := (len(primarySessions) + sessionsPerPage - 1) / sessionsPerPage
-	log.Printf("[CMD] HandleSelectSession: showing page 0/%d", totalPages)
+	h.logger.Debug("HandleSelectSession: showing first page", "pages", totalPages)
 
 	return h.showSessionPage(ctx, primarySessions, currentID, 0, totalPages)
 }
 
 func (h *CommandHandler) HandleSessionPageCallback(ctx context.Context, page int) error {
-	if h.sessionCache == nil || len(h.sessionCache) == 0 {
-		_, err := h.tgBot.SendMessage(ctx, "❌ Session list expired. Please use /selectsession again.")
+	cached := h.cachedSessions()
+	if len(cached) == 0 {
+		_, err := h.tgBot.SendMessage(ctx, h.t("sessions.select_expired"))
 		return err
 	}
 
-	currentID := h.appState.GetCurrentSession()
+	currentID := h.sessions.current(ctx)
 	const sessionsPerPage = 8
-	totalPages := (len(h.sessionCache) + sessionsPerPage - 1) / sessionsPerPage
+	totalPages := (len(cached) + sessionsPerPage - 1) / sessionsPerPage
 
-	return h.showSessionPage(ctx, h.sessionCache, currentID, page, totalPages)
+	return h.showSessionPage(ctx, cached, currentID, page, totalPages)
 }
 
 func (h *CommandHandler) showSessionPage(ctx context.Context, sessions []opencode.Session, currentID string, page, totalPages int) error {
@@ -441,34 +497,51 @@ func (h *CommandHandler) showSessionPage(ctx context.Context, sessions []opencod
 		end = len(sessions)
 	}
 
-	log.Printf("[CMD] showSessionPage: page=%d, start=%d, end=%d, total=%d", page, start, end, len(sessions))
+	h.logger.Debug("showSessionPage", "page", page, "start", start, "end", end, "total", len(sessions))
 	pageSessions := sessions[start:end]
 
 	keyboard := h.buildSessionKeyboard(pageSessions, currentID, page, totalPages)
-	log.Printf("[CMD] showSessionPage: keyboard built with %d rows", len(keyboard.InlineKeyboard))
+	h.logger.Debug("showSessionPage: keyboard built", "rows", len(keyboard.InlineKeyboard))
 
-	msg := fmt.Sprintf("📋 <b>Select Session</b> (page %d/%d)", page+1, totalPages)
-	log.Printf("[CMD] showSessionPage: sending message with keyboard...")
+	msg := h.t("sessions.select_page", page+1, totalPages)
+	h.logger.Debug("showSessionPage: sending message with keyboard")
 	msgID, err := h.tgBot.SendMessageWithKeyboard(ctx, msg, keyboard)
 	if err != nil {
-		log.Printf("[CMD] showSessionPage: SendMessageWithKeyboard failed: %v", err)
+		h.logger.Error("showSessionPage: SendMessageWithKeyboard failed", "error", err)
 		return err
 	}
-	log.Printf("[CMD] showSessionPage: message sent successfully, msgID=%d", msgID)
+	h.logger.Debug("showSessionPage: message sent", "telegram_message", msgID)
 	return nil
 }
 
 func (h *CommandHandler) buildSessionKeyboard(sessions []opencode.Session, currentID string, page, totalPages int) *models.InlineKeyboardMarkup {
 	var rows [][]models.InlineKeyboardButton
 
+	favorites := make(map[string]bool)
+	for _, id := range h.appState.GetFavorites(h.sessions.chatID) {
+		favorites[id] = true
+	}
+	aliases := h.sessionAliasNames()
+
 	for _, sess := range sessions {
 		dirDisplay := h.shortenDirectory(sess.Directory)
 
+		title := sess.Title
+		if alias := aliases[sess.ID]; alias != "" {
+			title = "🏷 " + alias + " · " + title
+		}
+
 		var label string
 		if sess.ID == currentID {
-			label = fmt.Sprintf("🟢 %s [%s]", sess.Title, dirDisplay)
+			label = fmt.Sprintf("🟢 %s [%s]", title, dirDisplay)
 		} else {
-			label = fmt.Sprintf("%s [%s]", sess.Title, dirDisplay)
+			label = fmt.Sprintf("%s [%s]", title, dirDisplay)
+		}
+		if sess.ParentID != nil {
+			label = "↳ " + label
+		}
+		if favorites[sess.ID] {
+			label = "⭐ " + label
 		}
 
 		if len(label) > 60 {
@@ -489,7 +562,7 @@ func (h *CommandHandler) buildSessionKeyboard(sessions []opencode.Session, curre
 	var navRow []models.InlineKeyboardButton
 	if page > 0 {
 		navRow = append(navRow, models.InlineKeyboardButton{
-			Text:         "◀️ Prev",
+			Text:         h.t("nav.prev"),
 			CallbackData: fmt.Sprintf("sesspage:%d", page-1),
 		})
 	}
@@ -501,7 +574,7 @@ func (h *CommandHandler) buildSessionKeyboard(sessions []opencode.Session, curre
 
 	if page < totalPages-1 {
 		navRow = append(navRow, models.InlineKeyboardButton{
-			Text:         "Next ▶️",
+			Text:         h.t("nav.next"),
 			CallbackData: fmt.Sprintf("sesspage:%d", page+1),
 		})
 	}
@@ -548,25 +621,25 @@ func (h *CommandHandler) shortenDirectory(dir string) string {
 }
 
 func (h *CommandHandler) HandleStatus(ctx context.Context) error {
-	sessionID := h.appState.GetCurrentSession()
+	sessionID := h.sessions.current(ctx)
 	agent := h.appState.GetCurrentAgent()
-	model := h.appState.GetCurrentModel()
+	model := h.appState.GetModelForChat(h.sessions.chatID)
 	status := h.appState.GetSessionStatus(sessionID)
 
-	statusStr := "idle"
+	statusStr := h.t("status.idle")
 	if status == state.SessionBusy {
-		statusStr = "processing"
+		statusStr = h.t("status.processing")
 	} else if status == state.SessionError {
-		statusStr = "error"
+		statusStr = h.t("status.error")
 	}
 
 	health, err := h.ocClient.Health()
-	healthStr := "unknown"
+	healthStr := h.t("status.health_nodata")
 	if err == nil {
 		if healthy, ok := health["healthy"].(bool); ok && healthy {
-			healthStr = "healthy"
+			healthStr = h.t("status.health_ok")
 		} else {
-			healthStr = "unhealthy"
+			healthStr = h.t("status.health_bad")
 		}
 	}
 
@@ -582,12 +655,12 @@ func (h *CommandHandler) HandleStatus(ctx context.Context) error {
 			}
 		}
 		if model == "" {
-			model = "(unknown)"
+			model = h.t("status.unknown")
 		}
 	}
 
-	sessionName := "(none)"
-	sessionDir := "(none)"
+	sessionName := h.t("status.none")
+	sessionDir := h.t("status.none")
 	if sessionID != "" {
 		sessions, err := h.ocClient.ListSessions()
 		if err == nil {
@@ -600,19 +673,19 @@ func (h *CommandHandler) HandleStatus(ctx context.Context) error {
 			}
 		}
 	} else {
-		sessionID = "(none)"
+		sessionID = h.t("status.none")
 	}
 
 	lines := []string{
-		"📊 Status:",
+		h.t("status.title"),
 		"",
-		fmt.Sprintf("Session: %s", sessionName),
-		fmt.Sprintf("Session ID: %s", sessionID),
-		fmt.Sprintf("Directory: %s", sessionDir),
-		fmt.Sprintf("Agent: %s", agent),
-		fmt.Sprintf("Model: %s", model),
-		fmt.Sprintf("Status: %s", statusStr),
-		fmt.Sprintf("OpenCode: %s", healthStr),
+		h.t("status.session", sessionName),
+		h.t("status.session_id", sessionID),
+		h.t("status.directory", sessionDir),
+		h.t("status.agent", agent),
+		h.t("status.model", model),
+		h.t("status.status", statusStr),
+		h.t("status.opencode", healthStr),
 	}
 
 	_, err = h.tgBot.SendMessage(ctx, strings.Join(lines, "\n"))
@@ -620,19 +693,7 @@ func (h *CommandHandler) HandleStatus(ctx context.Context) error {
 }
 
 func (h *CommandHandler) HandleHelp(ctx context.Context) error {
-	help := `🆘 Available Commands:
-
-/newsession [title] - Create a new session
-/sessions - List primary sessions
-/selectsession - Select session from menu
-/deletesessions - Delete sessions (interactive menu)
-/session &lt;id&gt; - Switch to a session
-/deletesession &lt;id&gt; - Delete a session directly
-/abort - Abort current session
-/status - Show current status
-/switch [agent] - Switch OHO agent
-/route [agent] - Set or view per-chat agent assignment
-/help - Show this help message`
+	help := h.t("help")
 
 	_, err := h.tgBot.SendMessage(ctx, help)
 	return err
@@ -647,13 +708,13 @@ func (h *CommandHandler) HandleRouteCommand(ctx context.Context, args string, ch
 
 	if args == "clear" {
 		h.appState.RemoveChatAgent(chatID)
-		_, err := h.tgBot.SendMessage(ctx, "✅ Chat agent assignment cleared. Using global agent.")
+		_, err := h.tgBot.SendMessage(ctx, h.t("route.cleared"))
 		return err
 	}
 
 	agent := args
 	h.appState.SetChatAgent(chatID, agent)
-	msg := fmt.Sprintf("✅ Chat agent set to: %s", agent)
+	msg := h.t("route.set", agent)
 	_, err := h.tgBot.SendMessage(ctx, msg)
 	return err
 }
@@ -664,57 +725,91 @@ func (h *CommandHandler) showRouteStatus(ctx context.Context, chatID string) err
 
 	var status string
 	if chatAgent != "" {
-		status = fmt.Sprintf("🎯 Chat Agent: %s\n📍 Global Agent: %s", chatAgent, globalAgent)
+		status = h.t("route.status_chat", chatAgent, globalAgent)
 	} else {
-		status = fmt.Sprintf("📍 Using Global Agent: %s\n(No chat-specific override)", globalAgent)
+		status = h.t("route.status_global", globalAgent)
 	}
 
 	_, err := h.tgBot.SendMessage(ctx, status)
 	return err
 }
 
-func formatTimeAgo(d time.Duration) string {
+func formatTimeAgo(lang i18n.Lang, d time.Duration) string {
 	if d < time.Minute {
-		return "just now"
+		return i18n.T(lang, "ago.now")
 	}
 	if d < time.Hour {
 		mins := int(d.Minutes())
 		if mins == 1 {
-			return "1 min ago"
+			return i18n.T(lang, "ago.minute")
 		}
-		return fmt.Sprintf("%d mins ago", mins)
+		return i18n.T(lang, "ago.minutes", mins)
 	}
 	if d < 24*time.Hour {
 		hours := int(d.Hours())
 		if hours == 1 {
-			return "1 hour ago"
+			return i18n.T(lang, "ago.hour")
 		}
-		return fmt.Sprintf("%d hours ago", hours)
+		return i18n.T(lang, "ago.hours", hours)
 	}
 	if d < 7*24*time.Hour {
 		days := int(d.Hours() / 24)
 		if days == 1 {
-			return "1 day ago"
+			return i18n.T(lang, "ago.day")
 		}
-		return fmt.Sprintf("%d days ago", days)
+		return i18n.T(lang, "ago.days", days)
 	}
 	if d < 30*24*time.Hour {
 		weeks := int(d.Hours() / 24 / 7)
 		if weeks == 1 {
-			return "1 week ago"
+			return i18n.T(lang, "ago.week")
 		}
-		return fmt.Sprintf("%d weeks ago", weeks)
+		return i18n.T(lang, "ago.weeks", weeks)
 	}
 	months := int(d.Hours() / 24 / 30)
 	if months == 1 {
-		return "1 month ago"
+		return i18n.T(lang, "ago.month")
 	}
 	if months < 12 {
-		return fmt.Sprintf("%d months ago", months)
+		return i18n.T(lang, "ago.months", months)
 	}
 	years := int(d.Hours() / 24 / 365)
 	if years == 1 {
-		return "1 year ago"
+		return i18n.T(lang, "ago.year")
+	}
+	return i18n.T(lang, "ago.years", years)
+}
+
+// cacheSessions keeps the session list shown by a paginated menu
+func (h *CommandHandler) cacheSessions(sessions []opencode.Session, key string) {
+	h.sessionCacheMu.Lock()
+	defer h.sessionCacheMu.Unlock()
+	h.sessionCache = sessions
+	h.sessionCacheKey = key
+}
+
+func (h *CommandHandler) cachedSessions() []opencode.Session {
+	h.sessionCacheMu.Lock()
+	defer h.sessionCacheMu.Unlock()
+	return h.sessionCache
+}
+
+// updateCachedSession applies a session change reported by OpenCode to the
+// cached list: a new title, or removal when deleted is set
+func (h *CommandHandler) updateCachedSession(sessionID, title string, deleted bool) {
+	h.sessionCacheMu.Lock()
+	defer h.sessionCacheMu.Unlock()
+	sessions := make([]opencode.Session, 0, len(h.sessionCache))
+	for _, sess := range h.sessionCache {
+		if sess.ID == sessionID {
+			if deleted {
+				continue
+			}
+			if title != "" {
+				sess.Title = title
+			}
+		}
+		sessions = append(sessions, sess)
 	}
-	return fmt.Sprintf("%d years ago", years)
+	h.sessionCache = sessions
 }
@@ -77,7 +77,7 @@ func TestCmdNewSession(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "ses_new", sess.ID)
 	appState.SetCurrentSession(sess.ID)
-	assert.Equal(t, "ses_new", appState.GetCurrentSession())
+	assert.Equal(t, "ses_new", appState.GetSessionForChat(""))
 }
 
 func TestCmdNewSessionDefault(t *testing.T) {
@@ -103,7 +103,7 @@ func TestCmdNewSessionDefault(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "ses_auto", sess.ID)
 	appState.SetCurrentSession(sess.ID)
-	assert.Equal(t, "ses_auto", appState.GetCurrentSession())
+	assert.Equal(t, "ses_auto", appState.GetSessionForChat(""))
 }
 
 func TestCmdSessions(t *testing.T) {
@@ -147,14 +147,14 @@ func TestCmdAbort(t *testing.T) {
 
 	assert.NoError(t, err)
 	appState.SetCurrentSession("")
-	assert.Equal(t, "", appState.GetCurrentSession())
+	assert.Equal(t, "", appState.GetSessionForChat(""))
 }
 
 func TestCmdAbortNoSession(t *testing.T) {
 	mockTG := new(MockSessionTelegramBot)
 	appState := state.NewAppStateForTest()
 
-	assert.Equal(t, "", appState.GetCurrentSession())
+	assert.Equal(t, "", appState.GetSessionForChat(""))
 
 	mockTG.On("SendMessage", mock.Anything, mock.MatchedBy(func(text string) bool {
 		return text != ""
@@ -222,5 +222,5 @@ func TestCmdSelectSession(t *testing.T) {
 	assert.Greater(t, len(listed), 0)
 
 	appState.SetCurrentSession("ses_b")
-	assert.Equal(t, "ses_b", appState.GetCurrentSession())
+	assert.Equal(t, "ses_b", appState.GetSessionForChat(""))
 }
@@ -0,0 +1,48 @@
+package bridge
+
+import (
+	"context"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// SetCompletionReactions enables reacting on the user's triggering message
+// once a response finishes. An empty emoji disables that outcome.
+// Telegram only accepts emoji from its reaction set (e.g. 👍, 👎, 👌, 🔥),
+// so ✅/❌ are rejected by setMessageReaction.
+func (b *Bridge) SetCompletionReactions(success, failure string) {
+	b.successReaction = success
+	b.failureReaction = failure
+}
+
+// rememberTrigger records the incoming message that started work on a session.
+// With debounced or album messages, the latest one wins.
+func (b *Bridge) rememberTrigger(ctx context.Context, sessionID string) {
+	if b.successReaction == "" && b.failureReaction == "" && !b.freshFinal() {
+		return
+	}
+	if messageID, ok := telegram.MessageIDFromContext(ctx); ok {
+		b.triggerMsgs.Store(sessionID, messageID)
+	}
+}
+
+// reactCompletion marks the triggering message with the success or failure reaction.
+// It also releases the trigger, so it must run after the final message is delivered.
+func (b *Bridge) reactCompletion(sessionID string, success bool) {
+	val, ok := b.triggerMsgs.LoadAndDelete(sessionID)
+	if !ok {
+		return
+	}
+
+	emoji := b.successReaction
+	if !success {
+		emoji = b.failureReaction
+	}
+	if emoji == "" {
+		return
+	}
+
+	if err := b.tgBot.SetReaction(context.Background(), val.(int), emoji); err != nil {
+		b.logger.Warn("Failed to set completion reaction", "session", sessionID, "error", err)
+	}
+}
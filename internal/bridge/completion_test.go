@@ -0,0 +1,80 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestCompletionReactionOnSuccess(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_ok")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetCompletionReactions("👍", "👎")
+
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockTG.On("EditMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockOC.On("TriggerPrompt", "ses_ok", "hi", mock.Anything).Return(nil)
+	mockTG.On("SetReaction", mock.Anything, 42, "👍").Return(nil).Once()
+
+	ctx := telegram.WithMessageID(context.Background(), 42)
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "hi"))
+	assert.Eventually(t, func() bool {
+		_, ok := bridge.thinkingMsgs.Load("ses_ok")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	bridge.sendToTelegram("ses_ok", "msg_1", "done")
+
+	mockTG.AssertExpectations(t)
+	_, pending := bridge.triggerMsgs.Load("ses_ok")
+	assert.False(t, pending)
+}
+
+func TestCompletionReactionOnError(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_err")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetCompletionReactions("👍", "👎")
+
+	reacted := make(chan string, 1)
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockTG.On("EditMessagePlain", mock.Anything, 1, mock.Anything).Return(nil)
+	mockOC.On("TriggerPrompt", "ses_err", "hi", mock.Anything).Return(errors.New("boom"))
+	mockTG.On("SetReaction", mock.Anything, 7, mock.Anything).
+		Run(func(args mock.Arguments) { reacted <- args.String(2) }).Return(nil).Once()
+
+	ctx := telegram.WithMessageID(context.Background(), 7)
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "hi"))
+
+	select {
+	case emoji := <-reacted:
+		assert.Equal(t, "👎", emoji)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no failure reaction")
+	}
+}
+
+func TestCompletionReactionsDisabled(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+
+	bridge.rememberTrigger(telegram.WithMessageID(context.Background(), 3), "ses_off")
+	bridge.reactCompletion("ses_off", true)
+
+	mockTG.AssertNotCalled(t, "SetReaction", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,63 @@
+package bridge
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxChatDebounce bounds /debounce; long enough for prompts dictated over
+// several voice-typed messages
+const maxChatDebounce = 30 * time.Second
+
+// debounceWindow returns how long this chat's messages are collected into
+// one prompt: its /debounce setting, otherwise the bridge default
+func (b *Bridge) debounceWindow() time.Duration {
+	if window, ok := b.state.GetChatDebounce(b.chatID); ok {
+		return window
+	}
+	return b.defaultDebounce()
+}
+
+// SetDebounce changes the window of chats without a /debounce setting, e.g.
+// when the configuration is reloaded. Invalid windows fall back to 1s.
+func (b *Bridge) SetDebounce(window time.Duration) {
+	if window <= 0 || window > 3000*time.Millisecond {
+		window = 1000 * time.Millisecond
+	}
+	b.debounceMs.Store(int64(window))
+}
+
+func (b *Bridge) defaultDebounce() time.Duration {
+	return time.Duration(b.debounceMs.Load())
+}
+
+// HandleDebounceCommand handles /debounce [ms|reset]
+// Without args: shows the window used in this chat. "reset" goes back to
+// TELEGRAM_DEBOUNCE_MS.
+func (b *Bridge) HandleDebounceCommand(ctx context.Context, args string) error {
+	var msg string
+	switch args = strings.TrimSpace(args); args {
+	case "":
+		if window, ok := b.state.GetChatDebounce(b.chatID); ok {
+			msg = b.t("debounce.current", window.Milliseconds())
+		} else {
+			msg = b.t("debounce.default", b.defaultDebounce().Milliseconds())
+		}
+	case "reset":
+		b.state.ResetChatDebounce(b.chatID)
+		msg = b.t("debounce.reset", b.defaultDebounce().Milliseconds())
+	default:
+		ms, err := strconv.Atoi(strings.TrimSuffix(args, "ms"))
+		if err != nil || ms < 0 || time.Duration(ms)*time.Millisecond > maxChatDebounce {
+			msg = b.t("debounce.invalid", maxChatDebounce.Milliseconds())
+			break
+		}
+		b.state.SetChatDebounce(b.chatID, time.Duration(ms)*time.Millisecond)
+		msg = b.t("debounce.set", ms)
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, msg)
+	return err
+}
@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestDebounceCommand(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), 1500*time.Millisecond)
+	bridge.chatID = "-100"
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "✅ Messages sent within 5000 ms of each other will be merged into one prompt").Return(1, nil).Once()
+	assert.NoError(t, bridge.HandleDebounceCommand(ctx, "5000"))
+	assert.Equal(t, 5*time.Second, bridge.debounceWindow())
+
+	mockTG.On("SendMessage", ctx, "❌ Give a number of milliseconds between 0 and 30000, or reset").Return(1, nil).Twice()
+	assert.NoError(t, bridge.HandleDebounceCommand(ctx, "60000"))
+	assert.NoError(t, bridge.HandleDebounceCommand(ctx, "soon"))
+	assert.Equal(t, 5*time.Second, bridge.debounceWindow(), "an invalid value must keep the setting")
+
+	mockTG.On("SendMessage", ctx, "✅ Debounce reset to the default (1500 ms)").Return(1, nil).Once()
+	assert.NoError(t, bridge.HandleDebounceCommand(ctx, "reset"))
+	assert.Equal(t, 1500*time.Millisecond, bridge.debounceWindow())
+
+	mockTG.AssertExpectations(t)
+}
+
+func TestChatDebounceWindowUsed(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+	appState.SetChatDebounce("", 10*time.Millisecond)
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 3000*time.Millisecond)
+
+	submitted := make(chan struct{}, 1)
+	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).
+		Run(func(mock.Arguments) { submitted <- struct{}{} }).Return(nil)
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	assert.NoError(t, bridge.HandleUserMessage(context.Background(), "Hello"))
+
+	// Well before the 3 s default window
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("expected the chat's 10 ms window to be used")
+	}
+}
+
+func TestSetDebounce(t *testing.T) {
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), appState, state.NewIDRegistry(), 1500*time.Millisecond)
+
+	bridge.SetDebounce(500 * time.Millisecond)
+	assert.Equal(t, 500*time.Millisecond, bridge.debounceWindow())
+
+	bridge.SetDebounce(time.Minute)
+	assert.Equal(t, time.Second, bridge.debounceWindow(), "an invalid window falls back to 1s")
+
+	appState.SetChatDebounce("", 200*time.Millisecond)
+	bridge.SetDebounce(2 * time.Second)
+	assert.Equal(t, 200*time.Millisecond, bridge.debounceWindow(), "the chat's /debounce setting wins")
+}
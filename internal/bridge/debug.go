@@ -0,0 +1,70 @@
+package bridge
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DebugState is a snapshot of what a bridge holds in memory, for the
+// /debug/state endpoint. Maps that keep growing point at a leak.
+type DebugState struct {
+	Account    string          `json:"account"`
+	Chat       string          `json:"chat"`
+	Maps       map[string]int  `json:"maps"`
+	Submitting int64           `json:"submitting"`
+	Debounce   []DebounceState `json:"debounce"`
+}
+
+// DebounceState describes the messages of a session waiting out the
+// debounce window
+type DebounceState struct {
+	Session      string    `json:"session"`
+	Messages     int       `json:"messages"`
+	LastReceived time.Time `json:"last_received"`
+	Flushed      bool      `json:"flushed"`
+}
+
+// DebugState returns the sizes of the bridge's maps and its debounce buffers
+func (b *Bridge) DebugState() DebugState {
+	maps := map[string]*sync.Map{
+		"debounce":       &b.debounceBuffers,
+		"thinking":       &b.thinkingMsgs,
+		"stream":         &b.streamBuffers,
+		"message":        &b.msgBuffers,
+		"permission":     &b.permissions,
+		"question":       &b.questions,
+		"last_update":    &b.lastUpdate,
+		"idle_processed": &b.idleProcessed,
+		"progress":       &b.progress,
+		"album":          &b.albums,
+		"last_prompt":    &b.lastPrompts,
+		"trigger":        &b.triggerMsgs,
+		"prompt_start":   &b.promptStarts,
+	}
+	s := DebugState{
+		Account:    b.account.Name,
+		Chat:       b.chatID,
+		Maps:       make(map[string]int, len(maps)),
+		Submitting: b.submitting.Load(),
+		Debounce:   []DebounceState{},
+	}
+	for name, m := range maps {
+		s.Maps[name] = mapLen(m)
+	}
+
+	b.debounceBuffers.Range(func(key, value interface{}) bool {
+		buf := value.(*DebounceBuffer)
+		buf.mu.Lock()
+		s.Debounce = append(s.Debounce, DebounceState{
+			Session:      key.(string),
+			Messages:     len(buf.messages),
+			LastReceived: buf.lastReceived,
+			Flushed:      buf.flushed,
+		})
+		buf.mu.Unlock()
+		return true
+	})
+	sort.Slice(s.Debounce, func(i, j int) bool { return s.Debounce[i].Session < s.Debounce[j].Session })
+	return s
+}
@@ -0,0 +1,51 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestDebugState(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), 3*time.Second)
+	bridge.SetAccount("work")
+	t.Cleanup(func() { stopDebounce(bridge) })
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	bridge.thinkingMsgs.Store("ses_stuck", 10)
+	assert.NoError(t, bridge.HandleUserMessage(context.Background(), "Hello"))
+	assert.NoError(t, bridge.HandleUserMessage(context.Background(), "World"))
+
+	s := bridge.DebugState()
+	assert.Equal(t, "work", s.Account)
+	assert.Equal(t, 1, s.Maps["thinking"])
+	assert.Equal(t, 1, s.Maps["debounce"])
+	assert.Equal(t, 0, s.Maps["permission"])
+	if assert.Len(t, s.Debounce, 1) {
+		assert.Equal(t, "ses_123", s.Debounce[0].Session)
+		assert.Equal(t, 2, s.Debounce[0].Messages)
+		assert.False(t, s.Debounce[0].Flushed)
+	}
+}
+
+// stopDebounce disarms the debounce timers a test left running, so they do
+// not fire into the mocks of later tests
+func stopDebounce(b *Bridge) {
+	b.debounceBuffers.Range(func(_, value interface{}) bool {
+		buf := value.(*DebounceBuffer)
+		buf.mu.Lock()
+		if buf.timer != nil {
+			buf.timer.Stop()
+		}
+		buf.mu.Unlock()
+		return true
+	})
+}
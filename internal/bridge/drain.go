@@ -0,0 +1,37 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// drainPoll is how often Drain checks for prompts still being submitted
+const drainPoll = 10 * time.Millisecond
+
+// Drain prepares the bridge for shutdown once no more updates arrive: it
+// submits the messages still waiting out their debounce delay, and waits
+// until every prompt has reached OpenCode (or failed for good)
+func (b *Bridge) Drain(ctx context.Context) error {
+	b.debounceBuffers.Range(func(key, value interface{}) bool {
+		buf := value.(*DebounceBuffer)
+		buf.mu.Lock()
+		if buf.timer != nil {
+			buf.timer.Stop()
+		}
+		buf.mu.Unlock()
+		b.flushDebounceBuffer(key.(string), buf)
+		return true
+	})
+
+	ticker := time.NewTicker(drainPoll)
+	defer ticker.Stop()
+	for b.submitting.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d prompts not submitted: %w", b.submitting.Load(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
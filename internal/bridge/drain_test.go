@@ -0,0 +1,38 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestBridgeDrainFlushesDebounceBuffers(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 3*time.Second)
+	ctx := context.Background()
+
+	mockOC.On("TriggerPrompt", "ses_123", "Hello\nWorld", mock.Anything).
+		Run(func(mock.Arguments) { time.Sleep(20 * time.Millisecond) }).Return(nil)
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "Hello"))
+	assert.NoError(t, bridge.HandleUserMessage(ctx, "World"))
+
+	drainCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	assert.NoError(t, bridge.Drain(drainCtx))
+
+	// Submitted without waiting out the 3s debounce
+	mockOC.AssertNumberOfCalls(t, "TriggerPrompt", 1)
+	_, pending := bridge.debounceBuffers.Load("ses_123")
+	assert.False(t, pending)
+}
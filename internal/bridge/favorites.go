@@ -0,0 +1,128 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// HandleFavCommand manages the chat's favorite sessions, listed first in
+// /selectsession.
+// Usage:
+//
+//	/fav add [session]  (default: the current session)
+//	/fav rm <session>
+//	/fav list
+func (b *Bridge) HandleFavCommand(ctx context.Context, args string) error {
+	sub, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	sessionID := strings.TrimSpace(rest)
+
+	var msg string
+	switch sub {
+	case "add":
+		if sessionID == "" {
+			sessionID = b.sessions.current(ctx)
+		}
+		if sessionID == "" {
+			msg = b.t("fav.no_session")
+			break
+		}
+		sessions, err := b.ocClient.ListSessions()
+		if err != nil {
+			return fmt.Errorf("list sessions: %w", err)
+		}
+		sess, ok := findSession(sessions, sessionID)
+		if !ok {
+			msg = b.t("fav.not_found", html.EscapeString(sessionID))
+			break
+		}
+		if !b.state.AddFavorite(b.chatID, sessionID) {
+			msg = b.t("fav.exists", html.EscapeString(sess.Title))
+			break
+		}
+		msg = b.t("fav.added", html.EscapeString(sess.Title))
+	case "rm":
+		if sessionID == "" {
+			msg = b.t("fav.usage")
+			break
+		}
+		if !b.state.RemoveFavorite(b.chatID, sessionID) {
+			msg = b.t("fav.not_favorite", html.EscapeString(sessionID))
+			break
+		}
+		msg = b.t("fav.removed", html.EscapeString(sessionID))
+	case "list", "":
+		var err error
+		if msg, err = b.formatFavorites(); err != nil {
+			return err
+		}
+	default:
+		msg = b.t("fav.usage")
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, msg)
+	return err
+}
+
+// formatFavorites lists the chat's favorite sessions with their titles
+// Example:
+//
+//	⭐ Favorite sessions:
+//	• Refactor parser — ses_abc
+//	• ses_gone
+func (b *Bridge) formatFavorites() (string, error) {
+	favorites := b.state.GetFavorites(b.chatID)
+	if len(favorites) == 0 {
+		return b.t("fav.list_empty"), nil
+	}
+	sessions, err := b.ocClient.ListSessions()
+	if err != nil {
+		return "", fmt.Errorf("list sessions: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.t("fav.list_title"))
+	for _, id := range favorites {
+		if sess, ok := findSession(sessions, id); ok {
+			sb.WriteString(fmt.Sprintf("\n• %s — <code>%s</code>", html.EscapeString(sess.Title), id))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n• <code>%s</code>", html.EscapeString(id)))
+		}
+	}
+	return sb.String(), nil
+}
+
+// findSession looks a session up by ID
+func findSession(sessions []opencode.Session, sessionID string) (opencode.Session, bool) {
+	for _, sess := range sessions {
+		if sess.ID == sessionID {
+			return sess, true
+		}
+	}
+	return opencode.Session{}, false
+}
+
+// favoritesFirst moves the favorite sessions to the front, in the order
+// they were added; the others keep their order
+func favoritesFirst(sessions []opencode.Session, favorites []string) []opencode.Session {
+	if len(favorites) == 0 {
+		return sessions
+	}
+	ordered := make([]opencode.Session, 0, len(sessions))
+	picked := make(map[string]bool, len(favorites))
+	for _, id := range favorites {
+		if sess, ok := findSession(sessions, id); ok && !picked[id] {
+			ordered = append(ordered, sess)
+			picked[id] = true
+		}
+	}
+	for _, sess := range sessions {
+		if !picked[sess.ID] {
+			ordered = append(ordered, sess)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,75 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestFavCommand(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_cur")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{
+		{ID: "ses_cur", Title: "Current"},
+		{ID: "ses_old", Title: "Old <work>"},
+	}, nil)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+
+	require.NoError(t, bridge.HandleFavCommand(ctx, "add"))
+	require.NoError(t, bridge.HandleFavCommand(ctx, "add ses_old"))
+	require.NoError(t, bridge.HandleFavCommand(ctx, "add ses_old"))
+	require.NoError(t, bridge.HandleFavCommand(ctx, "add ses_missing"))
+	assert.Equal(t, []string{"ses_cur", "ses_old"}, appState.GetFavorites(""))
+
+	require.NoError(t, bridge.HandleFavCommand(ctx, "list"))
+	require.NoError(t, bridge.HandleFavCommand(ctx, "rm ses_cur"))
+	assert.Equal(t, []string{"ses_old"}, appState.GetFavorites(""))
+
+	assert.Equal(t, []string{
+		"⭐ Current added to favorites",
+		"⭐ Old &lt;work&gt; added to favorites",
+		"⭐ Old &lt;work&gt; is already a favorite",
+		"❌ Session not found: ses_missing",
+		"⭐ Favorite sessions:\n• Current — <code>ses_cur</code>\n• Old &lt;work&gt; — <code>ses_old</code>",
+		"🗑 ses_cur removed from favorites",
+	}, mockTG.sentMessages)
+}
+
+func TestSelectSessionListsFavoritesFirst(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.AddFavorite("", "ses_3")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{
+		{ID: "ses_1", Title: "One", Slug: "one"},
+		{ID: "ses_2", Title: "Two", Slug: "two"},
+		{ID: "ses_3", Title: "Three", Slug: "three"},
+	}, nil)
+
+	var keyboard *models.InlineKeyboardMarkup
+	mockTG.On("SendMessageWithKeyboard", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		keyboard = args.Get(2).(*models.InlineKeyboardMarkup)
+	}).Return(1, nil).Once()
+	require.NoError(t, bridge.cmdHandler.HandleSelectSession(ctx))
+	require.NotNil(t, keyboard)
+	assert.Equal(t, "sess:ses_3", keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Contains(t, keyboard.InlineKeyboard[0][0].Text, "⭐ ")
+	assert.Equal(t, "sess:ses_1", keyboard.InlineKeyboard[1][0].CallbackData)
+	assert.NotContains(t, keyboard.InlineKeyboard[1][0].Text, "⭐")
+}
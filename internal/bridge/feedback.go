@@ -0,0 +1,65 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// SetFeedbackChat sets the admin chat that /feedback forwards to
+// (0 disables the command)
+func (b *Bridge) SetFeedbackChat(chatID int64) {
+	b.feedbackChatID = chatID
+}
+
+// HandleFeedbackCommand handles /feedback <text>
+// The text is forwarded to the admin chat with the sender's chat, user and
+// session so operators can follow up on bug reports.
+func (b *Bridge) HandleFeedbackCommand(ctx context.Context, args string) error {
+	if b.feedbackChatID == 0 {
+		_, err := b.tgBot.SendMessage(ctx, b.t("feedback.disabled"))
+		return err
+	}
+
+	text := strings.TrimSpace(args)
+	if text == "" {
+		_, err := b.tgBot.SendMessage(ctx, b.t("feedback.usage"))
+		return err
+	}
+
+	if _, err := b.tgBot.SendMessageToChat(ctx, b.feedbackChatID, b.feedbackReport(ctx, text)); err != nil {
+		return fmt.Errorf("forward feedback: %w", err)
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, b.t("feedback.sent"))
+	return err
+}
+
+// feedbackReport formats feedback for the admin chat
+// Example:
+//
+//	📝 Feedback
+//	Chat: -100123
+//	User: @alice (42)
+//	Session: ses_abc
+//
+//	The model menu is empty
+func (b *Bridge) feedbackReport(ctx context.Context, text string) string {
+	user := b.t("status.unknown")
+	if userID, ok := telegram.UserIDFromContext(ctx); ok {
+		user = fmt.Sprintf("%d", userID)
+		if name, ok := telegram.UserNameFromContext(ctx); ok {
+			user = fmt.Sprintf("%s (%d)", html.EscapeString(name), userID)
+		}
+	}
+
+	session := b.sessions.current(ctx)
+	if session == "" {
+		session = b.t("status.none")
+	}
+
+	return b.t("feedback.report", b.chatID, user, session, html.EscapeString(text))
+}
@@ -0,0 +1,45 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestFeedbackForwardedToAdminChat(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_1")
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), time.Second)
+	bridge.chatID = "-100"
+	bridge.SetFeedbackChat(999)
+	ctx := telegram.WithUserName(telegram.WithUserID(context.Background(), 42), "@alice")
+
+	report := "📝 Feedback\nChat: -100\nUser: @alice (42)\nSession: ses_1\n\nThe &lt;model&gt; menu is empty"
+	mockTG.On("SendMessageToChat", ctx, int64(999), report).Return(7, nil).Once()
+	mockTG.On("SendMessage", ctx, "✅ Thanks! Your feedback was sent to the operators.").Return(1, nil).Once()
+
+	require.NoError(t, bridge.HandleFeedbackCommand(ctx, " The <model> menu is empty "))
+	mockTG.AssertExpectations(t)
+}
+
+func TestFeedbackDisabledAndUsage(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "⚠️ Feedback is not enabled for this bot").Return(1, nil).Once()
+	require.NoError(t, bridge.HandleFeedbackCommand(ctx, "hello"))
+
+	bridge.SetFeedbackChat(999)
+	mockTG.On("SendMessage", ctx, "Usage: /feedback &lt;message&gt;").Return(2, nil).Once()
+	require.NoError(t, bridge.HandleFeedbackCommand(ctx, "  "))
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "SendMessageToChat")
+}
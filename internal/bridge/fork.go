@@ -0,0 +1,86 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// sessionNode is a session placed in the parent/child tree
+type sessionNode struct {
+	opencode.Session
+	depth int
+}
+
+// sessionTree orders sessions depth-first with children directly below their
+// parent. Sessions whose parent is not in the list are treated as roots.
+func sessionTree(sessions []opencode.Session) []sessionNode {
+	known := make(map[string]bool, len(sessions))
+	for _, sess := range sessions {
+		known[sess.ID] = true
+	}
+
+	children := make(map[string][]opencode.Session)
+	var roots []opencode.Session
+	for _, sess := range sessions {
+		if sess.ParentID != nil && known[*sess.ParentID] && *sess.ParentID != sess.ID {
+			children[*sess.ParentID] = append(children[*sess.ParentID], sess)
+		} else {
+			roots = append(roots, sess)
+		}
+	}
+
+	nodes := make([]sessionNode, 0, len(sessions))
+	visited := make(map[string]bool, len(sessions))
+	var walk func(sess opencode.Session, depth int)
+	walk = func(sess opencode.Session, depth int) {
+		if visited[sess.ID] {
+			return
+		}
+		visited[sess.ID] = true
+		nodes = append(nodes, sessionNode{Session: sess, depth: depth})
+		for _, child := range children[sess.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return nodes
+}
+
+// HandleForkSession creates a child of the current session and switches to it
+func (h *CommandHandler) HandleForkSession(ctx context.Context, title *string) error {
+	parentID := h.sessions.current(ctx)
+	if parentID == "" {
+		_, err := h.tgBot.SendMessage(ctx, h.t("fork.none"))
+		return err
+	}
+
+	if title == nil || *title == "" {
+		defaultTitle := h.t("fork.default_title", parentID)
+		sessions, err := h.ocClient.ListSessions()
+		if err != nil {
+			return fmt.Errorf("list sessions: %w", err)
+		}
+		for _, sess := range sessions {
+			if sess.ID == parentID && sess.Title != "" {
+				defaultTitle = h.t("fork.default_title", sess.Title)
+				break
+			}
+		}
+		title = &defaultTitle
+	}
+
+	session, err := h.ocClient.CreateSession(title, &parentID)
+	if err != nil {
+		return fmt.Errorf("fork session: %w", err)
+	}
+
+	h.sessions.set(ctx, session.ID)
+
+	_, err = h.tgBot.SendMessage(ctx, h.t("fork.created", html.EscapeString(session.Title), session.ID, parentID))
+	return err
+}
@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestSessionTree(t *testing.T) {
+	sessions := []opencode.Session{
+		{ID: "ses_child", ParentID: strPtr("ses_root")},
+		{ID: "ses_root"},
+		{ID: "ses_grandchild", ParentID: strPtr("ses_child")},
+		{ID: "ses_orphan", ParentID: strPtr("ses_gone")},
+	}
+
+	var got []string
+	for _, node := range sessionTree(sessions) {
+		got = append(got, strings.Repeat(">", node.depth)+node.ID)
+	}
+	assert.Equal(t, []string{"ses_root", ">ses_child", ">>ses_grandchild", "ses_orphan"}, got)
+}
+
+func TestForkSession(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_parent")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{{ID: "ses_parent", Title: "Refactor"}}, nil)
+	mockOC.On("CreateSession", strPtr("Fork of Refactor"), strPtr("ses_parent")).
+		Return(&opencode.Session{ID: "ses_fork", Title: "Fork of Refactor"}, nil).Once()
+	mockTG.On("SendMessage", ctx, "🌿 Forked into <b>Fork of Refactor</b> (ses_fork)\n↳ parent: <code>ses_parent</code>").Return(1, nil).Once()
+
+	require.NoError(t, bridge.cmdHandler.HandleForkSession(ctx, nil))
+	assert.Equal(t, "ses_fork", appState.GetSessionForChat(""))
+	mockOC.AssertExpectations(t)
+	mockTG.AssertExpectations(t)
+}
+
+func TestForkSessionWithoutCurrent(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "❌ No active session to fork. Use /newsession first.").Return(1, nil).Once()
+
+	require.NoError(t, bridge.cmdHandler.HandleForkSession(ctx, nil))
+	mockOC.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+}
+
+func TestSessionListsShowForks(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_fork")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{
+		{ID: "ses_fork", Title: "Fork", Slug: "fork", ParentID: strPtr("ses_root")},
+		{ID: "ses_root", Title: "Root", Slug: "root"},
+	}, nil)
+	mockOC.On("GetSessionSummary", "ses_root").Return(&opencode.SessionSummary{
+		SessionID: "ses_root", MessageCount: 12, LastAssistant: "Done <b>", InputTokens: 12000, OutputTokens: 345,
+	}, nil).Once()
+	mockOC.On("GetSessionSummary", "ses_fork").Return(nil, errors.New("unavailable")).Once()
+
+	var listing string
+	mockTG.On("SendMessage", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		listing = args.String(1)
+	}).Return(1, nil).Once()
+	require.NoError(t, bridge.cmdHandler.HandleListSessions(ctx))
+	assert.Contains(t, listing, "(showing 1 of 1)")
+	assert.Less(t, strings.Index(listing, "⚫ <b>Root</b>"), strings.Index(listing, "↳ 🟢 <b>Fork</b>"))
+	assert.Contains(t, listing, " · 💬 12 messages · 🪙 12.3k tokens\n   💭 <i>Done &lt;b&gt;</i>\n")
+
+	var keyboard *models.InlineKeyboardMarkup
+	mockTG.On("SendMessageWithKeyboard", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		keyboard = args.Get(2).(*models.InlineKeyboardMarkup)
+	}).Return(2, nil).Once()
+	require.NoError(t, bridge.cmdHandler.HandleSelectSession(ctx))
+	require.NotNil(t, keyboard)
+	assert.Equal(t, "sess:ses_root", keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, "sess:ses_fork", keyboard.InlineKeyboard[1][0].CallbackData)
+	assert.True(t, strings.HasPrefix(keyboard.InlineKeyboard[1][0].Text, "↳ 🟢 Fork"))
+}
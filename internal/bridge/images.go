@@ -0,0 +1,95 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// maxPhotoUploadSize is the Bot API limit for sendPhoto uploads
+const maxPhotoUploadSize = 10 * 1024 * 1024
+
+// sendGeneratedImages forwards image file parts of an assistant message
+// (diagrams, screenshots, ...) to Telegram as photos
+func (b *Bridge) sendGeneratedImages(sessionID string, msg *opencode.Message) {
+	var images []opencode.MessagePart
+	for _, part := range msg.Parts {
+		if part.IsImage() {
+			images = append(images, part)
+		}
+	}
+	if len(images) == 0 {
+		return
+	}
+
+	// Both the SSE and plugin completion paths may deliver the same message
+	cacheKey := fmt.Sprintf("img:%s", msg.Info.ID)
+	if _, exists := b.idleProcessed.LoadOrStore(cacheKey, time.Now()); exists {
+		return
+	}
+	time.AfterFunc(60*time.Second, func() {
+		b.idleProcessed.Delete(cacheKey)
+	})
+
+	ctx := context.Background()
+	for _, part := range images {
+		name := imageFilename(part)
+
+		data, err := b.loadImagePart(part)
+		if err == nil && len(data) > maxPhotoUploadSize {
+			err = fmt.Errorf("larger than %d MB", maxPhotoUploadSize/(1024*1024))
+		}
+		if err == nil {
+			_, err = b.tgBot.SendPhoto(ctx, data, name, "")
+		}
+		if err != nil {
+			b.logger.Error("sendGeneratedImages failed", "session", sessionID, "file", name, "error", err)
+			b.tgBot.SendMessagePlain(ctx, b.t("image.send_failed", name, err.Error()))
+		}
+	}
+}
+
+// loadImagePart resolves a file part's URL to image bytes.
+// Inline data URLs are decoded directly; file:// URLs are read through
+// the OpenCode file API since the server may not share our filesystem.
+func (b *Bridge) loadImagePart(part opencode.MessagePart) ([]byte, error) {
+	if strings.HasPrefix(part.URL, "data:") {
+		return opencode.DecodeDataURL(part.URL)
+	}
+
+	u, err := url.Parse(part.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse image URL: %w", err)
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("unsupported image URL scheme %q", u.Scheme)
+	}
+
+	content, err := b.ocClient.GetFileContent(u.Path)
+	if err != nil {
+		return nil, err
+	}
+	return content.Bytes()
+}
+
+// imageFilename picks an upload filename for an image part
+func imageFilename(part opencode.MessagePart) string {
+	if part.Filename != "" {
+		return part.Filename
+	}
+	if u, err := url.Parse(part.URL); err == nil && u.Scheme != "data" {
+		if base := path.Base(u.Path); base != "." && base != "/" {
+			return base
+		}
+	}
+	if exts, _ := mime.ExtensionsByType(part.Mime); len(exts) > 0 {
+		return "image" + exts[0]
+	}
+	return "image"
+}
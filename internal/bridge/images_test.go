@@ -0,0 +1,68 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestImageFilename(t *testing.T) {
+	assert.Equal(t, "chart.png", imageFilename(opencode.MessagePart{Filename: "chart.png", URL: "file:///tmp/x.png"}))
+	assert.Equal(t, "x.png", imageFilename(opencode.MessagePart{Mime: "image/png", URL: "file:///tmp/x.png"}))
+	assert.Equal(t, "image.png", imageFilename(opencode.MessagePart{Mime: "image/png", URL: "data:image/png;base64,aGk="}))
+}
+
+func TestCompletedMessageSendsGeneratedImages(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	ctx := context.Background()
+
+	msg := &opencode.Message{
+		Info: opencode.MessageInfo{ID: "msg_img", SessionID: "ses_img", Role: "assistant"},
+		Parts: []opencode.MessagePart{
+			{Type: "text", Text: "Here is the diagram"},
+			{Type: "file", Mime: "image/png", Filename: "inline.png", URL: "data:image/png;base64,aGVsbG8="},
+			{Type: "file", Mime: "image/png", URL: "file:///work/out/arch.png"},
+			{Type: "file", Mime: "text/plain", URL: "file:///work/notes.txt"},
+		},
+	}
+	mockOC.On("GetMessage", "ses_img", "msg_img").Return(msg, nil)
+	mockOC.On("GetFileContent", "/work/out/arch.png").
+		Return(&opencode.FileContent{Type: "binary", Content: "d29ybGQ=", Encoding: "base64"}, nil)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+	mockTG.On("SendPhoto", ctx, []byte("hello"), "inline.png", "").Return(2, nil).Once()
+	mockTG.On("SendPhoto", ctx, []byte("world"), "arch.png", "").Return(3, nil).Once()
+
+	bridge.fetchAndSendCompletedMessage("ses_img", "msg_img")
+	// Duplicate delivery (plugin + SSE) must not resend
+	bridge.sendGeneratedImages("ses_img", msg)
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNumberOfCalls(t, "SendPhoto", 2)
+	mockOC.AssertNotCalled(t, "GetFileContent", "/work/notes.txt")
+}
+
+func TestGeneratedImageFailureReported(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	ctx := context.Background()
+
+	msg := &opencode.Message{
+		Info:  opencode.MessageInfo{ID: "msg_remote", Role: "assistant"},
+		Parts: []opencode.MessagePart{{Type: "file", Mime: "image/png", URL: "https://example.com/a.png"}},
+	}
+	mockTG.On("SendMessagePlain", ctx, "⚠️ Could not send image a.png: unsupported image URL scheme \"https\"").Return(1, nil)
+
+	bridge.sendGeneratedImages("ses_remote", msg)
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "SendPhoto", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,81 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// HandleInitCommand handles /init: OpenCode analyzes the current session's
+// project and writes an AGENTS.md for it. A session is created when there is
+// none. The run can take minutes, so it continues in the background and the
+// agent's reply arrives like any other response.
+func (b *Bridge) HandleInitCommand(ctx context.Context) error {
+	model := b.initModel()
+	providerID, modelID, ok := strings.Cut(model, "/")
+	if !ok || providerID == "" || modelID == "" {
+		_, err := b.tgBot.SendMessage(ctx, b.t("init.no_model"))
+		return err
+	}
+
+	sessionID := b.sessions.current(ctx)
+	if sessionID == "" {
+		title := b.t("init.title")
+		session, err := b.ocClient.CreateSession(&title, nil)
+		if err != nil {
+			return fmt.Errorf("create session: %w", err)
+		}
+		sessionID = session.ID
+		b.sessions.set(ctx, sessionID)
+	}
+
+	if b.state.GetSessionStatus(sessionID) == state.SessionBusy {
+		_, err := b.tgBot.SendMessage(ctx, b.t("busy"))
+		return err
+	}
+	b.state.SetSessionStatus(sessionID, state.SessionBusy)
+
+	if _, err := b.tgBot.SendMessage(ctx, b.t("init.started", model)); err != nil {
+		b.logger.Warn("Failed to announce init", "session", sessionID, "error", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		err := b.ocClient.InitSession(sessionID, providerID, modelID)
+		if err != nil {
+			b.logger.Error("Init of session failed", "session", sessionID, "error", err)
+			b.state.SetSessionStatus(sessionID, state.SessionError)
+			b.tgBot.SendMessage(ctx, b.errorText(err))
+			return
+		}
+		b.state.SetSessionStatus(sessionID, state.SessionIdle)
+		b.tgBot.SendMessage(ctx, b.t("init.done"))
+	}()
+	return nil
+}
+
+// initModel returns the "provider/model" to run /init with: the model picked
+// with /model, else the current agent's configured model, else OpenCode's
+// default model
+func (b *Bridge) initModel() string {
+	if model := b.state.GetModelForChat(b.chatID); model != "" {
+		return model
+	}
+
+	config, err := b.ocClient.GetConfig()
+	if err != nil {
+		b.logger.Warn("Failed to read OpenCode config", "error", err)
+		return ""
+	}
+	if agents, ok := config["agent"].(map[string]interface{}); ok {
+		if agentConfig, ok := agents[b.state.GetCurrentAgent()].(map[string]interface{}); ok {
+			if model, ok := agentConfig["model"].(string); ok && model != "" {
+				return model
+			}
+		}
+	}
+	model, _ := config["model"].(string)
+	return model
+}
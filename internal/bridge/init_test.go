@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestHandleInitCommandCreatesSession(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentModel("anthropic/claude-sonnet-4")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("CreateSession", strPtr("Project init"), (*string)(nil)).Return(&opencode.Session{ID: "ses_init"}, nil).Once()
+	mockOC.On("InitSession", "ses_init", "anthropic", "claude-sonnet-4").Return(nil).Once()
+	mockTG.On("SendMessage", mock.Anything, "📝 Analyzing the project and writing AGENTS.md with anthropic/claude-sonnet-4. This can take a few minutes…").Return(1, nil).Once()
+	done := make(chan struct{})
+	mockTG.On("SendMessage", mock.Anything, "✅ AGENTS.md generated.").Run(func(mock.Arguments) { close(done) }).Return(2, nil).Once()
+
+	require.NoError(t, bridge.HandleInitCommand(ctx))
+	assert.Equal(t, "ses_init", appState.GetSessionForChat(""))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("init did not finish")
+	}
+	assert.Equal(t, state.SessionIdle, appState.GetSessionStatus("ses_init"))
+	mockOC.AssertExpectations(t)
+}
+
+func TestHandleInitCommandUsesConfiguredModel(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_1")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("GetConfig").Return(map[string]interface{}{"model": "openai/gpt-4.1"}, nil).Once()
+	mockOC.On("InitSession", "ses_1", "openai", "gpt-4.1").Return(errors.New("boom")).Once()
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+
+	require.NoError(t, bridge.HandleInitCommand(ctx))
+	assert.Eventually(t, func() bool {
+		return appState.GetSessionStatus("ses_1") == state.SessionError
+	}, time.Second, 10*time.Millisecond)
+	mockOC.AssertExpectations(t)
+}
+
+func TestHandleInitCommandWithoutModel(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("GetConfig").Return(map[string]interface{}{}, nil).Once()
+	mockTG.On("SendMessage", ctx, "❌ No model to run /init with. Pick one with /model first.").Return(1, nil).Once()
+
+	require.NoError(t, bridge.HandleInitCommand(ctx))
+	mockOC.AssertNotCalled(t, "InitSession", mock.Anything, mock.Anything, mock.Anything)
+	mockTG.AssertExpectations(t)
+}
@@ -0,0 +1,138 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// janitorInterval is how often the janitor sweeps the in-flight maps
+const janitorInterval = 5 * time.Minute
+
+// janitor drops in-flight entries (thinking messages, stream buffers,
+// permission and question prompts) left behind when a session errors before
+// they are cleaned up. Entries are not timestamped, so it remembers when it
+// first saw each one; an entry replaced under the same key starts over.
+type janitor struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	seen map[string]seenEntry // map name + key -> entry
+}
+
+type seenEntry struct {
+	value any
+	since time.Time
+}
+
+// SetEntryTTL sets how long in-flight entries may live before the janitor
+// drops them (0: never)
+func (b *Bridge) SetEntryTTL(ttl time.Duration) {
+	b.janitor.ttl = ttl
+}
+
+// StartJanitor starts a background goroutine that sweeps expired in-flight
+// entries every 5 minutes
+func (b *Bridge) StartJanitor(ctx context.Context) {
+	if b.janitor.ttl <= 0 {
+		return
+	}
+	ticker := time.NewTicker(janitorInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				b.sweep(now)
+			}
+		}
+	}()
+}
+
+// sweep drops the entries first seen more than the TTL before now. The
+// state of a session that is still busy is kept, as a long generation may
+// outlive the TTL. Permission and question prompts are only dropped once
+// OpenCode no longer has them pending, closing their keyboards.
+func (b *Bridge) sweep(now time.Time) {
+	j := &b.janitor
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.seen == nil {
+		j.seen = make(map[string]seenEntry)
+	}
+
+	alive := make(map[string]bool)
+	// age returns how long ago an entry was first seen
+	age := func(name string, key, value any) time.Duration {
+		id := fmt.Sprintf("%s/%v", name, key)
+		alive[id] = true
+		entry, ok := j.seen[id]
+		if !ok || entry.value != value {
+			j.seen[id] = seenEntry{value: value, since: now}
+			return 0
+		}
+		return now.Sub(entry.since)
+	}
+	visit := func(name string, m *sync.Map) {
+		m.Range(func(key, value any) bool {
+			entryAge := age(name, key, value)
+			if entryAge < j.ttl || b.state.GetSessionStatus(key.(string)) == state.SessionBusy {
+				return true
+			}
+			if m.CompareAndDelete(key, value) {
+				b.countEviction(name, 1)
+				b.logger.Info("Dropped stale entry", "kind", name, "key", key, "age", entryAge.Round(time.Second))
+			}
+			return true
+		})
+	}
+	visit("thinking", &b.thinkingMsgs)
+	visit("stream", &b.streamBuffers)
+	visit("message", &b.msgBuffers)
+
+	// stale reports whether a prompt has outlived the TTL
+	stale := func(name string, m *sync.Map) bool {
+		found := false
+		m.Range(func(key, value any) bool {
+			if age(name, key, value) >= j.ttl {
+				found = true
+			}
+			return true
+		})
+		return found
+	}
+	ctx := context.Background()
+	if stale("permission", &b.permissions) {
+		if pending, err := b.ocClient.ListPermissions(); err != nil {
+			b.logger.Warn("Keeping stale permissions: failed to list pending ones", "error", err)
+		} else {
+			b.countEviction("permission", b.closeStalePermissions(ctx, pending))
+		}
+	}
+	if stale("question", &b.questions) {
+		if pending, err := b.ocClient.ListQuestions(); err != nil {
+			b.logger.Warn("Keeping stale questions: failed to list pending ones", "error", err)
+		} else {
+			b.countEviction("question", b.closeStaleQuestions(ctx, pending))
+		}
+	}
+
+	for id := range j.seen {
+		if !alive[id] {
+			delete(j.seen, id)
+		}
+	}
+}
+
+// countEviction counts entries the janitor dropped from the named map
+func (b *Bridge) countEviction(name string, n int) {
+	if n > 0 {
+		metrics.BridgeEntriesEvicted.WithLabelValues(b.account.Name, b.account.Chat, name).Add(float64(n))
+	}
+}
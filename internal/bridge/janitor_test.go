@@ -0,0 +1,97 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// counter reads a counter of the default registry, 0 when the labels
+// were never counted
+func counter(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok {
+					if label.GetValue() != value {
+						continue metrics
+					}
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+// evictions reads the janitor's eviction counter for a map
+func evictions(t *testing.T, name string) float64 {
+	return counter(t, "bridge_entries_evicted_total", map[string]string{"map": name})
+}
+
+func TestJanitorDropsStaleEntries(t *testing.T) {
+	appState := state.NewAppStateForTest()
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	bridge.SetEntryTTL(time.Hour)
+	thinkingBefore, permissionBefore := evictions(t, "thinking"), evictions(t, "permission")
+
+	start := time.Now()
+	bridge.thinkingMsgs.Store("ses_stuck", 10)
+	bridge.thinkingMsgs.Store("ses_busy", 20)
+	bridge.thinkingMsgs.Store("ses_long", 40)
+	appState.SetSessionStatus("ses_long", state.SessionBusy)
+	bridge.storePermission("p:1", PermissionState{PermissionID: "perm_1", SessionID: "ses_stuck", MessageID: 11})
+	bridge.storePermission("p:2", PermissionState{PermissionID: "perm_2", SessionID: "ses_long", MessageID: 41})
+	bridge.sweep(start)
+
+	// ses_busy moved on to another prompt; ses_new appeared meanwhile
+	bridge.thinkingMsgs.Store("ses_busy", 21)
+	bridge.thinkingMsgs.Store("ses_new", 30)
+	bridge.sweep(start.Add(30 * time.Minute))
+	_, ok := bridge.thinkingMsgs.Load("ses_stuck")
+	assert.True(t, ok, "expected entries younger than the TTL to be kept")
+
+	// OpenCode still waits for perm_2 only
+	mockOC.On("ListPermissions").Return([]opencode.PermissionRequest{{ID: "perm_2", SessionID: "ses_long"}}, nil).Once()
+	mockTG.On("EditMessage", mock.Anything, 11, mock.Anything).Return(nil).Once()
+	bridge.sweep(start.Add(61 * time.Minute))
+	_, ok = bridge.thinkingMsgs.Load("ses_stuck")
+	assert.False(t, ok, "expected the stale thinking message to be dropped")
+	_, ok = bridge.thinkingMsgs.Load("ses_busy")
+	assert.True(t, ok, "expected a replaced entry to start over")
+	_, ok = bridge.thinkingMsgs.Load("ses_new")
+	assert.True(t, ok)
+	_, ok = bridge.thinkingMsgs.Load("ses_long")
+	assert.True(t, ok, "expected the thinking message of a busy session to be kept")
+	_, ok = bridge.permissions.Load("p:1")
+	assert.False(t, ok, "expected the answered permission to be dropped")
+	_, ok = bridge.permissions.Load("p:2")
+	assert.True(t, ok, "expected the open permission to be kept")
+	assert.Len(t, appState.ListPending(), 1, "expected the dropped permission to leave the state file")
+	mockOC.AssertExpectations(t)
+	mockTG.AssertExpectations(t)
+
+	assert.Equal(t, float64(1), evictions(t, "thinking")-thinkingBefore)
+	assert.Equal(t, float64(1), evictions(t, "permission")-permissionBefore)
+}
@@ -0,0 +1,98 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/logging"
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// translator resolves bot-facing strings in the chat's current language.
+// Handlers embed it; the bridge wires lang to its chat when registering them.
+// Strings are redacted, as errors in the arguments may hold a bot token, e.g.
+// in a file URL.
+type translator struct {
+	lang func() i18n.Lang
+}
+
+func (tr translator) language() i18n.Lang {
+	if tr.lang == nil {
+		return i18n.Default
+	}
+	return tr.lang()
+}
+
+func (tr translator) t(key string, args ...interface{}) string {
+	return logging.Redact(i18n.T(tr.language(), key, args...))
+}
+
+// lang returns the language selected for this bridge's chat
+func (b *Bridge) lang() i18n.Lang {
+	return i18n.Lang(b.state.GetLanguageForChat(b.chatID))
+}
+
+func (b *Bridge) t(key string, args ...interface{}) string {
+	return logging.Redact(i18n.T(b.lang(), key, args...))
+}
+
+// errorText formats err for the chat. Connection failures and OpenCode API
+// errors get a friendly notice instead of the raw error.
+func (b *Bridge) errorText(err error) string {
+	if errors.Is(err, opencode.ErrUnavailable) {
+		return b.t("opencode.unavailable")
+	}
+	var apiErr *opencode.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.RateLimited() {
+			return b.t("opencode.rate_limited")
+		}
+		return b.t("opencode.api_error", apiErr.Message)
+	}
+	return b.t("error", err)
+}
+
+// SetDefaultLanguage sets the language for chats that have not used /lang
+func (b *Bridge) SetDefaultLanguage(lang i18n.Lang) {
+	b.state.SetDefaultLanguage(string(lang))
+}
+
+// HandleLangCommand handles /lang [code]
+// Without args: shows the current and available languages. With a code: switches this chat.
+func (b *Bridge) HandleLangCommand(ctx context.Context, args string) error {
+	var available []string
+	for _, lang := range i18n.Supported() {
+		available = append(available, string(lang)+" ("+lang.Name()+")")
+	}
+	availableStr := strings.Join(available, ", ")
+
+	args = strings.TrimSpace(args)
+	if args == "" {
+		_, err := b.tgBot.SendMessage(ctx, b.t("lang.current", b.lang().Name(), availableStr))
+		return err
+	}
+
+	lang, ok := i18n.Parse(args)
+	if !ok {
+		_, err := b.tgBot.SendMessage(ctx, b.t("lang.unknown", args, availableStr))
+		return err
+	}
+
+	b.state.SetChatLanguage(b.chatID, string(lang))
+
+	// Translate the command menu for everyone in this chat
+	if err := b.tgBot.SetChatCommands(ctx, lang); err != nil {
+		b.logger.Warn("Failed to update chat commands", "error", err)
+	}
+
+	// Refresh the quick action keyboard so its labels match the new language
+	if b.quickKeyboard {
+		_, err := b.tgBot.SendMessageWithReplyMarkup(ctx, b.t("lang.set", lang.Name()), b.quickActionKeyboard())
+		return err
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, b.t("lang.set", lang.Name()))
+	return err
+}
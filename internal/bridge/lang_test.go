@@ -0,0 +1,81 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestLangCommandSwitchesChatLanguage(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 100*time.Millisecond)
+	ctx := context.Background()
+
+	mockTG.On("SetChatCommands", ctx, i18n.Chinese).Return(nil).Once()
+	mockTG.On("SendMessage", ctx, "🌐 語言已設為 繁體中文").Return(1, nil)
+	mockTG.On("SendMessage", ctx, "❌ 沒有可中止的 session").Return(2, nil)
+
+	assert.NoError(t, bridge.HandleLangCommand(ctx, "zh-TW"))
+	assert.Equal(t, i18n.Chinese, bridge.lang())
+
+	// Command handler replies follow the chat language
+	assert.NoError(t, bridge.cmdHandler.HandleAbortSession(ctx))
+	mockTG.AssertExpectations(t)
+}
+
+func TestLangCommandRejectsUnknown(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, mock.MatchedBy(func(msg string) bool {
+		return strings.Contains(msg, "Unknown language: fr")
+	})).Return(1, nil)
+
+	assert.NoError(t, bridge.HandleLangCommand(ctx, "fr"))
+	assert.Equal(t, i18n.English, bridge.lang())
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "SetChatCommands", mock.Anything, mock.Anything)
+}
+
+func TestDefaultLanguageAppliesWithoutSelection(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetDefaultLanguage(i18n.Chinese)
+
+	assert.Equal(t, "⏳ 處理中...", bridge.t("processing"))
+}
+
+func TestErrorTextIsUserFriendly(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+
+	unavailable := fmt.Errorf("list sessions: %w", fmt.Errorf("%w: dial tcp: connection refused", opencode.ErrUnavailable))
+	assert.Equal(t, "⚠️ OpenCode is unreachable right now. Please try again shortly.", bridge.errorText(unavailable))
+	assert.Equal(t, "❌ Error: session not found", bridge.errorText(errors.New("session not found")))
+
+	apiErr := &opencode.APIError{Op: "abort session", StatusCode: 404, Code: "NotFoundError", Message: "Session not found"}
+	assert.Equal(t, "❌ OpenCode: Session not found", bridge.errorText(fmt.Errorf("abort: %w", apiErr)))
+	apiErr = &opencode.APIError{Op: "trigger prompt", StatusCode: 429, Message: "Too Many Requests"}
+	assert.Equal(t, "⏳ The model is rate limited right now. Please try again in a moment.", bridge.errorText(apiErr))
+}
+
+func TestErrorTextRedactsTokens(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+
+	err := errors.New(`Get "https://api.telegram.org/file/bot123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsawq/voice/file_2.oga": EOF`)
+	assert.Equal(t, `❌ Error: Get "https://api.telegram.org/file/bot123456789:[REDACTED]/voice/file_2.oga": EOF`, bridge.errorText(err))
+}
@@ -0,0 +1,27 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/logging"
+)
+
+// HandleLogLevelCommand handles /loglevel [debug|info|warn|error]
+// Without args: shows the current level. The level applies to the whole
+// bridge, every account included, until the next restart or reload.
+func (b *Bridge) HandleLogLevelCommand(ctx context.Context, args string) error {
+	var msg string
+	if args = strings.TrimSpace(args); args == "" {
+		msg = b.t("loglevel.current", logging.CurrentLevel())
+	} else if level, ok := logging.ParseLevel(args); ok {
+		logging.SetLevel(level)
+		b.logger.Info("Log level set from chat", "level", level)
+		msg = b.t("loglevel.set", level)
+	} else {
+		msg = b.t("loglevel.invalid")
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, msg)
+	return err
+}
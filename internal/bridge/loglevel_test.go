@@ -0,0 +1,54 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/logging"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestLogLevelCommand(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	ctx := context.Background()
+	defer logging.SetLevel(logging.CurrentLevel())
+	logging.SetLevel(logging.LevelInfo)
+
+	mockTG.On("SendMessage", ctx, "📝 Log level: <b>info</b>\n\nUsage: /loglevel debug|info|warn|error").Return(1, nil).Once()
+	assert.NoError(t, bridge.HandleLogLevelCommand(ctx, ""))
+
+	mockTG.On("SendMessage", ctx, "✅ Log level set to <b>debug</b>").Return(1, nil).Once()
+	assert.NoError(t, bridge.HandleLogLevelCommand(ctx, "DEBUG"))
+	assert.Equal(t, logging.LevelDebug, logging.CurrentLevel())
+
+	mockTG.On("SendMessage", ctx, "❌ Give one of debug, info, warn or error").Return(1, nil).Once()
+	assert.NoError(t, bridge.HandleLogLevelCommand(ctx, "loud"))
+	assert.Equal(t, logging.LevelDebug, logging.CurrentLevel(), "an invalid level must keep the current one")
+
+	mockTG.AssertExpectations(t)
+}
+
+func TestSetAccountLabelsLogLines(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, logging.Setup(&out, logging.FormatJSON))
+	defer logging.Setup(os.Stderr, logging.FormatText)
+
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetAccount("work")
+	// Lines of the command handler carry the account too
+	bridge.cmdHandler.logger.Info("switched", "session", "ses_1")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "bridge", line["component"])
+	assert.Equal(t, "work", line["account"])
+	assert.Equal(t, "ses_1", line["session"])
+}
@@ -3,12 +3,15 @@ package bridge
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-telegram/bot/models"
 	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
 )
 
 // modelTelegramBot interface for sending messages and keyboards
@@ -22,8 +25,8 @@ type modelTelegramBot interface {
 
 // modelAppState for app state access
 type modelAppState interface {
-	SetCurrentModel(model string)
-	GetCurrentModel() string
+	SetChatModel(chatID string, model string)
+	GetModelForChat(chatID string) string
 }
 
 // modelOpenCodeClient for OpenCode API access
@@ -31,11 +34,26 @@ type modelOpenCodeClient interface {
 	GetProviders() (*opencode.ProvidersResponse, error)
 }
 
+// modelCacheTTL is how long the provider model list is reused before /model
+// fetches it again
+const modelCacheTTL = 5 * time.Minute
+
 // ModelHandler manages model selection
 type ModelHandler struct {
 	tgBot    modelTelegramBot
 	appState modelAppState
 	ocClient modelOpenCodeClient
+	chatID   string // whose model /model picks
+	audit    *auditTrail
+	logger   *slog.Logger
+	translator
+
+	// Model list cache; only lists fetched from OpenCode are cached, so the
+	// fallback is replaced as soon as the server answers again
+	cacheMu   sync.Mutex
+	cached    []modelEntry
+	fetchedAt time.Time
+	cacheTTL  time.Duration
 }
 
 // NewModelHandler creates a new ModelHandler
@@ -44,15 +62,17 @@ func NewModelHandler(tgBot modelTelegramBot, appState modelAppState, ocClient mo
 		tgBot:    tgBot,
 		appState: appState,
 		ocClient: ocClient,
+		cacheTTL: modelCacheTTL,
+		logger:   logger,
 	}
 }
 
 // HandleModelCommand processes the /model command
 // Shows available models as paginated Inline Keyboard
 func (h *ModelHandler) HandleModelCommand(ctx context.Context) error {
-	log.Printf("[MODEL] HandleModelCommand called")
-	models := h.GetAvailableModels(ctx)
-	log.Printf("[MODEL] Got %d models to display", len(models))
+	h.logger.Debug("HandleModelCommand called")
+	models := h.availableModels()
+	h.logger.Debug("Got models to display", "count", len(models))
 
 	// Show first page
 	return h.showModelPage(ctx, models, 0)
@@ -60,15 +80,20 @@ func (h *ModelHandler) HandleModelCommand(ctx context.Context) error {
 
 // HandleModelCallback processes model selection or pagination from Inline Keyboard
 func (h *ModelHandler) HandleModelCallback(ctx context.Context, msgID int, data string) error {
-	models := h.GetAvailableModels(ctx)
-
-	// Parse callback: mdl:page:N or mdl:sel:MODEL
+	// Parse callback: mdl:page:N, mdl:sel:MODEL or mdl:refresh
 	if len(data) < 4 {
 		return fmt.Errorf("invalid callback data: %s", data)
 	}
 
 	action := data[4:] // skip "mdl:"
 
+	if action == "refresh" {
+		h.invalidateModels()
+		return h.editModelPage(ctx, msgID, h.availableModels(), 0)
+	}
+
+	models := h.availableModels()
+
 	// Page navigation
 	if strings.HasPrefix(action, "page:") {
 		var page int
@@ -85,13 +110,14 @@ func (h *ModelHandler) HandleModelCallback(ctx context.Context, msgID int, data
 	// Model selection
 	if strings.HasPrefix(action, "sel:") {
 		model := action[4:]
-		if !isValidModel(model, models) {
+		if !isValidModel(model, modelIDs(models)) {
 			return fmt.Errorf("invalid model: %s", model)
 		}
 
-		h.appState.SetCurrentModel(model)
+		h.appState.SetChatModel(h.chatID, model)
+		h.audit.record(ctx, h.chatID, state.AuditModelSwitch, model)
 
-		msg := fmt.Sprintf("✅ Model set to: %s", model)
+		msg := h.t("model.set", model)
 		_, err := h.tgBot.SendMessage(ctx, msg)
 		return err
 	}
@@ -100,7 +126,7 @@ func (h *ModelHandler) HandleModelCallback(ctx context.Context, msgID int, data
 }
 
 // showModelPage displays models for a given page
-func (h *ModelHandler) showModelPage(ctx context.Context, models []string, page int) error {
+func (h *ModelHandler) showModelPage(ctx context.Context, models []modelEntry, page int) error {
 	const perPage = 8
 
 	if page < 0 {
@@ -122,17 +148,17 @@ func (h *ModelHandler) showModelPage(ctx context.Context, models []string, page
 	}
 
 	pageModels := models[start:end]
-	currentModel := h.appState.GetCurrentModel()
+	currentModel := h.appState.GetModelForChat(h.chatID)
 
-	keyboard := buildModelKeyboard(pageModels, currentModel, page, len(models), perPage)
+	keyboard := h.buildModelKeyboard(modelIDs(pageModels), currentModel, page, len(models), perPage)
 
-	msg := "🤖 Select a Model:\n\n"
+	msg := h.t("model.select")
 	for _, m := range pageModels {
 		prefix := "  "
-		if m == currentModel {
+		if m.ID == currentModel {
 			prefix = "✅"
 		}
-		msg += fmt.Sprintf("%s %s\n", prefix, m)
+		msg += fmt.Sprintf("%s %s\n", prefix, formatModelLine(m))
 	}
 
 	_, err := h.tgBot.SendMessageWithKeyboard(ctx, msg, keyboard)
@@ -140,7 +166,7 @@ func (h *ModelHandler) showModelPage(ctx context.Context, models []string, page
 }
 
 // editModelPage edits the message for a given page
-func (h *ModelHandler) editModelPage(ctx context.Context, msgID int, models []string, page int) error {
+func (h *ModelHandler) editModelPage(ctx context.Context, msgID int, models []modelEntry, page int) error {
 	const perPage = 8
 
 	if page < 0 {
@@ -162,24 +188,24 @@ func (h *ModelHandler) editModelPage(ctx context.Context, msgID int, models []st
 	}
 
 	pageModels := models[start:end]
-	currentModel := h.appState.GetCurrentModel()
+	currentModel := h.appState.GetModelForChat(h.chatID)
 
-	keyboard := buildModelKeyboard(pageModels, currentModel, page, len(models), perPage)
+	keyboard := h.buildModelKeyboard(modelIDs(pageModels), currentModel, page, len(models), perPage)
 
-	msg := "🤖 Select a Model:\n\n"
+	msg := h.t("model.select")
 	for _, m := range pageModels {
 		prefix := "  "
-		if m == currentModel {
+		if m.ID == currentModel {
 			prefix = "✅"
 		}
-		msg += fmt.Sprintf("%s %s\n", prefix, m)
+		msg += fmt.Sprintf("%s %s\n", prefix, formatModelLine(m))
 	}
 
 	return h.tgBot.EditMessageWithKeyboard(ctx, msgID, msg, keyboard)
 }
 
 // buildModelKeyboard creates an Inline Keyboard with model buttons and pagination
-func buildModelKeyboard(pageModels []string, currentModel string, page int, total int, perPage int) *models.InlineKeyboardMarkup {
+func (h *ModelHandler) buildModelKeyboard(pageModels []string, currentModel string, page int, total int, perPage int) *models.InlineKeyboardMarkup {
 	buttons := make([][]models.InlineKeyboardButton, 0)
 
 	for i := 0; i < len(pageModels); i += 2 {
@@ -215,7 +241,7 @@ func buildModelKeyboard(pageModels []string, currentModel string, page int, tota
 	if totalPages > 1 {
 		navRow := make([]models.InlineKeyboardButton, 0, 2)
 		if page > 0 {
-			prevText := fmt.Sprintf("◀️ Prev")
+			prevText := h.t("nav.prev")
 			prevCbData := fmt.Sprintf("mdl:page:%d", page-1)
 			navRow = append(navRow, models.InlineKeyboardButton{
 				Text:         prevText,
@@ -223,7 +249,7 @@ func buildModelKeyboard(pageModels []string, currentModel string, page int, tota
 			})
 		}
 		if page < totalPages-1 {
-			nextText := fmt.Sprintf("Next ▶️")
+			nextText := h.t("nav.next")
 			nextCbData := fmt.Sprintf("mdl:page:%d", page+1)
 			navRow = append(navRow, models.InlineKeyboardButton{
 				Text:         nextText,
@@ -235,6 +261,11 @@ func buildModelKeyboard(pageModels []string, currentModel string, page int, tota
 		}
 	}
 
+	buttons = append(buttons, []models.InlineKeyboardButton{{
+		Text:         h.t("model.refresh"),
+		CallbackData: "mdl:refresh",
+	}})
+
 	return &models.InlineKeyboardMarkup{
 		InlineKeyboard: buttons,
 	}
@@ -250,36 +281,104 @@ func isValidModel(model string, models []string) bool {
 	return false
 }
 
-// GetAvailableModels returns the list of available models
+// modelEntry is a selectable model with the metadata shown in the list
+type modelEntry struct {
+	ID   string // "provider/model", as OpenCode expects
+	Info opencode.Model
+}
+
+// modelIDs returns the IDs of entries
+func modelIDs(entries []modelEntry) []string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+// formatModelLine renders a model with its context size, cost and status
+// Example: "anthropic/claude-sonnet-4 · 200k ctx · $3/$15 per 1M · beta"
+func formatModelLine(m modelEntry) string {
+	parts := []string{m.ID}
+	if m.Info.Limit.Context > 0 {
+		parts = append(parts, fmt.Sprintf("%dk ctx", m.Info.Limit.Context/1000))
+	}
+	if m.Info.Cost.Input > 0 || m.Info.Cost.Output > 0 {
+		parts = append(parts, fmt.Sprintf("$%g/$%g per 1M", m.Info.Cost.Input, m.Info.Cost.Output))
+	}
+	if m.Info.Status != "" && m.Info.Status != "active" {
+		parts = append(parts, m.Info.Status)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// GetAvailableModels returns the IDs of the available models
 func (h *ModelHandler) GetAvailableModels(ctx context.Context) []string {
-	log.Printf("[MODEL] GetAvailableModels called")
+	return modelIDs(h.availableModels())
+}
+
+// availableModels returns the cached model list, fetching it when the cache
+// is empty or older than cacheTTL
+func (h *ModelHandler) availableModels() []modelEntry {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+
+	if h.cached != nil && time.Since(h.fetchedAt) < h.cacheTTL {
+		return h.cached
+	}
+
+	models, fromAPI := h.fetchModels()
+	if fromAPI {
+		h.cached = models
+		h.fetchedAt = time.Now()
+	}
+	return models
+}
+
+// invalidateModels drops the cached model list so the next lookup refetches it
+func (h *ModelHandler) invalidateModels() {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+	h.cached = nil
+}
+
+// fetchModels fetches the models of all configured providers, skipping
+// deprecated ones. Falls back to a hardcoded list when OpenCode has none;
+// fromAPI reports whether the list came from OpenCode.
+func (h *ModelHandler) fetchModels() (models []modelEntry, fromAPI bool) {
+	h.logger.Debug("Fetching models from providers")
 	providers, err := h.ocClient.GetProviders()
 	if err != nil {
-		log.Printf("[MODEL] Error fetching providers: %v", err)
+		h.logger.Error("Error fetching providers", "error", err)
 	} else if providers == nil {
-		log.Printf("[MODEL] Providers response is nil")
+		h.logger.Debug("Providers response is nil")
 	} else {
-		log.Printf("[MODEL] Got %d providers", len(providers.Providers))
-		var models []string
+		h.logger.Debug("Got providers", "count", len(providers.Providers))
 		for _, provider := range providers.Providers {
-			log.Printf("[MODEL] Provider: %s, models: %d", provider.Name, len(provider.Models))
+			h.logger.Debug("Provider", "provider", provider.Name, "models", len(provider.Models))
 			for modelID, model := range provider.Models {
-				if model.Status == "active" {
-					displayName := fmt.Sprintf("%s (%s)", modelID, provider.Name)
-					models = append(models, displayName)
+				if model.Status == "deprecated" {
+					continue
+				}
+				id := provider.ID + "/" + modelID
+				// Telegram rejects the whole keyboard if any callback_data exceeds 64 bytes
+				if len("mdl:sel:"+id) > 64 {
+					h.logger.Debug("Skipping model: ID too long for a button", "model", id)
+					continue
 				}
+				models = append(models, modelEntry{ID: id, Info: model})
 			}
 		}
 		if len(models) > 0 {
-			log.Printf("[MODEL] Returning %d models from API", len(models))
-			sort.Strings(models)
-			return models
+			h.logger.Debug("Returning models from API", "count", len(models))
+			sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+			return models, true
 		}
-		log.Printf("[MODEL] No active models found, using fallback")
+		h.logger.Debug("No available models found, using fallback")
 	}
 
-	log.Printf("[MODEL] Using hardcoded fallback")
-	return []string{
+	h.logger.Debug("Using hardcoded fallback")
+	fallback := []string{
 		"claude-sonnet-4-20250514",
 		"claude-opus-4-20250514",
 		"claude-haiku-4-20250514",
@@ -288,4 +387,9 @@ func (h *ModelHandler) GetAvailableModels(ctx context.Context) []string {
 		"gemini-2.5-pro",
 		"gemini-2.5-flash",
 	}
+	models = make([]modelEntry, len(fallback))
+	for i, id := range fallback {
+		models[i] = modelEntry{ID: id}
+	}
+	return models, false
 }
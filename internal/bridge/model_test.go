@@ -2,10 +2,16 @@ package bridge
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
 )
 
 type mockModelTelegramBot struct {
@@ -33,26 +39,48 @@ func (m *mockModelTelegramBot) EditMessage(ctx context.Context, msgID int, text
 	return nil
 }
 
+func (m *mockModelTelegramBot) EditMessageWithKeyboard(ctx context.Context, msgID int, text string, keyboard *models.InlineKeyboardMarkup) error {
+	if m.editedMessages == nil {
+		m.editedMessages = make(map[int]string)
+	}
+	m.editedMessages[msgID] = text
+	return nil
+}
+
 func (m *mockModelTelegramBot) AnswerCallback(ctx context.Context, callbackID string) error {
 	return nil
 }
 
 type mockModelAppState struct {
-	currentModel string
+	models map[string]string
+}
+
+func (m *mockModelAppState) SetChatModel(chatID string, model string) {
+	if m.models == nil {
+		m.models = make(map[string]string)
+	}
+	m.models[chatID] = model
 }
 
-func (m *mockModelAppState) SetCurrentModel(model string) {
-	m.currentModel = model
+func (m *mockModelAppState) GetModelForChat(chatID string) string {
+	return m.models[chatID]
 }
 
-func (m *mockModelAppState) GetCurrentModel() string {
-	return m.currentModel
+type mockModelOpenCodeClient struct {
+	providers *opencode.ProvidersResponse
+	err       error
+	calls     int
+}
+
+func (m *mockModelOpenCodeClient) GetProviders() (*opencode.ProvidersResponse, error) {
+	m.calls++
+	return m.providers, m.err
 }
 
 func TestModelCommandShowsKeyboard(t *testing.T) {
 	mockTG := &mockModelTelegramBot{}
 	appState := &mockModelAppState{}
-	handler := NewModelHandler(mockTG, appState)
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{err: errors.New("unavailable")})
 	err := handler.HandleModelCommand(context.Background())
 	if err != nil {
 		t.Fatalf("HandleModelCommand failed: %v", err)
@@ -71,7 +99,7 @@ func TestModelCommandShowsKeyboard(t *testing.T) {
 func TestModelPaginationFirstPage(t *testing.T) {
 	mockTG := &mockModelTelegramBot{}
 	appState := &mockModelAppState{}
-	handler := NewModelHandler(mockTG, appState)
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{err: errors.New("unavailable")})
 	err := handler.HandleModelCommand(context.Background())
 	if err != nil {
 		t.Fatalf("HandleModelCommand failed: %v", err)
@@ -88,7 +116,7 @@ func TestModelPaginationFirstPage(t *testing.T) {
 func TestModelPaginationLastPage(t *testing.T) {
 	mockTG := &mockModelTelegramBot{}
 	appState := &mockModelAppState{}
-	handler := NewModelHandler(mockTG, appState)
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{err: errors.New("unavailable")})
 	models := handler.GetAvailableModels(context.Background())
 	if len(models) <= 8 {
 		t.Skip("Skipping last page test - models list too small for pagination")
@@ -108,7 +136,7 @@ func TestModelPaginationLastPage(t *testing.T) {
 func TestModelSelectionUpdatesState(t *testing.T) {
 	mockTG := &mockModelTelegramBot{}
 	appState := &mockModelAppState{}
-	handler := NewModelHandler(mockTG, appState)
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{err: errors.New("unavailable")})
 	models := handler.GetAvailableModels(context.Background())
 	selectedModel := models[0]
 	callbackData := "mdl:sel:" + selectedModel
@@ -116,8 +144,8 @@ func TestModelSelectionUpdatesState(t *testing.T) {
 	if err != nil {
 		t.Fatalf("HandleModelCallback failed: %v", err)
 	}
-	if appState.GetCurrentModel() != selectedModel {
-		t.Errorf("Expected model '%s', got '%s'", selectedModel, appState.GetCurrentModel())
+	if appState.GetModelForChat("") != selectedModel {
+		t.Errorf("Expected model '%s', got '%s'", selectedModel, appState.GetModelForChat(""))
 	}
 	if len(mockTG.messages) == 0 {
 		t.Fatal("Expected confirmation message")
@@ -131,10 +159,10 @@ func TestModelSelectionUpdatesState(t *testing.T) {
 func TestModelCurrentHighlighted(t *testing.T) {
 	mockTG := &mockModelTelegramBot{}
 	appState := &mockModelAppState{}
-	handler := NewModelHandler(mockTG, appState)
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{err: errors.New("unavailable")})
 	models := handler.GetAvailableModels(context.Background())
 	selectedModel := models[0]
-	appState.SetCurrentModel(selectedModel)
+	appState.SetChatModel("", selectedModel)
 	err := handler.HandleModelCommand(context.Background())
 	if err != nil {
 		t.Fatalf("HandleModelCommand failed: %v", err)
@@ -148,7 +176,7 @@ func TestModelCurrentHighlighted(t *testing.T) {
 func TestModelCallbackPageNavigation(t *testing.T) {
 	mockTG := &mockModelTelegramBot{}
 	appState := &mockModelAppState{}
-	handler := NewModelHandler(mockTG, appState)
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{err: errors.New("unavailable")})
 	models := handler.GetAvailableModels(context.Background())
 	if len(models) <= 8 {
 		t.Skip("Skipping pagination test - models list too small")
@@ -163,3 +191,129 @@ func TestModelCallbackPageNavigation(t *testing.T) {
 		t.Fatal("Expected message to be edited for page navigation")
 	}
 }
+
+func TestModelsFromProviders(t *testing.T) {
+	mockTG := &mockModelTelegramBot{}
+	appState := &mockModelAppState{}
+	providers := &opencode.ProvidersResponse{
+		Providers: []opencode.Provider{{
+			ID:   "anthropic",
+			Name: "Anthropic",
+			Models: map[string]opencode.Model{
+				"claude-sonnet-4": {
+					ID:    "claude-sonnet-4",
+					Cost:  opencode.ModelCost{Input: 3, Output: 15},
+					Limit: opencode.ModelLimit{Context: 200000},
+				},
+				"claude-next":           {ID: "claude-next", Status: "beta"},
+				"claude-2":              {ID: "claude-2", Status: "deprecated"},
+				strings.Repeat("x", 60): {ID: "too-long"},
+			},
+		}},
+	}
+	handler := NewModelHandler(mockTG, appState, &mockModelOpenCodeClient{providers: providers})
+
+	got := handler.GetAvailableModels(context.Background())
+	want := []string{"anthropic/claude-next", "anthropic/claude-sonnet-4"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	appState.SetChatModel("", "anthropic/claude-sonnet-4")
+	if err := handler.HandleModelCommand(context.Background()); err != nil {
+		t.Fatalf("HandleModelCommand failed: %v", err)
+	}
+	msg := mockTG.messages[0]
+	if !strings.Contains(msg, "✅ anthropic/claude-sonnet-4 · 200k ctx · $3/$15 per 1M\n") {
+		t.Errorf("Expected model metadata in list, got '%s'", msg)
+	}
+	if !strings.Contains(msg, "anthropic/claude-next · beta\n") {
+		t.Errorf("Expected beta status in list, got '%s'", msg)
+	}
+}
+
+func TestModelListCached(t *testing.T) {
+	mockTG := &mockModelTelegramBot{}
+	ocClient := &mockModelOpenCodeClient{providers: &opencode.ProvidersResponse{
+		Providers: []opencode.Provider{{
+			ID:     "anthropic",
+			Models: map[string]opencode.Model{"claude-sonnet-4": {ID: "claude-sonnet-4"}},
+		}},
+	}}
+	handler := NewModelHandler(mockTG, &mockModelAppState{}, ocClient)
+	ctx := context.Background()
+
+	if err := handler.HandleModelCommand(ctx); err != nil {
+		t.Fatalf("HandleModelCommand failed: %v", err)
+	}
+	if err := handler.HandleModelCallback(ctx, 0, "mdl:page:0"); err != nil {
+		t.Fatalf("page callback failed: %v", err)
+	}
+	if err := handler.HandleModelCallback(ctx, 0, "mdl:sel:anthropic/claude-sonnet-4"); err != nil {
+		t.Fatalf("select callback failed: %v", err)
+	}
+	if ocClient.calls != 1 {
+		t.Errorf("Expected providers to be fetched once, got %d", ocClient.calls)
+	}
+
+	keyboard := mockTG.keyboards[0].InlineKeyboard
+	refresh := keyboard[len(keyboard)-1][0]
+	if refresh.CallbackData != "mdl:refresh" || refresh.Text != "🔄 Refresh" {
+		t.Errorf("Expected refresh button last, got %+v", refresh)
+	}
+
+	ocClient.providers.Providers[0].Models["claude-opus-4"] = opencode.Model{ID: "claude-opus-4"}
+	if err := handler.HandleModelCallback(ctx, 7, "mdl:refresh"); err != nil {
+		t.Fatalf("refresh callback failed: %v", err)
+	}
+	if ocClient.calls != 2 {
+		t.Errorf("Expected refresh to refetch providers, got %d calls", ocClient.calls)
+	}
+	if !strings.Contains(mockTG.editedMessages[7], "anthropic/claude-opus-4") {
+		t.Errorf("Expected refreshed list to include new model, got '%s'", mockTG.editedMessages[7])
+	}
+
+	handler.fetchedAt = handler.fetchedAt.Add(-modelCacheTTL)
+	handler.GetAvailableModels(ctx)
+	if ocClient.calls != 3 {
+		t.Errorf("Expected expired cache to refetch providers, got %d calls", ocClient.calls)
+	}
+}
+
+func TestModelFallbackNotCached(t *testing.T) {
+	ocClient := &mockModelOpenCodeClient{err: errors.New("unavailable")}
+	handler := NewModelHandler(&mockModelTelegramBot{}, &mockModelAppState{}, ocClient)
+
+	handler.GetAvailableModels(context.Background())
+	handler.GetAvailableModels(context.Background())
+	if ocClient.calls != 2 {
+		t.Errorf("Expected fallback list not to be cached, got %d calls", ocClient.calls)
+	}
+}
+
+func TestPromptsUseChatModel(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_123")
+	appState.SetChatModel("-100", "anthropic/claude-sonnet-4")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Hour)
+	bridge.chatID = "-100"
+
+	triggered := make(chan opencode.PromptOptions, 1)
+	mockOC.On("TriggerPrompt", "ses_123", "Hello", mock.Anything).
+		Run(func(args mock.Arguments) { triggered <- args.Get(2).(opencode.PromptOptions) }).Return(nil)
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	bridge.sendPromptAsync(context.Background(), "ses_123", "Hello", 1)
+
+	select {
+	case opts := <-triggered:
+		if opts.Model != "anthropic/claude-sonnet-4" || opts.Agent != "sisyphus" {
+			t.Errorf("Expected the chat's model and agent, got %+v", opts)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the prompt")
+	}
+}
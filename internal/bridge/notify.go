@@ -0,0 +1,162 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// NotificationPolicy controls which bot messages trigger push notifications
+type NotificationPolicy string
+
+const (
+	// NotifyAll keeps Telegram's default: every new bot message notifies
+	NotifyAll NotificationPolicy = "all"
+	// NotifyFinal sends the "Processing..." placeholder silently and delivers the
+	// final response (or error) as a fresh message, since edits never notify
+	NotifyFinal NotificationPolicy = "final"
+)
+
+// ParseNotificationPolicy parses a TELEGRAM_NOTIFY value
+func ParseNotificationPolicy(value string) (NotificationPolicy, bool) {
+	switch policy := NotificationPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case NotifyAll, NotifyFinal:
+		return policy, true
+	}
+	return NotifyAll, false
+}
+
+// SetNotificationPolicy sets the push notification policy (default NotifyAll)
+func (b *Bridge) SetNotificationPolicy(policy NotificationPolicy) {
+	b.notifyPolicy = policy
+}
+
+// SetFreshFinalMessage makes the bridge delete the "Processing..." placeholder
+// and send the answer as a new message replying to the user's message,
+// instead of editing the placeholder (edits neither notify nor thread replies)
+func (b *Bridge) SetFreshFinalMessage(enabled bool) {
+	b.freshMessage = enabled
+}
+
+// freshFinal reports whether the final response replaces the placeholder
+// with a new message instead of editing it
+func (b *Bridge) freshFinal() bool {
+	return b.freshMessage || b.notifyPolicy == NotifyFinal
+}
+
+// sendPlaceholder posts the thinking message, silently under NotifyFinal. It
+// is never queued in the outbox: replayed later, it would show a stale
+// "Processing…".
+func (b *Bridge) sendPlaceholder(ctx context.Context, text string) (int, error) {
+	ctx = telegram.WithoutOutbox(ctx)
+	if b.notifyPolicy == NotifyFinal {
+		return b.tgBot.SendMessageSilent(ctx, text)
+	}
+	return b.tgBot.SendMessage(ctx, text)
+}
+
+// deliverFinal puts the formatted response chunks in place of the thinking
+// message and returns the IDs of the Telegram messages showing them. The
+// error is the first send or edit that did not reach Telegram, so the caller
+// can report the response as undelivered.
+func (b *Bridge) deliverFinal(ctx context.Context, sessionID string, thinkingMsgID int, chunks []string) ([]int, error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	if b.showMore && len(chunks) > 1 {
+		return b.deliverPaged(ctx, thinkingMsgID, chunks)
+	}
+
+	// Response action buttons go on the last chunk
+	keyboard := b.responseActionsKeyboard()
+	firstKeyboard := keyboard
+	if len(chunks) > 1 {
+		firstKeyboard = nil
+	}
+
+	var msgIDs []int
+	var sendErr error
+	if b.freshFinal() {
+		if err := b.tgBot.DeleteMessage(ctx, thinkingMsgID); err != nil {
+			b.logger.Error("deliverFinal: delete placeholder failed", "session", sessionID, "error", err)
+		}
+		if msgID, err := b.sendFirstChunk(ctx, sessionID, chunks[0], firstKeyboard); !delivered(err) {
+			b.logger.Error("deliverFinal: send chunk failed", "session", sessionID, "chunk", 0, "error", err)
+			sendErr = fmt.Errorf("send chunk 0: %w", err)
+		} else {
+			msgIDs = append(msgIDs, msgID)
+		}
+	} else if firstKeyboard != nil {
+		if err := b.tgBot.EditMessageWithKeyboard(ctx, thinkingMsgID, chunks[0], firstKeyboard); !delivered(err) {
+			b.logger.Error("deliverFinal: edit failed", "session", sessionID, "error", err)
+			sendErr = fmt.Errorf("edit thinking message: %w", err)
+		} else {
+			msgIDs = append(msgIDs, thinkingMsgID)
+		}
+	} else if err := b.tgBot.EditMessage(ctx, thinkingMsgID, chunks[0]); !delivered(err) {
+		b.logger.Error("deliverFinal: edit failed", "session", sessionID, "error", err)
+		sendErr = fmt.Errorf("edit thinking message: %w", err)
+	} else {
+		msgIDs = append(msgIDs, thinkingMsgID)
+	}
+
+	rest := chunks[1:]
+
+	for i, chunk := range rest {
+		var msgID int
+		var err error
+		if i == len(rest)-1 {
+			msgID, err = b.sendChunk(ctx, chunk, keyboard)
+		} else {
+			msgID, err = b.tgBot.SendMessage(ctx, chunk)
+		}
+		if !delivered(err) {
+			b.logger.Error("deliverFinal: send chunk failed", "session", sessionID, "chunk", i+1, "error", err)
+			if sendErr == nil {
+				sendErr = fmt.Errorf("send chunk %d: %w", i+1, err)
+			}
+		} else {
+			msgIDs = append(msgIDs, msgID)
+		}
+	}
+	return msgIDs, sendErr
+}
+
+// delivered reports whether a send or edit reached Telegram, or was queued in
+// the outbox and will once the chat is reachable again: no fallback must be
+// sent for it
+func delivered(err error) bool {
+	return err == nil || errors.Is(err, telegram.ErrQueued)
+}
+
+// recordMessages remembers the OpenCode message the given Telegram messages
+// show, so replies and reactions to them can find it
+func (b *Bridge) recordMessages(sessionID, messageID string, msgIDs []int) {
+	if messageID == "" {
+		return
+	}
+	for _, msgID := range msgIDs {
+		if msgID != 0 {
+			b.state.RecordMessage(b.chatID, msgID, sessionID, messageID)
+		}
+	}
+}
+
+// sendFirstChunk sends the start of a fresh answer, threaded as a reply to
+// the user's message when it is known. A message carrying the response
+// action keyboard is sent without threading.
+func (b *Bridge) sendFirstChunk(ctx context.Context, sessionID string, chunk string, keyboard *models.InlineKeyboardMarkup) (int, error) {
+	if keyboard != nil {
+		return b.tgBot.SendMessageWithKeyboard(ctx, chunk, keyboard)
+	}
+	if val, ok := b.triggerMsgs.Load(sessionID); ok {
+		return b.tgBot.SendMessageReply(ctx, chunk, val.(int))
+	}
+	return b.tgBot.SendMessage(ctx, chunk)
+}
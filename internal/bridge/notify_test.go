@@ -0,0 +1,162 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestParseNotificationPolicy(t *testing.T) {
+	policy, ok := ParseNotificationPolicy(" Final ")
+	assert.True(t, ok)
+	assert.Equal(t, NotifyFinal, policy)
+
+	policy, ok = ParseNotificationPolicy("loud")
+	assert.False(t, ok)
+	assert.Equal(t, NotifyAll, policy)
+}
+
+func TestNotifyFinalSendsPlaceholderSilently(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_quiet")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetNotificationPolicy(NotifyFinal)
+
+	mockTG.On("SendMessageSilent", mock.Anything, "⏳ Processing...").Return(1, nil).Once()
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockOC.On("TriggerPrompt", "ses_quiet", "hi", mock.Anything).Return(nil)
+
+	assert.NoError(t, bridge.HandleUserMessage(context.Background(), "hi"))
+	assert.Eventually(t, func() bool {
+		_, ok := bridge.thinkingMsgs.Load("ses_quiet")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "SendMessage", mock.Anything, "⏳ Processing...")
+}
+
+func TestNotifyFinalDeliversFreshMessage(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetNotificationPolicy(NotifyFinal)
+	bridge.thinkingMsgs.Store("ses_quiet", 5)
+
+	mockTG.On("DeleteMessage", mock.Anything, 5).Return(nil).Once()
+	mockTG.On("SendMessage", mock.Anything, "All done").Return(6, nil).Once()
+
+	bridge.sendToTelegram("ses_quiet", "msg_1", "All done")
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "EditMessage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotifyAllEditsPlaceholder(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.thinkingMsgs.Store("ses_loud", 5)
+
+	mockTG.On("EditMessage", mock.Anything, 5, "All done").Return(nil).Once()
+
+	bridge.sendToTelegram("ses_loud", "msg_1", "All done")
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "DeleteMessage", mock.Anything, mock.Anything)
+}
+
+func TestFreshFinalMessageRepliesToTrigger(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetFreshFinalMessage(true)
+	bridge.rememberTrigger(telegram.WithMessageID(context.Background(), 41), "ses_reply")
+	bridge.thinkingMsgs.Store("ses_reply", 42)
+
+	mockTG.On("DeleteMessage", mock.Anything, 42).Return(nil).Once()
+	mockTG.On("SendMessageReply", mock.Anything, "Answer", 41).Return(43, nil).Once()
+
+	bridge.sendToTelegram("ses_reply", "msg_1", "Answer")
+
+	mockTG.AssertExpectations(t)
+	_, pending := bridge.triggerMsgs.Load("ses_reply")
+	assert.False(t, pending)
+}
+
+func TestReactionTargetsRecordedResponse(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	ctx := context.Background()
+	bridge.thinkingMsgs.Store("ses_old", 42)
+
+	mockTG.On("EditMessage", mock.Anything, 42, "Answer").Return(nil).Once()
+	bridge.sendToTelegram("ses_old", "msg_1", "Answer")
+
+	ref, ok := appState.LookupMessage("", 42)
+	assert.True(t, ok)
+	assert.Equal(t, "ses_old", ref.SessionID)
+	assert.Equal(t, "msg_1", ref.MessageID)
+
+	// The chat moved on to another session; the reaction still reaches the answer's
+	appState.SetSessionForChat("", "ses_new")
+	appState.SetChatAgent("", "plan")
+	mockOC.On("SendPrompt", "ses_old", "[User reacted with 👍 to your response msg_1]", mock.MatchedBy(func(opts opencode.PromptOptions) bool {
+		return opts.Agent == "plan"
+	})).Return(&opencode.SendPromptResponse{}, nil).Once()
+
+	err := bridge.HandleReaction(ctx, 42, 1, []models.ReactionType{{
+		Type:              models.ReactionTypeTypeEmoji,
+		ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: "👍"},
+	}})
+
+	assert.NoError(t, err)
+	mockOC.AssertExpectations(t)
+}
+
+func TestReactionsDisabled(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetReactions(false)
+	appState.SetSessionForChat("", "ses_1")
+
+	err := bridge.HandleReaction(context.Background(), 42, 1, []models.ReactionType{{
+		Type:              models.ReactionTypeTypeEmoji,
+		ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: "👍"},
+	}})
+
+	assert.NoError(t, err)
+	mockOC.AssertNotCalled(t, "SendPrompt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStreamingEditsDisabled(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetStreamingEdits(false)
+	bridge.thinkingMsgs.Store("ses_1", 42)
+
+	delta := "Hello\n\n"
+	part := &opencode.EventMessagePartUpdated{Type: "message.part.updated"}
+	part.Properties.Part = map[string]interface{}{"sessionID": "ses_1", "type": "text"}
+	part.Properties.Delta = &delta
+	bridge.handleMessagePartUpdated(opencode.Event{Type: "message.part.updated", Properties: part})
+
+	_, buffered := bridge.streamBuffers.Load("ses_1")
+	assert.False(t, buffered)
+	mockTG.AssertNotCalled(t, "EditMessage", mock.Anything, mock.Anything, mock.Anything)
+}
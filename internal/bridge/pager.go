@@ -0,0 +1,94 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// pagesPrefix prefixes registry keys and callback_data of paginated responses
+const pagesPrefix = "more"
+
+// SetShowMore sends long responses one page at a time: the first chunk gets a
+// "Show more" button that reveals the next one, instead of sending every chunk at once
+func (b *Bridge) SetShowMore(enabled bool) {
+	b.showMore = enabled
+}
+
+// deliverPaged sends the first page of a multi-chunk response with a
+// "Show more" button and returns the ID of its message, or the error that
+// kept it from Telegram. The pages are kept
+// in the ID registry, so they expire with its TTL. thinkingMsgID is 0 when
+// there is no placeholder to replace.
+func (b *Bridge) deliverPaged(ctx context.Context, thinkingMsgID int, chunks []string) ([]int, error) {
+	pagesKey := b.registry.Store(pagesPrefix, chunks)
+	keyboard := telegram.BuildShowMoreKeyboard(pagesKey, 1, len(chunks), b.lang())
+
+	if thinkingMsgID != 0 && !b.freshFinal() {
+		if err := b.tgBot.EditMessageWithKeyboard(ctx, thinkingMsgID, chunks[0], keyboard); !delivered(err) {
+			b.logger.Error("deliverPaged: edit failed", "error", err)
+			return nil, fmt.Errorf("edit thinking message: %w", err)
+		}
+		return []int{thinkingMsgID}, nil
+	}
+
+	if thinkingMsgID != 0 {
+		if err := b.tgBot.DeleteMessage(ctx, thinkingMsgID); err != nil {
+			b.logger.Error("deliverPaged: delete placeholder failed", "error", err)
+		}
+	}
+	msgID, err := b.tgBot.SendMessageWithKeyboard(ctx, chunks[0], keyboard)
+	if !delivered(err) {
+		b.logger.Error("deliverPaged: send first page failed", "error", err)
+		return nil, fmt.Errorf("send first page: %w", err)
+	}
+	return []int{msgID}, nil
+}
+
+// HandleShowMore reveals the next page of a paginated response.
+// data format: "more:{counter}:{page}", e.g. "more:3:1"
+func (b *Bridge) HandleShowMore(ctx context.Context, messageID int, data string) error {
+	idx := strings.LastIndex(data, ":")
+	page, err := strconv.Atoi(data[idx+1:])
+	if idx < 0 || err != nil {
+		return fmt.Errorf("invalid page callback: %s", data)
+	}
+	pagesKey := data[:idx]
+
+	value, ok := b.registry.Load(pagesKey)
+	if !ok {
+		_, err := b.tgBot.SendMessage(ctx, b.t("pages.expired"))
+		return err
+	}
+	pages, ok := value.([]string)
+	if raw, restored := value.(json.RawMessage); restored {
+		ok = json.Unmarshal(raw, &pages) == nil
+	}
+	if !ok {
+		return fmt.Errorf("invalid pages for %s", pagesKey)
+	}
+	if page < 1 || page >= len(pages) {
+		return fmt.Errorf("invalid page callback: %s", data)
+	}
+
+	// Drop the button from the page that was just expanded
+	if err := b.tgBot.EditMessage(ctx, messageID, pages[page-1]); err != nil {
+		b.logger.Warn("HandleShowMore: remove button failed", "error", err)
+	}
+
+	var msgID int
+	if page+1 < len(pages) {
+		keyboard := telegram.BuildShowMoreKeyboard(pagesKey, page+1, len(pages), b.lang())
+		msgID, err = b.tgBot.SendMessageWithKeyboard(ctx, pages[page], keyboard)
+	} else {
+		msgID, err = b.sendChunk(ctx, pages[page], b.responseActionsKeyboard())
+	}
+	if ref, ok := b.state.LookupMessage(b.chatID, messageID); ok && err == nil {
+		b.recordMessages(ref.SessionID, ref.MessageID, []int{msgID})
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package bridge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// showMoreCallback returns the callback_data of a "Show more" keyboard
+func showMoreCallback(keyboard *models.InlineKeyboardMarkup) string {
+	return keyboard.InlineKeyboard[0][0].CallbackData
+}
+
+func TestDeliverFinalPaginates(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	registry := state.NewIDRegistry()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), registry, 10*time.Millisecond)
+	bridge.SetShowMore(true)
+	ctx := context.Background()
+
+	var keyboard *models.InlineKeyboardMarkup
+	mockTG.On("EditMessageWithKeyboard", ctx, 5, "page 1", mock.Anything).Run(func(args mock.Arguments) {
+		keyboard = args.Get(3).(*models.InlineKeyboardMarkup)
+	}).Return(nil).Once()
+
+	bridge.deliverFinal(ctx, "ses_1", 5, []string{"page 1", "page 2", "page 3"})
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+	require.NotNil(t, keyboard)
+	assert.Equal(t, "▶️ Show more (2/3)", keyboard.InlineKeyboard[0][0].Text)
+
+	// Page 2 drops the button from page 1 and offers page 3
+	var next *models.InlineKeyboardMarkup
+	mockTG.On("EditMessage", ctx, 5, "page 1").Return(nil).Once()
+	mockTG.On("SendMessageWithKeyboard", ctx, "page 2", mock.Anything).Run(func(args mock.Arguments) {
+		next = args.Get(2).(*models.InlineKeyboardMarkup)
+	}).Return(6, nil).Once()
+
+	require.NoError(t, bridge.HandleShowMore(ctx, 5, showMoreCallback(keyboard)))
+	require.NotNil(t, next)
+	assert.Equal(t, "▶️ Show more (3/3)", next.InlineKeyboard[0][0].Text)
+
+	// The last page is sent without a button
+	mockTG.On("EditMessage", ctx, 6, "page 2").Return(nil).Once()
+	mockTG.On("SendMessage", ctx, "page 3").Return(7, nil).Once()
+
+	require.NoError(t, bridge.HandleShowMore(ctx, 6, showMoreCallback(next)))
+	mockTG.AssertExpectations(t)
+}
+
+func TestDeliverFinalSinglePageNotPaginated(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetShowMore(true)
+	ctx := context.Background()
+
+	mockTG.On("EditMessage", ctx, 5, "short").Return(nil).Once()
+
+	bridge.deliverFinal(ctx, "ses_1", 5, []string{"short"})
+
+	mockTG.AssertExpectations(t)
+	mockTG.AssertNotCalled(t, "EditMessageWithKeyboard", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleShowMoreExpired(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "⌛ This response has expired, the remaining pages are no longer available").Return(1, nil).Once()
+
+	require.NoError(t, bridge.HandleShowMore(ctx, 5, "more:99:1"))
+	mockTG.AssertExpectations(t)
+}
+
+func TestHandleShowMoreAfterRestart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	before := state.NewIDRegistry()
+	before.SetStore(state.NewAppState(stateFile))
+	pagesKey := before.Store(pagesPrefix, []string{"page 1", "page 2"})
+	before.Flush() // as when the bot shuts down
+
+	registry := state.NewIDRegistry()
+	registry.SetStore(state.NewAppState(stateFile))
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), registry, 10*time.Millisecond)
+	ctx := context.Background()
+
+	mockTG.On("EditMessage", ctx, 5, "page 1").Return(nil).Once()
+	mockTG.On("SendMessage", ctx, "page 2").Return(6, nil).Once()
+
+	require.NoError(t, bridge.HandleShowMore(ctx, 5, pagesKey+":1"))
+	mockTG.AssertExpectations(t)
+}
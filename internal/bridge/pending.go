@@ -0,0 +1,122 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// Permission and question prompts are kept in the state file as well as in
+// memory, so their keyboards keep working after a restart. Keys are the
+// callback keys from the ID registry: "p:…" for permissions, "q:…" for
+// questions.
+
+func (b *Bridge) storePermission(shortKey string, permState PermissionState) {
+	b.permissions.Store(shortKey, permState)
+	if err := b.state.SetPending(shortKey, permState); err != nil {
+		b.logger.Error("Failed to persist permission", "permission", permState.PermissionID, "session", permState.SessionID, "error", err)
+	}
+}
+
+func (b *Bridge) deletePermission(shortKey string) {
+	b.permissions.Delete(shortKey)
+	b.state.RemovePending(shortKey)
+}
+
+func (b *Bridge) storeQuestion(shortKey string, questionState *QuestionState) {
+	b.questions.Store(shortKey, questionState)
+	if err := b.state.SetPending(shortKey, questionState); err != nil {
+		b.logger.Error("Failed to persist question", "request", questionState.RequestID, "session", questionState.SessionID, "error", err)
+	}
+}
+
+func (b *Bridge) deleteQuestion(shortKey string) {
+	b.questions.Delete(shortKey)
+	b.state.RemovePending(shortKey)
+}
+
+// restorePending loads the prompts persisted before a restart and reserves
+// their callback keys in the registry
+func (b *Bridge) restorePending() {
+	for shortKey, data := range b.state.ListPending() {
+		switch {
+		case strings.HasPrefix(shortKey, "p:"):
+			var permState PermissionState
+			if err := json.Unmarshal(data, &permState); err != nil {
+				b.logger.Warn("Dropping unreadable permission", "key", shortKey, "error", err)
+				b.state.RemovePending(shortKey)
+				continue
+			}
+			b.permissions.Store(shortKey, permState)
+			b.registry.Restore(shortKey, permState.PermissionID)
+		case strings.HasPrefix(shortKey, "q:"):
+			var questionState QuestionState
+			if err := json.Unmarshal(data, &questionState); err != nil {
+				b.logger.Warn("Dropping unreadable question", "key", shortKey, "error", err)
+				b.state.RemovePending(shortKey)
+				continue
+			}
+			if questionState.SelectedOptions == nil {
+				questionState.SelectedOptions = make(map[int]bool)
+			}
+			b.questions.Store(shortKey, &questionState)
+			b.registry.Restore(shortKey, questionState.RequestID)
+		}
+	}
+}
+
+// closeStalePermissions drops tracked permission prompts missing from
+// pending and closes their keyboards. It returns how many it dropped.
+func (b *Bridge) closeStalePermissions(ctx context.Context, pending []opencode.PermissionRequest) int {
+	open := make(map[string]bool, len(pending))
+	for _, perm := range pending {
+		open[perm.ID] = true
+	}
+	closed := 0
+	b.permissions.Range(func(key, value interface{}) bool {
+		permState := value.(PermissionState)
+		if open[permState.PermissionID] {
+			return true
+		}
+		if _, ok := b.permissions.LoadAndDelete(key); !ok {
+			return true
+		}
+		closed++
+		b.state.RemovePending(key.(string))
+		editedMsg := b.t("permission.title") + "\n\n" + b.t("pending.closed")
+		if err := b.tgBot.EditMessage(ctx, permState.MessageID, editedMsg); err != nil {
+			b.logger.Warn("Failed to close permission prompt", "permission", permState.PermissionID, "session", permState.SessionID, "error", err)
+		}
+		return true
+	})
+	return closed
+}
+
+// closeStaleQuestions drops tracked question prompts missing from pending
+// and closes their keyboards. It returns how many it dropped.
+func (b *Bridge) closeStaleQuestions(ctx context.Context, pending []opencode.QuestionRequest) int {
+	open := make(map[string]bool, len(pending))
+	for _, q := range pending {
+		open[q.ID] = true
+	}
+	closed := 0
+	b.questions.Range(func(key, value interface{}) bool {
+		questionState := value.(*QuestionState)
+		if open[questionState.RequestID] {
+			return true
+		}
+		if _, ok := b.questions.LoadAndDelete(key); !ok {
+			return true
+		}
+		closed++
+		b.state.RemovePending(key.(string))
+		editedMsg := questionState.QuestionInfo.Question + "\n\n" + b.t("pending.closed")
+		if err := b.tgBot.EditMessage(ctx, questionState.MessageID, editedMsg); err != nil {
+			b.logger.Warn("Failed to close question prompt", "request", questionState.RequestID, "session", questionState.SessionID, "error", err)
+		}
+		return true
+	})
+	return closed
+}
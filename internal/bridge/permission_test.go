@@ -2,6 +2,9 @@ package bridge
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -74,6 +77,41 @@ func TestHandlePermissionCallback(t *testing.T) {
 	mockTG.AssertCalled(t, "EditMessage", ctx, 42, mock.Anything)
 }
 
+func TestPermissionSurvivesRestart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	ctx := context.Background()
+
+	before := NewBridge(mockOC, mockTG, state.NewAppState(stateFile), state.NewIDRegistry(), time.Second)
+	mockTG.On("SendMessageWithKeyboard", ctx, mock.Anything, mock.Anything).Return(1, nil)
+	before.handlePermissionAsked(opencode.Event{
+		Type: "permission.asked",
+		Properties: &opencode.EventPermissionAsked{Properties: opencode.PermissionRequest{
+			ID: "perm_123", SessionID: "ses_123", Permission: "bash",
+		}},
+	})
+	var shortKey string
+	before.permissions.Range(func(key, _ interface{}) bool {
+		shortKey = key.(string)
+		return false
+	})
+
+	// A new process starts from the state file with an empty registry
+	registry := state.NewIDRegistry()
+	after := NewBridge(mockOC, mockTG, state.NewAppState(stateFile), registry, time.Second)
+	fullID, ok := registry.Lookup(shortKey)
+	assert.True(t, ok)
+	assert.Equal(t, "perm_123", fullID)
+
+	mockOC.On("ReplyPermission", "ses_123", "perm_123", opencode.PermissionOnce).Return(nil)
+	mockTG.On("EditMessage", ctx, mock.Anything, mock.Anything).Return(nil)
+	assert.NoError(t, after.HandlePermissionCallback(ctx, shortKey, "once"))
+	mockOC.AssertCalled(t, "ReplyPermission", "ses_123", "perm_123", opencode.PermissionOnce)
+
+	assert.Empty(t, state.NewAppState(stateFile).ListPending(), "an answered prompt must not be restored again")
+}
+
 func TestPermissionMessageContainsPermissionType(t *testing.T) {
 	mockOC := new(MockOpenCodeClient)
 	mockTG := NewMockTelegramBot()
@@ -218,3 +256,68 @@ func TestPermissionKeyboardRemovedAfterResponse(t *testing.T) {
 	assert.NoError(t, err)
 	mockTG.AssertCalled(t, "EditMessage", ctx, 99, mock.Anything)
 }
+
+func TestPermissionRepliedElsewhereClosesPrompt(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	registry := state.NewIDRegistry()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), registry, 100*time.Millisecond)
+
+	shortKey := registry.Register("perm_tui", "p", "")
+	bridge.permissions.Store(shortKey, PermissionState{PermissionID: "perm_tui", SessionID: "ses_1", MessageID: 7})
+	mockTG.On("EditMessage", mock.Anything, 7, mock.MatchedBy(func(text string) bool {
+		return strings.Contains(text, "Allowed (always)") && strings.Contains(text, "Answered outside Telegram")
+	})).Return(nil)
+
+	replied := &opencode.EventPermissionReplied{}
+	replied.Properties.SessionID = "ses_1"
+	replied.Properties.RequestID = "perm_tui"
+	replied.Properties.Reply = opencode.PermissionAlways
+	bridge.handlePermissionReplied(opencode.Event{Type: "permission.replied", Properties: replied})
+
+	mockTG.AssertNumberOfCalls(t, "EditMessage", 1)
+	_, ok := bridge.permissions.Load(shortKey)
+	assert.False(t, ok)
+
+	// Replies to prompts answered here, or unknown ones, are ignored
+	replied.Properties.RequestID = "perm_other"
+	bridge.handlePermissionReplied(opencode.Event{Type: "permission.replied", Properties: replied})
+	mockTG.AssertNumberOfCalls(t, "EditMessage", 1)
+}
+
+func TestPermissionCallbackKeepsPromptOnFailure(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	registry := state.NewIDRegistry()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), registry, 100*time.Millisecond)
+
+	shortKey := registry.Register("perm_fail", "p", "")
+	bridge.permissions.Store(shortKey, PermissionState{PermissionID: "perm_fail", SessionID: "ses_1", MessageID: 8})
+	mockOC.On("ReplyPermission", "ses_1", "perm_fail", opencode.PermissionOnce).Return(errors.New("unreachable"))
+
+	err := bridge.HandlePermissionCallback(context.Background(), shortKey, "once")
+
+	assert.Error(t, err)
+	_, ok := bridge.permissions.Load(shortKey)
+	assert.True(t, ok, "prompt should stay answerable after a failed reply")
+}
+
+func TestFailedEventCountedPerAccount(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetAccount("work")
+	labels := map[string]string{"account": "work", "event_type": "permission.asked"}
+	before := counter(t, "event_errors_total", labels)
+
+	mockTG.On("SendMessageWithKeyboard", context.Background(), mock.Anything, mock.Anything).Return(0, errors.New("network down"))
+	bridge.HandleSSEEvent(opencode.Event{
+		Type: "permission.asked",
+		Properties: &opencode.EventPermissionAsked{
+			Type:       "permission.asked",
+			Properties: opencode.PermissionRequest{ID: "perm_1", SessionID: "ses_1", Permission: "bash"},
+		},
+	})
+
+	assert.Equal(t, float64(1), counter(t, "event_errors_total", labels)-before)
+	assert.Zero(t, counter(t, "event_errors_total", map[string]string{"account": "other", "event_type": "permission.asked"}))
+}
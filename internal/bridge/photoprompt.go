@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"context"
+	"html"
+	"strings"
+)
+
+// SetPhotoPrompt sets the default instruction sent alongside photos that
+// arrive without a caption, e.g. "Describe this screenshot and identify errors".
+// Chats can override it with /photoprompt.
+func (b *Bridge) SetPhotoPrompt(prompt string) {
+	b.state.SetDefaultPhotoPrompt(prompt)
+}
+
+// photoCaption returns the text part for a photo prompt: the caption, or the
+// chat's photo prompt when there is none
+func (b *Bridge) photoCaption(caption string) string {
+	if caption = strings.TrimSpace(caption); caption != "" {
+		return caption
+	}
+	return b.state.GetPhotoPromptForChat(b.chatID)
+}
+
+// HandlePhotoPromptCommand handles /photoprompt [text|off|reset]
+// Without args: shows the prompt used for caption-less photos in this chat.
+// "off" sends such photos alone, "reset" goes back to the configured default.
+func (b *Bridge) HandlePhotoPromptCommand(ctx context.Context, args string) error {
+	var msg string
+	switch args = strings.TrimSpace(args); args {
+	case "":
+		if prompt := b.state.GetPhotoPromptForChat(b.chatID); prompt != "" {
+			msg = b.t("photoprompt.current", html.EscapeString(prompt))
+		} else {
+			msg = b.t("photoprompt.none")
+		}
+	case "off":
+		b.state.SetChatPhotoPrompt(b.chatID, "")
+		msg = b.t("photoprompt.off")
+	case "reset":
+		b.state.ResetChatPhotoPrompt(b.chatID)
+		msg = b.t("photoprompt.reset")
+	default:
+		b.state.SetChatPhotoPrompt(b.chatID, args)
+		msg = b.t("photoprompt.set", html.EscapeString(args))
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, msg)
+	return err
+}
@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// sentPhotoParts sends a photo with caption and returns the parts passed to OpenCode
+func sentPhotoParts(t *testing.T, b *Bridge, mockOC *MockOpenCodeClient, caption string) []interface{} {
+	t.Helper()
+	sent := make(chan []interface{}, 1)
+	mockOC.On("SendPromptWithParts", "ses_photo", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sent <- args.Get(1).([]interface{}) }).
+		Return(&opencode.SendPromptResponse{}, nil).Once()
+
+	photos := []models.PhotoSize{{FileID: "p1", Width: 800, Height: 600}}
+	require.NoError(t, b.HandlePhotoMessage(context.Background(), photos, caption, "token"))
+
+	select {
+	case parts := <-sent:
+		b.state.SetSessionStatus("ses_photo", state.SessionIdle)
+		return parts
+	case <-time.After(3 * time.Second):
+		t.Fatal("photo prompt was not sent")
+		return nil
+	}
+}
+
+func TestPhotoPromptForCaptionlessPhotos(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_photo")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.downloadFile = func(ctx context.Context, botToken, fileID string) ([]byte, error) {
+		return []byte(fileID), nil
+	}
+	mockTG.On("SendMessage", mock.Anything, mock.Anything).Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	// No default: the photo is sent alone
+	assert.Len(t, sentPhotoParts(t, bridge, mockOC, ""), 1)
+
+	bridge.SetPhotoPrompt("Describe this screenshot and identify errors")
+	parts := sentPhotoParts(t, bridge, mockOC, "")
+	assert.Equal(t, opencode.TextPartInput{Type: "text", Text: "Describe this screenshot and identify errors"}, parts[1])
+
+	// A caption always wins over the prompt
+	parts = sentPhotoParts(t, bridge, mockOC, "What font is this?")
+	assert.Equal(t, opencode.TextPartInput{Type: "text", Text: "What font is this?"}, parts[1])
+}
+
+func TestPhotoPromptCommand(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	bridge.SetPhotoPrompt("Describe this")
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+
+	require.NoError(t, bridge.HandlePhotoPromptCommand(ctx, "Find the <bug>"))
+	assert.Equal(t, "Find the <bug>", bridge.photoCaption(""))
+	mockTG.AssertCalled(t, "SendMessage", ctx, "✅ Photos without a caption will be sent with:\nFind the &lt;bug&gt;")
+
+	require.NoError(t, bridge.HandlePhotoPromptCommand(ctx, "off"))
+	assert.Equal(t, "", bridge.photoCaption(""))
+
+	require.NoError(t, bridge.HandlePhotoPromptCommand(ctx, "reset"))
+	assert.Equal(t, "Describe this", bridge.photoCaption(""))
+
+	require.NoError(t, bridge.HandlePhotoPromptCommand(ctx, ""))
+	mockTG.AssertCalled(t, "SendMessage", ctx, "🖼️ Photos without a caption are sent with:\nDescribe this\n\nUsage: /photoprompt &lt;text&gt; | off | reset")
+}
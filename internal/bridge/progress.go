@@ -0,0 +1,221 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// progressUpdateInterval is how often the thinking message is refreshed while busy.
+// Kept well above Telegram's per-chat edit limit.
+const progressUpdateInterval = 5 * time.Second
+
+// progressMinEditGap is how soon after the last refresh a newly started
+// tool is shown, ahead of the next tick
+const progressMinEditGap = 2 * time.Second
+
+// maxToolTitleRunes caps the tool title shown next to the tool name
+const maxToolTitleRunes = 60
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// ProgressTracker records what a busy session is doing, derived from part events
+type ProgressTracker struct {
+	lang        i18n.Lang
+	label       string
+	started     time.Time
+	currentTool string
+	toolTitle   string // what the running tool does, e.g. its command
+	steps       int
+	toolCalls   int
+	changed     chan struct{}   // a new tool started
+	askedBy     context.Context // the prompt's update, credited with its shell commands in the audit log
+	mu          sync.Mutex
+}
+
+// startProgress registers a tracker for a session and refreshes the thinking message
+// with elapsed time, step count and the running tool until the session leaves busy state
+// or the response starts streaming into the same message.
+func (b *Bridge) startProgress(ctx context.Context, sessionID string, thinkingMsgID int, label string) {
+	tracker := &ProgressTracker{
+		lang:    b.lang(),
+		label:   label,
+		started: time.Now(),
+		changed: make(chan struct{}, 1),
+		askedBy: ctx,
+	}
+	b.progress.Store(sessionID, tracker)
+
+	go func() {
+		ticker := time.NewTicker(progressUpdateInterval)
+		defer ticker.Stop()
+		frame := 0
+		var lastEdit time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tracker.changed:
+				if time.Since(lastEdit) < progressMinEditGap {
+					continue
+				}
+			case <-ticker.C:
+			}
+
+			if b.state.GetSessionStatus(sessionID) != state.SessionBusy {
+				return
+			}
+			current, ok := b.progress.Load(sessionID)
+			if !ok || current.(*ProgressTracker) != tracker {
+				return
+			}
+			// Streamed text owns the thinking message once it starts
+			if _, streaming := b.streamBuffers.Load(sessionID); streaming {
+				return
+			}
+
+			frame++
+			lastEdit = time.Now()
+			// Progress is stale by the time an outbox replay would show it
+			_ = b.tgBot.EditMessagePlain(telegram.WithoutOutbox(context.Background()), thinkingMsgID, tracker.render(frame))
+		}
+	}()
+}
+
+// trackPartProgress updates the session's progress tracker from a message part
+func (b *Bridge) trackPartProgress(part map[string]interface{}) {
+	sessionID, _ := part["sessionID"].(string)
+	if sessionID == "" {
+		return
+	}
+
+	val, ok := b.progress.Load(sessionID)
+	if !ok {
+		return
+	}
+	tracker := val.(*ProgressTracker)
+
+	partType, _ := part["type"].(string)
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	switch partType {
+	case "step-start":
+		tracker.steps++
+	case "tool":
+		toolName, _ := part["tool"].(string)
+		status := ""
+		st, _ := part["state"].(map[string]interface{})
+		if st != nil {
+			status, _ = st["status"].(string)
+		}
+		switch status {
+		case "pending", "running":
+			title := toolTitle(st)
+			if tracker.currentTool != toolName {
+				tracker.toolCalls++
+			} else if title == tracker.toolTitle {
+				return
+			}
+			tracker.currentTool = toolName
+			tracker.toolTitle = title
+			select {
+			case tracker.changed <- struct{}{}:
+			default:
+			}
+		case "completed", "error":
+			if toolName == "bash" {
+				input, _ := st["input"].(map[string]interface{})
+				command, _ := input["command"].(string)
+				b.audit.record(tracker.askedBy, b.chatID, state.AuditShell,
+					fmt.Sprintf("%s: %s in %s", status, command, sessionID))
+			}
+			if tracker.currentTool == toolName {
+				tracker.currentTool = ""
+				tracker.toolTitle = ""
+			}
+		}
+	}
+}
+
+// toolTitle describes what a tool call does: the title OpenCode gives it,
+// or else its most telling input (the command, file or pattern)
+func toolTitle(toolState map[string]interface{}) string {
+	title, _ := toolState["title"].(string)
+	if title == "" {
+		input, _ := toolState["input"].(map[string]interface{})
+		for _, key := range []string{"description", "command", "filePath", "pattern", "url"} {
+			if title, _ = input[key].(string); title != "" {
+				break
+			}
+		}
+	}
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > maxToolTitleRunes {
+		title = string(runes[:maxToolTitleRunes-1]) + "…"
+	}
+	return title
+}
+
+// setProgressLabel replaces the headline of the session's thinking message
+func (b *Bridge) setProgressLabel(sessionID, label string) {
+	if val, ok := b.progress.Load(sessionID); ok {
+		tracker := val.(*ProgressTracker)
+		tracker.mu.Lock()
+		tracker.label = label
+		tracker.mu.Unlock()
+	}
+}
+
+// stopProgress removes the session's progress tracker
+func (b *Bridge) stopProgress(sessionID string) {
+	b.progress.Delete(sessionID)
+}
+
+func (t *ProgressTracker) render(frame int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return formatProgress(t.lang, t.label, time.Since(t.started), t.currentTool, t.toolTitle, t.steps, t.toolCalls, frame)
+}
+
+// formatProgress renders the thinking message body
+// Example:
+//
+//	⏳ Processing... ⠹ 1:05
+//	🔧 Running bash: npm test…
+//	▰▰▰▱▱ step 3 · 4 tool calls
+func formatProgress(lang i18n.Lang, label string, elapsed time.Duration, tool, title string, steps, toolCalls, frame int) string {
+	spinner := spinnerFrames[frame%len(spinnerFrames)]
+	secs := int(elapsed.Seconds())
+
+	lines := []string{fmt.Sprintf("%s %s %d:%02d", label, spinner, secs/60, secs%60)}
+
+	if tool != "" && title != "" {
+		lines = append(lines, i18n.T(lang, "progress.running_title", tool, title))
+	} else if tool != "" {
+		lines = append(lines, i18n.T(lang, "progress.running", tool))
+	}
+
+	if steps > 0 {
+		// Step count is unbounded, so the bar fills per 5 steps and wraps
+		const barWidth = 5
+		filled := (steps-1)%barWidth + 1
+		bar := strings.Repeat("▰", filled) + strings.Repeat("▱", barWidth-filled)
+		line := bar + " " + i18n.T(lang, "progress.step", steps)
+		if toolCalls == 1 {
+			line += " · " + i18n.T(lang, "progress.tool_call")
+		} else if toolCalls > 1 {
+			line += " · " + i18n.T(lang, "progress.tool_calls", toolCalls)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
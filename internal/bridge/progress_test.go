@@ -0,0 +1,98 @@
+package bridge
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestFormatProgressElapsedOnly(t *testing.T) {
+	text := formatProgress(i18n.English, "⏳ Processing...", 65*time.Second, "", "", 0, 0, 0)
+
+	assert.Equal(t, "⏳ Processing... ⠋ 1:05", text)
+}
+
+func TestFormatProgressWithToolAndSteps(t *testing.T) {
+	text := formatProgress(i18n.English, "⏳ Processing...", 3*time.Second, "bash", "", 3, 4, 2)
+
+	lines := strings.Split(text, "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "⏳ Processing... ⠹ 0:03", lines[0])
+	assert.Equal(t, "🔧 Running: bash", lines[1])
+	assert.Equal(t, "▰▰▰▱▱ step 3 · 4 tool calls", lines[2])
+}
+
+func TestFormatProgressBarWraps(t *testing.T) {
+	text := formatProgress(i18n.English, "⏳", 0, "", "", 6, 1, 0)
+
+	assert.Contains(t, text, "▰▱▱▱▱ step 6 · 1 tool call")
+}
+
+func TestFormatProgressWithToolTitle(t *testing.T) {
+	text := formatProgress(i18n.English, "⏳", 0, "bash", "npm test", 1, 1, 0)
+
+	assert.Contains(t, text, "🔧 Running bash: npm test…")
+}
+
+func TestToolTitle(t *testing.T) {
+	assert.Equal(t, "Run tests", toolTitle(map[string]interface{}{
+		"title": "Run tests",
+		"input": map[string]interface{}{"command": "npm test"},
+	}))
+	assert.Equal(t, "npm test -- --watch=false", toolTitle(map[string]interface{}{
+		"input": map[string]interface{}{"command": "npm test\n  -- --watch=false"},
+	}))
+	assert.Equal(t, "", toolTitle(nil))
+
+	long := toolTitle(map[string]interface{}{"title": strings.Repeat("é", 100)})
+	assert.Equal(t, maxToolTitleRunes, len([]rune(long)))
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
+
+func TestTrackPartProgress(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	tracker := &ProgressTracker{label: "⏳", started: time.Now()}
+	bridge.progress.Store("ses_1", tracker)
+
+	bridge.trackPartProgress(map[string]interface{}{"sessionID": "ses_1", "type": "step-start"})
+	bridge.trackPartProgress(map[string]interface{}{
+		"sessionID": "ses_1",
+		"type":      "tool",
+		"tool":      "read",
+		"state":     map[string]interface{}{"status": "running"},
+	})
+
+	assert.Equal(t, 1, tracker.steps)
+	assert.Equal(t, "read", tracker.currentTool)
+	assert.Equal(t, 1, tracker.toolCalls)
+
+	bridge.trackPartProgress(map[string]interface{}{
+		"sessionID": "ses_1",
+		"type":      "tool",
+		"tool":      "read",
+		"state":     map[string]interface{}{"status": "completed"},
+	})
+
+	assert.Equal(t, "", tracker.currentTool)
+
+	// A new tool wakes the refresh loop with its title
+	tracker.changed = make(chan struct{}, 1)
+	bridge.trackPartProgress(map[string]interface{}{
+		"sessionID": "ses_1",
+		"type":      "tool",
+		"tool":      "bash",
+		"state":     map[string]interface{}{"status": "running", "input": map[string]interface{}{"command": "go test ./..."}},
+	})
+
+	assert.Equal(t, "go test ./...", tracker.toolTitle)
+	assert.Len(t, tracker.changed, 1)
+
+	bridge.stopProgress("ses_1")
+	_, ok := bridge.progress.Load("ses_1")
+	assert.False(t, ok)
+}
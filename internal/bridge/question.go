@@ -3,8 +3,10 @@ package bridge
 import (
 	"context"
 	"fmt"
+	"html"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/user/opencode-telegram/internal/opencode"
 	"github.com/user/opencode-telegram/internal/telegram"
@@ -14,11 +16,10 @@ func (b *Bridge) handleQuestionAsked(event opencode.EventQuestionAsked) error {
 	ctx := context.Background()
 	props := event.Properties
 
-	fmt.Printf("[QUESTION] Received question.asked event, requestID=%s, sessionID=%s, questions=%d\n",
-		props.ID, props.SessionID, len(props.Questions))
+	b.logger.Debug("Received question.asked event", "request", props.ID, "session", props.SessionID, "questions", len(props.Questions))
 
 	var msgBuilder strings.Builder
-	msgBuilder.WriteString("🤔 OpenCode has questions:\n\n")
+	msgBuilder.WriteString(b.t("question.header"))
 
 	for i, q := range props.Questions {
 		msgBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, q.Question))
@@ -29,18 +30,21 @@ func (b *Bridge) handleQuestionAsked(event opencode.EventQuestionAsked) error {
 		}
 		custom := q.Custom != nil && *q.Custom
 		if custom {
-			msgBuilder.WriteString("  • ✏️ Custom answer allowed\n")
+			msgBuilder.WriteString(b.t("question.custom_allowed"))
 		}
 		msgBuilder.WriteString("\n")
 	}
 
-	msgBuilder.WriteString("Please answer the first question:")
+	msgBuilder.WriteString(b.t("question.answer_first"))
+	// Typed text still goes to a question waiting for a custom answer
+	if _, waiting := b.waitingCustomQuestion(); waiting != nil {
+		msgBuilder.WriteString(b.t("question.custom_pending", waiting.QuestionInfo.Question))
+	}
 
 	firstQ := props.Questions[0]
 	shortKey := b.registry.Register(props.ID, "q", fmt.Sprintf("%d", 0))
 
-	fmt.Printf("[QUESTION] First question: %s, options=%d, shortKey=%s\n",
-		firstQ.Question, len(firstQ.Options), shortKey)
+	b.logger.Debug("First question", "session", props.SessionID, "question", firstQ.Question, "options", len(firstQ.Options), "key", shortKey)
 
 	multiple := firstQ.Multiple != nil && *firstQ.Multiple
 	custom := firstQ.Custom != nil && *firstQ.Custom
@@ -58,15 +62,15 @@ func (b *Bridge) handleQuestionAsked(event opencode.EventQuestionAsked) error {
 		}
 	}
 
-	keyboard := telegram.BuildQuestionKeyboard(tgQuestion, shortKey)
+	keyboard := telegram.BuildQuestionKeyboard(tgQuestion, shortKey, b.lang())
 
 	messageID, err := b.tgBot.SendMessageWithKeyboard(ctx, msgBuilder.String(), keyboard)
 	if err != nil {
-		fmt.Printf("[QUESTION] Error sending question keyboard: %v\n", err)
+		b.logger.Error("Error sending question keyboard", "session", props.SessionID, "error", err)
 		return fmt.Errorf("failed to send question: %w", err)
 	}
 
-	fmt.Printf("[QUESTION] Question sent successfully, messageID=%d\n", messageID)
+	b.logger.Debug("Question sent", "session", props.SessionID, "telegram_message", messageID)
 
 	state := &QuestionState{
 		RequestID:       props.ID,
@@ -77,13 +81,13 @@ func (b *Bridge) handleQuestionAsked(event opencode.EventQuestionAsked) error {
 		SelectedOptions: make(map[int]bool),
 		WaitingCustom:   false,
 	}
-	b.questions.Store(shortKey, state)
+	b.storeQuestion(shortKey, state)
 
 	return nil
 }
 
 func (b *Bridge) HandleQuestionCallback(ctx context.Context, shortKey, action string) error {
-	fmt.Printf("[QUESTION] HandleQuestionCallback called with shortKey=%s, action=%s\n", shortKey, action)
+	b.logger.Debug("HandleQuestionCallback", "key", shortKey, "action", action)
 
 	// Debug: list all stored keys
 	var storedKeys []string
@@ -91,7 +95,7 @@ func (b *Bridge) HandleQuestionCallback(ctx context.Context, shortKey, action st
 		storedKeys = append(storedKeys, key.(string))
 		return true
 	})
-	fmt.Printf("[QUESTION] Currently stored keys: %v\n", storedKeys)
+	b.logger.Debug("Currently stored question keys", "keys", storedKeys)
 
 	val, ok := b.questions.Load(shortKey)
 	if !ok {
@@ -101,9 +105,10 @@ func (b *Bridge) HandleQuestionCallback(ctx context.Context, shortKey, action st
 
 	if action == "custom" {
 		state.WaitingCustom = true
-		b.questions.Store(shortKey, state)
+		state.CustomSince = time.Now()
+		b.storeQuestion(shortKey, state)
 		return b.tgBot.EditMessage(ctx, state.MessageID,
-			fmt.Sprintf("%s\n\n✏️ Please type your custom answer:", state.QuestionInfo.Question))
+			b.t("question.type_custom", state.QuestionInfo.Question))
 	}
 
 	if action == "submit" {
@@ -122,50 +127,73 @@ func (b *Bridge) HandleQuestionCallback(ctx context.Context, shortKey, action st
 	multiple := state.QuestionInfo.Multiple != nil && *state.QuestionInfo.Multiple
 	if !multiple {
 		state.SelectedOptions = map[int]bool{optionIdx: true}
-		b.questions.Store(shortKey, state)
+		b.storeQuestion(shortKey, state)
 		return b.submitQuestionAnswer(ctx, shortKey, state)
 	}
 
 	state.SelectedOptions[optionIdx] = !state.SelectedOptions[optionIdx]
-	b.questions.Store(shortKey, state)
+	b.storeQuestion(shortKey, state)
 
 	return nil
 }
 
-func (b *Bridge) HandleQuestionCustomInput(ctx context.Context, text string) bool {
+// waitingCustomQuestion returns the question that asked for custom input
+// last, which the next text message answers
+func (b *Bridge) waitingCustomQuestion() (string, *QuestionState) {
 	var foundShortKey string
 	var foundState *QuestionState
 
 	b.questions.Range(func(key, value interface{}) bool {
 		state := value.(*QuestionState)
-		if state.WaitingCustom {
+		if state.WaitingCustom && (foundState == nil || state.CustomSince.After(foundState.CustomSince)) {
 			foundShortKey = key.(string)
 			foundState = state
-			return false
 		}
 		return true
 	})
+	return foundShortKey, foundState
+}
 
+func (b *Bridge) HandleQuestionCustomInput(ctx context.Context, text string) bool {
+	foundShortKey, foundState := b.waitingCustomQuestion()
 	if foundState == nil {
 		return false
 	}
+	b.submitCustomAnswer(ctx, foundShortKey, foundState, text)
+	return true
+}
 
-	foundState.SelectedOptions = map[int]bool{-1: true}
-	b.questions.Store(foundShortKey, foundState)
+// submitCustomAnswer replies to a question with typed text. The text is kept
+// as a draft until OpenCode accepts it, so neither a failed reply nor a
+// restart loses it.
+func (b *Bridge) submitCustomAnswer(ctx context.Context, shortKey string, state *QuestionState, text string) {
+	state.SelectedOptions = map[int]bool{-1: true}
+	state.Draft = text
+	b.storeQuestion(shortKey, state)
 
 	answers := []opencode.QuestionAnswer{{text}}
 
-	if err := b.ocClient.ReplyQuestion(foundState.RequestID, answers); err != nil {
-		b.tgBot.SendMessage(ctx, fmt.Sprintf("❌ Failed to submit answer: %v", err))
-		return true
+	b.deleteQuestion(shortKey)
+	if err := b.ocClient.ReplyQuestion(state.RequestID, answers); err != nil {
+		b.storeQuestion(shortKey, state)
+		b.tgBot.SendMessage(ctx, b.t("question.submit_failed", err, html.EscapeString(text)))
+		return
 	}
 
-	b.tgBot.EditMessage(ctx, foundState.MessageID,
-		fmt.Sprintf("%s\n\n✅ Answer submitted: %s", foundState.QuestionInfo.Question, text))
-
-	b.questions.Delete(foundShortKey)
+	b.tgBot.EditMessage(ctx, state.MessageID,
+		b.t("question.submitted", state.QuestionInfo.Question, text))
+}
 
-	return true
+// resubmitDrafts retries the custom answers whose reply was cut short, e.g.
+// by a restart; only questions still open should be tracked by now
+func (b *Bridge) resubmitDrafts(ctx context.Context) {
+	b.questions.Range(func(key, value interface{}) bool {
+		if state := value.(*QuestionState); state.Draft != "" {
+			b.logger.Info("Resubmitting the draft answer", "request", state.RequestID, "session", state.SessionID)
+			b.submitCustomAnswer(ctx, key.(string), state, state.Draft)
+		}
+		return true
+	})
 }
 
 func (b *Bridge) submitQuestionAnswer(ctx context.Context, shortKey string, state *QuestionState) error {
@@ -182,15 +210,71 @@ func (b *Bridge) submitQuestionAnswer(ctx context.Context, shortKey string, stat
 
 	answers := []opencode.QuestionAnswer{values}
 
+	// Forget the question before replying, so the question.replied event
+	// for this answer is not taken for an answer from elsewhere
+	b.deleteQuestion(shortKey)
 	if err := b.ocClient.ReplyQuestion(state.RequestID, answers); err != nil {
+		b.storeQuestion(shortKey, state)
 		return fmt.Errorf("failed to submit answer: %w", err)
 	}
 
 	answerText := strings.Join(values, ", ")
 	b.tgBot.EditMessage(ctx, state.MessageID,
-		fmt.Sprintf("%s\n\n✅ Answer submitted: %s", state.QuestionInfo.Question, answerText))
-
-	b.questions.Delete(shortKey)
+		b.t("question.submitted", state.QuestionInfo.Question, answerText))
 
 	return nil
 }
+
+// takeQuestion removes and returns the pending question of a request
+func (b *Bridge) takeQuestion(requestID string) *QuestionState {
+	var found *QuestionState
+	b.questions.Range(func(key, value interface{}) bool {
+		if state := value.(*QuestionState); state.RequestID == requestID {
+			if _, ok := b.questions.LoadAndDelete(key); ok {
+				b.state.RemovePending(key.(string))
+				found = state
+			}
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// handleQuestionReplied closes a question answered outside Telegram, e.g.
+// in the TUI, so its keyboard does not linger
+func (b *Bridge) handleQuestionReplied(event opencode.Event) {
+	replied, ok := event.Properties.(*opencode.EventQuestionReplied)
+	if !ok {
+		return
+	}
+	state := b.takeQuestion(replied.Properties.RequestID)
+	if state == nil {
+		return
+	}
+
+	var answerText string
+	if len(replied.Properties.Answers) > 0 {
+		answerText = strings.Join(replied.Properties.Answers[0], ", ")
+	}
+	text := b.t("question.submitted", state.QuestionInfo.Question, answerText) + "\n" + b.t("question.answered_elsewhere")
+	if err := b.tgBot.EditMessage(context.Background(), state.MessageID, text); err != nil {
+		b.logger.Warn("Failed to close question", "request", state.RequestID, "session", state.SessionID, "error", err)
+	}
+}
+
+// handleQuestionRejected closes a question dismissed outside Telegram
+func (b *Bridge) handleQuestionRejected(event opencode.Event) {
+	rejected, ok := event.Properties.(*opencode.EventQuestionRejected)
+	if !ok {
+		return
+	}
+	state := b.takeQuestion(rejected.Properties.RequestID)
+	if state == nil {
+		return
+	}
+
+	if err := b.tgBot.EditMessage(context.Background(), state.MessageID, b.t("question.dismissed", state.QuestionInfo.Question)); err != nil {
+		b.logger.Warn("Failed to close question", "request", state.RequestID, "session", state.SessionID, "error", err)
+	}
+}
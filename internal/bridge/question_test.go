@@ -0,0 +1,130 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestQuestionRepliedElsewhereClosesQuestion(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+
+	bridge.questions.Store("q1", &QuestionState{
+		RequestID:    "que_1",
+		MessageID:    5,
+		QuestionInfo: opencode.QuestionInfo{Question: "Which database?"},
+	})
+	mockTG.On("EditMessage", mock.Anything, 5,
+		"Which database?\n\n✅ Answer submitted: Postgres\n↪️ Answered outside Telegram").Return(nil)
+
+	replied := &opencode.EventQuestionReplied{}
+	replied.Properties.RequestID = "que_1"
+	replied.Properties.Answers = []opencode.QuestionAnswer{{"Postgres"}}
+	bridge.handleQuestionReplied(opencode.Event{Type: "question.replied", Properties: replied})
+
+	mockTG.AssertExpectations(t)
+	_, ok := bridge.questions.Load("q1")
+	assert.False(t, ok)
+}
+
+func TestQuestionRejectedElsewhereClosesQuestion(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+
+	bridge.questions.Store("q1", &QuestionState{
+		RequestID:    "que_1",
+		MessageID:    5,
+		QuestionInfo: opencode.QuestionInfo{Question: "Which database?"},
+	})
+	mockTG.On("EditMessage", mock.Anything, 5, "Which database?\n\n🚫 Dismissed outside Telegram").Return(nil)
+
+	rejected := &opencode.EventQuestionRejected{}
+	rejected.Properties.RequestID = "que_1"
+	bridge.handleQuestionRejected(opencode.Event{Type: "question.rejected", Properties: rejected})
+
+	mockTG.AssertExpectations(t)
+	_, ok := bridge.questions.Load("q1")
+	assert.False(t, ok)
+}
+
+func TestCustomAnswerGoesToLatestWaitingQuestion(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	now := time.Now()
+	bridge.storeQuestion("q:1:0", &QuestionState{RequestID: "que_1", MessageID: 5, WaitingCustom: true, CustomSince: now.Add(-time.Minute),
+		QuestionInfo: opencode.QuestionInfo{Question: "Name?"}, SelectedOptions: map[int]bool{}})
+	bridge.storeQuestion("q:2:0", &QuestionState{RequestID: "que_2", MessageID: 6, WaitingCustom: true, CustomSince: now,
+		QuestionInfo: opencode.QuestionInfo{Question: "Path?"}, SelectedOptions: map[int]bool{}})
+
+	mockOC.On("ReplyQuestion", "que_2", []opencode.QuestionAnswer{{"/tmp"}}).Return(nil).Once()
+	mockTG.On("EditMessage", ctx, 6, "Path?\n\n✅ Answer submitted: /tmp").Return(nil).Once()
+	assert.True(t, bridge.HandleQuestionCustomInput(ctx, "/tmp"))
+
+	mockOC.On("ReplyQuestion", "que_1", []opencode.QuestionAnswer{{"demo"}}).Return(nil).Once()
+	mockTG.On("EditMessage", ctx, 5, "Name?\n\n✅ Answer submitted: demo").Return(nil).Once()
+	assert.True(t, bridge.HandleQuestionCustomInput(ctx, "demo"))
+
+	assert.False(t, bridge.HandleQuestionCustomInput(ctx, "a normal prompt"))
+	mockOC.AssertExpectations(t)
+	mockTG.AssertExpectations(t)
+}
+
+func TestQuestionAskedWhileCustomAnswerPending(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	bridge.storeQuestion("q:1:0", &QuestionState{RequestID: "que_1", MessageID: 5, WaitingCustom: true, CustomSince: time.Now(),
+		QuestionInfo: opencode.QuestionInfo{Question: "Name?"}, SelectedOptions: map[int]bool{}})
+
+	var text string
+	mockTG.On("SendMessageWithKeyboard", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		text = args.String(1)
+	}).Return(7, nil)
+	event := opencode.EventQuestionAsked{Type: "question.asked"}
+	event.Properties.ID = "que_2"
+	event.Properties.Questions = []opencode.QuestionInfo{{Question: "Proceed?"}}
+	require.NoError(t, bridge.handleQuestionAsked(event))
+
+	assert.True(t, strings.HasSuffix(text, "✏️ Your next message still answers: Name?"), text)
+}
+
+func TestCustomAnswerDraftSurvivesRestart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	ctx := context.Background()
+
+	before := NewBridge(mockOC, mockTG, state.NewAppState(stateFile), state.NewIDRegistry(), time.Second)
+	before.storeQuestion("q:1:0", &QuestionState{RequestID: "que_1", MessageID: 5, WaitingCustom: true, CustomSince: time.Now(),
+		QuestionInfo: opencode.QuestionInfo{Question: "Name?"}, SelectedOptions: map[int]bool{}})
+	mockOC.On("ReplyQuestion", "que_1", []opencode.QuestionAnswer{{"<demo>"}}).Return(errors.New("connection refused")).Once()
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+	assert.True(t, before.HandleQuestionCustomInput(ctx, "<demo>"))
+	assert.Equal(t, "❌ Failed to submit answer: connection refused\n\nYour answer is kept, send it again to retry:\n<code>&lt;demo&gt;</code>",
+		mockTG.sentMessages[0])
+
+	after := NewBridge(mockOC, mockTG, state.NewAppState(stateFile), state.NewIDRegistry(), time.Second)
+	mockOC.On("ListPermissions").Return([]opencode.PermissionRequest{}, nil)
+	mockOC.On("ListQuestions").Return([]opencode.QuestionRequest{{ID: "que_1", Questions: []opencode.QuestionInfo{{Question: "Name?"}}}}, nil)
+	mockOC.On("ReplyQuestion", "que_1", []opencode.QuestionAnswer{{"<demo>"}}).Return(nil).Once()
+	mockTG.On("EditMessage", ctx, 5, "Name?\n\n✅ Answer submitted: <demo>").Return(nil).Once()
+	after.ReconcilePending(ctx)
+
+	mockOC.AssertExpectations(t)
+	mockTG.AssertExpectations(t)
+	_, found := after.questions.Load("q:1:0")
+	assert.False(t, found)
+}
@@ -0,0 +1,60 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// HandleQuickAction dispatches a reply keyboard button press to its command.
+// Returns handled=false when the keyboard is disabled or text is not a button label,
+// so the caller can forward the text to OpenCode as a normal prompt.
+func (b *Bridge) HandleQuickAction(ctx context.Context, text string) (bool, error) {
+	if !b.quickKeyboard {
+		return false, nil
+	}
+
+	action, ok := telegram.QuickActionFor(text)
+	if !ok {
+		return false, nil
+	}
+
+	switch action {
+	case telegram.QuickActionNewSession:
+		return true, b.cmdHandler.HandleNewSession(ctx, nil)
+	case telegram.QuickActionStatus:
+		return true, b.cmdHandler.HandleStatus(ctx)
+	case telegram.QuickActionAbort:
+		return true, b.cmdHandler.HandleAbortSession(ctx)
+	case telegram.QuickActionSwitchAgent:
+		b.handleSwitchCommand(ctx, "")
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// HandleKeyboardCommand handles /keyboard [off]
+// Without args: shows the quick action keyboard. With "off": removes it from the chat.
+func (b *Bridge) HandleKeyboardCommand(ctx context.Context, args string) error {
+	if !b.quickKeyboard {
+		_, err := b.tgBot.SendMessage(ctx, b.t("keyboard.disabled"))
+		return err
+	}
+
+	if strings.TrimSpace(args) == "off" {
+		_, err := b.tgBot.SendMessageWithReplyMarkup(ctx, b.t("keyboard.hidden"),
+			&models.ReplyKeyboardRemove{RemoveKeyboard: true})
+		return err
+	}
+
+	_, err := b.tgBot.SendMessageWithReplyMarkup(ctx, b.t("keyboard.enabled"), b.quickActionKeyboard())
+	return err
+}
+
+func (b *Bridge) quickActionKeyboard() *models.ReplyKeyboardMarkup {
+	return telegram.BuildQuickActionKeyboard(b.lang())
+}
@@ -0,0 +1,68 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestQuickActionDisabledByDefault(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+
+	handled, err := bridge.HandleQuickAction(context.Background(), "🛑 Abort")
+
+	assert.False(t, handled)
+	assert.NoError(t, err)
+	mockTG.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+}
+
+func TestQuickActionAbortNoSession(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetQuickActionKeyboard(true)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "❌ No active session to abort").Return(1, nil)
+
+	handled, err := bridge.HandleQuickAction(ctx, "🛑 Abort")
+
+	assert.True(t, handled)
+	assert.NoError(t, err)
+	mockTG.AssertExpectations(t)
+}
+
+func TestQuickActionIgnoresRegularText(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetQuickActionKeyboard(true)
+
+	handled, err := bridge.HandleQuickAction(context.Background(), "Status of the build?")
+
+	assert.False(t, handled)
+	assert.NoError(t, err)
+}
+
+func TestKeyboardCommandShowsAndHides(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetQuickActionKeyboard(true)
+	ctx := context.Background()
+
+	mockTG.On("SendMessageWithReplyMarkup", ctx, mock.Anything, mock.AnythingOfType("*models.ReplyKeyboardMarkup")).Return(1, nil)
+	mockTG.On("SendMessageWithReplyMarkup", ctx, mock.Anything, &models.ReplyKeyboardRemove{RemoveKeyboard: true}).Return(2, nil)
+
+	assert.NoError(t, bridge.HandleKeyboardCommand(ctx, ""))
+	assert.NoError(t, bridge.HandleKeyboardCommand(ctx, "off"))
+	mockTG.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"context"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// ReconcilePending re-posts the keyboards of permission and question
+// requests that are still pending in OpenCode but unknown to this bridge,
+// typically because they were asked before a restart. Requests already
+// tracked are left alone, so calling it again is harmless; tracked requests
+// OpenCode no longer lists, e.g. restored ones answered while the bridge was
+// down, are closed.
+func (b *Bridge) ReconcilePending(ctx context.Context) {
+	permissions, err := b.ocClient.ListPermissions()
+	if err != nil {
+		b.logger.Warn("Failed to list pending permissions", "error", err)
+	} else {
+		b.closeStalePermissions(ctx, permissions)
+	}
+	questions, err := b.ocClient.ListQuestions()
+	if err != nil {
+		b.logger.Warn("Failed to list pending questions", "error", err)
+	} else {
+		b.closeStaleQuestions(ctx, questions)
+	}
+
+	knownPermissions := make(map[string]bool)
+	b.permissions.Range(func(_, value interface{}) bool {
+		knownPermissions[value.(PermissionState).PermissionID] = true
+		return true
+	})
+	knownQuestions := make(map[string]bool)
+	b.questions.Range(func(_, value interface{}) bool {
+		knownQuestions[value.(*QuestionState).RequestID] = true
+		return true
+	})
+	// With the list above, only open questions are still tracked: retry
+	// their drafts. They stay known, so they are not posted again below.
+	if err == nil {
+		b.resubmitDrafts(ctx)
+	}
+
+	var missingPermissions []opencode.PermissionRequest
+	for _, perm := range permissions {
+		if !knownPermissions[perm.ID] {
+			missingPermissions = append(missingPermissions, perm)
+		}
+	}
+	var missingQuestions []opencode.QuestionRequest
+	for _, q := range questions {
+		if !knownQuestions[q.ID] && len(q.Questions) > 0 {
+			missingQuestions = append(missingQuestions, q)
+		}
+	}
+
+	restored := len(missingPermissions) + len(missingQuestions)
+	if restored == 0 {
+		return
+	}
+	b.logger.Info("Restoring pending requests", "permissions", len(missingPermissions), "questions", len(missingQuestions))
+	b.tgBot.SendMessage(ctx, b.t("pending.restored", restored))
+
+	for _, perm := range missingPermissions {
+		b.handlePermissionAsked(opencode.Event{
+			Type:       "permission.asked",
+			Properties: &opencode.EventPermissionAsked{Type: "permission.asked", Properties: perm},
+		})
+	}
+	for _, q := range missingQuestions {
+		if err := b.handleQuestionAsked(opencode.EventQuestionAsked{Type: "question.asked", Properties: q}); err != nil {
+			b.tgBot.SendMessage(ctx, b.t("question.error", err))
+		}
+	}
+}
+
+// staleBusyAfter is how long a session restored as busy is trusted when
+// OpenCode cannot be asked whether it is still generating
+const staleBusyAfter = 30 * time.Minute
+
+// ReconcileSessionStatus checks the sessions restored as busy against
+// OpenCode, typically after a restart: those that finished while the bridge
+// was down become idle, so they accept prompts again, while those still
+// generating keep refusing duplicate prompts. If OpenCode cannot be asked,
+// only sessions busy for longer than staleBusyAfter are reset.
+func (b *Bridge) ReconcileSessionStatus(ctx context.Context) {
+	busy := b.state.SessionsWithStatus(state.SessionBusy)
+	if len(busy) == 0 {
+		return
+	}
+
+	statuses, err := b.ocClient.ListSessionStatuses()
+	if err != nil {
+		b.logger.Warn("Failed to list session statuses", "error", err)
+	}
+	for sessionID, since := range busy {
+		switch {
+		case err == nil && statuses[sessionID].Busy():
+			b.logger.Info("Session is still generating", "session", sessionID, "busy_since", since)
+		case err == nil || time.Since(since) > staleBusyAfter:
+			b.logger.Info("Session is no longer busy", "session", sessionID)
+			b.state.SetSessionStatus(sessionID, state.SessionIdle)
+		}
+	}
+	b.refreshBanner(ctx)
+}
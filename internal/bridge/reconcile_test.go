@@ -0,0 +1,144 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestReconcilePendingRepostsUnknownRequests(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	// perm_known was posted before and must not be repeated
+	bridge.permissions.Store("known", PermissionState{PermissionID: "perm_known", SessionID: "ses_1"})
+
+	mockOC.On("ListPermissions").Return([]opencode.PermissionRequest{
+		{ID: "perm_known", SessionID: "ses_1", Permission: "bash"},
+		{ID: "perm_new", SessionID: "ses_1", Permission: "edit", Patterns: []string{"main.go"}},
+	}, nil)
+	mockOC.On("ListQuestions").Return([]opencode.QuestionRequest{{
+		ID:        "que_new",
+		SessionID: "ses_1",
+		Questions: []opencode.QuestionInfo{{Question: "Proceed?", Options: []opencode.QuestionOption{{Label: "Yes"}}}},
+	}}, nil)
+	mockTG.On("SendMessage", ctx, "♻️ Restoring 2 pending request(s) from before the restart:").Return(1, nil).Once()
+	mockTG.On("SendMessageWithKeyboard", ctx, mock.Anything, mock.Anything).Return(2, nil).Twice()
+
+	bridge.ReconcilePending(ctx)
+
+	mockTG.AssertExpectations(t)
+	var permissionIDs []string
+	bridge.permissions.Range(func(_, value interface{}) bool {
+		permissionIDs = append(permissionIDs, value.(PermissionState).PermissionID)
+		return true
+	})
+	assert.ElementsMatch(t, []string{"perm_known", "perm_new"}, permissionIDs)
+	var questionIDs []string
+	bridge.questions.Range(func(_, value interface{}) bool {
+		questionIDs = append(questionIDs, value.(*QuestionState).RequestID)
+		return true
+	})
+	assert.Equal(t, []string{"que_new"}, questionIDs)
+
+	// A second pass finds nothing new
+	bridge.ReconcilePending(ctx)
+	mockTG.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestReconcilePendingToleratesErrors(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+
+	mockOC.On("ListPermissions").Return(nil, errors.New("not found"))
+	mockOC.On("ListQuestions").Return(nil, errors.New("not found"))
+
+	bridge.ReconcilePending(context.Background())
+	mockTG.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+}
+
+func TestReconcilePendingClosesAnsweredRequests(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	// Restored from before a restart, but answered in the TUI meanwhile
+	bridge.permissions.Store("p:1:", PermissionState{PermissionID: "perm_done", SessionID: "ses_1", MessageID: 5})
+	bridge.questions.Store("q:2:0", &QuestionState{RequestID: "que_done", SessionID: "ses_1", MessageID: 6,
+		QuestionInfo: opencode.QuestionInfo{Question: "Proceed?"}})
+
+	mockOC.On("ListPermissions").Return([]opencode.PermissionRequest{}, nil)
+	mockOC.On("ListQuestions").Return([]opencode.QuestionRequest{}, nil)
+	mockTG.On("EditMessage", ctx, 5, mock.Anything).Return(nil).Once()
+	mockTG.On("EditMessage", ctx, 6, "Proceed?\n\n⌛ No longer pending in OpenCode").Return(nil).Once()
+
+	bridge.ReconcilePending(ctx)
+
+	mockTG.AssertExpectations(t)
+	_, found := bridge.permissions.Load("p:1:")
+	assert.False(t, found)
+	_, found = bridge.questions.Load("q:2:0")
+	assert.False(t, found)
+}
+
+// restoredState writes a state file with busy sessions and loads it
+func restoredState(t *testing.T, busySince map[string]time.Time) *state.AppState {
+	t.Helper()
+	statuses := make(map[string]interface{})
+	for sessionID, since := range busySince {
+		statuses[sessionID] = map[string]interface{}{"status": "busy", "since": since}
+	}
+	data, err := json.Marshal(map[string]interface{}{"session": "ses_running", "session_status": statuses})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateFile := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(stateFile, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return state.NewAppState(stateFile)
+}
+
+func TestReconcileSessionStatus(t *testing.T) {
+	appState := restoredState(t, map[string]time.Time{
+		"ses_running": time.Now(),
+		"ses_done":    time.Now(),
+	})
+	mockOC := new(MockOpenCodeClient)
+	bridge := NewBridge(mockOC, NewMockTelegramBot(), appState, state.NewIDRegistry(), time.Second)
+	mockOC.On("ListSessionStatuses").Return(map[string]opencode.SessionStatus{"ses_running": {Type: "busy"}}, nil)
+
+	bridge.ReconcileSessionStatus(context.Background())
+
+	assert.Equal(t, state.SessionBusy, appState.GetSessionStatus("ses_running"), "a generating session must keep refusing prompts")
+	assert.Equal(t, state.SessionIdle, appState.GetSessionStatus("ses_done"))
+}
+
+func TestReconcileSessionStatusWithoutOpenCode(t *testing.T) {
+	appState := restoredState(t, map[string]time.Time{
+		"ses_recent": time.Now().Add(-time.Minute),
+		"ses_stale":  time.Now().Add(-2 * staleBusyAfter),
+	})
+	mockOC := new(MockOpenCodeClient)
+	bridge := NewBridge(mockOC, NewMockTelegramBot(), appState, state.NewIDRegistry(), time.Second)
+	mockOC.On("ListSessionStatuses").Return(nil, errors.New("connection refused"))
+
+	bridge.ReconcileSessionStatus(context.Background())
+
+	assert.Equal(t, state.SessionBusy, appState.GetSessionStatus("ses_recent"))
+	assert.Equal(t, state.SessionIdle, appState.GetSessionStatus("ses_stale"))
+}
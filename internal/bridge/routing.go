@@ -2,15 +2,17 @@ package bridge
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"strings"
+
+	"github.com/user/opencode-telegram/internal/state"
 )
 
 // RoutingHandler handles /route commands for per-chat agent assignment
 type RoutingHandler struct {
 	appState routingAppState
 	tgBot    routingTelegramBot
+	audit    *auditTrail
+	translator
 }
 
 // Interfaces for dependency injection
@@ -58,8 +60,9 @@ func (h *RoutingHandler) HandleRouteCommand(ctx context.Context, chatID string,
 	if args == "clear" {
 		// Remove per-chat assignment
 		h.appState.RemoveChatAgent(chatID)
-		if _, err := h.tgBot.SendMessage(ctx, "✅ Chat agent assignment cleared. Using global agent."); err != nil {
-			log.Printf("[ROUTING] Failed to send message: %v", err)
+		h.audit.record(ctx, chatID, state.AuditAgentSwitch, "route cleared")
+		if _, err := h.tgBot.SendMessage(ctx, h.t("route.cleared")); err != nil {
+			logger.Warn("Failed to send message", "chat", chatID, "error", err)
 		}
 		return
 	}
@@ -67,9 +70,10 @@ func (h *RoutingHandler) HandleRouteCommand(ctx context.Context, chatID string,
 	// Set per-chat agent
 	agentName := args
 	h.appState.SetChatAgent(chatID, agentName)
-	message := fmt.Sprintf("✅ Chat agent set to: %s", agentName)
+	h.audit.record(ctx, chatID, state.AuditAgentSwitch, "route "+agentName)
+	message := h.t("route.set", agentName)
 	if _, err := h.tgBot.SendMessage(ctx, message); err != nil {
-		log.Printf("[ROUTING] Failed to send message: %v", err)
+		logger.Warn("Failed to send message", "chat", chatID, "error", err)
 	}
 }
 
@@ -80,12 +84,12 @@ func (h *RoutingHandler) showRoutingStatus(ctx context.Context, chatID string) {
 
 	var status string
 	if chatAgent != "" {
-		status = fmt.Sprintf("🎯 Chat Agent: %s\n📍 Global Agent: %s", chatAgent, globalAgent)
+		status = h.t("route.status_chat", chatAgent, globalAgent)
 	} else {
-		status = fmt.Sprintf("📍 Using Global Agent: %s\n(No chat-specific override)", globalAgent)
+		status = h.t("route.status_global", globalAgent)
 	}
 
 	if _, err := h.tgBot.SendMessage(ctx, status); err != nil {
-		log.Printf("[ROUTING] Failed to send message: %v", err)
+		logger.Warn("Failed to send message", "chat", chatID, "error", err)
 	}
 }
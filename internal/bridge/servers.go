@@ -0,0 +1,325 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// Server is a named OpenCode backend
+type Server struct {
+	Name    string
+	BaseURL string // shown in /server
+	Client  OpenCodeClient
+}
+
+// ServerSwitch is an OpenCodeClient that forwards to one of several OpenCode
+// servers. New sessions and server-wide calls go to the active server, while
+// calls for an existing session go to the server that owns it, so work
+// started before a /server switch still completes.
+type ServerSwitch struct {
+	mu      sync.RWMutex
+	servers []Server
+	active  int
+	owners  map[string]int // session ID -> index into servers
+}
+
+// NewServerSwitch creates a switch over servers; the first one is active
+func NewServerSwitch(servers []Server) *ServerSwitch {
+	return &ServerSwitch{
+		servers: servers,
+		owners:  make(map[string]int),
+	}
+}
+
+// Servers returns the configured servers
+func (s *ServerSwitch) Servers() []Server {
+	return s.servers
+}
+
+// Active returns the server new sessions are created on
+func (s *ServerSwitch) Active() Server {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.servers[s.active]
+}
+
+// Select makes the named server active, reporting whether it exists
+func (s *ServerSwitch) Select(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, srv := range s.servers {
+		if srv.Name == name {
+			s.active = i
+			return true
+		}
+	}
+	return false
+}
+
+// LearnSessions records which server owns each existing session, so
+// sessions restored from state are routed to the right server
+func (s *ServerSwitch) LearnSessions() {
+	for i, srv := range s.servers {
+		sessions, err := srv.Client.ListSessions()
+		if err != nil {
+			logger.Warn("Could not list sessions", "server", srv.Name, "error", err)
+			continue
+		}
+		s.remember(i, sessions...)
+	}
+}
+
+func (s *ServerSwitch) remember(idx int, sessions ...opencode.Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sess := range sessions {
+		s.owners[sess.ID] = idx
+	}
+}
+
+// activeClient returns the client of the active server and its index
+func (s *ServerSwitch) activeClient() (int, OpenCodeClient) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active, s.servers[s.active].Client
+}
+
+// sessionClient returns the client of the server owning sessionID, falling
+// back to the active server for sessions not seen yet
+func (s *ServerSwitch) sessionClient(sessionID string) OpenCodeClient {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if idx, ok := s.owners[sessionID]; ok {
+		return s.servers[idx].Client
+	}
+	return s.servers[s.active].Client
+}
+
+func (s *ServerSwitch) CreateSession(title *string, parentID *string) (*opencode.Session, error) {
+	idx, client := s.activeClient()
+	if parentID != nil {
+		// Forks live next to their parent
+		s.mu.RLock()
+		if owner, ok := s.owners[*parentID]; ok {
+			idx, client = owner, s.servers[owner].Client
+		}
+		s.mu.RUnlock()
+	}
+	session, err := client.CreateSession(title, parentID)
+	if err == nil && session != nil {
+		s.remember(idx, *session)
+	}
+	return session, err
+}
+
+func (s *ServerSwitch) ListSessions() ([]opencode.Session, error) {
+	idx, client := s.activeClient()
+	sessions, err := client.ListSessions()
+	if err == nil {
+		s.remember(idx, sessions...)
+	}
+	return sessions, err
+}
+
+func (s *ServerSwitch) DeleteSession(sessionID string) error {
+	return s.sessionClient(sessionID).DeleteSession(sessionID)
+}
+
+func (s *ServerSwitch) SendPrompt(sessionID, text string, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error) {
+	return s.sessionClient(sessionID).SendPrompt(sessionID, text, opts)
+}
+
+func (s *ServerSwitch) SendPromptWithParts(sessionID string, parts []interface{}, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error) {
+	return s.sessionClient(sessionID).SendPromptWithParts(sessionID, parts, opts)
+}
+
+func (s *ServerSwitch) TriggerPrompt(sessionID, text string, opts opencode.PromptOptions) error {
+	return s.sessionClient(sessionID).TriggerPrompt(sessionID, text, opts)
+}
+
+func (s *ServerSwitch) AbortSession(sessionID string) error {
+	return s.sessionClient(sessionID).AbortSession(sessionID)
+}
+
+func (s *ServerSwitch) InitSession(sessionID, providerID, modelID string) error {
+	return s.sessionClient(sessionID).InitSession(sessionID, providerID, modelID)
+}
+
+func (s *ServerSwitch) Health() (map[string]interface{}, error) {
+	_, client := s.activeClient()
+	return client.Health()
+}
+
+func (s *ServerSwitch) GetConfig() (map[string]interface{}, error) {
+	_, client := s.activeClient()
+	return client.GetConfig()
+}
+
+func (s *ServerSwitch) GetMessages(sessionID string, limit int) ([]opencode.Message, error) {
+	return s.sessionClient(sessionID).GetMessages(sessionID, limit)
+}
+
+func (s *ServerSwitch) GetSessionSummary(sessionID string) (*opencode.SessionSummary, error) {
+	return s.sessionClient(sessionID).GetSessionSummary(sessionID)
+}
+
+func (s *ServerSwitch) GetMessage(sessionID string, messageID string) (*opencode.Message, error) {
+	return s.sessionClient(sessionID).GetMessage(sessionID, messageID)
+}
+
+func (s *ServerSwitch) ReplyPermission(sessionID, permissionID string, response opencode.PermissionResponse) error {
+	return s.sessionClient(sessionID).ReplyPermission(sessionID, permissionID, response)
+}
+
+// ReplyQuestion answers on the active server first; question IDs carry no
+// session, so the other servers are tried when the active one does not know it
+func (s *ServerSwitch) ReplyQuestion(requestID string, answers []opencode.QuestionAnswer) error {
+	active, client := s.activeClient()
+	err := client.ReplyQuestion(requestID, answers)
+	for i, srv := range s.servers {
+		if !isNotFound(err) {
+			break
+		}
+		if i != active {
+			err = srv.Client.ReplyQuestion(requestID, answers)
+		}
+	}
+	return err
+}
+
+// ListPermissions lists pending permissions on every server
+func (s *ServerSwitch) ListPermissions() ([]opencode.PermissionRequest, error) {
+	var all []opencode.PermissionRequest
+	var errs []error
+	for _, srv := range s.servers {
+		permissions, err := srv.Client.ListPermissions()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", srv.Name, err))
+			continue
+		}
+		all = append(all, permissions...)
+	}
+	return all, errors.Join(errs...)
+}
+
+// ListQuestions lists pending questions on every server
+func (s *ServerSwitch) ListQuestions() ([]opencode.QuestionRequest, error) {
+	var all []opencode.QuestionRequest
+	var errs []error
+	for _, srv := range s.servers {
+		questions, err := srv.Client.ListQuestions()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", srv.Name, err))
+			continue
+		}
+		all = append(all, questions...)
+	}
+	return all, errors.Join(errs...)
+}
+
+// ListSessionStatuses lists the busy sessions of every server
+func (s *ServerSwitch) ListSessionStatuses() (map[string]opencode.SessionStatus, error) {
+	all := make(map[string]opencode.SessionStatus)
+	var errs []error
+	for _, srv := range s.servers {
+		statuses, err := srv.Client.ListSessionStatuses()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", srv.Name, err))
+			continue
+		}
+		for sessionID, status := range statuses {
+			all[sessionID] = status
+		}
+	}
+	return all, errors.Join(errs...)
+}
+
+func (s *ServerSwitch) GetProviders() (*opencode.ProvidersResponse, error) {
+	_, client := s.activeClient()
+	return client.GetProviders()
+}
+
+func (s *ServerSwitch) GetFileContent(path string) (*opencode.FileContent, error) {
+	_, client := s.activeClient()
+	return client.GetFileContent(path)
+}
+
+func (s *ServerSwitch) GetAgents() ([]string, error) {
+	_, client := s.activeClient()
+	return client.GetAgents()
+}
+
+// isNotFound reports whether err is an OpenCode 404
+func isNotFound(err error) bool {
+	var apiErr *opencode.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// SetServerSwitch enables /server; the bridge's OpenCode client must be sw
+func (b *Bridge) SetServerSwitch(sw *ServerSwitch) {
+	b.servers = sw
+}
+
+// HandleServerCommand handles /server [name]
+// Without args: lists the servers with a keyboard. With a name: switches to it.
+func (b *Bridge) HandleServerCommand(ctx context.Context, args string) error {
+	if b.servers == nil {
+		_, err := b.tgBot.SendMessage(ctx, b.t("server.single"))
+		return err
+	}
+
+	if name := strings.TrimSpace(args); name != "" {
+		return b.switchServer(ctx, name)
+	}
+
+	active := b.servers.Active()
+	var lines []string
+	var buttons [][]models.InlineKeyboardButton
+	for _, srv := range b.servers.Servers() {
+		icon := "⚫"
+		if srv.Name == active.Name {
+			icon = "🟢"
+		}
+		lines = append(lines, fmt.Sprintf("%s <b>%s</b> — <code>%s</code>", icon, html.EscapeString(srv.Name), html.EscapeString(srv.BaseURL)))
+		buttons = append(buttons, []models.InlineKeyboardButton{{
+			Text:         icon + " " + srv.Name,
+			CallbackData: "srv:" + srv.Name,
+		}})
+	}
+
+	text := b.t("server.list", html.EscapeString(active.Name)) + "\n\n" + strings.Join(lines, "\n")
+	_, err := b.tgBot.SendMessageWithKeyboard(ctx, text, &models.InlineKeyboardMarkup{InlineKeyboard: buttons})
+	return err
+}
+
+// switchServer makes the named server active for this chat. The current
+// session belongs to the old server, so it is cleared and the next message
+// starts a session on the new one.
+func (b *Bridge) switchServer(ctx context.Context, name string) error {
+	if b.servers.Active().Name == name {
+		_, err := b.tgBot.SendMessage(ctx, b.t("server.already", html.EscapeString(name)))
+		return err
+	}
+	if !b.servers.Select(name) {
+		_, err := b.tgBot.SendMessage(ctx, b.t("server.unknown", html.EscapeString(name)))
+		return err
+	}
+
+	b.logger.Info("Chat switched server", "server", name)
+	b.sessions.set(ctx, "")
+	if b.models != nil {
+		b.models.invalidateModels()
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, b.t("server.switched", html.EscapeString(name)))
+	return err
+}
@@ -0,0 +1,113 @@
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func newTestServerSwitch() (*ServerSwitch, *MockOpenCodeClient, *MockOpenCodeClient) {
+	laptop := new(MockOpenCodeClient)
+	buildbox := new(MockOpenCodeClient)
+	sw := NewServerSwitch([]Server{
+		{Name: "laptop", BaseURL: "http://localhost:54321", Client: laptop},
+		{Name: "buildbox", BaseURL: "https://build:4096", Client: buildbox},
+	})
+	return sw, laptop, buildbox
+}
+
+func TestServerSwitchRoutesSessionsToOwner(t *testing.T) {
+	sw, laptop, buildbox := newTestServerSwitch()
+
+	laptop.On("ListSessions").Return([]opencode.Session{{ID: "ses_laptop"}}, nil).Once()
+	buildbox.On("ListSessions").Return([]opencode.Session{{ID: "ses_build"}}, nil).Once()
+	sw.LearnSessions()
+
+	// Existing sessions stay on their own server whichever server is active
+	require.True(t, sw.Select("buildbox"))
+	laptop.On("AbortSession", "ses_laptop").Return(nil).Once()
+	require.NoError(t, sw.AbortSession("ses_laptop"))
+
+	// New sessions and unknown IDs go to the active server
+	buildbox.On("CreateSession", mock.Anything, (*string)(nil)).Return(&opencode.Session{ID: "ses_new"}, nil).Once()
+	_, err := sw.CreateSession(nil, nil)
+	require.NoError(t, err)
+	buildbox.On("TriggerPrompt", "ses_new", "hi", opencode.PromptOptions{}).Return(nil).Once()
+	require.NoError(t, sw.TriggerPrompt("ses_new", "hi", opencode.PromptOptions{}))
+
+	// Forks are created next to their parent
+	require.True(t, sw.Select("buildbox"))
+	laptop.On("CreateSession", mock.Anything, strPtr("ses_laptop")).Return(&opencode.Session{ID: "ses_fork"}, nil).Once()
+	_, err = sw.CreateSession(strPtr("fork"), strPtr("ses_laptop"))
+	require.NoError(t, err)
+	laptop.On("GetMessages", "ses_fork", 0).Return([]opencode.Message{}, nil).Once()
+	_, err = sw.GetMessages("ses_fork", 0)
+	require.NoError(t, err)
+
+	assert.False(t, sw.Select("missing"))
+	assert.Equal(t, "buildbox", sw.Active().Name)
+	laptop.AssertExpectations(t)
+	buildbox.AssertExpectations(t)
+}
+
+func TestServerSwitchReplyQuestionFallsBack(t *testing.T) {
+	sw, laptop, buildbox := newTestServerSwitch()
+	answers := []opencode.QuestionAnswer{{"Yes"}}
+
+	laptop.On("ReplyQuestion", "que_1", answers).Return(&opencode.APIError{StatusCode: http.StatusNotFound}).Once()
+	buildbox.On("ReplyQuestion", "que_1", answers).Return(nil).Once()
+	require.NoError(t, sw.ReplyQuestion("que_1", answers))
+
+	// Other errors are returned without trying the next server
+	laptop.On("ReplyQuestion", "que_2", answers).Return(&opencode.APIError{StatusCode: http.StatusBadRequest}).Once()
+	assert.Error(t, sw.ReplyQuestion("que_2", answers))
+	buildbox.AssertNotCalled(t, "ReplyQuestion", "que_2", answers)
+}
+
+func TestHandleServerCommand(t *testing.T) {
+	sw, _, _ := newTestServerSwitch()
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_laptop")
+	bridge := NewBridge(sw, mockTG, appState, state.NewIDRegistry(), time.Second)
+	bridge.SetServerSwitch(sw)
+	ctx := context.Background()
+
+	var keyboard *models.InlineKeyboardMarkup
+	mockTG.On("SendMessageWithKeyboard", ctx, "🖥 <b>OpenCode Servers</b> (current: <b>laptop</b>)\n\n"+
+		"🟢 <b>laptop</b> — <code>http://localhost:54321</code>\n"+
+		"⚫ <b>buildbox</b> — <code>https://build:4096</code>", mock.Anything).Run(func(args mock.Arguments) {
+		keyboard = args.Get(2).(*models.InlineKeyboardMarkup)
+	}).Return(1, nil).Once()
+	require.NoError(t, bridge.HandleServerCommand(ctx, ""))
+	require.NotNil(t, keyboard)
+	assert.Equal(t, "srv:buildbox", keyboard.InlineKeyboard[1][0].CallbackData)
+
+	mockTG.On("SendMessage", ctx, "✅ Switched to server <b>buildbox</b>. Your next message starts a new session there.").Return(2, nil).Once()
+	require.NoError(t, bridge.HandleServerCommand(ctx, "buildbox"))
+	assert.Equal(t, "buildbox", sw.Active().Name)
+	assert.Empty(t, appState.GetSessionForChat(""))
+
+	mockTG.On("SendMessage", ctx, "❌ Unknown server: nope. Use /server to see the list.").Return(3, nil).Once()
+	require.NoError(t, bridge.HandleServerCommand(ctx, "nope"))
+	mockTG.AssertExpectations(t)
+}
+
+func TestHandleServerCommandSingleServer(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "ℹ️ Only one OpenCode server is configured. Set OPENCODE_SERVERS to add more.").Return(1, nil).Once()
+	require.NoError(t, bridge.HandleServerCommand(ctx, "buildbox"))
+	mockTG.AssertExpectations(t)
+}
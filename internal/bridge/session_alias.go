@@ -0,0 +1,118 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// HandleAliasSessionCommand names sessions so /session accepts the name
+// instead of the ID.
+// Usage:
+//
+//	/alias-session <name> [session]  (default: the current session)
+//	/alias-session rm <name>
+//	/alias-session                   (list)
+func (b *Bridge) HandleAliasSessionCommand(ctx context.Context, args string) error {
+	name, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+
+	var msg string
+	switch {
+	case name == "":
+		msg = b.formatSessionAliases()
+	case name == "rm":
+		if rest == "" {
+			msg = b.t("session_alias.usage")
+			break
+		}
+		if !b.state.RemoveSessionAlias(b.chatID, rest) {
+			msg = b.t("session_alias.not_found", html.EscapeString(rest))
+			break
+		}
+		msg = b.t("session_alias.removed", rest)
+	case !aliasNamePattern.MatchString(name) || strings.HasPrefix(name, "ses_"):
+		// "ses_" names would be mistaken for session IDs
+		msg = b.t("session_alias.invalid_name", html.EscapeString(name))
+	default:
+		sessionID := rest
+		if sessionID == "" {
+			sessionID = b.sessions.current(ctx)
+		}
+		if sessionID == "" {
+			msg = b.t("session_alias.no_session")
+			break
+		}
+		sessions, err := b.ocClient.ListSessions()
+		if err != nil {
+			return fmt.Errorf("list sessions: %w", err)
+		}
+		sess, ok := findSession(sessions, sessionID)
+		if !ok {
+			msg = b.t("session.not_found", html.EscapeString(sessionID))
+			break
+		}
+		b.state.SetSessionAlias(b.chatID, name, sessionID)
+		msg = b.t("session_alias.added", name, html.EscapeString(sess.Title))
+	}
+
+	_, err := b.tgBot.SendMessage(ctx, msg)
+	return err
+}
+
+// formatSessionAliases lists the chat's session aliases
+// Example:
+//
+//	🏷 Session aliases:
+//	mybot → ses_abc
+func (b *Bridge) formatSessionAliases() string {
+	aliases := b.state.ListSessionAliases(b.chatID)
+	if len(aliases) == 0 {
+		return b.t("session_alias.list_empty")
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(b.t("session_alias.list_title"))
+	for _, name := range names {
+		fmt.Fprintf(&sb, "\n%s → <code>%s</code>", name, aliases[name])
+	}
+	return sb.String()
+}
+
+// resolveSessionAlias returns the session a chat's alias names, or the
+// argument itself when it is no alias
+func (h *CommandHandler) resolveSessionAlias(nameOrID string) string {
+	if sessionID, ok := h.appState.GetSessionAlias(h.sessions.chatID, nameOrID); ok {
+		return sessionID
+	}
+	return nameOrID
+}
+
+// sessionAliasNames maps session IDs to their aliases in this chat, for
+// listings ("a, b" when a session has several)
+func (h *CommandHandler) sessionAliasNames() map[string]string {
+	aliases := h.appState.ListSessionAliases(h.sessions.chatID)
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bySession := make(map[string]string, len(aliases))
+	for _, name := range names {
+		sessionID := aliases[name]
+		if bySession[sessionID] != "" {
+			bySession[sessionID] += ", "
+		}
+		bySession[sessionID] += name
+	}
+	return bySession
+}
@@ -0,0 +1,73 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestAliasSessionCommand(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_cur")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{
+		{ID: "ses_cur", Title: "Current", Slug: "current"},
+		{ID: "ses_bot", Title: "Bot", Slug: "bot"},
+	}, nil)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+
+	require.NoError(t, bridge.HandleAliasSessionCommand(ctx, "here"))
+	require.NoError(t, bridge.HandleAliasSessionCommand(ctx, "mybot ses_bot"))
+	require.NoError(t, bridge.HandleAliasSessionCommand(ctx, "ses_x ses_bot"))
+	require.NoError(t, bridge.HandleAliasSessionCommand(ctx, "gone ses_missing"))
+	require.NoError(t, bridge.HandleAliasSessionCommand(ctx, ""))
+	require.NoError(t, bridge.HandleAliasSessionCommand(ctx, "rm here"))
+
+	require.NoError(t, bridge.cmdHandler.HandleSwitchSession(ctx, "mybot"))
+	assert.Equal(t, "ses_bot", bridge.sessions.current(ctx))
+
+	assert.Equal(t, []string{
+		"🏷 here now names Current. Switch to it with /session here",
+		"🏷 mybot now names Bot. Switch to it with /session mybot",
+		"❌ Invalid session alias: ses_x (use a-z, 0-9 and _, up to 32 characters, not starting with ses_)",
+		"❌ Session ses_missing not found",
+		"🏷 Session aliases:\nhere → <code>ses_cur</code>\nmybot → <code>ses_bot</code>",
+		"🗑 Session alias here removed",
+	}, mockTG.sentMessages[:6])
+}
+
+func TestSessionAliasesShownInListings(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetSessionAlias("", "mybot", "ses_bot")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), time.Second)
+	ctx := context.Background()
+
+	mockOC.On("ListSessions").Return([]opencode.Session{{ID: "ses_bot", Title: "Bot", Slug: "bot"}}, nil)
+	mockOC.On("GetSessionSummary", "ses_bot").Return(nil, assert.AnError)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil).Once()
+	require.NoError(t, bridge.cmdHandler.HandleListSessions(ctx))
+	assert.Contains(t, mockTG.sentMessages[0], "<code>ses_bot</code> · 🏷 mybot")
+
+	var keyboard *models.InlineKeyboardMarkup
+	mockTG.On("SendMessageWithKeyboard", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		keyboard = args.Get(2).(*models.InlineKeyboardMarkup)
+	}).Return(2, nil).Once()
+	require.NoError(t, bridge.cmdHandler.HandleSelectSession(ctx))
+	require.NotNil(t, keyboard)
+	assert.True(t, strings.HasPrefix(keyboard.InlineKeyboard[0][0].Text, "🏷 mybot · Bot"))
+}
@@ -0,0 +1,58 @@
+package bridge
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// summaryWorkers bounds concurrent summary requests when listing sessions
+const summaryWorkers = 4
+
+// sessionSummaries fetches summaries for the given sessions concurrently.
+// Sessions whose summary cannot be fetched are missing from the result, so
+// the listing degrades to title and timestamp.
+func (h *CommandHandler) sessionSummaries(ids []string) map[string]*opencode.SessionSummary {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		summaries = make(map[string]*opencode.SessionSummary, len(ids))
+		jobs      = make(chan string)
+	)
+
+	for i := 0; i < summaryWorkers && i < len(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				summary, err := h.ocClient.GetSessionSummary(id)
+				if err != nil {
+					h.logger.Warn("Failed to summarize session", "session", id, "error", err)
+					continue
+				}
+				mu.Lock()
+				summaries[id] = summary
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range ids {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	return summaries
+}
+
+// formatTokenCount renders a token count compactly, e.g. 950, 12.3k, 1.2M
+func formatTokenCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d", n)
+}
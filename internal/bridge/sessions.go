@@ -0,0 +1,119 @@
+package bridge
+
+import (
+	"context"
+	"html"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// sessionScope resolves which OpenCode session an update belongs to.
+// By default the whole chat shares one current session, kept apart from
+// other chats; with per-user sessions enabled, each (chatID, userID) pair
+// in a group gets its own.
+type sessionScope struct {
+	state   *state.AppState
+	chatID  string
+	perUser bool
+
+	// onSwitch runs after every session change (e.g. to refresh the banner)
+	onSwitch func(ctx context.Context)
+}
+
+func (s *sessionScope) userID(ctx context.Context) (int64, bool) {
+	if !s.perUser || ctx == nil {
+		return 0, false
+	}
+	return telegram.UserIDFromContext(ctx)
+}
+
+// current returns the session for the user behind ctx
+func (s *sessionScope) current(ctx context.Context) string {
+	if userID, ok := s.userID(ctx); ok {
+		return s.state.GetUserSession(s.chatID, userID)
+	}
+	return s.state.GetSessionForChat(s.chatID)
+}
+
+// set switches the session for the user behind ctx
+func (s *sessionScope) set(ctx context.Context, sessionID string) {
+	if userID, ok := s.userID(ctx); ok {
+		s.state.SetUserSession(s.chatID, userID, sessionID)
+	} else {
+		s.state.SetSessionForChat(s.chatID, sessionID)
+	}
+	if s.onSwitch != nil {
+		s.onSwitch(ctx)
+	}
+}
+
+// TracksSession reports whether events of sessionID matter to this bridge:
+// it is a session of this chat or one with a response still in flight. Used
+// as the SSE consumer's session filter.
+func (b *Bridge) TracksSession(sessionID string) bool {
+	if b.state.TracksSession(sessionID) {
+		return true
+	}
+	if _, ok := b.thinkingMsgs.Load(sessionID); ok {
+		return true
+	}
+	_, ok := b.triggerMsgs.Load(sessionID)
+	return ok
+}
+
+// SetPerUserSessions gives every group member their own current session.
+// Without a known sender (e.g. SSE events), the shared session is used.
+func (b *Bridge) SetPerUserSessions(enabled bool) {
+	b.sessions.perUser = enabled
+}
+
+// handleSessionDeleted forgets a session deleted in OpenCode (e.g. from the
+// TUI), so the next message starts a new session instead of failing with
+// "session not found"
+func (b *Bridge) handleSessionDeleted(event opencode.Event) {
+	sessionID := event.SessionID()
+	if sessionID == "" {
+		return
+	}
+
+	b.thinkingMsgs.Delete(sessionID)
+	b.streamBuffers.Delete(sessionID)
+	b.stopProgress(sessionID)
+	b.cmdHandler.updateCachedSession(sessionID, "", true)
+	if !b.state.ForgetSession(sessionID) {
+		return
+	}
+
+	b.logger.Info("Session in use was deleted in OpenCode", "session", sessionID)
+	ctx := context.Background()
+	if _, err := b.tgBot.SendMessage(ctx, b.t("session.deleted_externally", html.EscapeString(sessionTitle(event, sessionID)))); err != nil {
+		b.logger.Warn("Failed to report deleted session", "session", sessionID, "error", err)
+	}
+	b.refreshBanner(ctx)
+}
+
+// handleSessionUpdated picks up renamed sessions in the banner and the
+// cached session menus
+func (b *Bridge) handleSessionUpdated(event opencode.Event) {
+	sessionID := event.SessionID()
+	if sessionID == "" {
+		return
+	}
+
+	b.cmdHandler.updateCachedSession(sessionID, sessionTitle(event, ""), false)
+	if b.state.TracksSession(sessionID) {
+		b.refreshBanner(context.Background())
+	}
+}
+
+// sessionTitle returns the title in a session.* event's info, or fallback
+func sessionTitle(event opencode.Event, fallback string) string {
+	props, _ := event.Properties.(map[string]interface{})
+	info, _ := props["info"].(map[string]interface{})
+	if title, _ := info["title"].(string); title != "" {
+		return title
+	}
+	return fallback
+}
@@ -0,0 +1,120 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+func TestPerUserSessionsInGroup(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_shared")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.SetPerUserSessions(true)
+
+	mockOC.On("CreateSession", mock.Anything, mock.Anything).Return(&opencode.Session{ID: "ses_alice"}, nil).Once()
+	mockOC.On("CreateSession", mock.Anything, mock.Anything).Return(&opencode.Session{ID: "ses_bob"}, nil).Once()
+	mockOC.On("TriggerPrompt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockTG.On("SendMessage", mock.Anything, "⏳ Processing...").Return(1, nil)
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+
+	alice := telegram.WithUserID(context.Background(), 101)
+	bob := telegram.WithUserID(context.Background(), 202)
+
+	assert.NoError(t, bridge.HandleUserMessage(alice, "hi from alice"))
+	assert.NoError(t, bridge.HandleUserMessage(bob, "hi from bob"))
+
+	assert.Equal(t, "ses_alice", bridge.sessions.current(alice))
+	assert.Equal(t, "ses_bob", bridge.sessions.current(bob))
+	assert.Equal(t, "ses_shared", appState.GetSessionForChat(""), "shared session must be untouched")
+
+	// Events without a sender fall back to the shared session
+	assert.Equal(t, "ses_shared", bridge.sessions.current(context.Background()))
+}
+
+func TestSharedSessionByDefault(t *testing.T) {
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), appState, state.NewIDRegistry(), 10*time.Millisecond)
+
+	alice := telegram.WithUserID(context.Background(), 101)
+	bridge.sessions.set(alice, "ses_1")
+
+	assert.Equal(t, "ses_1", appState.GetSessionForChat(""))
+	assert.Equal(t, "ses_1", bridge.sessions.current(telegram.WithUserID(context.Background(), 202)))
+}
+
+func TestSessionsKeptPerChat(t *testing.T) {
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_default")
+	first := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), appState, state.NewIDRegistry(), 10*time.Millisecond)
+	first.sessions.chatID = "-100"
+	second := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), appState, state.NewIDRegistry(), 10*time.Millisecond)
+	second.sessions.chatID = "-200"
+
+	first.sessions.set(context.Background(), "ses_first")
+
+	assert.Equal(t, "ses_first", first.sessions.current(context.Background()))
+	assert.Equal(t, "ses_default", second.sessions.current(context.Background()), "another chat must not follow the switch")
+
+	first.sessions.set(context.Background(), "")
+	assert.Equal(t, "", first.sessions.current(context.Background()), "a cleared chat must not fall back to the default")
+}
+
+func TestTracksSession(t *testing.T) {
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_shared")
+	appState.SetUserSession("-100", 101, "ses_alice")
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), appState, state.NewIDRegistry(), 10*time.Millisecond)
+
+	assert.True(t, bridge.TracksSession("ses_shared"))
+	assert.True(t, bridge.TracksSession("ses_alice"))
+	assert.False(t, bridge.TracksSession("ses_tui"))
+
+	// A response still in flight keeps its session tracked after a switch
+	bridge.thinkingMsgs.Store("ses_old", 42)
+	assert.True(t, bridge.TracksSession("ses_old"))
+}
+
+func TestSessionDeletedElsewhere(t *testing.T) {
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_gone")
+	bridge := NewBridge(new(MockOpenCodeClient), mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.cmdHandler.cacheSessions([]opencode.Session{{ID: "ses_gone"}, {ID: "ses_other"}}, "cache")
+	mockTG.On("SendMessage", mock.Anything, "🗑 Session Fix &lt;login&gt; was deleted in OpenCode. Your next message starts a new session.").Return(1, nil)
+
+	bridge.handleSessionDeleted(opencode.Event{Type: "session.deleted", Properties: map[string]interface{}{
+		"info": map[string]interface{}{"id": "ses_gone", "title": "Fix <login>"},
+	}})
+
+	mockTG.AssertExpectations(t)
+	assert.Equal(t, "", appState.GetSessionForChat(""))
+	assert.Len(t, bridge.cmdHandler.cachedSessions(), 1)
+
+	// Sessions the chat does not use are dropped silently
+	bridge.handleSessionDeleted(opencode.Event{Type: "session.deleted", Properties: map[string]interface{}{
+		"info": map[string]interface{}{"id": "ses_other"},
+	}})
+	mockTG.AssertNumberOfCalls(t, "SendMessage", 1)
+	assert.Empty(t, bridge.cmdHandler.cachedSessions())
+}
+
+func TestSessionUpdatedRenamesCachedSession(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.cmdHandler.cacheSessions([]opencode.Session{{ID: "ses_1", Title: "Old"}}, "cache")
+
+	bridge.handleSessionUpdated(opencode.Event{Type: "session.updated", Properties: map[string]interface{}{
+		"info": map[string]interface{}{"id": "ses_1", "title": "New"},
+	}})
+
+	assert.Equal(t, "New", bridge.cmdHandler.cachedSessions()[0].Title)
+}
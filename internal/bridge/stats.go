@@ -0,0 +1,62 @@
+package bridge
+
+import (
+	"context"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// recordPrompt counts a prompt OpenCode accepted and starts timing its response
+func (b *Bridge) recordPrompt(sessionID string) {
+	b.promptStarts.Store(sessionID, time.Now())
+	b.publishStats(b.state.RecordPrompt(b.chatID))
+}
+
+// recordResponse counts a delivered response with the tokens it used
+func (b *Bridge) recordResponse(sessionID string, tokens int) {
+	var latency time.Duration
+	if started, ok := b.promptStarts.LoadAndDelete(sessionID); ok {
+		latency = time.Since(started.(time.Time))
+	}
+	b.publishStats(b.state.RecordResponse(b.chatID, tokens, latency))
+}
+
+// recordError counts a failed prompt or session error
+func (b *Bridge) recordError(sessionID string) {
+	b.promptStarts.Delete(sessionID)
+	b.publishStats(b.state.RecordError(b.chatID))
+}
+
+// publishStats exports a chat's usage counters as Prometheus gauges
+func (b *Bridge) publishStats(stats state.ChatStats) {
+	metrics.ChatPrompts.WithLabelValues(b.chatID).Set(float64(stats.Prompts))
+	metrics.ChatResponses.WithLabelValues(b.chatID).Set(float64(stats.Responses))
+	metrics.ChatErrors.WithLabelValues(b.chatID).Set(float64(stats.Errors))
+	metrics.ChatTokens.WithLabelValues(b.chatID).Set(float64(stats.Tokens))
+	metrics.ChatResponseLatency.WithLabelValues(b.chatID).Set(stats.AverageLatency().Seconds())
+}
+
+// messageTokens returns the tokens an assistant message used, like
+// SessionSummary counts them
+func messageTokens(msg *opencode.Message) int {
+	tokens := msg.Info.Tokens
+	if tokens == nil {
+		return 0
+	}
+	return tokens.Input + tokens.Cache.Read + tokens.Cache.Write + tokens.Output + tokens.Reasoning
+}
+
+// HandleStatsCommand handles /stats, showing the chat's usage counters
+func (b *Bridge) HandleStatsCommand(ctx context.Context) error {
+	stats := b.state.GetChatStats(b.chatID)
+	latency := "—"
+	if avg := stats.AverageLatency(); avg > 0 {
+		latency = avg.Round(100 * time.Millisecond).String()
+	}
+	_, err := b.tgBot.SendMessage(ctx, b.t("stats.report",
+		stats.Prompts, stats.Responses, stats.Errors, formatTokenCount(stats.Tokens), latency))
+	return err
+}
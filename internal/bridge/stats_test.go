@@ -0,0 +1,68 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// chatGauge reads a per-chat usage gauge
+func chatGauge(t *testing.T, name, chatID string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "chat" && label.GetValue() == chatID {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestStatsCountPromptsAndResponses(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 10*time.Millisecond)
+	bridge.chatID = "-100stats"
+	ctx := context.Background()
+
+	mockOC.On("GetMessage", "ses_1", "msg_1").Return(&opencode.Message{
+		Info:  opencode.MessageInfo{ID: "msg_1", Role: "assistant", Tokens: &opencode.MessageTokens{Input: 1200, Output: 300}},
+		Parts: []opencode.MessagePart{{Type: "text", Text: "Done"}},
+	}, nil)
+	mockTG.On("SendMessage", ctx, mock.Anything).Return(1, nil)
+
+	bridge.recordPrompt("ses_1")
+	bridge.fetchAndSendCompletedMessage("ses_1", "msg_1")
+	bridge.recordError("ses_2")
+
+	stats := appState.GetChatStats("-100stats")
+	assert.Equal(t, 1, stats.Prompts)
+	assert.Equal(t, 1, stats.Responses)
+	assert.Equal(t, 1, stats.Errors)
+	assert.Equal(t, 1500, stats.Tokens)
+	assert.Equal(t, 1, stats.Timed)
+	assert.Equal(t, float64(1500), chatGauge(t, "chat_tokens", "-100stats"))
+	assert.Equal(t, float64(1), chatGauge(t, "chat_errors", "-100stats"))
+
+	assert.NoError(t, bridge.HandleStatsCommand(ctx))
+	report := mockTG.sentMessages[len(mockTG.sentMessages)-1]
+	assert.Contains(t, report, "Prompts: 1\nResponses: 1\nErrors: 1\nTokens: 1.5k")
+}
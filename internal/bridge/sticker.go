@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/go-telegram/bot/models"
+
 	"github.com/user/opencode-telegram/internal/opencode"
 )
 
 // stickerOpenCodeClient interface for sending prompts
 type stickerOpenCodeClient interface {
-	SendPrompt(sessionID, text string, agent *string) (*opencode.SendPromptResponse, error)
+	SendPrompt(sessionID, text string, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error)
 }
 
 // stickerTelegramBot interface for sending messages
@@ -27,6 +29,12 @@ type StickerHandler struct {
 	ocClient stickerOpenCodeClient
 	tgBot    stickerTelegramBot
 	appState stickerAppState
+	translator
+
+	// sessionFor overrides the appState lookup (per-user group sessions)
+	sessionFor func(ctx context.Context) string
+	// promptOptions gives the chat's agent and model, like text prompts get
+	promptOptions func() opencode.PromptOptions
 }
 
 // NewStickerHandler creates a new StickerHandler
@@ -40,35 +48,50 @@ func NewStickerHandler(ocClient stickerOpenCodeClient, tgBot stickerTelegramBot,
 
 // HandleSticker processes incoming sticker messages
 // Formats sticker as text description for AI
-// Format: [Sticker: {emoji} from set "{setName}"]
-// With fallbacks for missing emoji/setName
 func (h *StickerHandler) HandleSticker(ctx context.Context, emoji string, setName string) error {
-	// Build text description with fallbacks
-	var text string
-
-	if emoji != "" && setName != "" {
-		text = fmt.Sprintf("[Sticker: %s from set \"%s\"]", emoji, setName)
-	} else if emoji != "" {
-		text = fmt.Sprintf("[Sticker: %s]", emoji)
-	} else if setName != "" {
-		text = fmt.Sprintf("[Sticker from set \"%s\"]", setName)
-	} else {
-		text = "[Sticker]"
-	}
+	text := describeSticker(emoji, setName)
 
 	// Send to current OpenCode session
 	sessionID := h.appState.GetCurrentSession()
+	if h.sessionFor != nil {
+		sessionID = h.sessionFor(ctx)
+	}
 	if sessionID == "" {
 		// No active session, just acknowledge
-		_, err := h.tgBot.SendMessage(ctx, "📌 Sticker received (no active session)")
+		_, err := h.tgBot.SendMessage(ctx, h.t("sticker.no_session"))
 		return err
 	}
 
 	// Send to AI session
-	_, err := h.ocClient.SendPrompt(sessionID, text, nil)
+	var opts opencode.PromptOptions
+	if h.promptOptions != nil {
+		opts = h.promptOptions()
+	}
+	_, err := h.ocClient.SendPrompt(sessionID, text, opts)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// describeSticker formats a sticker as text for the AI
+// Format: [Sticker: {emoji} from set "{setName}"]
+// With fallbacks for missing emoji/setName
+func describeSticker(emoji string, setName string) string {
+	switch {
+	case emoji != "" && setName != "":
+		return fmt.Sprintf("[Sticker: %s from set \"%s\"]", emoji, setName)
+	case emoji != "":
+		return fmt.Sprintf("[Sticker: %s]", emoji)
+	case setName != "":
+		return fmt.Sprintf("[Sticker from set \"%s\"]", setName)
+	}
+	return "[Sticker]"
+}
+
+// HandleStickerFrame sends a rendered frame of an animated/video sticker
+// through the photo pipeline, with the sticker description as the caption
+func (b *Bridge) HandleStickerFrame(ctx context.Context, frame models.PhotoSize, emoji string, setName string, botToken string) error {
+	return b.handlePhotos(ctx, [][]models.PhotoSize{{frame}}, describeSticker(emoji, setName), botToken)
+}
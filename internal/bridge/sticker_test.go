@@ -3,20 +3,28 @@ package bridge
 import (
 	"context"
 	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
 )
 
 // Mock clients for sticker tests
 type mockStickerOpenCodeClient struct {
 	messages map[string][]string // sessionID -> messages
+	opts     []opencode.PromptOptions
 }
 
-func (m *mockStickerOpenCodeClient) SendPrompt(sessionID string, text string, agent *string) (*opencode.SendPromptResponse, error) {
+func (m *mockStickerOpenCodeClient) SendPrompt(sessionID string, text string, opts opencode.PromptOptions) (*opencode.SendPromptResponse, error) {
 	if m.messages == nil {
 		m.messages = make(map[string][]string)
 	}
 	m.messages[sessionID] = append(m.messages[sessionID], text)
+	m.opts = append(m.opts, opts)
 	return nil, nil
 }
 
@@ -61,6 +69,23 @@ func TestStickerWithEmojiAndSet(t *testing.T) {
 	}
 }
 
+func TestStickerUsesPromptOptions(t *testing.T) {
+	mockOC := &mockStickerOpenCodeClient{}
+	appState := &mockStickerAppState{currentSessionID: "sess123"}
+
+	handler := NewStickerHandler(mockOC, &mockStickerTelegramBot{}, appState)
+	handler.promptOptions = func() opencode.PromptOptions {
+		return opencode.PromptOptions{Agent: "plan", Model: "anthropic/claude"}
+	}
+
+	if err := handler.HandleSticker(context.Background(), "👍", ""); err != nil {
+		t.Fatalf("HandleSticker failed: %v", err)
+	}
+	if len(mockOC.opts) != 1 || mockOC.opts[0].Agent != "plan" || mockOC.opts[0].Model != "anthropic/claude" {
+		t.Errorf("Expected the chat's agent and model, got %+v", mockOC.opts)
+	}
+}
+
 func TestStickerWithEmojiOnly(t *testing.T) {
 	// Test sticker with emoji only
 	mockOC := &mockStickerOpenCodeClient{}
@@ -156,16 +181,14 @@ func TestStickerNoActiveSession(t *testing.T) {
 	}
 }
 
-func TestStickerAnimatedSkipped(t *testing.T) {
-	// Test that animated stickers are rejected at bot.go level
-	// This test verifies the handler itself doesn't break on any input
+func TestStickerAnimatedWithoutFrame(t *testing.T) {
+	// Animated stickers without a thumbnail fall back to the text description
 	mockOC := &mockStickerOpenCodeClient{}
 	mockTG := &mockStickerTelegramBot{}
 	appState := &mockStickerAppState{currentSessionID: "sess123"}
 
 	handler := NewStickerHandler(mockOC, mockTG, appState)
 
-	// Handler should work fine - rejection happens in bot.go RegisterStickerHandler
 	err := handler.HandleSticker(context.Background(), "🎬", "animated_pack")
 	if err != nil {
 		t.Fatalf("HandleSticker failed: %v", err)
@@ -177,16 +200,14 @@ func TestStickerAnimatedSkipped(t *testing.T) {
 	}
 }
 
-func TestStickerVideoSkipped(t *testing.T) {
-	// Test that video stickers are rejected at bot.go level
-	// This test verifies the handler itself doesn't break on any input
+func TestStickerVideoWithoutFrame(t *testing.T) {
+	// Video stickers without a thumbnail fall back to the text description
 	mockOC := &mockStickerOpenCodeClient{}
 	mockTG := &mockStickerTelegramBot{}
 	appState := &mockStickerAppState{currentSessionID: "sess456"}
 
 	handler := NewStickerHandler(mockOC, mockTG, appState)
 
-	// Handler should work fine - rejection happens in bot.go RegisterStickerHandler
 	err := handler.HandleSticker(context.Background(), "🎥", "video_pack")
 	if err != nil {
 		t.Fatalf("HandleSticker failed: %v", err)
@@ -197,3 +218,39 @@ func TestStickerVideoSkipped(t *testing.T) {
 		t.Errorf("Expected 1 message, got %d", len(mockOC.messages["sess456"]))
 	}
 }
+
+func TestStickerFrameSentToVision(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession("ses_sticker")
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 100*time.Millisecond)
+
+	webp := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 16)...)
+	bridge.downloadFile = func(ctx context.Context, botToken, fileID string) ([]byte, error) {
+		assert.Equal(t, "thumb1", fileID)
+		return webp, nil
+	}
+
+	sent := make(chan []interface{}, 1)
+	mockTG.On("SendMessage", mock.Anything, "🖼️ Processing image...").Return(1, nil).Once()
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockOC.On("SendPromptWithParts", "ses_sticker", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sent <- args.Get(1).([]interface{}) }).
+		Return(&opencode.SendPromptResponse{}, nil).Once()
+
+	frame := models.PhotoSize{FileID: "thumb1", Width: 128, Height: 128}
+	assert.NoError(t, bridge.HandleStickerFrame(context.Background(), frame, "😂", "memes", "token"))
+
+	select {
+	case parts := <-sent:
+		assert.Len(t, parts, 2)
+		img, ok := parts[0].(opencode.ImagePartInput)
+		if assert.True(t, ok) {
+			assert.Equal(t, "image/webp", img.MimeType)
+		}
+		assert.Equal(t, opencode.TextPartInput{Type: "text", Text: "[Sticker: 😂 from set \"memes\"]"}, parts[1])
+	case <-time.After(2 * time.Second):
+		t.Fatal("sticker frame was not sent")
+	}
+}
@@ -0,0 +1,25 @@
+package bridge
+
+import (
+	"context"
+
+	"github.com/user/opencode-telegram/internal/buildinfo"
+)
+
+// HandleVersionCommand handles /version: shows which build of the bridge
+// is running
+func (b *Bridge) HandleVersionCommand(ctx context.Context, args string) error {
+	_, err := b.tgBot.SendMessage(ctx, b.versionText(buildinfo.Get()))
+	return err
+}
+
+func (b *Bridge) versionText(info buildinfo.Info) string {
+	commit, date := info.Commit, info.Date
+	if commit == "" {
+		commit = "-"
+	}
+	if date == "" {
+		date = "-"
+	}
+	return b.t("version.info", info.Version, commit, date, info.GoVersion)
+}
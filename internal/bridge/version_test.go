@@ -0,0 +1,20 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/user/opencode-telegram/internal/buildinfo"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+func TestVersionText(t *testing.T) {
+	bridge := NewBridge(new(MockOpenCodeClient), NewMockTelegramBot(), state.NewAppStateForTest(), state.NewIDRegistry(), 10*time.Millisecond)
+
+	assert.Equal(t, "🏷 <b>opencode-telegram v1.2.3</b>\nCommit: <code>abc1234</code>\nBuilt: 2026-01-02T03:04:05Z\nGo: go1.25.6",
+		bridge.versionText(buildinfo.Info{Version: "v1.2.3", Commit: "abc1234", Date: "2026-01-02T03:04:05Z", GoVersion: "go1.25.6"}))
+	assert.Equal(t, "🏷 <b>opencode-telegram dev</b>\nCommit: <code>-</code>\nBuilt: -\nGo: go1.25.6",
+		bridge.versionText(buildinfo.Info{Version: "dev", GoVersion: "go1.25.6"}))
+}
@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+// FrameExtractor pulls still frames out of GIFs and videos
+type FrameExtractor interface {
+	Extract(ctx context.Context, data []byte, duration int) ([][]byte, error)
+}
+
+// SetFrameExtractor enables keyframe extraction for GIFs and videos.
+// Without an extractor, only Telegram's thumbnail is sent.
+func (b *Bridge) SetFrameExtractor(extractor FrameExtractor) {
+	b.frames = extractor
+}
+
+// HandleVideoMessage sends keyframes of a GIF, video, or video note to
+// OpenCode as image parts, with the caption as the instruction
+func (b *Bridge) HandleVideoMessage(ctx context.Context, video telegram.VideoFile, caption string, botToken string) error {
+	if b.frames == nil && video.Thumbnail == nil {
+		return b.HandleUnsupportedMedia(ctx)
+	}
+
+	if video.FileSize > telegram.MaxDownloadSize {
+		_, err := b.tgBot.SendMessage(ctx, b.t("video.too_large", telegram.MaxDownloadSize/(1024*1024)))
+		return err
+	}
+
+	sessionID, thinkingMsgID, started, err := b.beginMediaPrompt(ctx, b.t("processing.video"))
+	if !started {
+		return err
+	}
+
+	go b.extractAndPrompt(context.Background(), sessionID, video, caption, botToken, thinkingMsgID)
+	return nil
+}
+
+func (b *Bridge) extractAndPrompt(ctx context.Context, sessionID string, video telegram.VideoFile, caption string, botToken string, thinkingMsgID int) {
+	frames, err := b.videoFrames(ctx, video, botToken)
+	if err != nil {
+		b.failPrompt(sessionID, thinkingMsgID, b.t("video.failed", err.Error()))
+		return
+	}
+
+	b.logger.Info("Extracted video frames", "session", sessionID, "frames", len(frames), "file", video.FileID, "duration", video.Duration)
+
+	b.sendImagePromptAsync(ctx, sessionID, frames, formatVideoPrompt(video, caption, len(frames)), thinkingMsgID)
+}
+
+// videoFrames extracts keyframes from the full clip, falling back to
+// Telegram's thumbnail when no extractor is set or extraction fails
+func (b *Bridge) videoFrames(ctx context.Context, video telegram.VideoFile, botToken string) ([][]byte, error) {
+	if b.frames != nil {
+		frames, err := b.extractFrames(ctx, video, botToken)
+		if err == nil && len(frames) > 0 {
+			return frames, nil
+		}
+		if video.Thumbnail == nil {
+			return nil, err
+		}
+		b.logger.Warn("Keyframe extraction failed, using thumbnail", "error", err)
+	}
+
+	thumb, err := b.downloadFile(ctx, botToken, video.Thumbnail.FileID)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{thumb}, nil
+}
+
+func (b *Bridge) extractFrames(ctx context.Context, video telegram.VideoFile, botToken string) ([][]byte, error) {
+	data, err := b.downloadFile(ctx, botToken, video.FileID)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := b.frames.Extract(ctx, data, video.Duration)
+	if err == nil && len(frames) == 0 {
+		err = fmt.Errorf("no frames extracted")
+	}
+	return frames, err
+}
+
+// formatVideoPrompt builds the text part sent alongside the frames
+// Example:
+//
+//	What is going wrong in this recording?
+//
+//	[Video "bug.mp4" (0:42), 3 frames]
+func formatVideoPrompt(video telegram.VideoFile, caption string, frameCount int) string {
+	header := "[Video"
+	if video.Animation {
+		header = "[GIF"
+	}
+	if video.FileName != "" {
+		header += fmt.Sprintf(" %q", video.FileName)
+	}
+	if video.Duration > 0 {
+		header += fmt.Sprintf(" (%d:%02d)", video.Duration/60, video.Duration%60)
+	}
+	if frameCount == 1 {
+		header += ", 1 frame]"
+	} else {
+		header += fmt.Sprintf(", %d frames]", frameCount)
+	}
+
+	if caption = strings.TrimSpace(caption); caption != "" {
+		return caption + "\n\n" + header
+	}
+	return header
+}
@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+	"github.com/user/opencode-telegram/internal/telegram"
+)
+
+type stubExtractor struct {
+	frames [][]byte
+	err    error
+}
+
+func (s stubExtractor) Extract(ctx context.Context, data []byte, duration int) ([][]byte, error) {
+	return s.frames, s.err
+}
+
+func TestFormatVideoPrompt(t *testing.T) {
+	gif := telegram.VideoFile{Duration: 3, Animation: true}
+	assert.Equal(t, "[GIF (0:03), 3 frames]", formatVideoPrompt(gif, "", 3))
+
+	video := telegram.VideoFile{FileName: "bug.mp4", Duration: 42}
+	assert.Equal(t, "What is going wrong?\n\n[Video \"bug.mp4\" (0:42), 1 frame]", formatVideoPrompt(video, " What is going wrong? ", 1))
+}
+
+func TestHandleVideoWithoutExtractorOrThumbnail(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "⚠️ This media type is not supported yet").Return(1, nil)
+
+	err := bridge.HandleVideoMessage(ctx, telegram.VideoFile{FileID: "v1"}, "", "token")
+
+	assert.NoError(t, err)
+	mockTG.AssertExpectations(t)
+	mockOC.AssertNotCalled(t, "CreateSession")
+}
+
+func TestHandleVideoTooLarge(t *testing.T) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	bridge := NewBridge(mockOC, mockTG, state.NewAppStateForTest(), state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.SetFrameExtractor(stubExtractor{})
+	ctx := context.Background()
+
+	mockTG.On("SendMessage", ctx, "❌ Video is too large (max 20 MB)").Return(1, nil)
+
+	err := bridge.HandleVideoMessage(ctx, telegram.VideoFile{FileID: "v1", FileSize: 80 * 1024 * 1024}, "", "token")
+
+	assert.NoError(t, err)
+	mockTG.AssertExpectations(t)
+	mockOC.AssertNotCalled(t, "CreateSession")
+}
+
+func videoBridge(sessionID string) (*Bridge, *MockOpenCodeClient, chan []interface{}) {
+	mockOC := new(MockOpenCodeClient)
+	mockTG := NewMockTelegramBot()
+	appState := state.NewAppStateForTest()
+	appState.SetCurrentSession(sessionID)
+	bridge := NewBridge(mockOC, mockTG, appState, state.NewIDRegistry(), 100*time.Millisecond)
+	bridge.downloadFile = func(ctx context.Context, botToken, fileID string) ([]byte, error) {
+		return []byte(fileID), nil
+	}
+
+	sent := make(chan []interface{}, 1)
+	mockTG.On("SendMessage", mock.Anything, "🎞️ Extracting video frames...").Return(1, nil).Once()
+	mockTG.On("SendTyping", mock.Anything).Return(nil)
+	mockOC.On("SendPromptWithParts", sessionID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sent <- args.Get(1).([]interface{}) }).
+		Return(&opencode.SendPromptResponse{}, nil).Once()
+
+	return bridge, mockOC, sent
+}
+
+func TestHandleVideoSendsKeyframes(t *testing.T) {
+	bridge, _, sent := videoBridge("ses_video")
+	bridge.SetFrameExtractor(stubExtractor{frames: [][]byte{[]byte("f1"), []byte("f2"), []byte("f3")}})
+
+	video := telegram.VideoFile{FileID: "v1", Duration: 9, Animation: true}
+	assert.NoError(t, bridge.HandleVideoMessage(context.Background(), video, "Explain this", "token"))
+
+	select {
+	case parts := <-sent:
+		assert.Len(t, parts, 4)
+		for i := 0; i < 3; i++ {
+			_, ok := parts[i].(opencode.ImagePartInput)
+			assert.True(t, ok, "part %d should be an image", i)
+		}
+		assert.Equal(t, opencode.TextPartInput{Type: "text", Text: "Explain this\n\n[GIF (0:09), 3 frames]"}, parts[3])
+	case <-time.After(2 * time.Second):
+		t.Fatal("video frames were not sent")
+	}
+}
+
+func TestHandleVideoFallsBackToThumbnail(t *testing.T) {
+	bridge, _, sent := videoBridge("ses_thumb")
+	bridge.SetFrameExtractor(stubExtractor{err: errors.New("ffmpeg: exit status 1")})
+
+	video := telegram.VideoFile{FileID: "v1", Duration: 30, Thumbnail: &models.PhotoSize{FileID: "thumb"}}
+	assert.NoError(t, bridge.HandleVideoMessage(context.Background(), video, "", "token"))
+
+	select {
+	case parts := <-sent:
+		assert.Len(t, parts, 2)
+		img, ok := parts[0].(opencode.ImagePartInput)
+		if assert.True(t, ok) {
+			assert.Equal(t, telegram.EncodeBase64([]byte("thumb")), img.Image)
+		}
+		assert.Equal(t, opencode.TextPartInput{Type: "text", Text: "[Video (0:30), 1 frame]"}, parts[1])
+	case <-time.After(2 * time.Second):
+		t.Fatal("thumbnail was not sent")
+	}
+}
@@ -0,0 +1,73 @@
+// Package buildinfo tells which build of the bridge is running. The values
+// are set at build time:
+//
+//	go build -ldflags "-X github.com/user/opencode-telegram/internal/buildinfo.Version=v1.2.3 \
+//	  -X github.com/user/opencode-telegram/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/user/opencode-telegram/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//	  -o opencode-telegram ./cmd
+//
+// Without them, the commit and date come from the version control
+// information the go command embeds when building from a checkout.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes a build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the running build
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.fill(bi.Settings)
+	}
+	return info
+}
+
+// fill takes the commit and date that were not set at build time from the
+// embedded version control settings
+func (i *Info) fill(settings []debug.BuildSetting) {
+	for _, s := range settings {
+		switch s.Key {
+		case "vcs.revision":
+			if i.Commit == "" {
+				i.Commit = s.Value
+				if len(i.Commit) > 12 {
+					i.Commit = i.Commit[:12]
+				}
+			}
+		case "vcs.time":
+			if i.Date == "" {
+				i.Date = s.Value
+			}
+		}
+	}
+}
+
+// String formats the build as e.g. "v1.2.3 (commit abc123, built
+// 2026-01-02T03:04:05Z, go1.25.6)"
+func (i Info) String() string {
+	s := i.Version + " ("
+	if i.Commit != "" {
+		s += fmt.Sprintf("commit %s, ", i.Commit)
+	}
+	if i.Date != "" {
+		s += fmt.Sprintf("built %s, ", i.Date)
+	}
+	return s + i.GoVersion + ")"
+}
@@ -0,0 +1,24 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFill(t *testing.T) {
+	info := Info{Version: "v1.2.3", Date: "2026-01-02T03:04:05Z", GoVersion: "go1.25.6"}
+	info.fill([]debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+		{Key: "vcs.time", Value: "2025-12-31T00:00:00Z"},
+	})
+
+	if info.Commit != "0123456789ab" {
+		t.Errorf("commit = %q, want the short embedded revision", info.Commit)
+	}
+	if info.Date != "2026-01-02T03:04:05Z" {
+		t.Errorf("date = %q, want the one set at build time", info.Date)
+	}
+	if want := "v1.2.3 (commit 0123456789ab, built 2026-01-02T03:04:05Z, go1.25.6)"; info.String() != want {
+		t.Errorf("String() = %q, want %q", info.String(), want)
+	}
+}
@@ -2,16 +2,30 @@ package config
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/logging"
 )
 
+var logger = logging.For("config")
+
 // AccountConfig represents a single bot account configuration
 type AccountConfig struct {
 	Token  string `json:"token"`
 	ChatID int64  `json:"chat_id"`
 	Name   string `json:"name"` // Optional label for the account
+
+	// Optional settings of this account, instead of the global ones
+	Directory   string `json:"directory"`    // OpenCode directory (OPENCODE_DIRECTORY)
+	Agent       string `json:"agent"`        // agent of chats that have not picked one
+	DebounceMs  int    `json:"debounce_ms"`  // TELEGRAM_DEBOUNCE_MS
+	WebhookPort string `json:"webhook_port"` // TELEGRAM_WEBHOOK_PORT
+	Proxy       string `json:"proxy"`        // TELEGRAM_PROXY, "none" to connect directly
 }
 
 // ParseAccountConfigs parses bot accounts from environment variables
@@ -23,23 +37,40 @@ func ParseAccountConfigs() ([]AccountConfig, error) {
 	if accountsJSON != "" {
 		var accounts []AccountConfig
 		if err := json.Unmarshal([]byte(accountsJSON), &accounts); err != nil {
-			return nil, err
+			return nil, fmt.Errorf("parse TELEGRAM_ACCOUNTS: %w", err)
 		}
 
 		// Enforce max 5 accounts
 		if len(accounts) > 5 {
-			log.Printf("Warning: More than 5 accounts configured, limiting to 5")
+			logger.Warn("More than 5 accounts configured, limiting to 5")
 			accounts = accounts[:5]
 		}
 
-		// Validate each account
+		// Validate each account, reporting every problem
+		var errs []error
 		for i, acc := range accounts {
 			if acc.Token == "" {
-				log.Fatalf("Account %d: missing token", i)
+				errs = append(errs, fmt.Errorf("account %d: missing token", i))
 			}
 			if acc.ChatID == 0 {
-				log.Fatalf("Account %d: missing or invalid chat_id", i)
+				errs = append(errs, fmt.Errorf("account %d: missing or invalid chat_id", i))
+			}
+			if acc.DebounceMs < 0 || acc.DebounceMs > 3000 {
+				errs = append(errs, fmt.Errorf("account %d: debounce_ms must be between 0 and 3000", i))
+			}
+			if acc.WebhookPort != "" {
+				if port, err := strconv.Atoi(acc.WebhookPort); err != nil || port < 1 || port > 65535 {
+					errs = append(errs, fmt.Errorf("account %d: invalid webhook_port %q", i, acc.WebhookPort))
+				}
 			}
+			if acc.Proxy != "" && acc.Proxy != "none" {
+				if u, err := url.Parse(acc.Proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+					errs = append(errs, fmt.Errorf("account %d: proxy must be an http://, https:// or socks5:// URL", i))
+				}
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
 		}
 
 		return accounts, nil
@@ -55,7 +86,7 @@ func ParseAccountConfigs() ([]AccountConfig, error) {
 
 	chatID, err := strconv.ParseInt(chatIDStr, 10, 64)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid TELEGRAM_CHAT_ID %q", chatIDStr)
 	}
 
 	return []AccountConfig{
@@ -66,3 +97,13 @@ func ParseAccountConfigs() ([]AccountConfig, error) {
 		},
 	}, nil
 }
+
+// FileFor derives this account's own copy of a per-account file (session
+// state, update offset) from the configured path, so that several accounts
+// do not overwrite each other: "<path>-<bot ID>-<chat ID>". The bot ID is
+// the part of the token before the colon, which stays the same when
+// accounts are renamed or reordered.
+func (a AccountConfig) FileFor(path string) string {
+	botID, _, _ := strings.Cut(a.Token, ":")
+	return fmt.Sprintf("%s-%s-%d", path, botID, a.ChatID)
+}
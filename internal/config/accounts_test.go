@@ -59,6 +59,58 @@ func TestParseAccountConfigsInvalidJSON(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestParseAccountConfigsMissingFields(t *testing.T) {
+	oldAccounts := os.Getenv("TELEGRAM_ACCOUNTS")
+	defer os.Setenv("TELEGRAM_ACCOUNTS", oldAccounts)
+
+	// An error rather than an exit, so a reload keeps the running bots
+	os.Setenv("TELEGRAM_ACCOUNTS", `[{"chat_id":111}]`)
+	_, err := ParseAccountConfigs()
+	assert.Error(t, err)
+
+	os.Setenv("TELEGRAM_ACCOUNTS", `[{"token":"t1"}]`)
+	_, err = ParseAccountConfigs()
+	assert.Error(t, err)
+	// Every problem is reported, not only the first
+	os.Setenv("TELEGRAM_ACCOUNTS", `[{"chat_id":111},{"token":"t2","debounce_ms":5000}]`)
+	_, err = ParseAccountConfigs()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account 0: missing token")
+	assert.Contains(t, err.Error(), "account 1: missing or invalid chat_id")
+	assert.Contains(t, err.Error(), "account 1: debounce_ms")
+}
+
+func TestParseAccountConfigsOverrides(t *testing.T) {
+	oldAccounts := os.Getenv("TELEGRAM_ACCOUNTS")
+	defer os.Setenv("TELEGRAM_ACCOUNTS", oldAccounts)
+
+	os.Setenv("TELEGRAM_ACCOUNTS", `[{"token":"t1","chat_id":1,"directory":"/srv/app","agent":"build","debounce_ms":2000,"webhook_port":"8444","proxy":"socks5://127.0.0.1:1080"}]`)
+	accounts, err := ParseAccountConfigs()
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "/srv/app", accounts[0].Directory)
+	assert.Equal(t, "build", accounts[0].Agent)
+	assert.Equal(t, 2000, accounts[0].DebounceMs)
+	assert.Equal(t, "8444", accounts[0].WebhookPort)
+	assert.Equal(t, "socks5://127.0.0.1:1080", accounts[0].Proxy)
+
+	// "none" connects an account directly despite TELEGRAM_PROXY
+	os.Setenv("TELEGRAM_ACCOUNTS", `[{"token":"t1","chat_id":1,"proxy":"none"}]`)
+	accounts, err = ParseAccountConfigs()
+	require.NoError(t, err)
+	assert.Equal(t, "none", accounts[0].Proxy)
+
+	for _, invalid := range []string{
+		`[{"token":"t1","chat_id":1,"debounce_ms":5000}]`,
+		`[{"token":"t1","chat_id":1,"webhook_port":"http"}]`,
+		`[{"token":"t1","chat_id":1,"proxy":"ftp://proxy"}]`,
+	} {
+		os.Setenv("TELEGRAM_ACCOUNTS", invalid)
+		_, err := ParseAccountConfigs()
+		assert.Error(t, err, invalid)
+	}
+}
+
 func TestParseAccountConfigsMaxLimit(t *testing.T) {
 	oldAccounts := os.Getenv("TELEGRAM_ACCOUNTS")
 	defer os.Setenv("TELEGRAM_ACCOUNTS", oldAccounts)
@@ -99,3 +151,12 @@ func TestParseAccountConfigsEmptyEnv(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, accounts, 0)
 }
+
+func TestAccountFileFor(t *testing.T) {
+	work := AccountConfig{Token: "123456:secret", ChatID: -100, Name: "work"}
+	personal := AccountConfig{Token: "654321:other", ChatID: 42, Name: "personal"}
+
+	assert.Equal(t, "~/.opencode-telegram-state-123456--100", work.FileFor("~/.opencode-telegram-state"))
+	assert.Equal(t, "~/.opencode-telegram-state-654321-42", personal.FileFor("~/.opencode-telegram-state"))
+	assert.NotContains(t, work.FileFor("/tmp/offset"), "secret", "the token secret must stay out of file names")
+}
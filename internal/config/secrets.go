@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// SecretVars are the settings that can be read from a file instead of the
+// environment, e.g. a Docker or Kubernetes secret: TELEGRAM_BOT_TOKEN_FILE
+// holds the path of the file with TELEGRAM_BOT_TOKEN. This keeps tokens out
+// of the environment of the container and of crash dumps.
+var SecretVars = []string{
+	"TELEGRAM_BOT_TOKEN",
+	"TELEGRAM_ACCOUNTS",
+	"TELEGRAM_WEBHOOK_SECRET",
+	"OPENCODE_API_KEY",
+	"OPENCODE_SERVERS",
+	"PLUGIN_WEBHOOK_TOKEN",
+	"TRANSCRIPTION_API_KEY",
+	"DEBUG_TOKEN",
+	"SENTRY_DSN",
+}
+
+// LoadSecretFiles sets each secret variable whose <NAME>_FILE is set from
+// that file, without its trailing newline; the file takes precedence over
+// the variable itself. It returns the names of the variables whose value
+// changed. When a file cannot be read, no variable is changed.
+func LoadSecretFiles() ([]string, error) {
+	values := make(map[string]string)
+	for _, name := range SecretVars {
+		path := os.Getenv(name + "_FILE")
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		values[name] = strings.TrimRight(string(data), "\r\n")
+	}
+
+	var changed []string
+	for _, name := range SecretVars {
+		value, ok := values[name]
+		if !ok || os.Getenv(name) == value {
+			continue
+		}
+		os.Setenv(name, value)
+		changed = append(changed, name)
+	}
+	return changed, nil
+}
+
+// WatchSecretFiles reloads the secret files every interval until ctx is
+// done, so that rotated secrets are picked up: Kubernetes updates mounted
+// secrets in place. The returned channel receives the names of the
+// variables that changed. A file that cannot be read is logged and the
+// previous values are kept.
+func WatchSecretFiles(ctx context.Context, interval time.Duration) <-chan []string {
+	changes := make(chan []string)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			changed, err := LoadSecretFiles()
+			if err != nil {
+				logger.Warn("Reloading secret files failed", "error", err)
+				continue
+			}
+			if len(changed) == 0 {
+				continue
+			}
+			select {
+			case changes <- changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes
+}
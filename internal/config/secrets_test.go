@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretFiles(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "bot_token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("123:secret\n"), 0600))
+	t.Setenv("TELEGRAM_BOT_TOKEN", "from-env")
+	t.Setenv("TELEGRAM_BOT_TOKEN_FILE", tokenFile)
+
+	changed, err := LoadSecretFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TELEGRAM_BOT_TOKEN"}, changed)
+	assert.Equal(t, "123:secret", os.Getenv("TELEGRAM_BOT_TOKEN"))
+
+	// Unchanged files report nothing
+	changed, err = LoadSecretFiles()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	// A missing file changes no variable
+	require.NoError(t, os.WriteFile(tokenFile, []byte("456:rotated"), 0600))
+	t.Setenv("OPENCODE_API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = LoadSecretFiles()
+	assert.ErrorContains(t, err, "OPENCODE_API_KEY_FILE")
+	assert.Equal(t, "123:secret", os.Getenv("TELEGRAM_BOT_TOKEN"))
+}
+
+func TestWatchSecretFiles(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "api_key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("old"), 0600))
+	t.Setenv("PLUGIN_WEBHOOK_TOKEN_FILE", keyFile)
+	_, err := LoadSecretFiles()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := WatchSecretFiles(ctx, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("new"), 0600))
+	select {
+	case changed := <-changes:
+		assert.Equal(t, []string{"PLUGIN_WEBHOOK_TOKEN"}, changed)
+		assert.Equal(t, "new", os.Getenv("PLUGIN_WEBHOOK_TOKEN"))
+	case <-time.After(time.Second):
+		t.Fatal("expected the rotated secret to be reported")
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ServerConfig describes one OpenCode server a chat can switch to
+type ServerConfig struct {
+	Name      string `json:"name"`
+	BaseURL   string `json:"base_url"`
+	Directory string `json:"directory"` // Optional, defaults to OPENCODE_DIRECTORY
+	APIKey    string `json:"api_key"`   // Optional, defaults to OPENCODE_API_KEY
+}
+
+// ParseServerConfigs parses OpenCode servers from OPENCODE_SERVERS (JSON array).
+// Returns nil when unset, meaning the single server from OPENCODE_BASE_URL.
+// The first server is the default for every chat.
+func ParseServerConfigs() ([]ServerConfig, error) {
+	serversJSON := os.Getenv("OPENCODE_SERVERS")
+	if serversJSON == "" {
+		return nil, nil
+	}
+
+	var servers []ServerConfig
+	if err := json.Unmarshal([]byte(serversJSON), &servers); err != nil {
+		return nil, fmt.Errorf("parse OPENCODE_SERVERS: %w", err)
+	}
+
+	seen := make(map[string]bool, len(servers))
+	for i, srv := range servers {
+		if srv.Name == "" {
+			return nil, fmt.Errorf("server %d: missing name", i)
+		}
+		if srv.BaseURL == "" {
+			return nil, fmt.Errorf("server %q: missing base_url", srv.Name)
+		}
+		if seen[srv.Name] {
+			return nil, fmt.Errorf("server %q: duplicate name", srv.Name)
+		}
+		seen[srv.Name] = true
+	}
+
+	return servers, nil
+}
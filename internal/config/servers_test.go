@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerConfigsUnset(t *testing.T) {
+	t.Setenv("OPENCODE_SERVERS", "")
+
+	servers, err := ParseServerConfigs()
+	require.NoError(t, err)
+	assert.Nil(t, servers)
+}
+
+func TestParseServerConfigs(t *testing.T) {
+	t.Setenv("OPENCODE_SERVERS", `[{"name":"laptop","base_url":"http://localhost:54321"},{"name":"buildbox","base_url":"https://build:4096","directory":"/srv/app","api_key":"k"}]`)
+
+	servers, err := ParseServerConfigs()
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	assert.Equal(t, ServerConfig{Name: "laptop", BaseURL: "http://localhost:54321"}, servers[0])
+	assert.Equal(t, ServerConfig{Name: "buildbox", BaseURL: "https://build:4096", Directory: "/srv/app", APIKey: "k"}, servers[1])
+}
+
+func TestParseServerConfigsInvalid(t *testing.T) {
+	for name, value := range map[string]string{
+		"bad json":       `{`,
+		"missing name":   `[{"base_url":"http://a"}]`,
+		"missing url":    `[{"name":"a"}]`,
+		"duplicate name": `[{"name":"a","base_url":"http://a"},{"name":"a","base_url":"http://b"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("OPENCODE_SERVERS", value)
+			_, err := ParseServerConfigs()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package errreport sends panics and error log lines to Sentry or to a
+// webhook, tagged with the account, chat and session they concern, so that
+// failures are noticed without watching the logs.
+package errreport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/buildinfo"
+	"github.com/user/opencode-telegram/internal/logging"
+)
+
+var logger = logging.For("errreport")
+
+const (
+	// queueSize bounds the reports waiting to be sent; more are dropped
+	queueSize = 100
+	// dedupWindow is how long the same error of the same component and
+	// account is reported only once, so a failure loop does not flood
+	dedupWindow = time.Minute
+	sendTimeout = 10 * time.Second
+)
+
+// TagKeys are the fields that become tags of a report, to search and group
+// by; the other fields, e.g. the error or a panic's stack, are its extra
+var TagKeys = []string{"component", "account", "chat", "session", "server", "type", "class"}
+
+// Report is one error to report
+type Report struct {
+	ID      string            `json:"id"`
+	Time    time.Time         `json:"time"`
+	Message string            `json:"message"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Extra   map[string]string `json:"extra,omitempty"`
+	Release string            `json:"release"`
+	Host    string            `json:"host,omitempty"`
+}
+
+// Sink is where reports go, e.g. Sentry or a webhook
+type Sink interface {
+	Send(ctx context.Context, report Report) error
+}
+
+// Reporter queues errors and sends them to its sink in the background, so
+// that reporting never holds up the code that failed
+type Reporter struct {
+	sink  Sink
+	queue chan Report
+	host  string
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+	now  func() time.Time
+}
+
+// New creates a reporter sending to sink once Run is started
+func New(sink Sink) *Reporter {
+	host, _ := os.Hostname()
+	return &Reporter{
+		sink:  sink,
+		queue: make(chan Report, queueSize),
+		host:  host,
+		seen:  make(map[string]time.Time),
+		now:   time.Now,
+	}
+}
+
+// Capture queues an error, e.g. from logging.SetErrorHook. It does not
+// block: duplicates within a minute and reports beyond a full queue are
+// dropped.
+func (r *Reporter) Capture(message string, fields map[string]string) {
+	now := r.now()
+	if !r.first(fields["component"]+"\x00"+fields["account"]+"\x00"+message, now) {
+		return
+	}
+
+	report := Report{
+		ID:      newID(),
+		Time:    now.UTC(),
+		Message: message,
+		Tags:    make(map[string]string),
+		Extra:   make(map[string]string),
+		Release: buildinfo.Get().Version,
+		Host:    r.host,
+	}
+	for key, value := range fields {
+		report.Extra[key] = value
+	}
+	for _, key := range TagKeys {
+		if value, ok := report.Extra[key]; ok {
+			report.Tags[key] = value
+			delete(report.Extra, key)
+		}
+	}
+
+	select {
+	case r.queue <- report:
+	default:
+		logger.Warn("Error report queue full, dropping report", "message", message)
+	}
+}
+
+// first tells whether key was not seen in the last dedupWindow
+func (r *Reporter) first(key string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, at := range r.seen {
+		if now.Sub(at) >= dedupWindow {
+			delete(r.seen, k)
+		}
+	}
+	if _, ok := r.seen[key]; ok {
+		return false
+	}
+	r.seen[key] = now
+	return true
+}
+
+// Run sends the queued reports until ctx is done
+func (r *Reporter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report := <-r.queue:
+			sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+			if err := r.sink.Send(sendCtx, report); err != nil {
+				// Not an error line, which would be reported in turn
+				logger.Warn("Sending error report failed", "message", report.Message, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// newID returns a random 32 hex digit ID, the form Sentry expects
+func newID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
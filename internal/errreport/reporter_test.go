@@ -0,0 +1,61 @@
+package errreport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sinkFunc func(ctx context.Context, report Report) error
+
+func (f sinkFunc) Send(ctx context.Context, report Report) error { return f(ctx, report) }
+
+func TestCapture(t *testing.T) {
+	sent := make(chan Report, 10)
+	r := New(sinkFunc(func(ctx context.Context, report Report) error {
+		sent <- report
+		return nil
+	}))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	fields := map[string]string{"component": "telegram", "account": "work", "chat": "42", "panic": "boom", "stack": "goroutine 1"}
+	r.Capture("Handler panicked", fields)
+	// The same error again within a minute is dropped, another account's is not
+	r.Capture("Handler panicked", fields)
+	r.Capture("Handler panicked", map[string]string{"component": "telegram", "account": "home"})
+
+	report := receive(t, sent)
+	assert.Len(t, report.ID, 32)
+	assert.Equal(t, now, report.Time)
+	assert.Equal(t, "Handler panicked", report.Message)
+	assert.Equal(t, map[string]string{"component": "telegram", "account": "work", "chat": "42"}, report.Tags)
+	assert.Equal(t, map[string]string{"panic": "boom", "stack": "goroutine 1"}, report.Extra)
+	assert.Equal(t, "home", receive(t, sent).Tags["account"])
+
+	now = now.Add(dedupWindow)
+	r.Capture("Handler panicked", fields)
+	assert.Equal(t, "work", receive(t, sent).Tags["account"], "reported again once the window passed")
+	select {
+	case report := <-sent:
+		t.Fatalf("unexpected report %+v", report)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func receive(t *testing.T, sent chan Report) Report {
+	t.Helper()
+	select {
+	case report := <-sent:
+		return report
+	case <-time.After(time.Second):
+		require.FailNow(t, "no report sent")
+		return Report{}
+	}
+}
@@ -0,0 +1,139 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/user/opencode-telegram/internal/buildinfo"
+)
+
+// Webhook posts each report as JSON to a URL
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a sink posting to url through client (nil: default)
+func NewWebhook(url string, client *http.Client) *Webhook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Webhook{url: url, client: client}
+}
+
+func (w *Webhook) Send(ctx context.Context, report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(w.client, req)
+}
+
+// Sentry sends reports as events to a Sentry project, through its envelope
+// endpoint
+type Sentry struct {
+	endpoint string
+	auth     string
+	dsn      string
+	client   *http.Client
+}
+
+// NewSentry creates a sink for the project of dsn, e.g.
+// https://<key>@o0.ingest.sentry.io/<project>, sending through client (nil:
+// default)
+func NewSentry(dsn string, client *http.Client) (*Sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	key := u.User.Username()
+	path := strings.TrimSuffix(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || key == "" || slash < 0 || slash == len(path)-1 {
+		return nil, fmt.Errorf("invalid Sentry DSN, expected https://<key>@<host>/<project>")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path[:slash] + "/api/" + path[slash+1:] + "/envelope/"}
+	return &Sentry{
+		endpoint: endpoint.String(),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=opencode-telegram/%s, sentry_key=%s", buildinfo.Get().Version, key),
+		dsn:      dsn,
+		client:   client,
+	}, nil
+}
+
+// sentryEvent is the part of Sentry's event payload that reports use
+type sentryEvent struct {
+	EventID    string            `json:"event_id"`
+	Timestamp  string            `json:"timestamp"`
+	Platform   string            `json:"platform"`
+	Level      string            `json:"level"`
+	Logger     string            `json:"logger,omitempty"`
+	Message    string            `json:"message"`
+	Release    string            `json:"release,omitempty"`
+	ServerName string            `json:"server_name,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Extra      map[string]string `json:"extra,omitempty"`
+}
+
+func (s *Sentry) Send(ctx context.Context, report Report) error {
+	event, err := json.Marshal(sentryEvent{
+		EventID:    report.ID,
+		Timestamp:  report.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		Platform:   "go",
+		Level:      "error",
+		Logger:     report.Tags["component"],
+		Message:    report.Message,
+		Release:    report.Release,
+		ServerName: report.Host,
+		Tags:       report.Tags,
+		Extra:      report.Extra,
+	})
+	if err != nil {
+		return err
+	}
+	header, err := json.Marshal(map[string]string{"event_id": report.ID, "dsn": s.dsn})
+	if err != nil {
+		return err
+	}
+
+	// An envelope is a header line followed by one item: its header, then
+	// its payload
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteString("\n{\"type\":\"event\"}\n")
+	body.Write(event)
+	body.WriteString("\n")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	return post(s.client, req)
+}
+
+func post(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testReport = Report{
+	ID:      "0123456789abcdef0123456789abcdef",
+	Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	Message: "Handling event failed",
+	Tags:    map[string]string{"component": "events", "session": "ses_1"},
+	Extra:   map[string]string{"error": "boom"},
+	Release: "v1.2.3",
+}
+
+func TestWebhook(t *testing.T) {
+	var got Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewWebhook(server.URL, nil).Send(context.Background(), testReport))
+	assert.Equal(t, testReport, got)
+}
+
+func TestSentry(t *testing.T) {
+	var lines [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sentry/api/42/envelope/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+		body, _ := io.ReadAll(r.Body)
+		lines = bytes.Split(bytes.TrimSpace(body), []byte("\n"))
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public@", 1) + "/sentry/42"
+	sink, err := NewSentry(dsn, nil)
+	require.NoError(t, err)
+	require.NoError(t, sink.Send(context.Background(), testReport))
+
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"type":"event"}`, string(lines[1]))
+	var event sentryEvent
+	require.NoError(t, json.Unmarshal(lines[2], &event))
+	assert.Equal(t, testReport.ID, event.EventID)
+	assert.Equal(t, "events", event.Logger)
+	assert.Equal(t, testReport.Tags, event.Tags)
+	assert.Equal(t, testReport.Extra, event.Extra)
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	assert.Error(t, sink.Send(context.Background(), testReport))
+}
+
+func TestNewSentryInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"", "sentry.io/42", "https://sentry.io/42", "https://key@sentry.io/"} {
+		_, err := NewSentry(dsn, nil)
+		assert.Error(t, err, dsn)
+	}
+}
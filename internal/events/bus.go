@@ -0,0 +1,265 @@
+// Package events fans OpenCode events out from their sources (the SSE
+// stream or the plugin webhook) to every bridge that subscribes.
+package events
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/logging"
+	"github.com/user/opencode-telegram/internal/metrics"
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+var logger = logging.For("events")
+
+// AnyType is the Handlers key for events without a handler of their own
+const AnyType = "*"
+
+// subscriberBuffer is how many events a subscriber may lag behind before
+// Publish waits for it
+const subscriberBuffer = 256
+
+// Handler handles one event. An error means the event was not (fully)
+// delivered and is reported to the bus's failure handler.
+type Handler func(event opencode.Event) error
+
+// Handlers maps event types to handlers
+type Handlers map[string]Handler
+
+func (h Handlers) lookup(eventType string) Handler {
+	if handler, ok := h[eventType]; ok {
+		return handler
+	}
+	return h[AnyType]
+}
+
+// maxSessionDepth bounds the walk up from a subagent session to its root
+const maxSessionDepth = 16
+
+type subscriber struct {
+	handlers Handlers
+	events   chan opencode.Event
+	done     <-chan struct{}
+
+	// Session routing (owns nil: every event)
+	owns     func(sessionID string) bool
+	fallback bool
+}
+
+// Bus delivers published events to subscribers. Each subscriber runs on its
+// own goroutine and sees events in publish order, so a slow chat does not
+// hold up the others until its buffer fills.
+//
+// Events of a session go to the subscribers owning it, or the session's
+// parent for subagent sessions; events without a session go to everyone.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+
+	parentsMu sync.RWMutex
+	parents   map[string]string // child session -> parent session
+
+	onFailure func(event opencode.Event, err error)
+
+	pending atomic.Int64 // events handed to subscribers and not yet handled
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+	return &Bus{
+		subs:    make(map[*subscriber]struct{}),
+		parents: make(map[string]string),
+	}
+}
+
+// SetFailureHandler is called when a handler fails or panics, e.g. to keep
+// the event for a later replay. Call before publishing.
+func (b *Bus) SetFailureHandler(onFailure func(event opencode.Event, err error)) {
+	b.onFailure = onFailure
+}
+
+// Subscribe calls handlers for every published event until ctx is done
+func (b *Bus) Subscribe(ctx context.Context, handlers Handlers) {
+	b.subscribe(ctx, &subscriber{handlers: handlers})
+}
+
+// SubscribeSessions calls handlers until ctx is done for events of the
+// sessions owns accepts and for events without a session. A fallback
+// subscriber also gets the events of sessions no subscriber owns, such as
+// ones started from the TUI.
+func (b *Bus) SubscribeSessions(ctx context.Context, handlers Handlers, owns func(sessionID string) bool, fallback bool) {
+	b.subscribe(ctx, &subscriber{handlers: handlers, owns: owns, fallback: fallback})
+}
+
+func (b *Bus) subscribe(ctx context.Context, sub *subscriber) {
+	sub.events = make(chan opencode.Event, subscriberBuffer)
+	sub.done = ctx.Done()
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		defer func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			b.mu.Unlock()
+			b.discard(sub)
+		}()
+		for {
+			select {
+			case event := <-sub.events:
+				b.dispatch(sub.handlers.lookup(event.Type), event)
+				b.addPending(-1)
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+}
+
+// discard drops the events a stopped subscriber left in its buffer, so
+// they no longer count as pending and Drain does not wait for them. Call
+// once sub is out of b.subs: no Publish can add to the buffer after that.
+func (b *Bus) discard(sub *subscriber) {
+	var dropped int64
+	for {
+		select {
+		case <-sub.events:
+			dropped++
+		default:
+			if dropped > 0 {
+				b.addPending(-dropped)
+				logger.Warn("Subscriber stopped with events unhandled", "dropped", dropped)
+			}
+			return
+		}
+	}
+}
+
+// addPending counts events handed to subscribers, and handled (n < 0)
+func (b *Bus) addPending(n int64) {
+	b.pending.Add(n)
+	metrics.EventBusPending.Add(float64(n))
+}
+
+// dispatch runs a handler, turning a panic into a failure so one bad event
+// does not take the subscriber down
+func (b *Bus) dispatch(handle Handler, event opencode.Event) {
+	metrics.ObserveEventLag(event.Type, event.Timestamp)
+	var stack []byte
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+				stack = debug.Stack()
+			}
+		}()
+		return handle(event)
+	}()
+	if err == nil {
+		return
+	}
+	if stack != nil {
+		logger.Error("Handling event failed", "type", event.Type, "session", event.SessionID(), "error", err, "stack", string(stack))
+	} else {
+		logger.Error("Handling event failed", "type", event.Type, "session", event.SessionID(), "error", err)
+	}
+	if b.onFailure != nil {
+		b.onFailure(event, err)
+	}
+}
+
+// Publish hands event to the subscribers it is routed to. It waits while a
+// subscriber's buffer is full, pushing back on the source instead of
+// dropping the event.
+func (b *Bus) Publish(event opencode.Event) {
+	sessionID := event.SessionID()
+	b.learnParent(event, sessionID)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.targets(event.Type, sessionID) {
+		b.addPending(1)
+		select {
+		case sub.events <- event:
+		case <-sub.done:
+			b.addPending(-1)
+		}
+	}
+}
+
+// drainPoll is how often Drain checks for outstanding events
+const drainPoll = 10 * time.Millisecond
+
+// Drain waits until every published event has been handled, e.g. on
+// shutdown once the sources have stopped
+func (b *Bus) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainPoll)
+	defer ticker.Stop()
+	for b.pending.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d events not handled: %w", b.pending.Load(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// targets returns the subscribers an event goes to
+func (b *Bus) targets(eventType, sessionID string) []*subscriber {
+	var targets, owners, fallbacks []*subscriber
+	for sub := range b.subs {
+		switch {
+		case sub.handlers.lookup(eventType) == nil:
+		case sub.owns == nil || sessionID == "":
+			targets = append(targets, sub)
+		case b.owned(sub, sessionID):
+			owners = append(owners, sub)
+		case sub.fallback:
+			fallbacks = append(fallbacks, sub)
+		}
+	}
+	if len(owners) == 0 {
+		owners = fallbacks
+	}
+	return append(targets, owners...)
+}
+
+// owned reports whether sub owns the session or one of its ancestors
+func (b *Bus) owned(sub *subscriber, sessionID string) bool {
+	b.parentsMu.RLock()
+	defer b.parentsMu.RUnlock()
+	for depth := 0; sessionID != "" && depth < maxSessionDepth; depth++ {
+		if sub.owns(sessionID) {
+			return true
+		}
+		sessionID = b.parents[sessionID]
+	}
+	return false
+}
+
+// learnParent remembers subagent sessions' parents from session events
+func (b *Bus) learnParent(event opencode.Event, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	parent := event.ParentSessionID()
+	if parent == "" && event.Type != "session.deleted" {
+		return
+	}
+
+	b.parentsMu.Lock()
+	defer b.parentsMu.Unlock()
+	if event.Type == "session.deleted" {
+		delete(b.parents, sessionID)
+	} else {
+		b.parents[sessionID] = parent
+	}
+}
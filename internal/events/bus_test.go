@@ -0,0 +1,279 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// recorder collects the types of the events it handles
+type recorder struct {
+	mu    sync.Mutex
+	types []string
+}
+
+func (r *recorder) handle(event opencode.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types = append(r.types, event.Type)
+	return nil
+}
+
+func (r *recorder) waitFor(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		if len(r.types) >= n {
+			types := append([]string(nil), r.types...)
+			r.mu.Unlock()
+			return types
+		}
+		r.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timeout waiting for %d events", n)
+	return nil
+}
+
+func TestBus_EverySubscriberGetsEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewBus()
+	var first, second recorder
+	bus.Subscribe(ctx, Handlers{AnyType: first.handle})
+	bus.Subscribe(ctx, Handlers{AnyType: second.handle})
+
+	bus.Publish(opencode.Event{Type: "session.idle"})
+	bus.Publish(opencode.Event{Type: "permission.asked"})
+
+	for _, r := range []*recorder{&first, &second} {
+		got := r.waitFor(t, 2)
+		if got[0] != "session.idle" || got[1] != "permission.asked" {
+			t.Errorf("Expected events in publish order, got %v", got)
+		}
+	}
+}
+
+func TestBus_PerTypeHandlers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewBus()
+	var permissions, rest recorder
+	bus.Subscribe(ctx, Handlers{
+		"permission.asked": permissions.handle,
+		AnyType:            rest.handle,
+	})
+
+	bus.Publish(opencode.Event{Type: "permission.asked"})
+	bus.Publish(opencode.Event{Type: "session.idle"})
+
+	if got := permissions.waitFor(t, 1); got[0] != "permission.asked" {
+		t.Errorf("Expected permission.asked, got %v", got)
+	}
+	if got := rest.waitFor(t, 1); got[0] != "session.idle" {
+		t.Errorf("Expected session.idle, got %v", got)
+	}
+}
+
+func TestBus_UnsubscribesWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus := NewBus()
+	bus.Subscribe(ctx, Handlers{"session.idle": func(opencode.Event) error { return nil }})
+	cancel()
+
+	// Publishing far past the buffer must not block on the gone subscriber
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*subscriberBuffer; i++ {
+			bus.Publish(opencode.Event{Type: "session.idle"})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a cancelled subscriber")
+	}
+}
+
+func sessionEvent(eventType, sessionID string) opencode.Event {
+	return opencode.Event{Type: eventType, Properties: map[string]interface{}{"sessionID": sessionID}}
+}
+
+func owner(ids ...string) func(string) bool {
+	return func(sessionID string) bool {
+		for _, id := range ids {
+			if id == sessionID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestBus_RoutesSessionsToTheirOwner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewBus()
+	var first, second, all recorder
+	bus.SubscribeSessions(ctx, Handlers{AnyType: first.handle}, owner("ses_a"), true)
+	bus.SubscribeSessions(ctx, Handlers{AnyType: second.handle}, owner("ses_b"), false)
+	bus.Subscribe(ctx, Handlers{AnyType: all.handle})
+
+	bus.Publish(sessionEvent("permission.asked", "ses_b"))
+	bus.Publish(sessionEvent("session.idle", "ses_tui"))
+	bus.Publish(opencode.Event{Type: "server.connected"})
+
+	if got := first.waitFor(t, 2); got[0] != "session.idle" || got[1] != "server.connected" {
+		t.Errorf("Expected the fallback to get the unowned session and the server event, got %v", got)
+	}
+	if got := second.waitFor(t, 2); got[0] != "permission.asked" || got[1] != "server.connected" {
+		t.Errorf("Expected the owner to get its session and the server event, got %v", got)
+	}
+	all.waitFor(t, 3)
+
+	time.Sleep(20 * time.Millisecond)
+	if n := len(first.waitFor(t, 2)); n != 2 {
+		t.Errorf("Expected the fallback not to get owned sessions, got %d events", n)
+	}
+}
+
+func TestBus_RoutesSubagentSessionsToParentOwner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewBus()
+	var fallback, owner2 recorder
+	bus.SubscribeSessions(ctx, Handlers{AnyType: fallback.handle}, owner(), true)
+	bus.SubscribeSessions(ctx, Handlers{"permission.asked": owner2.handle}, owner("ses_parent"), false)
+
+	bus.Publish(opencode.Event{Type: "session.created", Properties: map[string]interface{}{
+		"info": map[string]interface{}{"id": "ses_child", "parentID": "ses_parent"},
+	}})
+	bus.Publish(sessionEvent("permission.asked", "ses_child"))
+
+	if got := owner2.waitFor(t, 1); got[0] != "permission.asked" {
+		t.Errorf("Expected the parent's owner to get the subagent permission, got %v", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	fallback.mu.Lock()
+	defer fallback.mu.Unlock()
+	for _, typ := range fallback.types {
+		if typ == "permission.asked" {
+			t.Error("Expected the fallback not to get the subagent permission")
+		}
+	}
+}
+
+func TestBus_ReportsFailuresAndPanics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failed := make(chan string, 2)
+	bus := NewBus()
+	bus.SetFailureHandler(func(event opencode.Event, err error) {
+		failed <- event.Type + ": " + err.Error()
+	})
+	var after recorder
+	bus.Subscribe(ctx, Handlers{
+		"permission.asked": func(opencode.Event) error { return errors.New("telegram down") },
+		"session.idle":     func(opencode.Event) error { panic("boom") },
+		AnyType:            after.handle,
+	})
+
+	bus.Publish(opencode.Event{Type: "permission.asked"})
+	bus.Publish(opencode.Event{Type: "session.idle"})
+	bus.Publish(opencode.Event{Type: "server.connected"})
+
+	for _, want := range []string{"permission.asked: telegram down", "session.idle: panic: boom"} {
+		select {
+		case got := <-failed:
+			if got != want {
+				t.Errorf("Expected failure %q, got %q", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for failure %q", want)
+		}
+	}
+	// The subscriber survives the panic
+	after.waitFor(t, 1)
+}
+
+func TestBus_DrainWaitsForHandlers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewBus()
+	var handled atomic.Int32
+	bus.Subscribe(ctx, Handlers{AnyType: func(opencode.Event) error {
+		time.Sleep(10 * time.Millisecond)
+		handled.Add(1)
+		return nil
+	}})
+	for i := 0; i < 5; i++ {
+		bus.Publish(opencode.Event{Type: "session.idle"})
+	}
+
+	drainCtx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stop()
+	if err := bus.Drain(drainCtx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if got := handled.Load(); got != 5 {
+		t.Errorf("Expected 5 events handled, got %d", got)
+	}
+}
+
+func TestBus_DrainGivesUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewBus()
+	release := make(chan struct{})
+	defer close(release)
+	bus.Subscribe(ctx, Handlers{AnyType: func(opencode.Event) error {
+		<-release
+		return nil
+	}})
+	bus.Publish(opencode.Event{Type: "session.idle"})
+
+	drainCtx, stop := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer stop()
+	if err := bus.Drain(drainCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
+
+func TestBus_DrainSkipsStoppedSubscribers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus := NewBus()
+	release := make(chan struct{})
+	bus.Subscribe(ctx, Handlers{AnyType: func(opencode.Event) error {
+		<-release
+		return nil
+	}})
+	for i := 0; i < 4; i++ {
+		bus.Publish(opencode.Event{Type: "session.idle"})
+	}
+
+	// The subscriber stops with events still buffered
+	cancel()
+	close(release)
+
+	drainCtx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	if err := bus.Drain(drainCtx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+}
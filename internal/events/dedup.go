@@ -0,0 +1,144 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// DefaultDedupWindow is how long a source may lag behind another before
+// its copy of an event is no longer recognized as a duplicate
+const DefaultDedupWindow = time.Minute
+
+// Dedup merges several sources delivering the same OpenCode events, such as
+// the SSE stream and the plugin webhook, into one publisher, so either can
+// degrade without the bridge missing or repeating events.
+//
+// Events are keyed on (type, session, message) plus what tells apart events
+// within a message (the part and its delta, or the request ID). Identical
+// keys are counted per source: the n-th event with a key is published by
+// whichever source delivers it first and dropped from the others, so
+// legitimately repeated events (e.g. the same delta twice) still pass.
+type Dedup struct {
+	next   opencode.Publisher
+	window time.Duration
+
+	mu        sync.Mutex
+	sources   int
+	seen      map[string]*dedupEntry
+	lastSweep time.Time
+	now       func() time.Time // for tests
+}
+
+type dedupEntry struct {
+	counts []int // events seen per source
+	last   time.Time
+}
+
+// NewDedup creates a Dedup publishing to next. Keys unseen for window are
+// forgotten (0: DefaultDedupWindow).
+func NewDedup(next opencode.Publisher, window time.Duration) *Dedup {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	return &Dedup{
+		next:   next,
+		window: window,
+		seen:   make(map[string]*dedupEntry),
+		now:    time.Now,
+	}
+}
+
+// Source returns the publisher for one event source; name labels the
+// events_deduplicated_total metric
+func (d *Dedup) Source(name string) opencode.Publisher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sources++
+	return &dedupSource{dedup: d, index: d.sources - 1, name: name}
+}
+
+type dedupSource struct {
+	dedup *Dedup
+	index int
+	name  string
+}
+
+func (s *dedupSource) Publish(event opencode.Event) {
+	if !s.dedup.first(s.index, event) {
+		metrics.EventsDeduplicated.WithLabelValues(s.name, event.Type).Inc()
+		return
+	}
+	s.dedup.next.Publish(event)
+}
+
+// first counts event for source and reports whether no other source has
+// delivered it yet
+func (d *Dedup) first(source int, event opencode.Event) bool {
+	key := dedupKey(event)
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if now.Sub(d.lastSweep) >= d.window {
+		for k, entry := range d.seen {
+			if now.Sub(entry.last) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	entry, ok := d.seen[key]
+	if !ok {
+		entry = &dedupEntry{counts: make([]int, d.sources)}
+		d.seen[key] = entry
+	}
+	for len(entry.counts) < d.sources {
+		entry.counts = append(entry.counts, 0)
+	}
+	entry.last = now
+	entry.counts[source]++
+	for i, count := range entry.counts {
+		if i != source && count >= entry.counts[source] {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupKey identifies an event across sources. It only uses fields both
+// the SSE stream and the plugin webhook fill in.
+func dedupKey(event opencode.Event) string {
+	var detail string
+	switch p := event.Properties.(type) {
+	case *opencode.EventMessagePartUpdated:
+		var partID, text string
+		if part, ok := p.Properties.Part.(map[string]interface{}); ok {
+			partID, _ = part["id"].(string)
+			text, _ = part["text"].(string)
+		}
+		if p.Properties.Delta != nil {
+			text = *p.Properties.Delta
+		}
+		detail = partID + "\x00" + text
+	case *opencode.EventMessageUpdated:
+		if p.Properties.Info != nil && p.Properties.Info.Time.Completed != nil {
+			detail = "completed"
+		}
+	case *opencode.EventPermissionAsked:
+		detail = p.Properties.ID
+	case *opencode.EventPermissionReplied:
+		detail = p.Properties.RequestID
+	case *opencode.EventQuestionAsked:
+		detail = p.Properties.ID
+	case *opencode.EventQuestionReplied:
+		detail = p.Properties.RequestID
+	case *opencode.EventQuestionRejected:
+		detail = p.Properties.RequestID
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s", event.Type, event.SessionID(), event.MessageID(), detail)
+}
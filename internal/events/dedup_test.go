@@ -0,0 +1,76 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+// publishedEvents collects what a Dedup passes on
+type publishedEvents []opencode.Event
+
+func (p *publishedEvents) Publish(event opencode.Event) { *p = append(*p, event) }
+
+func deltaEvent(partID, delta string) opencode.Event {
+	evt := &opencode.EventMessagePartUpdated{}
+	evt.Properties.Part = map[string]interface{}{"sessionID": "ses_a", "messageID": "msg_a", "id": partID}
+	evt.Properties.Delta = &delta
+	return opencode.Event{Type: "message.part.updated", Properties: evt}
+}
+
+func TestDedup_DropsCopiesFromOtherSource(t *testing.T) {
+	var published publishedEvents
+	dedup := NewDedup(&published, 0)
+	sse, plugin := dedup.Source("sse"), dedup.Source("plugin")
+
+	idle := &opencode.EventSessionIdle{}
+	idle.Properties.SessionID = "ses_a"
+	sse.Publish(deltaEvent("prt_a", "Hel"))
+	plugin.Publish(deltaEvent("prt_a", "Hel"))
+	plugin.Publish(deltaEvent("prt_a", "lo"))
+	sse.Publish(deltaEvent("prt_a", "lo"))
+	plugin.Publish(opencode.Event{Type: "session.idle", Properties: idle})
+	sse.Publish(opencode.Event{Type: "session.idle", Properties: idle})
+
+	if len(published) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(published))
+	}
+}
+
+func TestDedup_KeepsRepeatedEvents(t *testing.T) {
+	var published publishedEvents
+	dedup := NewDedup(&published, 0)
+	sse, plugin := dedup.Source("sse"), dedup.Source("plugin")
+
+	// The same delta twice in a row is two events, from either source
+	sse.Publish(deltaEvent("prt_a", " the"))
+	sse.Publish(deltaEvent("prt_a", " the"))
+	plugin.Publish(deltaEvent("prt_a", " the"))
+	plugin.Publish(deltaEvent("prt_a", " the"))
+	plugin.Publish(deltaEvent("prt_a", " the"))
+
+	if len(published) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(published))
+	}
+}
+
+func TestDedup_ForgetsAfterWindow(t *testing.T) {
+	var published publishedEvents
+	dedup := NewDedup(&published, time.Minute)
+	now := time.Now()
+	dedup.now = func() time.Time { return now }
+	sse, plugin := dedup.Source("sse"), dedup.Source("plugin")
+
+	sse.Publish(deltaEvent("prt_a", "Hi"))
+	now = now.Add(2 * time.Minute)
+	plugin.Publish(deltaEvent("prt_b", "other"))
+	plugin.Publish(deltaEvent("prt_a", "Hi"))
+
+	if len(published) != 3 {
+		t.Fatalf("Expected a copy past the window to pass, got %d events", len(published))
+	}
+	if len(dedup.seen) != 2 {
+		t.Errorf("Expected the expired key to be swept, have %d keys", len(dedup.seen))
+	}
+}
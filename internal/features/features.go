@@ -0,0 +1,105 @@
+// Package features gates experimental behaviors, so that a deployment can
+// turn a risky one off (or on) without a code change.
+package features
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Flag names a behavior that can be toggled
+type Flag string
+
+const (
+	// StreamingEdits edits the placeholder as response text streams in;
+	// off, it only shows the final response
+	StreamingEdits Flag = "streaming_edits"
+	// PluginMode receives events from the OpenCode plugin webhook instead
+	// of the SSE stream (USE_PLUGIN_MODE)
+	PluginMode Flag = "plugin_mode"
+	// Reactions forwards the user's emoji reactions on responses to OpenCode
+	Reactions Flag = "reactions"
+	// PendingQuestions polls OpenCode on start for the question and
+	// permission requests asked while the bridge was down, and re-posts them
+	PendingQuestions Flag = "pending_questions"
+)
+
+// defaults are the flags known and their state when not configured
+var defaults = map[Flag]bool{
+	StreamingEdits:   true,
+	PluginMode:       true,
+	Reactions:        true,
+	PendingQuestions: true,
+}
+
+// Set is the state of every flag
+type Set struct {
+	enabled map[Flag]bool
+}
+
+// Defaults returns the flags in their default state
+func Defaults() Set {
+	s := Set{enabled: make(map[Flag]bool, len(defaults))}
+	for flag, on := range defaults {
+		s.enabled[flag] = on
+	}
+	return s
+}
+
+// Parse reads a comma-separated list of flag=bool pairs, e.g.
+// "streaming_edits=false,reactions=false", over the defaults. Unknown flags
+// are an error, so a typo does not silently keep a feature on.
+func Parse(spec string) (Set, error) {
+	s := Defaults()
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return Set{}, fmt.Errorf("%q: expected name=true or name=false", item)
+		}
+		flag := Flag(strings.TrimSpace(name))
+		if _, known := defaults[flag]; !known {
+			return Set{}, fmt.Errorf("unknown feature %q (known: %s)", flag, strings.Join(Names(), ", "))
+		}
+		on, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return Set{}, fmt.Errorf("feature %s: invalid value %q", flag, value)
+		}
+		s.enabled[flag] = on
+	}
+	return s, nil
+}
+
+// Names returns the known flags, sorted
+func Names() []string {
+	names := make([]string, 0, len(defaults))
+	for flag := range defaults {
+		names = append(names, string(flag))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Enabled reports whether flag is on; unknown flags are off
+func (s Set) Enabled(flag Flag) bool {
+	return s.enabled[flag]
+}
+
+// Set turns flag on or off, e.g. from a setting that predates the flag
+func (s Set) Set(flag Flag, on bool) {
+	s.enabled[flag] = on
+}
+
+// String lists every flag and its state, for the startup log
+func (s Set) String() string {
+	parts := make([]string, 0, len(s.enabled))
+	for _, name := range Names() {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, s.enabled[Flag(name)]))
+	}
+	return strings.Join(parts, ",")
+}
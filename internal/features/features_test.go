@@ -0,0 +1,40 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDefaults(t *testing.T) {
+	s, err := Parse("")
+	require.NoError(t, err)
+	for _, name := range Names() {
+		assert.True(t, s.Enabled(Flag(name)), name)
+	}
+	assert.False(t, s.Enabled("unknown"))
+}
+
+func TestParseOverrides(t *testing.T) {
+	s, err := Parse(" streaming_edits=false, reactions=0 ,")
+	require.NoError(t, err)
+	assert.False(t, s.Enabled(StreamingEdits))
+	assert.False(t, s.Enabled(Reactions))
+	assert.True(t, s.Enabled(PluginMode))
+	assert.Equal(t, "pending_questions=true,plugin_mode=true,reactions=false,streaming_edits=false", s.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"streaming", "streaming=false", "reactions=maybe"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSetDoesNotChangeDefaults(t *testing.T) {
+	s := Defaults()
+	s.Set(PluginMode, false)
+	assert.False(t, s.Enabled(PluginMode))
+	assert.True(t, Defaults().Enabled(PluginMode))
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultAlertAfter is how long the bridge must stay unhealthy or degraded
+// before the admin chat is alerted, so a brief SSE reconnect stays quiet
+const DefaultAlertAfter = 2 * time.Minute
+
+// alertCheckInterval is how often the alerter looks at the monitor
+const alertCheckInterval = 15 * time.Second
+
+// Alert is a change of the bridge's health worth telling an operator about
+type Alert struct {
+	Status   HealthStatus
+	Problems []string      // empty once recovered
+	Duration time.Duration // how long the problem lasted so far
+}
+
+// Alerter notifies, e.g. an admin chat, when the monitor reports the bridge
+// unhealthy or degraded for longer than a grace period, again when it gets
+// worse, and once it recovers. A notification that fails is retried on the
+// next check.
+type Alerter struct {
+	monitor *HealthMonitor
+	after   time.Duration
+	notify  func(ctx context.Context, alert Alert) error
+
+	since   time.Time    // when the bridge stopped being healthy (zero: healthy)
+	alerted HealthStatus // status last alerted ("": none)
+}
+
+// NewAlerter creates an alerter that calls notify once a problem lasted
+// for after
+func NewAlerter(monitor *HealthMonitor, after time.Duration, notify func(ctx context.Context, alert Alert) error) *Alerter {
+	return &Alerter{monitor: monitor, after: after, notify: notify}
+}
+
+// Run checks the monitor every 15 seconds until ctx is done
+func (a *Alerter) Run(ctx context.Context) {
+	ticker := time.NewTicker(alertCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.check(ctx, now)
+		}
+	}
+}
+
+// check alerts on the monitor's current status, as of now
+func (a *Alerter) check(ctx context.Context, now time.Time) {
+	status, problems := a.monitor.GetProblems()
+
+	if status == StatusHealthy {
+		if a.alerted != "" {
+			err := a.notify(ctx, Alert{Status: status, Duration: now.Sub(a.since)})
+			if err != nil {
+				logger.Warn("Failed to send recovery alert", "error", err)
+				return
+			}
+			a.alerted = ""
+		}
+		a.since = time.Time{}
+		return
+	}
+
+	if a.since.IsZero() {
+		a.since = now
+	}
+	if now.Sub(a.since) < a.after || status == a.alerted || (status == StatusDegraded && a.alerted == StatusUnhealthy) {
+		return
+	}
+	if err := a.notify(ctx, Alert{Status: status, Problems: problems, Duration: now.Sub(a.since)}); err != nil {
+		logger.Warn("Failed to send health alert", "status", status, "error", err)
+		return
+	}
+	a.alerted = status
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAlerter(t *testing.T) {
+	h := NewHealthMonitor()
+	h.SetSSEConnected(true)
+	var alerts []Alert
+	fail := false
+	a := NewAlerter(h, 2*time.Minute, func(ctx context.Context, alert Alert) error {
+		if fail {
+			return errors.New("telegram down")
+		}
+		alerts = append(alerts, alert)
+		return nil
+	})
+	ctx := context.Background()
+	start := time.Now()
+
+	a.check(ctx, start)
+	h.SetOpenCodeAvailable(false)
+	a.check(ctx, start.Add(time.Minute))
+	if len(alerts) != 0 {
+		t.Fatalf("alerted within the grace period: %+v", alerts)
+	}
+
+	// A failed notification is retried on the next check
+	fail = true
+	a.check(ctx, start.Add(3*time.Minute))
+	fail = false
+	a.check(ctx, start.Add(4*time.Minute))
+	a.check(ctx, start.Add(5*time.Minute))
+	if len(alerts) != 1 || alerts[0].Status != StatusDegraded || len(alerts[0].Problems) != 1 {
+		t.Fatalf("alerts = %+v, want one degraded alert", alerts)
+	}
+	if alerts[0].Duration != 3*time.Minute {
+		t.Errorf("duration = %s, want 3m", alerts[0].Duration)
+	}
+
+	// Getting worse alerts again
+	h.SetSSEConnected(false)
+	a.check(ctx, start.Add(6*time.Minute))
+	if len(alerts) != 2 || alerts[1].Status != StatusUnhealthy {
+		t.Fatalf("alerts = %+v, want an unhealthy alert", alerts)
+	}
+
+	h.SetSSEConnected(true)
+	h.SetOpenCodeAvailable(true)
+	h.reconnectCount = 0
+	a.check(ctx, start.Add(8*time.Minute))
+	if len(alerts) != 3 || alerts[2].Status != StatusHealthy || alerts[2].Duration != 7*time.Minute {
+		t.Fatalf("alerts = %+v, want a recovery after 7m", alerts)
+	}
+
+	a.check(ctx, start.Add(9*time.Minute))
+	if len(alerts) != 3 {
+		t.Errorf("alerted again while healthy: %+v", alerts)
+	}
+}
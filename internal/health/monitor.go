@@ -2,9 +2,13 @@ package health
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/user/opencode-telegram/internal/buildinfo"
 )
 
 // HealthStatus represents the overall health state
@@ -26,11 +30,32 @@ type HealthMonitor struct {
 	lastEventType  string
 	eventCount     int64
 	reconnectCount int
+
+	// circuitOpen is set while the OpenCode client's circuit breaker is open
+	circuitOpen bool
+
+	// webhookListening is set while the plugin webhook accepts events
+	webhookListening bool
+	// botsRunning counts the bots receiving Telegram updates
+	botsRunning int
+
+	// telegram is the last Bot API probe result of each bot, by account
+	telegram map[string]telegramStatus
+
+	// opencode is the last /health poll result of each OpenCode server
+	opencode map[string]OpenCodeServer
+}
+
+// telegramStatus is what a bot's Bot API probe found
+type telegramStatus struct {
+	connected bool
+	lastSend  time.Time
 }
 
 // HealthReport contains the current health status
 type HealthReport struct {
 	Status             HealthStatus `json:"status"`
+	Problems           []string     `json:"problems,omitempty"`
 	SSEConnected       bool         `json:"sse_connected"`
 	LastEventTime      string       `json:"last_event_time"`
 	TimeSinceLastEvent string       `json:"time_since_last_event"`
@@ -39,12 +64,37 @@ type HealthReport struct {
 	LastEventType      string       `json:"last_event_type,omitempty"`
 	TotalEvents        int64        `json:"total_events"`
 	ReconnectCount     int          `json:"reconnect_count"`
+	CircuitOpen        bool         `json:"opencode_circuit_open"`
+	TelegramConnected  bool         `json:"telegram_connected"`
+	LastTelegramSend   string       `json:"last_telegram_send"`
+
+	OpenCodeReachable bool             `json:"opencode_reachable"`
+	OpenCodeServers   []OpenCodeServer `json:"opencode_servers,omitempty"`
+
+	Build buildinfo.Info `json:"build"`
+}
+
+// OpenCodeServer is what polling an OpenCode server's /health found
+type OpenCodeServer struct {
+	Name      string `json:"name"`
+	Reachable bool   `json:"reachable"`
+	Version   string `json:"version,omitempty"`
+}
+
+// ReadinessReport says whether the bridge can serve chats, for /readyz
+type ReadinessReport struct {
+	Ready             bool `json:"ready"`
+	EventsConnected   bool `json:"events_connected"`
+	BotsRunning       int  `json:"bots_running"`
+	OpenCodeReachable bool `json:"opencode_reachable"`
 }
 
 // NewHealthMonitor creates a new health monitor
 func NewHealthMonitor() *HealthMonitor {
 	return &HealthMonitor{
 		startTime: time.Now(),
+		telegram:  make(map[string]telegramStatus),
+		opencode:  make(map[string]OpenCodeServer),
 	}
 }
 
@@ -52,10 +102,10 @@ func NewHealthMonitor() *HealthMonitor {
 func (h *HealthMonitor) SetSSEConnected(connected bool) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.sseConnected = connected
-	if !connected {
+	if !connected && h.sseConnected {
 		h.reconnectCount++
 	}
+	h.sseConnected = connected
 }
 
 // RecordEvent records an SSE event
@@ -74,27 +124,115 @@ func (h *HealthMonitor) SetActiveSessions(count int) {
 	h.activeSessions = count
 }
 
-// GetStatus determines overall health status
-func (h *HealthMonitor) GetStatus() HealthStatus {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// SetOpenCodeAvailable records whether OpenCode API requests are going
+// through; false while the client's circuit breaker is open
+func (h *HealthMonitor) SetOpenCodeAvailable(available bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.circuitOpen = !available
+}
 
-	// Unhealthy: SSE not connected
-	if !h.sseConnected {
-		return StatusUnhealthy
+// SetWebhookListening records whether the plugin webhook accepts events
+func (h *HealthMonitor) SetWebhookListening(listening bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.webhookListening = listening
+}
+
+// BotStarted records a bot that started receiving Telegram updates
+func (h *HealthMonitor) BotStarted() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.botsRunning++
+}
+
+// BotStopped records a bot that stopped receiving Telegram updates
+func (h *HealthMonitor) BotStopped() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.botsRunning--
+}
+
+// SetTelegramStatus records the result of a bot's Bot API probe and when
+// its last request to the chat succeeded
+func (h *HealthMonitor) SetTelegramStatus(account string, connected bool, lastSend time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.telegram[account] = telegramStatus{connected: connected, lastSend: lastSend}
+}
+
+// RemoveTelegramStatus forgets a bot that stopped
+func (h *HealthMonitor) RemoveTelegramStatus(account string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.telegram, account)
+}
+
+// SetOpenCodeStatus records the result of polling an OpenCode server's
+// /health and the version it reported; an empty version keeps the last one
+func (h *HealthMonitor) SetOpenCodeStatus(server string, reachable bool, version string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if version == "" {
+		version = h.opencode[server].Version
 	}
+	h.opencode[server] = OpenCodeServer{Name: server, Reachable: reachable, Version: version}
+}
 
-	// Degraded: No events in last 5 minutes (but connected)
-	if !h.lastEventTime.IsZero() && time.Since(h.lastEventTime) > 5*time.Minute {
-		return StatusDegraded
+// openCodeReachableLocked reports whether requests to OpenCode go through
+// and every polled server answered its last /health (caller must hold lock)
+func (h *HealthMonitor) openCodeReachableLocked() bool {
+	if h.circuitOpen {
+		return false
+	}
+	for _, server := range h.opencode {
+		if !server.Reachable {
+			return false
+		}
 	}
+	return true
+}
 
-	// Degraded: Multiple reconnects (instability)
-	if h.reconnectCount > 3 {
-		return StatusDegraded
+// telegramConnectedLocked reports whether every bot reached the Bot API on
+// its last probe (caller must hold lock)
+func (h *HealthMonitor) telegramConnectedLocked() bool {
+	for _, status := range h.telegram {
+		if !status.connected {
+			return false
+		}
+	}
+	return true
+}
+
+// GetReadiness reports whether the bridge can serve chats: an event source
+// (SSE or the plugin webhook) is connected, at least one bot receives
+// updates and OpenCode requests go through
+func (h *HealthMonitor) GetReadiness() ReadinessReport {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r := ReadinessReport{
+		EventsConnected:   h.sseConnected || h.webhookListening,
+		BotsRunning:       h.botsRunning,
+		OpenCodeReachable: h.openCodeReachableLocked(),
 	}
+	r.Ready = r.EventsConnected && r.BotsRunning > 0 && r.OpenCodeReachable
+	return r
+}
 
-	return StatusHealthy
+// GetStatus determines overall health status
+func (h *HealthMonitor) GetStatus() HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.GetStatusLocked()
+}
+
+// GetProblems returns the status with what keeps the bridge from being
+// healthy, one line each
+func (h *HealthMonitor) GetProblems() (HealthStatus, []string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.problemsLocked()
 }
 
 // GetReport generates a health report
@@ -111,8 +249,21 @@ func (h *HealthMonitor) GetReport() HealthReport {
 		timeSinceLastEvent = "N/A"
 	}
 
+	lastSend := "never"
+	var lastSendTime time.Time
+	for _, status := range h.telegram {
+		if status.lastSend.After(lastSendTime) {
+			lastSendTime = status.lastSend
+		}
+	}
+	if !lastSendTime.IsZero() {
+		lastSend = lastSendTime.Format(time.RFC3339)
+	}
+
+	status, problems := h.problemsLocked()
 	return HealthReport{
-		Status:             h.GetStatusLocked(),
+		Status:             status,
+		Problems:           problems,
 		SSEConnected:       h.sseConnected,
 		LastEventTime:      lastEventStr,
 		TimeSinceLastEvent: timeSinceLastEvent,
@@ -121,24 +272,84 @@ func (h *HealthMonitor) GetReport() HealthReport {
 		LastEventType:      h.lastEventType,
 		TotalEvents:        h.eventCount,
 		ReconnectCount:     h.reconnectCount,
+		CircuitOpen:        h.circuitOpen,
+		TelegramConnected:  h.telegramConnectedLocked(),
+		LastTelegramSend:   lastSend,
+		OpenCodeReachable:  h.openCodeReachableLocked(),
+		OpenCodeServers:    h.sortedOpenCodeLocked(),
+		Build:              buildinfo.Get(),
 	}
 }
 
 // GetStatusLocked returns status without acquiring lock (caller must hold lock)
 func (h *HealthMonitor) GetStatusLocked() HealthStatus {
-	if !h.sseConnected {
-		return StatusUnhealthy
+	status, _ := h.problemsLocked()
+	return status
+}
+
+// problemsLocked returns the status with what keeps the bridge from being
+// healthy, e.g. for alerts (caller must hold lock)
+func (h *HealthMonitor) problemsLocked() (HealthStatus, []string) {
+	status := StatusHealthy
+	var problems []string
+	degraded := func(problem string) {
+		if status == StatusHealthy {
+			status = StatusDegraded
+		}
+		problems = append(problems, problem)
+	}
+
+	// Unhealthy: neither the SSE stream nor the plugin webhook is up
+	if !h.sseConnected && !h.webhookListening {
+		status = StatusUnhealthy
+		problems = append(problems, "no event source connected (SSE or plugin webhook)")
 	}
 
+	// Degraded: No events in last 5 minutes (but connected)
 	if !h.lastEventTime.IsZero() && time.Since(h.lastEventTime) > 5*time.Minute {
-		return StatusDegraded
+		degraded(fmt.Sprintf("no events for %s", time.Since(h.lastEventTime).Round(time.Minute)))
 	}
 
+	// Degraded: Multiple reconnects (instability)
 	if h.reconnectCount > 3 {
-		return StatusDegraded
+		degraded(fmt.Sprintf("SSE stream reconnected %d times", h.reconnectCount))
+	}
+
+	// Degraded: OpenCode API requests are failing fast or its health
+	// check fails
+	if h.circuitOpen {
+		degraded("OpenCode circuit breaker open")
+	}
+	for _, server := range h.sortedOpenCodeLocked() {
+		if !server.Reachable {
+			degraded(fmt.Sprintf("OpenCode server %s not answering /health", server.Name))
+		}
 	}
 
-	return StatusHealthy
+	// Degraded: a bot cannot reach the Telegram Bot API
+	accounts := make([]string, 0, len(h.telegram))
+	for account, telegram := range h.telegram {
+		if !telegram.connected {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		degraded(fmt.Sprintf("bot %s cannot reach Telegram", account))
+	}
+
+	return status, problems
+}
+
+// sortedOpenCodeLocked returns the polled OpenCode servers by name (caller
+// must hold lock)
+func (h *HealthMonitor) sortedOpenCodeLocked() []OpenCodeServer {
+	servers := make([]OpenCodeServer, 0, len(h.opencode))
+	for _, server := range h.opencode {
+		servers = append(servers, server)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers
 }
 
 // ServeHTTP implements http.Handler for the /health endpoint
@@ -159,3 +370,32 @@ func (h *HealthMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(report)
 }
+
+// LivenessHandler serves /livez: 200 as long as the process answers, so an
+// orchestrator only restarts a bridge that is stuck, not one whose
+// OpenCode server is down
+func (h *HealthMonitor) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status": "alive",
+			"uptime": time.Since(h.startTime).Round(time.Second).String(),
+		})
+	})
+}
+
+// ReadinessHandler serves /readyz: 200 when the bridge can serve chats,
+// 503 with the failing checks otherwise
+func (h *HealthMonitor) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := h.GetReadiness()
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Ready {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+	h := NewHealthMonitor()
+	if h.GetReadiness().Ready {
+		t.Fatal("ready before any event source or bot")
+	}
+
+	h.SetWebhookListening(true)
+	h.BotStarted()
+	if r := h.GetReadiness(); !r.Ready || !r.EventsConnected || r.BotsRunning != 1 {
+		t.Errorf("readiness = %+v, want ready with the webhook and one bot", r)
+	}
+
+	// OpenCode being down makes the bridge not ready, but still alive
+	h.SetOpenCodeAvailable(false)
+	rec := httptest.NewRecorder()
+	h.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz status = %d, want 503", rec.Code)
+	}
+	var report ReadinessReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.OpenCodeReachable {
+		t.Error("opencode_reachable = true while the circuit is open")
+	}
+	rec = httptest.NewRecorder()
+	h.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/livez status = %d, want 200", rec.Code)
+	}
+
+	h.SetOpenCodeAvailable(true)
+	h.BotStopped()
+	if h.GetReadiness().Ready {
+		t.Error("ready without a running bot")
+	}
+}
+
+func TestTelegramStatus(t *testing.T) {
+	h := NewHealthMonitor()
+	h.SetSSEConnected(true)
+	if r := h.GetReport(); !r.TelegramConnected || r.LastTelegramSend != "never" {
+		t.Errorf("report = %+v, want connected without sends", r)
+	}
+
+	sent := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h.SetTelegramStatus("work", true, sent)
+	h.SetTelegramStatus("home", false, time.Time{})
+	r := h.GetReport()
+	if r.TelegramConnected || r.Status != StatusDegraded {
+		t.Errorf("report = %+v, want degraded while a bot cannot reach Telegram", r)
+	}
+	if r.LastTelegramSend != sent.Format(time.RFC3339) {
+		t.Errorf("last_telegram_send = %q, want %q", r.LastTelegramSend, sent.Format(time.RFC3339))
+	}
+
+	h.RemoveTelegramStatus("home")
+	if r := h.GetReport(); !r.TelegramConnected || r.Status != StatusHealthy {
+		t.Errorf("report = %+v, want healthy once the failing bot stopped", r)
+	}
+}
+
+func TestPollOpenCode(t *testing.T) {
+	h := NewHealthMonitor()
+	h.SetSSEConnected(true)
+	h.BotStarted()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // a single check
+
+	h.PollOpenCode(ctx, "local", time.Minute, func() (map[string]interface{}, error) {
+		return map[string]interface{}{"healthy": true, "version": "1.2.3"}, nil
+	})
+	r := h.GetReport()
+	if !r.OpenCodeReachable || len(r.OpenCodeServers) != 1 || r.OpenCodeServers[0].Version != "1.2.3" {
+		t.Errorf("report = %+v, want local 1.2.3 reachable", r)
+	}
+
+	h.PollOpenCode(ctx, "local", time.Minute, func() (map[string]interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+	r = h.GetReport()
+	if r.OpenCodeReachable || r.Status != StatusDegraded {
+		t.Errorf("report = %+v, want degraded while OpenCode is down", r)
+	}
+	if r.OpenCodeServers[0].Version != "1.2.3" {
+		t.Errorf("version = %q, want the last known 1.2.3", r.OpenCodeServers[0].Version)
+	}
+	if h.GetReadiness().Ready {
+		t.Error("ready while OpenCode is down")
+	}
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/logging"
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+var logger = logging.For("health")
+
+// OpenCodeCheck calls an OpenCode server's /health, e.g. Client.Health
+type OpenCodeCheck func() (map[string]interface{}, error)
+
+// PollOpenCode checks an OpenCode server right away and then every interval
+// until ctx is done, recording whether it answered and its version in the
+// monitor and in the opencode_up and opencode_info metrics
+func (h *HealthMonitor) PollOpenCode(ctx context.Context, server string, interval time.Duration, check OpenCodeCheck) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reachable, version := pollOpenCode(check)
+		h.SetOpenCodeStatus(server, reachable, version)
+		metrics.SetOpenCodeHealth(server, reachable, version)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollOpenCode calls check once; a server answering without "healthy": true
+// counts as unreachable
+func pollOpenCode(check OpenCodeCheck) (reachable bool, version string) {
+	data, err := check()
+	if err != nil {
+		logger.Warn("OpenCode health check failed", "error", err)
+		return false, ""
+	}
+	version, _ = data["version"].(string)
+	if healthy, ok := data["healthy"].(bool); ok && !healthy {
+		logger.Warn("OpenCode reports unhealthy", "version", version)
+		return false, version
+	}
+	return true, version
+}
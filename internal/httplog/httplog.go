@@ -0,0 +1,83 @@
+// Package httplog logs the requests of the bridge's HTTP servers, the
+// health and the plugin webhook server, and counts them per endpoint.
+package httplog
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/logging"
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+var logger = logging.For("http")
+
+// unmatched is the endpoint of requests no route matched, so that probing
+// random paths does not add metric series
+const unmatched = "other"
+
+// Handler wraps the mux of the server named server. Each request is logged
+// with its method, path, status, latency and remote address: at debug level
+// when it succeeded, as probes and webhook events are frequent, at info
+// level on a client error and at warn level on a server error.
+func Handler(server string, mux *http.ServeMux) http.Handler {
+	log := logger.With("server", server)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, r)
+		latency := time.Since(start)
+
+		// The route, e.g. "/debug/", rather than the path keeps the
+		// endpoints few. The mux sets it on the request it matched.
+		endpoint := r.Pattern
+		if endpoint == "" {
+			endpoint = unmatched
+		}
+		metrics.HTTPRequests.WithLabelValues(server, endpoint, r.Method, strconv.Itoa(rec.status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(server, endpoint).Observe(latency.Seconds())
+
+		level := slog.LevelDebug
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelWarn
+		case rec.status >= 400:
+			level = slog.LevelInfo
+		}
+		log.Log(r.Context(), level, "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"latency", latency,
+			"remote", r.RemoteAddr,
+		)
+	})
+}
+
+// statusRecorder remembers the status a handler answered with
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush a profile while it is streamed
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,56 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/logging"
+)
+
+func TestHandlerLogsRequests(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, logging.Setup(&out, logging.FormatJSON))
+	defer logging.Setup(os.Stderr, logging.FormatText)
+	defer logging.SetLevel(logging.CurrentLevel())
+	logging.SetLevel(logging.LevelDebug)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/debug/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+	handler := Handler("health", mux)
+
+	for _, path := range []string{"/health", "/debug/state", "/missing"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	var statuses []float64
+	for _, line := range lines {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, "health", entry["server"])
+		assert.Equal(t, "GET", entry["method"])
+		assert.Equal(t, "10.0.0.1:1234", entry["remote"])
+		assert.Contains(t, entry, "latency")
+		statuses = append(statuses, entry["status"].(float64))
+	}
+	assert.Equal(t, []float64{200, 401, 404}, statuses)
+	assert.Contains(t, lines[0], `"level":"DEBUG"`)
+	assert.Contains(t, lines[1], `"level":"INFO"`)
+	assert.Contains(t, lines[1], `"path":"/debug/state"`)
+}
@@ -0,0 +1,319 @@
+package i18n
+
+var en = map[string]string{
+	// OpenCode connection and API errors
+	"opencode.unavailable":           "⚠️ OpenCode is unreachable right now. Please try again shortly.",
+	"opencode.rate_limited":          "⏳ The model is rate limited right now. Please try again in a moment.",
+	"opencode.api_error":             "❌ OpenCode: %s",
+	"opencode.retrying":              "⏳ OpenCode returned an error, retrying...",
+	"opencode.retrying.rate_limited": "⏳ Model rate limited, retrying...",
+
+	// Generic
+	"error":             "❌ Error: %v",
+	"busy":              "⏳ Still processing your previous request...",
+	"processing":        "⏳ Processing...",
+	"processing.audio":  "🎙️ Transcribing audio...",
+	"processing.image":  "🖼️ Processing image...",
+	"processing.images": "🖼️ Processing %d images...",
+	"processing.video":  "🎞️ Extracting video frames...",
+	"callback.invalid":  "❌ Invalid callback data: %s",
+	"nav.prev":          "◀️ Prev",
+	"nav.next":          "Next ▶️",
+	"cancel":            "❌ Cancel",
+
+	// Responses
+	"response.completed": "✅ Response completed",
+	"response.empty":     "✅ Response completed (no content)",
+	"pages.show_more":    "▶️ Show more (%d/%d)",
+	"pages.expired":      "⌛ This response has expired, the remaining pages are no longer available",
+
+	// Media
+	"photo.invalid":           "❌ Error: No valid photo found",
+	"photo.download_failed":   "❌ Error downloading image: %s",
+	"image.send_failed":       "⚠️ Could not send image %s: %s",
+	"media.unsupported":       "⚠️ This media type is not supported yet",
+	"audio.too_large":         "❌ Audio file is too large (max %d MB)",
+	"audio.download_failed":   "❌ Error downloading audio: %s",
+	"audio.transcribe_failed": "❌ Transcription failed: %s",
+	"audio.no_speech":         "⚠️ No speech detected in the audio",
+	"video.too_large":         "❌ Video is too large (max %d MB)",
+	"video.failed":            "❌ Could not read video frames: %s",
+	"sticker.no_session":      "📌 Sticker received (no active session)",
+
+	// Progress
+	"progress.running":       "🔧 Running: %s",
+	"progress.running_title": "🔧 Running %s: %s…",
+	"progress.step":          "step %d",
+	"progress.tool_call":     "1 tool call",
+	"progress.tool_calls":    "%d tool calls",
+
+	// Agents and routing
+	"agent.switched":      "🔄 Switched to %s",
+	"agent.unknown":       "❌ Unknown agent: %s\n\nAvailable agents:\n",
+	"agent.select":        "🤖 Select an OHO Agent:\n\n",
+	"route.cleared":       "✅ Chat agent assignment cleared. Using global agent.",
+	"route.set":           "✅ Chat agent set to: %s",
+	"route.status_chat":   "🎯 Chat Agent: %s\n📍 Global Agent: %s",
+	"route.status_global": "📍 Using Global Agent: %s\n(No chat-specific override)",
+
+	// Models
+	"model.select":  "🤖 Select a Model:\n\n",
+	"model.set":     "✅ Model set to: %s",
+	"model.refresh": "🔄 Refresh",
+
+	// Permissions
+	"permission.title":              "🔐 **Permission Request**",
+	"permission.body":               "**Permission:** %s\n**Patterns:** %s",
+	"permission.details":            "**Details:**",
+	"permission.allowed_once":       "✅ Allowed (once)",
+	"permission.allowed_always":     "✅ Allowed (always)",
+	"permission.rejected":           "❌ Rejected",
+	"permission.answered_elsewhere": "↪️ Answered outside Telegram",
+	"permission.button.once":        "✅ Allow Once",
+	"permission.button.always":      "✅ Always Allow",
+	"permission.button.reject":      "❌ Reject",
+
+	// Questions
+	"question.header":             "🤔 OpenCode has questions:\n\n",
+	"question.custom_allowed":     "  • ✏️ Custom answer allowed\n",
+	"question.answer_first":       "Please answer the first question:",
+	"question.type_custom":        "%s\n\n✏️ Please type your custom answer:",
+	"question.submitted":          "%s\n\n✅ Answer submitted: %s",
+	"question.submit_failed":      "❌ Failed to submit answer: %v\n\nYour answer is kept, send it again to retry:\n<code>%s</code>",
+	"question.custom_pending":     "\n\n✏️ Your next message still answers: %s",
+	"question.answered_elsewhere": "↪️ Answered outside Telegram",
+	"question.dismissed":          "%s\n\n🚫 Dismissed outside Telegram",
+	"question.error":              "❌ Error handling question: %v",
+	"question.button.submit":      "✅ Submit",
+	"question.button.custom":      "✏️ Type custom...",
+	"pending.restored":            "♻️ Restoring %d pending request(s) from before the restart:",
+	"pending.closed":              "⌛ No longer pending in OpenCode",
+
+	// Sessions
+	"session.created":            "✅ New session created: %s (%s)",
+	"session.switched":           "✅ Switched to session: %s (%s)",
+	"session.not_found":          "❌ Session %s not found",
+	"session.deleted_externally": "🗑 Session %s was deleted in OpenCode. Your next message starts a new session.",
+	"session.id_required":        "❌ Please provide a session ID: /session &lt;id|alias&gt;",
+	"sessions.none":              "No sessions found. Use /newsession to create one.",
+	"sessions.none_short":        "No sessions found.",
+	"sessions.no_primary":        "No primary sessions found.",
+	"sessions.header":            "📋 <b>Sessions</b> (showing %d of %d)\n",
+	"sessions.more":              "💡 <i>... and %d more sessions</i>",
+	"sessions.stats":             "💬 %s messages · 🪙 %s tokens",
+	"sessions.tip":               "\n<b>Tip:</b> Use <code>/session &lt;id&gt;</code> or <code>/selectsession</code> for menu",
+	"sessions.select_page":       "📋 <b>Select Session</b> (page %d/%d)",
+	"sessions.select_expired":    "❌ Session list expired. Please use /selectsession again.",
+	"abort.none":                 "❌ No active session to abort",
+	"abort.done":                 "🛑 Session %s aborted",
+	"delete.id_required":         "❌ Please provide session ID: /deletesession &lt;id&gt;",
+	"delete.done":                "🗑️ Deleted session: %s\n📝 Title: %s",
+	"delete.expired":             "❌ Session list expired. Please use /deletesessions again.",
+	"delete.select_page":         "🗑️ Select session to delete (Page %d/%d):",
+	"delete.previous":            "⬅️ Previous",
+	"delete.next":                "Next ➡️",
+	"delete.confirm":             "⚠️ Confirm deletion?\n\n📝 Title: %s\n🆔 ID: %s\n📂 Directory: %s",
+	"delete.yes":                 "✅ Yes, delete",
+	"delete.gone":                "❌ Session not found or already deleted",
+	"delete.success":             "✅ Deleted successfully!\n\n📝 Title: %s\n🆔 ID: %s",
+	"delete.cancelled":           "❌ Deletion cancelled",
+
+	// Status
+	"status.title":         "📊 Status:",
+	"status.session":       "Session: %s",
+	"status.session_id":    "Session ID: %s",
+	"status.directory":     "Directory: %s",
+	"status.agent":         "Agent: %s",
+	"status.model":         "Model: %s",
+	"status.status":        "Status: %s",
+	"status.opencode":      "OpenCode: %s",
+	"status.none":          "(none)",
+	"status.unknown":       "(unknown)",
+	"status.idle":          "idle",
+	"status.processing":    "processing",
+	"status.error":         "error",
+	"status.health_ok":     "healthy",
+	"status.health_bad":    "unhealthy",
+	"status.health_nodata": "unknown",
+	"banner.title":         "📌 Active session",
+
+	// Relative time
+	"ago.now":     "just now",
+	"ago.minute":  "1 min ago",
+	"ago.minutes": "%d mins ago",
+	"ago.hour":    "1 hour ago",
+	"ago.hours":   "%d hours ago",
+	"ago.day":     "1 day ago",
+	"ago.days":    "%d days ago",
+	"ago.week":    "1 week ago",
+	"ago.weeks":   "%d weeks ago",
+	"ago.month":   "1 month ago",
+	"ago.months":  "%d months ago",
+	"ago.year":    "1 year ago",
+	"ago.years":   "%d years ago",
+
+	// Quick actions
+	"quick.new":            "🆕 New session",
+	"quick.status":         "📊 Status",
+	"quick.abort":          "🛑 Abort",
+	"quick.switch":         "🔄 Switch agent",
+	"keyboard.placeholder": "Message OpenCode...",
+	"keyboard.disabled":    "⚠️ Quick action keyboard is disabled (set TELEGRAM_QUICK_KEYBOARD=true)",
+	"keyboard.hidden":      "⌨️ Quick action keyboard hidden. Use /keyboard to show it again.",
+	"keyboard.enabled":     "⌨️ Quick actions enabled",
+
+	// Response actions (inline buttons under a completed response)
+	"action.continue":        "▶️ Continue",
+	"action.retry":           "🔁 Retry",
+	"action.new":             "🆕 New session",
+	"action.explain":         "💡 Explain more",
+	"action.prompt.continue": "Continue",
+	"action.prompt.explain":  "Explain your last answer in more detail",
+	"action.retry_none":      "⚠️ Nothing to retry in this session",
+
+	// Language
+	"lang.current": "🌐 Language: %s\n\nAvailable: %s\nUsage: /lang &lt;code&gt;",
+	"lang.set":     "🌐 Language set to %s",
+	"lang.unknown": "❌ Unknown language: %s\n\nAvailable: %s",
+
+	// Aliases
+	"alias.usage":           "Usage:\n/alias add &lt;name&gt; &lt;expansion&gt;\n/alias rm &lt;name&gt;\n/alias list\n\nSeparate steps with ;, e.g. /alias add b /switch build; continue",
+	"alias.added":           "✅ Alias /%s → %s",
+	"alias.removed":         "🗑 Alias /%s removed",
+	"alias.not_found":       "❌ No alias named /%s",
+	"alias.list_empty":      "No aliases defined. Add one with /alias add &lt;name&gt; &lt;expansion&gt;",
+	"alias.list_title":      "🔖 Aliases:",
+	"alias.invalid_name":    "❌ Invalid alias name: %s (use a-z, 0-9 and _, up to 32 characters)",
+	"alias.reserved":        "❌ /%s conflicts with a built-in command",
+	"alias.unknown_command": "❌ Unknown command: /%s",
+
+	// Photo prompt
+	"photoprompt.current": "🖼️ Photos without a caption are sent with:\n%s\n\nUsage: /photoprompt &lt;text&gt; | off | reset",
+	"photoprompt.none":    "🖼️ Photos without a caption are sent on their own.\n\nUsage: /photoprompt &lt;text&gt; | off | reset",
+	"photoprompt.set":     "✅ Photos without a caption will be sent with:\n%s",
+	"photoprompt.off":     "✅ Photos without a caption will be sent on their own",
+	"photoprompt.reset":   "✅ Photo prompt reset to the default",
+
+	// Session aliases
+	"session_alias.usage":        "Usage:\n/alias-session &lt;name&gt; [session]\n/alias-session rm &lt;name&gt;\n/alias-session\n\nWithout a session, the current one is named. Then switch with /session &lt;name&gt;.",
+	"session_alias.added":        "🏷 %s now names %s. Switch to it with /session %[1]s",
+	"session_alias.removed":      "🗑 Session alias %s removed",
+	"session_alias.not_found":    "❌ No session alias named %s",
+	"session_alias.invalid_name": "❌ Invalid session alias: %s (use a-z, 0-9 and _, up to 32 characters, not starting with ses_)",
+	"session_alias.no_session":   "❌ No current session. Give a session ID: /alias-session &lt;name&gt; &lt;session&gt;",
+	"session_alias.list_empty":   "No session aliases. Name the current session with /alias-session &lt;name&gt;",
+	"session_alias.list_title":   "🏷 Session aliases:",
+
+	// Favorites
+	"fav.usage":        "Usage:\n/fav add [session]\n/fav rm &lt;session&gt;\n/fav list\n\nWithout a session, /fav add adds the current one.",
+	"fav.added":        "⭐ %s added to favorites",
+	"fav.exists":       "⭐ %s is already a favorite",
+	"fav.removed":      "🗑 %s removed from favorites",
+	"fav.not_favorite": "❌ %s is not a favorite",
+	"fav.not_found":    "❌ Session not found: %s",
+	"fav.no_session":   "❌ No current session. Give a session ID: /fav add &lt;session&gt;",
+	"fav.list_empty":   "No favorite sessions. Add the current one with /fav add",
+	"fav.list_title":   "⭐ Favorite sessions:",
+
+	// Debounce
+	"debounce.current": "⏱️ Messages sent within %d ms of each other are merged into one prompt in this chat.\n\nUsage: /debounce &lt;ms&gt; | reset",
+	"debounce.default": "⏱️ Messages sent within %d ms of each other are merged into one prompt (default).\n\nUsage: /debounce &lt;ms&gt; | reset",
+	"debounce.set":     "✅ Messages sent within %d ms of each other will be merged into one prompt",
+	"debounce.reset":   "✅ Debounce reset to the default (%d ms)",
+	"debounce.invalid": "❌ Give a number of milliseconds between 0 and %d, or reset",
+
+	// Log level
+	"loglevel.current": "📝 Log level: <b>%s</b>\n\nUsage: /loglevel debug|info|warn|error",
+	"loglevel.set":     "✅ Log level set to <b>%s</b>",
+	"loglevel.invalid": "❌ Give one of debug, info, warn or error",
+
+	// Version
+	"version.info": "🏷 <b>opencode-telegram %s</b>\nCommit: <code>%s</code>\nBuilt: %s\nGo: %s",
+
+	// Feedback
+	"feedback.usage":    "Usage: /feedback &lt;message&gt;",
+	"feedback.sent":     "✅ Thanks! Your feedback was sent to the operators.",
+	"feedback.disabled": "⚠️ Feedback is not enabled for this bot",
+	"feedback.report":   "📝 Feedback\nChat: %s\nUser: %s\nSession: %s\n\n%s",
+
+	// Health alerts to the admin chat
+	"alert.unhealthy": "🚨 Bridge <b>unhealthy</b> for %s\n%s",
+	"alert.degraded":  "⚠️ Bridge <b>degraded</b> for %s\n%s",
+	"alert.recovered": "✅ Bridge healthy again after %s",
+
+	// Session forks
+	"fork.none":          "❌ No active session to fork. Use /newsession first.",
+	"fork.created":       "🌿 Forked into <b>%s</b> (%s)\n↳ parent: <code>%s</code>",
+	"fork.default_title": "Fork of %s",
+
+	// OpenCode servers
+	"server.list":     "🖥 <b>OpenCode Servers</b> (current: <b>%s</b>)",
+	"server.switched": "✅ Switched to server <b>%s</b>. Your next message starts a new session there.",
+	"server.already":  "ℹ️ Already using server <b>%s</b>.",
+	"server.unknown":  "❌ Unknown server: %s. Use /server to see the list.",
+	"server.single":   "ℹ️ Only one OpenCode server is configured. Set OPENCODE_SERVERS to add more.",
+
+	// Project init (/init)
+	"init.title":    "Project init",
+	"init.started":  "📝 Analyzing the project and writing AGENTS.md with %s. This can take a few minutes…",
+	"init.done":     "✅ AGENTS.md generated.",
+	"init.no_model": "❌ No model to run /init with. Pick one with /model first.",
+
+	// Stats
+	"stats.report": "📊 Usage in this chat\n\nPrompts: %d\nResponses: %d\nErrors: %d\nTokens: %s\nAverage response time: %s",
+
+	// Audit
+	"audit.title": "🔐 Audit log, newest last:",
+	"audit.empty": "No audited actions in this chat yet.",
+	"audit.usage": "❌ Usage: /audit [n], with n between 1 and %d",
+
+	// Help
+	"help": `🆘 Available Commands:
+
+/newsession [title] - Create a new session
+/fork [title] - Fork the current session into a child session
+/sessions - List sessions with their forks
+/selectsession - Select session from menu
+/deletesessions - Delete sessions (interactive menu)
+/session &lt;id|alias&gt; - Switch to a session
+/deletesession &lt;id&gt; - Delete a session directly
+/abort - Abort current session
+/status - Show current status
+/switch [agent] - Switch OHO agent
+/route [agent] - Set or view per-chat agent assignment
+/keyboard [off] - Show or hide the quick action keyboard
+/lang [code] - Show or change the bot language
+/photoprompt [text|off|reset] - Set the prompt for photos without a caption
+/fav add|rm|list - Manage favorite sessions, listed first in /selectsession
+/alias add|rm|list - Manage command aliases
+/alias-session &lt;name&gt; [session] - Name a session for /session &lt;name&gt;
+/server [name] - Show or switch the OpenCode server
+/init - Generate AGENTS.md for the current project
+/stats - Show usage statistics for this chat
+/audit [n] - Show the latest permission replies, session deletions and agent/model switches
+/debounce [ms|reset] - Set how long messages are merged into one prompt
+/loglevel [level] - Show or change the bridge's log level
+/version - Show which build of the bridge is running
+/feedback &lt;message&gt; - Send feedback to the bot operators
+/help - Show this help message`,
+
+	// Command menu descriptions (SetMyCommands)
+	"cmd.help":           "Show all available commands",
+	"cmd.sessions":       "List sessions",
+	"cmd.selectsession":  "Select a session (menu)",
+	"cmd.deletesessions": "Delete sessions (menu)",
+	"cmd.status":         "Show current status",
+	"cmd.model":          "Select AI model",
+	"cmd.route":          "Set agent routing",
+	"cmd.new":            "Create a new session",
+	"cmd.fork":           "Fork the current session",
+	"cmd.abort":          "Abort current request",
+	"cmd.keyboard":       "Show/hide quick action keyboard",
+	"cmd.lang":           "Change bot language",
+	"cmd.alias":          "Manage command aliases",
+	"cmd.feedback":       "Send feedback to the operators",
+	"cmd.server":         "Switch OpenCode server",
+	"cmd.stats":          "Show usage statistics",
+	"cmd.fav":            "Manage favorite sessions",
+	"cmd.init":           "Generate AGENTS.md for the project",
+}
@@ -0,0 +1,71 @@
+// Package i18n provides the message catalog for all bot-facing strings.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang is a catalog language code, matching Telegram's IETF language_code prefix
+type Lang string
+
+const (
+	English Lang = "en"
+	Chinese Lang = "zh"
+
+	// Default is used when a chat has not selected a language
+	Default = English
+)
+
+var catalogs = map[Lang]map[string]string{
+	English: en,
+	Chinese: zh,
+}
+
+var names = map[Lang]string{
+	English: "English",
+	Chinese: "繁體中文",
+}
+
+// T looks up key in the catalog for lang and formats it with args.
+// Falls back to the default language, then to the key itself.
+func T(lang Lang, key string, args ...interface{}) string {
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		msg, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Parse resolves a user- or Telegram-supplied language code (e.g. "zh-TW", "EN")
+// to a supported language
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	lang := Lang(code)
+	if _, ok := catalogs[lang]; ok {
+		return lang, true
+	}
+	return "", false
+}
+
+// Supported returns all catalog languages in display order
+func Supported() []Lang {
+	return []Lang{English, Chinese}
+}
+
+// Name returns the language's display name in its own language
+func (l Lang) Name() string {
+	if name, ok := names[l]; ok {
+		return name
+	}
+	return string(l)
+}
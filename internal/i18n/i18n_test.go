@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTFormatsArgs(t *testing.T) {
+	assert.Equal(t, "🔄 Switched to build", T(English, "agent.switched", "build"))
+	assert.Equal(t, "🔄 已切換至 build", T(Chinese, "agent.switched", "build"))
+}
+
+func TestTFallsBack(t *testing.T) {
+	assert.Equal(t, T(English, "busy"), T(Lang("fr"), "busy"), "unknown language uses default catalog")
+	assert.Equal(t, "no.such.key", T(English, "no.such.key"))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code string
+		want Lang
+		ok   bool
+	}{
+		{"en", English, true},
+		{"EN", English, true},
+		{"zh-TW", Chinese, true},
+		{"zh_hant", Chinese, true},
+		{" zh ", Chinese, true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.code)
+		assert.Equal(t, tt.ok, ok, tt.code)
+		assert.Equal(t, tt.want, got, tt.code)
+	}
+}
+
+// Every catalog must define the same keys with the same format verbs,
+// otherwise T would silently fall back or mis-format
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Supported() {
+		catalog := catalogs[lang]
+		for key, msg := range catalogs[Default] {
+			translated, ok := catalog[key]
+			if !assert.True(t, ok, "%s: missing key %q", lang, key) {
+				continue
+			}
+			assert.Equal(t, strings.Count(msg, "%"), strings.Count(translated, "%"), "%s: format verbs differ for %q", lang, key)
+		}
+		assert.Len(t, catalog, len(catalogs[Default]), "%s: extra keys", lang)
+	}
+}
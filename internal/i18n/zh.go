@@ -0,0 +1,319 @@
+package i18n
+
+var zh = map[string]string{
+	// OpenCode connection and API errors
+	"opencode.unavailable":           "⚠️ 目前無法連線到 OpenCode，請稍後再試。",
+	"opencode.rate_limited":          "⏳ 模型目前受到速率限制，請稍後再試。",
+	"opencode.api_error":             "❌ OpenCode：%s",
+	"opencode.retrying":              "⏳ OpenCode 回傳錯誤，重試中...",
+	"opencode.retrying.rate_limited": "⏳ 模型受到速率限制，重試中...",
+
+	// Generic
+	"error":             "❌ 錯誤：%v",
+	"busy":              "⏳ 仍在處理上一個請求...",
+	"processing":        "⏳ 處理中...",
+	"processing.audio":  "🎙️ 語音轉文字中...",
+	"processing.image":  "🖼️ 處理圖片中...",
+	"processing.images": "🖼️ 處理 %d 張圖片中...",
+	"processing.video":  "🎞️ 擷取影片畫面中...",
+	"callback.invalid":  "❌ 無效的回呼資料：%s",
+	"nav.prev":          "◀️ 上一頁",
+	"nav.next":          "下一頁 ▶️",
+	"cancel":            "❌ 取消",
+
+	// Responses
+	"response.completed": "✅ 回應完成",
+	"response.empty":     "✅ 回應完成（無內容）",
+	"pages.show_more":    "▶️ 顯示更多 (%d/%d)",
+	"pages.expired":      "⌛ 此回應已過期，無法再顯示剩餘內容",
+
+	// Media
+	"photo.invalid":           "❌ 錯誤：找不到有效的圖片",
+	"photo.download_failed":   "❌ 下載圖片失敗：%s",
+	"image.send_failed":       "⚠️ 無法傳送圖片 %s：%s",
+	"media.unsupported":       "⚠️ 目前不支援此媒體類型",
+	"audio.too_large":         "❌ 音訊檔案過大（上限 %d MB）",
+	"audio.download_failed":   "❌ 下載音訊失敗：%s",
+	"audio.transcribe_failed": "❌ 語音轉文字失敗：%s",
+	"audio.no_speech":         "⚠️ 音訊中未偵測到語音",
+	"video.too_large":         "❌ 影片檔案過大（上限 %d MB）",
+	"video.failed":            "❌ 無法讀取影片畫面：%s",
+	"sticker.no_session":      "📌 已收到貼圖（沒有進行中的 session）",
+
+	// Progress
+	"progress.running":       "🔧 執行中：%s",
+	"progress.running_title": "🔧 執行 %s 中：%s…",
+	"progress.step":          "第 %d 步",
+	"progress.tool_call":     "1 次工具呼叫",
+	"progress.tool_calls":    "%d 次工具呼叫",
+
+	// Agents and routing
+	"agent.switched":      "🔄 已切換至 %s",
+	"agent.unknown":       "❌ 未知的 agent：%s\n\n可用的 agents：\n",
+	"agent.select":        "🤖 選擇 OHO Agent：\n\n",
+	"route.cleared":       "✅ 已清除聊天室 agent 設定，改用全域 agent。",
+	"route.set":           "✅ 聊天室 agent 已設為：%s",
+	"route.status_chat":   "🎯 聊天室 Agent：%s\n📍 全域 Agent：%s",
+	"route.status_global": "📍 使用全域 Agent：%s\n（無聊天室專屬設定）",
+
+	// Models
+	"model.select":  "🤖 選擇模型：\n\n",
+	"model.set":     "✅ 模型已設為：%s",
+	"model.refresh": "🔄 重新整理",
+
+	// Permissions
+	"permission.title":              "🔐 **權限請求**",
+	"permission.body":               "**權限：** %s\n**範圍：** %s",
+	"permission.details":            "**詳細資訊：**",
+	"permission.allowed_once":       "✅ 已允許（僅此一次）",
+	"permission.allowed_always":     "✅ 已允許（永遠）",
+	"permission.rejected":           "❌ 已拒絕",
+	"permission.answered_elsewhere": "↪️ 已在 Telegram 以外回覆",
+	"permission.button.once":        "✅ 允許一次",
+	"permission.button.always":      "✅ 永遠允許",
+	"permission.button.reject":      "❌ 拒絕",
+
+	// Questions
+	"question.header":             "🤔 OpenCode 有問題想確認：\n\n",
+	"question.custom_allowed":     "  • ✏️ 可自訂答案\n",
+	"question.answer_first":       "請回答第一個問題：",
+	"question.type_custom":        "%s\n\n✏️ 請輸入你的自訂答案：",
+	"question.submitted":          "%s\n\n✅ 已送出答案：%s",
+	"question.submit_failed":      "❌ 送出答案失敗：%v\n\n已保留你的答案，再次傳送即可重試：\n<code>%s</code>",
+	"question.custom_pending":     "\n\n✏️ 你的下一則訊息仍會回答：%s",
+	"question.answered_elsewhere": "↪️ 已在 Telegram 以外回覆",
+	"question.dismissed":          "%s\n\n🚫 已在 Telegram 以外略過",
+	"question.error":              "❌ 處理問題時發生錯誤：%v",
+	"question.button.submit":      "✅ 送出",
+	"question.button.custom":      "✏️ 自訂輸入...",
+	"pending.restored":            "♻️ 重新送出重啟前尚未回覆的 %d 個請求：",
+	"pending.closed":              "⌛ OpenCode 已不再等待此請求",
+
+	// Sessions
+	"session.created":            "✅ 已建立新 session：%s (%s)",
+	"session.switched":           "✅ 已切換至 session：%s (%s)",
+	"session.not_found":          "❌ 找不到 session %s",
+	"session.deleted_externally": "🗑 Session %s 已在 OpenCode 中刪除。下一則訊息將建立新的 session。",
+	"session.id_required":        "❌ 請提供 session ID：/session &lt;id|別名&gt;",
+	"sessions.none":              "沒有任何 session。使用 /newsession 建立一個。",
+	"sessions.none_short":        "沒有任何 session。",
+	"sessions.no_primary":        "沒有主要 session。",
+	"sessions.header":            "📋 <b>Sessions</b>（顯示 %d / %d）\n",
+	"sessions.stats":             "💬 %s 則訊息 · 🪙 %s tokens",
+	"sessions.more":              "💡 <i>...還有 %d 個 session</i>",
+	"sessions.tip":               "\n<b>提示：</b>使用 <code>/session &lt;id&gt;</code> 或 <code>/selectsession</code> 開啟選單",
+	"sessions.select_page":       "📋 <b>選擇 Session</b>（第 %d/%d 頁）",
+	"sessions.select_expired":    "❌ Session 清單已過期，請重新使用 /selectsession。",
+	"abort.none":                 "❌ 沒有可中止的 session",
+	"abort.done":                 "🛑 已中止 session %s",
+	"delete.id_required":         "❌ 請提供 session ID：/deletesession &lt;id&gt;",
+	"delete.done":                "🗑️ 已刪除 session：%s\n📝 標題：%s",
+	"delete.expired":             "❌ Session 清單已過期，請重新使用 /deletesessions。",
+	"delete.select_page":         "🗑️ 選擇要刪除的 session（第 %d/%d 頁）：",
+	"delete.previous":            "⬅️ 上一頁",
+	"delete.next":                "下一頁 ➡️",
+	"delete.confirm":             "⚠️ 確定要刪除？\n\n📝 標題：%s\n🆔 ID：%s\n📂 目錄：%s",
+	"delete.yes":                 "✅ 是，刪除",
+	"delete.gone":                "❌ 找不到 session 或已被刪除",
+	"delete.success":             "✅ 刪除成功！\n\n📝 標題：%s\n🆔 ID：%s",
+	"delete.cancelled":           "❌ 已取消刪除",
+
+	// Status
+	"status.title":         "📊 狀態：",
+	"status.session":       "Session：%s",
+	"status.session_id":    "Session ID：%s",
+	"status.directory":     "目錄：%s",
+	"status.agent":         "Agent：%s",
+	"status.model":         "模型：%s",
+	"status.status":        "狀態：%s",
+	"status.opencode":      "OpenCode：%s",
+	"status.none":          "（無）",
+	"status.unknown":       "（未知）",
+	"status.idle":          "閒置",
+	"status.processing":    "處理中",
+	"status.error":         "錯誤",
+	"status.health_ok":     "正常",
+	"status.health_bad":    "異常",
+	"status.health_nodata": "未知",
+	"banner.title":         "📌 目前的 session",
+
+	// Relative time
+	"ago.now":     "剛剛",
+	"ago.minute":  "1 分鐘前",
+	"ago.minutes": "%d 分鐘前",
+	"ago.hour":    "1 小時前",
+	"ago.hours":   "%d 小時前",
+	"ago.day":     "1 天前",
+	"ago.days":    "%d 天前",
+	"ago.week":    "1 週前",
+	"ago.weeks":   "%d 週前",
+	"ago.month":   "1 個月前",
+	"ago.months":  "%d 個月前",
+	"ago.year":    "1 年前",
+	"ago.years":   "%d 年前",
+
+	// Quick actions
+	"quick.new":            "🆕 新 session",
+	"quick.status":         "📊 狀態",
+	"quick.abort":          "🛑 中止",
+	"quick.switch":         "🔄 切換 agent",
+	"keyboard.placeholder": "傳訊息給 OpenCode...",
+	"keyboard.disabled":    "⚠️ 快捷鍵盤未啟用（請設定 TELEGRAM_QUICK_KEYBOARD=true）",
+	"keyboard.hidden":      "⌨️ 已隱藏快捷鍵盤。使用 /keyboard 重新顯示。",
+	"keyboard.enabled":     "⌨️ 已啟用快捷鍵盤",
+
+	// Response actions (inline buttons under a completed response)
+	"action.continue":        "▶️ 繼續",
+	"action.retry":           "🔁 重試",
+	"action.new":             "🆕 新 session",
+	"action.explain":         "💡 詳細說明",
+	"action.prompt.continue": "繼續",
+	"action.prompt.explain":  "請更詳細地說明你剛才的回答",
+	"action.retry_none":      "⚠️ 此 session 沒有可重試的訊息",
+
+	// Language
+	"lang.current": "🌐 語言：%s\n\n可用：%s\n用法：/lang &lt;code&gt;",
+	"lang.set":     "🌐 語言已設為 %s",
+	"lang.unknown": "❌ 未知的語言：%s\n\n可用：%s",
+
+	// Aliases
+	"alias.usage":           "用法：\n/alias add &lt;名稱&gt; &lt;展開內容&gt;\n/alias rm &lt;名稱&gt;\n/alias list\n\n以 ; 分隔多個步驟，例如 /alias add b /switch build; continue",
+	"alias.added":           "✅ 別名 /%s → %s",
+	"alias.removed":         "🗑 已移除別名 /%s",
+	"alias.not_found":       "❌ 找不到別名 /%s",
+	"alias.list_empty":      "尚未定義別名。使用 /alias add &lt;名稱&gt; &lt;展開內容&gt; 新增",
+	"alias.list_title":      "🔖 別名：",
+	"alias.invalid_name":    "❌ 無效的別名：%s（僅限 a-z、0-9 與 _，最多 32 個字元）",
+	"alias.reserved":        "❌ /%s 與內建指令衝突",
+	"alias.unknown_command": "❌ 未知的指令：/%s",
+
+	// Photo prompt
+	"photoprompt.current": "🖼️ 無說明文字的圖片會附上：\n%s\n\n用法：/photoprompt &lt;文字&gt; | off | reset",
+	"photoprompt.none":    "🖼️ 無說明文字的圖片會單獨送出。\n\n用法：/photoprompt &lt;文字&gt; | off | reset",
+	"photoprompt.set":     "✅ 無說明文字的圖片將附上：\n%s",
+	"photoprompt.off":     "✅ 無說明文字的圖片將單獨送出",
+	"photoprompt.reset":   "✅ 圖片提示已重設為預設值",
+
+	// Session aliases
+	"session_alias.usage":        "用法：\n/alias-session &lt;名稱&gt; [session]\n/alias-session rm &lt;名稱&gt;\n/alias-session\n\n未指定 session 時會命名目前的 session，之後可用 /session &lt;名稱&gt; 切換。",
+	"session_alias.added":        "🏷 %s 現在指向 %s，可用 /session %[1]s 切換",
+	"session_alias.removed":      "🗑 已移除 session 別名 %s",
+	"session_alias.not_found":    "❌ 沒有名為 %s 的 session 別名",
+	"session_alias.invalid_name": "❌ 無效的 session 別名：%s（僅限 a-z、0-9 與 _，最多 32 個字元，且不可以 ses_ 開頭）",
+	"session_alias.no_session":   "❌ 目前沒有 session。請指定 session ID：/alias-session &lt;名稱&gt; &lt;session&gt;",
+	"session_alias.list_empty":   "尚無 session 別名。可用 /alias-session &lt;名稱&gt; 命名目前的 session",
+	"session_alias.list_title":   "🏷 Session 別名：",
+
+	// Favorites
+	"fav.usage":        "用法：\n/fav add [session]\n/fav rm &lt;session&gt;\n/fav list\n\n/fav add 未指定 session 時會加入目前的 session。",
+	"fav.added":        "⭐ 已將 %s 加入最愛",
+	"fav.exists":       "⭐ %s 已在最愛中",
+	"fav.removed":      "🗑 已將 %s 從最愛移除",
+	"fav.not_favorite": "❌ %s 不在最愛中",
+	"fav.not_found":    "❌ 找不到 session：%s",
+	"fav.no_session":   "❌ 目前沒有 session。請指定 session ID：/fav add &lt;session&gt;",
+	"fav.list_empty":   "尚無最愛的 session。可用 /fav add 加入目前的 session",
+	"fav.list_title":   "⭐ 最愛的 sessions：",
+
+	// Debounce
+	"debounce.current": "⏱️ 此聊天室中間隔 %d 毫秒內的訊息會合併為一個 prompt。\n\n用法：/debounce &lt;毫秒&gt; | reset",
+	"debounce.default": "⏱️ 間隔 %d 毫秒內的訊息會合併為一個 prompt（預設值）。\n\n用法：/debounce &lt;毫秒&gt; | reset",
+	"debounce.set":     "✅ 間隔 %d 毫秒內的訊息將合併為一個 prompt",
+	"debounce.reset":   "✅ 合併間隔已重設為預設值（%d 毫秒）",
+	"debounce.invalid": "❌ 請輸入 0 到 %d 之間的毫秒數，或 reset",
+
+	// Log level
+	"loglevel.current": "📝 日誌等級：<b>%s</b>\n\n用法：/loglevel debug|info|warn|error",
+	"loglevel.set":     "✅ 日誌等級已設為 <b>%s</b>",
+	"loglevel.invalid": "❌ 請輸入 debug、info、warn 或 error",
+
+	// Version
+	"version.info": "🏷 <b>opencode-telegram %s</b>\nCommit：<code>%s</code>\n建置時間：%s\nGo：%s",
+
+	// Feedback
+	"feedback.usage":    "用法：/feedback &lt;訊息&gt;",
+	"feedback.sent":     "✅ 感謝！您的意見已送給管理者。",
+	"feedback.disabled": "⚠️ 此機器人未啟用意見回饋",
+	"feedback.report":   "📝 意見回饋\n聊天室：%s\n使用者：%s\nSession：%s\n\n%s",
+
+	// Health alerts to the admin chat
+	"alert.unhealthy": "🚨 Bridge <b>異常</b>已持續 %s\n%s",
+	"alert.degraded":  "⚠️ Bridge <b>效能降低</b>已持續 %s\n%s",
+	"alert.recovered": "✅ Bridge 已恢復正常（歷時 %s）",
+
+	// Session forks
+	"fork.none":          "❌ 沒有可分支的 session，請先使用 /newsession。",
+	"fork.created":       "🌿 已分支為 <b>%s</b> (%s)\n↳ 上層：<code>%s</code>",
+	"fork.default_title": "%s 的分支",
+
+	// OpenCode servers
+	"server.list":     "🖥 <b>OpenCode 伺服器</b>（目前：<b>%s</b>）",
+	"server.switched": "✅ 已切換至伺服器 <b>%s</b>。下一則訊息會在該伺服器建立新的 session。",
+	"server.already":  "ℹ️ 目前已在使用伺服器 <b>%s</b>。",
+	"server.unknown":  "❌ 未知的伺服器：%s。使用 /server 查看清單。",
+	"server.single":   "ℹ️ 只設定了一個 OpenCode 伺服器。設定 OPENCODE_SERVERS 以新增更多。",
+
+	// Project init (/init)
+	"init.title":    "專案初始化",
+	"init.started":  "📝 正在以 %s 分析專案並撰寫 AGENTS.md，可能需要幾分鐘…",
+	"init.done":     "✅ 已產生 AGENTS.md。",
+	"init.no_model": "❌ 沒有可用於 /init 的模型。請先使用 /model 選擇。",
+
+	// Stats
+	"stats.report": "📊 此聊天室的使用統計\n\nPrompt：%d\n回應：%d\n錯誤：%d\nToken：%s\n平均回應時間：%s",
+
+	// Audit
+	"audit.title": "🔐 稽核紀錄，最新的在最後：",
+	"audit.empty": "此聊天室尚無稽核紀錄。",
+	"audit.usage": "❌ 用法：/audit [n]，n 介於 1 到 %d",
+
+	// Help
+	"help": `🆘 可用指令：
+
+/newsession [標題] - 建立新 session
+/fork [標題] - 將目前 session 分支為子 session
+/sessions - 列出 sessions 及其分支
+/selectsession - 從選單選擇 session
+/deletesessions - 刪除 session（互動選單）
+/session &lt;id|別名&gt; - 切換至指定 session
+/deletesession &lt;id&gt; - 直接刪除 session
+/abort - 中止目前 session
+/status - 顯示目前狀態
+/switch [agent] - 切換 OHO agent
+/route [agent] - 設定或查看聊天室 agent
+/keyboard [off] - 顯示或隱藏快捷鍵盤
+/lang [code] - 顯示或變更語言
+/photoprompt [文字|off|reset] - 設定無說明文字圖片的提示
+/fav add|rm|list - 管理最愛的 session，會列在 /selectsession 最前面
+/alias add|rm|list - 管理指令別名
+/alias-session &lt;名稱&gt; [session] - 為 session 命名，之後可用 /session &lt;名稱&gt;
+/server [名稱] - 顯示或切換 OpenCode 伺服器
+/init - 為目前的專案產生 AGENTS.md
+/stats - 顯示此聊天室的使用統計
+/audit [n] - 顯示最近的權限回覆、session 刪除與 agent/模型切換紀錄
+/debounce [毫秒|reset] - 設定訊息合併為一個 prompt 的間隔
+/loglevel [等級] - 顯示或變更 bridge 的日誌等級
+/version - 顯示執行中的 bridge 版本
+/feedback &lt;訊息&gt; - 傳送意見給機器人管理者
+/help - 顯示此說明`,
+
+	// Command menu descriptions (SetMyCommands)
+	"cmd.help":           "顯示所有可用指令",
+	"cmd.sessions":       "列出 sessions（表格檢視）",
+	"cmd.selectsession":  "選擇 session（互動選單）",
+	"cmd.deletesessions": "刪除 session（互動選單）",
+	"cmd.status":         "顯示目前狀態",
+	"cmd.model":          "選擇 AI 模型",
+	"cmd.route":          "設定 agent 路由",
+	"cmd.new":            "建立新 session",
+	"cmd.fork":           "分支目前 session",
+	"cmd.abort":          "中止目前請求",
+	"cmd.keyboard":       "顯示/隱藏快捷鍵盤",
+	"cmd.lang":           "變更語言",
+	"cmd.alias":          "管理指令別名",
+	"cmd.feedback":       "傳送意見給管理者",
+	"cmd.server":         "切換 OpenCode 伺服器",
+	"cmd.stats":          "顯示使用統計",
+	"cmd.fav":            "管理最愛的 session",
+	"cmd.init":           "為專案產生 AGENTS.md",
+}
@@ -0,0 +1,91 @@
+package keyframes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// MaxFrames is the most frames extracted from a single clip
+const MaxFrames = 3
+
+// Extractor pulls still JPEG frames out of GIFs and short videos using ffmpeg
+type Extractor struct {
+	ffmpegPath string
+}
+
+// NewExtractor creates an extractor using the given ffmpeg binary.
+// An empty path means "ffmpeg" looked up on PATH.
+func NewExtractor(ffmpegPath string) *Extractor {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	return &Extractor{ffmpegPath: ffmpegPath}
+}
+
+// Available reports whether the ffmpeg binary can be found
+func (e *Extractor) Available() bool {
+	_, err := exec.LookPath(e.ffmpegPath)
+	return err == nil
+}
+
+// Extract returns 1-3 frames spread evenly across the clip.
+// duration is in seconds as reported by Telegram; 0 extracts only the first frame.
+func (e *Extractor) Extract(ctx context.Context, data []byte, duration int) ([][]byte, error) {
+	dir, err := os.MkdirTemp("", "keyframes-")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input")
+	if err := os.WriteFile(input, data, 0600); err != nil {
+		return nil, fmt.Errorf("write input: %w", err)
+	}
+
+	var frames [][]byte
+	for i, ts := range frameTimestamps(duration, MaxFrames) {
+		output := filepath.Join(dir, fmt.Sprintf("frame%d.jpg", i))
+		cmd := exec.CommandContext(ctx, e.ffmpegPath,
+			"-v", "error",
+			"-ss", strconv.FormatFloat(ts, 'f', 2, 64),
+			"-i", input,
+			"-frames:v", "1",
+			"-q:v", "3",
+			"-y", output,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("ffmpeg: %w: %s", err, out)
+		}
+
+		frame, err := os.ReadFile(output)
+		if err != nil {
+			return nil, fmt.Errorf("read frame: %w", err)
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// frameTimestamps picks up to max seek offsets (in seconds), one per second
+// of footage, each centred in its slice of the clip
+func frameTimestamps(duration int, max int) []float64 {
+	count := duration
+	if count > max {
+		count = max
+	}
+	if count < 1 {
+		return []float64{0}
+	}
+
+	timestamps := make([]float64, count)
+	step := float64(duration) / float64(count)
+	for i := range timestamps {
+		timestamps[i] = step * (float64(i) + 0.5)
+	}
+	return timestamps
+}
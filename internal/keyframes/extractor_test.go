@@ -0,0 +1,49 @@
+package keyframes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameTimestamps(t *testing.T) {
+	assert.Equal(t, []float64{0}, frameTimestamps(0, MaxFrames))
+	assert.Equal(t, []float64{0.5}, frameTimestamps(1, MaxFrames))
+	assert.Equal(t, []float64{0.5, 1.5}, frameTimestamps(2, MaxFrames))
+	assert.Equal(t, []float64{5, 15, 25}, frameTimestamps(30, MaxFrames))
+}
+
+// fakeFFmpeg writes a script that copies its -ss argument into the output file
+func fakeFFmpeg(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	script := "#!/bin/sh\nwhile [ $# -gt 1 ]; do [ \"$1\" = -ss ] && ts=$2; shift; done\nprintf '%s' \"$ts\" > \"$1\"\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestExtract(t *testing.T) {
+	e := NewExtractor(fakeFFmpeg(t))
+	require.True(t, e.Available())
+
+	frames, err := e.Extract(context.Background(), []byte("clip"), 6)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("1.00"), []byte("3.00"), []byte("5.00")}, frames)
+}
+
+func TestExtractFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho 'invalid data' >&2\nexit 1\n"), 0755))
+
+	_, err := NewExtractor(path).Extract(context.Background(), []byte("clip"), 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid data")
+}
+
+func TestAvailableMissingBinary(t *testing.T) {
+	assert.False(t, NewExtractor(filepath.Join(t.TempDir(), "missing")).Available())
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// ErrorHook receives the error lines: their message and attributes, those
+// of the logger included, as strings. It runs on the logging goroutine, so
+// it must not block, and must not log errors itself.
+type ErrorHook func(message string, fields map[string]string)
+
+var errorHook atomic.Pointer[ErrorHook]
+
+// SetErrorHook makes every error line, of the standard logger too, reach
+// hook (nil: none), e.g. to report it
+func SetErrorHook(hook ErrorHook) {
+	if hook == nil {
+		errorHook.Store(nil)
+		return
+	}
+	errorHook.Store(&hook)
+}
+
+func (h lazyHandler) reportError(r slog.Record) {
+	hook := errorHook.Load()
+	if hook == nil {
+		return
+	}
+	fields := make(map[string]string, len(h.attrs)+r.NumAttrs())
+	for _, attr := range h.attrs {
+		fields[attr.Key] = attr.Value.String()
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		fields[attr.Key] = attr.Value.String()
+		return true
+	})
+	(*hook)(r.Message, fields)
+}
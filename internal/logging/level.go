@@ -0,0 +1,72 @@
+// Package logging sets up the bridge's structured log (log/slog) and its
+// level, which can be changed while the bridge runs.
+//
+// Each package logs through a component logger, e.g. For("webhook"), and
+// adds the account, chat and session a line is about as attributes, so that
+// the lines of multi-account deployments can be filtered.
+package logging
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// Level is the minimum severity of the lines logged
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// DefaultLevel is the level without LOG_LEVEL
+const DefaultLevel = LevelInfo
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+var slogLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return "unknown"
+	}
+	return levelNames[l]
+}
+
+// ParseLevel reads a level name (debug, info, warn or error)
+func ParseLevel(name string) (Level, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		name = "warn"
+	}
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), true
+		}
+	}
+	return 0, false
+}
+
+// level is shared by every handler, so a change applies at once
+var level = new(slog.LevelVar)
+
+// SetLevel changes the level of the lines logged from now on
+func SetLevel(l Level) {
+	if l < LevelDebug || l > LevelError {
+		return
+	}
+	level.Set(slogLevels[l])
+}
+
+// CurrentLevel returns the level lines are logged at
+func CurrentLevel() Level {
+	current := level.Level()
+	for i, l := range slogLevels {
+		if current <= l {
+			return Level(i)
+		}
+	}
+	return LevelError
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level Level
+		ok    bool
+	}{
+		{"debug", LevelDebug, true},
+		{" INFO ", LevelInfo, true},
+		{"warning", LevelWarn, true},
+		{"error", LevelError, true},
+		{"verbose", 0, false},
+	}
+	for _, tt := range tests {
+		level, ok := ParseLevel(tt.name)
+		if ok != tt.ok || (ok && level != tt.level) {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, %v", tt.name, level, ok, tt.level, tt.ok)
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	defer SetLevel(DefaultLevel)
+	for _, l := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		SetLevel(l)
+		if got := CurrentLevel(); got != l {
+			t.Errorf("CurrentLevel() = %v after SetLevel(%v)", got, l)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var out bytes.Buffer
+	if err := Setup(&out, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	defer Setup(os.Stderr, FormatText)
+	defer SetLevel(DefaultLevel)
+	logger := For("bridge")
+
+	SetLevel(LevelInfo)
+	logger.Debug("hidden")
+	log.Printf("[DEBUG] hidden too")
+	logger.Info("shown")
+	if got := out.String(); bytes.Contains(out.Bytes(), []byte("hidden")) || !bytes.Contains(out.Bytes(), []byte("shown")) {
+		t.Errorf("expected only the info line at info level, got %q", got)
+	}
+
+	out.Reset()
+	SetLevel(LevelWarn)
+	logger.Info("info")
+	log.Printf("Warning: careful")
+	logger.Error("broken")
+	if got := out.String(); bytes.Contains(out.Bytes(), []byte("info")) || !bytes.Contains(out.Bytes(), []byte("careful")) || !bytes.Contains(out.Bytes(), []byte("broken")) {
+		t.Errorf("expected warnings and errors at warn level, got %q", got)
+	}
+
+	out.Reset()
+	SetLevel(LevelDebug)
+	logger.Debug("details")
+	if !bytes.Contains(out.Bytes(), []byte("details")) {
+		t.Errorf("expected debug lines at debug level, got %q", out.String())
+	}
+}
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Formats of the log lines
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// handler is where every logger writes, replaced by Setup
+var handler atomic.Pointer[slog.Handler]
+
+func init() {
+	setHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+func setHandler(h slog.Handler) {
+	handler.Store(&h)
+}
+
+// Setup writes the log to out as text (key=value) or JSON lines, for the
+// loggers of For and the standard logger alike
+func Setup(out io.Writer, format string) error {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "", FormatText:
+		setHandler(slog.NewTextHandler(out, opts))
+	case FormatJSON:
+		setHandler(slog.NewJSONHandler(out, opts))
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", format)
+	}
+	slog.SetDefault(slog.New(lazyHandler{}))
+	// After SetDefault, which points the standard logger at slog too
+	log.SetFlags(0)
+	log.SetOutput(stdWriter{})
+	return nil
+}
+
+// For returns the logger of a component, e.g. "bridge" or "sse". It can be
+// created before Setup, e.g. in a package variable.
+func For(component string) *slog.Logger {
+	return slog.New(lazyHandler{}).With("component", component)
+}
+
+// lazyHandler hands records to the handler of the last Setup, with the
+// attributes and groups added to its logger
+type lazyHandler struct {
+	with []func(slog.Handler) slog.Handler
+	// attrs are those of WithAttrs, for the error hook
+	attrs []slog.Attr
+}
+
+func (h lazyHandler) current() slog.Handler {
+	inner := *handler.Load()
+	for _, with := range h.with {
+		inner = with(inner)
+	}
+	return inner
+}
+
+func (h lazyHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h lazyHandler) Handle(ctx context.Context, r slog.Record) error {
+	r = redactRecord(r)
+	err := h.current().Handle(ctx, r)
+	if r.Level >= slog.LevelError {
+		h.reportError(r)
+	}
+	return err
+}
+
+func (h lazyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	attrs = redactAttrs(attrs)
+	derived := h.derive(func(inner slog.Handler) slog.Handler { return inner.WithAttrs(attrs) })
+	derived.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)
+	return derived
+}
+
+func (h lazyHandler) WithGroup(name string) slog.Handler {
+	return h.derive(func(inner slog.Handler) slog.Handler { return inner.WithGroup(name) })
+}
+
+func (h lazyHandler) derive(with func(slog.Handler) slog.Handler) lazyHandler {
+	return lazyHandler{with: append(h.with[:len(h.with):len(h.with)], with), attrs: h.attrs}
+}
+
+// stdWriter turns lines of the standard logger, e.g. from libraries, into
+// records. A leading "[TAG]" becomes the component, and "[DEBUG]",
+// "[WARN]"/"Warning:" and "[ERROR]" the level.
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	line := string(bytes.TrimRight(p, "\n"))
+	l := levelOf(line)
+	if l < level.Level() {
+		return len(p), nil
+	}
+	logger := slog.New(lazyHandler{})
+	if tag, rest, ok := cutTag(line); ok {
+		switch tag {
+		case "DEBUG", "INFO", "WARN", "ERROR":
+		default:
+			logger = logger.With("component", strings.ToLower(tag))
+		}
+		line = rest
+	}
+	logger.Log(context.Background(), l, line)
+	return len(p), nil
+}
+
+// levelOf finds the level of a standard logger line from its tag
+func levelOf(line string) slog.Level {
+	switch {
+	case strings.Contains(line, "[DEBUG]"):
+		return slog.LevelDebug
+	case strings.Contains(line, "[ERROR]"), strings.Contains(line, "[PANIC]"):
+		return slog.LevelError
+	case strings.Contains(line, "[WARN]"), strings.Contains(line, "Warning:"):
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// cutTag splits "[TAG] rest" lines
+func cutTag(line string) (tag, rest string, ok bool) {
+	if !strings.HasPrefix(line, "[") {
+		return "", line, false
+	}
+	end := strings.IndexByte(line, ']')
+	if end < 0 {
+		return "", line, false
+	}
+	return line[1:end], strings.TrimSpace(line[end+1:]), true
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForJSON(t *testing.T) {
+	// Created before Setup, like a package variable
+	logger := For("sse").With("account", "work")
+
+	var out bytes.Buffer
+	require.NoError(t, Setup(&out, FormatJSON))
+	defer Setup(os.Stderr, FormatText)
+
+	logger.Info("event received", "session", "ses_1")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "event received", line["msg"])
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "sse", line["component"])
+	assert.Equal(t, "work", line["account"])
+	assert.Equal(t, "ses_1", line["session"])
+}
+
+func TestStandardLoggerLines(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Setup(&out, FormatJSON))
+	defer Setup(os.Stderr, FormatText)
+
+	log.Printf("[WEBHOOK] [ERROR] handler failed")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "[ERROR] handler failed", line["msg"])
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, "webhook", line["component"])
+}
+
+func TestSetupUnknownFormat(t *testing.T) {
+	assert.Error(t, Setup(os.Stderr, "xml"))
+}
+
+func TestErrorHook(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Setup(&out, FormatJSON))
+	defer Setup(os.Stderr, FormatText)
+
+	var messages []string
+	var fields []map[string]string
+	SetErrorHook(func(message string, f map[string]string) {
+		messages = append(messages, message)
+		fields = append(fields, f)
+	})
+	defer SetErrorHook(nil)
+
+	logger := For("bridge").With("account", "work")
+	logger.Warn("slow")
+	logger.Error("send failed", "session", "ses_1", "error", errors.New("boom"))
+	log.Printf("[WEBHOOK] [ERROR] handler failed")
+
+	require.Equal(t, []string{"send failed", "[ERROR] handler failed"}, messages)
+	assert.Equal(t, map[string]string{"component": "bridge", "account": "work", "session": "ses_1", "error": "boom"}, fields[0])
+	assert.Equal(t, map[string]string{"component": "webhook"}, fields[1])
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// redacted replaces a secret in log lines and chat messages
+const redacted = "[REDACTED]"
+
+// minSecretLength keeps short values, e.g. a placeholder, from redacting
+// common words
+const minSecretLength = 8
+
+// botTokenPattern matches Telegram bot tokens, also inside the URLs of Bot
+// API requests and file downloads (https://api.telegram.org/file/bot<token>/...).
+// The bot ID before the colon is kept, as it is not secret and tells the
+// bots apart.
+var botTokenPattern = regexp.MustCompile(`(\d{5,}):[A-Za-z0-9_-]{30,}`)
+
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// AddSecret makes values, e.g. webhook secrets and API keys, redacted from
+// every log line and from the messages passed through Redact. Bot tokens
+// are redacted without being added.
+func AddSecret(values ...string) {
+	secrets.Lock()
+	defer secrets.Unlock()
+	for _, value := range values {
+		if len(value) < minSecretLength || slices.Contains(secrets.values, value) {
+			continue
+		}
+		secrets.values = append(secrets.values, value)
+	}
+}
+
+// Redact replaces the bot tokens and added secrets in s
+func Redact(s string) string {
+	s = botTokenPattern.ReplaceAllString(s, "$1:"+redacted)
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, secret := range secrets.values {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// redactRecord returns r with its message and attributes redacted
+func redactRecord(r slog.Record) slog.Record {
+	clean := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return clean
+}
+
+func redactAttrs(attrs []slog.Attr) []slog.Attr {
+	clean := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		clean[i] = redactAttr(attr)
+	}
+	return clean
+}
+
+// redactAttr redacts string values, and values such as errors whose text
+// holds a secret
+func redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(Redact(attr.Value.String()))
+	case slog.KindAny:
+		text := attr.Value.String()
+		if clean := Redact(text); clean != text {
+			attr.Value = slog.StringValue(clean)
+		}
+	case slog.KindGroup:
+		attr.Value = slog.GroupValue(redactAttrs(attr.Value.Group())...)
+	}
+	return attr
+}
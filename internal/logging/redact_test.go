@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsawq"
+
+func TestRedact(t *testing.T) {
+	AddSecret("webhook-secret-value", "short")
+
+	assert.Equal(t, "https://api.telegram.org/file/bot123456789:[REDACTED]/photos/file_1.jpg",
+		Redact("https://api.telegram.org/file/bot"+testToken+"/photos/file_1.jpg"))
+	assert.Equal(t, "secret [REDACTED] rejected", Redact("secret webhook-secret-value rejected"))
+	assert.Equal(t, "a short message at 12:30", Redact("a short message at 12:30"), "values shorter than 8 bytes are not secrets")
+}
+
+func TestRedactLogLines(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Setup(&out, FormatJSON))
+	defer Setup(os.Stderr, FormatText)
+
+	var fields map[string]string
+	SetErrorHook(func(message string, f map[string]string) { fields = f })
+	defer SetErrorHook(nil)
+
+	err := fmt.Errorf("download: %w", errors.New(`Get "https://api.telegram.org/file/bot`+testToken+`/a.jpg": timeout`))
+	For("telegram").With("url", "https://api.telegram.org/bot"+testToken+"/getMe").Error("Download failed "+testToken, "error", err)
+	assert.Equal(t, "https://api.telegram.org/bot123456789:[REDACTED]/getMe", fields["url"])
+	assert.NotContains(t, fields["error"], testToken)
+
+	log.Printf("[ERROR] request to bot%s failed", testToken)
+	assert.NotContains(t, out.String(), testToken)
+	assert.Contains(t, out.String(), "bot123456789:[REDACTED]/a.jpg")
+}
@@ -14,15 +14,40 @@ var (
 			Help:    "Latency of SSE event processing",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"event_type"},
+		[]string{"account", "chat", "event_type"},
+	)
+
+	EventErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_errors_total",
+			Help: "Total number of OpenCode events a bridge failed to handle",
+		},
+		[]string{"account", "chat", "event_type"},
 	)
 
-	TelegramMessageSendLatency = promauto.NewHistogram(
+	TelegramMessageSendLatency = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "telegram_message_send_latency_seconds",
 			Help:    "Latency of Telegram message send operations",
 			Buckets: prometheus.DefBuckets,
 		},
+		[]string{"account", "chat"},
+	)
+
+	TelegramFloodWaits = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telegram_flood_waits_total",
+			Help: "Total number of Telegram 429 responses waited out before retrying",
+		},
+		[]string{"account", "chat"},
+	)
+
+	TelegramErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telegram_errors_total",
+			Help: "Total number of failed Telegram Bot API requests, by class (flood_wait, not_modified, blocked, parse, bad_request, server, network, other)",
+		},
+		[]string{"account", "chat", "class"},
 	)
 
 	ActiveSSEConnections = promauto.NewGauge(
@@ -39,12 +64,240 @@ var (
 		},
 		[]string{"error_type"},
 	)
+
+	SSEEventBacklog = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sse_event_backlog",
+			Help: "Number of events received from OpenCode's event streams and not yet passed on to the bridges",
+		},
+	)
+
+	EventBusPending = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "event_bus_pending",
+			Help: "Number of events handed to the bridges and not yet handled",
+		},
+	)
+
+	// EventLag grows when events back up: handling keeps up while it stays
+	// in the lowest buckets
+	EventLag = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "event_processing_lag_seconds",
+			Help:    "Time from an OpenCode event's timestamp until a bridge started handling it",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"event_type"},
+	)
+
+	SSEEventsDropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sse_events_dropped_total",
+			Help: "Total number of SSE events dropped because the event backlog was full",
+		},
+		[]string{"event_type"},
+	)
+
+	EventDecodeErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_decode_errors_total",
+			Help: "Total number of OpenCode events that failed to decode (invalid) or, with strict decoding, did not match the known schema (unknown_field, untyped)",
+		},
+		[]string{"event_type", "reason"},
+	)
+
+	EventsDeduplicated = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "events_deduplicated_total",
+			Help: "Total number of events dropped because another event source already delivered them",
+		},
+		[]string{"source", "event_type"},
+	)
+
+	BridgeEntriesEvicted = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bridge_entries_evicted_total",
+			Help: "Total number of in-flight bridge entries (thinking messages, stream buffers, prompts) dropped after outliving their TTL",
+		},
+		[]string{"account", "chat", "map"},
+	)
+
+	// Sizes of each bridge's buffers, refreshed every few seconds, so stuck
+	// chats show up as buffers that keep growing
+	BridgeDebounceBuffers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bridge_debounce_buffers",
+			Help: "Number of sessions whose messages are waiting out the debounce window",
+		},
+		[]string{"account", "chat"},
+	)
+
+	BridgeDebounceMessages = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bridge_debounce_messages",
+			Help: "Number of messages waiting in debounce buffers",
+		},
+		[]string{"account", "chat"},
+	)
+
+	BridgeStreamBuffers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bridge_stream_buffers",
+			Help: "Number of responses being streamed into a thinking message",
+		},
+		[]string{"account", "chat"},
+	)
+
+	BridgeStreamBufferBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bridge_stream_buffer_bytes",
+			Help: "Size of the response text held while responses stream in",
+		},
+		[]string{"account", "chat"},
+	)
+
+	BridgePendingPermissions = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bridge_pending_permissions",
+			Help: "Number of permission prompts awaiting an answer",
+		},
+		[]string{"account", "chat"},
+	)
+
+	BridgePendingQuestions = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bridge_pending_questions",
+			Help: "Number of questions awaiting an answer",
+		},
+		[]string{"account", "chat"},
+	)
+
+	OpenCodeUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "opencode_up",
+			Help: "Whether the OpenCode server answered its last health check (1) or not (0)",
+		},
+		[]string{"server"},
+	)
+
+	OpenCodeInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "opencode_info",
+			Help: "Version an OpenCode server reported in its last health check, as a label; always 1",
+		},
+		[]string{"server", "version"},
+	)
+
+	HTTPRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of requests to the health and webhook servers, by endpoint and status code",
+		},
+		[]string{"server", "endpoint", "method", "code"},
+	)
+
+	HTTPRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time the health and webhook servers took to answer a request",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"server", "endpoint"},
+	)
+
+	BuildInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "build_info",
+			Help: "Build of the running bridge, as labels; always 1",
+		},
+		[]string{"version", "commit", "date", "go_version"},
+	)
+
+	// Per-chat usage from the state store (see /stats); gauges because the
+	// counts are restored after a restart
+	ChatPrompts = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "chat_prompts",
+			Help: "Number of prompts a chat sent to OpenCode",
+		},
+		[]string{"chat"},
+	)
+
+	ChatResponses = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "chat_responses",
+			Help: "Number of responses delivered to a chat",
+		},
+		[]string{"chat"},
+	)
+
+	ChatErrors = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "chat_errors",
+			Help: "Number of failed prompts and session errors in a chat",
+		},
+		[]string{"chat"},
+	)
+
+	ChatTokens = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "chat_tokens",
+			Help: "Number of tokens used by the responses delivered to a chat",
+		},
+		[]string{"chat"},
+	)
+
+	ChatResponseLatency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "chat_response_latency_average_seconds",
+			Help: "Average time from a chat's prompt to its response",
+		},
+		[]string{"chat"},
+	)
 )
 
-func ObserveSSEEventProcessing(eventType string, start time.Time) {
-	SSEEventProcessingLatency.WithLabelValues(eventType).Observe(time.Since(start).Seconds())
+// Account labels the metrics of one bot instance, so that the accounts of
+// a multi-account deployment can be told apart
+type Account struct {
+	Name string
+	Chat string
+}
+
+func ObserveSSEEventProcessing(account Account, eventType string, start time.Time) {
+	SSEEventProcessingLatency.WithLabelValues(account.Name, account.Chat, eventType).Observe(time.Since(start).Seconds())
+}
+
+func ObserveTelegramMessageSend(account Account, start time.Time) {
+	TelegramMessageSendLatency.WithLabelValues(account.Name, account.Chat).Observe(time.Since(start).Seconds())
+}
+
+// ObserveEventLag records how long ago an event happened as it is handled.
+// Events without a timestamp are skipped, and a timestamp ahead of the
+// local clock counts as no lag.
+func ObserveEventLag(eventType string, timestamp time.Time) {
+	if timestamp.IsZero() {
+		return
+	}
+	EventLag.WithLabelValues(eventType).Observe(max(time.Since(timestamp), 0).Seconds())
+}
+
+// SetOpenCodeHealth records the result of an OpenCode health check. The
+// version of the last answer that reported one is kept.
+func SetOpenCodeHealth(server string, up bool, version string) {
+	if up {
+		OpenCodeUp.WithLabelValues(server).Set(1)
+	} else {
+		OpenCodeUp.WithLabelValues(server).Set(0)
+	}
+	if version != "" {
+		OpenCodeInfo.DeletePartialMatch(prometheus.Labels{"server": server})
+		OpenCodeInfo.WithLabelValues(server, version).Set(1)
+	}
 }
 
-func ObserveTelegramMessageSend(start time.Time) {
-	TelegramMessageSendLatency.Observe(time.Since(start).Seconds())
+// DeleteBridgeBuffers removes the buffer gauges of a bridge that stopped
+func DeleteBridgeBuffers(account Account) {
+	for _, gauge := range []*prometheus.GaugeVec{BridgeDebounceBuffers, BridgeDebounceMessages, BridgeStreamBuffers, BridgeStreamBufferBytes, BridgePendingPermissions, BridgePendingQuestions} {
+		gauge.DeleteLabelValues(account.Name, account.Chat)
+	}
 }
@@ -0,0 +1,28 @@
+package opencode
+
+import "net/http"
+
+// authTransport attaches Config.APIKey as a bearer token to every request
+type authTransport struct {
+	base   http.RoundTripper
+	apiKey string
+}
+
+// withAuth wraps base so requests carry the API key. It returns base
+// unchanged when no key is configured (base may be nil for the default).
+func withAuth(base http.RoundTripper, apiKey string) http.RoundTripper {
+	if apiKey == "" {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &authTransport{base: base, apiKey: apiKey}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,59 @@
+package opencode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIKeySentAsBearerToken(t *testing.T) {
+	headers := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.URL.Path + " " + r.Header.Get("Authorization")
+		if r.URL.Path == "/event" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	config := Config{BaseURL: server.URL, APIKey: "secret"}
+
+	if _, err := NewClient(config).ListSessions(); err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if got := <-headers; got != "/session Bearer secret" {
+		t.Errorf("client request = %q", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, consumer := range []*SSEConsumer{NewSSEConsumer(config), NewSSEConsumerWithTransport(config, &http.Transport{})} {
+		if err := consumer.Connect(ctx); err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+		if got := <-headers; got != "/event Bearer secret" {
+			t.Errorf("SSE request = %q", got)
+		}
+		consumer.Close()
+	}
+}
+
+func TestNoAuthorizationWithoutAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Unexpected Authorization header %q", auth)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(Config{BaseURL: server.URL}).ListSessions(); err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+}
@@ -2,68 +2,132 @@ package opencode
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/user/opencode-telegram/internal/logging"
 )
 
+var logger = logging.For("opencode")
+
+// agentsCacheTTL is how long GetAgents reuses the last /agent response
+const agentsCacheTTL = 5 * time.Minute
+
 // Client wraps the OpenCode SDK HTTP client
 type Client struct {
 	config     Config
 	httpClient *http.Client
+	transport  *resilientTransport
+
+	// Clients for endpoints with their own timeout (see Timeouts); all
+	// share transport
+	healthClient   *http.Client
+	promptClient   *http.Client
+	messagesClient *http.Client
+
+	promptAsyncUnsupported atomic.Bool
+
+	// sessionDirs maps session IDs to their directory (see sessiondir.go)
+	sessionDirsMu sync.RWMutex
+	sessionDirs   map[string]string
+
+	agentsMu      sync.Mutex
+	agents        []string
+	agentsFetched time.Time
 }
 
 // NewClient creates a new OpenCode client
 func NewClient(config Config) *Client {
-	if config.BaseURL == "" {
-		config.BaseURL = "http://localhost:54321"
-	}
-
-	return &Client{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-	}
+	return NewClientWithTransport(config, nil)
 }
 
-// NewClientWithTransport creates a new OpenCode client with optional custom transport
+// NewClientWithTransport creates a new OpenCode client with optional custom transport.
+// Without one, the client gets its own NewTransport.
+// Requests go through DefaultRetryPolicy and a circuit breaker (see retry.go)
+// and are bounded by DefaultTimeouts (see timeouts.go).
 func NewClientWithTransport(config Config, transport *http.Transport) *Client {
 	if config.BaseURL == "" {
 		config.BaseURL = "http://localhost:54321"
 	}
 
-	httpClient := &http.Client{
-		Timeout: 60 * time.Second,
+	if transport == nil {
+		transport = NewTransport()
 	}
+	resilient := newResilientTransport(withAuth(transport, config.APIKey))
 
-	if transport != nil {
-		httpClient.Transport = transport
+	c := &Client{
+		config:         config,
+		transport:      resilient,
+		httpClient:     &http.Client{Transport: resilient},
+		healthClient:   &http.Client{Transport: resilient},
+		promptClient:   &http.Client{Transport: resilient},
+		messagesClient: &http.Client{Transport: resilient},
 	}
+	c.SetTimeouts(DefaultTimeouts)
+	return c
+}
 
+// WithDirectory returns a client for the same server working in another
+// directory, e.g. for a bot account with its own project. It shares the
+// connections, retry policy, timeouts and circuit breaker of c.
+func (c *Client) WithDirectory(directory string) *Client {
+	config := c.config
+	config.Directory = directory
 	return &Client{
-		config:     config,
-		httpClient: httpClient,
+		config:         config,
+		transport:      c.transport,
+		httpClient:     c.httpClient,
+		healthClient:   c.healthClient,
+		promptClient:   c.promptClient,
+		messagesClient: c.messagesClient,
 	}
 }
 
+// SetRetryPolicy changes how idempotent requests are retried
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.transport.mu.Lock()
+	defer c.transport.mu.Unlock()
+	c.transport.policy = policy
+}
+
+// SetCircuitBreaker opens the circuit after threshold consecutive failures
+// and keeps it open for cooldown (threshold 0 disables the breaker)
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.transport.breaker.mu.Lock()
+	defer c.transport.breaker.mu.Unlock()
+	c.transport.breaker.threshold = threshold
+	c.transport.breaker.cooldown = cooldown
+}
+
+// OnCircuitChange registers a callback for circuit breaker state changes,
+// e.g. to report OpenCode availability to the health monitor
+func (c *Client) OnCircuitChange(fn func(open bool)) {
+	c.transport.breaker.mu.Lock()
+	defer c.transport.breaker.mu.Unlock()
+	c.transport.breaker.onChange = fn
+}
+
 func (c *Client) Health() (map[string]interface{}, error) {
 	req, err := http.NewRequest(http.MethodGet, c.config.BaseURL+"/health", nil)
 	if err != nil {
 		return nil, fmt.Errorf("create health request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.healthClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("health check: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("health check failed with status: %d", resp.StatusCode)
+		return nil, newAPIError("health check", resp)
 	}
 
 	var healthData map[string]interface{}
@@ -91,13 +155,14 @@ func (c *Client) ListSessions() ([]Session, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list sessions failed with status: %d", resp.StatusCode)
+		return nil, newAPIError("list sessions", resp)
 	}
 
 	var sessions []Session
 	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
 		return nil, fmt.Errorf("decode sessions: %w", err)
 	}
+	c.rememberSessions(sessions...)
 
 	return sessions, nil
 }
@@ -114,9 +179,15 @@ func (c *Client) CreateSession(title *string, parentID *string) (*Session, error
 		return nil, fmt.Errorf("marshal create session request: %w", err)
 	}
 
+	// Forks are created next to their parent
+	directory := c.config.Directory
+	if parentID != nil && *parentID != "" {
+		directory = c.sessionDirectory(*parentID)
+	}
+
 	url := c.config.BaseURL + "/session"
-	if c.config.Directory != "" {
-		url += "?directory=" + c.config.Directory
+	if directory != "" {
+		url += "?" + neturl.Values{"directory": {directory}}.Encode()
 	}
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
@@ -132,24 +203,21 @@ func (c *Client) CreateSession(title *string, parentID *string) (*Session, error
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("create session failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError("create session", resp)
 	}
 
 	var session Session
 	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
 		return nil, fmt.Errorf("decode session: %w", err)
 	}
+	c.rememberSessions(session)
 
 	return &session, nil
 }
 
 // DeleteSession deletes a session
 func (c *Client) DeleteSession(sessionID string) error {
-	url := c.config.BaseURL + "/session/" + sessionID
-	if c.config.Directory != "" {
-		url += "?directory=" + c.config.Directory
-	}
+	url := c.sessionURL(sessionID, "")
 
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
 	if err != nil {
@@ -163,19 +231,16 @@ func (c *Client) DeleteSession(sessionID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete session failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError("delete session", resp)
 	}
+	c.forgetSession(sessionID)
 
 	return nil
 }
 
 // AbortSession aborts a running session
 func (c *Client) AbortSession(sessionID string) error {
-	url := c.config.BaseURL + "/session/" + sessionID + "/abort"
-	if c.config.Directory != "" {
-		url += "?directory=" + c.config.Directory
-	}
+	url := c.sessionURL(sessionID, "/abort")
 
 	req, err := http.NewRequest(http.MethodPost, url, nil)
 	if err != nil {
@@ -189,56 +254,83 @@ func (c *Client) AbortSession(sessionID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("abort session failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError("abort session", resp)
+	}
+
+	return nil
+}
+
+// InitSession asks OpenCode to analyze the session's project and write an
+// AGENTS.md for it. The call lasts as long as the agent run (bounded by
+// Timeouts.Prompt); progress and the final reply arrive via events.
+func (c *Client) InitSession(sessionID, providerID, modelID string) error {
+	reqBody := InitSessionRequest{
+		MessageID:  NewMessageID(),
+		ProviderID: providerID,
+		ModelID:    modelID,
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("marshal init session request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.sessionURL(sessionID, "/init"), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("create init session request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyHeader, reqBody.MessageID)
+
+	resp, err := c.promptClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("init session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("init session", resp)
 	}
 
 	return nil
 }
 
 // SendPrompt sends a prompt to a session with text
-func (c *Client) SendPrompt(sessionID, text string, agent *string) (*SendPromptResponse, error) {
+func (c *Client) SendPrompt(sessionID, text string, opts PromptOptions) (*SendPromptResponse, error) {
 	return c.SendPromptWithParts(sessionID, []interface{}{
 		TextPartInput{
 			Type: "text",
 			Text: text,
 		},
-	}, agent)
+	}, opts)
 }
 
 // SendPromptWithParts sends a prompt to a session with mixed parts (text + images)
-func (c *Client) SendPromptWithParts(sessionID string, parts []interface{}, agent *string) (*SendPromptResponse, error) {
-	reqBody := SendPromptRequest{
-		Agent:  agent,
-		System: nil,
-		Parts:  parts,
-	}
+func (c *Client) SendPromptWithParts(sessionID string, parts []interface{}, opts PromptOptions) (*SendPromptResponse, error) {
+	reqBody := opts.request(parts)
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("marshal send prompt request: %w", err)
 	}
 
-	url := c.config.BaseURL + "/session/" + sessionID + "/message"
-	if c.config.Directory != "" {
-		url += "?directory=" + c.config.Directory
-	}
+	url := c.sessionURL(sessionID, "/message")
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("create send prompt request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyHeader, reqBody.MessageID)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.promptClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("send prompt: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("send prompt failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError("send prompt", resp)
 	}
 
 	var response SendPromptResponse
@@ -249,64 +341,67 @@ func (c *Client) SendPromptWithParts(sessionID string, parts []interface{}, agen
 	return &response, nil
 }
 
-func (c *Client) TriggerPrompt(sessionID, text string, agent *string) error {
-	parts := []interface{}{
+// TriggerPrompt starts a prompt without waiting for the reply, which arrives
+// via SSE/plugin events. It posts to /prompt_async, which OpenCode answers as
+// soon as the prompt is queued, so errors returned here are real failures.
+// Servers without that endpoint get the blocking /message call instead, which
+// lasts as long as the agent run (bounded by Timeouts.Prompt).
+// Both attempts share one message ID, so the prompt runs at most once.
+func (c *Client) TriggerPrompt(sessionID, text string, opts PromptOptions) error {
+	reqBody := opts.request([]interface{}{
 		TextPartInput{
 			Type: "text",
 			Text: text,
 		},
-	}
-
-	reqBody := SendPromptRequest{
-		Agent:  agent,
-		System: nil,
-		Parts:  parts,
-	}
+	})
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return fmt.Errorf("marshal send prompt request: %w", err)
 	}
 
-	url := c.config.BaseURL + "/session/" + sessionID + "/message"
-	if c.config.Directory != "" {
-		url += "?directory=" + c.config.Directory
+	if !c.promptAsyncUnsupported.Load() {
+		err := c.postPrompt(sessionID, "prompt_async", reqBody.MessageID, bodyBytes)
+		if !errors.Is(err, errPromptAsyncUnsupported) {
+			return err
+		}
+		logger.Warn("/prompt_async not supported by server, falling back to /message", "session", sessionID)
+		c.promptAsyncUnsupported.Store(true)
 	}
 
-	fmt.Printf("[TriggerPrompt] Sending to: %s, text length: %d\n", url, len(text))
+	return c.postPrompt(sessionID, "message", reqBody.MessageID, bodyBytes)
+}
+
+// errPromptAsyncUnsupported is returned by postPrompt when the server has no
+// /prompt_async endpoint (OpenCode releases before it was added)
+var errPromptAsyncUnsupported = errors.New("prompt_async not supported")
 
-	triggerCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// postPrompt posts a prompt body to /session/{id}/{endpoint}
+func (c *Client) postPrompt(sessionID, endpoint, messageID string, body []byte) error {
+	url := c.sessionURL(sessionID, "/"+endpoint)
 
-	start := time.Now()
-	req, err := http.NewRequestWithContext(triggerCtx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		fmt.Printf("[TriggerPrompt] Failed to create request: %v\n", err)
 		return fmt.Errorf("create trigger request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyHeader, messageID)
 
-	resp, err := c.httpClient.Do(req)
-	elapsed := time.Since(start)
+	resp, err := c.promptClient.Do(req)
 	if err != nil {
-		if triggerCtx.Err() == context.DeadlineExceeded {
-			fmt.Printf("[TriggerPrompt] Timeout after %v (request likely sent, waiting for async processing via SSE)\n", elapsed)
-			return nil
-		}
-		fmt.Printf("[TriggerPrompt] Request failed after %v: %v\n", elapsed, err)
 		return fmt.Errorf("trigger prompt: %w", err)
 	}
 	defer resp.Body.Close()
 
-	fmt.Printf("[TriggerPrompt] Response status: %d (elapsed: %v)\n", resp.StatusCode, elapsed)
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body)
-		fmt.Printf("[TriggerPrompt] Error response body: %s\n", string(body))
-		return fmt.Errorf("trigger prompt failed with status %d: %s", resp.StatusCode, string(body))
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent:
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	case endpoint == "prompt_async" && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed):
+		return errPromptAsyncUnsupported
 	}
 
-	return nil
+	return newAPIError("trigger prompt", resp)
 }
 
 // ListQuestions retrieves all pending questions
@@ -328,8 +423,7 @@ func (c *Client) ListQuestions() ([]QuestionRequest, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list questions failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError("list questions", resp)
 	}
 
 	var questions []QuestionRequest
@@ -369,8 +463,7 @@ func (c *Client) ReplyQuestion(requestID string, answers []QuestionAnswer) error
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("reply question failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError("reply question", resp)
 	}
 
 	return nil
@@ -395,13 +488,42 @@ func (c *Client) RejectQuestion(requestID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("reject question failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError("reject question", resp)
 	}
 
 	return nil
 }
 
+// ListPermissions retrieves all pending permission requests
+func (c *Client) ListPermissions() ([]PermissionRequest, error) {
+	url := c.config.BaseURL + "/permission"
+	if c.config.Directory != "" {
+		url += "?directory=" + neturl.QueryEscape(c.config.Directory)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create list permissions request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list permissions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("list permissions", resp)
+	}
+
+	var permissions []PermissionRequest
+	if err := json.NewDecoder(resp.Body).Decode(&permissions); err != nil {
+		return nil, fmt.Errorf("decode permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
 // ReplyPermission responds to a permission request
 func (c *Client) ReplyPermission(sessionID, permissionID string, response PermissionResponse) error {
 	reqBody := PermissionReplyRequest{
@@ -413,10 +535,7 @@ func (c *Client) ReplyPermission(sessionID, permissionID string, response Permis
 		return fmt.Errorf("marshal reply permission request: %w", err)
 	}
 
-	url := c.config.BaseURL + "/session/" + sessionID + "/permissions/" + permissionID
-	if c.config.Directory != "" {
-		url += "?directory=" + c.config.Directory
-	}
+	url := c.sessionURL(sessionID, "/permissions/"+permissionID)
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
@@ -431,8 +550,7 @@ func (c *Client) ReplyPermission(sessionID, permissionID string, response Permis
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("reply permission failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError("reply permission", resp)
 	}
 
 	return nil
@@ -451,7 +569,7 @@ func (c *Client) GetConfig() (map[string]interface{}, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get config failed with status: %d", resp.StatusCode)
+		return nil, newAPIError("get config", resp)
 	}
 
 	var configData map[string]interface{}
@@ -462,23 +580,27 @@ func (c *Client) GetConfig() (map[string]interface{}, error) {
 	return configData, nil
 }
 
+// GetMessages returns the last limit messages of a session, oldest first
+// (limit <= 0 returns all of them)
 func (c *Client) GetMessages(sessionID string, limit int) ([]Message, error) {
-	url := fmt.Sprintf("%s/session/%s/message?limit=%d", c.config.BaseURL, sessionID, limit)
+	url := fmt.Sprintf("%s/session/%s/message", c.config.BaseURL, sessionID)
+	if limit > 0 {
+		url += fmt.Sprintf("?limit=%d", limit)
+	}
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create get messages request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.messagesClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("get messages: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get messages failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError("get messages", resp)
 	}
 
 	var messages []Message
@@ -489,6 +611,124 @@ func (c *Client) GetMessages(sessionID string, limit int) ([]Message, error) {
 	return messages, nil
 }
 
+// MessageQuery selects a page of a session's messages
+type MessageQuery struct {
+	Limit      int  // page size (required)
+	Offset     int  // messages to skip from the start of the chosen order
+	Descending bool // newest first
+}
+
+// ListMessages returns one page of a session's messages and whether more
+// follow. OpenCode only supports "last N" queries, so newest-first pages
+// fetch just Offset+Limit messages, while oldest-first pages need the whole
+// session and are sliced here.
+func (c *Client) ListMessages(sessionID string, q MessageQuery) (page []Message, more bool, err error) {
+	if q.Limit <= 0 {
+		return nil, false, fmt.Errorf("list messages: limit must be positive")
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	if q.Descending {
+		// Ask for one extra message to learn whether another page exists
+		messages, err := c.GetMessages(sessionID, q.Offset+q.Limit+1)
+		if err != nil {
+			return nil, false, err
+		}
+		end := len(messages) - q.Offset
+		if end <= 0 {
+			return nil, false, nil
+		}
+		start := end - q.Limit
+		if start < 0 {
+			start = 0
+		}
+		page = make([]Message, 0, end-start)
+		for i := end - 1; i >= start; i-- {
+			page = append(page, messages[i])
+		}
+		return page, start > 0, nil
+	}
+
+	messages, err := c.GetMessages(sessionID, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	if q.Offset >= len(messages) {
+		return nil, false, nil
+	}
+	end := q.Offset + q.Limit
+	if end > len(messages) {
+		end = len(messages)
+	}
+	return messages[q.Offset:end], end < len(messages), nil
+}
+
+// WalkMessages calls fn with successive pages of pageSize messages until
+// the session is exhausted or fn returns an error. Oldest-first walks load the
+// session once and page through it. Newest-first walks fetch a tail that
+// doubles each round, so they can stop early without loading older history
+// and fetch each message at most about twice overall.
+func (c *Client) WalkMessages(sessionID string, pageSize int, descending bool, fn func([]Message) error) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("walk messages: page size must be positive")
+	}
+
+	if !descending {
+		messages, err := c.GetMessages(sessionID, 0)
+		if err != nil {
+			return err
+		}
+		for start := 0; start < len(messages); start += pageSize {
+			end := start + pageSize
+			if end > len(messages) {
+				end = len(messages)
+			}
+			if err := fn(messages[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	seen := 0 // messages already handed to fn, counted from the newest
+	for window := pageSize; ; window *= 2 {
+		// Ask for one extra message to learn whether older ones exist
+		messages, err := c.GetMessages(sessionID, window+1)
+		if err != nil {
+			return err
+		}
+		more := len(messages) > window
+		if more {
+			messages = messages[1:]
+		}
+
+		end := len(messages) - seen
+		for end > 0 {
+			start := end - pageSize
+			if start < 0 {
+				if more {
+					break // finish this page from the next, longer tail
+				}
+				start = 0
+			}
+			page := make([]Message, 0, end-start)
+			for i := end - 1; i >= start; i-- {
+				page = append(page, messages[i])
+			}
+			if err := fn(page); err != nil {
+				return err
+			}
+			seen += end - start
+			end = start
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
 func (c *Client) GetMessage(sessionID string, messageID string) (*Message, error) {
 	url := fmt.Sprintf("%s/session/%s/message/%s", c.config.BaseURL, sessionID, messageID)
 
@@ -497,15 +737,14 @@ func (c *Client) GetMessage(sessionID string, messageID string) (*Message, error
 		return nil, fmt.Errorf("create get message request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.messagesClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("get message: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get message failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError("get message", resp)
 	}
 
 	var message Message
@@ -531,8 +770,7 @@ func (c *Client) GetProviders() (*ProvidersResponse, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get providers failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError("get providers", resp)
 	}
 
 	var providers ProvidersResponse
@@ -542,3 +780,82 @@ func (c *Client) GetProviders() (*ProvidersResponse, error) {
 
 	return &providers, nil
 }
+
+// GetAgents returns the names of the agents that can drive a session
+// (subagents are left out). The list is cached for agentsCacheTTL.
+func (c *Client) GetAgents() ([]string, error) {
+	c.agentsMu.Lock()
+	defer c.agentsMu.Unlock()
+
+	if c.agents != nil && time.Since(c.agentsFetched) < agentsCacheTTL {
+		return c.agents, nil
+	}
+
+	url := c.config.BaseURL + "/agent"
+	if c.config.Directory != "" {
+		url += "?" + neturl.Values{"directory": {c.config.Directory}}.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create get agents request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get agents: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("get agents", resp)
+	}
+
+	var agents []Agent
+	if err := json.NewDecoder(resp.Body).Decode(&agents); err != nil {
+		return nil, fmt.Errorf("decode agents: %w", err)
+	}
+
+	names := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		if agent.Mode != "subagent" {
+			names = append(names, agent.Name)
+		}
+	}
+
+	c.agents = names
+	c.agentsFetched = time.Now()
+	return names, nil
+}
+
+// GetFileContent reads a file from the OpenCode workspace.
+// Binary files (e.g. generated images) are returned base64 encoded.
+func (c *Client) GetFileContent(path string) (*FileContent, error) {
+	query := neturl.Values{"path": {path}}
+	if c.config.Directory != "" {
+		query.Set("directory", c.config.Directory)
+	}
+	url := c.config.BaseURL + "/file/content?" + query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create get file content request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get file content: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("get file content", resp)
+	}
+
+	var content FileContent
+	if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		return nil, fmt.Errorf("decode file content: %w", err)
+	}
+
+	return &content, nil
+}
@@ -2,8 +2,11 @@ package opencode
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -121,6 +124,30 @@ func TestClient_CreateSession(t *testing.T) {
 	}
 }
 
+func TestClient_WithDirectory(t *testing.T) {
+	var directories []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		directories = append(directories, r.URL.Query().Get("directory"))
+		json.NewEncoder(w).Encode(Session{ID: "sess_new"})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Directory: "/global"})
+	project := client.WithDirectory("/project")
+	if _, err := project.CreateSession(nil, nil); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if _, err := client.CreateSession(nil, nil); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if len(directories) != 2 || directories[0] != "/project" || directories[1] != "/global" {
+		t.Errorf("Expected /project then /global, got %v", directories)
+	}
+	if project.transport != client.transport {
+		t.Error("Expected the connections and circuit breaker to be shared")
+	}
+}
+
 func TestClient_DeleteSession(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/session/sess_123" {
@@ -192,8 +219,7 @@ func TestClient_SendPrompt(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(Config{BaseURL: server.URL})
-	agent := "build"
-	resp, err := client.SendPrompt("sess_123", "Hello OpenCode", &agent)
+	resp, err := client.SendPrompt("sess_123", "Hello OpenCode", PromptOptions{Agent: "build"})
 	if err != nil {
 		t.Fatalf("SendPrompt() error = %v", err)
 	}
@@ -202,6 +228,54 @@ func TestClient_SendPrompt(t *testing.T) {
 	}
 }
 
+func TestClient_ListPermissions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/permission" {
+			t.Errorf("Expected path /permission, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("directory"); got != "/work/my project" {
+			t.Errorf("Expected directory query, got %q", got)
+		}
+
+		permissions := []PermissionRequest{
+			{ID: "per_123", SessionID: "sess_456", Permission: "bash", Patterns: []string{"go test"}},
+		}
+		json.NewEncoder(w).Encode(permissions)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Directory: "/work/my project"})
+	permissions, err := client.ListPermissions()
+	if err != nil {
+		t.Fatalf("ListPermissions() error = %v", err)
+	}
+	if len(permissions) != 1 || permissions[0].ID != "per_123" || permissions[0].Patterns[0] != "go test" {
+		t.Errorf("Unexpected permissions: %+v", permissions)
+	}
+}
+
+func TestClient_ListSessionStatuses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/status" {
+			t.Errorf("Expected path /session/status, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"ses_busy":{"type":"busy"},"ses_retry":{"type":"retry","attempt":2,"message":"overloaded","next":1700000000000}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	statuses, err := client.ListSessionStatuses()
+	if err != nil {
+		t.Fatalf("ListSessionStatuses() error = %v", err)
+	}
+	if !statuses["ses_busy"].Busy() || !statuses["ses_retry"].Busy() || statuses["ses_retry"].Attempt != 2 {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+	if statuses["ses_idle"].Busy() {
+		t.Error("Expected a missing session to be idle")
+	}
+}
+
 func TestClient_ListQuestions(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/question" {
@@ -323,3 +397,286 @@ func TestClient_ReplyPermission(t *testing.T) {
 		t.Fatalf("ReplyPermission() error = %v", err)
 	}
 }
+
+func TestClient_GetAgents(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/agent" {
+			t.Errorf("Expected path /agent, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("directory"); got != "/work" {
+			t.Errorf("Expected directory /work, got %q", got)
+		}
+		json.NewEncoder(w).Encode([]Agent{
+			{Name: "build", Mode: "primary"},
+			{Name: "explore", Mode: "subagent"},
+			{Name: "sisyphus", Mode: "all"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Directory: "/work"})
+	agents, err := client.GetAgents()
+	if err != nil {
+		t.Fatalf("GetAgents() error = %v", err)
+	}
+	if len(agents) != 2 || agents[0] != "build" || agents[1] != "sisyphus" {
+		t.Errorf("Expected [build sisyphus], got %v", agents)
+	}
+
+	// Served from cache
+	if _, err := client.GetAgents(); err != nil {
+		t.Fatalf("GetAgents() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestClient_GetProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config/providers" {
+			t.Errorf("Expected path /config/providers, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"providers": [{
+				"id": "anthropic",
+				"name": "Anthropic",
+				"models": {
+					"claude-sonnet-4": {
+						"id": "claude-sonnet-4",
+						"name": "Claude Sonnet 4",
+						"cost": {"input": 3, "output": 15},
+						"limit": {"context": 200000, "output": 64000},
+						"status": "beta"
+					}
+				}
+			}],
+			"default": {"anthropic": "claude-sonnet-4"}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	resp, err := client.GetProviders()
+	if err != nil {
+		t.Fatalf("GetProviders() error = %v", err)
+	}
+
+	model := resp.Providers[0].Models["claude-sonnet-4"]
+	if model.Cost.Output != 15 || model.Limit.Context != 200000 || model.Status != "beta" {
+		t.Errorf("Unexpected model metadata: %+v", model)
+	}
+	if resp.Default["anthropic"] != "claude-sonnet-4" {
+		t.Errorf("Unexpected default model: %v", resp.Default)
+	}
+}
+
+func TestClient_TriggerPromptAsync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/ses_1/prompt_async" {
+			t.Errorf("Expected path /session/ses_1/prompt_async, got %s", r.URL.Path)
+		}
+		var req SendPromptRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Parts) != 1 || req.Agent == nil || *req.Agent != "build" {
+			t.Errorf("Unexpected request body: %+v", req)
+		}
+		if req.Model == nil || req.Model.ProviderID != "openrouter" || req.Model.ModelID != "anthropic/claude-sonnet-4" {
+			t.Errorf("Unexpected model: %+v", req.Model)
+		}
+		if req.MessageID != "msg_fixed" || r.Header.Get(IdempotencyHeader) != "msg_fixed" {
+			t.Errorf("Expected message ID msg_fixed, got %q", req.MessageID)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	opts := PromptOptions{Agent: "build", Model: "openrouter/anthropic/claude-sonnet-4", MessageID: "msg_fixed"}
+	if err := client.TriggerPrompt("ses_1", "hello", opts); err != nil {
+		t.Fatalf("TriggerPrompt() error = %v", err)
+	}
+}
+
+func TestClient_TriggerPromptSurfacesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"session busy"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	err := client.TriggerPrompt("ses_1", "hello", PromptOptions{})
+	if err == nil || !strings.Contains(err.Error(), "session busy") {
+		t.Fatalf("Expected server error, got %v", err)
+	}
+}
+
+func TestClient_TriggerPromptFallsBackToMessage(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/prompt_async") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(SendPromptResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	for i := 0; i < 2; i++ {
+		if err := client.TriggerPrompt("ses_1", "hello", PromptOptions{}); err != nil {
+			t.Fatalf("TriggerPrompt() error = %v", err)
+		}
+	}
+
+	// The unsupported endpoint is only tried once
+	want := []string{"/session/ses_1/prompt_async", "/session/ses_1/message", "/session/ses_1/message"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected paths %v, got %v", want, paths)
+	}
+}
+
+// messageServer serves count messages (msg_0 oldest) honoring ?limit like OpenCode
+func messageServer(count int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var messages []Message
+		for i := 0; i < count; i++ {
+			messages = append(messages, Message{Info: MessageInfo{ID: fmt.Sprintf("msg_%d", i)}})
+		}
+		if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit < len(messages) {
+			messages = messages[len(messages)-limit:]
+		}
+		json.NewEncoder(w).Encode(messages)
+	}))
+}
+
+func messageIDs(messages []Message) string {
+	var ids []string
+	for _, m := range messages {
+		ids = append(ids, m.Info.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestClient_GetMessagesLimit(t *testing.T) {
+	server := messageServer(5)
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL})
+
+	for limit, want := range map[int]string{2: "msg_3,msg_4", 0: "msg_0,msg_1,msg_2,msg_3,msg_4"} {
+		messages, err := client.GetMessages("ses_1", limit)
+		if err != nil {
+			t.Fatalf("GetMessages(%d) error = %v", limit, err)
+		}
+		if got := messageIDs(messages); got != want {
+			t.Errorf("GetMessages(%d) = %q, want %q", limit, got, want)
+		}
+	}
+}
+
+func TestClient_ListMessages(t *testing.T) {
+	server := messageServer(5)
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL})
+
+	tests := []struct {
+		query    MessageQuery
+		wantIDs  string
+		wantMore bool
+	}{
+		{MessageQuery{Limit: 2}, "msg_0,msg_1", true},
+		{MessageQuery{Limit: 2, Offset: 4}, "msg_4", false},
+		{MessageQuery{Limit: 2, Descending: true}, "msg_4,msg_3", true},
+		{MessageQuery{Limit: 2, Offset: 3, Descending: true}, "msg_1,msg_0", false},
+		{MessageQuery{Limit: 2, Offset: 5, Descending: true}, "", false},
+	}
+	for _, tt := range tests {
+		page, more, err := client.ListMessages("ses_1", tt.query)
+		if err != nil {
+			t.Fatalf("ListMessages(%+v) error = %v", tt.query, err)
+		}
+		if messageIDs(page) != tt.wantIDs || more != tt.wantMore {
+			t.Errorf("ListMessages(%+v) = %q, %v; want %q, %v", tt.query, messageIDs(page), more, tt.wantIDs, tt.wantMore)
+		}
+	}
+}
+
+func TestClient_WalkMessages(t *testing.T) {
+	server := messageServer(5)
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL})
+
+	for _, descending := range []bool{false, true} {
+		var pages []string
+		err := client.WalkMessages("ses_1", 2, descending, func(page []Message) error {
+			pages = append(pages, messageIDs(page))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WalkMessages() error = %v", err)
+		}
+		want := "msg_0,msg_1|msg_2,msg_3|msg_4"
+		if descending {
+			want = "msg_4,msg_3|msg_2,msg_1|msg_0"
+		}
+		if got := strings.Join(pages, "|"); got != want {
+			t.Errorf("WalkMessages(descending=%v) pages = %q, want %q", descending, got, want)
+		}
+	}
+}
+
+func TestClient_WalkMessagesDoublesTail(t *testing.T) {
+	var limits []string
+	inner := messageServer(7)
+	defer inner.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		inner.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL})
+
+	var pages []string
+	err := client.WalkMessages("ses_1", 2, true, func(page []Message) error {
+		pages = append(pages, messageIDs(page))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkMessages() error = %v", err)
+	}
+	if got, want := strings.Join(pages, "|"), "msg_6,msg_5|msg_4,msg_3|msg_2,msg_1|msg_0"; got != want {
+		t.Errorf("pages = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(limits, ","), "3,5,9"; got != want {
+		t.Errorf("limits = %q, want %q", got, want)
+	}
+}
+
+func TestClient_InitSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/session/ses_1/init" {
+			t.Errorf("Expected POST /session/ses_1/init, got %s %s", r.Method, r.URL.Path)
+		}
+		var body InitSessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.ProviderID != "anthropic" || body.ModelID != "claude-sonnet-4" {
+			t.Errorf("Unexpected model in body: %+v", body)
+		}
+		if body.MessageID == "" || r.Header.Get(IdempotencyHeader) != body.MessageID {
+			t.Errorf("Expected message ID in body and %s header", IdempotencyHeader)
+		}
+		w.Write([]byte("true"))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	if err := client.InitSession("ses_1", "anthropic", "claude-sonnet-4"); err != nil {
+		t.Fatalf("InitSession() error = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package opencode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBody caps how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// APIError is a non-2xx response from the OpenCode server
+type APIError struct {
+	Op         string        // operation that failed, e.g. "send prompt"
+	StatusCode int           // HTTP status
+	Code       string        // OpenCode error name, e.g. "ProviderAuthError" (may be empty)
+	Message    string        // human-readable message, or the raw body
+	Retryable  bool          // the same request may succeed later
+	RetryAfter time.Duration // from the Retry-After header, if any
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s failed with status %d (%s): %s", e.Op, e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s failed with status %d: %s", e.Op, e.StatusCode, e.Message)
+}
+
+// RateLimited reports whether the server or the model provider throttled the request
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(e.Message), "rate limit")
+}
+
+// errorBody matches OpenCode's error responses:
+//
+//	{"name": "ProviderAuthError", "data": {"message": "...", "isRetryable": false}}
+//	{"success": false, "errors": [{"message": "..."}]}  (validation errors)
+type errorBody struct {
+	Name string `json:"name"`
+	Data struct {
+		Message     string `json:"message"`
+		IsRetryable *bool  `json:"isRetryable"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// newAPIError builds an APIError from a failed response, consuming its body
+func newAPIError(op string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	apiErr := &APIError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+		Retryable:  retryableStatus(resp.StatusCode),
+	}
+
+	var parsed errorBody
+	if json.Unmarshal(body, &parsed) == nil {
+		apiErr.Code = parsed.Name
+		if parsed.Data.Message != "" {
+			apiErr.Message = parsed.Data.Message
+		} else if len(parsed.Errors) > 0 && parsed.Errors[0].Message != "" {
+			apiErr.Message = parsed.Errors[0].Message
+		}
+		if parsed.Data.IsRetryable != nil {
+			apiErr.Retryable = *parsed.Data.IsRetryable
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiErr
+}
+
+// retryableStatus reports statuses that are usually transient
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return code >= 500 && code != http.StatusNotImplemented
+}
+
+// IsRetryable reports whether err is an APIError worth retrying
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable
+}
@@ -0,0 +1,97 @@
+package opencode
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		header        http.Header
+		wantCode      string
+		wantMessage   string
+		wantRetryable bool
+		wantAfter     time.Duration
+	}{
+		{
+			name:        "named error",
+			status:      http.StatusBadRequest,
+			body:        `{"name":"ProviderModelNotFoundError","data":{"message":"model gpt-9 not found"}}`,
+			wantCode:    "ProviderModelNotFoundError",
+			wantMessage: "model gpt-9 not found",
+		},
+		{
+			name:          "retryable flag overrides status",
+			status:        http.StatusBadRequest,
+			body:          `{"name":"APIError","data":{"message":"overloaded","isRetryable":true}}`,
+			wantCode:      "APIError",
+			wantMessage:   "overloaded",
+			wantRetryable: true,
+		},
+		{
+			name:        "validation error",
+			status:      http.StatusBadRequest,
+			body:        `{"success":false,"errors":[{"message":"Expected string"}]}`,
+			wantMessage: "Expected string",
+		},
+		{
+			name:          "rate limit with Retry-After",
+			status:        http.StatusTooManyRequests,
+			body:          "slow down",
+			header:        http.Header{"Retry-After": {"7"}},
+			wantMessage:   "slow down",
+			wantRetryable: true,
+			wantAfter:     7 * time.Second,
+		},
+		{
+			name:          "empty body",
+			status:        http.StatusInternalServerError,
+			wantMessage:   "Internal Server Error",
+			wantRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			for k, v := range tt.header {
+				rec.Header()[k] = v
+			}
+			rec.WriteHeader(tt.status)
+			rec.WriteString(tt.body)
+
+			err := newAPIError("send prompt", rec.Result())
+			if err.Code != tt.wantCode || err.Message != tt.wantMessage || err.Retryable != tt.wantRetryable || err.RetryAfter != tt.wantAfter {
+				t.Errorf("newAPIError() = %+v", err)
+			}
+			if err.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestClient_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"name":"NotFoundError","data":{"message":"Session not found"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	err := client.AbortSession("ses_missing")
+
+	want := "abort session failed with status 404 (NotFoundError): Session not found"
+	if err == nil || err.Error() != want {
+		t.Fatalf("AbortSession() error = %v, want %q", err, want)
+	}
+	if IsRetryable(err) || IsRetryable(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("404 should not be retryable")
+	}
+}
@@ -0,0 +1,116 @@
+package opencode
+
+import (
+	"sync"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+// DefaultEventBacklog caps how many events wait in memory while the bridge
+// falls behind the stream
+const DefaultEventBacklog = 10000
+
+// lossyEvents may be dropped when the backlog is full: each streaming update
+// is superseded by the next one and by the final message.updated
+var lossyEvents = map[string]bool{
+	"message.part.updated": true,
+}
+
+// eventQueue sits between the SSE reader and the events channel. Events
+// spill into memory instead of being dropped when the channel is full; only
+// lossy events are dropped, and only once the backlog limit is reached, so
+// permission and question requests are never lost.
+type eventQueue struct {
+	mu     sync.Mutex
+	events []Event
+	limit  int           // <= 0: unbounded
+	wake   chan struct{} // signals the pump that events arrived
+	ending bool          // close out once the queue is empty
+}
+
+func newEventQueue(limit int) *eventQueue {
+	return &eventQueue{limit: limit, wake: make(chan struct{}, 1)}
+}
+
+// push queues an event, reporting false when it was dropped
+func (q *eventQueue) push(event Event) bool {
+	q.mu.Lock()
+	if q.limit > 0 && len(q.events) >= q.limit && lossyEvents[event.Type] {
+		q.mu.Unlock()
+		metrics.SSEEventsDropped.WithLabelValues(event.Type).Inc()
+		sseLogger.Warn("Event backlog full, dropping event", "limit", q.limit, "type", event.Type, "session", event.SessionID())
+		return false
+	}
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+	metrics.SSEEventBacklog.Inc()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// end makes run close out once every queued event has been sent
+func (q *eventQueue) end() {
+	q.mu.Lock()
+	q.ending = true
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the oldest event
+func (q *eventQueue) pop() (Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) == 0 {
+		return Event{}, false
+	}
+	event := q.events[0]
+	q.events[0] = Event{}
+	q.events = q.events[1:]
+	return event, true
+}
+
+// len returns the number of waiting events
+func (q *eventQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
+
+// run feeds queued events into out in order until done is closed, or the
+// queue is empty after end, then closes out. It is the only sender on out.
+func (q *eventQueue) run(out chan<- Event, done <-chan struct{}) {
+	defer close(out)
+	// Events left behind on close are no longer waiting
+	defer func() { metrics.SSEEventBacklog.Sub(float64(q.len())) }()
+	for {
+		event, ok := q.pop()
+		if !ok {
+			q.mu.Lock()
+			ending := q.ending
+			q.mu.Unlock()
+			if ending {
+				return
+			}
+			select {
+			case <-q.wake:
+				continue
+			case <-done:
+				return
+			}
+		}
+		select {
+		case out <- event:
+		case <-done:
+			metrics.SSEEventBacklog.Dec()
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package opencode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventQueue_KeepsCriticalEventsWhenFull(t *testing.T) {
+	q := newEventQueue(2)
+	q.push(Event{Type: "message.part.updated"})
+	q.push(Event{Type: "message.part.updated"})
+
+	if q.push(Event{Type: "message.part.updated"}) {
+		t.Error("Expected streaming update to be dropped when the backlog is full")
+	}
+	if !q.push(Event{Type: "permission.asked"}) {
+		t.Error("Expected permission.asked to be kept when the backlog is full")
+	}
+	if n := q.len(); n != 3 {
+		t.Errorf("Expected 3 queued events, got %d", n)
+	}
+}
+
+func TestEventQueue_DeliversInOrder(t *testing.T) {
+	q := newEventQueue(0)
+	out := make(chan Event) // unbuffered: everything spills into the queue
+	done := make(chan struct{})
+	go q.run(out, done)
+
+	types := []string{"message.updated", "permission.asked", "session.idle"}
+	for _, typ := range types {
+		q.push(Event{Type: typ})
+	}
+	for _, want := range types {
+		select {
+		case event := <-out:
+			if event.Type != want {
+				t.Fatalf("Expected %s, got %s", want, event.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for %s", want)
+		}
+	}
+
+	close(done)
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("Expected no further events")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the output channel to be closed")
+	}
+}
+
+func TestSSE_SlowReaderLosesNoPermissions(t *testing.T) {
+	consumer := NewSSEConsumer(Config{})
+	defer consumer.Close()
+	consumer.SetEventBacklog(10)
+
+	// Far more events than the channel buffer and backlog hold, with nobody reading
+	for i := 0; i < 300; i++ {
+		consumer.parseAndSendEvent("message.part.updated", `{"properties":{"part":{"sessionID":"s"}}}`)
+	}
+	if err := consumer.parseAndSendEvent("permission.asked", `{"properties":{"id":"per_1","sessionID":"s"}}`); err != nil {
+		t.Fatalf("parseAndSendEvent() error = %v", err)
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-consumer.Events():
+			if event.Type == "permission.asked" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("permission.asked was lost")
+		}
+	}
+}
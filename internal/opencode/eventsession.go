@@ -0,0 +1,96 @@
+package opencode
+
+import "strings"
+
+// SessionID returns the session an event belongs to, or "" for events not
+// tied to a session (server and installation events)
+func (e Event) SessionID() string {
+	switch p := e.Properties.(type) {
+	case *EventQuestionAsked:
+		return p.Properties.SessionID
+	case *EventQuestionReplied:
+		return p.Properties.SessionID
+	case *EventQuestionRejected:
+		return p.Properties.SessionID
+	case *EventPermissionAsked:
+		return p.Properties.SessionID
+	case *EventPermissionReplied:
+		return p.Properties.SessionID
+	case *EventMessageUpdated:
+		if p.Properties.Info != nil {
+			return p.Properties.Info.SessionID
+		}
+	case *EventMessagePartUpdated:
+		if part, ok := p.Properties.Part.(map[string]interface{}); ok {
+			id, _ := part["sessionID"].(string)
+			return id
+		}
+	case *EventSessionIdle:
+		return p.Properties.SessionID
+	case *EventSessionError:
+		if p.Properties.SessionID != nil {
+			return *p.Properties.SessionID
+		}
+	case map[string]interface{}:
+		// Untyped events: {"sessionID": ...} or, for session.*, {"info": {"id": ...}}
+		if id, ok := p["sessionID"].(string); ok {
+			return id
+		}
+		if info, ok := p["info"].(map[string]interface{}); ok {
+			if id, ok := info["sessionID"].(string); ok {
+				return id
+			}
+			if id, ok := info["id"].(string); ok && strings.HasPrefix(e.Type, "session.") {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// ParentSessionID returns the parent of the session a session.created or
+// session.updated event describes ("" for top-level sessions)
+func (e Event) ParentSessionID() string {
+	if e.Type != "session.created" && e.Type != "session.updated" {
+		return ""
+	}
+	props, ok := e.Properties.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	info, ok := props["info"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	parent, _ := info["parentID"].(string)
+	return parent
+}
+
+// MessageID returns the message an event belongs to, or "" for events not
+// tied to a message
+func (e Event) MessageID() string {
+	switch p := e.Properties.(type) {
+	case *EventMessageUpdated:
+		if p.Properties.Info != nil {
+			return p.Properties.Info.ID
+		}
+	case *EventMessagePartUpdated:
+		if part, ok := p.Properties.Part.(map[string]interface{}); ok {
+			id, _ := part["messageID"].(string)
+			return id
+		}
+	case *EventPermissionAsked:
+		if p.Properties.Tool != nil {
+			return p.Properties.Tool.MessageID
+		}
+	case *EventQuestionAsked:
+		if p.Properties.Tool != nil {
+			return p.Properties.Tool.MessageID
+		}
+	case map[string]interface{}:
+		if id, ok := p["messageID"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
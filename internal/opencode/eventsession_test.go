@@ -0,0 +1,76 @@
+package opencode
+
+import "testing"
+
+func TestEventSessionID(t *testing.T) {
+	idle := &EventSessionIdle{}
+	idle.Properties.SessionID = "ses_idle"
+	part := &EventMessagePartUpdated{}
+	part.Properties.Part = map[string]interface{}{"sessionID": "ses_part"}
+	errSession := "ses_err"
+	sessionErr := &EventSessionError{}
+	sessionErr.Properties.SessionID = &errSession
+
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"typed", Event{Type: "session.idle", Properties: idle}, "ses_idle"},
+		{"part", Event{Type: "message.part.updated", Properties: part}, "ses_part"},
+		{"pointer", Event{Type: "session.error", Properties: sessionErr}, "ses_err"},
+		{"permission", Event{Type: "permission.asked", Properties: &EventPermissionAsked{Properties: PermissionRequest{SessionID: "ses_perm"}}}, "ses_perm"},
+		{"generic", Event{Type: "session.status", Properties: map[string]interface{}{"sessionID": "ses_status"}}, "ses_status"},
+		{"session info", Event{Type: "session.updated", Properties: map[string]interface{}{"info": map[string]interface{}{"id": "ses_info"}}}, "ses_info"},
+		{"no session", Event{Type: "server.connected", Properties: map[string]interface{}{}}, ""},
+		{"nil", Event{Type: "session.created"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.SessionID(); got != tt.want {
+				t.Errorf("SessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventParentSessionID(t *testing.T) {
+	event := Event{Type: "session.created", Properties: map[string]interface{}{
+		"info": map[string]interface{}{"id": "ses_child", "parentID": "ses_parent"},
+	}}
+	if got := event.ParentSessionID(); got != "ses_parent" {
+		t.Errorf("ParentSessionID() = %q, want ses_parent", got)
+	}
+	event.Type = "message.updated"
+	if got := event.ParentSessionID(); got != "" {
+		t.Errorf("ParentSessionID() = %q for a message event, want empty", got)
+	}
+}
+
+func TestEventMessageID(t *testing.T) {
+	part := &EventMessagePartUpdated{}
+	part.Properties.Part = map[string]interface{}{"messageID": "msg_part"}
+	perm := &EventPermissionAsked{}
+	perm.Properties.Tool = &struct {
+		MessageID string `json:"messageID"`
+		CallID    string `json:"callID"`
+	}{MessageID: "msg_perm"}
+
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"part", Event{Type: "message.part.updated", Properties: part}, "msg_part"},
+		{"permission", Event{Type: "permission.asked", Properties: perm}, "msg_perm"},
+		{"generic", Event{Type: "message.removed", Properties: map[string]interface{}{"messageID": "msg_removed"}}, "msg_removed"},
+		{"no message", Event{Type: "session.idle", Properties: &EventSessionIdle{}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.MessageID(); got != tt.want {
+				t.Errorf("MessageID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
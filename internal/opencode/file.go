@@ -0,0 +1,36 @@
+package opencode
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Bytes returns the raw file data, decoding base64 content for binary files
+func (f *FileContent) Bytes() ([]byte, error) {
+	if f.Encoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(f.Content)
+		if err != nil {
+			return nil, fmt.Errorf("decode file content: %w", err)
+		}
+		return data, nil
+	}
+	return []byte(f.Content), nil
+}
+
+// DecodeDataURL decodes a base64 data URL (data:image/png;base64,...)
+// as used by OpenCode for inline file parts
+func DecodeDataURL(url string) ([]byte, error) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !ok || !strings.HasPrefix(url, "data:") {
+		return nil, fmt.Errorf("not a data URL")
+	}
+	if !strings.HasSuffix(meta, ";base64") {
+		return []byte(payload), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode data URL: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,52 @@
+package opencode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDecodeDataURL(t *testing.T) {
+	data, err := DecodeDataURL("data:image/png;base64,aGVsbG8=")
+	if err != nil {
+		t.Fatalf("DecodeDataURL() error = %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected hello, got %q", data)
+	}
+
+	if _, err := DecodeDataURL("file:///tmp/out.png"); err == nil {
+		t.Error("expected error for non-data URL")
+	}
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file/content" {
+			t.Errorf("Expected path /file/content, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("path"); got != "/work/diagram.png" {
+			t.Errorf("Expected path query /work/diagram.png, got %s", got)
+		}
+		if got := r.URL.Query().Get("directory"); got != "/work" {
+			t.Errorf("Expected directory query /work, got %s", got)
+		}
+		json.NewEncoder(w).Encode(FileContent{Type: "binary", Content: "aGVsbG8=", Encoding: "base64", MimeType: "image/png"})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Directory: "/work"})
+	content, err := client.GetFileContent("/work/diagram.png")
+	if err != nil {
+		t.Fatalf("GetFileContent() error = %v", err)
+	}
+
+	data, err := content.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected hello, got %q", data)
+	}
+}
@@ -0,0 +1,44 @@
+package opencode
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IdempotencyHeader carries the client-generated message ID of a prompt
+// submission. Requests with this header are safe to retry.
+const IdempotencyHeader = "Idempotency-Key"
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var (
+	idMu       sync.Mutex
+	idLastTime int64
+	idCounter  int64
+)
+
+// NewMessageID returns an ascending message ID in OpenCode's format
+// ("msg_" + 12 hex chars of time and counter + 14 random base62 chars).
+// OpenCode stores a prompt's user message under the ID it is given, so
+// submitting the same ID twice cannot create a second message.
+func NewMessageID() string {
+	idMu.Lock()
+	now := time.Now().UnixMilli()
+	if now != idLastTime {
+		idLastTime = now
+		idCounter = 0
+	}
+	idCounter++
+	value := uint64(now)*0x1000 + uint64(idCounter)
+	idMu.Unlock()
+
+	random := make([]byte, 14)
+	rand.Read(random)
+	for i, b := range random {
+		random[i] = base62[int(b)%len(base62)]
+	}
+
+	return fmt.Sprintf("msg_%012x%s", value&0xffffffffffff, random)
+}
@@ -0,0 +1,23 @@
+package opencode
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewMessageID(t *testing.T) {
+	format := regexp.MustCompile(`^msg_[0-9a-f]{12}[0-9A-Za-z]{14}$`)
+
+	prev := ""
+	for i := 0; i < 100; i++ {
+		id := NewMessageID()
+		if !format.MatchString(id) {
+			t.Fatalf("NewMessageID() = %q, unexpected format", id)
+		}
+		// The prefix sorts by creation order
+		if id[:16] <= prev {
+			t.Fatalf("NewMessageID() = %q, not ascending after %q", id, prev)
+		}
+		prev = id[:16]
+	}
+}
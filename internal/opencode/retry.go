@@ -0,0 +1,217 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is wrapped by errors returned while the OpenCode server
+// cannot be reached, either after retries or while the circuit breaker is open
+var ErrUnavailable = errors.New("OpenCode server unavailable")
+
+// RetryPolicy controls how idempotent requests (GET, HEAD, and prompts
+// carrying an IdempotencyHeader) are retried on connection errors and
+// 502/503/504 responses
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration // delay before the first retry, doubled each time
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy retries twice, after 500ms and 1s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 2,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
+}
+
+const (
+	// DefaultBreakerThreshold is the number of consecutive failed requests
+	// that opens the circuit
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long the circuit stays open before a
+	// trial request is let through
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// backoff returns the delay before retry number attempt (0-based)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// circuitBreaker fails requests fast after repeated failures, so a down
+// server does not make every command wait for its timeouts
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	open      bool
+	openUntil time.Time
+	onChange  func(open bool)
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a request may be sent. Once the cooldown has passed
+// one trial request is let through per cooldown period (half-open).
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.open {
+		return nil
+	}
+	if now := cb.now(); now.Before(cb.openUntil) {
+		return fmt.Errorf("%w: circuit open, retrying in %s", ErrUnavailable, cb.openUntil.Sub(now).Round(time.Second))
+	}
+	cb.openUntil = cb.now().Add(cb.cooldown)
+	return nil
+}
+
+// record updates the breaker with the outcome of a request
+func (cb *circuitBreaker) record(ok bool) {
+	cb.mu.Lock()
+
+	var changed bool
+	if ok {
+		cb.failures = 0
+		changed = cb.open
+		cb.open = false
+	} else {
+		cb.failures++
+		if cb.threshold > 0 && cb.failures >= cb.threshold && !cb.open {
+			cb.open = true
+			cb.openUntil = cb.now().Add(cb.cooldown)
+			changed = true
+		}
+	}
+	open, onChange := cb.open, cb.onChange
+	cb.mu.Unlock()
+
+	if changed {
+		if open {
+			logger.Warn("Circuit opened", "failures", cb.threshold)
+		} else {
+			logger.Info("Circuit closed, server reachable again")
+		}
+		if onChange != nil {
+			onChange(open)
+		}
+	}
+}
+
+// resilientTransport retries idempotent requests and guards all requests
+// with a circuit breaker
+type resilientTransport struct {
+	base    http.RoundTripper
+	mu      sync.RWMutex
+	policy  RetryPolicy
+	breaker *circuitBreaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func newResilientTransport(base http.RoundTripper) *resilientTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &resilientTransport{
+		base:    base,
+		policy:  DefaultRetryPolicy,
+		breaker: newCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+		sleep:   sleepContext,
+	}
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	policy := t.policy
+	t.mu.RUnlock()
+
+	retries := 0
+	if isIdempotent(req) {
+		retries = policy.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			// The previous attempt consumed the body
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		failed := err != nil || isUnavailableStatus(resp.StatusCode)
+
+		if errors.Is(err, context.Canceled) {
+			// Given up on by the caller, which says nothing of the server
+			return nil, err
+		}
+		if !failed || attempt >= retries {
+			t.breaker.record(!failed)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+			}
+			return resp, nil
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), policy.backoff(attempt)); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			t.breaker.record(false)
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+	}
+}
+
+// isIdempotent reports whether req can be sent again without side effects
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	return req.Header.Get(IdempotencyHeader) != ""
+}
+
+// isUnavailableStatus reports gateway/overload responses worth retrying
+func isUnavailableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
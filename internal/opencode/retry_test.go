@@ -0,0 +1,173 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client whose retries do not sleep
+func newTestClient(url string) *Client {
+	client := NewClient(Config{BaseURL: url})
+	client.transport.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return client
+}
+
+func TestClient_RetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	if _, err := client.ListSessions(); err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClient_DoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	if _, err := client.CreateSession(nil, nil); err == nil {
+		t.Fatal("Expected error from CreateSession()")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 attempt, got %d", got)
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close() // every request fails to connect
+
+	client := newTestClient(url)
+	client.SetRetryPolicy(RetryPolicy{})
+	client.SetCircuitBreaker(2, time.Minute)
+
+	now := time.Now()
+	client.transport.breaker.now = func() time.Time { return now }
+
+	var changes []bool
+	client.OnCircuitChange(func(open bool) { changes = append(changes, open) })
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListSessions(); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("Expected ErrUnavailable, got %v", err)
+		}
+	}
+	if len(changes) != 1 || !changes[0] {
+		t.Fatalf("Expected circuit to open, got changes %v", changes)
+	}
+
+	// While open, requests fail fast without reaching the server
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer live.Close()
+	client.config.BaseURL = live.URL
+
+	if _, err := client.ListSessions(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected fail-fast ErrUnavailable, got %v", err)
+	}
+
+	// After the cooldown a trial request closes the circuit again
+	now = now.Add(time.Minute)
+	if _, err := client.ListSessions(); err != nil {
+		t.Fatalf("ListSessions() after cooldown error = %v", err)
+	}
+	if len(changes) != 2 || changes[1] {
+		t.Errorf("Expected circuit to close, got changes %v", changes)
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestResilientTransport_IgnoresCanceledRequests(t *testing.T) {
+	refused := errors.New("connection refused")
+	transport := newResilientTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		return nil, refused
+	}))
+	transport.policy = RetryPolicy{}
+	transport.breaker.threshold = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://opencode/session", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) || errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected a plain context.Canceled, got %v", err)
+	}
+	if transport.breaker.open || transport.breaker.failures != 0 {
+		t.Fatal("Expected a canceled request not to count as a failure")
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://opencode/session", nil)
+	_, err := transport.RoundTrip(req)
+	if !errors.Is(err, ErrUnavailable) || !errors.Is(err, refused) {
+		t.Fatalf("Expected ErrUnavailable wrapping the cause, got %v", err)
+	}
+	if !transport.breaker.open {
+		t.Error("Expected the failed request to open the circuit")
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	for attempt, expected := range want {
+		if got := policy.backoff(attempt); got != expected {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, expected)
+		}
+	}
+}
+
+func TestClient_RetriesPromptWithSameMessageID(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SendPromptRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.Header.Get(IdempotencyHeader) != req.MessageID {
+			t.Errorf("Header %q does not match body messageID %q", r.Header.Get(IdempotencyHeader), req.MessageID)
+		}
+		ids = append(ids, req.MessageID)
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	if err := client.TriggerPrompt("ses_1", "hello", PromptOptions{}); err != nil {
+		t.Fatalf("TriggerPrompt() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("Expected one retry with the same message ID, got %v", ids)
+	}
+}
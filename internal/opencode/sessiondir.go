@@ -0,0 +1,47 @@
+package opencode
+
+import neturl "net/url"
+
+// rememberSessions caches the directory each session belongs to
+func (c *Client) rememberSessions(sessions ...Session) {
+	c.sessionDirsMu.Lock()
+	defer c.sessionDirsMu.Unlock()
+	if c.sessionDirs == nil {
+		c.sessionDirs = make(map[string]string)
+	}
+	for _, sess := range sessions {
+		if sess.Directory != "" {
+			c.sessionDirs[sess.ID] = sess.Directory
+		}
+	}
+}
+
+// forgetSession drops a deleted session from the directory cache
+func (c *Client) forgetSession(sessionID string) {
+	c.sessionDirsMu.Lock()
+	defer c.sessionDirsMu.Unlock()
+	delete(c.sessionDirs, sessionID)
+}
+
+// sessionDirectory returns the directory a session lives in. OpenCode scopes
+// session operations to a project directory, so prompts, aborts and deletes
+// must use the session's own directory rather than Config.Directory, which
+// is only the fallback for sessions this client has not seen yet.
+func (c *Client) sessionDirectory(sessionID string) string {
+	c.sessionDirsMu.RLock()
+	defer c.sessionDirsMu.RUnlock()
+	if dir, ok := c.sessionDirs[sessionID]; ok {
+		return dir
+	}
+	return c.config.Directory
+}
+
+// sessionURL builds the URL for a session endpoint, scoped to the session's
+// directory. path is appended after /session/{id}, e.g. "/abort".
+func (c *Client) sessionURL(sessionID, path string) string {
+	url := c.config.BaseURL + "/session/" + sessionID + path
+	if dir := c.sessionDirectory(sessionID); dir != "" {
+		url += "?" + neturl.Values{"directory": {dir}}.Encode()
+	}
+	return url
+}
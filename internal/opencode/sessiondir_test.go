@@ -0,0 +1,68 @@
+package opencode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_UsesSessionDirectory(t *testing.T) {
+	requests := make(map[string]string) // "METHOD path" -> directory
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path] = r.URL.Query().Get("directory")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/session":
+			json.NewEncoder(w).Encode([]Session{
+				{ID: "ses_web", Directory: "/work/web app"},
+				{ID: "ses_api", Directory: "/work/api"},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/session":
+			json.NewEncoder(w).Encode(Session{ID: "ses_fork", Directory: r.URL.Query().Get("directory")})
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`true`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, Directory: "/default"})
+
+	// Unknown sessions fall back to Config.Directory
+	if err := client.AbortSession("ses_web"); err != nil {
+		t.Fatalf("AbortSession() error = %v", err)
+	}
+	if got := requests["POST /session/ses_web/abort"]; got != "/default" {
+		t.Errorf("abort before ListSessions used directory %q, want /default", got)
+	}
+
+	if _, err := client.ListSessions(); err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+
+	if err := client.AbortSession("ses_web"); err != nil {
+		t.Fatalf("AbortSession() error = %v", err)
+	}
+	if got := requests["POST /session/ses_web/abort"]; got != "/work/web app" {
+		t.Errorf("abort used directory %q, want /work/web app", got)
+	}
+
+	if err := client.DeleteSession("ses_api"); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if got := requests["DELETE /session/ses_api"]; got != "/work/api" {
+		t.Errorf("delete used directory %q, want /work/api", got)
+	}
+
+	// Forks are created in their parent's directory and remembered
+	parent := "ses_web"
+	if _, err := client.CreateSession(nil, &parent); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if got := requests["POST /session"]; got != "/work/web app" {
+		t.Errorf("fork created in %q, want /work/web app", got)
+	}
+	if got := client.sessionDirectory("ses_fork"); got != "/work/web app" {
+		t.Errorf("fork directory = %q, want /work/web app", got)
+	}
+}
@@ -3,27 +3,50 @@ package opencode
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/user/opencode-telegram/internal/logging"
 	"github.com/user/opencode-telegram/internal/metrics"
 )
 
+var sseLogger = logging.For("sse")
+
+// DefaultSSEStaleTimeout is how long the stream may stay silent before the
+// consumer assumes the connection is dead and reconnects
+const DefaultSSEStaleTimeout = 90 * time.Second
+
 // SSEConsumer consumes Server-Sent Events from OpenCode
 type SSEConsumer struct {
-	config     Config
-	httpClient *http.Client
-	eventChan  chan Event
-	closeChan  chan struct{}
-	closeOnce  sync.Once
-	ctx        context.Context
-	cancel     context.CancelFunc
+	config       Config
+	httpClient   *http.Client
+	eventChan    chan Event
+	queue        *eventQueue
+	closeChan    chan struct{}
+	closeOnce    sync.Once
+	ctx          context.Context
+	cancel       context.CancelFunc
+	staleTimeout time.Duration
+	filter       *sessionFilter // nil: deliver every event
+	published    chan struct{}  // closed when PublishTo stops (nil: not publishing)
+
+	strict *strictDecoding // nil: unknown fields and event types pass silently
+
+	connected    atomic.Bool
+	onConnChange func(connected bool)
+
+	// WebSocket transport (see websocket.go); wsPath "" reads SSE
+	wsPath string
+	wsTLS  *tls.Config
 }
 
 // NewSSEConsumer creates a new SSE consumer
@@ -32,14 +55,10 @@ func NewSSEConsumer(config Config) *SSEConsumer {
 		config.BaseURL = "http://localhost:54321"
 	}
 
-	return &SSEConsumer{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: 0, // No timeout for SSE connections
-		},
-		eventChan: make(chan Event, 100), // Buffer events
-		closeChan: make(chan struct{}),
-	}
+	return newSSEConsumer(config, &http.Client{
+		Timeout:   0, // No timeout for SSE connections
+		Transport: withAuth(nil, config.APIKey),
+	})
 }
 
 // NewSSEConsumerWithTransport creates a new SSE consumer with optional custom transport
@@ -52,15 +71,62 @@ func NewSSEConsumerWithTransport(config Config, transport *http.Transport) *SSEC
 		Timeout: 0, // No timeout for SSE connections
 	}
 
+	var base http.RoundTripper
 	if transport != nil {
-		httpClient.Transport = transport
+		base = transport
 	}
+	httpClient.Transport = withAuth(base, config.APIKey)
+
+	return newSSEConsumer(config, httpClient)
+}
+
+func newSSEConsumer(config Config, httpClient *http.Client) *SSEConsumer {
+	s := &SSEConsumer{
+		config:       config,
+		httpClient:   httpClient,
+		eventChan:    make(chan Event, 100), // Buffer events
+		queue:        newEventQueue(DefaultEventBacklog),
+		closeChan:    make(chan struct{}),
+		staleTimeout: DefaultSSEStaleTimeout,
+	}
+	go s.queue.run(s.eventChan, s.closeChan)
+	return s
+}
 
-	return &SSEConsumer{
-		config:     config,
-		httpClient: httpClient,
-		eventChan:  make(chan Event, 100),
-		closeChan:  make(chan struct{}),
+// SetStaleTimeout changes how long the stream may go without any bytes,
+// heartbeats and comments included, before it is dropped and reconnected.
+// A half-open TCP connection otherwise looks connected until the kernel
+// gives up on it. Zero disables the watchdog. Call before Connect.
+func (s *SSEConsumer) SetStaleTimeout(timeout time.Duration) {
+	s.staleTimeout = timeout
+}
+
+// SetEventBacklog changes how many events may wait while the bridge falls
+// behind before streaming updates are dropped. Other events are always kept.
+// Zero or less removes the limit.
+func (s *SSEConsumer) SetEventBacklog(limit int) {
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+	s.queue.limit = limit
+}
+
+// OnConnectionChange registers a callback for when the stream connects or
+// drops, e.g. to report readiness to the health monitor. Call before
+// Connect.
+func (s *SSEConsumer) OnConnectionChange(fn func(connected bool)) {
+	s.onConnChange = fn
+}
+
+// setConnected updates the connection gauge and calls the
+// OnConnectionChange callback if the state changed
+func (s *SSEConsumer) setConnected(connected bool) {
+	if connected {
+		metrics.ActiveSSEConnections.Set(1)
+	} else {
+		metrics.ActiveSSEConnections.Set(0)
+	}
+	if s.connected.Swap(connected) != connected && s.onConnChange != nil {
+		s.onConnChange(connected)
 	}
 }
 
@@ -69,6 +135,32 @@ func (s *SSEConsumer) Events() <-chan Event {
 	return s.eventChan
 }
 
+// Publisher receives the events of a source, e.g. an event bus
+type Publisher interface {
+	Publish(event Event)
+}
+
+// PublishTo forwards received events to pub until ctx is done or the
+// consumer is closed
+func (s *SSEConsumer) PublishTo(ctx context.Context, pub Publisher) {
+	s.published = make(chan struct{})
+	go func() {
+		defer close(s.published)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-s.eventChan:
+				if !ok {
+					return
+				}
+				metrics.SSEEventBacklog.Dec()
+				pub.Publish(event)
+			}
+		}
+	}()
+}
+
 // Connect establishes the SSE connection with automatic reconnection
 func (s *SSEConsumer) Connect(ctx context.Context) error {
 	s.ctx, s.cancel = context.WithCancel(ctx)
@@ -78,14 +170,33 @@ func (s *SSEConsumer) Connect(ctx context.Context) error {
 	return nil
 }
 
+// Drain stops reading the stream, waits until PublishTo has passed on the
+// events already received, and closes the consumer
+func (s *SSEConsumer) Drain(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.queue.end()
+	defer s.Close()
+
+	if s.published == nil {
+		return nil
+	}
+	select {
+	case <-s.published:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d events not published: %w", s.queue.len(), ctx.Err())
+	}
+}
+
 // Close closes the SSE connection
 func (s *SSEConsumer) Close() {
 	s.closeOnce.Do(func() {
 		if s.cancel != nil {
 			s.cancel()
 		}
-		close(s.closeChan)
-		close(s.eventChan)
+		close(s.closeChan) // the queue closes eventChan
 	})
 }
 
@@ -135,12 +246,20 @@ func (s *SSEConsumer) reconnectLoop() {
 
 // connect establishes a single SSE connection
 func (s *SSEConsumer) connect() error {
+	if s.wsPath != "" {
+		return s.connectWebSocket()
+	}
+
 	url := s.config.BaseURL + "/event"
 	if s.config.Directory != "" {
-		url += "?directory=" + s.config.Directory
+		url += "?" + neturl.Values{"directory": {s.config.Directory}}.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, url, nil)
+	// The watchdog cancels this connection when the stream goes silent
+	connCtx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(connCtx, http.MethodGet, url, nil)
 	if err != nil {
 		metrics.SSEConnectionErrors.WithLabelValues("request_creation").Inc()
 		return fmt.Errorf("create SSE request: %w", err)
@@ -148,28 +267,62 @@ func (s *SSEConsumer) connect() error {
 
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
+	// A compressing proxy could hold events back until its buffer fills
+	req.Header.Set("Accept-Encoding", "identity")
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		metrics.SSEConnectionErrors.WithLabelValues("connection").Inc()
-		metrics.ActiveSSEConnections.Set(0)
+		s.setConnected(false)
 		return fmt.Errorf("connect to SSE: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		metrics.SSEConnectionErrors.WithLabelValues("http_status").Inc()
-		metrics.ActiveSSEConnections.Set(0)
+		s.setConnected(false)
 		return fmt.Errorf("SSE connection failed with status: %d", resp.StatusCode)
 	}
 
-	metrics.ActiveSSEConnections.Set(1)
+	s.setConnected(true)
+
+	var body io.Reader = resp.Body
+	var stale atomic.Bool
+	if s.staleTimeout > 0 {
+		watchdog := time.AfterFunc(s.staleTimeout, func() {
+			stale.Store(true)
+			cancel()
+		})
+		defer watchdog.Stop()
+		body = &watchdogReader{r: resp.Body, watchdog: watchdog, timeout: s.staleTimeout}
+	}
 
-	err = s.readEvents(resp.Body)
-	metrics.ActiveSSEConnections.Set(0)
+	err = s.readEvents(body)
+	s.setConnected(false)
+	if stale.Load() && s.ctx.Err() == nil {
+		// Not a server failure: reconnect right away
+		sseLogger.Warn("No data, reconnecting", "stale_timeout", s.staleTimeout)
+		metrics.SSEConnectionErrors.WithLabelValues("stale").Inc()
+		return nil
+	}
 	return err
 }
 
+// watchdogReader pushes back the watchdog whenever bytes arrive
+type watchdogReader struct {
+	r        io.Reader
+	watchdog *time.Timer
+	timeout  time.Duration
+}
+
+func (w *watchdogReader) Read(p []byte) (int, error) {
+	n, err := w.r.Read(p)
+	if n > 0 {
+		w.watchdog.Reset(w.timeout)
+	}
+	return n, err
+}
+
 // readEvents reads and parses SSE events from the connection
 func (s *SSEConsumer) readEvents(r io.Reader) error {
 	scanner := bufio.NewScanner(r)
@@ -183,26 +336,7 @@ func (s *SSEConsumer) readEvents(r io.Reader) error {
 		// Empty line indicates end of event
 		if line == "" {
 			if len(dataLines) > 0 {
-				// Parse and send event
-				data := strings.Join(dataLines, "\n")
-
-				// OpenCode sends events as: data: {"type":"...", "properties":{...}}
-				// Try to extract type from JSON if not set via event: field
-				if eventType == "" {
-					var envelope struct {
-						Type string `json:"type"`
-					}
-					if err := json.Unmarshal([]byte(data), &envelope); err == nil && envelope.Type != "" {
-						eventType = envelope.Type
-					}
-				}
-
-				if eventType != "" {
-					if err := s.parseAndSendEvent(eventType, data); err != nil {
-						// Log error but continue processing
-						fmt.Printf("Error parsing event: %v\n", err)
-					}
-				}
+				s.handleData(eventType, strings.Join(dataLines, "\n"))
 			}
 			// Reset for next event
 			eventType = ""
@@ -222,6 +356,27 @@ func (s *SSEConsumer) readEvents(r io.Reader) error {
 	return scanner.Err()
 }
 
+// handleData parses one event's data and queues it unless filtered out
+func (s *SSEConsumer) handleData(eventType, data string) {
+	// OpenCode sends events as: data: {"type":"...", "properties":{...}}
+	// Try to extract type from JSON if not set via event: field
+	if eventType == "" {
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(data), &envelope); err == nil && envelope.Type != "" {
+			eventType = envelope.Type
+		}
+	}
+
+	if eventType != "" && (s.filter == nil || s.filter.allow(eventType, data)) {
+		if err := s.parseAndSendEvent(eventType, data); err != nil {
+			// Log error but continue processing
+			sseLogger.Warn("Error parsing event", "type", eventType, "error", err)
+		}
+	}
+}
+
 // parseAndSendEvent parses event data and sends it to the channel
 func (s *SSEConsumer) parseAndSendEvent(eventType, data string) error {
 	event := Event{
@@ -233,64 +388,64 @@ func (s *SSEConsumer) parseAndSendEvent(eventType, data string) error {
 	switch eventType {
 	case "question.asked":
 		var evt EventQuestionAsked
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal question.asked: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "question.replied":
 		var evt EventQuestionReplied
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal question.replied: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "question.rejected":
 		var evt EventQuestionRejected
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal question.rejected: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "permission.asked":
 		var evt EventPermissionAsked
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal permission.asked: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "permission.replied":
 		var evt EventPermissionReplied
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal permission.replied: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "message.updated":
 		var evt EventMessageUpdated
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal message.updated: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "message.part.updated":
 		var evt EventMessagePartUpdated
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal message.part.updated: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "session.idle":
 		var evt EventSessionIdle
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal session.idle: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
 	case "session.error":
 		var evt EventSessionError
-		if err := json.Unmarshal([]byte(data), &evt); err != nil {
-			return fmt.Errorf("unmarshal session.error: %w", err)
+		if err := s.decodeEvent(eventType, data, &evt); err != nil {
+			return err
 		}
 		event.Properties = &evt
 
@@ -298,8 +453,12 @@ func (s *SSEConsumer) parseAndSendEvent(eventType, data string) error {
 		// Generic event, parse as map
 		var props map[string]interface{}
 		if err := json.Unmarshal([]byte(data), &props); err != nil {
+			metrics.EventDecodeErrors.WithLabelValues(eventType, "invalid").Inc()
 			return fmt.Errorf("unmarshal generic event: %w", err)
 		}
+		if s.strict != nil {
+			s.strict.untyped(eventType)
+		}
 		// Extract properties field if it exists
 		if p, ok := props["properties"]; ok {
 			event.Properties = p
@@ -308,17 +467,6 @@ func (s *SSEConsumer) parseAndSendEvent(eventType, data string) error {
 		}
 	}
 
-	// Send event to channel (non-blocking)
-	select {
-	case s.eventChan <- event:
-	case <-s.ctx.Done():
-		return s.ctx.Err()
-	case <-s.closeChan:
-		return nil
-	default:
-		// Channel full, drop event
-		fmt.Printf("Warning: event channel full, dropping event: %s\n", eventType)
-	}
-
+	s.queue.push(event)
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -35,7 +36,8 @@ func TestSSE_Connect(t *testing.T) {
 		event := EventSessionIdle{
 			Type: "session.idle",
 			Properties: struct {
-				SessionID string `json:"sessionID"`
+				SessionID string  `json:"sessionID"`
+				Content   *string `json:"content,omitempty"`
 			}{
 				SessionID: "sess_123",
 			},
@@ -127,10 +129,11 @@ func TestSSE_ParseQuestionAskedEvent(t *testing.T) {
 			t.Errorf("Expected event type 'question.asked', got %s", event.Type)
 		}
 
-		props, ok := event.Properties.(*QuestionRequest)
+		evt, ok := event.Properties.(*EventQuestionAsked)
 		if !ok {
-			t.Fatalf("Expected *QuestionRequest, got %T", event.Properties)
+			t.Fatalf("Expected *EventQuestionAsked, got %T", event.Properties)
 		}
+		props := evt.Properties
 		if props.ID != "req_123" {
 			t.Errorf("Expected request ID 'req_123', got %s", props.ID)
 		}
@@ -191,10 +194,11 @@ func TestSSE_ParsePermissionAskedEvent(t *testing.T) {
 			t.Errorf("Expected event type 'permission.asked', got %s", event.Type)
 		}
 
-		props, ok := event.Properties.(*PermissionRequest)
+		evt, ok := event.Properties.(*EventPermissionAsked)
 		if !ok {
-			t.Fatalf("Expected *PermissionRequest, got %T", event.Properties)
+			t.Fatalf("Expected *EventPermissionAsked, got %T", event.Properties)
 		}
+		props := evt.Properties
 		if props.ID != "perm_123" {
 			t.Errorf("Expected permission ID 'perm_123', got %s", props.ID)
 		}
@@ -222,7 +226,8 @@ func TestSSE_Reconnect(t *testing.T) {
 			event := EventSessionIdle{
 				Type: "session.idle",
 				Properties: struct {
-					SessionID string `json:"sessionID"`
+					SessionID string  `json:"sessionID"`
+					Content   *string `json:"content,omitempty"`
 				}{
 					SessionID: "sess_first",
 				},
@@ -239,7 +244,8 @@ func TestSSE_Reconnect(t *testing.T) {
 			event := EventSessionIdle{
 				Type: "session.idle",
 				Properties: struct {
-					SessionID string `json:"sessionID"`
+					SessionID string  `json:"sessionID"`
+					Content   *string `json:"content,omitempty"`
 				}{
 					SessionID: "sess_second",
 				},
@@ -265,12 +271,11 @@ func TestSSE_Reconnect(t *testing.T) {
 	// Should receive event from first connection
 	select {
 	case event := <-consumer.Events():
-		propsStruct, ok := event.Properties.(*struct {
-			SessionID string `json:"sessionID"`
-		})
+		evt, ok := event.Properties.(*EventSessionIdle)
 		if !ok {
-			t.Fatalf("Unexpected properties type: %T (want *struct{ SessionID string })", event.Properties)
+			t.Fatalf("Unexpected properties type: %T (want *EventSessionIdle)", event.Properties)
 		}
+		propsStruct := evt.Properties
 		if propsStruct.SessionID != "sess_first" {
 			t.Errorf("Expected first event sessionID 'sess_first', got %v", propsStruct.SessionID)
 		}
@@ -281,12 +286,11 @@ func TestSSE_Reconnect(t *testing.T) {
 	// Should receive event from second connection (after reconnect)
 	select {
 	case event := <-consumer.Events():
-		propsStruct, ok := event.Properties.(*struct {
-			SessionID string `json:"sessionID"`
-		})
+		evt, ok := event.Properties.(*EventSessionIdle)
 		if !ok {
-			t.Fatalf("Unexpected properties type: %T (want *struct{ SessionID string })", event.Properties)
+			t.Fatalf("Unexpected properties type: %T (want *EventSessionIdle)", event.Properties)
 		}
+		propsStruct := evt.Properties
 		if propsStruct.SessionID != "sess_second" {
 			t.Errorf("Expected second event sessionID 'sess_second', got %v", propsStruct.SessionID)
 		}
@@ -398,3 +402,221 @@ func TestSSE_MultilineData(t *testing.T) {
 		t.Fatal("Timeout waiting for multiline event")
 	}
 }
+
+func TestSSE_StaleStreamReconnects(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"sess_%d\"}}\n\n", n)
+		w.(http.Flusher).Flush()
+		// Go silent without closing, like a half-open connection
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	consumer := NewSSEConsumer(Config{BaseURL: server.URL})
+	consumer.SetStaleTimeout(200 * time.Millisecond)
+	if err := consumer.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	for _, want := range []string{"sess_1", "sess_2"} {
+		select {
+		case event := <-consumer.Events():
+			evt, ok := event.Properties.(*EventSessionIdle)
+			if !ok || evt.Properties.SessionID != want {
+				t.Fatalf("Expected session.idle for %s, got %+v", want, event.Properties)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for event from %s", want)
+		}
+	}
+}
+
+func TestSSE_HeartbeatsKeepStreamAlive(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	consumer := NewSSEConsumer(Config{BaseURL: server.URL})
+	consumer.SetStaleTimeout(200 * time.Millisecond)
+	if err := consumer.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	time.Sleep(600 * time.Millisecond)
+	if n := connections.Load(); n != 1 {
+		t.Errorf("Expected heartbeats to keep one connection, got %d connections", n)
+	}
+}
+
+func TestSSE_SessionFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"type":"message.part.updated","properties":{"part":{"sessionID":"tui","text":"noise"}}}`,
+			`{"type":"session.created","properties":{"info":{"id":"other_child","parentID":"tui"}}}`,
+			`{"type":"session.created","properties":{"info":{"id":"child","parentID":"mine"}}}`,
+			`{"type":"permission.asked","properties":{"id":"per_1","sessionID":"child","permission":"bash"}}`,
+			`{"type":"server.connected","properties":{}}`,
+			`{"type":"session.idle","properties":{"sessionID":"other_child"}}`,
+			`{"type":"session.idle","properties":{"sessionID":"mine"}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	consumer := NewSSEConsumer(Config{BaseURL: server.URL})
+	consumer.SetSessionFilter(func(sessionID string) bool { return sessionID == "mine" })
+	if err := consumer.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case event := <-consumer.Events():
+			got = append(got, event.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for events, got %v", got)
+		}
+	}
+	want := []string{"session.created", "permission.asked", "server.connected", "session.idle"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected events %v, got %v", want, got)
+		}
+	}
+	select {
+	case event := <-consumer.Events():
+		t.Errorf("Unexpected event %s", event.Type)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSSE_DirectoryIsEscaped(t *testing.T) {
+	gotDir := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case gotDir <- r.URL.Query().Get("directory"):
+		default:
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	consumer := NewSSEConsumer(Config{BaseURL: server.URL, Directory: "/work/a&b c"})
+	if err := consumer.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	if dir := <-gotDir; dir != "/work/a&b c" {
+		t.Errorf("Expected directory %q, got %q", "/work/a&b c", dir)
+	}
+}
+
+// slowPublisher records event types, taking a while over each
+type slowPublisher struct {
+	mu    sync.Mutex
+	types []string
+}
+
+func (p *slowPublisher) Publish(event Event) {
+	time.Sleep(5 * time.Millisecond)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.types = append(p.types, event.Type)
+}
+
+func TestSSE_DrainPublishesReceivedEvents(t *testing.T) {
+	s := NewSSEConsumer(Config{})
+	pub := &slowPublisher{}
+	s.PublishTo(context.Background(), pub)
+
+	for i := 0; i < 20; i++ {
+		if err := s.parseAndSendEvent("session.idle", `{"type":"session.idle","properties":{"sessionID":"ses_1"}}`); err != nil {
+			t.Fatalf("parseAndSendEvent() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	if len(pub.types) != 20 {
+		t.Errorf("Expected 20 events published before Drain returned, got %d", len(pub.types))
+	}
+}
+
+func TestSSE_ConnectionChange(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first connection drops right away, the second stays open
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	changes := make(chan bool, 10)
+	consumer := NewSSEConsumer(Config{BaseURL: server.URL})
+	consumer.OnConnectionChange(func(connected bool) { changes <- connected })
+	if err := consumer.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	for _, want := range []bool{true, false, true} {
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("connection change = %v, want %v", got, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Timeout waiting for connection change to %v", want)
+		}
+	}
+}
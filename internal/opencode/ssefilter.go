@@ -0,0 +1,83 @@
+package opencode
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// sessionFilter drops events for sessions nobody is interested in. Child
+// sessions (e.g. subagent runs) of wanted sessions are let through too, since
+// their permission and question requests block the parent.
+type sessionFilter struct {
+	wants    func(sessionID string) bool
+	mu       sync.Mutex
+	children map[string]bool
+}
+
+// eventScope holds the fields that tie an event to a session
+type eventScope struct {
+	Properties struct {
+		SessionID string `json:"sessionID"`
+		Info      *struct {
+			ID        string `json:"id"`
+			SessionID string `json:"sessionID"`
+			ParentID  string `json:"parentID"`
+		} `json:"info"`
+		Part *struct {
+			SessionID string `json:"sessionID"`
+		} `json:"part"`
+	} `json:"properties"`
+}
+
+// SetSessionFilter makes the consumer drop events of sessions for which
+// wants returns false, before they are decoded or queued. Events that carry
+// no session (server and installation events) always pass. The server-side
+// directory filter (Config.Directory) still applies. Call before Connect.
+func (s *SSEConsumer) SetSessionFilter(wants func(sessionID string) bool) {
+	s.filter = &sessionFilter{wants: wants, children: make(map[string]bool)}
+}
+
+// allow reports whether an event should be delivered
+func (f *sessionFilter) allow(eventType, data string) bool {
+	var scope eventScope
+	if err := json.Unmarshal([]byte(data), &scope); err != nil {
+		return true // let the regular parser report it
+	}
+	props := scope.Properties
+
+	sessionID := props.SessionID
+	if sessionID == "" && props.Part != nil {
+		sessionID = props.Part.SessionID
+	}
+	if sessionID == "" && props.Info != nil {
+		if strings.HasPrefix(eventType, "session.") {
+			sessionID = props.Info.ID
+		} else {
+			sessionID = props.Info.SessionID
+		}
+	}
+	if sessionID == "" {
+		return true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.children[sessionID] {
+		if eventType == "session.deleted" {
+			delete(f.children, sessionID)
+		}
+		return true
+	}
+	if f.wants(sessionID) {
+		return true
+	}
+	// A new child of a wanted (or already admitted) session
+	if strings.HasPrefix(eventType, "session.") && props.Info != nil && props.Info.ParentID != "" {
+		if f.children[props.Info.ParentID] || f.wants(props.Info.ParentID) {
+			f.children[sessionID] = true
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package opencode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+)
+
+// SessionStatus is what a session is doing, as reported by /session/status
+type SessionStatus struct {
+	Type    string `json:"type"`              // "idle", "busy" or "retry"
+	Attempt int    `json:"attempt,omitempty"` // retry only
+	Message string `json:"message,omitempty"` // retry only
+}
+
+// Busy reports whether the session is still generating (retrying counts)
+func (s SessionStatus) Busy() bool {
+	return s.Type == "busy" || s.Type == "retry"
+}
+
+// ListSessionStatuses retrieves the status of every session that is not
+// idle; sessions missing from the result are idle
+func (c *Client) ListSessionStatuses() (map[string]SessionStatus, error) {
+	url := c.config.BaseURL + "/session/status"
+	if c.config.Directory != "" {
+		url += "?directory=" + neturl.QueryEscape(c.config.Directory)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create session status request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list session statuses: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("list session statuses", resp)
+	}
+
+	var statuses map[string]SessionStatus
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("decode session statuses: %w", err)
+	}
+	return statuses, nil
+}
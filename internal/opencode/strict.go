@@ -0,0 +1,66 @@
+package opencode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+// strictDecoding reports events that do not match the types this bridge
+// knows, a sign that OpenCode's event schema has moved on. Each distinct
+// problem is logged once; every occurrence is counted.
+type strictDecoding struct {
+	logged sync.Map // problem -> struct{}
+}
+
+// SetStrictDecoding turns on schema drift detection: typed events are also
+// decoded with unknown fields disallowed, and events without a type of
+// their own are reported. Events are delivered as before either way. Meant
+// for debugging, as OpenCode adds fields freely. Call before Connect.
+func (s *SSEConsumer) SetStrictDecoding(enabled bool) {
+	if enabled {
+		s.strict = &strictDecoding{}
+	} else {
+		s.strict = nil
+	}
+}
+
+// decodeEvent unmarshals an event's data into v, counting failures per
+// event type
+func (s *SSEConsumer) decodeEvent(eventType, data string, v interface{}) error {
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		metrics.EventDecodeErrors.WithLabelValues(eventType, "invalid").Inc()
+		return fmt.Errorf("unmarshal %s: %w", eventType, err)
+	}
+	if s.strict != nil {
+		s.strict.check(eventType, data, v)
+	}
+	return nil
+}
+
+// check decodes data again, this time rejecting fields v has no place for
+func (d *strictDecoding) check(eventType, data string, v interface{}) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	fresh := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := decoder.Decode(fresh); err != nil {
+		metrics.EventDecodeErrors.WithLabelValues(eventType, "unknown_field").Inc()
+		d.logOnce(eventType+": "+err.Error(), "Schema drift", "type", eventType, "error", err)
+	}
+}
+
+// untyped reports an event decoded as a generic map
+func (d *strictDecoding) untyped(eventType string) {
+	metrics.EventDecodeErrors.WithLabelValues(eventType, "untyped").Inc()
+	d.logOnce(eventType, "Event type has no typed schema, decoded as a map", "type", eventType)
+}
+
+func (d *strictDecoding) logOnce(problem, msg string, args ...interface{}) {
+	if _, seen := d.logged.LoadOrStore(problem, struct{}{}); !seen {
+		sseLogger.Warn(msg, args...)
+	}
+}
@@ -0,0 +1,89 @@
+package opencode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// decodeErrors reads event_decode_errors_total for an event type and reason
+func decodeErrors(t *testing.T, eventType, reason string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "event_decode_errors_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["event_type"] == eventType && labels["reason"] == reason {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestStrictDecoding_CountsSchemaDrift(t *testing.T) {
+	s := NewSSEConsumer(Config{})
+	defer s.Close()
+	s.SetStrictDecoding(true)
+
+	before := decodeErrors(t, "session.idle", "unknown_field")
+	beforeUntyped := decodeErrors(t, "session.compacted", "untyped")
+
+	if err := s.parseAndSendEvent("session.idle", `{"type":"session.idle","properties":{"sessionID":"ses_1","newField":1}}`); err != nil {
+		t.Fatalf("parseAndSendEvent() error = %v", err)
+	}
+	if err := s.parseAndSendEvent("session.compacted", `{"type":"session.compacted","properties":{"sessionID":"ses_1"}}`); err != nil {
+		t.Fatalf("parseAndSendEvent() error = %v", err)
+	}
+
+	if got := decodeErrors(t, "session.idle", "unknown_field") - before; got != 1 {
+		t.Errorf("Expected 1 unknown field error, got %v", got)
+	}
+	if got := decodeErrors(t, "session.compacted", "untyped") - beforeUntyped; got != 1 {
+		t.Errorf("Expected 1 untyped event, got %v", got)
+	}
+	// Both events are still delivered
+	for i := 0; i < 2; i++ {
+		select {
+		case <-s.Events():
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 2 events, got %d", i)
+		}
+	}
+}
+
+func TestStrictDecoding_OffByDefault(t *testing.T) {
+	s := NewSSEConsumer(Config{})
+	defer s.Close()
+
+	before := decodeErrors(t, "session.idle", "unknown_field")
+	if err := s.parseAndSendEvent("session.idle", `{"type":"session.idle","properties":{"sessionID":"ses_1","other":true}}`); err != nil {
+		t.Fatalf("parseAndSendEvent() error = %v", err)
+	}
+	if got := decodeErrors(t, "session.idle", "unknown_field") - before; got != 0 {
+		t.Errorf("Expected no schema checks without strict decoding, got %v", got)
+	}
+}
+
+func TestDecodeEvent_CountsInvalidEvents(t *testing.T) {
+	s := NewSSEConsumer(Config{})
+	defer s.Close()
+
+	before := decodeErrors(t, "permission.asked", "invalid")
+	if err := s.parseAndSendEvent("permission.asked", `{"properties":{"id":42}}`); err == nil {
+		t.Fatal("Expected an error for a mistyped field")
+	}
+	if got := decodeErrors(t, "permission.asked", "invalid") - before; got != 1 {
+		t.Errorf("Expected 1 invalid event, got %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package opencode
+
+import "strings"
+
+// maxSummarySnippet caps the assistant snippet in a SessionSummary, in runes
+const maxSummarySnippet = 80
+
+// summaryWindow is how many of a session's latest messages a summary reads,
+// so listing many long sessions does not fetch their whole history
+const summaryWindow = 50
+
+// SessionSummary condenses a session's latest messages for listings
+type SessionSummary struct {
+	SessionID     string
+	MessageCount  int
+	LastAssistant string // start of the last assistant text, on one line
+	InputTokens   int    // includes cache reads and writes
+	OutputTokens  int    // includes reasoning
+	Cost          float64
+
+	// Truncated is set when the session may have older messages than those
+	// summarized: the counts are then lower bounds
+	Truncated bool
+}
+
+// TotalTokens returns the input and output tokens used by the session
+func (s *SessionSummary) TotalTokens() int {
+	return s.InputTokens + s.OutputTokens
+}
+
+// GetSessionSummary fetches a session's latest messages and summarizes them
+func (c *Client) GetSessionSummary(sessionID string) (*SessionSummary, error) {
+	messages, err := c.GetMessages(sessionID, summaryWindow)
+	if err != nil {
+		return nil, err
+	}
+	summary := summarizeMessages(sessionID, messages)
+	summary.Truncated = len(messages) >= summaryWindow
+	return summary, nil
+}
+
+// summarizeMessages counts messages and token usage and picks the last
+// assistant text
+func summarizeMessages(sessionID string, messages []Message) *SessionSummary {
+	summary := &SessionSummary{SessionID: sessionID, MessageCount: len(messages)}
+	for _, msg := range messages {
+		if msg.Info.Role != "assistant" {
+			continue
+		}
+		if tokens := msg.Info.Tokens; tokens != nil {
+			summary.InputTokens += tokens.Input + tokens.Cache.Read + tokens.Cache.Write
+			summary.OutputTokens += tokens.Output + tokens.Reasoning
+		}
+		summary.Cost += msg.Info.Cost
+
+		var text []string
+		for _, part := range msg.Parts {
+			if part.Type == "text" && strings.TrimSpace(part.Text) != "" {
+				text = append(text, part.Text)
+			}
+		}
+		if len(text) > 0 {
+			summary.LastAssistant = snippet(strings.Join(text, " "), maxSummarySnippet)
+		}
+	}
+	return summary
+}
+
+// snippet collapses whitespace and truncates s to max runes
+func snippet(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,71 @@
+package opencode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetSessionSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/ses_1/message" || r.URL.Query().Get("limit") != "50" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`[
+			{"info": {"id": "msg_1", "role": "user"}, "parts": [{"type": "text", "text": "hi"}]},
+			{"info": {"id": "msg_2", "role": "assistant", "cost": 0.5,
+				"tokens": {"input": 100, "output": 20, "reasoning": 5, "cache": {"read": 1000, "write": 10}}},
+				"parts": [{"type": "text", "text": "first"}]},
+			{"info": {"id": "msg_3", "role": "user"}, "parts": [{"type": "text", "text": "again"}]},
+			{"info": {"id": "msg_4", "role": "assistant", "cost": 0.25,
+				"tokens": {"input": 50, "output": 30, "reasoning": 0, "cache": {"read": 0, "write": 0}}},
+				"parts": [{"type": "tool"}, {"type": "text", "text": "Refactored\n  the   parser"}]}
+		]`))
+	}))
+	defer server.Close()
+
+	summary, err := NewClient(Config{BaseURL: server.URL}).GetSessionSummary("ses_1")
+	if err != nil {
+		t.Fatalf("GetSessionSummary: %v", err)
+	}
+	want := SessionSummary{
+		SessionID:     "ses_1",
+		MessageCount:  4,
+		LastAssistant: "Refactored the parser",
+		InputTokens:   1160,
+		OutputTokens:  55,
+		Cost:          0.75,
+	}
+	if *summary != want {
+		got, _ := json.Marshal(summary)
+		t.Errorf("summary = %s, want %+v", got, want)
+	}
+	if summary.TotalTokens() != 1215 {
+		t.Errorf("TotalTokens = %d, want 1215", summary.TotalTokens())
+	}
+}
+
+func TestGetSessionSummaryReadsLatestMessages(t *testing.T) {
+	server := messageServer(summaryWindow + 10)
+	defer server.Close()
+
+	summary, err := NewClient(Config{BaseURL: server.URL}).GetSessionSummary("ses_1")
+	if err != nil {
+		t.Fatalf("GetSessionSummary: %v", err)
+	}
+	if summary.MessageCount != summaryWindow || !summary.Truncated {
+		t.Errorf("expected the latest %d messages, truncated; got %d, %v", summaryWindow, summary.MessageCount, summary.Truncated)
+	}
+}
+
+func TestSnippetTruncates(t *testing.T) {
+	got := snippet(strings.Repeat("word ", 40), 20)
+	if got != "word word word word…" {
+		t.Errorf("snippet = %q", got)
+	}
+	if len([]rune(got)) > 20 {
+		t.Errorf("snippet longer than 20 runes: %q", got)
+	}
+}
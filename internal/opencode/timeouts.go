@@ -0,0 +1,30 @@
+package opencode
+
+import "time"
+
+// Timeouts bounds how long each kind of OpenCode request may take, including
+// retries. Zero means no limit.
+type Timeouts struct {
+	Default  time.Duration // sessions, questions, permissions, config, ...
+	Health   time.Duration // GET /health
+	Prompt   time.Duration // prompt submissions, which may wait for the whole agent run
+	Messages time.Duration // message history fetches
+}
+
+// DefaultTimeouts keeps health checks short and lets prompts run as long as
+// the agent needs
+var DefaultTimeouts = Timeouts{
+	Default:  60 * time.Second,
+	Health:   2 * time.Second,
+	Prompt:   0,
+	Messages: 60 * time.Second,
+}
+
+// SetTimeouts changes the per-endpoint timeouts. Call it before the client
+// is used.
+func (c *Client) SetTimeouts(t Timeouts) {
+	c.httpClient.Timeout = t.Default
+	c.healthClient.Timeout = t.Health
+	c.promptClient.Timeout = t.Prompt
+	c.messagesClient.Timeout = t.Messages
+}
@@ -0,0 +1,35 @@
+package opencode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_PerEndpointTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.SetRetryPolicy(RetryPolicy{})
+	client.SetCircuitBreaker(0, 0)
+	client.SetTimeouts(Timeouts{
+		Default:  time.Second,
+		Health:   20 * time.Millisecond,
+		Messages: time.Second,
+	})
+
+	if _, err := client.Health(); err == nil {
+		t.Error("Expected health check to time out")
+	}
+	if _, err := client.ListSessions(); err != nil {
+		t.Errorf("ListSessions() error = %v", err)
+	}
+	if _, err := client.GetMessages("ses_1", 10); err != nil {
+		t.Errorf("GetMessages() error = %v", err)
+	}
+}
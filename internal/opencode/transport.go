@@ -2,10 +2,13 @@ package opencode
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"golang.org/x/net/proxy"
@@ -58,6 +61,90 @@ func NewProxyTransport(proxyURL string) (*http.Transport, error) {
 	return transport, nil
 }
 
+// KeepAlive tunes connection reuse to the OpenCode server
+type KeepAlive struct {
+	MaxIdleConnsPerHost int           // idle connections kept open to the server
+	IdleConnTimeout     time.Duration // how long an idle connection is kept
+}
+
+// DefaultKeepAlive keeps enough connections for overlapping event handling,
+// prompts and message fetches. http.DefaultTransport keeps only 2 per host,
+// so concurrent requests otherwise pay a new TCP/TLS handshake each time,
+// which hurts over high-latency links such as Tailscale.
+var DefaultKeepAlive = KeepAlive{
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     5 * time.Minute,
+}
+
+// NewTransport returns a transport for OpenCode requests: a copy of
+// http.DefaultTransport (environment proxy, dial and TLS timeouts) tuned
+// with DefaultKeepAlive
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	TuneTransport(transport, DefaultKeepAlive)
+	return transport
+}
+
+// TuneTransport applies keep-alive settings to transport and makes sure
+// responses are compressed: with compression enabled the transport asks for
+// gzip and decompresses transparently, which shrinks large message lists
+// several times over
+func TuneTransport(transport *http.Transport, keepAlive KeepAlive) {
+	transport.DisableKeepAlives = false
+	transport.DisableCompression = false
+	transport.MaxIdleConnsPerHost = keepAlive.MaxIdleConnsPerHost
+	if transport.MaxIdleConns != 0 && transport.MaxIdleConns < keepAlive.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = keepAlive.MaxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = keepAlive.IdleConnTimeout
+}
+
+// TLSFiles locates PEM files for an OpenCode server behind a TLS terminator
+type TLSFiles struct {
+	CAFile   string // extra CA bundle trusted in addition to the system roots
+	CertFile string // client certificate for mTLS (requires KeyFile)
+	KeyFile  string
+}
+
+// Enabled reports whether any TLS file is configured
+func (f TLSFiles) Enabled() bool {
+	return f.CAFile != "" || f.CertFile != "" || f.KeyFile != ""
+}
+
+// NewTLSConfig builds a client TLS config from the CA bundle and client
+// certificate in files
+func NewTLSConfig(files TLSFiles) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if files.CAFile != "" {
+		pem, err := os.ReadFile(files.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", files.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if files.CertFile != "" || files.KeyFile != "" {
+		if files.CertFile == "" || files.KeyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
 // NewHTTPClient creates an HTTP client with optional proxy support
 func NewHTTPClient(proxyURL string, timeout time.Duration) (*http.Client, error) {
 	transport, err := NewProxyTransport(proxyURL)
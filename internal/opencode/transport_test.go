@@ -1,7 +1,22 @@
 package opencode
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -83,3 +98,136 @@ func TestNewHTTPClientInvalidProxy(t *testing.T) {
 	_, err := NewHTTPClient("!invalid://proxy", 30*time.Second)
 	assert.Error(t, err)
 }
+
+// writePEM writes PEM blocks to a temp file and returns its path
+func writePEM(t *testing.T, name string, blocks ...*pem.Block) string {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		require.NoError(t, pem.Encode(&buf, block))
+	}
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	return path
+}
+
+// newTestCert creates a self-signed ECDSA certificate for 127.0.0.1
+func newTestCert(t *testing.T) (tls.Certificate, *x509.Certificate, *pem.Block, *pem.Block) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "opencode-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certBlock := &pem.Block{Type: "CERTIFICATE", Bytes: der}
+	keyBlock := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}
+	cert, err := tls.X509KeyPair(pem.EncodeToMemory(certBlock), pem.EncodeToMemory(keyBlock))
+	require.NoError(t, err)
+	return cert, leaf, certBlock, keyBlock
+}
+
+func TestNewTLSConfigMutualTLS(t *testing.T) {
+	serverCert, _, serverPEM, _ := newTestCert(t)
+	_, clientLeaf, clientPEM, clientKeyPEM := newTestCert(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientLeaf)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	files := TLSFiles{
+		CAFile:   writePEM(t, "ca.pem", serverPEM),
+		CertFile: writePEM(t, "client.crt", clientPEM),
+		KeyFile:  writePEM(t, "client.key", clientKeyPEM),
+	}
+	config, err := NewTLSConfig(files)
+	require.NoError(t, err)
+
+	transport, err := NewProxyTransport("")
+	require.NoError(t, err)
+	transport.TLSClientConfig = config
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Without the client certificate the server rejects the handshake
+	config, err = NewTLSConfig(TLSFiles{CAFile: files.CAFile})
+	require.NoError(t, err)
+	transport, err = NewProxyTransport("")
+	require.NoError(t, err)
+	transport.TLSClientConfig = config
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	assert.False(t, TLSFiles{}.Enabled())
+
+	_, err := NewTLSConfig(TLSFiles{CertFile: "client.crt"})
+	assert.ErrorContains(t, err, "must be set together")
+
+	_, err = NewTLSConfig(TLSFiles{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "read CA bundle")
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+	_, err = NewTLSConfig(TLSFiles{CAFile: empty})
+	assert.ErrorContains(t, err, "no certificates found")
+}
+
+func TestTuneTransport(t *testing.T) {
+	transport := &http.Transport{MaxIdleConns: 4, DisableCompression: true, DisableKeepAlives: true}
+	TuneTransport(transport, KeepAlive{MaxIdleConnsPerHost: 8, IdleConnTimeout: time.Minute})
+
+	assert.Equal(t, 8, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 8, transport.MaxIdleConns)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.False(t, transport.DisableCompression)
+	assert.False(t, transport.DisableKeepAlives)
+
+	transport = NewTransport()
+	assert.Equal(t, DefaultKeepAlive.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.NotNil(t, transport.DialContext)
+}
+
+func TestClientDecodesGzipResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("Expected gzip to be accepted, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`[{"info": {"id": "msg_1", "role": "assistant"}, "parts": []}]`))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	messages, err := NewClient(Config{BaseURL: server.URL}).GetMessages("ses_1", 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "msg_1", messages[0].Info.ID)
+}
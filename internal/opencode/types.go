@@ -1,11 +1,15 @@
 package opencode
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Config holds OpenCode client configuration
 type Config struct {
 	BaseURL   string
 	Directory string
+	APIKey    string // sent as "Authorization: Bearer <key>" when set
 }
 
 // QuestionOption represents a choice in a question
@@ -53,12 +57,45 @@ type MessageInfo struct {
 		Started   *int64 `json:"started,omitempty"`
 		Completed *int64 `json:"completed,omitempty"`
 	} `json:"time,omitempty"`
+
+	// Assistant messages only
+	Tokens *MessageTokens `json:"tokens,omitempty"`
+	Cost   float64        `json:"cost,omitempty"`
+}
+
+// MessageTokens is the token usage of an assistant message
+type MessageTokens struct {
+	Input     int `json:"input"`
+	Output    int `json:"output"`
+	Reasoning int `json:"reasoning"`
+	Cache     struct {
+		Read  int `json:"read"`
+		Write int `json:"write"`
+	} `json:"cache"`
 }
 
 // MessagePart represents a part of a message
 type MessagePart struct {
-	Type string `json:"type"` // "text", "image", etc.
+	Type string `json:"type"` // "text", "file", etc.
 	Text string `json:"text,omitempty"`
+
+	// File parts (type "file")
+	Mime     string `json:"mime,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	URL      string `json:"url,omitempty"` // data:, file:// or http(s) URL
+}
+
+// IsImage reports whether the part is a file part holding an image
+func (p MessagePart) IsImage() bool {
+	return p.Type == "file" && strings.HasPrefix(p.Mime, "image/") && p.URL != ""
+}
+
+// FileContent is the response from the file content API
+type FileContent struct {
+	Type     string `json:"type"` // "text" or "binary"
+	Content  string `json:"content"`
+	Encoding string `json:"encoding,omitempty"` // "base64" for binary files
+	MimeType string `json:"mimeType,omitempty"`
 }
 
 // Message represents a complete message with info and parts
@@ -133,9 +170,52 @@ type ImagePartInput struct {
 
 // SendPromptRequest is the request body for sending a prompt
 type SendPromptRequest struct {
-	Agent  *string       `json:"agent,omitempty"`  // Agent type (per-message, not per-session)
-	Parts  []interface{} `json:"parts"`            // Message parts (TextPartInput or ImagePartInput)
-	System *string       `json:"system,omitempty"` // System message
+	MessageID string        `json:"messageID,omitempty"` // Client-generated ID (see NewMessageID)
+	Agent     *string       `json:"agent,omitempty"`     // Agent type (per-message, not per-session)
+	Model     *ModelRef     `json:"model,omitempty"`     // Model (per-message; nil: the agent's or OpenCode's default)
+	Parts     []interface{} `json:"parts"`               // Message parts (TextPartInput or ImagePartInput)
+	System    *string       `json:"system,omitempty"`    // System message
+}
+
+// ModelRef identifies a model of a provider
+type ModelRef struct {
+	ProviderID string `json:"providerID"`
+	ModelID    string `json:"modelID"`
+}
+
+// PromptOptions selects who answers a prompt; empty fields leave the choice
+// to OpenCode
+type PromptOptions struct {
+	Agent     string // agent name
+	Model     string // "provider/model"
+	MessageID string // ID of the user message; generated when empty
+}
+
+// request builds the body of a prompt with these options
+func (o PromptOptions) request(parts []interface{}) SendPromptRequest {
+	req := SendPromptRequest{
+		MessageID: o.MessageID,
+		Parts:     parts,
+	}
+	if req.MessageID == "" {
+		req.MessageID = NewMessageID()
+	}
+	if o.Agent != "" {
+		agent := o.Agent
+		req.Agent = &agent
+	}
+	// Model IDs may contain slashes themselves, provider IDs do not
+	if provider, model, ok := strings.Cut(o.Model, "/"); ok && provider != "" && model != "" {
+		req.Model = &ModelRef{ProviderID: provider, ModelID: model}
+	}
+	return req
+}
+
+// InitSessionRequest is the request body for generating a project's AGENTS.md
+type InitSessionRequest struct {
+	MessageID  string `json:"messageID"`
+	ProviderID string `json:"providerID"`
+	ModelID    string `json:"modelID"`
 }
 
 // AssistantMessage represents the response from sending a prompt
@@ -161,6 +241,7 @@ type Event struct {
 	Type       string
 	Properties interface{}
 	Timestamp  time.Time
+	Raw        []byte // the event as received, kept by sources that can replay it
 }
 
 // EventQuestionAsked represents a question.asked event
@@ -287,3 +368,11 @@ type ProvidersResponse struct {
 	Providers []Provider        `json:"providers"`
 	Default   map[string]string `json:"default"`
 }
+
+// Agent represents an agent from /agent
+type Agent struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Mode        string `json:"mode"` // "primary", "subagent" or "all"
+	BuiltIn     bool   `json:"builtIn,omitempty"`
+}
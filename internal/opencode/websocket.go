@@ -0,0 +1,144 @@
+package opencode
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+// NewWebSocketConsumer creates a consumer reading the event stream from a
+// WebSocket at path on the server, for networks whose proxies buffer SSE
+// responses. Each text message holds one event as an SSE data line would
+// ({"type": ..., "properties": ...}). Backlog, session filter, stale timeout,
+// reconnects and PublishTo work as for SSE.
+//
+// The WebSocket is dialed directly: of transport, only the TLS settings
+// apply, not its proxy.
+func NewWebSocketConsumer(config Config, transport *http.Transport, path string) *SSEConsumer {
+	s := NewSSEConsumerWithTransport(config, transport)
+	s.wsPath = "/" + strings.TrimPrefix(path, "/")
+	if transport != nil {
+		s.wsTLS = transport.TLSClientConfig
+	}
+	return s
+}
+
+// webSocketURL turns the server's base URL into the ws:// or wss:// URL of
+// path, scoped to directory
+func webSocketURL(baseURL, path, directory string) (string, error) {
+	u, err := neturl.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported base URL scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = ""
+	if directory != "" {
+		u.RawQuery = neturl.Values{"directory": {directory}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// connectWebSocket reads events from a single WebSocket connection
+func (s *SSEConsumer) connectWebSocket() error {
+	location, err := webSocketURL(s.config.BaseURL, s.wsPath, s.config.Directory)
+	if err != nil {
+		metrics.SSEConnectionErrors.WithLabelValues("request_creation").Inc()
+		return err
+	}
+	wsConfig, err := websocket.NewConfig(location, s.config.BaseURL)
+	if err != nil {
+		metrics.SSEConnectionErrors.WithLabelValues("request_creation").Inc()
+		return fmt.Errorf("create WebSocket config: %w", err)
+	}
+	wsConfig.TlsConfig = s.wsTLS
+	if s.config.APIKey != "" {
+		wsConfig.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	netConn, err := dialWebSocket(s.ctx, wsConfig)
+	if err != nil {
+		metrics.SSEConnectionErrors.WithLabelValues("connection").Inc()
+		s.setConnected(false)
+		return fmt.Errorf("connect to WebSocket: %w", err)
+	}
+	defer netConn.Close()
+	stop := context.AfterFunc(s.ctx, func() { netConn.Close() })
+	defer stop()
+
+	conn, err := websocket.NewClient(wsConfig, &deadlineConn{Conn: netConn, timeout: s.staleTimeout})
+	if err != nil {
+		metrics.SSEConnectionErrors.WithLabelValues("connection").Inc()
+		s.setConnected(false)
+		return fmt.Errorf("connect to WebSocket: %w", err)
+	}
+
+	s.setConnected(true)
+	defer s.setConnected(false)
+
+	for {
+		var data string
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && s.ctx.Err() == nil {
+				// Not a server failure: reconnect right away
+				sseLogger.Warn("No WebSocket message, reconnecting", "stale_timeout", s.staleTimeout)
+				metrics.SSEConnectionErrors.WithLabelValues("stale").Inc()
+				return nil
+			}
+			return fmt.Errorf("read WebSocket: %w", err)
+		}
+		s.handleData("", data)
+	}
+}
+
+// dialWebSocket opens the TCP or TLS connection for the handshake, as
+// websocket.Config.DialContext would
+func dialWebSocket(ctx context.Context, config *websocket.Config) (net.Conn, error) {
+	addr := config.Location.Host
+	if config.Location.Port() == "" {
+		port := "80"
+		if config.Location.Scheme == "wss" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(config.Location.Hostname(), port)
+	}
+	if config.Location.Scheme == "wss" {
+		dialer := &tls.Dialer{Config: config.TlsConfig}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// deadlineConn extends the read deadline before every read, so any frame
+// keeps the connection alive: websocket answers pings and drops pongs inside
+// Receive, which only returns on a message. Zero timeout disables it.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	if c.timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	}
+	return c.Conn.Read(p)
+}
@@ -0,0 +1,105 @@
+package opencode
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocket_Connect(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		r := ws.Request()
+		if r.URL.Path != "/event/ws" {
+			t.Errorf("Expected path /event/ws, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("directory"); got != "/work/my project" {
+			t.Errorf("Expected directory query, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Expected API key, got %q", got)
+		}
+		websocket.Message.Send(ws, `{"type":"session.idle","properties":{"sessionID":"ses_ws"}}`)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	consumer := NewWebSocketConsumer(Config{BaseURL: server.URL, Directory: "/work/my project", APIKey: "secret"}, nil, "event/ws")
+	if err := consumer.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	select {
+	case event := <-consumer.Events():
+		if event.Type != "session.idle" || event.SessionID() != "ses_ws" {
+			t.Errorf("Unexpected event %s for %q", event.Type, event.SessionID())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for event")
+	}
+}
+
+func TestWebSocket_PingsKeepConnection(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		connections.Add(1)
+		// Only pings for longer than the stale timeout, then an event
+		ws.PayloadType = websocket.PingFrame
+		for i := 0; i < 8; i++ {
+			if _, err := ws.Write(nil); err != nil {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		websocket.Message.Send(ws, `{"type":"session.idle","properties":{"sessionID":"ses_ping"}}`)
+		<-ws.Request().Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	consumer := NewWebSocketConsumer(Config{BaseURL: server.URL}, nil, "event/ws")
+	consumer.SetStaleTimeout(200 * time.Millisecond)
+	if err := consumer.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer consumer.Close()
+
+	select {
+	case event := <-consumer.Events():
+		if event.SessionID() != "ses_ping" {
+			t.Errorf("Unexpected event for %q", event.SessionID())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for event")
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("Expected 1 connection, got %d", n)
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	tests := []struct {
+		base, want string
+	}{
+		{"http://localhost:4096", "ws://localhost:4096/event/ws"},
+		{"https://opencode.example.com/api/", "wss://opencode.example.com/api/event/ws"},
+	}
+	for _, tt := range tests {
+		got, err := webSocketURL(tt.base, "/event/ws", "")
+		if err != nil || got != tt.want {
+			t.Errorf("webSocketURL(%q) = %q, %v; want %q", tt.base, got, err, tt.want)
+		}
+	}
+	if _, err := webSocketURL("ftp://host", "/event/ws", ""); err == nil {
+		t.Error("Expected an error for an unsupported scheme")
+	}
+}
@@ -0,0 +1,177 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxAuditEntries caps the entries kept in memory for /audit; the file keeps
+// them all
+const maxAuditEntries = 1000
+
+// Audited actions
+const (
+	AuditPermission    = "permission"
+	AuditSessionDelete = "session_delete"
+	AuditAgentSwitch   = "agent_switch"
+	AuditModelSwitch   = "model_switch"
+	AuditShell         = "shell" // a bash tool call OpenCode ran for the chat
+)
+
+// AuditEntry is a sensitive action taken from Telegram
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	ChatID string    `json:"chat_id"`
+	UserID int64     `json:"user_id,omitempty"`
+	User   string    `json:"user,omitempty"`
+	Action string    `json:"action"`
+	Detail string    `json:"detail"`
+}
+
+// AuditLog is an append-only record of sensitive actions. The file holds one
+// JSON entry per line, or one base64-encoded encrypted entry per line when a
+// cipher is set.
+type AuditLog struct {
+	mu       sync.Mutex
+	entries  []AuditEntry
+	filePath string
+}
+
+// LoadAuditLog opens the log at filePath (empty for in-memory only) and reads
+// its latest entries. A missing file is an empty log. When the file cannot
+// be read or decrypted the log is kept in memory only, so nothing is appended
+// to a file that could not be checked.
+func LoadAuditLog(filePath string) (*AuditLog, error) {
+	a := &AuditLog{}
+	if filePath == "" {
+		return a, nil
+	}
+
+	expanded, err := expandHome(filePath)
+	if err != nil {
+		return a, fmt.Errorf("failed to expand path: %w", err)
+	}
+
+	data, err := os.ReadFile(expanded)
+	if err != nil && !os.IsNotExist(err) {
+		return a, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		entry, err := decodeAuditLine(text)
+		if err != nil {
+			a.entries = nil
+			return a, fmt.Errorf("failed to read audit log line %d: %w", line, err)
+		}
+		a.entries = append(a.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		a.entries = nil
+		return a, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if len(a.entries) > maxAuditEntries {
+		a.entries = a.entries[len(a.entries)-maxAuditEntries:]
+	}
+
+	a.filePath = expanded
+	return a, nil
+}
+
+// Record appends an entry, stamped with the current time when it has none
+func (a *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.entries = append(a.entries, entry)
+	if len(a.entries) > maxAuditEntries {
+		a.entries = a.entries[len(a.entries)-maxAuditEntries:]
+	}
+	return a.appendLocked(entry)
+}
+
+// Entries returns the latest n entries of a chat, oldest first
+func (a *AuditLog) Entries(chatID string, n int) []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var result []AuditEntry
+	for i := len(a.entries) - 1; i >= 0 && len(result) < n; i-- {
+		if a.entries[i].ChatID == chatID {
+			result = append(result, a.entries[i])
+		}
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// appendLocked writes an entry at the end of the file
+func (a *AuditLog) appendLocked(entry AuditEntry) error {
+	if a.filePath == "" {
+		return nil
+	}
+
+	line, err := encodeAuditLine(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(a.filePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+func encodeAuditLine(entry AuditEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if fileCipher != nil {
+		sealed, err := encrypt(data)
+		if err != nil {
+			return nil, err
+		}
+		data = []byte(base64.StdEncoding.EncodeToString(sealed))
+	}
+	return append(data, '\n'), nil
+}
+
+func decodeAuditLine(line []byte) (AuditEntry, error) {
+	var entry AuditEntry
+	if line[0] != '{' {
+		sealed, err := base64.StdEncoding.DecodeString(string(line))
+		if err != nil {
+			return entry, err
+		}
+		if line, err = Decrypt(sealed); err != nil {
+			return entry, err
+		}
+	}
+	err := json.Unmarshal(line, &entry)
+	return entry, err
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogAppendsAndReloads(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "audit")
+	a, err := LoadAuditLog(logFile)
+	if err != nil {
+		t.Fatalf("LoadAuditLog failed: %v", err)
+	}
+	a.Record(AuditEntry{ChatID: "-100", UserID: 7, User: "@alice", Action: AuditPermission, Detail: "once: bash"})
+	a.Record(AuditEntry{ChatID: "-200", Action: AuditModelSwitch, Detail: "openai/gpt-5"})
+	a.Record(AuditEntry{ChatID: "-100", Action: AuditSessionDelete, Detail: "ses_1"})
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 {
+		t.Fatalf("expected one JSON line per entry, got %q", data)
+	}
+
+	reloaded, err := LoadAuditLog(logFile)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	entries := reloaded.Entries("-100", 10)
+	if len(entries) != 2 || entries[0].Action != AuditPermission || entries[1].Detail != "ses_1" {
+		t.Fatalf("expected the chat's two entries oldest first, got %+v", entries)
+	}
+	if entries[0].User != "@alice" || entries[0].UserID != 7 || entries[0].Time.IsZero() {
+		t.Errorf("expected who and when to be kept, got %+v", entries[0])
+	}
+	if latest := reloaded.Entries("-100", 1); len(latest) != 1 || latest[0].Detail != "ses_1" {
+		t.Errorf("expected the latest entry, got %+v", latest)
+	}
+}
+
+func TestEncryptedAuditLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "audit")
+	useCipher(t, 1)
+
+	a, _ := LoadAuditLog(logFile)
+	a.Record(AuditEntry{ChatID: "-100", Action: AuditSessionDelete, Detail: "ses_secret"})
+	data, _ := os.ReadFile(logFile)
+	if strings.Contains(string(data), "ses_secret") {
+		t.Error("expected the audit log to be encrypted")
+	}
+	if entries := mustLoadAudit(t, logFile).Entries("-100", 1); len(entries) != 1 || entries[0].Detail != "ses_secret" {
+		t.Errorf("expected the entry decrypted, got %+v", entries)
+	}
+
+	useCipher(t, 2)
+	wrongKey, err := LoadAuditLog(logFile)
+	if err == nil {
+		t.Fatal("expected an error with the wrong key")
+	}
+	wrongKey.Record(AuditEntry{ChatID: "-100", Action: AuditModelSwitch})
+	if after, _ := os.ReadFile(logFile); string(after) != string(data) {
+		t.Error("expected nothing appended to a log that could not be read")
+	}
+}
+
+func mustLoadAudit(t *testing.T, path string) *AuditLog {
+	t.Helper()
+	a, err := LoadAuditLog(path)
+	if err != nil {
+		t.Fatalf("LoadAuditLog failed: %v", err)
+	}
+	return a
+}
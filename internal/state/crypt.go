@@ -0,0 +1,141 @@
+package state
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// encryptedMagic starts every encrypted file, followed by the nonce and the
+// sealed content
+var encryptedMagic = []byte("OCTG-AESGCM1\n")
+
+// errUndecryptable reports an encrypted file that cannot be read with the
+// configured key (or without any key). Such files are never overwritten.
+var errUndecryptable = errors.New("cannot decrypt file")
+
+// Cipher encrypts the files of this package (state, outbox and dead
+// letters) with AES-GCM
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a Cipher from a 32-byte AES-256 key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// ParseKey decodes a base64 key, e.g. from `openssl rand -base64 32`
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not valid base64: %w", err)
+	}
+	return key, nil
+}
+
+// LoadKeyFile reads a base64 key from a file
+func LoadKeyFile(filePath string) ([]byte, error) {
+	expanded, err := expandHome(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return ParseKey(string(data))
+}
+
+// fileCipher encrypts files written from now on; nil writes plaintext
+var fileCipher *Cipher
+
+// SetCipher encrypts the files of this package with c. Call it before
+// loading any of them. Plaintext files are still read, and encrypted on
+// their next save.
+func SetCipher(c *Cipher) {
+	fileCipher = c
+}
+
+// encrypt seals data with the configured cipher, if any
+func encrypt(data []byte) ([]byte, error) {
+	if fileCipher == nil {
+		return data, nil
+	}
+	nonce := make([]byte, fileCipher.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := append(append([]byte(nil), encryptedMagic...), nonce...)
+	return fileCipher.aead.Seal(sealed, nonce, data, nil), nil
+}
+
+// Decrypt opens data written encrypted by this package; plaintext is
+// returned as is
+func Decrypt(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		return data, nil
+	}
+	if fileCipher == nil {
+		return nil, fmt.Errorf("%w: no encryption key configured", errUndecryptable)
+	}
+	data = data[len(encryptedMagic):]
+	nonceSize := fileCipher.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("%w: truncated", errUndecryptable)
+	}
+	plain, err := fileCipher.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: wrong key or corrupted content", errUndecryptable)
+	}
+	return plain, nil
+}
+
+// EncryptFile encrypts a plaintext file in place with the configured cipher,
+// e.g. the credentials file, which the bridge reads but never writes
+func EncryptFile(filePath string) error {
+	if fileCipher == nil {
+		return fmt.Errorf("no encryption key configured")
+	}
+	expanded, err := expandHome(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to expand path: %w", err)
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if bytes.HasPrefix(data, encryptedMagic) {
+		return fmt.Errorf("%s is already encrypted", filePath)
+	}
+	if data, err = encrypt(data); err != nil {
+		return err
+	}
+
+	tempFile := expanded + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tempFile, expanded); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
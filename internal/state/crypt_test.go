@@ -0,0 +1,138 @@
+package state
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useCipher encrypts files with a key made of b for the rest of the test
+func useCipher(t *testing.T, b byte) {
+	t.Helper()
+	c, err := NewCipher(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+	SetCipher(c)
+	t.Cleanup(func() { SetCipher(nil) })
+}
+
+func TestEncryptedStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	useCipher(t, 1)
+
+	NewAppState(stateFile).SetSessionForChat("-100", "ses_secret")
+
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ses_secret") {
+		t.Error("expected the state file to be encrypted")
+	}
+	if got := NewAppState(stateFile).GetSessionForChat("-100"); got != "ses_secret" {
+		t.Errorf("expected ses_secret restored, got %q", got)
+	}
+}
+
+func TestPlaintextStateFileEncryptedOnSave(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(stateFile, []byte(`{"session":"ses_old"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	useCipher(t, 1)
+
+	s := NewAppState(stateFile)
+	if got := s.GetCurrentSession(); got != "ses_old" {
+		t.Fatalf("expected the plaintext file to be read, got %q", got)
+	}
+	s.SetSessionForChat("-100", "ses_new")
+
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		t.Error("expected the file to be encrypted on save")
+	}
+}
+
+func TestUndecryptableFileLeftUntouched(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, "state")
+	outboxFile := filepath.Join(dir, "outbox")
+	useCipher(t, 1)
+	NewAppState(stateFile).SetSessionForChat("-100", "ses_secret")
+	outbox, _ := LoadOutbox(outboxFile)
+	outbox.Push(OutboxEntry{ChatID: 1, Kind: OutboxSend, Text: "queued"})
+	savedState, _ := os.ReadFile(stateFile)
+	savedOutbox, _ := os.ReadFile(outboxFile)
+
+	useCipher(t, 2)
+	s := NewAppState(stateFile)
+	if got := s.GetSessionForChat("-100"); got != "" {
+		t.Errorf("expected nothing restored with the wrong key, got %q", got)
+	}
+	s.SetSessionForChat("-100", "ses_other")
+	reloaded, err := LoadOutbox(outboxFile)
+	if err == nil {
+		t.Error("expected an error loading the outbox with the wrong key")
+	}
+	reloaded.Push(OutboxEntry{ChatID: 1, Kind: OutboxSend, Text: "more"})
+
+	if data, _ := os.ReadFile(stateFile); !bytes.Equal(data, savedState) {
+		t.Error("expected the state file to be left untouched")
+	}
+	if data, _ := os.ReadFile(outboxFile); !bytes.Equal(data, savedOutbox) {
+		t.Error("expected the outbox file to be left untouched")
+	}
+}
+
+func TestDecryptWithoutKey(t *testing.T) {
+	useCipher(t, 1)
+	sealed, err := encrypt([]byte("TELEGRAM_BOT_TOKEN=secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCipher(nil)
+
+	if _, err := Decrypt(sealed); err == nil {
+		t.Error("expected an encrypted file to need a key")
+	}
+	if plain, err := Decrypt([]byte("OPENCODE_DIRECTORY=/src")); err != nil || string(plain) != "OPENCODE_DIRECTORY=/src" {
+		t.Errorf("expected plaintext returned as is, got %q (%v)", plain, err)
+	}
+}
+
+func TestNewCipherKeyLength(t *testing.T) {
+	if _, err := NewCipher(make([]byte, 16)); err == nil {
+		t.Error("expected a 16-byte key to be refused")
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(file, []byte("TELEGRAM_BOT_TOKEN=secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptFile(file); err == nil {
+		t.Error("expected an error without a key")
+	}
+
+	useCipher(t, 1)
+	if err := EncryptFile(file); err != nil {
+		t.Fatalf("EncryptFile failed: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, err := Decrypt(data); err != nil || string(plain) != "TELEGRAM_BOT_TOKEN=secret\n" {
+		t.Errorf("expected the content back after decrypting, got %q (%v)", plain, err)
+	}
+	if err := EncryptFile(file); err == nil {
+		t.Error("expected an error for a file encrypted already")
+	}
+}
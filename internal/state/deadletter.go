@@ -0,0 +1,127 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxDeadLetters caps the store; the oldest letters go first
+const maxDeadLetters = 1000
+
+// DeadLetter is an incoming event whose handling failed
+type DeadLetter struct {
+	Payload  json.RawMessage `json:"payload"` // the event as it was received
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// DeadLetters is a durable list of failed events waiting to be replayed
+type DeadLetters struct {
+	mu       sync.Mutex
+	letters  []DeadLetter
+	filePath string
+}
+
+// LoadDeadLetters opens the store at filePath (empty for in-memory only). A
+// missing file is an empty store; an unreadable one is reported and
+// replaced by an empty store, except when it cannot be decrypted: it is then
+// left untouched and the store is kept in memory only.
+func LoadDeadLetters(filePath string) (*DeadLetters, error) {
+	d := &DeadLetters{filePath: filePath}
+	if filePath == "" {
+		return d, nil
+	}
+
+	expanded, err := expandHome(filePath)
+	if err != nil {
+		return d, fmt.Errorf("failed to expand path: %w", err)
+	}
+	d.filePath = expanded
+
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d, nil
+		}
+		return d, fmt.Errorf("failed to read dead letter file: %w", err)
+	}
+	if data, err = Decrypt(data); err != nil {
+		// Keep the file for when the right key is configured
+		d.filePath = ""
+		return d, fmt.Errorf("failed to read dead letter file: %w", err)
+	}
+	if len(data) == 0 {
+		return d, nil
+	}
+
+	if err := json.Unmarshal(data, &d.letters); err != nil {
+		return d, fmt.Errorf("failed to parse dead letter file: %w", err)
+	}
+	return d, nil
+}
+
+// Add stores a failed event
+func (d *DeadLetters) Add(payload []byte, cause error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.letters = append(d.letters, DeadLetter{
+		Payload:  payload,
+		Error:    cause.Error(),
+		FailedAt: time.Now(),
+	})
+	if len(d.letters) > maxDeadLetters {
+		d.letters = d.letters[len(d.letters)-maxDeadLetters:]
+	}
+	return d.saveLocked()
+}
+
+// List returns the stored letters, oldest first
+func (d *DeadLetters) List() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DeadLetter(nil), d.letters...)
+}
+
+// Take removes and returns all stored letters, oldest first
+func (d *DeadLetters) Take() ([]DeadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	letters := d.letters
+	d.letters = nil
+	return letters, d.saveLocked()
+}
+
+// saveLocked writes the store atomically (write-to-temp-file + rename)
+func (d *DeadLetters) saveLocked() error {
+	if d.filePath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.Marshal(d.letters)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letters: %w", err)
+	}
+	if data, err = encrypt(data); err != nil {
+		return err
+	}
+
+	tempFile := d.filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tempFile, d.filePath); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
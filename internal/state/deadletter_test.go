@@ -0,0 +1,46 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeadLettersSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deadletters")
+
+	letters, err := LoadDeadLetters(path)
+	if err != nil {
+		t.Fatalf("LoadDeadLetters failed: %v", err)
+	}
+	if err := letters.Add([]byte(`{"type":"session.idle"}`), errors.New("telegram down")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	reloaded, err := LoadDeadLetters(path)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	list := reloaded.List()
+	if len(list) != 1 || string(list[0].Payload) != `{"type":"session.idle"}` || list[0].Error != "telegram down" {
+		t.Fatalf("unexpected letters after reload: %+v", list)
+	}
+
+	taken, err := reloaded.Take()
+	if err != nil || len(taken) != 1 {
+		t.Fatalf("expected to take 1 letter, got %d (err=%v)", len(taken), err)
+	}
+	if again, _ := LoadDeadLetters(path); len(again.List()) != 0 {
+		t.Fatalf("expected an empty store after Take, got %d letters", len(again.List()))
+	}
+}
+
+func TestDeadLettersCapped(t *testing.T) {
+	letters, _ := LoadDeadLetters("")
+	for i := 0; i < maxDeadLetters+5; i++ {
+		letters.Add([]byte(`{}`), errors.New("failed"))
+	}
+	if got := len(letters.List()); got != maxDeadLetters {
+		t.Fatalf("expected %d letters, got %d", maxDeadLetters, got)
+	}
+}
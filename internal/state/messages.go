@@ -0,0 +1,54 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxMessageRefs bounds the message map; the oldest entries are dropped
+// first
+const maxMessageRefs = 1000
+
+// MessageRef is the OpenCode message behind a Telegram message
+type MessageRef struct {
+	SessionID string    `json:"session"`
+	MessageID string    `json:"message"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// messageKey identifies a Telegram message: IDs are only unique per chat
+func messageKey(chatID string, telegramMsgID int) string {
+	return fmt.Sprintf("%s:%d", chatID, telegramMsgID)
+}
+
+// RecordMessage remembers that a Telegram message of a chat shows an
+// OpenCode message
+func (s *AppState) RecordMessage(chatID string, telegramMsgID int, sessionID, messageID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messageRefs[messageKey(chatID, telegramMsgID)] = MessageRef{
+		SessionID: sessionID,
+		MessageID: messageID,
+		SentAt:    time.Now(),
+	}
+	for len(s.messageRefs) > maxMessageRefs {
+		var oldest string
+		for key, ref := range s.messageRefs {
+			if oldest == "" || ref.SentAt.Before(s.messageRefs[oldest].SentAt) {
+				oldest = key
+			}
+		}
+		delete(s.messageRefs, oldest)
+	}
+	s.saveLocked()
+}
+
+// LookupMessage returns the OpenCode message behind a Telegram message the
+// bridge sent to a chat
+func (s *AppState) LookupMessage(chatID string, telegramMsgID int) (MessageRef, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ref, ok := s.messageRefs[messageKey(chatID, telegramMsgID)]
+	return ref, ok
+}
@@ -0,0 +1,55 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestMessageRefsPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.RecordMessage("-100", 42, "ses_1", "msg_1")
+	s.RecordMessage("-200", 42, "ses_2", "msg_2")
+
+	restored := NewAppState(stateFile)
+	ref, ok := restored.LookupMessage("-100", 42)
+	if !ok || ref.SessionID != "ses_1" || ref.MessageID != "msg_1" {
+		t.Errorf("expected ses_1/msg_1 restored, got %+v (found=%v)", ref, ok)
+	}
+	if ref, _ := restored.LookupMessage("-200", 42); ref.MessageID != "msg_2" {
+		t.Errorf("expected message IDs to be kept per chat, got %+v", ref)
+	}
+	if _, ok := restored.LookupMessage("-100", 43); ok {
+		t.Error("expected an unknown message not to be found")
+	}
+}
+
+func TestMessageRefsBounded(t *testing.T) {
+	s := NewAppStateForTest()
+	for i := 1; i <= maxMessageRefs+10; i++ {
+		s.RecordMessage("-100", i, "ses_1", fmt.Sprintf("msg_%d", i))
+	}
+
+	if _, ok := s.LookupMessage("-100", 1); ok {
+		t.Error("expected the oldest message to be dropped")
+	}
+	if _, ok := s.LookupMessage("-100", maxMessageRefs+10); !ok {
+		t.Error("expected the newest message to be kept")
+	}
+}
+
+func TestForgetSessionDropsMessageRefs(t *testing.T) {
+	s := NewAppStateForTest()
+	s.RecordMessage("-100", 1, "ses_gone", "msg_1")
+	s.RecordMessage("-100", 2, "ses_kept", "msg_2")
+
+	s.ForgetSession("ses_gone")
+
+	if _, ok := s.LookupMessage("-100", 1); ok {
+		t.Error("expected the deleted session's messages to be forgotten")
+	}
+	if _, ok := s.LookupMessage("-100", 2); !ok {
+		t.Error("expected other sessions' messages to be kept")
+	}
+}
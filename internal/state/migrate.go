@@ -0,0 +1,131 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SchemaVersion is the version of the state file this build writes.
+// Version 0 is the bare session ID of the first releases, version 1 the
+// unversioned JSON object that followed.
+const SchemaVersion = 5
+
+// errNewerSchema reports a state file written by a newer version. Such
+// files are never overwritten, so downgrading does not lose them.
+var errNewerSchema = errors.New("state file written by a newer version")
+
+// errBackupFailed reports a state file that needs migrating but could not
+// be backed up first; it is not overwritten either
+var errBackupFailed = errors.New("cannot back up state file")
+
+// migration upgrades a state file by one version
+type migration struct {
+	description string
+	apply       func(data []byte) ([]byte, error)
+}
+
+// migrations[i] upgrades a state file from version i to i+1. To change the
+// schema, append a migration and bump SchemaVersion; the files already
+// written keep loading and are upgraded on startup.
+var migrations = []migration{
+	{
+		description: "wrap the bare session ID in a JSON object",
+		apply: func(data []byte) ([]byte, error) {
+			return json.Marshal(map[string]string{"session": string(data)})
+		},
+	},
+	{
+		// The version is written with the state; nothing else changed
+		description: "add the schema version",
+		apply:       func(data []byte) ([]byte, error) { return data, nil },
+	},
+	{
+		// Nothing to convert, but older builds would drop the aliases when
+		// saving: the version bump makes them leave the file alone
+		description: "add per-chat command aliases",
+		apply:       func(data []byte) ([]byte, error) { return data, nil },
+	},
+	{
+		// Likewise for the photo prompts
+		description: "add per-chat photo prompts",
+		apply:       func(data []byte) ([]byte, error) { return data, nil },
+	},
+	{
+		// And for the per-user group sessions
+		description: "add per-user group sessions",
+		apply:       func(data []byte) ([]byte, error) { return data, nil },
+	},
+}
+
+// schemaVersion returns the version of a decrypted, trimmed state file
+func schemaVersion(data []byte) (int, error) {
+	if data[0] != '{' {
+		return 0, nil
+	}
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if header.Version == 0 {
+		return 1, nil
+	}
+	return header.Version, nil
+}
+
+// migrateState upgrades a decrypted, trimmed state file to SchemaVersion. It
+// returns the upgraded content, the version it was written with and the
+// steps applied.
+func migrateState(data []byte) ([]byte, int, []string, error) {
+	from, err := schemaVersion(data)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if from > SchemaVersion {
+		return nil, from, nil, fmt.Errorf("%w: version %d, this build reads up to %d", errNewerSchema, from, SchemaVersion)
+	}
+
+	var steps []string
+	for version := from; version < SchemaVersion; version++ {
+		m := migrations[version]
+		if data, err = m.apply(data); err != nil {
+			return nil, from, steps, fmt.Errorf("failed to migrate state from version %d: %w", version, err)
+		}
+		steps = append(steps, fmt.Sprintf("v%d → v%d: %s", version, version+1, m.description))
+	}
+	return data, from, steps, nil
+}
+
+// PlanStateMigration reports the migrations loading a state file would
+// apply, without changing it (dry run)
+func PlanStateMigration(backend, stateFile string) ([]string, error) {
+	expanded, err := expandHome(stateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+	// Opening a database creates it: check there is one first
+	if _, err := os.Stat(expanded); os.IsNotExist(err) {
+		return nil, nil
+	}
+	store, err := OpenStore(backend, expanded)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	data, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if data, err = Decrypt(data); err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if data = bytes.TrimSpace(data); len(data) == 0 {
+		return nil, nil
+	}
+	_, _, steps, err := migrateState(data)
+	return steps, err
+}
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readVersion(t *testing.T, stateFile string) int {
+	t.Helper()
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved persistedState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("expected a JSON state file, got %q: %v", data, err)
+	}
+	return saved.Version
+}
+
+func TestMigrateBareSessionID(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(stateFile, []byte("ses_legacy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAppState(stateFile)
+	if got := s.GetCurrentSession(); got != "ses_legacy" {
+		t.Errorf("expected ses_legacy after the migration, got %q", got)
+	}
+	if got := readVersion(t, stateFile); got != SchemaVersion {
+		t.Errorf("expected the file rewritten as version %d, got %d", SchemaVersion, got)
+	}
+	backup, err := os.ReadFile(stateFile + ".v0.bak")
+	if err != nil || string(backup) != "ses_legacy\n" {
+		t.Errorf("expected the original kept in a backup, got %q (%v)", backup, err)
+	}
+}
+
+func TestMigrateUnversionedJSON(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	original := `{"session":"ses_a","chat_sessions":{"-100":"ses_b"}}`
+	if err := os.WriteFile(stateFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAppState(stateFile)
+	if got := s.GetSessionForChat("-100"); got != "ses_b" {
+		t.Errorf("expected ses_b after the migration, got %q", got)
+	}
+	if got := readVersion(t, stateFile); got != SchemaVersion {
+		t.Errorf("expected the file rewritten as version %d, got %d", SchemaVersion, got)
+	}
+	if backup, err := os.ReadFile(stateFile + ".v1.bak"); err != nil || string(backup) != original {
+		t.Errorf("expected the original kept in a backup, got %q (%v)", backup, err)
+	}
+
+	// Loading the current version migrates nothing
+	NewAppState(stateFile)
+	if _, err := os.Stat(fmt.Sprintf("%s.v%d.bak", stateFile, SchemaVersion)); !os.IsNotExist(err) {
+		t.Errorf("expected no backup of an up to date file, got %v", err)
+	}
+}
+
+func TestMigrateVersion2(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	original := `{"version":2,"session":"ses_a"}`
+	if err := os.WriteFile(stateFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAppState(stateFile)
+	if got := s.GetCurrentSession(); got != "ses_a" {
+		t.Errorf("expected ses_a after the migration, got %q", got)
+	}
+	if len(s.ListAliases("-100")) != 0 {
+		t.Error("expected no aliases in a file that predates them")
+	}
+	if got := readVersion(t, stateFile); got != SchemaVersion {
+		t.Errorf("expected the file rewritten as version %d, got %d", SchemaVersion, got)
+	}
+	if backup, err := os.ReadFile(stateFile + ".v2.bak"); err != nil || string(backup) != original {
+		t.Errorf("expected the original kept in a backup, got %q (%v)", backup, err)
+	}
+}
+
+func TestMigrateVersion4(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	original := `{"version":4,"chat_sessions":{"-100":"ses_a"}}`
+	if err := os.WriteFile(stateFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAppState(stateFile)
+	if got := s.GetSessionForChat("-100"); got != "ses_a" {
+		t.Errorf("expected ses_a after the migration, got %q", got)
+	}
+	if got := s.GetUserSession("-100", 1); got != "" {
+		t.Errorf("expected no user sessions in a file that predates them, got %q", got)
+	}
+	if got := readVersion(t, stateFile); got != SchemaVersion {
+		t.Errorf("expected the file rewritten as version %d, got %d", SchemaVersion, got)
+	}
+	if backup, err := os.ReadFile(stateFile + ".v4.bak"); err != nil || string(backup) != original {
+		t.Errorf("expected the original kept in a backup, got %q (%v)", backup, err)
+	}
+}
+
+func TestNewerSchemaLeftUntouched(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	original := `{"version":99,"session":"ses_future"}`
+	if err := os.WriteFile(stateFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAppState(stateFile)
+	if got := s.GetCurrentSession(); got != "" {
+		t.Errorf("expected a file from a newer version not loaded, got %q", got)
+	}
+	s.SetCurrentSession("ses_new")
+	if data, _ := os.ReadFile(stateFile); string(data) != original {
+		t.Errorf("expected the file left untouched, got %q", data)
+	}
+}
+
+func TestPlanStateMigration(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	if steps, err := PlanStateMigration(BackendFile, stateFile); err != nil || len(steps) != 0 {
+		t.Errorf("expected nothing to migrate without a file, got %v (%v)", steps, err)
+	}
+
+	if err := os.WriteFile(stateFile, []byte("ses_legacy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := PlanStateMigration(BackendFile, stateFile)
+	if err != nil || len(steps) != SchemaVersion {
+		t.Errorf("expected %d steps from version 0, got %v (%v)", SchemaVersion, steps, err)
+	}
+	if data, _ := os.ReadFile(stateFile); string(data) != "ses_legacy" {
+		t.Errorf("expected a dry run to leave the file untouched, got %q", data)
+	}
+	if _, err := os.Stat(stateFile + ".v0.bak"); !os.IsNotExist(err) {
+		t.Errorf("expected no backup from a dry run, got %v", err)
+	}
+
+	if err := os.WriteFile(stateFile, []byte(`{"version":99}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PlanStateMigration(BackendFile, stateFile); err == nil {
+		t.Error("expected an error for a file from a newer version")
+	}
+}
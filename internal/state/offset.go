@@ -75,6 +75,40 @@ func SaveOffset(filePath string, offset int64) error {
 	return nil
 }
 
+// AdoptFile copies the file src to dst unless dst exists already, reporting
+// whether it did. A missing src is not an error: there is nothing to adopt.
+func AdoptFile(src, dst string) (bool, error) {
+	src, err := expandHome(src)
+	if err != nil {
+		return false, fmt.Errorf("failed to expand path: %w", err)
+	}
+	dst, err = expandHome(dst)
+	if err != nil {
+		return false, fmt.Errorf("failed to expand path: %w", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		return false, err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	// Write to temp file first, like SaveOffset
+	tempFile := dst + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return false, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tempFile, dst); err != nil {
+		os.Remove(tempFile)
+		return false, fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return true, nil
+}
+
 // expandHome expands ~ to the user's home directory
 func expandHome(path string) (string, error) {
 	if !strings.HasPrefix(path, "~") {
@@ -167,6 +167,41 @@ func TestSaveOffsetWithTildeExpansion(t *testing.T) {
 	}
 }
 
+func TestAdoptFile(t *testing.T) {
+	tempDir := t.TempDir()
+	shared := filepath.Join(tempDir, "offset")
+	own := filepath.Join(tempDir, "offset-1-2")
+
+	// Nothing to adopt yet
+	if adopted, err := AdoptFile(shared, own); err != nil || adopted {
+		t.Fatalf("AdoptFile without a shared file = %v, %v", adopted, err)
+	}
+
+	if err := SaveOffset(shared, 42); err != nil {
+		t.Fatalf("SaveOffset failed: %v", err)
+	}
+	if adopted, err := AdoptFile(shared, own); err != nil || !adopted {
+		t.Fatalf("AdoptFile = %v, %v, expected the file to be adopted", adopted, err)
+	}
+	if offset, _ := LoadOffset(own); offset != 42 {
+		t.Errorf("Adopted offset = %d, expected 42", offset)
+	}
+	if offset, _ := LoadOffset(shared); offset != 42 {
+		t.Errorf("Shared offset = %d, expected it left in place", offset)
+	}
+
+	// The account's own file is never overwritten
+	if err := SaveOffset(shared, 99); err != nil {
+		t.Fatalf("SaveOffset failed: %v", err)
+	}
+	if adopted, err := AdoptFile(shared, own); err != nil || adopted {
+		t.Fatalf("AdoptFile over an existing file = %v, %v", adopted, err)
+	}
+	if offset, _ := LoadOffset(own); offset != 42 {
+		t.Errorf("Own offset = %d, expected 42", offset)
+	}
+}
+
 func TestExpandHome(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -0,0 +1,154 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outbox entry kinds
+const (
+	OutboxSend = "send"
+	OutboxEdit = "edit"
+)
+
+// OutboxEntry is a Telegram send or edit that failed and waits to be retried
+type OutboxEntry struct {
+	ChatID    int64     `json:"chat_id"`
+	Kind      string    `json:"kind"`
+	MessageID int       `json:"message_id,omitempty"` // edit target, or reply-to for sends
+	Text      string    `json:"text"`
+	HTML      bool      `json:"html,omitempty"`
+	QueuedAt  time.Time `json:"queued_at"`
+}
+
+// Outbox is a durable FIFO of failed outgoing messages, shared by all bot
+// accounts. Entries keep their order within a chat and survive restarts.
+type Outbox struct {
+	mu       sync.Mutex
+	entries  []OutboxEntry
+	filePath string
+}
+
+// LoadOutbox opens the outbox stored at filePath (empty for in-memory only).
+// A missing file is an empty outbox; an unreadable one is reported and
+// replaced by an empty outbox, except when it cannot be decrypted: it is
+// then left untouched and the outbox is kept in memory only.
+func LoadOutbox(filePath string) (*Outbox, error) {
+	o := &Outbox{filePath: filePath}
+	if filePath == "" {
+		return o, nil
+	}
+
+	expanded, err := expandHome(filePath)
+	if err != nil {
+		return o, fmt.Errorf("failed to expand path: %w", err)
+	}
+	o.filePath = expanded
+
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return o, nil
+		}
+		return o, fmt.Errorf("failed to read outbox file: %w", err)
+	}
+	if data, err = Decrypt(data); err != nil {
+		// Keep the file for when the right key is configured
+		o.filePath = ""
+		return o, fmt.Errorf("failed to read outbox file: %w", err)
+	}
+	if len(data) == 0 {
+		return o, nil
+	}
+
+	if err := json.Unmarshal(data, &o.entries); err != nil {
+		return o, fmt.Errorf("failed to parse outbox file: %w", err)
+	}
+	return o, nil
+}
+
+// Push appends an entry to the end of its chat's queue
+func (o *Outbox) Push(entry OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if entry.QueuedAt.IsZero() {
+		entry.QueuedAt = time.Now()
+	}
+	o.entries = append(o.entries, entry)
+	return o.saveLocked()
+}
+
+// Next returns the oldest entry queued for a chat
+func (o *Outbox) Next(chatID int64) (OutboxEntry, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, entry := range o.entries {
+		if entry.ChatID == chatID {
+			return entry, true
+		}
+	}
+	return OutboxEntry{}, false
+}
+
+// Done removes the oldest entry queued for a chat once it was delivered or dropped
+func (o *Outbox) Done(chatID int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, entry := range o.entries {
+		if entry.ChatID == chatID {
+			o.entries = append(o.entries[:i], o.entries[i+1:]...)
+			return o.saveLocked()
+		}
+	}
+	return nil
+}
+
+// Pending returns how many entries are queued for a chat
+func (o *Outbox) Pending(chatID int64) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	count := 0
+	for _, entry := range o.entries {
+		if entry.ChatID == chatID {
+			count++
+		}
+	}
+	return count
+}
+
+// saveLocked writes the outbox atomically (write-to-temp-file + rename)
+func (o *Outbox) saveLocked() error {
+	if o.filePath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.Marshal(o.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox: %w", err)
+	}
+	if data, err = encrypt(data); err != nil {
+		return err
+	}
+
+	tempFile := o.filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tempFile, o.filePath); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutboxPerChatOrder(t *testing.T) {
+	outbox, err := LoadOutbox("")
+	if err != nil {
+		t.Fatalf("LoadOutbox failed: %v", err)
+	}
+
+	outbox.Push(OutboxEntry{ChatID: 1, Kind: OutboxSend, Text: "first"})
+	outbox.Push(OutboxEntry{ChatID: 2, Kind: OutboxSend, Text: "other chat"})
+	outbox.Push(OutboxEntry{ChatID: 1, Kind: OutboxEdit, MessageID: 7, Text: "second"})
+
+	if got := outbox.Pending(1); got != 2 {
+		t.Fatalf("expected 2 pending for chat 1, got %d", got)
+	}
+
+	entry, ok := outbox.Next(1)
+	if !ok || entry.Text != "first" {
+		t.Fatalf("expected first entry, got %+v (ok=%v)", entry, ok)
+	}
+	outbox.Done(1)
+
+	entry, ok = outbox.Next(1)
+	if !ok || entry.Text != "second" || entry.MessageID != 7 {
+		t.Fatalf("expected second entry, got %+v (ok=%v)", entry, ok)
+	}
+	outbox.Done(1)
+
+	if _, ok := outbox.Next(1); ok {
+		t.Fatal("expected chat 1 to be drained")
+	}
+	if got := outbox.Pending(2); got != 1 {
+		t.Fatalf("chat 2 entry should be untouched, got %d pending", got)
+	}
+}
+
+func TestOutboxSurvivesRestart(t *testing.T) {
+	outboxFile := filepath.Join(t.TempDir(), "outbox")
+
+	outbox, err := LoadOutbox(outboxFile)
+	if err != nil {
+		t.Fatalf("LoadOutbox failed: %v", err)
+	}
+	if err := outbox.Push(OutboxEntry{ChatID: 1, Kind: OutboxSend, Text: "<b>done</b>", HTML: true}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	reloaded, err := LoadOutbox(outboxFile)
+	if err != nil {
+		t.Fatalf("LoadOutbox after restart failed: %v", err)
+	}
+	entry, ok := reloaded.Next(1)
+	if !ok || entry.Text != "<b>done</b>" || !entry.HTML || entry.QueuedAt.IsZero() {
+		t.Fatalf("unexpected entry after restart: %+v (ok=%v)", entry, ok)
+	}
+}
+
+func TestLoadOutboxCorruptFile(t *testing.T) {
+	outboxFile := filepath.Join(t.TempDir(), "outbox")
+	if err := os.WriteFile(outboxFile, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outbox, err := LoadOutbox(outboxFile)
+	if err == nil {
+		t.Fatal("expected parse error for corrupt outbox")
+	}
+	if outbox == nil || outbox.Pending(1) != 0 {
+		t.Fatal("corrupt outbox should fall back to an empty queue")
+	}
+}
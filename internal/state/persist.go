@@ -0,0 +1,245 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// statusEntry is a session's status and when it was entered
+type statusEntry struct {
+	status SessionStatus
+	since  time.Time
+}
+
+// statusNames are the persisted names of non-idle statuses
+var statusNames = map[SessionStatus]string{
+	SessionBusy:  "busy",
+	SessionError: "error",
+}
+
+// persistedState is the state file's content. Files written by older
+// versions are upgraded when loaded, see migrate.go.
+type persistedState struct {
+	Version        int                          `json:"version"`
+	Session        string                       `json:"session,omitempty"`
+	ChatSessions   map[string]string            `json:"chat_sessions,omitempty"`
+	UserSessions   map[string]string            `json:"user_sessions,omitempty"`
+	ChatModels     map[string]string            `json:"chat_models,omitempty"`
+	ChatAgents     map[string]string            `json:"chat_agents,omitempty"`
+	PhotoPrompts   map[string]string            `json:"photo_prompts,omitempty"`
+	ChatLanguages  map[string]string            `json:"chat_languages,omitempty"`
+	ChatAliases    map[string]map[string]string `json:"chat_aliases,omitempty"`
+	SessionStatus  map[string]persistedStatus   `json:"session_status,omitempty"`
+	Pending        map[string]json.RawMessage   `json:"pending,omitempty"`
+	Registry       *persistedRegistry           `json:"registry,omitempty"`
+	Messages       map[string]MessageRef        `json:"messages,omitempty"`
+	ChatStats      map[string]ChatStats         `json:"chat_stats,omitempty"`
+	ChatDebounce   map[string]int64             `json:"chat_debounce_ms,omitempty"`
+	ChatFavorites  map[string][]string          `json:"chat_favorites,omitempty"`
+	SessionAliases map[string]map[string]string `json:"session_aliases,omitempty"`
+}
+
+type persistedStatus struct {
+	Status string    `json:"status"`
+	Since  time.Time `json:"since"`
+}
+
+// persistedRegistry is the ID registry's content, see IDRegistry.SetStore
+type persistedRegistry struct {
+	Counter int                               `json:"counter"`
+	Entries map[string]persistedRegistryEntry `json:"entries,omitempty"`
+}
+
+// persistedRegistryEntry holds either a registered full ID or a stored value
+type persistedRegistryEntry struct {
+	ID      string          `json:"id,omitempty"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Expires time.Time       `json:"expires"`
+}
+
+// load reads the saved state; a missing state leaves it empty
+func (s *AppState) load() error {
+	original, err := s.store.Load()
+	if err != nil || original == nil {
+		return err
+	}
+	data, err := Decrypt(original)
+	if err != nil {
+		return fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	data, from, steps, err := migrateState(data)
+	if err != nil {
+		return err
+	}
+	if len(steps) > 0 {
+		if err := s.store.Backup(original, from); err != nil {
+			return err
+		}
+		for _, step := range steps {
+			logger.Info("Migrated state file", "step", step)
+		}
+		s.migrated = true
+	}
+
+	var saved persistedState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse state file: %w", err)
+	}
+	s.currentSessionID = saved.Session
+	for chatID, sessionID := range saved.ChatSessions {
+		s.chatSessionMap[chatID] = sessionID
+	}
+	for key, sessionID := range saved.UserSessions {
+		s.userSessionMap[key] = sessionID
+	}
+	for chatID, model := range saved.ChatModels {
+		s.chatModelMap[chatID] = model
+	}
+	for chatID, agent := range saved.ChatAgents {
+		s.chatAgentMap[chatID] = agent
+	}
+	for chatID, prompt := range saved.PhotoPrompts {
+		s.photoPromptMap[chatID] = prompt
+	}
+	for chatID, lang := range saved.ChatLanguages {
+		s.chatLanguageMap[chatID] = lang
+	}
+	for chatID, aliases := range saved.ChatAliases {
+		s.chatAliasMap[chatID] = aliases
+	}
+	for key, value := range saved.Pending {
+		s.pendingMap[key] = value
+	}
+	s.registry = saved.Registry
+	for key, ref := range saved.Messages {
+		s.messageRefs[key] = ref
+	}
+	for chatID, stats := range saved.ChatStats {
+		s.chatStats[chatID] = stats
+	}
+	for chatID, ms := range saved.ChatDebounce {
+		s.chatDebounceMap[chatID] = time.Duration(ms) * time.Millisecond
+	}
+	for chatID, favorites := range saved.ChatFavorites {
+		s.chatFavoriteMap[chatID] = favorites
+	}
+	for chatID, aliases := range saved.SessionAliases {
+		s.sessionAliasMap[chatID] = aliases
+	}
+	for sessionID, entry := range saved.SessionStatus {
+		for status, name := range statusNames {
+			if entry.Status == name {
+				s.sessionStatus[sessionID] = statusEntry{status: status, since: entry.Since}
+			}
+		}
+	}
+	return nil
+}
+
+// saveLocked writes the state to its store. Failures are logged: the bridge
+// keeps working from memory.
+func (s *AppState) saveLocked() {
+	if s.store == nil {
+		return
+	}
+	if err := s.writeLocked(); err != nil {
+		logger.Error("Failed to save session state", "error", err)
+	}
+}
+
+// Close releases the store, e.g. the database's lock. Later changes are
+// kept in memory only.
+func (s *AppState) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store := s.store
+	s.store = nil
+	if store == nil {
+		return nil
+	}
+	return store.Close()
+}
+
+func (s *AppState) writeLocked() error {
+	saved := persistedState{
+		Version:        SchemaVersion,
+		Session:        s.currentSessionID,
+		ChatSessions:   s.chatSessionMap,
+		UserSessions:   s.userSessionMap,
+		ChatModels:     s.chatModelMap,
+		ChatAgents:     s.chatAgentMap,
+		PhotoPrompts:   s.photoPromptMap,
+		ChatLanguages:  s.chatLanguageMap,
+		ChatAliases:    s.chatAliasMap,
+		Pending:        s.pendingMap,
+		Registry:       s.registry,
+		Messages:       s.messageRefs,
+		ChatStats:      s.chatStats,
+		ChatFavorites:  s.chatFavoriteMap,
+		SessionAliases: s.sessionAliasMap,
+		SessionStatus:  make(map[string]persistedStatus),
+	}
+	if len(s.chatDebounceMap) > 0 {
+		saved.ChatDebounce = make(map[string]int64, len(s.chatDebounceMap))
+		for chatID, window := range s.chatDebounceMap {
+			saved.ChatDebounce[chatID] = window.Milliseconds()
+		}
+	}
+	for sessionID, entry := range s.sessionStatus {
+		if name, ok := statusNames[entry.status]; ok {
+			saved.SessionStatus[sessionID] = persistedStatus{Status: name, Since: entry.since}
+		}
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if data, err = encrypt(data); err != nil {
+		return err
+	}
+	return s.store.Save(data)
+}
+
+// savedRegistry returns the ID registry restored from the state file
+func (s *AppState) savedRegistry() *persistedRegistry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.registry
+}
+
+// saveRegistry replaces the ID registry kept in the state file
+func (s *AppState) saveRegistry(registry *persistedRegistry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registry = registry
+	s.saveLocked()
+}
+
+// CheckWritable reports whether a file can be saved at path: its directory is
+// created if needed and must accept new files, as saves write a temp file
+// and rename it. An existing file is left untouched.
+func CheckWritable(path string) error {
+	expanded, err := expandHome(path)
+	if err != nil {
+		return fmt.Errorf("failed to expand path: %w", err)
+	}
+	dir := filepath.Dir(expanded)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -2,17 +2,30 @@ package state
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
 
+// registrySaveDelay batches the registry's saves: changes are written at
+// most this long after they were made, rather than one write each
+const registrySaveDelay = time.Second
+
 type IDRegistry struct {
-	mu       sync.RWMutex
-	counter  int
-	mappings map[string]string    // shortKey → fullID
-	reverse  map[string]string    // fullID → shortKey (for deduplication)
-	ttl      map[string]time.Time // shortKey → expiry time
+	mu        sync.RWMutex
+	counter   int
+	mappings  map[string]string    // shortKey → fullID
+	reverse   map[string]string    // fullID → shortKey (for deduplication)
+	ttl       map[string]time.Time // shortKey → expiry time
+	values    map[string]any       // shortKey → stored value (see Store)
+	store     *AppState            // saves the registry, see SetStore
+	saveTimer *time.Timer          // pending save, see saveLocked
+
+	// flushMu keeps saves in order, so an older snapshot never overwrites
+	// a newer one
+	flushMu sync.Mutex
 }
 
 func NewIDRegistry() *IDRegistry {
@@ -21,6 +34,7 @@ func NewIDRegistry() *IDRegistry {
 		mappings: make(map[string]string),
 		reverse:  make(map[string]string),
 		ttl:      make(map[string]time.Time),
+		values:   make(map[string]any),
 	}
 }
 
@@ -46,10 +60,104 @@ func (r *IDRegistry) Register(fullID string, prefix string, suffix string) strin
 
 	// Set TTL to 1 hour from now
 	r.ttl[shortKey] = time.Now().Add(1 * time.Hour)
+	r.saveLocked()
 
 	return shortKey
 }
 
+// Restore registers a short key handed out before a restart, so it keeps
+// mapping to its full ID and the counter never hands it out again
+func (r *IDRegistry) Restore(shortKey string, fullID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var prefix string
+	var counter int
+	if _, err := fmt.Sscanf(strings.Replace(shortKey, ":", " ", 2), "%s %d", &prefix, &counter); err == nil && counter > r.counter {
+		r.counter = counter
+	}
+	r.mappings[shortKey] = fullID
+	r.reverse[fullID] = shortKey
+	r.ttl[shortKey] = time.Now().Add(1 * time.Hour)
+	r.saveLocked()
+}
+
+// SetStore keeps the registry in the state file of s, so callback_data
+// handed out before a restart still resolves afterwards. Entries saved
+// earlier are restored unless their TTL has passed; the counter resumes where
+// it stopped, so old keys are never handed out again.
+func (r *IDRegistry) SetStore(s *AppState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store = s
+	saved := s.savedRegistry()
+	if saved == nil {
+		return
+	}
+	if saved.Counter > r.counter {
+		r.counter = saved.Counter
+	}
+	now := time.Now()
+	for shortKey, entry := range saved.Entries {
+		if now.After(entry.Expires) {
+			continue
+		}
+		if entry.Value != nil {
+			r.values[shortKey] = entry.Value
+		} else {
+			r.mappings[shortKey] = entry.ID
+			r.reverse[entry.ID] = shortKey
+		}
+		r.ttl[shortKey] = entry.Expires
+	}
+}
+
+// saveLocked schedules a save of the registry to its store, if any. Changes
+// made until it runs are written together.
+func (r *IDRegistry) saveLocked() {
+	if r.store == nil || r.saveTimer != nil {
+		return
+	}
+	r.saveTimer = time.AfterFunc(registrySaveDelay, r.Flush)
+}
+
+// Flush writes pending changes to the store now. Values that cannot be
+// encoded are left out: they only last until the next restart.
+func (r *IDRegistry) Flush() {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	// Snapshot under the registry lock, write under the state's only
+	r.mu.Lock()
+	if r.saveTimer == nil {
+		r.mu.Unlock()
+		return
+	}
+	r.saveTimer.Stop()
+	r.saveTimer = nil
+	saved := &persistedRegistry{
+		Counter: r.counter,
+		Entries: make(map[string]persistedRegistryEntry, len(r.ttl)),
+	}
+	for shortKey, expiry := range r.ttl {
+		entry := persistedRegistryEntry{ID: r.mappings[shortKey], Expires: expiry}
+		if value, ok := r.values[shortKey]; ok {
+			data, err := json.Marshal(value)
+			if err != nil {
+				logger.Warn("Not persisting registry value", "key", shortKey, "error", err)
+				continue
+			}
+			entry.Value = data
+		}
+		saved.Entries[shortKey] = entry
+	}
+	store := r.store
+	r.mu.Unlock()
+
+	store.saveRegistry(saved)
+}
+
 // Lookup retrieves the full ID from a short key.
 // Returns the full ID and a boolean indicating if the key was found.
 func (r *IDRegistry) Lookup(shortKey string) (string, bool) {
@@ -60,6 +168,37 @@ func (r *IDRegistry) Lookup(shortKey string) (string, bool) {
 	return fullID, found
 }
 
+// Store keeps an arbitrary value (e.g. response pages) under a new short key
+// with the same 1 hour TTL as registered IDs.
+// Format: prefix:counter (e.g., "more:3")
+func (r *IDRegistry) Store(prefix string, value any) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counter++
+	shortKey := fmt.Sprintf("%s:%d", prefix, r.counter)
+	r.values[shortKey] = value
+	r.ttl[shortKey] = time.Now().Add(1 * time.Hour)
+	r.saveLocked()
+
+	return shortKey
+}
+
+// Load retrieves a value kept with Store.
+// Returns false once the value has expired. Values restored from the state
+// file (see SetStore) come back as json.RawMessage.
+func (r *IDRegistry) Load(shortKey string) (any, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, found := r.values[shortKey]
+	if !found || time.Now().After(r.ttl[shortKey]) {
+		// Expired entries linger until the next cleanup
+		return nil, false
+	}
+	return value, true
+}
+
 // cleanup removes entries that have expired (TTL passed).
 // This is called internally and can be called explicitly for testing.
 func (r *IDRegistry) cleanup() {
@@ -67,6 +206,7 @@ func (r *IDRegistry) cleanup() {
 	defer r.mu.Unlock()
 
 	now := time.Now()
+	removed := false
 	for shortKey, expiry := range r.ttl {
 		if now.After(expiry) {
 			// Remove expired entry
@@ -74,9 +214,14 @@ func (r *IDRegistry) cleanup() {
 				delete(r.reverse, fullID)
 			}
 			delete(r.mappings, shortKey)
+			delete(r.values, shortKey)
 			delete(r.ttl, shortKey)
+			removed = true
 		}
 	}
+	if removed {
+		r.saveLocked()
+	}
 }
 
 // setTTL is an internal method for testing — allows setting custom TTL times.
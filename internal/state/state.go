@@ -1,14 +1,17 @@
 package state
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
-	"strings"
 	"sync"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/logging"
 )
 
+var logger = logging.For("state")
+
 type SessionStatus int
 
 const (
@@ -23,24 +26,76 @@ type AppState struct {
 	currentAgent     string
 	currentModel     string
 	chatAgentMap     map[string]string
-	sessionStatus    map[string]SessionStatus
-	stateFile        string
+	chatModelMap     map[string]string
+	chatLanguageMap  map[string]string
+	chatSessionMap   map[string]string
+	userSessionMap   map[string]string
+	chatAliasMap     map[string]map[string]string
+	photoPromptMap   map[string]string
+	defaultLanguage  string
+	photoPrompt      string
+	sessionStatus    map[string]statusEntry
+	pendingMap       map[string]json.RawMessage // callback key -> pending prompt
+	registry         *persistedRegistry         // saved by IDRegistry.SetStore
+	messageRefs      map[string]MessageRef      // chat:telegram message -> OpenCode message
+	chatStats        map[string]ChatStats
+	chatDebounceMap  map[string]time.Duration
+	chatFavoriteMap  map[string][]string
+	sessionAliasMap  map[string]map[string]string // chat -> alias -> session ID
+	migrated         bool                         // loaded from an older schema, see migrate.go
+	store            Store                        // nil keeps the state in memory only
 }
 
+// NewAppState loads the state saved in stateFile, "" keeps it in memory only
 func NewAppState(stateFile string) *AppState {
+	if stateFile == "" {
+		return NewAppStateWithStore(nil)
+	}
+	store, err := OpenStore(BackendFile, stateFile)
+	if err != nil {
+		logger.Error("Failed to load session state", "file", stateFile, "error", err)
+	}
+	return NewAppStateWithStore(store)
+}
+
+// NewAppStateWithStore loads the state saved in store, nil keeps it in memory only
+func NewAppStateWithStore(store Store) *AppState {
 	state := &AppState{
-		currentAgent:  "sisyphus",
-		sessionStatus: make(map[string]SessionStatus),
-		chatAgentMap:  make(map[string]string),
-		stateFile:     stateFile,
-	}
-
-	if stateFile != "" {
-		if sessionID, err := LoadSessionState(stateFile); err == nil && sessionID != "" {
-			state.currentSessionID = sessionID
-			log.Printf("[STATE] Loaded saved session: %s", sessionID)
-		} else if err != nil {
-			log.Printf("[STATE] Failed to load session state: %v", err)
+		currentAgent:    "sisyphus",
+		sessionStatus:   make(map[string]statusEntry),
+		pendingMap:      make(map[string]json.RawMessage),
+		messageRefs:     make(map[string]MessageRef),
+		chatStats:       make(map[string]ChatStats),
+		chatDebounceMap: make(map[string]time.Duration),
+		chatFavoriteMap: make(map[string][]string),
+		sessionAliasMap: make(map[string]map[string]string),
+		chatAgentMap:    make(map[string]string),
+		chatModelMap:    make(map[string]string),
+		chatLanguageMap: make(map[string]string),
+		chatSessionMap:  make(map[string]string),
+		userSessionMap:  make(map[string]string),
+		chatAliasMap:    make(map[string]map[string]string),
+		photoPromptMap:  make(map[string]string),
+		defaultLanguage: "en",
+		store:           store,
+	}
+
+	if store != nil {
+		if err := state.load(); err != nil {
+			logger.Error("Failed to load session state", "error", err)
+			if errors.Is(err, errUndecryptable) || errors.Is(err, errNewerSchema) || errors.Is(err, errBackupFailed) {
+				// Keep the file for when the right key or version is
+				// used, or it can be backed up before a migration
+				state.store = nil
+				store.Close()
+			}
+		} else {
+			if state.migrated {
+				state.saveLocked()
+			}
+			if state.currentSessionID != "" {
+				logger.Info("Loaded saved session", "session", state.currentSessionID)
+			}
 		}
 	}
 
@@ -55,13 +110,7 @@ func (s *AppState) SetCurrentSession(sessionID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.currentSessionID = sessionID
-
-	// Persist to file
-	if s.stateFile != "" {
-		if err := SaveSessionState(s.stateFile, sessionID); err != nil {
-			log.Printf("[ERROR] Failed to save session state: %v", err)
-		}
-	}
+	s.saveLocked()
 }
 
 func (s *AppState) GetCurrentSession() string {
@@ -82,21 +131,45 @@ func (s *AppState) GetCurrentAgent() string {
 	return s.currentAgent
 }
 
+// SetSessionStatus records what a session is doing. Busy and error
+// statuses are persisted with the time they were set, so a restart does
+// not make a generating session look idle.
 func (s *AppState) SetSessionStatus(sessionID string, status SessionStatus) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.sessionStatus[sessionID] = status
+	previous, exists := s.sessionStatus[sessionID]
+	if exists && previous.status == status {
+		return
+	}
+	s.sessionStatus[sessionID] = statusEntry{status: status, since: time.Now()}
+	if status != SessionIdle || (exists && previous.status != SessionIdle) {
+		s.saveLocked()
+	}
 }
 
 func (s *AppState) GetSessionStatus(sessionID string) SessionStatus {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	if status, exists := s.sessionStatus[sessionID]; exists {
-		return status
+	if entry, exists := s.sessionStatus[sessionID]; exists {
+		return entry.status
 	}
 	return SessionIdle
 }
 
+// SessionsWithStatus returns the sessions in a (non-idle) status, with the
+// time each entered it
+func (s *AppState) SessionsWithStatus(status SessionStatus) map[string]time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sessions := make(map[string]time.Time)
+	for sessionID, entry := range s.sessionStatus {
+		if entry.status == status {
+			sessions[sessionID] = entry.since
+		}
+	}
+	return sessions
+}
+
 func (s *AppState) SetCurrentModel(model string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -109,11 +182,36 @@ func (s *AppState) GetCurrentModel() string {
 	return s.currentModel
 }
 
+// SetChatModel sets the "provider/model" prompts of a chat are answered
+// with (empty: the default model)
+func (s *AppState) SetChatModel(chatID string, model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if model == "" {
+		delete(s.chatModelMap, chatID)
+	} else {
+		s.chatModelMap[chatID] = model
+	}
+	s.saveLocked()
+}
+
+// GetModelForChat returns the model to use for a given chat ID
+// Returns per-chat model if set, otherwise returns currentModel
+func (s *AppState) GetModelForChat(chatID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if model := s.chatModelMap[chatID]; model != "" {
+		return model
+	}
+	return s.currentModel
+}
+
 // SetChatAgent assigns an agent to a specific chat
 func (s *AppState) SetChatAgent(chatID string, agent string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.chatAgentMap[chatID] = agent
+	s.saveLocked()
 }
 
 // GetChatAgent gets the agent assigned to a specific chat (empty if none)
@@ -128,6 +226,7 @@ func (s *AppState) RemoveChatAgent(chatID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.chatAgentMap, chatID)
+	s.saveLocked()
 }
 
 // ListChatAgents returns all per-chat agent assignments
@@ -152,59 +251,395 @@ func (s *AppState) GetAgentForChat(chatID string) string {
 	return s.currentAgent
 }
 
-// LoadSessionState loads the saved session ID from a file.
-// Returns empty string if file doesn't exist (first run).
-func LoadSessionState(filePath string) (string, error) {
-	// Expand ~ to home directory
-	expanded, err := expandHome(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to expand path: %w", err)
+// SetChatLanguage sets the language used for bot messages in a specific chat
+func (s *AppState) SetChatLanguage(chatID string, lang string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatLanguageMap[chatID] = lang
+	s.saveLocked()
+}
+
+// GetChatLanguage gets the language selected for a specific chat (empty if none)
+func (s *AppState) GetChatLanguage(chatID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chatLanguageMap[chatID]
+}
+
+// SetDefaultLanguage sets the language used by chats without a selection
+func (s *AppState) SetDefaultLanguage(lang string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultLanguage = lang
+}
+
+// GetLanguageForChat returns the language to use for a given chat ID
+// Returns per-chat language if set, otherwise returns defaultLanguage
+func (s *AppState) GetLanguageForChat(chatID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if lang := s.chatLanguageMap[chatID]; lang != "" {
+		return lang
 	}
+	return s.defaultLanguage
+}
 
-	// Read the file
-	data, err := os.ReadFile(expanded)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// File doesn't exist - first run
-			return "", nil
+// SetDefaultPhotoPrompt sets the instruction sent with caption-less photos
+// in chats without their own (empty sends the photo alone)
+func (s *AppState) SetDefaultPhotoPrompt(prompt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.photoPrompt = prompt
+}
+
+// SetChatPhotoPrompt overrides the photo prompt for a chat; an empty prompt
+// turns it off there
+func (s *AppState) SetChatPhotoPrompt(chatID string, prompt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.photoPromptMap[chatID] = prompt
+	s.saveLocked()
+}
+
+// ResetChatPhotoPrompt makes a chat use the default photo prompt again
+func (s *AppState) ResetChatPhotoPrompt(chatID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.photoPromptMap, chatID)
+	s.saveLocked()
+}
+
+// GetPhotoPromptForChat returns the photo prompt to use for a given chat ID
+// Returns the chat's override if set (even if empty), otherwise the default
+func (s *AppState) GetPhotoPromptForChat(chatID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if prompt, ok := s.photoPromptMap[chatID]; ok {
+		return prompt
+	}
+	return s.photoPrompt
+}
+
+// SetChatDebounce sets how long a chat's messages are collected into one
+// prompt, overriding the bridge's default window
+func (s *AppState) SetChatDebounce(chatID string, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatDebounceMap[chatID] = window
+	s.saveLocked()
+}
+
+// ResetChatDebounce makes a chat use the default debounce window again
+func (s *AppState) ResetChatDebounce(chatID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.chatDebounceMap[chatID]; !ok {
+		return
+	}
+	delete(s.chatDebounceMap, chatID)
+	s.saveLocked()
+}
+
+// GetChatDebounce returns a chat's own debounce window, if it has one
+func (s *AppState) GetChatDebounce(chatID string) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	window, ok := s.chatDebounceMap[chatID]
+	return window, ok
+}
+
+// SetSessionForChat sets the current session of a chat. An empty sessionID
+// leaves the chat without a session (it does not fall back to the default).
+func (s *AppState) SetSessionForChat(chatID string, sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatSessionMap[chatID] = sessionID
+	s.saveLocked()
+}
+
+// GetSessionForChat returns the current session of a chat
+// Returns the chat's own session if set, otherwise the default (current) session
+func (s *AppState) GetSessionForChat(chatID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sessionID, ok := s.chatSessionMap[chatID]; ok {
+		return sessionID
+	}
+	return s.currentSessionID
+}
+
+// userSessionKey identifies a user within a chat
+func userSessionKey(chatID string, userID int64) string {
+	return fmt.Sprintf("%s:%d", chatID, userID)
+}
+
+// SetUserSession sets the current session of one user in a chat (per-user group sessions)
+func (s *AppState) SetUserSession(chatID string, userID int64, sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sessionID == "" {
+		delete(s.userSessionMap, userSessionKey(chatID, userID))
+	} else {
+		s.userSessionMap[userSessionKey(chatID, userID)] = sessionID
+	}
+	s.saveLocked()
+}
+
+// GetUserSession gets the current session of one user in a chat (empty if none)
+func (s *AppState) GetUserSession(chatID string, userID int64) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.userSessionMap[userSessionKey(chatID, userID)]
+}
+
+// TracksSession reports whether sessionID is the current session, some
+// chat's or user's session, or a session whose status this state has recorded
+func (s *AppState) TracksSession(sessionID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sessionID == s.currentSessionID {
+		return true
+	}
+	if _, ok := s.sessionStatus[sessionID]; ok {
+		return true
+	}
+	for _, id := range s.chatSessionMap {
+		if id == sessionID {
+			return true
+		}
+	}
+	for _, id := range s.userSessionMap {
+		if id == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// ForgetSession drops a session deleted in OpenCode: it stops being the
+// current session, any chat's or user's session, a favorite and an alias
+// target, and its status and messages are forgotten. Reports whether it was in use.
+func (s *AppState) ForgetSession(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inUse := false
+	_, hadStatus := s.sessionStatus[sessionID]
+	delete(s.sessionStatus, sessionID)
+	for chatID, id := range s.chatSessionMap {
+		if id == sessionID {
+			s.chatSessionMap[chatID] = ""
+			inUse = true
+		}
+	}
+	for key, id := range s.userSessionMap {
+		if id == sessionID {
+			delete(s.userSessionMap, key)
+			inUse = true
+		}
+	}
+	if sessionID != "" && s.currentSessionID == sessionID {
+		s.currentSessionID = ""
+		inUse = true
+	}
+	forgotten := false
+	for key, ref := range s.messageRefs {
+		if ref.SessionID == sessionID {
+			delete(s.messageRefs, key)
+			forgotten = true
+		}
+	}
+	for chatID, favorites := range s.chatFavoriteMap {
+		for i, id := range favorites {
+			if id == sessionID {
+				s.chatFavoriteMap[chatID] = append(favorites[:i:i], favorites[i+1:]...)
+				forgotten = true
+				break
+			}
+		}
+	}
+	for chatID, aliases := range s.sessionAliasMap {
+		for name, id := range aliases {
+			if id == sessionID {
+				delete(aliases, name)
+				forgotten = true
+			}
+		}
+		if len(aliases) == 0 {
+			delete(s.sessionAliasMap, chatID)
 		}
-		return "", fmt.Errorf("failed to read state file: %w", err)
 	}
+	if inUse || hadStatus || forgotten {
+		s.saveLocked()
+	}
+	return inUse
+}
+
+// SetAlias defines a command alias for a chat (name without the leading "/")
+func (s *AppState) SetAlias(chatID string, name string, expansion string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chatAliasMap[chatID] == nil {
+		s.chatAliasMap[chatID] = make(map[string]string)
+	}
+	s.chatAliasMap[chatID][name] = expansion
+	s.saveLocked()
+}
 
-	// Return trimmed session ID
-	sessionID := strings.TrimSpace(string(data))
-	return sessionID, nil
+// GetAlias gets the expansion of a chat's command alias
+func (s *AppState) GetAlias(chatID string, name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	expansion, ok := s.chatAliasMap[chatID][name]
+	return expansion, ok
 }
 
-// SaveSessionState saves the session ID to a file atomically.
-// Uses write-to-temp-file + rename pattern to prevent corruption.
-func SaveSessionState(filePath string, sessionID string) error {
-	// Expand ~ to home directory
-	expanded, err := expandHome(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to expand path: %w", err)
+// RemoveAlias deletes a chat's command alias, reporting whether it existed
+func (s *AppState) RemoveAlias(chatID string, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.chatAliasMap[chatID][name]; !ok {
+		return false
+	}
+	delete(s.chatAliasMap[chatID], name)
+	if len(s.chatAliasMap[chatID]) == 0 {
+		delete(s.chatAliasMap, chatID)
+	}
+	s.saveLocked()
+	return true
+}
+
+// ListAliases returns all command aliases of a chat
+func (s *AppState) ListAliases(chatID string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(map[string]string)
+	for k, v := range s.chatAliasMap[chatID] {
+		result[k] = v
+	}
+	return result
+}
+
+// AddFavorite marks a session as a favorite of a chat; false if it already was
+func (s *AppState) AddFavorite(chatID string, sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range s.chatFavoriteMap[chatID] {
+		if id == sessionID {
+			return false
+		}
+	}
+	s.chatFavoriteMap[chatID] = append(s.chatFavoriteMap[chatID], sessionID)
+	s.saveLocked()
+	return true
+}
+
+// RemoveFavorite unmarks a favorite session of a chat; false if it was not one
+func (s *AppState) RemoveFavorite(chatID string, sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	favorites := s.chatFavoriteMap[chatID]
+	for i, id := range favorites {
+		if id == sessionID {
+			s.chatFavoriteMap[chatID] = append(favorites[:i:i], favorites[i+1:]...)
+			if len(s.chatFavoriteMap[chatID]) == 0 {
+				delete(s.chatFavoriteMap, chatID)
+			}
+			s.saveLocked()
+			return true
+		}
 	}
+	return false
+}
 
-	// Ensure directory exists
-	dir := filepath.Dir(expanded)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// GetFavorites returns the favorite sessions of a chat in the order they
+// were added
+func (s *AppState) GetFavorites(chatID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.chatFavoriteMap[chatID]...)
+}
+
+// SetSessionAlias names a session for a chat, replacing any session the name
+// pointed to
+func (s *AppState) SetSessionAlias(chatID string, name string, sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionAliasMap[chatID] == nil {
+		s.sessionAliasMap[chatID] = make(map[string]string)
 	}
+	s.sessionAliasMap[chatID][name] = sessionID
+	s.saveLocked()
+}
 
-	// Write to temp file first
-	tempFile := expanded + ".tmp"
-	sessionBytes := []byte(sessionID)
+// GetSessionAlias returns the session a chat's alias names
+func (s *AppState) GetSessionAlias(chatID string, name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sessionID, ok := s.sessionAliasMap[chatID][name]
+	return sessionID, ok
+}
 
-	if err := os.WriteFile(tempFile, sessionBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
+// RemoveSessionAlias deletes a chat's session alias, reporting whether it
+// existed
+func (s *AppState) RemoveSessionAlias(chatID string, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessionAliasMap[chatID][name]; !ok {
+		return false
 	}
+	delete(s.sessionAliasMap[chatID], name)
+	if len(s.sessionAliasMap[chatID]) == 0 {
+		delete(s.sessionAliasMap, chatID)
+	}
+	s.saveLocked()
+	return true
+}
 
-	// Atomically rename temp file to target file
-	if err := os.Rename(tempFile, expanded); err != nil {
-		// Clean up temp file if rename failed
-		os.Remove(tempFile)
-		return fmt.Errorf("failed to rename temp file: %w", err)
+// ListSessionAliases returns all session aliases of a chat (alias -> session ID)
+func (s *AppState) ListSessionAliases(chatID string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(map[string]string)
+	for k, v := range s.sessionAliasMap[chatID] {
+		result[k] = v
 	}
+	return result
+}
 
+// SetPending keeps a prompt waiting for an answer in Telegram (e.g. a
+// permission or question keyboard) under its callback key, so callbacks
+// still resolve after a restart
+func (s *AppState) SetPending(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode pending prompt: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingMap[key] = data
+	s.saveLocked()
 	return nil
 }
+
+// RemovePending forgets a prompt once it is answered
+func (s *AppState) RemovePending(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pendingMap[key]; !ok {
+		return
+	}
+	delete(s.pendingMap, key)
+	s.saveLocked()
+}
+
+// ListPending returns the pending prompts by callback key
+func (s *AppState) ListPending() map[string]json.RawMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(map[string]json.RawMessage, len(s.pendingMap))
+	for k, v := range s.pendingMap {
+		result[k] = v
+	}
+	return result
+}
@@ -1,6 +1,10 @@
 package state
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -44,6 +48,385 @@ func TestSetCurrentAgent(t *testing.T) {
 	}
 }
 
+func TestLanguageForChat(t *testing.T) {
+	state := NewAppStateForTest()
+
+	if got := state.GetLanguageForChat("123"); got != "en" {
+		t.Errorf("GetLanguageForChat() = %q, want default %q", got, "en")
+	}
+
+	state.SetDefaultLanguage("zh")
+	state.SetChatLanguage("456", "en")
+
+	if got := state.GetLanguageForChat("123"); got != "zh" {
+		t.Errorf("GetLanguageForChat(123) = %q, want %q", got, "zh")
+	}
+	if got := state.GetLanguageForChat("456"); got != "en" {
+		t.Errorf("GetLanguageForChat(456) = %q, want %q", got, "en")
+	}
+}
+
+func TestUserSessions(t *testing.T) {
+	s := NewAppStateForTest()
+	s.SetCurrentSession("ses_shared")
+
+	s.SetUserSession("-100", 1, "ses_alice")
+	s.SetUserSession("-100", 2, "ses_bob")
+
+	if got := s.GetUserSession("-100", 1); got != "ses_alice" {
+		t.Errorf("expected ses_alice, got %s", got)
+	}
+	if got := s.GetUserSession("-100", 2); got != "ses_bob" {
+		t.Errorf("expected ses_bob, got %s", got)
+	}
+	if got := s.GetUserSession("-200", 1); got != "" {
+		t.Errorf("expected no session in another chat, got %s", got)
+	}
+	if got := s.GetCurrentSession(); got != "ses_shared" {
+		t.Errorf("user sessions must not change the shared session, got %s", got)
+	}
+
+	s.SetUserSession("-100", 1, "")
+	if got := s.GetUserSession("-100", 1); got != "" {
+		t.Errorf("expected cleared session, got %s", got)
+	}
+}
+
+func TestUserSessionsPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetUserSession("-100", 1, "ses_alice")
+	s.SetUserSession("-100", 2, "ses_bob")
+	s.SetUserSession("-100", 2, "")
+
+	restored := NewAppState(stateFile)
+	if got := restored.GetUserSession("-100", 1); got != "ses_alice" {
+		t.Errorf("expected ses_alice restored after a restart, got %s", got)
+	}
+	if got := restored.GetUserSession("-100", 2); got != "" {
+		t.Errorf("expected the cleared session to stay cleared, got %s", got)
+	}
+}
+
+func TestChatSessions(t *testing.T) {
+	s := NewAppStateForTest()
+	s.SetCurrentSession("ses_default")
+
+	s.SetSessionForChat("-100", "ses_a")
+	s.SetSessionForChat("-200", "ses_b")
+
+	if got := s.GetSessionForChat("-100"); got != "ses_a" {
+		t.Errorf("expected ses_a, got %s", got)
+	}
+	if got := s.GetSessionForChat("-200"); got != "ses_b" {
+		t.Errorf("expected ses_b, got %s", got)
+	}
+	if got := s.GetSessionForChat("-300"); got != "ses_default" {
+		t.Errorf("expected a chat without a session to use the default, got %s", got)
+	}
+	if !s.TracksSession("ses_b") {
+		t.Error("expected a chat's session to be tracked")
+	}
+
+	if !s.ForgetSession("ses_a") {
+		t.Error("expected the deleted session to be reported in use")
+	}
+	if got := s.GetSessionForChat("-100"); got != "" {
+		t.Errorf("expected the chat's session cleared, got %s", got)
+	}
+}
+
+func TestChatSessionPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetSessionForChat("-100", "ses_a")
+
+	if got := NewAppState(stateFile).GetSessionForChat("-100"); got != "ses_a" {
+		t.Errorf("expected ses_a restored after a restart, got %s", got)
+	}
+}
+
+func TestSessionStatusPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetSessionStatus("ses_busy", SessionBusy)
+	s.SetSessionStatus("ses_failed", SessionError)
+	s.SetSessionStatus("ses_idle", SessionBusy)
+	s.SetSessionStatus("ses_idle", SessionIdle)
+
+	restored := NewAppState(stateFile)
+	if got := restored.GetSessionStatus("ses_busy"); got != SessionBusy {
+		t.Errorf("expected ses_busy restored busy, got %v", got)
+	}
+	if got := restored.GetSessionStatus("ses_failed"); got != SessionError {
+		t.Errorf("expected ses_failed restored as error, got %v", got)
+	}
+	if got := restored.GetSessionStatus("ses_idle"); got != SessionIdle {
+		t.Errorf("expected ses_idle restored idle, got %v", got)
+	}
+	busy := restored.SessionsWithStatus(SessionBusy)
+	if since, ok := busy["ses_busy"]; !ok || time.Since(since) > time.Minute || len(busy) != 1 {
+		t.Errorf("expected only ses_busy busy with its timestamp, got %v", busy)
+	}
+}
+
+func TestLegacyStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(stateFile, []byte("ses_legacy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := NewAppState(stateFile).GetCurrentSession(); got != "ses_legacy" {
+		t.Errorf("expected the bare session ID of older versions, got %q", got)
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckWritable(filepath.Join(dir, "nested", "state")); err != nil {
+		t.Errorf("expected a new directory to be writable, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "nested"))
+	if len(entries) != 0 {
+		t.Errorf("expected no file left behind, got %v", entries)
+	}
+
+	readOnly := filepath.Join(dir, "ro")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+	if os.Getuid() != 0 {
+		if err := CheckWritable(filepath.Join(readOnly, "state")); err == nil {
+			t.Error("expected a read-only directory to be reported")
+		}
+	}
+}
+
+func TestChatModels(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetCurrentModel("anthropic/claude-sonnet-4")
+	s.SetChatModel("-100", "openai/gpt-4.1")
+
+	if got := s.GetModelForChat("-100"); got != "openai/gpt-4.1" {
+		t.Errorf("expected the chat's model, got %s", got)
+	}
+	if got := s.GetModelForChat("-200"); got != "anthropic/claude-sonnet-4" {
+		t.Errorf("expected the default model, got %s", got)
+	}
+	if got := NewAppState(stateFile).GetModelForChat("-100"); got != "openai/gpt-4.1" {
+		t.Errorf("expected the chat's model restored after a restart, got %s", got)
+	}
+
+	s.SetChatModel("-100", "")
+	if got := s.GetModelForChat("-100"); got != "anthropic/claude-sonnet-4" {
+		t.Errorf("expected a cleared chat to use the default model, got %s", got)
+	}
+}
+
+func TestChatAgentsPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetChatAgent("-100", "oracle")
+	s.SetChatAgent("-200", "librarian")
+	s.RemoveChatAgent("-200")
+
+	restored := NewAppState(stateFile)
+	if got := restored.GetChatAgent("-100"); got != "oracle" {
+		t.Errorf("expected oracle restored after a restart, got %q", got)
+	}
+	if got := restored.GetChatAgent("-200"); got != "" {
+		t.Errorf("expected the removed assignment to stay removed, got %q", got)
+	}
+}
+
+func TestChatLanguagesPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetDefaultLanguage("en")
+	s.SetChatLanguage("-100", "zh")
+
+	restored := NewAppState(stateFile)
+	if got := restored.GetChatLanguage("-100"); got != "zh" {
+		t.Errorf("expected zh restored after a restart, got %q", got)
+	}
+	if got := restored.GetChatLanguage("-200"); got != "" {
+		t.Errorf("expected no language for another chat, got %q", got)
+	}
+}
+
+func TestChatAliasesPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetAlias("-100", "b", "/switch build")
+	s.SetAlias("-100", "s", "/status")
+	s.RemoveAlias("-100", "s")
+
+	restored := NewAppState(stateFile)
+	if got, ok := restored.GetAlias("-100", "b"); !ok || got != "/switch build" {
+		t.Errorf("expected /b restored after a restart, got %q", got)
+	}
+	if _, ok := restored.GetAlias("-100", "s"); ok {
+		t.Error("expected the removed alias to stay removed")
+	}
+}
+
+func TestChatPhotoPromptsPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetChatPhotoPrompt("-100", "Describe the bug")
+	s.SetChatPhotoPrompt("-200", "")
+	s.SetChatPhotoPrompt("-300", "Transcribe")
+	s.ResetChatPhotoPrompt("-300")
+
+	restored := NewAppState(stateFile)
+	restored.SetDefaultPhotoPrompt("Default")
+	if got := restored.GetPhotoPromptForChat("-100"); got != "Describe the bug" {
+		t.Errorf("expected the chat's prompt restored after a restart, got %q", got)
+	}
+	if got := restored.GetPhotoPromptForChat("-200"); got != "" {
+		t.Errorf("expected the prompt to stay off, got %q", got)
+	}
+	if got := restored.GetPhotoPromptForChat("-300"); got != "Default" {
+		t.Errorf("expected the reset chat to use the default, got %q", got)
+	}
+}
+
+func TestPendingPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	if err := s.SetPending("p:1:", map[string]string{"id": "perm_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPending("q:2:0", map[string]string{"id": "que_1"}); err != nil {
+		t.Fatal(err)
+	}
+	s.RemovePending("q:2:0")
+
+	pending := NewAppState(stateFile).ListPending()
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending prompt restored, got %d", len(pending))
+	}
+	if got := string(pending["p:1:"]); got != `{"id":"perm_1"}` {
+		t.Errorf("unexpected restored prompt %s", got)
+	}
+}
+
+func TestChatDebouncePersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetChatDebounce("-100", 4*time.Second)
+	s.SetChatDebounce("-200", 0)
+	s.SetChatDebounce("-300", time.Second)
+	s.ResetChatDebounce("-300")
+
+	restored := NewAppState(stateFile)
+	if window, ok := restored.GetChatDebounce("-100"); !ok || window != 4*time.Second {
+		t.Errorf("expected 4s restored, got %s (set=%v)", window, ok)
+	}
+	if window, ok := restored.GetChatDebounce("-200"); !ok || window != 0 {
+		t.Errorf("expected a zero window to be kept, got %s (set=%v)", window, ok)
+	}
+	if _, ok := restored.GetChatDebounce("-300"); ok {
+		t.Error("expected the reset chat to use the default")
+	}
+}
+
+func TestFavorites(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	if !s.AddFavorite("-100", "ses_a") || !s.AddFavorite("-100", "ses_b") {
+		t.Fatal("expected new favorites to be added")
+	}
+	if s.AddFavorite("-100", "ses_a") {
+		t.Error("expected a duplicate favorite to be refused")
+	}
+	if !s.RemoveFavorite("-100", "ses_a") || s.RemoveFavorite("-100", "ses_a") {
+		t.Error("expected ses_a to be removed once")
+	}
+	s.AddFavorite("-200", "ses_c")
+
+	restored := NewAppState(stateFile)
+	if got := restored.GetFavorites("-100"); len(got) != 1 || got[0] != "ses_b" {
+		t.Errorf("expected [ses_b] restored, got %v", got)
+	}
+	restored.ForgetSession("ses_c")
+	if got := restored.GetFavorites("-200"); len(got) != 0 {
+		t.Errorf("expected a forgotten session to leave the favorites, got %v", got)
+	}
+}
+
+func TestSessionAliases(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.SetSessionAlias("-100", "mybot", "ses_a")
+	s.SetSessionAlias("-100", "old", "ses_b")
+	s.SetSessionAlias("-200", "mybot", "ses_c")
+	if !s.RemoveSessionAlias("-100", "old") || s.RemoveSessionAlias("-100", "old") {
+		t.Error("expected the alias to be removed once")
+	}
+
+	restored := NewAppState(stateFile)
+	if id, ok := restored.GetSessionAlias("-100", "mybot"); !ok || id != "ses_a" {
+		t.Errorf("expected mybot -> ses_a restored, got %q (%v)", id, ok)
+	}
+	if id, _ := restored.GetSessionAlias("-200", "mybot"); id != "ses_c" {
+		t.Errorf("expected aliases to be per chat, got %q", id)
+	}
+	restored.ForgetSession("ses_a")
+	if _, ok := restored.GetSessionAlias("-100", "mybot"); ok {
+		t.Error("expected an alias of a forgotten session to be dropped")
+	}
+}
+
+func TestForgetSession(t *testing.T) {
+	s := NewAppStateForTest()
+	s.SetCurrentSession("ses_gone")
+	s.SetUserSession("-100", 1, "ses_gone")
+	s.SetUserSession("-100", 2, "ses_kept")
+	s.SetSessionStatus("ses_gone", SessionBusy)
+
+	if !s.ForgetSession("ses_gone") {
+		t.Error("expected the deleted session to be reported in use")
+	}
+	if got := s.GetCurrentSession(); got != "" {
+		t.Errorf("expected no current session, got %s", got)
+	}
+	if got := s.GetUserSession("-100", 1); got != "" {
+		t.Errorf("expected the user's session cleared, got %s", got)
+	}
+	if got := s.GetUserSession("-100", 2); got != "ses_kept" {
+		t.Errorf("expected other sessions kept, got %s", got)
+	}
+	if s.TracksSession("ses_gone") {
+		t.Error("expected the deleted session to be untracked")
+	}
+	if s.ForgetSession("ses_unused") {
+		t.Error("expected an unused session not to be reported in use")
+	}
+}
+
+func TestAliases(t *testing.T) {
+	s := NewAppStateForTest()
+
+	s.SetAlias("1", "b", "/switch build; continue")
+	if got, ok := s.GetAlias("1", "b"); !ok || got != "/switch build; continue" {
+		t.Errorf("expected alias b, got %q (ok=%v)", got, ok)
+	}
+	if _, ok := s.GetAlias("2", "b"); ok {
+		t.Error("aliases should be scoped to their chat")
+	}
+	if got := len(s.ListAliases("1")); got != 1 {
+		t.Errorf("expected 1 alias, got %d", got)
+	}
+
+	if !s.RemoveAlias("1", "b") {
+		t.Error("expected RemoveAlias to report an existing alias")
+	}
+	if s.RemoveAlias("1", "b") {
+		t.Error("expected RemoveAlias to report a missing alias")
+	}
+}
+
 // TestSessionStatus tests setting and getting session status
 func TestSessionStatus(t *testing.T) {
 	state := NewAppStateForTest()
@@ -206,7 +589,101 @@ func TestRegistryCleanup(t *testing.T) {
 	}
 }
 
+// TestRegistryStoreExpires tests stored values share the registry TTL
+func TestRegistryStoreExpires(t *testing.T) {
+	registry := NewIDRegistry()
+
+	pages := []string{"page 1", "page 2"}
+	shortKey := registry.Store("more", pages)
+	if shortKey != "more:1" {
+		t.Errorf("Expected short key more:1, got %s", shortKey)
+	}
+
+	value, found := registry.Load(shortKey)
+	if !found || len(value.([]string)) != 2 {
+		t.Fatalf("Stored value should be found, got %v (found=%v)", value, found)
+	}
+
+	registry.setTTL(shortKey, time.Now().Add(-1*time.Hour))
+	if _, found := registry.Load(shortKey); found {
+		t.Error("Expired value should not be found before cleanup")
+	}
+
+	registry.cleanup()
+
+	if _, found := registry.Load(shortKey); found {
+		t.Error("Expired value should be removed by cleanup")
+	}
+}
+
 // TestRegistryConcurrentAccess tests that registry is goroutine-safe
+func TestRegistryRestore(t *testing.T) {
+	registry := NewIDRegistry()
+	registry.Restore("p:7:", "perm_old")
+
+	if fullID, ok := registry.Lookup("p:7:"); !ok || fullID != "perm_old" {
+		t.Errorf("expected restored key to map to perm_old, got %q (found=%v)", fullID, ok)
+	}
+	if shortKey := registry.Register("perm_new", "p", ""); shortKey != "p:8:" {
+		t.Errorf("expected counter to continue after restored key, got %q", shortKey)
+	}
+}
+
+func TestRegistryPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	registry := NewIDRegistry()
+	registry.SetStore(NewAppState(stateFile))
+	questionKey := registry.Register("que_1", "q", "0")
+	pagesKey := registry.Store("more", []string{"page 1", "page 2"})
+	expiredKey := registry.Register("perm_old", "p", "")
+	registry.setTTL(expiredKey, time.Now().Add(-time.Minute))
+	registry.Register("perm_new", "p", "") // saves the TTL change
+	registry.Flush()
+
+	restored := NewIDRegistry()
+	restored.SetStore(NewAppState(stateFile))
+	if fullID, ok := restored.Lookup(questionKey); !ok || fullID != "que_1" {
+		t.Errorf("expected %s to map to que_1 after a restart, got %q (found=%v)", questionKey, fullID, ok)
+	}
+	if shortKey := restored.Register("que_1", "q", "0"); shortKey != questionKey {
+		t.Errorf("expected the restored key to be reused, got %q", shortKey)
+	}
+	value, ok := restored.Load(pagesKey)
+	if !ok {
+		t.Fatalf("expected %s to be restored", pagesKey)
+	}
+	var pages []string
+	if err := json.Unmarshal(value.(json.RawMessage), &pages); err != nil || len(pages) != 2 {
+		t.Errorf("unexpected restored pages %s", value)
+	}
+	if _, ok := restored.Lookup(expiredKey); ok {
+		t.Error("expected an expired key to be dropped")
+	}
+	if shortKey := restored.Register("perm_next", "p", ""); shortKey != "p:5:" {
+		t.Errorf("expected the counter to resume after a restart, got %q", shortKey)
+	}
+}
+
+func TestRegistrySavesBatched(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	registry := NewIDRegistry()
+	registry.SetStore(NewAppState(stateFile))
+	for i := 0; i < 10; i++ {
+		registry.Register(fmt.Sprintf("que_%d", i), "q", "0")
+	}
+
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Fatalf("expected no write before the batch is saved, got %v", err)
+	}
+	registry.Flush()
+
+	restored := NewIDRegistry()
+	restored.SetStore(NewAppState(stateFile))
+	if fullID, ok := restored.Lookup("q:10:0"); !ok || fullID != "que_9" {
+		t.Errorf("expected the whole batch to be saved, got %q (found=%v)", fullID, ok)
+	}
+}
+
 func TestRegistryConcurrentAccess(t *testing.T) {
 	registry := NewIDRegistry()
 	var wg sync.WaitGroup
@@ -243,3 +720,30 @@ func TestRegistryDeduplication(t *testing.T) {
 		t.Errorf("Same fullID should return same shortKey: %q vs %q", first, second)
 	}
 }
+
+func TestPhotoPromptForChat(t *testing.T) {
+	s := NewAppStateForTest()
+
+	if got := s.GetPhotoPromptForChat("1"); got != "" {
+		t.Errorf("expected no photo prompt by default, got %q", got)
+	}
+
+	s.SetDefaultPhotoPrompt("Describe this")
+	s.SetChatPhotoPrompt("2", "Find the bug")
+	s.SetChatPhotoPrompt("3", "")
+
+	if got := s.GetPhotoPromptForChat("1"); got != "Describe this" {
+		t.Errorf("expected default prompt, got %q", got)
+	}
+	if got := s.GetPhotoPromptForChat("2"); got != "Find the bug" {
+		t.Errorf("expected chat prompt, got %q", got)
+	}
+	if got := s.GetPhotoPromptForChat("3"); got != "" {
+		t.Errorf("expected prompt turned off, got %q", got)
+	}
+
+	s.ResetChatPhotoPrompt("3")
+	if got := s.GetPhotoPromptForChat("3"); got != "Describe this" {
+		t.Errorf("expected default after reset, got %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package state
+
+import "time"
+
+// ChatStats are a chat's usage counters, kept across restarts
+type ChatStats struct {
+	Prompts   int `json:"prompts"`
+	Responses int `json:"responses"`
+	Errors    int `json:"errors"`
+	Tokens    int `json:"tokens"`
+
+	// Total time from prompt to response, over the responses whose prompt
+	// was timed
+	LatencyTotal time.Duration `json:"latency_total"`
+	Timed        int           `json:"timed"`
+}
+
+// AverageLatency returns the mean time from prompt to response (0: none timed)
+func (c ChatStats) AverageLatency() time.Duration {
+	if c.Timed == 0 {
+		return 0
+	}
+	return c.LatencyTotal / time.Duration(c.Timed)
+}
+
+// RecordPrompt counts a prompt a chat sent to OpenCode
+func (s *AppState) RecordPrompt(chatID string) ChatStats {
+	return s.updateStats(chatID, func(c *ChatStats) {
+		c.Prompts++
+	})
+}
+
+// RecordResponse counts a response delivered to a chat with the tokens it
+// used; latency is the time since its prompt (0: unknown)
+func (s *AppState) RecordResponse(chatID string, tokens int, latency time.Duration) ChatStats {
+	return s.updateStats(chatID, func(c *ChatStats) {
+		c.Responses++
+		c.Tokens += tokens
+		if latency > 0 {
+			c.LatencyTotal += latency
+			c.Timed++
+		}
+	})
+}
+
+// RecordError counts a failed prompt or session error of a chat
+func (s *AppState) RecordError(chatID string) ChatStats {
+	return s.updateStats(chatID, func(c *ChatStats) {
+		c.Errors++
+	})
+}
+
+// GetChatStats returns a chat's usage counters
+func (s *AppState) GetChatStats(chatID string) ChatStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chatStats[chatID]
+}
+
+// updateStats applies update to a chat's counters and returns them
+func (s *AppState) updateStats(chatID string, update func(*ChatStats)) ChatStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.chatStats[chatID]
+	update(&stats)
+	s.chatStats[chatID] = stats
+	s.saveLocked()
+	return stats
+}
@@ -0,0 +1,29 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChatStatsPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	s := NewAppState(stateFile)
+	s.RecordPrompt("-100")
+	s.RecordPrompt("-100")
+	s.RecordResponse("-100", 500, 2*time.Second)
+	s.RecordResponse("-100", 300, 0)
+	s.RecordError("-100")
+	s.RecordPrompt("-200")
+
+	stats := NewAppState(stateFile).GetChatStats("-100")
+	if stats.Prompts != 2 || stats.Responses != 2 || stats.Errors != 1 || stats.Tokens != 800 {
+		t.Errorf("unexpected restored stats %+v", stats)
+	}
+	if got := stats.AverageLatency(); got != 2*time.Second {
+		t.Errorf("expected untimed responses left out of the average, got %s", got)
+	}
+	if got := NewAppState(stateFile).GetChatStats("-200").Prompts; got != 1 {
+		t.Errorf("expected stats kept per chat, got %d prompts", got)
+	}
+}
@@ -0,0 +1,101 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps the encoded state between runs. The state is saved whole, so
+// a store only has to replace it atomically.
+type Store interface {
+	// Load returns the saved state, nil if nothing was saved yet
+	Load() ([]byte, error)
+	// Save replaces the saved state
+	Save(data []byte) error
+	// Backup keeps a copy of state written with an older schema version
+	// before it is migrated
+	Backup(data []byte, version int) error
+	Close() error
+}
+
+// State backends, selected with STATE_BACKEND
+const (
+	BackendFile = "file" // one file rewritten on every save (default)
+	BackendBolt = "bolt" // an embedded bbolt database, pure Go
+)
+
+// CheckBackend reports whether backend names a state backend ("" is the default)
+func CheckBackend(backend string) error {
+	switch backend {
+	case "", BackendFile, BackendBolt:
+		return nil
+	}
+	return fmt.Errorf("unknown state backend %q, use %s or %s", backend, BackendFile, BackendBolt)
+}
+
+// OpenStore opens the state saved at path with the given backend
+func OpenStore(backend, path string) (Store, error) {
+	if err := CheckBackend(backend); err != nil {
+		return nil, err
+	}
+	expanded, err := expandHome(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+	if backend == BackendBolt {
+		store, err := openBoltStore(expanded)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return &fileStore{path: expanded}, nil
+}
+
+// fileStore keeps the state in one file
+type fileStore struct {
+	path string
+}
+
+func (f *fileStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist - first run
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return data, nil
+}
+
+// Save writes the file atomically (write-to-temp-file + rename)
+func (f *fileStore) Save(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tempFile := f.path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tempFile, f.path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
+
+// Backup writes <file>.v<version>.bak next to the file
+func (f *fileStore) Backup(data []byte, version int) error {
+	backup := fmt.Sprintf("%s.v%d.bak", f.path, version)
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return fmt.Errorf("%w: %v", errBackupFailed, err)
+	}
+	return nil
+}
+
+func (f *fileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket holds the state under boltStateKey and its backups under
+// boltStateKey.v<version>.bak, like the file backend's names
+var (
+	boltBucket   = []byte("state")
+	boltStateKey = "state"
+)
+
+// boltStore keeps the state in a bbolt database, for setups that want a
+// database file without CGO. Every save is a transaction, so a crash never
+// leaves a half-written state.
+type boltStore struct {
+	db *bolt.DB
+}
+
+func openBoltStore(path string) (*boltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	// The database is locked while open: fail instead of waiting forever
+	// when another instance uses it
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (b *boltStore) Load() ([]byte, error) {
+	var data []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// Values are only valid during the transaction
+		data = bytes.Clone(tx.Bucket(boltBucket).Get([]byte(boltStateKey)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state database: %w", err)
+	}
+	return data, nil
+}
+
+func (b *boltStore) Save(data []byte) error {
+	if err := b.put(boltStateKey, data); err != nil {
+		return fmt.Errorf("failed to write state database: %w", err)
+	}
+	return nil
+}
+
+func (b *boltStore) Backup(data []byte, version int) error {
+	if err := b.put(fmt.Sprintf("%s.v%d.bak", boltStateKey, version), data); err != nil {
+		return fmt.Errorf("%w: %v", errBackupFailed, err)
+	}
+	return nil
+}
+
+func (b *boltStore) put(key string, data []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(key), data)
+	})
+}
+
+func (b *boltStore) Close() error {
+	return b.db.Close()
+}
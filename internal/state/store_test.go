@@ -0,0 +1,83 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltStorePersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenStore(BackendBolt, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewAppStateWithStore(store)
+	s.SetSessionForChat("-100", "ses_a")
+	s.SetUserSession("-100", 1, "ses_alice")
+
+	// The database stays locked until the state is closed
+	if _, err := OpenStore(BackendBolt, path); err == nil {
+		t.Error("expected a second open of the database to fail")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetSessionForChat("-100", "ses_b") // kept in memory only
+
+	store, err = OpenStore(BackendBolt, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewAppStateWithStore(store)
+	defer restored.Close()
+	if got := restored.GetSessionForChat("-100"); got != "ses_a" {
+		t.Errorf("expected ses_a restored after a restart, got %s", got)
+	}
+	if got := restored.GetUserSession("-100", 1); got != "ses_alice" {
+		t.Errorf("expected ses_alice restored after a restart, got %s", got)
+	}
+}
+
+func TestBoltStoreBacksUpBeforeMigrating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenStore(BackendBolt, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := []byte(`{"version":2,"session":"ses_a"}`)
+	if err := store.Save(original); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	steps, err := PlanStateMigration(BackendBolt, path)
+	if err != nil || len(steps) != SchemaVersion-2 {
+		t.Errorf("expected %d steps from version 2, got %v (%v)", SchemaVersion-2, steps, err)
+	}
+
+	store, err = OpenStore(BackendBolt, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewAppStateWithStore(store)
+	defer s.Close()
+	if got := s.GetCurrentSession(); got != "ses_a" {
+		t.Errorf("expected ses_a after the migration, got %q", got)
+	}
+	var backup string
+	store.(*boltStore).db.View(func(tx *bolt.Tx) error {
+		backup = string(tx.Bucket(boltBucket).Get([]byte("state.v2.bak")))
+		return nil
+	})
+	if backup != string(original) {
+		t.Errorf("expected the original kept in a backup, got %q", backup)
+	}
+}
+
+func TestOpenStoreUnknownBackend(t *testing.T) {
+	if _, err := OpenStore("sqlite", filepath.Join(t.TempDir(), "state")); err == nil {
+		t.Error("expected an unknown backend to be refused")
+	}
+}
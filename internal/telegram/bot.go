@@ -1,19 +1,29 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
-	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
 
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 
+	"github.com/user/opencode-telegram/internal/i18n"
+	"github.com/user/opencode-telegram/internal/logging"
 	"github.com/user/opencode-telegram/internal/metrics"
+	"github.com/user/opencode-telegram/internal/state"
 )
 
+var logger = logging.For("telegram")
+
 // Bot wraps the Telegram bot client
 type Bot struct {
 	bot            *bot.Bot
@@ -23,10 +33,25 @@ type Bot struct {
 	offsetFilePath string
 	maxUpdateID    int64
 	offsetMu       sync.Mutex
+	limiter        *rateLimiter
+	outbox         *state.Outbox
+	outboxMu       sync.Mutex   // serializes sends with outbox replay
+	httpClient     *http.Client // Bot API and file requests (nil: defaults)
+	webhook        atomic.Bool  // set once StartWebhook registered the webhook
+
+	editsMu sync.Mutex
+	edits   map[int]*editQueue // unsent edits by message, see edit
 }
 
 // NewBot creates a new Telegram bot instance with optional initial offset
 func NewBot(token string, chatID int64, initialOffset int64) *Bot {
+	return NewBotWithClient(token, chatID, initialOffset, nil)
+}
+
+// NewBotWithClient creates a bot whose Bot API requests and file downloads
+// go through client, e.g. one with the account's proxy (nil: defaults).
+// Long polling needs the client's timeout to be at least a minute.
+func NewBotWithClient(token string, chatID int64, initialOffset int64, client *http.Client) *Bot {
 	opts := []bot.Option{
 		bot.WithSkipGetMe(),
 		bot.WithInitialOffset(initialOffset),
@@ -37,20 +62,33 @@ func NewBot(token string, chatID int64, initialOffset int64) *Bot {
 		}),
 	}
 
+	if client != nil {
+		opts = append(opts, bot.WithHTTPClient(time.Minute, client))
+	}
+
 	b, err := bot.New(token, opts...)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create bot: %v", err))
 	}
 
+	limiter := newRateLimiter(DefaultSendInterval)
+	limiter.account.Chat = strconv.FormatInt(chatID, 10)
 	return &Bot{
 		bot:         b,
 		chatID:      chatID,
 		token:       token,
 		offset:      initialOffset,
 		maxUpdateID: initialOffset - 1,
+		limiter:     limiter,
+		httpClient:  client,
 	}
 }
 
+// SetAccount labels the bot's metrics with the account it runs for
+func (b *Bot) SetAccount(name string) {
+	b.limiter.account.Name = name
+}
+
 func (b *Bot) Token() string {
 	return b.token
 }
@@ -59,6 +97,12 @@ func (b *Bot) ChatID() int64 {
 	return b.chatID
 }
 
+// SetSendInterval sets the minimum spacing between send/edit requests to the chat.
+// Zero disables pacing; 429 flood waits are still retried.
+func (b *Bot) SetSendInterval(interval time.Duration) {
+	b.limiter.setInterval(interval)
+}
+
 // SetOffset stores the offset file path for later persistence
 func (b *Bot) SetOffset(offsetFilePath string) {
 	b.offsetMu.Lock()
@@ -85,13 +129,17 @@ func (b *Bot) trackUpdateID(update *models.Update) {
 func (b *Bot) SendMessage(ctx context.Context, text string) (int, error) {
 	start := time.Now()
 	defer func() {
-		metrics.ObserveTelegramMessageSend(start)
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
 	}()
 
-	msg, err := b.bot.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:    b.chatID,
-		Text:      text,
-		ParseMode: models.ParseModeHTML,
+	var msg *models.Message
+	err := b.deliver(ctx, state.OutboxEntry{Kind: state.OutboxSend, Text: text, HTML: true}, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    b.chatID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		return err
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to send message: %w", err)
@@ -100,15 +148,95 @@ func (b *Bot) SendMessage(ctx context.Context, text string) (int, error) {
 	return msg.ID, nil
 }
 
+// SendMessageToChat sends an HTML message to another chat than the bot's own,
+// e.g. an operator's admin chat
+func (b *Bot) SendMessageToChat(ctx context.Context, chatID int64, text string) (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
+	}()
+
+	var msg *models.Message
+	err := b.limiter.do(ctx, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send message to chat %d: %w", chatID, err)
+	}
+
+	return msg.ID, nil
+}
+
+// SendMessageSilent sends an HTML message without a push notification
+func (b *Bot) SendMessageSilent(ctx context.Context, text string) (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
+	}()
+
+	var msg *models.Message
+	err := b.limiter.do(ctx, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:              b.chatID,
+			Text:                text,
+			ParseMode:           models.ParseModeHTML,
+			DisableNotification: true,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send silent message: %w", err)
+	}
+
+	return msg.ID, nil
+}
+
+// SendMessageReply sends an HTML message as a reply to another message.
+// The message is still sent if the original was deleted.
+func (b *Bot) SendMessageReply(ctx context.Context, text string, replyTo int) (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
+	}()
+
+	var msg *models.Message
+	err := b.deliver(ctx, state.OutboxEntry{Kind: state.OutboxSend, MessageID: replyTo, Text: text, HTML: true}, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    b.chatID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+			ReplyParameters: &models.ReplyParameters{
+				MessageID:                replyTo,
+				AllowSendingWithoutReply: true,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send reply: %w", err)
+	}
+
+	return msg.ID, nil
+}
+
 func (b *Bot) SendMessagePlain(ctx context.Context, text string) (int, error) {
 	start := time.Now()
 	defer func() {
-		metrics.ObserveTelegramMessageSend(start)
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
 	}()
 
-	msg, err := b.bot.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: b.chatID,
-		Text:   text,
+	var msg *models.Message
+	err := b.deliver(ctx, state.OutboxEntry{Kind: state.OutboxSend, Text: text}, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: b.chatID,
+			Text:   text,
+		})
+		return err
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to send plain message: %w", err)
@@ -118,34 +246,65 @@ func (b *Bot) SendMessagePlain(ctx context.Context, text string) (int, error) {
 }
 
 func (b *Bot) SendMessageWithKeyboard(ctx context.Context, text string, keyboard *models.InlineKeyboardMarkup) (int, error) {
-	log.Printf("[SEND_KEYBOARD] Attempting to send message with keyboard")
-	log.Printf("[SEND_KEYBOARD] ChatID: %d", b.chatID)
-	log.Printf("[SEND_KEYBOARD] Text length: %d", len(text))
 	if keyboard != nil {
-		log.Printf("[SEND_KEYBOARD] Keyboard rows: %d", len(keyboard.InlineKeyboard))
+		logger.Debug("Sending message with keyboard", "chat", b.chatID, "length", len(text), "rows", len(keyboard.InlineKeyboard))
+	} else {
+		logger.Debug("Sending message with keyboard", "chat", b.chatID, "length", len(text))
 	}
 
-	msg, err := b.bot.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:      b.chatID,
-		Text:        text,
-		ReplyMarkup: keyboard,
-		ParseMode:   models.ParseModeHTML,
+	var msg *models.Message
+	err := b.limiter.do(ctx, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      b.chatID,
+			Text:        text,
+			ReplyMarkup: keyboard,
+			ParseMode:   models.ParseModeHTML,
+		})
+		return err
 	})
 	if err != nil {
-		log.Printf("[SEND_KEYBOARD] Error: %v", err)
+		logger.Error("Failed to send message with keyboard", "chat", b.chatID, "class", ErrorClass(err), "error", err)
 		return 0, fmt.Errorf("failed to send message with keyboard: %w", err)
 	}
 
-	log.Printf("[SEND_KEYBOARD] Success! MessageID: %d", msg.ID)
+	logger.Debug("Sent message with keyboard", "chat", b.chatID, "message", msg.ID)
+	return msg.ID, nil
+}
+
+// SendMessageWithReplyMarkup sends a message with an arbitrary reply markup
+// Used for reply keyboards (models.ReplyKeyboardMarkup) and removing them (models.ReplyKeyboardRemove)
+func (b *Bot) SendMessageWithReplyMarkup(ctx context.Context, text string, markup models.ReplyMarkup) (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
+	}()
+
+	var msg *models.Message
+	err := b.limiter.do(ctx, func() (err error) {
+		msg, err = b.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      b.chatID,
+			Text:        text,
+			ReplyMarkup: markup,
+			ParseMode:   models.ParseModeHTML,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send message with reply markup: %w", err)
+	}
+
 	return msg.ID, nil
 }
 
 func (b *Bot) EditMessage(ctx context.Context, messageID int, text string) error {
-	_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
-		ChatID:    b.chatID,
-		MessageID: messageID,
-		Text:      text,
-		ParseMode: models.ParseModeHTML,
+	err := b.edit(ctx, state.OutboxEntry{Kind: state.OutboxEdit, MessageID: messageID, Text: text, HTML: true}, func() error {
+		_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    b.chatID,
+			MessageID: messageID,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to edit message: %w", err)
@@ -155,12 +314,15 @@ func (b *Bot) EditMessage(ctx context.Context, messageID int, text string) error
 }
 
 func (b *Bot) EditMessageWithKeyboard(ctx context.Context, messageID int, text string, keyboard *models.InlineKeyboardMarkup) error {
-	_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
-		ChatID:      b.chatID,
-		MessageID:   messageID,
-		Text:        text,
-		ParseMode:   models.ParseModeHTML,
-		ReplyMarkup: keyboard,
+	err := b.limiter.do(ctx, func() error {
+		_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      b.chatID,
+			MessageID:   messageID,
+			Text:        text,
+			ParseMode:   models.ParseModeHTML,
+			ReplyMarkup: keyboard,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to edit message with keyboard: %w", err)
@@ -170,10 +332,13 @@ func (b *Bot) EditMessageWithKeyboard(ctx context.Context, messageID int, text s
 }
 
 func (b *Bot) EditMessagePlain(ctx context.Context, messageID int, text string) error {
-	_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
-		ChatID:    b.chatID,
-		MessageID: messageID,
-		Text:      text,
+	err := b.edit(ctx, state.OutboxEntry{Kind: state.OutboxEdit, MessageID: messageID, Text: text}, func() error {
+		_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    b.chatID,
+			MessageID: messageID,
+			Text:      text,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to edit plain message: %w", err)
@@ -182,6 +347,83 @@ func (b *Bot) EditMessagePlain(ctx context.Context, messageID int, text string)
 	return nil
 }
 
+// SendPhoto uploads an image to the chat with an optional plain-text caption
+func (b *Bot) SendPhoto(ctx context.Context, data []byte, filename string, caption string) (int, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveTelegramMessageSend(b.limiter.account, start)
+	}()
+
+	var msg *models.Message
+	err := b.limiter.do(ctx, func() (err error) {
+		msg, err = b.bot.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:  b.chatID,
+			Photo:   &models.InputFileUpload{Filename: filename, Data: bytes.NewReader(data)},
+			Caption: caption,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send photo: %w", err)
+	}
+
+	return msg.ID, nil
+}
+
+// SetReaction sets the bot's emoji reaction on a message, replacing any previous one.
+// Only emoji from Telegram's reaction set are accepted.
+func (b *Bot) SetReaction(ctx context.Context, messageID int, emoji string) error {
+	err := b.limiter.do(ctx, func() error {
+		_, err := b.bot.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
+			ChatID:    b.chatID,
+			MessageID: messageID,
+			Reaction: []models.ReactionType{{
+				Type:              models.ReactionTypeTypeEmoji,
+				ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: emoji},
+			}},
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set reaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMessage deletes a message from the chat
+func (b *Bot) DeleteMessage(ctx context.Context, messageID int) error {
+	err := b.limiter.do(ctx, func() error {
+		_, err := b.bot.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    b.chatID,
+			MessageID: messageID,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
+
+// PinMessage pins a message in the chat without notifying members
+func (b *Bot) PinMessage(ctx context.Context, messageID int) error {
+	err := b.limiter.do(ctx, func() error {
+		_, err := b.bot.PinChatMessage(ctx, &bot.PinChatMessageParams{
+			ChatID:              b.chatID,
+			MessageID:           messageID,
+			DisableNotification: true,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+
+	return nil
+}
+
 // SendTyping sends a typing indicator to the chat
 // The indicator expires after 5 seconds, so it should be refreshed every 4 seconds
 func (b *Bot) SendTyping(ctx context.Context) error {
@@ -208,27 +450,73 @@ func (b *Bot) AnswerCallback(ctx context.Context, callbackID string) error {
 	return nil
 }
 
-// SetMyCommands sets the bot's command list for auto-completion
-func (b *Bot) SetMyCommands(ctx context.Context) error {
-	commands := []models.BotCommand{
-		{Command: "help", Description: "顯示所有可用指令"},
-		{Command: "sessions", Description: "列出 sessions（表格檢視）"},
-		{Command: "selectsession", Description: "選擇 session（互動選單）"},
-		{Command: "deletesessions", Description: "刪除 session（互動選單）"},
-		{Command: "status", Description: "顯示目前狀態"},
-		{Command: "model", Description: "選擇 AI 模型"},
-		{Command: "route", Description: "設定 agent 路由"},
-		{Command: "new", Description: "建立新 session"},
-		{Command: "abort", Description: "中止目前請求"},
+// menuCommands are the commands shown in Telegram's auto-completion menu.
+// Descriptions come from the i18n catalog under "cmd.<command>".
+var menuCommands = []string{
+	"help", "sessions", "selectsession", "deletesessions", "status",
+	"model", "route", "new", "fork", "abort", "keyboard", "lang", "alias", "feedback",
+	"server", "init", "stats", "fav",
+}
+
+func buildCommands(lang i18n.Lang) []models.BotCommand {
+	commands := make([]models.BotCommand, 0, len(menuCommands))
+	for _, cmd := range menuCommands {
+		commands = append(commands, models.BotCommand{
+			Command:     cmd,
+			Description: i18n.T(lang, "cmd."+cmd),
+		})
 	}
+	return commands
+}
 
+// SetMyCommands sets the bot's command list for auto-completion.
+// The default list uses defaultLang; each supported language is also registered
+// under its language_code so Telegram clients show a translated menu.
+// The chat's own menu follows chatLang, the language /lang picked for it;
+// without one, a chat-scoped menu left over from /lang is removed.
+func (b *Bot) SetMyCommands(ctx context.Context, defaultLang, chatLang i18n.Lang) error {
 	_, err := b.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
-		Commands: commands,
+		Commands: buildCommands(defaultLang),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to set commands: %w", err)
 	}
 
+	for _, lang := range i18n.Supported() {
+		_, err := b.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands:     buildCommands(lang),
+			LanguageCode: string(lang),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set %s commands: %w", lang, err)
+		}
+	}
+
+	if chatLang != "" {
+		return b.SetChatCommands(ctx, chatLang)
+	}
+	_, err = b.bot.DeleteMyCommands(ctx, &bot.DeleteMyCommandsParams{
+		Scope: &models.BotCommandScopeChat{ChatID: b.chatID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset chat commands: %w", err)
+	}
+
+	return nil
+}
+
+// SetChatCommands sets the command menu of the bot's chat in lang, overriding
+// the language_code menus for everyone in it (used when /lang changes the
+// chat language)
+func (b *Bot) SetChatCommands(ctx context.Context, lang i18n.Lang) error {
+	_, err := b.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+		Commands: buildCommands(lang),
+		Scope:    &models.BotCommandScopeChat{ChatID: b.chatID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set chat commands: %w", err)
+	}
+
 	return nil
 }
 
@@ -265,6 +553,7 @@ func (b *Bot) StartWebhook(ctx context.Context, webhookURL string, port string,
 	if err != nil {
 		return fmt.Errorf("set webhook: %w", err)
 	}
+	b.webhook.Store(true)
 
 	// Start webhook server (go-telegram/bot handles HTTP listener internally)
 	// Note: StartWebhook blocks until context is cancelled
@@ -285,6 +574,14 @@ type TextHandler func(ctx context.Context, text string)
 type CommandHandler func(ctx context.Context, args string)
 type CallbackHandler func(ctx context.Context, callbackID, data string, messageID int)
 
+// recoverPanic keeps a panicking handler from taking the bot down. The
+// stack goes with the error line, and so to the error reporter.
+func (b *Bot) recoverPanic(handler string) {
+	if r := recover(); r != nil {
+		logger.Error(handler+" panicked", "account", b.limiter.account.Name, "chat", b.chatID, "panic", r, "stack", string(debug.Stack()))
+	}
+}
+
 func (b *Bot) RegisterTextHandler(handler TextHandler) {
 	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		isMatch := update.Message != nil &&
@@ -292,18 +589,14 @@ func (b *Bot) RegisterTextHandler(handler TextHandler) {
 			len(update.Message.Text) > 0 &&
 			update.Message.Text[0] != '/'
 		if isMatch {
-			fmt.Printf("[TEXT] Received text message: %q\n", update.Message.Text)
+			logger.Debug("Received text message", "chat", b.chatID, "text", update.Message.Text)
 		}
 		return isMatch
 	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("[PANIC] Handler panicked: %v\n", r)
-			}
-		}()
+		defer b.recoverPanic("Handler")
 
 		b.trackUpdateID(update)
-		handler(ctx, update.Message.Text)
+		handler(updateContext(ctx, update), update.Message.Text)
 	})
 }
 
@@ -312,6 +605,7 @@ func (b *Bot) RegisterCommandHandler(command string, handler CommandHandler) {
 		if update.Message == nil {
 			return
 		}
+		defer b.recoverPanic("Command handler")
 
 		b.trackUpdateID(update)
 
@@ -321,16 +615,51 @@ func (b *Bot) RegisterCommandHandler(command string, handler CommandHandler) {
 			args = text[len(command)+2:]
 		}
 
-		fmt.Printf("[CMD] Executing command: %s, args: %q\n", command, args)
-		handler(ctx, args)
+		logger.Debug("Executing command", "chat", b.chatID, "command", command, "args", args)
+		handler(updateContext(ctx, update), args)
 	})
 }
 
+// DynamicCommandHandler handles a command that is not known at startup
+type DynamicCommandHandler func(ctx context.Context, command string, args string)
+
+// RegisterDynamicCommandHandler handles commands resolved at runtime, such as
+// user-defined aliases. match is consulted with the command name (without "/"
+// or "@botname") of every command message.
+func (b *Bot) RegisterDynamicCommandHandler(match func(command string) bool, handler DynamicCommandHandler) {
+	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		if update.Message == nil {
+			return false
+		}
+		command, _, ok := ParseCommand(update.Message.Text)
+		return ok && match(command)
+	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
+		defer b.recoverPanic("Command handler")
+
+		b.trackUpdateID(update)
+
+		command, args, _ := ParseCommand(update.Message.Text)
+		logger.Debug("Executing dynamic command", "chat", b.chatID, "command", command, "args", args)
+		handler(updateContext(ctx, update), command, args)
+	})
+}
+
+// ParseCommand splits "/name@botname args" into its name and arguments
+func ParseCommand(text string) (command string, args string, ok bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+	command, args, _ = strings.Cut(text[1:], " ")
+	command, _, _ = strings.Cut(command, "@")
+	return command, strings.TrimSpace(args), command != ""
+}
+
 func (b *Bot) RegisterCallbackHandler(prefix string, handler CallbackHandler) {
 	b.bot.RegisterHandler(bot.HandlerTypeCallbackQueryData, prefix, bot.MatchTypePrefix, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
 		if update.CallbackQuery == nil {
 			return
 		}
+		defer b.recoverPanic("Callback handler")
 
 		b.trackUpdateID(update)
 		b.AnswerCallback(ctx, update.CallbackQuery.ID)
@@ -340,55 +669,82 @@ func (b *Bot) RegisterCallbackHandler(prefix string, handler CallbackHandler) {
 			msgID = update.CallbackQuery.Message.Message.ID
 		}
 
-		handler(ctx, update.CallbackQuery.ID, update.CallbackQuery.Data, msgID)
+		handler(updateContext(ctx, update), update.CallbackQuery.ID, update.CallbackQuery.Data, msgID)
 	})
 }
 
-type PhotoHandler func(ctx context.Context, photos []models.PhotoSize, caption string, botToken string)
+// PhotoHandler receives one photo update. mediaGroupID is set when the photo
+// belongs to an album, which Telegram delivers as one update per photo.
+type PhotoHandler func(ctx context.Context, photos []models.PhotoSize, caption string, mediaGroupID string, botToken string)
 
 func (b *Bot) RegisterPhotoHandler(handler PhotoHandler) {
 	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return update.Message != nil && update.Message.Photo != nil && len(update.Message.Photo) > 0
 	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("[PANIC] Photo handler panicked: %v\n", r)
-			}
-		}()
+		defer b.recoverPanic("Photo handler")
 
 		b.trackUpdateID(update)
 		caption := update.Message.Caption
 
-		handler(ctx, update.Message.Photo, caption, b.token)
+		handler(updateContext(ctx, update), update.Message.Photo, caption, update.Message.MediaGroupID, b.token)
 	})
 }
 
-type StickerHandler func(ctx context.Context, emoji string, setName string)
+// StickerHandler receives incoming stickers. frame is non-nil for animated and
+// video stickers (see StickerFrame) so they can be sent to the vision pipeline.
+type StickerHandler func(ctx context.Context, emoji string, setName string, frame *models.PhotoSize, botToken string)
 
 func (b *Bot) RegisterStickerHandler(handler StickerHandler) {
 	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return update.Message != nil && update.Message.Sticker != nil
 	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("[PANIC] Sticker handler panicked: %v\n", r)
-			}
-		}()
+		defer b.recoverPanic("Sticker handler")
 
 		b.trackUpdateID(update)
 		sticker := update.Message.Sticker
 
-		// Reject animated/video stickers
-		if sticker.IsAnimated || sticker.IsVideo {
-			b.SendMessage(ctx, "⚠️ Animated/video stickers are not supported")
-			return
-		}
-
 		// Extract emoji and set name
 		emoji := sticker.Emoji
 		setName := sticker.SetName
+		frame, _ := StickerFrame(sticker)
+
+		handler(updateContext(ctx, update), emoji, setName, frame, b.token)
+	})
+}
+
+type AudioHandler func(ctx context.Context, audio AudioFile, caption string, botToken string)
+
+// RegisterAudioHandler handles voice notes and audio files (see AudioFromMessage).
+// Must be registered before RegisterUnsupportedMediaHandler, which also matches audio.
+func (b *Bot) RegisterAudioHandler(handler AudioHandler) {
+	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		_, ok := AudioFromMessage(update.Message)
+		return ok
+	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
+		defer b.recoverPanic("Audio handler")
+
+		b.trackUpdateID(update)
+		audio, _ := AudioFromMessage(update.Message)
+
+		handler(updateContext(ctx, update), *audio, update.Message.Caption, b.token)
+	})
+}
+
+type VideoHandler func(ctx context.Context, video VideoFile, caption string, botToken string)
+
+// RegisterVideoHandler handles GIFs, videos, and video notes (see VideoFromMessage).
+// Must be registered before RegisterUnsupportedMediaHandler, which also matches video.
+func (b *Bot) RegisterVideoHandler(handler VideoHandler) {
+	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		_, ok := VideoFromMessage(update.Message)
+		return ok
+	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
+		defer b.recoverPanic("Video handler")
+
+		b.trackUpdateID(update)
+		video, _ := VideoFromMessage(update.Message)
 
-		handler(ctx, emoji, setName)
+		handler(updateContext(ctx, update), *video, update.Message.Caption, b.token)
 	})
 }
 
@@ -406,11 +762,7 @@ func (b *Bot) RegisterUnsupportedMediaHandler(handler UnsupportedMediaHandler) {
 			update.Message.VideoNote != nil ||
 			update.Message.Animation != nil
 	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("[PANIC] Unsupported media handler panicked: %v\n", r)
-			}
-		}()
+		defer b.recoverPanic("Unsupported media handler")
 
 		b.trackUpdateID(update)
 		handler(ctx)
@@ -423,11 +775,7 @@ func (b *Bot) RegisterReactionHandler(handler ReactionHandler) {
 	b.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return update.MessageReaction != nil
 	}, func(ctx context.Context, botInstance *bot.Bot, update *models.Update) {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("[PANIC] Reaction handler panicked: %v\n", r)
-			}
-		}()
+		defer b.recoverPanic("Reaction handler")
 
 		b.trackUpdateID(update)
 		reaction := update.MessageReaction
@@ -2,10 +2,18 @@ package telegram
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/i18n"
 )
 
 func TestNewBot(t *testing.T) {
@@ -23,6 +31,67 @@ func TestNewBot(t *testing.T) {
 	}
 }
 
+func TestBuildCommandsTranslated(t *testing.T) {
+	for _, lang := range i18n.Supported() {
+		commands := buildCommands(lang)
+
+		if len(commands) != len(menuCommands) {
+			t.Fatalf("%s: expected %d commands, got %d", lang, len(menuCommands), len(commands))
+		}
+		for _, cmd := range commands {
+			if cmd.Description == "" || cmd.Description == "cmd."+cmd.Command {
+				t.Errorf("%s: missing description for /%s", lang, cmd.Command)
+			}
+		}
+	}
+
+	if got := buildCommands(i18n.Chinese)[0].Description; got != "顯示所有可用指令" {
+		t.Errorf("unexpected zh description for /help: %q", got)
+	}
+}
+
+func TestChatScopedCommands(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		mu.Lock()
+		calls = append(calls, fmt.Sprintf("%s lang=%q scope=%s", path.Base(r.URL.Path), r.FormValue("language_code"), r.FormValue("scope")))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	defer server.Close()
+	b := newTestBot(t, server)
+	ctx := context.Background()
+
+	if err := b.SetMyCommands(ctx, i18n.English, ""); err != nil {
+		t.Fatalf("SetMyCommands: %v", err)
+	}
+	if err := b.SetChatCommands(ctx, i18n.Chinese); err != nil {
+		t.Fatalf("SetChatCommands: %v", err)
+	}
+	// On restart, the language the chat picked is applied again
+	if err := b.SetMyCommands(ctx, i18n.English, i18n.Chinese); err != nil {
+		t.Fatalf("SetMyCommands: %v", err)
+	}
+
+	want := []string{
+		`setMyCommands lang="" scope=`,
+		`setMyCommands lang="en" scope=`,
+		`setMyCommands lang="zh" scope=`,
+		`deleteMyCommands lang="" scope={"type":"chat","chat_id":1}`,
+		`setMyCommands lang="" scope={"type":"chat","chat_id":1}`,
+		`setMyCommands lang="" scope=`,
+		`setMyCommands lang="en" scope=`,
+		`setMyCommands lang="zh" scope=`,
+		`setMyCommands lang="" scope={"type":"chat","chat_id":1}`,
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(calls, "\n"))
+	}
+}
+
 func TestSendMessage(t *testing.T) {
 	t.Skip("Skipping test that requires real Telegram API - tested in integration")
 }
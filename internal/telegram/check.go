@@ -0,0 +1,32 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// CheckToken calls getMe with a bot token and returns the bot's username,
+// e.g. to validate the configuration before starting. A nil client is the
+// default one.
+func CheckToken(ctx context.Context, token string, client *http.Client) (string, error) {
+	if client != nil {
+		return checkToken(ctx, token, bot.WithHTTPClient(time.Minute, client))
+	}
+	return checkToken(ctx, token)
+}
+
+func checkToken(ctx context.Context, token string, opts ...bot.Option) (string, error) {
+	b, err := bot.New(token, append(opts, bot.WithSkipGetMe())...)
+	if err != nil {
+		return "", err
+	}
+	me, err := b.GetMe(ctx)
+	if err != nil {
+		return "", fmt.Errorf("getMe: %w", err)
+	}
+	return me.Username, nil
+}
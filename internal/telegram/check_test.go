@@ -0,0 +1,31 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+)
+
+func TestCheckToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "good-token") {
+			w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Bridge","username":"bridge_bot"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	username, err := checkToken(context.Background(), "good-token", bot.WithServerURL(server.URL))
+	if err != nil || username != "bridge_bot" {
+		t.Errorf("expected bridge_bot, got %q (%v)", username, err)
+	}
+	if _, err := checkToken(context.Background(), "bad-token", bot.WithServerURL(server.URL)); err == nil {
+		t.Error("expected an error for a rejected token")
+	}
+}
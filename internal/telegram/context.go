@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+type messageIDKey struct{}
+
+type userIDKey struct{}
+
+type userNameKey struct{}
+
+type noOutboxKey struct{}
+
+// WithMessageID records the ID of the incoming message that triggered a handler
+func WithMessageID(ctx context.Context, messageID int) context.Context {
+	return context.WithValue(ctx, messageIDKey{}, messageID)
+}
+
+// MessageIDFromContext returns the triggering message ID set by the update handlers
+func MessageIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(messageIDKey{}).(int)
+	return id, ok && id != 0
+}
+
+// WithUserID records the Telegram user who sent the update
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the sending user set by the update handlers
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey{}).(int64)
+	return id, ok && id != 0
+}
+
+// WithUserName records the display name of the user who sent the update
+func WithUserName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, userNameKey{}, name)
+}
+
+// UserNameFromContext returns the sender's @username, or their full name when
+// they have none
+func UserNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(userNameKey{}).(string)
+	return name, ok && name != ""
+}
+
+// WithoutOutbox keeps the sends and edits made with ctx out of the outbox:
+// a transient failure is returned instead of queued. Status messages such as
+// the thinking placeholder would be stale by the time they are replayed.
+func WithoutOutbox(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noOutboxKey{}, true)
+}
+
+// skipsOutbox reports whether ctx is WithoutOutbox
+func skipsOutbox(ctx context.Context) bool {
+	skip, _ := ctx.Value(noOutboxKey{}).(bool)
+	return skip
+}
+
+// displayName returns @username, or the full name for users without one
+func displayName(user *models.User) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// updateContext attaches the message ID and sender of an update to ctx
+func updateContext(ctx context.Context, update *models.Update) context.Context {
+	switch {
+	case update.Message != nil:
+		ctx = WithMessageID(ctx, update.Message.ID)
+		if update.Message.From != nil {
+			ctx = WithUserID(ctx, update.Message.From.ID)
+			ctx = WithUserName(ctx, displayName(update.Message.From))
+		}
+	case update.CallbackQuery != nil:
+		ctx = WithUserID(ctx, update.CallbackQuery.From.ID)
+		ctx = WithUserName(ctx, displayName(&update.CallbackQuery.From))
+	}
+	return ctx
+}
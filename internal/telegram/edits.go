@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// editQueue is the edits of one message: the one being sent, and the one
+// waiting for the chat's next send slot. Edits arriving while one waits
+// replace its text, so a message streamed faster than the chat's pace gets
+// only its latest text rather than a backlog of stale ones.
+type editQueue struct {
+	sending *pendingEdit
+	waiting *pendingEdit
+}
+
+// pendingEdit is an edit waiting to go out; the callers whose text it
+// replaced get its outcome
+type pendingEdit struct {
+	ctx   context.Context
+	entry state.OutboxEntry
+	send  func() error
+
+	done chan struct{}
+	err  error
+}
+
+// edit delivers an edit of entry.MessageID like deliver, coalesced with the
+// other edits of the message that have not gone out yet. Edits of a message
+// go out one at a time, in the order they were made.
+func (b *Bot) edit(ctx context.Context, entry state.OutboxEntry, send func() error) error {
+	id := entry.MessageID
+	b.editsMu.Lock()
+	if b.edits == nil {
+		b.edits = make(map[int]*editQueue)
+	}
+	q := b.edits[id]
+	if q == nil {
+		q = &editQueue{}
+		b.edits[id] = q
+	}
+	if p := q.waiting; p != nil {
+		// Not sent yet: it sends this text instead
+		p.ctx, p.entry, p.send = ctx, entry, send
+		b.editsMu.Unlock()
+		select {
+		case <-p.done:
+			return p.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	p := &pendingEdit{ctx: ctx, entry: entry, send: send, done: make(chan struct{})}
+	prev := q.sending
+	q.waiting = p
+	b.editsMu.Unlock()
+
+	var err error
+	if prev != nil {
+		select {
+		case <-prev.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if err == nil {
+		err = b.limiter.waitTurn(ctx)
+	}
+
+	b.editsMu.Lock()
+	q.waiting = nil
+	if err == nil {
+		q.sending = p
+	}
+	ctx, entry, send = p.ctx, p.entry, p.send
+	b.editsMu.Unlock()
+
+	if err == nil {
+		err = b.deliver(ctx, entry, send)
+	}
+	p.err = err
+	close(p.done)
+
+	b.editsMu.Lock()
+	if q.sending == p {
+		q.sending = nil
+	}
+	if q.sending == nil && q.waiting == nil && b.edits[id] == q {
+		delete(b.edits, id)
+	}
+	b.editsMu.Unlock()
+	return err
+}
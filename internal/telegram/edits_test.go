@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditsCoalesceWhileWaiting(t *testing.T) {
+	fake := &fakeTelegram{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	b := newTestBot(t, server)
+	b.SetSendInterval(200 * time.Millisecond)
+	ctx := context.Background()
+
+	// Takes the chat's slot, so the edits below wait for the next one
+	_, err := b.SendMessage(ctx, "thinking")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = b.EditMessage(ctx, 1, fmt.Sprintf("part %d", i))
+		}()
+		time.Sleep(10 * time.Millisecond) // made in order
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"sendMessage:thinking", "editMessageText:part 4"}, fake.calls)
+	assert.Empty(t, b.edits)
+}
@@ -0,0 +1,52 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/go-telegram/bot"
+)
+
+// Classes of failed Bot API requests, the class label of
+// telegram_errors_total
+const (
+	ErrorClassFloodWait   = "flood_wait"   // 429, retry_after
+	ErrorClassNotModified = "not_modified" // edit to the text already shown
+	ErrorClassBlocked     = "blocked"      // 403: blocked by the user, removed from the chat
+	ErrorClassParse       = "parse"        // HTML the Bot API could not parse
+	ErrorClassBadRequest  = "bad_request"  // other 400s
+	ErrorClassServer      = "server"       // Telegram 5xx
+	ErrorClassNetwork     = "network"      // no response
+	ErrorClassOther       = "other"
+)
+
+// ErrorClass tells what kind of failure a Bot API error is, so failures can
+// be counted and logged by cause
+func ErrorClass(err error) string {
+	var flood *bot.TooManyRequestsError
+	if errors.As(err, &flood) {
+		return ErrorClassFloodWait
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "message is not modified"):
+		return ErrorClassNotModified
+	case errors.Is(err, bot.ErrorForbidden):
+		return ErrorClassBlocked
+	case strings.Contains(msg, "can't parse entities"):
+		return ErrorClassParse
+	case errors.Is(err, bot.ErrorBadRequest):
+		return ErrorClassBadRequest
+	case serverErrorPattern.MatchString(msg):
+		return ErrorClassServer
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
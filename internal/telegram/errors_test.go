@@ -0,0 +1,30 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&bot.TooManyRequestsError{RetryAfter: 3}, ErrorClassFloodWait},
+		{fmt.Errorf("%w, Bad Request: message is not modified: specified new message content and reply markup are exactly the same", bot.ErrorBadRequest), ErrorClassNotModified},
+		{fmt.Errorf("%w, Forbidden: bot was blocked by the user", bot.ErrorForbidden), ErrorClassBlocked},
+		{fmt.Errorf("%w, Bad Request: can't parse entities: Unsupported start tag \"foo\"", bot.ErrorBadRequest), ErrorClassParse},
+		{fmt.Errorf("%w, Bad Request: message to edit not found", bot.ErrorBadRequest), ErrorClassBadRequest},
+		{errors.New("error response from telegram for method sendMessage, 502 Bad Gateway"), ErrorClassServer},
+		{fmt.Errorf("failed to send message: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), ErrorClassNetwork},
+		{errors.New("something else"), ErrorClassOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ErrorClass(tt.err), tt.err.Error())
+	}
+}
@@ -7,17 +7,33 @@ import (
 	"unicode/utf8"
 )
 
+// thinkingRegex matches reasoning sections some models wrap in <think> tags
+var thinkingRegex = regexp.MustCompile(`(?s)<(think|thinking)>\s*(.*?)\s*</(?:think|thinking)>`)
+
 // FormatHTML converts markdown-style text to Telegram-compatible HTML
-// Supports: bold, italic, code, code blocks, strikethrough, links, blockquotes, headings, lists
+// Supports: bold, italic, code, code blocks, strikethrough, spoilers, links, blockquotes, headings, lists.
+// <think>...</think> sections become expandable blockquotes, collapsed by default.
 func FormatHTML(text string) string {
 	// First, escape HTML entities in the entire text
 	// We'll temporarily protect markdown patterns during escaping
 	result := text
+	placeholder := 0
+
+	// Step 0: Extract and protect reasoning sections, formatted on their own
+	protectedThinking := make(map[string]string)
+
+	result = thinkingRegex.ReplaceAllStringFunc(result, func(match string) string {
+		inner := thinkingRegex.FindStringSubmatch(match)[2]
+
+		key := "\x00THINKING" + string(rune(placeholder)) + "\x00"
+		protectedThinking[key] = FormatThinking(inner)
+		placeholder++
+		return key
+	})
 
 	// Step 1: Extract and protect code blocks (``` fenced blocks)
 	codeBlockRegex := regexp.MustCompile("```([a-z]*)\n([\\s\\S]*?)\n?```")
 	protectedCodeBlocks := make(map[string]string)
-	placeholder := 0
 
 	result = codeBlockRegex.ReplaceAllStringFunc(result, func(match string) string {
 		matches := codeBlockRegex.FindStringSubmatch(match)
@@ -112,6 +128,10 @@ func FormatHTML(text string) string {
 	strikeRegex := regexp.MustCompile(`~~([^~]+)~~`)
 	result = strikeRegex.ReplaceAllString(result, "<s>$1</s>")
 
+	// Spoiler: ||text||
+	spoilerRegex := regexp.MustCompile(`\|\|([^|\n]+)\|\|`)
+	result = spoilerRegex.ReplaceAllString(result, "<tg-spoiler>$1</tg-spoiler>")
+
 	// Links: [text](url)
 	linkRegex := regexp.MustCompile(`\[([^\]]+)\]\(([^\)]+)\)`)
 	result = linkRegex.ReplaceAllString(result, `<a href="$2">$1</a>`)
@@ -138,10 +158,22 @@ func FormatHTML(text string) string {
 	for key, value := range protectedCodeBlocks {
 		result = strings.ReplaceAll(result, key, value)
 	}
+	for key, value := range protectedThinking {
+		result = strings.ReplaceAll(result, key, value)
+	}
 
 	return result
 }
 
+// FormatThinking formats reasoning text as an expandable blockquote, which
+// Telegram shows collapsed to a few lines until tapped. Blockquotes cannot be
+// nested, so quotes inside the reasoning are flattened.
+func FormatThinking(text string) string {
+	inner := FormatHTML(strings.TrimSpace(text))
+	inner = strings.NewReplacer("<blockquote>", "", "</blockquote>", "").Replace(inner)
+	return "<blockquote expandable>💭 " + inner + "</blockquote>"
+}
+
 // isMarkdownChar checks if a byte is a markdown formatting character
 func isMarkdownChar(b byte) bool {
 	return b == '*' || b == '_'
@@ -198,7 +230,7 @@ func SplitMessage(text string, limit int) []string {
 			openTags := findOpenTags(chunk)
 			tagOverhead := 0
 			for _, tag := range openTags {
-				tagOverhead += len(tag) + 3 // </tag>
+				tagOverhead += len(tagName(tag)) + 3 // </tag>
 			}
 
 			// Retry with adjusted limit
@@ -279,47 +311,52 @@ func balanceHTMLTags(chunk, remaining string) (string, string) {
 
 	// Close tags at end of chunk (in reverse order)
 	for i := len(openTags) - 1; i >= 0; i-- {
-		chunk += "</" + openTags[i] + ">"
+		chunk += "</" + tagName(openTags[i]) + ">"
 	}
 
-	// Reopen tags at start of next chunk
-	for _, tag := range openTags {
-		remaining = "<" + tag + ">" + remaining
-	}
+	// Reopen tags at start of next chunk, keeping their attributes
+	// (e.g. <a href="..."> or <blockquote expandable>)
+	reopen := strings.Join(openTags, "")
+	remaining = reopen + remaining
 
 	return chunk, remaining
 }
 
-// findOpenTags finds all unclosed HTML tags in the text
+// htmlTagRegex matches opening and closing HTML tags, including tg-spoiler
+var htmlTagRegex = regexp.MustCompile(`<(/?)([a-z][a-z-]*)(?:\s[^>]*)?>`)
+
+// findOpenTags finds all unclosed HTML tags in the text, returned as their
+// full opening tags in nesting order
 func findOpenTags(text string) []string {
-	var openTags []string
 	tagStack := make([]string, 0)
 
-	// Simple HTML tag parser
-	tagRegex := regexp.MustCompile(`<(/?)([a-z]+)(?:\s[^>]*)?>`)
-	matches := tagRegex.FindAllStringSubmatch(text, -1)
+	matches := htmlTagRegex.FindAllStringSubmatch(text, -1)
 
 	for _, match := range matches {
 		isClosing := match[1] == "/"
-		tagName := match[2]
+		name := match[2]
 
 		// Skip self-closing tags
-		if tagName == "br" {
+		if name == "br" {
 			continue
 		}
 
 		if isClosing {
 			// Pop from stack if it matches
-			if len(tagStack) > 0 && tagStack[len(tagStack)-1] == tagName {
+			if len(tagStack) > 0 && tagName(tagStack[len(tagStack)-1]) == name {
 				tagStack = tagStack[:len(tagStack)-1]
 			}
 		} else {
 			// Push to stack
-			tagStack = append(tagStack, tagName)
+			tagStack = append(tagStack, match[0])
 		}
 	}
 
 	// Return remaining open tags
-	openTags = tagStack
-	return openTags
+	return tagStack
+}
+
+// tagName returns the element name of an opening tag, e.g. "a" for <a href="...">
+func tagName(openTag string) string {
+	return htmlTagRegex.FindStringSubmatch(openTag)[2]
 }
@@ -107,6 +107,21 @@ func TestFormatHTML(t *testing.T) {
 			input:    "`a && b`",
 			expected: "<code>a &amp;&amp; b</code>",
 		},
+		{
+			name:     "spoiler",
+			input:    "The answer is ||42||",
+			expected: "The answer is <tg-spoiler>42</tg-spoiler>",
+		},
+		{
+			name:     "thinking section collapsed",
+			input:    "<think>\nCheck **auth** first\n> maybe\n</think>\nUse `login()`",
+			expected: "<blockquote expandable>💭 Check <b>auth</b> first\nmaybe</blockquote>\nUse <code>login()</code>",
+		},
+		{
+			name:     "thinking tag variant",
+			input:    "<thinking>a < b</thinking>done",
+			expected: "<blockquote expandable>💭 a &lt; b</blockquote>done",
+		},
 	}
 
 	for _, tt := range tests {
@@ -257,6 +272,25 @@ func TestSplitMessageNoSplitInsideHTMLTag(t *testing.T) {
 	}
 }
 
+func TestSplitMessageReopensTagsWithAttributes(t *testing.T) {
+	text := "<blockquote expandable>" + strings.Repeat("step ", 1000) + "</blockquote> <tg-spoiler>x</tg-spoiler>"
+
+	chunks := SplitMessage(text, 4096)
+	if len(chunks) < 2 {
+		t.Fatalf("expected text to be split, got %d chunk(s)", len(chunks))
+	}
+
+	if !strings.HasSuffix(chunks[0], "</blockquote>") {
+		t.Errorf("first chunk should close the blockquote, got suffix %q", chunks[0][len(chunks[0])-20:])
+	}
+	if !strings.HasPrefix(chunks[1], "<blockquote expandable>") {
+		t.Errorf("second chunk should reopen the expandable blockquote, got prefix %q", chunks[1][:30])
+	}
+	if got := findOpenTags("<tg-spoiler>secret"); len(got) != 1 || got[0] != "<tg-spoiler>" {
+		t.Errorf("findOpenTags should track tg-spoiler, got %v", got)
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/i18n"
 )
 
 // QuestionOption represents a single option in a question
@@ -26,7 +28,7 @@ type QuestionInfo struct {
 // BuildQuestionKeyboard builds an inline keyboard for a question
 // Each option becomes a button with callback_data: {shortKey}:{optionIndex}
 // shortKey is expected to be the short key from registry (e.g., "q:2:0")
-func BuildQuestionKeyboard(info QuestionInfo, shortKey string) *models.InlineKeyboardMarkup {
+func BuildQuestionKeyboard(info QuestionInfo, shortKey string, lang i18n.Lang) *models.InlineKeyboardMarkup {
 	var rows [][]models.InlineKeyboardButton
 
 	// Add a button for each option
@@ -41,7 +43,7 @@ func BuildQuestionKeyboard(info QuestionInfo, shortKey string) *models.InlineKey
 	// If multiple selection, add Submit button
 	if info.Multiple {
 		submitButton := models.InlineKeyboardButton{
-			Text:         "✅ Submit",
+			Text:         i18n.T(lang, "question.button.submit"),
 			CallbackData: fmt.Sprintf("%s:submit", shortKey),
 		}
 		rows = append(rows, []models.InlineKeyboardButton{submitButton})
@@ -50,7 +52,7 @@ func BuildQuestionKeyboard(info QuestionInfo, shortKey string) *models.InlineKey
 	// If custom input allowed, add custom button
 	if info.Custom {
 		customButton := models.InlineKeyboardButton{
-			Text:         "✏️ Type custom...",
+			Text:         i18n.T(lang, "question.button.custom"),
 			CallbackData: fmt.Sprintf("%s:custom", shortKey),
 		}
 		rows = append(rows, []models.InlineKeyboardButton{customButton})
@@ -63,26 +65,26 @@ func BuildQuestionKeyboard(info QuestionInfo, shortKey string) *models.InlineKey
 
 // BuildPermissionKeyboard builds the standard permission keyboard
 // Returns keyboard with: Allow Once, Always Allow, Reject
-func BuildPermissionKeyboard(permissionID string) *models.InlineKeyboardMarkup {
+func BuildPermissionKeyboard(permissionID string, lang i18n.Lang) *models.InlineKeyboardMarkup {
 	// Generate a short ID to keep callback_data under 64 bytes
 	shortID := generateShortID(permissionID)
 
 	rows := [][]models.InlineKeyboardButton{
 		{
 			{
-				Text:         "✅ Allow Once",
+				Text:         i18n.T(lang, "permission.button.once"),
 				CallbackData: fmt.Sprintf("p:%s:once", shortID),
 			},
 		},
 		{
 			{
-				Text:         "✅ Always Allow",
+				Text:         i18n.T(lang, "permission.button.always"),
 				CallbackData: fmt.Sprintf("p:%s:always", shortID),
 			},
 		},
 		{
 			{
-				Text:         "❌ Reject",
+				Text:         i18n.T(lang, "permission.button.reject"),
 				CallbackData: fmt.Sprintf("p:%s:reject", shortID),
 			},
 		},
@@ -93,6 +95,98 @@ func BuildPermissionKeyboard(permissionID string) *models.InlineKeyboardMarkup {
 	}
 }
 
+// BuildShowMoreKeyboard builds the button revealing page next (0-based) of a
+// paginated response. pagesKey is the registry key holding the pages (e.g. "more:3").
+// callback_data: {pagesKey}:{next}
+func BuildShowMoreKeyboard(pagesKey string, next int, total int, lang i18n.Lang) *models.InlineKeyboardMarkup {
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{
+					Text:         i18n.T(lang, "pages.show_more", next+1, total),
+					CallbackData: fmt.Sprintf("%s:%d", pagesKey, next),
+				},
+			},
+		},
+	}
+}
+
+// Actions offered under a completed response.
+// callback_data: act:{action}
+const (
+	ResponseActionContinue = "continue"
+	ResponseActionRetry    = "retry"
+	ResponseActionNew      = "new"
+	ResponseActionExplain  = "explain"
+)
+
+// BuildResponseActionsKeyboard builds the inline keyboard attached to a completed response
+// Layout: 2x2 grid (Continue, Retry / New session, Explain more)
+func BuildResponseActionsKeyboard(lang i18n.Lang) *models.InlineKeyboardMarkup {
+	button := func(action string) models.InlineKeyboardButton {
+		return models.InlineKeyboardButton{
+			Text:         i18n.T(lang, "action."+action),
+			CallbackData: "act:" + action,
+		}
+	}
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{button(ResponseActionContinue), button(ResponseActionRetry)},
+			{button(ResponseActionNew), button(ResponseActionExplain)},
+		},
+	}
+}
+
+// Quick actions shown on the persistent reply keyboard.
+// Pressing a button sends its translated label as a plain text message.
+const (
+	QuickActionNewSession  = "new"
+	QuickActionStatus      = "status"
+	QuickActionAbort       = "abort"
+	QuickActionSwitchAgent = "switch"
+)
+
+var quickActions = []string{QuickActionNewSession, QuickActionStatus, QuickActionAbort, QuickActionSwitchAgent}
+
+func quickActionLabel(lang i18n.Lang, action string) string {
+	return i18n.T(lang, "quick."+action)
+}
+
+// BuildQuickActionKeyboard builds the persistent reply keyboard for common actions
+// Layout: 2x2 grid (New session, Status / Abort, Switch agent)
+func BuildQuickActionKeyboard(lang i18n.Lang) *models.ReplyKeyboardMarkup {
+	return &models.ReplyKeyboardMarkup{
+		Keyboard: [][]models.KeyboardButton{
+			{
+				{Text: quickActionLabel(lang, QuickActionNewSession)},
+				{Text: quickActionLabel(lang, QuickActionStatus)},
+			},
+			{
+				{Text: quickActionLabel(lang, QuickActionAbort)},
+				{Text: quickActionLabel(lang, QuickActionSwitchAgent)},
+			},
+		},
+		IsPersistent:          true,
+		ResizeKeyboard:        true,
+		InputFieldPlaceholder: i18n.T(lang, "keyboard.placeholder"),
+	}
+}
+
+// QuickActionFor returns the quick action whose button label matches text.
+// Labels of every supported language are accepted, since a keyboard sent
+// before a /lang change stays on the user's screen.
+func QuickActionFor(text string) (string, bool) {
+	for _, lang := range i18n.Supported() {
+		for _, action := range quickActions {
+			if text == quickActionLabel(lang, action) {
+				return action, true
+			}
+		}
+	}
+	return "", false
+}
+
 // generateShortID creates a short hash from a long ID to keep callback_data under 64 bytes
 // Takes first 8 characters of SHA256 hash
 func generateShortID(id string) string {
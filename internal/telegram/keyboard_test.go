@@ -2,6 +2,8 @@ package telegram
 
 import (
 	"testing"
+
+	"github.com/user/opencode-telegram/internal/i18n"
 )
 
 func TestBuildQuestionKeyboard(t *testing.T) {
@@ -61,7 +63,7 @@ func TestBuildQuestionKeyboard(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			keyboard := BuildQuestionKeyboard(tt.questionInfo, tt.requestID)
+			keyboard := BuildQuestionKeyboard(tt.questionInfo, tt.requestID, i18n.English)
 
 			if keyboard == nil {
 				t.Fatal("BuildQuestionKeyboard returned nil")
@@ -83,9 +85,46 @@ func TestBuildQuestionKeyboard(t *testing.T) {
 	}
 }
 
+func TestBuildShowMoreKeyboard(t *testing.T) {
+	keyboard := BuildShowMoreKeyboard("more:3", 1, 6, i18n.English)
+
+	if len(keyboard.InlineKeyboard) != 1 || len(keyboard.InlineKeyboard[0]) != 1 {
+		t.Fatalf("expected a single button, got %v", keyboard.InlineKeyboard)
+	}
+
+	btn := keyboard.InlineKeyboard[0][0]
+	if btn.Text != "▶️ Show more (2/6)" {
+		t.Errorf("unexpected button text %q", btn.Text)
+	}
+	if btn.CallbackData != "more:3:1" {
+		t.Errorf("unexpected callback data %q", btn.CallbackData)
+	}
+}
+
+func TestBuildResponseActionsKeyboard(t *testing.T) {
+	keyboard := BuildResponseActionsKeyboard(i18n.English)
+
+	var callbacks []string
+	for _, row := range keyboard.InlineKeyboard {
+		for _, btn := range row {
+			callbacks = append(callbacks, btn.CallbackData)
+		}
+	}
+
+	expected := []string{"act:continue", "act:retry", "act:new", "act:explain"}
+	if len(callbacks) != len(expected) {
+		t.Fatalf("expected %d buttons, got %d", len(expected), len(callbacks))
+	}
+	for i := range expected {
+		if callbacks[i] != expected[i] {
+			t.Errorf("button %d: expected %q, got %q", i, expected[i], callbacks[i])
+		}
+	}
+}
+
 func TestBuildPermissionKeyboard(t *testing.T) {
 	permissionID := "perm123"
-	keyboard := BuildPermissionKeyboard(permissionID)
+	keyboard := BuildPermissionKeyboard(permissionID, i18n.English)
 
 	if keyboard == nil {
 		t.Fatal("BuildPermissionKeyboard returned nil")
@@ -172,7 +211,7 @@ func TestCallbackDataLength(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			keyboard := BuildQuestionKeyboard(tt.questionInfo, tt.requestID)
+			keyboard := BuildQuestionKeyboard(tt.questionInfo, tt.requestID, i18n.English)
 
 			for rowIdx, row := range keyboard.InlineKeyboard {
 				for btnIdx, btn := range row {
@@ -187,7 +226,7 @@ func TestCallbackDataLength(t *testing.T) {
 
 	// Test permission keyboard
 	t.Run("permission keyboard", func(t *testing.T) {
-		keyboard := BuildPermissionKeyboard("perm_longid1234567890")
+		keyboard := BuildPermissionKeyboard("perm_longid1234567890", i18n.English)
 
 		for rowIdx, row := range keyboard.InlineKeyboard {
 			for btnIdx, btn := range row {
@@ -199,3 +238,31 @@ func TestCallbackDataLength(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildQuickActionKeyboard(t *testing.T) {
+	for _, lang := range i18n.Supported() {
+		keyboard := BuildQuickActionKeyboard(lang)
+
+		if !keyboard.IsPersistent {
+			t.Errorf("%s: expected keyboard to be persistent", lang)
+		}
+		if len(keyboard.Keyboard) != 2 {
+			t.Fatalf("%s: expected 2 rows, got %d", lang, len(keyboard.Keyboard))
+		}
+
+		for _, row := range keyboard.Keyboard {
+			for _, button := range row {
+				if _, ok := QuickActionFor(button.Text); !ok {
+					t.Errorf("%s: button %q is not recognised as a quick action", lang, button.Text)
+				}
+			}
+		}
+	}
+
+	if action, _ := QuickActionFor("📊 Status"); action != QuickActionStatus {
+		t.Errorf("expected %q, got %q", QuickActionStatus, action)
+	}
+	if _, ok := QuickActionFor("hello"); ok {
+		t.Error("regular text should not be a quick action")
+	}
+}
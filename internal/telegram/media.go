@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/go-telegram/bot/models"
 )
@@ -54,6 +55,25 @@ func SetMediaClient(client *http.Client) {
 }
 // DownloadPhoto downloads a photo from Telegram servers using the Bot API
 func DownloadPhoto(ctx context.Context, botToken, fileID string) ([]byte, error) {
+	return DownloadFile(ctx, botToken, fileID)
+}
+
+// DownloadFile downloads any file (photo, voice, audio, document) by file ID.
+// The Bot API only serves files up to MaxDownloadSize.
+func DownloadFile(ctx context.Context, botToken, fileID string) ([]byte, error) {
+	return downloadFile(ctx, mediaClient, botToken, fileID)
+}
+
+// DownloadFile downloads a file like the DownloadFile function, through the
+// bot's own HTTP client when it has one (see NewBotWithClient)
+func (b *Bot) DownloadFile(ctx context.Context, botToken, fileID string) ([]byte, error) {
+	if b.httpClient == nil {
+		return DownloadFile(ctx, botToken, fileID)
+	}
+	return downloadFile(ctx, b.httpClient, botToken, fileID)
+}
+
+func downloadFile(ctx context.Context, client *http.Client, botToken, fileID string) ([]byte, error) {
 	// Step 1: Get file path using getFile API
 	getFileURL := fmt.Sprintf("https://api.telegram.org/bot%s/getFile?file_id=%s", botToken, fileID)
 
@@ -62,8 +82,7 @@ func DownloadPhoto(ctx context.Context, botToken, fileID string) ([]byte, error)
 		return nil, fmt.Errorf("create getFile request: %w", err)
 	}
 
-	// Use shared media client
-	resp, err := mediaClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("getFile request: %w", err)
 	}
@@ -91,7 +110,7 @@ func DownloadPhoto(ctx context.Context, botToken, fileID string) ([]byte, error)
 		return nil, fmt.Errorf("create file download request: %w", err)
 	}
 
-	fileDownloadResp, err := mediaClient.Do(fileReq)
+	fileDownloadResp, err := client.Do(fileReq)
 	if err != nil {
 		return nil, fmt.Errorf("download file: %w", err)
 	}
@@ -110,6 +129,168 @@ func DownloadPhoto(ctx context.Context, botToken, fileID string) ([]byte, error)
 	return fileData, nil
 }
 
+// MaxDownloadSize is the largest file the Bot API getFile method will serve
+const MaxDownloadSize = 20 * 1024 * 1024
+
+// AudioFile describes an incoming voice note or uploaded audio file
+type AudioFile struct {
+	FileID   string
+	FileName string
+	MimeType string
+	FileSize int64
+	Duration int
+	Voice    bool
+}
+
+// AudioFromMessage extracts a voice note, audio upload, or audio document from a message.
+// Audio sent "as file" (common for .m4a) arrives as a Document with an audio/* MIME type.
+func AudioFromMessage(msg *models.Message) (*AudioFile, bool) {
+	if msg == nil {
+		return nil, false
+	}
+
+	switch {
+	case msg.Voice != nil:
+		return &AudioFile{
+			FileID:   msg.Voice.FileID,
+			FileName: "voice.ogg",
+			MimeType: msg.Voice.MimeType,
+			FileSize: msg.Voice.FileSize,
+			Duration: msg.Voice.Duration,
+			Voice:    true,
+		}, true
+	case msg.Audio != nil:
+		name := msg.Audio.FileName
+		if name == "" {
+			name = "audio" + audioExtension(msg.Audio.MimeType)
+		}
+		return &AudioFile{
+			FileID:   msg.Audio.FileID,
+			FileName: name,
+			MimeType: msg.Audio.MimeType,
+			FileSize: msg.Audio.FileSize,
+			Duration: msg.Audio.Duration,
+		}, true
+	case msg.Document != nil && strings.HasPrefix(msg.Document.MimeType, "audio/"):
+		name := msg.Document.FileName
+		if name == "" {
+			name = "audio" + audioExtension(msg.Document.MimeType)
+		}
+		return &AudioFile{
+			FileID:   msg.Document.FileID,
+			FileName: name,
+			MimeType: msg.Document.MimeType,
+			FileSize: msg.Document.FileSize,
+		}, true
+	}
+
+	return nil, false
+}
+
+// audioExtension maps common audio MIME types to a file extension so
+// transcription backends can detect the container format
+func audioExtension(mimeType string) string {
+	switch mimeType {
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/x-m4a", "audio/m4a", "audio/aac":
+		return ".m4a"
+	case "audio/ogg", "audio/opus":
+		return ".ogg"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "audio/webm":
+		return ".webm"
+	case "audio/flac":
+		return ".flac"
+	}
+	return ".mp3"
+}
+
+// VideoFile describes an incoming GIF animation, video, or round video note
+type VideoFile struct {
+	FileID    string
+	FileName  string
+	MimeType  string
+	FileSize  int64
+	Duration  int
+	Thumbnail *models.PhotoSize
+	Animation bool
+}
+
+// VideoFromMessage extracts an animation, video, video note, or video document from a message.
+// Animations are checked first since Telegram also fills Document for them.
+func VideoFromMessage(msg *models.Message) (*VideoFile, bool) {
+	if msg == nil {
+		return nil, false
+	}
+
+	switch {
+	case msg.Animation != nil:
+		return &VideoFile{
+			FileID:    msg.Animation.FileID,
+			FileName:  msg.Animation.FileName,
+			MimeType:  msg.Animation.MimeType,
+			FileSize:  msg.Animation.FileSize,
+			Duration:  msg.Animation.Duration,
+			Thumbnail: msg.Animation.Thumbnail,
+			Animation: true,
+		}, true
+	case msg.Video != nil:
+		return &VideoFile{
+			FileID:    msg.Video.FileID,
+			FileName:  msg.Video.FileName,
+			MimeType:  msg.Video.MimeType,
+			FileSize:  msg.Video.FileSize,
+			Duration:  msg.Video.Duration,
+			Thumbnail: msg.Video.Thumbnail,
+		}, true
+	case msg.VideoNote != nil:
+		return &VideoFile{
+			FileID:    msg.VideoNote.FileID,
+			FileName:  "video_note.mp4",
+			MimeType:  "video/mp4",
+			FileSize:  int64(msg.VideoNote.FileSize),
+			Duration:  msg.VideoNote.Duration,
+			Thumbnail: msg.VideoNote.Thumbnail,
+		}, true
+	case msg.Document != nil && strings.HasPrefix(msg.Document.MimeType, "video/"):
+		return &VideoFile{
+			FileID:    msg.Document.FileID,
+			FileName:  msg.Document.FileName,
+			MimeType:  msg.Document.MimeType,
+			FileSize:  msg.Document.FileSize,
+			Thumbnail: msg.Document.Thumbnail,
+		}, true
+	}
+
+	return nil, false
+}
+
+// StickerFrame returns a static frame for animated (.tgs) and video (.webm)
+// stickers. Telegram ships a pre-rendered WebP/JPEG thumbnail with these,
+// which the vision pipeline can consume without a Lottie or VP9 decoder.
+// Static stickers report false; they are described as text instead.
+func StickerFrame(sticker *models.Sticker) (*models.PhotoSize, bool) {
+	if sticker == nil || !(sticker.IsAnimated || sticker.IsVideo) {
+		return nil, false
+	}
+	if sticker.Thumbnail == nil || sticker.Thumbnail.FileID == "" {
+		return nil, false
+	}
+	return sticker.Thumbnail, true
+}
+
+// ImageMimeType sniffs the MIME type of downloaded image data, falling back
+// to image/jpeg (Telegram's photo format) for anything unrecognised.
+func ImageMimeType(data []byte) string {
+	mimeType := http.DetectContentType(data)
+	if strings.HasPrefix(mimeType, "image/") {
+		return mimeType
+	}
+	return "image/jpeg"
+}
+
 // EncodeBase64 encodes binary data to base64 string
 func EncodeBase64(data []byte) string {
 	return base64.StdEncoding.EncodeToString(data)
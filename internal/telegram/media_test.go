@@ -104,3 +104,173 @@ func TestEncodeBase64(t *testing.T) {
 		})
 	}
 }
+
+func TestAudioFromMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *models.Message
+		ok       bool
+		fileName string
+		voice    bool
+	}{
+		{
+			name:     "voice note",
+			msg:      &models.Message{Voice: &models.Voice{FileID: "v1", MimeType: "audio/ogg", Duration: 5}},
+			ok:       true,
+			fileName: "voice.ogg",
+			voice:    true,
+		},
+		{
+			name:     "mp3 upload",
+			msg:      &models.Message{Audio: &models.Audio{FileID: "a1", FileName: "talk.mp3", MimeType: "audio/mpeg"}},
+			ok:       true,
+			fileName: "talk.mp3",
+		},
+		{
+			name:     "unnamed audio gets extension from mime type",
+			msg:      &models.Message{Audio: &models.Audio{FileID: "a2", MimeType: "audio/x-m4a"}},
+			ok:       true,
+			fileName: "audio.m4a",
+		},
+		{
+			name:     "m4a sent as document",
+			msg:      &models.Message{Document: &models.Document{FileID: "d1", FileName: "meeting.m4a", MimeType: "audio/mp4"}},
+			ok:       true,
+			fileName: "meeting.m4a",
+		},
+		{
+			name: "non-audio document",
+			msg:  &models.Message{Document: &models.Document{FileID: "d2", FileName: "notes.pdf", MimeType: "application/pdf"}},
+			ok:   false,
+		},
+		{
+			name: "nil message",
+			msg:  nil,
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audio, ok := AudioFromMessage(tt.msg)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if audio.FileName != tt.fileName {
+				t.Errorf("expected file name %q, got %q", tt.fileName, audio.FileName)
+			}
+			if audio.Voice != tt.voice {
+				t.Errorf("expected voice=%v, got %v", tt.voice, audio.Voice)
+			}
+		})
+	}
+}
+
+func TestStickerFrame(t *testing.T) {
+	thumb := &models.PhotoSize{FileID: "thumb", Width: 128, Height: 128}
+
+	tests := []struct {
+		name    string
+		sticker *models.Sticker
+		ok      bool
+	}{
+		{name: "static sticker", sticker: &models.Sticker{FileID: "s1", Thumbnail: thumb}, ok: false},
+		{name: "animated sticker", sticker: &models.Sticker{FileID: "s2", IsAnimated: true, Thumbnail: thumb}, ok: true},
+		{name: "video sticker", sticker: &models.Sticker{FileID: "s3", IsVideo: true, Thumbnail: thumb}, ok: true},
+		{name: "video sticker without thumbnail", sticker: &models.Sticker{FileID: "s4", IsVideo: true}, ok: false},
+		{name: "nil sticker", sticker: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, ok := StickerFrame(tt.sticker)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && frame.FileID != "thumb" {
+				t.Errorf("expected thumbnail frame, got %q", frame.FileID)
+			}
+		})
+	}
+}
+
+func TestImageMimeType(t *testing.T) {
+	webp := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 16)...)
+	if got := ImageMimeType(webp); got != "image/webp" {
+		t.Errorf("expected image/webp, got %q", got)
+	}
+	if got := ImageMimeType([]byte("not an image")); got != "image/jpeg" {
+		t.Errorf("expected image/jpeg fallback, got %q", got)
+	}
+}
+
+func TestVideoFromMessage(t *testing.T) {
+	thumb := &models.PhotoSize{FileID: "thumb"}
+	tests := []struct {
+		name      string
+		msg       *models.Message
+		ok        bool
+		fileID    string
+		animation bool
+	}{
+		{
+			name: "gif animation (document also set)",
+			msg: &models.Message{
+				Animation: &models.Animation{FileID: "g1", Duration: 3, Thumbnail: thumb},
+				Document:  &models.Document{FileID: "g1", MimeType: "video/mp4"},
+			},
+			ok:        true,
+			fileID:    "g1",
+			animation: true,
+		},
+		{
+			name:   "video",
+			msg:    &models.Message{Video: &models.Video{FileID: "v1", Duration: 12}},
+			ok:     true,
+			fileID: "v1",
+		},
+		{
+			name:   "video note",
+			msg:    &models.Message{VideoNote: &models.VideoNote{FileID: "n1", Duration: 5}},
+			ok:     true,
+			fileID: "n1",
+		},
+		{
+			name:   "video sent as document",
+			msg:    &models.Message{Document: &models.Document{FileID: "d1", FileName: "bug.mov", MimeType: "video/quicktime"}},
+			ok:     true,
+			fileID: "d1",
+		},
+		{
+			name: "non-video document",
+			msg:  &models.Message{Document: &models.Document{FileID: "d2", MimeType: "application/zip"}},
+			ok:   false,
+		},
+		{
+			name: "nil message",
+			msg:  nil,
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			video, ok := VideoFromMessage(tt.msg)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if video.FileID != tt.fileID {
+				t.Errorf("expected file ID %q, got %q", tt.fileID, video.FileID)
+			}
+			if video.Animation != tt.animation {
+				t.Errorf("expected animation=%v, got %v", tt.animation, video.Animation)
+			}
+		})
+	}
+}
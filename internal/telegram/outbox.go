@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// ErrQueued is wrapped by send/edit errors when the request was stored in the
+// outbox and will be retried later
+var ErrQueued = errors.New("queued for retry")
+
+// serverErrorPattern matches Telegram 5xx responses, e.g.
+// "error response from telegram for method sendMessage, 502 Bad Gateway"
+var serverErrorPattern = regexp.MustCompile(`error response from telegram for method \w+, 5\d\d\b`)
+
+// SetOutbox enables the durable retry queue for sends and edits
+func (b *Bot) SetOutbox(outbox *state.Outbox) {
+	b.outboxMu.Lock()
+	defer b.outboxMu.Unlock()
+	b.outbox = outbox
+}
+
+// RunOutbox retries queued messages for this chat every interval until ctx is done
+func (b *Bot) RunOutbox(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.outboxMu.Lock()
+			if err := b.drainLocked(ctx); err != nil {
+				logger.Warn("Chat still unreachable, keeping the outbox", "chat", b.chatID, "class", ErrorClass(err), "error", err)
+			}
+			b.outboxMu.Unlock()
+		}
+	}
+}
+
+// deliver runs a send/edit through the rate limiter. With an outbox, earlier
+// queued entries for the chat go out first, and a transient failure queues
+// entry instead of losing it, unless ctx is WithoutOutbox. The lock is not
+// held during the send, so a flood wait does not hold up the chat's other
+// sends.
+func (b *Bot) deliver(ctx context.Context, entry state.OutboxEntry, send func() error) error {
+	b.outboxMu.Lock()
+	outbox := b.outbox
+	var err error
+	if outbox != nil {
+		err = b.drainLocked(ctx)
+	}
+	b.outboxMu.Unlock()
+
+	if outbox == nil {
+		return b.limiter.do(ctx, send)
+	}
+	if err == nil {
+		err = b.limiter.do(ctx, send)
+		if !isTransient(err) {
+			return err
+		}
+	}
+	if skipsOutbox(ctx) {
+		return err
+	}
+
+	entry.ChatID = b.chatID
+	b.outboxMu.Lock()
+	if pushErr := outbox.Push(entry); pushErr != nil {
+		logger.Error("Failed to persist outbox entry", "chat", b.chatID, "kind", entry.Kind, "error", pushErr)
+	}
+	pending := outbox.Pending(b.chatID)
+	b.outboxMu.Unlock()
+	logger.Warn("Queued in the outbox", "chat", b.chatID, "kind", entry.Kind, "pending", pending, "class", ErrorClass(err), "error", err)
+	return fmt.Errorf("%w: %w", ErrQueued, err)
+}
+
+// drainLocked replays queued entries for this chat in order, stopping at the
+// first transient failure. Entries Telegram rejects outright are dropped.
+func (b *Bot) drainLocked(ctx context.Context) error {
+	if b.outbox == nil {
+		return nil
+	}
+
+	for {
+		entry, ok := b.outbox.Next(b.chatID)
+		if !ok {
+			return nil
+		}
+
+		err := b.limiter.do(ctx, func() error { return b.replay(ctx, entry) })
+		if isTransient(err) {
+			return err
+		}
+		if err != nil {
+			logger.Warn("Dropping outbox entry", "chat", b.chatID, "kind", entry.Kind, "queued_at", entry.QueuedAt, "class", ErrorClass(err), "error", err)
+		} else {
+			logger.Info("Delivered outbox entry", "chat", b.chatID, "kind", entry.Kind, "queued_at", entry.QueuedAt)
+		}
+		if err := b.outbox.Done(b.chatID); err != nil {
+			logger.Error("Failed to persist outbox", "chat", b.chatID, "error", err)
+		}
+	}
+}
+
+// replay sends a queued entry to Telegram
+func (b *Bot) replay(ctx context.Context, entry state.OutboxEntry) error {
+	var parseMode models.ParseMode
+	if entry.HTML {
+		parseMode = models.ParseModeHTML
+	}
+
+	switch entry.Kind {
+	case state.OutboxEdit:
+		_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    b.chatID,
+			MessageID: entry.MessageID,
+			Text:      entry.Text,
+			ParseMode: parseMode,
+		})
+		return err
+	case state.OutboxSend:
+		params := &bot.SendMessageParams{
+			ChatID:    b.chatID,
+			Text:      entry.Text,
+			ParseMode: parseMode,
+		}
+		if entry.MessageID != 0 {
+			params.ReplyParameters = &models.ReplyParameters{
+				MessageID:                entry.MessageID,
+				AllowSendingWithoutReply: true,
+			}
+		}
+		_, err := b.bot.SendMessage(ctx, params)
+		return err
+	default:
+		return fmt.Errorf("unknown outbox entry kind %q", entry.Kind)
+	}
+}
+
+// isTransient reports whether a failed request may succeed later:
+// network errors, Telegram 5xx responses, and flood waits that outlasted retries
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var flood *bot.TooManyRequestsError
+	if errors.As(err, &flood) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	return serverErrorPattern.MatchString(msg) ||
+		strings.Contains(msg, "error read response body") ||
+		strings.Contains(msg, "error decode response body")
+}
@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// fakeTelegram records sendMessage/editMessageText calls and fails them with
+// 502 Bad Gateway while down is set
+type fakeTelegram struct {
+	mu    sync.Mutex
+	down  bool
+	calls []string
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseMultipartForm(1 << 20)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if f.down {
+		fmt.Fprint(w, `{"ok":false,"error_code":502,"description":"Bad Gateway"}`)
+		return
+	}
+	f.calls = append(f.calls, path.Base(r.URL.Path)+":"+r.FormValue("text"))
+	fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"chat":{"id":1}}}`, len(f.calls))
+}
+
+func (f *fakeTelegram) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func newTestBot(t *testing.T, server *httptest.Server) *Bot {
+	t.Helper()
+	api, err := bot.New("test-token", bot.WithServerURL(server.URL), bot.WithSkipGetMe())
+	require.NoError(t, err)
+	return &Bot{bot: api, chatID: 1, limiter: newRateLimiter(0)}
+}
+
+func TestOutboxQueuesAndReplaysInOrder(t *testing.T) {
+	fake := &fakeTelegram{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	outbox, err := state.LoadOutbox("")
+	require.NoError(t, err)
+	b := newTestBot(t, server)
+	b.SetOutbox(outbox)
+	ctx := context.Background()
+
+	fake.setDown(true)
+	_, err = b.SendMessage(ctx, "chunk 1")
+	assert.True(t, errors.Is(err, ErrQueued), "expected queued error, got %v", err)
+	err = b.EditMessage(ctx, 42, "final")
+	assert.True(t, errors.Is(err, ErrQueued), "expected queued error, got %v", err)
+	assert.Equal(t, 2, outbox.Pending(1))
+
+	fake.setDown(false)
+	msgID, err := b.SendMessage(ctx, "chunk 2")
+	require.NoError(t, err)
+	assert.Equal(t, 3, msgID)
+
+	assert.Equal(t, []string{"sendMessage:chunk 1", "editMessageText:final", "sendMessage:chunk 2"}, fake.calls)
+	assert.Equal(t, 0, outbox.Pending(1))
+}
+
+func TestOutboxDoesNotQueuePermanentErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: message is not modified"}`)
+	}))
+	defer server.Close()
+
+	outbox, err := state.LoadOutbox("")
+	require.NoError(t, err)
+	b := newTestBot(t, server)
+	b.SetOutbox(outbox)
+
+	err = b.EditMessage(context.Background(), 42, "same text")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrQueued))
+	assert.Equal(t, 0, outbox.Pending(1))
+}
+
+func TestOutboxSkippedWithoutOutbox(t *testing.T) {
+	fake := &fakeTelegram{down: true}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	outbox, err := state.LoadOutbox("")
+	require.NoError(t, err)
+	b := newTestBot(t, server)
+	b.SetOutbox(outbox)
+
+	_, err = b.SendMessage(WithoutOutbox(context.Background()), "⏳ Processing...")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrQueued))
+	assert.Equal(t, 0, outbox.Pending(1))
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, isTransient(&bot.TooManyRequestsError{RetryAfter: 3}))
+	assert.True(t, isTransient(errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")))
+	assert.False(t, isTransient(fmt.Errorf("%w, message is not modified", bot.ErrorBadRequest)))
+	assert.False(t, isTransient(context.Canceled))
+	assert.False(t, isTransient(nil))
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// webhookErrorWindow is how recent a delivery error reported by
+// getWebhookInfo must be for the webhook to count as failing
+const webhookErrorWindow = 5 * time.Minute
+
+// Probe checks that the Bot API answers. In webhook mode it calls
+// getWebhookInfo, which also tells whether Telegram could deliver updates
+// lately; otherwise getMe.
+func (b *Bot) Probe(ctx context.Context) error {
+	if !b.webhook.Load() {
+		if _, err := b.bot.GetMe(ctx); err != nil {
+			return fmt.Errorf("getMe: %w", err)
+		}
+		return nil
+	}
+
+	info, err := b.bot.GetWebhookInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("getWebhookInfo: %w", err)
+	}
+	if info.URL == "" {
+		return fmt.Errorf("webhook is not set")
+	}
+	lastError := time.Unix(int64(info.LastErrorDate), 0)
+	if info.LastErrorMessage != "" && time.Since(lastError) < webhookErrorWindow {
+		return fmt.Errorf("webhook delivery failed at %s: %s", lastError.Format(time.RFC3339), info.LastErrorMessage)
+	}
+	return nil
+}
+
+// RunProbe probes the Bot API right away and then every interval until ctx
+// is done, passing each result to report
+func (b *Bot) RunProbe(ctx context.Context, interval time.Duration, report func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, interval)
+		err := b.Probe(probeCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		report(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// LastSend returns when a request to the chat last succeeded (zero: never)
+func (b *Bot) LastSend() time.Time {
+	return b.limiter.lastSent()
+}
+
+// SendFailures returns how many requests to the chat failed in a row for
+// lack of a connection, flood control or because the bot was blocked;
+// requests Telegram rejected as invalid do not count
+func (b *Bot) SendFailures() int {
+	return b.limiter.failedInARow()
+}
@@ -0,0 +1,47 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbe(t *testing.T) {
+	var webhookInfo atomic.Value
+	webhookInfo.Store(`{"url":"https://bridge.example/tg"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch path.Base(r.URL.Path) {
+		case "getMe":
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Bridge"}}`)
+		case "getWebhookInfo":
+			fmt.Fprintf(w, `{"ok":true,"result":%s}`, webhookInfo.Load())
+		default:
+			fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`)
+		}
+	}))
+	defer server.Close()
+	b := newTestBot(t, server)
+	ctx := context.Background()
+
+	assert.NoError(t, b.Probe(ctx), "polling mode probes with getMe")
+
+	b.webhook.Store(true)
+	assert.NoError(t, b.Probe(ctx))
+	webhookInfo.Store(fmt.Sprintf(`{"url":"https://bridge.example/tg","last_error_date":%d,"last_error_message":"Connection refused"}`, time.Now().Unix()))
+	assert.ErrorContains(t, b.Probe(ctx), "Connection refused")
+	webhookInfo.Store(fmt.Sprintf(`{"url":"https://bridge.example/tg","last_error_date":%d,"last_error_message":"Connection refused"}`, time.Now().Add(-time.Hour).Unix()))
+	assert.NoError(t, b.Probe(ctx), "an old delivery error is ignored")
+
+	assert.True(t, b.LastSend().IsZero())
+	_, err := b.SendMessage(ctx, "hello")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), b.LastSend(), time.Second)
+}
@@ -0,0 +1,160 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+
+	"github.com/user/opencode-telegram/internal/metrics"
+)
+
+const (
+	// DefaultSendInterval keeps a chat at about one request per second,
+	// which is what Telegram recommends to avoid flood limits
+	DefaultSendInterval = time.Second
+
+	// maxFloodRetries is how many 429 responses a single call waits out
+	maxFloodRetries = 3
+)
+
+// rateLimiter paces outgoing requests to one chat and waits out
+// Telegram's flood control (429 retry_after) before retrying.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // earliest time the next request may go out
+	sent     time.Time // when a request last succeeded
+	failures int       // requests failed in a row since (see countsAsFailure)
+
+	// account labels the metrics of the chat's requests
+	account metrics.Account
+
+	// sleep waits for d or until ctx is done; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	return &rateLimiter{interval: interval, sleep: sleepContext}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (r *rateLimiter) setInterval(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interval = interval
+}
+
+// reserve claims the next send slot and returns how long to wait for it
+func (r *rateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	slot := r.next
+	if slot.Before(now) {
+		slot = now
+	}
+	r.next = slot.Add(r.interval)
+	return slot.Sub(now)
+}
+
+// waitTurn waits until the next send slot comes up, without claiming it
+func (r *rateLimiter) waitTurn(ctx context.Context) error {
+	r.mu.Lock()
+	wait := time.Until(r.next)
+	r.mu.Unlock()
+	return r.sleep(ctx, wait)
+}
+
+// pause holds back every request to this chat for d
+func (r *rateLimiter) pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(r.next) {
+		r.next = until
+	}
+}
+
+// lastSent returns when a request last succeeded
+func (r *rateLimiter) lastSent() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sent
+}
+
+// failedInARow returns how many requests failed since the last success
+func (r *rateLimiter) failedInARow() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures
+}
+
+// finish records the outcome of a request
+func (r *rateLimiter) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.sent = time.Now()
+		r.failures = 0
+	} else if countsAsFailure(err) {
+		r.failures++
+	}
+}
+
+// countsAsFailure tells whether a failed request says the chat cannot be
+// reached, rather than that the request itself was wrong
+func countsAsFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch ErrorClass(err) {
+	case ErrorClassNotModified, ErrorClassParse, ErrorClassBadRequest:
+		return false
+	}
+	return true
+}
+
+// do runs fn in its send slot, retrying after flood waits.
+// Other errors are returned unchanged.
+func (r *rateLimiter) do(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := r.sleep(ctx, r.reserve()); err != nil {
+			return err
+		}
+
+		err := fn()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			metrics.TelegramErrors.WithLabelValues(r.account.Name, r.account.Chat, ErrorClass(err)).Inc()
+		}
+		var flood *bot.TooManyRequestsError
+		if !errors.As(err, &flood) || attempt >= maxFloodRetries {
+			r.finish(err)
+			return err
+		}
+
+		wait := time.Duration(flood.RetryAfter) * time.Second
+		if wait <= 0 {
+			wait = time.Second
+		}
+		logger.Warn("Flood control, retrying", "account", r.account.Name, "chat", r.account.Chat, "wait", wait, "attempt", attempt+1, "max_attempts", maxFloodRetries)
+		metrics.TelegramFloodWaits.WithLabelValues(r.account.Name, r.account.Chat).Inc()
+		r.pause(wait)
+	}
+}
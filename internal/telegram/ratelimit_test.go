@@ -0,0 +1,128 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// recordingLimiter returns a limiter whose sleeps are recorded instead of waited
+func recordingLimiter(interval time.Duration) (*rateLimiter, *[]time.Duration) {
+	var waits []time.Duration
+	r := newRateLimiter(interval)
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return r, &waits
+}
+
+func TestRateLimiterPacesRequests(t *testing.T) {
+	r, waits := recordingLimiter(time.Second)
+
+	for i := 0; i < 3; i++ {
+		if err := r.do(context.Background(), func() error { return nil }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if (*waits)[0] != 0 {
+		t.Errorf("first request should go out immediately, waited %s", (*waits)[0])
+	}
+	if w := (*waits)[2]; w < 1900*time.Millisecond || w > 2*time.Second {
+		t.Errorf("third request should wait ~2s, waited %s", w)
+	}
+}
+
+func TestRateLimiterRetriesFloodWait(t *testing.T) {
+	r, waits := recordingLimiter(0)
+
+	calls := 0
+	err := r.do(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return &bot.TooManyRequestsError{Message: "Too Many Requests", RetryAfter: 5}
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+	if w := (*waits)[1]; w < 4900*time.Millisecond || w > 5*time.Second {
+		t.Errorf("retry should wait out retry_after (~5s), waited %s", w)
+	}
+}
+
+func TestRateLimiterGivesUpAfterMaxRetries(t *testing.T) {
+	r, _ := recordingLimiter(0)
+
+	calls := 0
+	err := r.do(context.Background(), func() error {
+		calls++
+		return &bot.TooManyRequestsError{Message: "Too Many Requests", RetryAfter: 1}
+	})
+
+	if !bot.IsTooManyRequestsError(err) {
+		t.Fatalf("expected flood error, got %v", err)
+	}
+	if calls != maxFloodRetries+1 {
+		t.Errorf("expected %d calls, got %d", maxFloodRetries+1, calls)
+	}
+}
+
+func TestRateLimiterDoesNotRetryOtherErrors(t *testing.T) {
+	r, _ := recordingLimiter(0)
+
+	calls := 0
+	want := errors.New("bad request: message is not modified")
+	err := r.do(context.Background(), func() error {
+		calls++
+		return want
+	})
+
+	if !errors.Is(err, want) || calls != 1 {
+		t.Errorf("expected a single call returning %v, got %d calls, %v", want, calls, err)
+	}
+}
+
+func TestRateLimiterStopsOnCancelledContext(t *testing.T) {
+	r, _ := recordingLimiter(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := r.do(ctx, func() error {
+		called = true
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("expected context.Canceled without calling fn, got %v (called=%v)", err, called)
+	}
+}
+
+func TestRateLimiterCountsFailuresInARow(t *testing.T) {
+	r, _ := recordingLimiter(0)
+	ctx := context.Background()
+	down := errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")
+
+	r.do(ctx, func() error { return down })
+	r.do(ctx, func() error { return down })
+	r.do(ctx, func() error { return fmt.Errorf("%w, Bad Request: message is not modified", bot.ErrorBadRequest) })
+	if n := r.failedInARow(); n != 2 {
+		t.Errorf("expected 2 failures in a row, got %d", n)
+	}
+
+	r.do(ctx, func() error { return nil })
+	if n := r.failedInARow(); n != 0 {
+		t.Errorf("expected a success to reset the failures, got %d", n)
+	}
+}
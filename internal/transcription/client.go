@@ -0,0 +1,111 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the OpenAI API, used when only an API key is configured
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Config holds transcription backend settings.
+// Any server implementing the OpenAI /audio/transcriptions endpoint works
+// (OpenAI, Groq, a local whisper.cpp / faster-whisper server, ...).
+type Config struct {
+	BaseURL  string
+	APIKey   string
+	Model    string
+	Language string // optional ISO-639-1 hint, empty lets the backend detect
+}
+
+// Client sends audio to an OpenAI-compatible transcription endpoint
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// NewClient creates a new transcription client
+func NewClient(config Config) *Client {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.Model == "" {
+		config.Model = "whisper-1"
+	}
+
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			// Long recordings can take a while to transcribe
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+type transcriptionResponse struct {
+	Text string `json:"text"`
+}
+
+// Transcribe uploads audio data and returns the recognised text.
+// filename is passed through so the backend can detect the format from its extension.
+func (c *Client) Transcribe(ctx context.Context, filename string, data []byte) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("write audio data: %w", err)
+	}
+	if err := writer.WriteField("model", c.config.Model); err != nil {
+		return "", fmt.Errorf("write model field: %w", err)
+	}
+	if c.config.Language != "" {
+		if err := writer.WriteField("language", c.config.Language); err != nil {
+			return "", fmt.Errorf("write language field: %w", err)
+		}
+	}
+	if err := writer.WriteField("response_format", "json"); err != nil {
+		return "", fmt.Errorf("write response_format field: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("create transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("transcription failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result transcriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode transcription response: %w", err)
+	}
+
+	return strings.TrimSpace(result.Text), nil
+}
@@ -0,0 +1,53 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/audio/transcriptions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		assert.Equal(t, "en", r.FormValue("language"))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		assert.Equal(t, "meeting.m4a", header.Filename)
+		assert.Equal(t, "audio-bytes", string(data))
+
+		w.Write([]byte(`{"text": " Hello from the meeting. "}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL + "/v1/", APIKey: "sk-test", Language: "en"})
+	text, err := client.Transcribe(context.Background(), "meeting.m4a", []byte("audio-bytes"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello from the meeting.", text)
+}
+
+func TestTranscribeErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid key"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	_, err := client.Transcribe(context.Background(), "voice.ogg", []byte("x"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// SetToken requires every webhook request to carry
+// "Authorization: Bearer <token>" (empty: no token needed). It can be
+// called while serving, to rotate the token.
+func (s *Server) SetToken(token string) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	s.token = token
+}
+
+func (s *Server) currentToken() string {
+	s.tokenMu.RLock()
+	defer s.tokenMu.RUnlock()
+	return s.token
+}
+
+// SetAllowedNetworks only accepts webhook requests from these networks
+// (empty: any source)
+func (s *Server) SetAllowedNetworks(networks []netip.Prefix) {
+	s.allowed = networks
+}
+
+// ParseNetworks parses a comma-separated list of CIDRs; plain addresses
+// stand for a single host
+func ParseNetworks(list string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+			}
+			networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// authorize rejects requests from outside the allowed networks or without
+// the token. Only the connection's address counts: forwarding headers are
+// set by the client and prove nothing.
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(s.allowed) > 0 && !s.allowedAddr(r) {
+			logger.Warn("Rejected request: address not allowed", "remote", r.RemoteAddr)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if want := s.currentToken(); want != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+				logger.Warn("Rejected request: missing or wrong token", "remote", r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) allowedAddr(r *http.Request) bool {
+	addr, ok := remoteAddr(r)
+	if !ok {
+		return false
+	}
+	for _, network := range s.allowed {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
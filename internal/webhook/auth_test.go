@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks(" 10.0.0.0/8, 172.18.0.5 ,fd00::/8,")
+	if err != nil {
+		t.Fatalf("ParseNetworks() error = %v", err)
+	}
+	want := []string{"10.0.0.0/8", "172.18.0.5/32", "fd00::/8"}
+	if len(networks) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, networks)
+	}
+	for i, network := range networks {
+		if network.String() != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], network)
+		}
+	}
+
+	if _, err := ParseNetworks("10.0.0.0/33"); err == nil {
+		t.Error("Expected an error for an invalid CIDR")
+	}
+	if _, err := ParseNetworks("localhost"); err == nil {
+		t.Error("Expected an error for a host name")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	networks, _ := ParseNetworks("172.18.0.0/16")
+	s := NewServer(":0", nil)
+	s.SetToken("secret")
+	s.SetAllowedNetworks(networks)
+	handler := s.authorize(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		auth       string
+		want       int
+	}{
+		{"allowed with token", "172.18.0.5:40000", "Bearer secret", http.StatusOK},
+		{"IPv4-mapped address", "[::ffff:172.18.0.5]:40000", "Bearer secret", http.StatusOK},
+		{"wrong token", "172.18.0.5:40000", "Bearer nope", http.StatusUnauthorized},
+		{"missing token", "172.18.0.5:40000", "", http.StatusUnauthorized},
+		{"outside network", "10.1.2.3:40000", "Bearer secret", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "172.18.0.5")
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRotateToken(t *testing.T) {
+	s := NewServer(":0", nil)
+	s.SetToken("old")
+	handler := s.authorize(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	status := func(auth string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+		req.Header.Set("Authorization", auth)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	s.SetToken("new")
+	if got := status("Bearer old"); got != http.StatusUnauthorized {
+		t.Errorf("Expected the old token rejected after a rotation, got %d", got)
+	}
+	if got := status("Bearer new"); got != http.StatusOK {
+		t.Errorf("Expected the new token accepted, got %d", got)
+	}
+}
+
+func TestAuthorizeOpenByDefault(t *testing.T) {
+	handler := NewServer(":0", nil).authorize(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 without auth settings, got %d", rec.Code)
+	}
+}
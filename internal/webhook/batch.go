@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Per-event statuses of a batch request
+const (
+	batchQueued    = "queued"     // handed to a worker
+	batchOK        = "ok"         // handled before answering (no workers)
+	batchInvalid   = "invalid"    // not a known event; sending it again will not help
+	batchQueueFull = "queue_full" // refused; send it again later
+)
+
+// batchResult is the outcome of one event of a batch request
+type batchResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// isBatch reports whether a webhook request body is an array of events
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// handleBatch accepts an array of webhook events, so the plugin can buffer
+// events during bursts and flush them in one request. Events are taken in
+// order and each gets its own status, in the order sent.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request, body []byte) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		logger.Warn("Failed to decode batch", "remote", r.RemoteAddr, "error", err)
+		http.Error(w, "Invalid event format", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]batchResult, len(items))
+	// Once a session's event is refused, its later events are refused too,
+	// so they cannot overtake it when the plugin sends it again
+	refusedSessions := make(map[string]bool)
+	refused := 0
+	for i, item := range items {
+		event, err := s.decode(item)
+		if err != nil {
+			logger.Warn("Invalid batch event", "index", i, "error", err)
+			results[i] = batchResult{Status: batchInvalid, Error: err.Error()}
+			continue
+		}
+		if refusedSessions[event.SessionID()] {
+			results[i] = batchResult{Status: batchQueueFull}
+			refused++
+			continue
+		}
+		switch err := s.enqueue(*event); {
+		case err == nil:
+			results[i] = batchResult{Status: batchQueued}
+		case errors.Is(err, errQueueFull):
+			results[i] = batchResult{Status: batchQueueFull}
+			refusedSessions[event.SessionID()] = true
+			refused++
+		default:
+			s.publisher.Publish(*event)
+			results[i] = batchResult{Status: batchOK}
+		}
+	}
+	if refused > 0 {
+		logger.Warn("Queue full, refused batched events", "refused", refused, "total", len(items))
+		w.Header().Set("Retry-After", "1")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
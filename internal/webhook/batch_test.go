@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+func postBatch(t *testing.T, s *Server, body string) (*httptest.ResponseRecorder, []batchResult) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Results []batchResult `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return rec, resp.Results
+}
+
+func TestBatchReportsEachEvent(t *testing.T) {
+	var sessions []string
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) {
+		sessions = append(sessions, event.SessionID())
+	}))
+	s.SetWorkers(0)
+
+	_, results := postBatch(t, s, ` [
+		{"type":"session.idle","data":{"sessionId":"ses_a"}},
+		{"type":"session.unknown","data":{}},
+		{"type":"session.idle","data":{"sessionId":"ses_b"}}
+	]`)
+
+	want := []string{batchOK, batchInvalid, batchOK}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %+v", len(want), results)
+	}
+	for i, status := range want {
+		if results[i].Status != status {
+			t.Errorf("Event %d: expected %s, got %+v", i, status, results[i])
+		}
+	}
+	if results[1].Error == "" {
+		t.Error("Expected the invalid event to carry an error")
+	}
+	if len(sessions) != 2 || sessions[0] != "ses_a" || sessions[1] != "ses_b" {
+		t.Errorf("Expected ses_a then ses_b published, got %v", sessions)
+	}
+}
+
+func TestBatchRefusesRestOfSessionWhenFull(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) { <-block }))
+	s.SetWorkers(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.startWorkers(ctx)
+
+	var items []string
+	for i := 0; i < workerQueueSize+3; i++ {
+		items = append(items, fmt.Sprintf(`{"type":"message.part.updated","data":{"sessionId":"ses_a","delta":"%d"}}`, i))
+	}
+	rec, results := postBatch(t, s, "["+strings.Join(items, ",")+"]")
+
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After when events are refused")
+	}
+	refused := false
+	for i, result := range results {
+		switch result.Status {
+		case batchQueueFull:
+			refused = true
+		case batchQueued:
+			if refused {
+				t.Fatalf("Event %d queued after an earlier one was refused", i)
+			}
+		default:
+			t.Fatalf("Event %d: unexpected %+v", i, result)
+		}
+	}
+	if !refused {
+		t.Error("Expected events to be refused once the queue is full")
+	}
+}
+
+func TestBatchRejectsMalformedArray(t *testing.T) {
+	s := NewServer(":0", publisherFunc(func(opencode.Event) {}))
+	rec := httptest.NewRecorder()
+	s.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`[{"type":`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
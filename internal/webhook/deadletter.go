@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// SetDeadLetters keeps events whose handling failed, so /webhook/replay can
+// inject them again once Telegram or OpenCode is back
+func (s *Server) SetDeadLetters(store *state.DeadLetters) {
+	s.deadLetters = store
+}
+
+// DeadLetter stores a webhook event whose handling failed; meant as the
+// event bus's failure handler. Events from other sources are ignored.
+func (s *Server) DeadLetter(event opencode.Event, err error) {
+	if s.deadLetters == nil || event.Raw == nil {
+		return
+	}
+	if saveErr := s.deadLetters.Add(event.Raw, err); saveErr != nil {
+		logger.Error("Failed to save dead letter", "type", event.Type, "session", event.SessionID(), "error", saveErr)
+		return
+	}
+	logger.Warn("Saved event as a dead letter", "type", event.Type, "session", event.SessionID(), "error", err)
+}
+
+// handleReplay lists the dead letters (GET) or publishes them again and
+// clears the store (POST). Events that fail again are stored again.
+func (s *Server) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if s.deadLetters == nil {
+		http.Error(w, "Dead letters are disabled", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"dead_letters": s.deadLetters.List()})
+
+	case http.MethodPost:
+		letters, err := s.deadLetters.Take()
+		if err != nil {
+			logger.Error("Failed to clear dead letters", "error", err)
+		}
+		replayed := 0
+		for _, letter := range letters {
+			if err := s.publish(letter.Payload); err != nil {
+				logger.Warn("Dropping dead letter that cannot be replayed", "error", err)
+				continue
+			}
+			replayed++
+		}
+		logger.Info("Replayed dead letters", "replayed", replayed, "total", len(letters))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int{"replayed": replayed, "dropped": len(letters) - replayed})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
+)
+
+// publisherFunc adapts a function to opencode.Publisher
+type publisherFunc func(event opencode.Event)
+
+func (f publisherFunc) Publish(event opencode.Event) { f(event) }
+
+func TestDeadLetterReplay(t *testing.T) {
+	var published []opencode.Event
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) {
+		published = append(published, event)
+	}))
+	store, _ := state.LoadDeadLetters("")
+	s.SetDeadLetters(store)
+
+	body := `{"type":"session.idle","data":{"sessionId":"ses_1","content":"done"},"timestamp":1700000000000}`
+	rec := httptest.NewRecorder()
+	s.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || len(published) != 1 {
+		t.Fatalf("Expected the event to be published, got status %d and %d events", rec.Code, len(published))
+	}
+	if string(published[0].Raw) != body {
+		t.Fatalf("Expected the raw payload to be kept, got %q", published[0].Raw)
+	}
+
+	// Handling failed downstream
+	s.DeadLetter(published[0], errors.New("telegram down"))
+	s.DeadLetter(opencode.Event{Type: "session.idle"}, errors.New("not from the webhook"))
+	if n := len(store.List()); n != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", n)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleReplay(rec, httptest.NewRequest(http.MethodPost, "/webhook/replay", nil))
+	var result map[string]int
+	json.NewDecoder(rec.Body).Decode(&result)
+	if result["replayed"] != 1 || result["dropped"] != 0 {
+		t.Fatalf("Unexpected replay result %v", result)
+	}
+	if len(published) != 2 || published[1].SessionID() != "ses_1" {
+		t.Fatalf("Expected the dead letter to be published again, got %d events", len(published))
+	}
+	if n := len(store.List()); n != 0 {
+		t.Errorf("Expected the store to be empty after replay, got %d", n)
+	}
+}
+
+func TestReplayWithoutDeadLetters(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewServer(":0", nil).handleReplay(rec, httptest.NewRequest(http.MethodPost, "/webhook/replay", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a dead letter store, got %d", rec.Code)
+	}
+}
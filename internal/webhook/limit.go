@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxBodyBytes caps a webhook request; completed responses are
+	// the largest events and stay far below it
+	DefaultMaxBodyBytes = 10 << 20
+
+	// DefaultRateLimit is the requests per second one client address may
+	// send, with bursts of twice as many. Streaming deltas are the busiest
+	// traffic.
+	DefaultRateLimit = 100
+
+	// idleBucketTTL is how long an idle client's bucket is kept
+	idleBucketTTL = time.Minute
+)
+
+// SetMaxBodyBytes caps the size of webhook requests (0: no limit)
+func (s *Server) SetMaxBodyBytes(n int64) {
+	s.maxBodyBytes = n
+}
+
+// SetRateLimit limits each client address to perSecond webhook requests
+// (0: no limit)
+func (s *Server) SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		s.limiter = nil
+		return
+	}
+	s.limiter = newAddrLimiter(perSecond, 2*perSecond)
+}
+
+// limit rejects clients over the rate limit and caps the body size
+func (s *Server) limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter != nil {
+			if addr, ok := remoteAddr(r); ok && !s.limiter.allow(addr, time.Now()) {
+				logger.Warn("Rate limit exceeded", "remote", addr)
+				w.Header().Set("Retry-After", strconv.Itoa(1))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+		}
+		if s.maxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		}
+		next(w, r)
+	}
+}
+
+// isTooLarge reports whether reading a body failed on the size cap
+func isTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// remoteAddr returns the address of the connecting client
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true // IPv4 clients on a dual-stack listener
+}
+
+// addrLimiter is a token bucket per client address
+type addrLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[netip.Addr]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newAddrLimiter(rate, burst float64) *addrLimiter {
+	return &addrLimiter{rate: rate, burst: burst, buckets: make(map[netip.Addr]*bucket)}
+}
+
+// allow takes a token from addr's bucket, reporting whether one was left
+func (l *addrLimiter) allow(addr netip.Addr, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleBucketTTL {
+		for a, b := range l.buckets {
+			if now.Sub(b.last) > idleBucketTTL {
+				delete(l.buckets, a)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[addr]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[addr] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+func TestAddrLimiter(t *testing.T) {
+	l := newAddrLimiter(1, 2)
+	a := netip.MustParseAddr("10.0.0.1")
+	b := netip.MustParseAddr("10.0.0.2")
+	now := time.Now()
+
+	if !l.allow(a, now) || !l.allow(a, now) {
+		t.Fatal("Expected the burst to be allowed")
+	}
+	if l.allow(a, now) {
+		t.Error("Expected the third request in a burst to be refused")
+	}
+	if !l.allow(b, now) {
+		t.Error("Expected another address to have its own bucket")
+	}
+	if !l.allow(a, now.Add(time.Second)) {
+		t.Error("Expected a token to come back after a second")
+	}
+
+	// Idle buckets are swept
+	l.allow(b, now.Add(2*idleBucketTTL))
+	if _, ok := l.buckets[a]; ok {
+		t.Error("Expected the idle bucket to be dropped")
+	}
+}
+
+func TestLimitRejectsFloodsAndLargeBodies(t *testing.T) {
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) {}))
+	s.SetRateLimit(1)
+	s.SetMaxBodyBytes(64)
+	handler := s.limit(s.handleWebhook)
+
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:5000"
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	if code := post(strings.Repeat("x", 100)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a large body, got %d", code)
+	}
+	if code := post(`{"type":"session.idle","data":{"sessionId":"s"}}`); code != http.StatusOK {
+		t.Errorf("Expected 200 within the limit, got %d", code)
+	}
+	if code := post(`{"type":"session.idle","data":{"sessionId":"s"}}`); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 over the limit, got %d", code)
+	}
+}
@@ -2,35 +2,61 @@ package webhook
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"net/netip"
+	"sync"
 	"time"
 
+	"github.com/user/opencode-telegram/internal/httplog"
+	"github.com/user/opencode-telegram/internal/logging"
 	"github.com/user/opencode-telegram/internal/opencode"
+	"github.com/user/opencode-telegram/internal/state"
 )
 
+var logger = logging.For("webhook")
+
 type WebhookEvent struct {
 	Type      string          `json:"type"`
 	Data      json.RawMessage `json:"data"`
 	Timestamp int64           `json:"timestamp"`
 }
 
-type EventHandler interface {
-	HandleSSEEvent(event opencode.Event)
-}
-
 type Server struct {
-	addr    string
-	handler EventHandler
-	server  *http.Server
+	addr      string
+	publisher opencode.Publisher
+	server    *http.Server
+
+	tokenMu sync.RWMutex
+	token   string         // required bearer token (empty: none)
+	allowed []netip.Prefix // accepted source networks (empty: any)
+
+	deadLetters *state.DeadLetters // failed events for /webhook/replay (nil: dropped)
+	tlsConfig   *tls.Config        // HTTPS (nil: plain HTTP)
+
+	maxBodyBytes int64        // request size cap (0: none)
+	limiter      *addrLimiter // per-client rate limit (nil: none)
+
+	workers   int // publishing workers (0: publish inline)
+	queuesMu  sync.RWMutex
+	queues    []chan opencode.Event // one per worker, once started
+	workersWG sync.WaitGroup
+
+	serverMu sync.Mutex // guards server, set by Start
 }
 
-func NewServer(addr string, handler EventHandler) *Server {
+// NewServer creates the plugin webhook server; received events go to publisher
+func NewServer(addr string, publisher opencode.Publisher) *Server {
 	return &Server{
-		addr:    addr,
-		handler: handler,
+		addr:         addr,
+		publisher:    publisher,
+		maxBodyBytes: DefaultMaxBodyBytes,
+		limiter:      newAddrLimiter(DefaultRateLimit, 2*DefaultRateLimit),
+		workers:      DefaultWorkers,
 	}
 }
 
@@ -40,26 +66,79 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var event WebhookEvent
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		log.Printf("[WEBHOOK] Failed to decode event: %v", err)
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	body, err := io.ReadAll(r.Body)
+	if isTooLarge(err) {
+		logger.Warn("Rejected event", "remote", r.RemoteAddr, "error", err)
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
 		return
 	}
-
-	log.Printf("[WEBHOOK] Received event: type=%s, timestamp=%d", event.Type, event.Timestamp)
-
-	sseEvent, err := s.convertToSSEEvent(event)
 	if err != nil {
-		log.Printf("[WEBHOOK] Failed to convert event: %v", err)
+		logger.Warn("Failed to read event", "remote", r.RemoteAddr, "error", err)
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if isBatch(body) {
+		s.handleBatch(w, r, body)
+		return
+	}
+	event, err := s.decode(body)
+	if err != nil {
+		logger.Warn("Invalid event", "remote", r.RemoteAddr, "error", err)
 		http.Error(w, "Invalid event format", http.StatusBadRequest)
 		return
 	}
 
-	s.handler.HandleSSEEvent(*sseEvent)
+	// Answer as soon as a worker has the event, so slow Telegram sends do
+	// not hold up the plugin
+	switch err := s.enqueue(*event); {
+	case err == nil:
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "queued"})
+	case errors.Is(err, errQueueFull):
+		logger.Warn("Queue full, refusing event", "type", event.Type, "session", event.SessionID())
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Event queue full", http.StatusServiceUnavailable)
+	default:
+		s.publisher.Publish(*event)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
+}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+// publish decodes a webhook event and hands it to the publisher
+func (s *Server) publish(body []byte) error {
+	event, err := s.decode(body)
+	if err != nil {
+		return err
+	}
+	s.publisher.Publish(*event)
+	return nil
+}
+
+// decode parses a webhook request into an event
+func (s *Server) decode(body []byte) (*opencode.Event, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	logger.Debug("Received event", "type", event.Type, "timestamp", event.Timestamp)
+
+	sseEvent, err := s.convertToSSEEvent(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert event: %w", err)
+	}
+	sseEvent.Raw = body
+	return sseEvent, nil
+}
+
+// eventTime converts a webhook timestamp in milliseconds, leaving it zero
+// when the payload has none
+func eventTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
 }
 
 func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error) {
@@ -76,7 +155,42 @@ func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error
 		return &opencode.Event{
 			Type:       "session.created",
 			Properties: nil,
-			Timestamp:  time.Unix(0, webhook.Timestamp*1e6),
+			Timestamp:  eventTime(webhook.Timestamp),
+		}, nil
+
+	case "session.updated", "session.deleted":
+		// Either the OpenCode session info as is, or its identifying fields
+		var data struct {
+			SessionID string                 `json:"sessionId"`
+			Title     string                 `json:"title"`
+			ParentID  string                 `json:"parentId"`
+			Info      map[string]interface{} `json:"info"`
+		}
+		if err := json.Unmarshal(webhook.Data, &data); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", webhook.Type, err)
+		}
+
+		info := data.Info
+		if info == nil {
+			info = make(map[string]interface{})
+		}
+		for key, value := range map[string]string{
+			"id":       data.SessionID,
+			"title":    data.Title,
+			"parentID": data.ParentID,
+		} {
+			if _, ok := info[key]; !ok && value != "" {
+				info[key] = value
+			}
+		}
+		if _, ok := info["id"].(string); !ok {
+			return nil, fmt.Errorf("%s without sessionId", webhook.Type)
+		}
+
+		return &opencode.Event{
+			Type:       webhook.Type,
+			Properties: map[string]interface{}{"info": info},
+			Timestamp:  eventTime(webhook.Timestamp),
 		}, nil
 
 	case "message.updated":
@@ -141,15 +255,57 @@ func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error
 					Content: data.Content,
 				},
 			},
-			Timestamp: time.Unix(0, webhook.Timestamp*1e6),
+			Timestamp: eventTime(webhook.Timestamp),
 		}
 
 		if data.Content != nil {
-			log.Printf("[WEBHOOK] Message content received, length=%d", len(*data.Content))
+			logger.Debug("Message content received", "session", data.SessionID, "length", len(*data.Content))
 		}
 
 		return event, nil
 
+	case "message.part.updated":
+		// Either the OpenCode part as is, or its identifying fields
+		var data struct {
+			SessionID string                 `json:"sessionId"`
+			MessageID string                 `json:"messageId"`
+			PartID    string                 `json:"partId"`
+			PartType  string                 `json:"partType"`
+			Delta     *string                `json:"delta"`
+			Part      map[string]interface{} `json:"part"`
+		}
+		if err := json.Unmarshal(webhook.Data, &data); err != nil {
+			return nil, fmt.Errorf("unmarshal message.part.updated: %w", err)
+		}
+
+		part := data.Part
+		if part == nil {
+			part = make(map[string]interface{})
+		}
+		for key, value := range map[string]string{
+			"sessionID": data.SessionID,
+			"messageID": data.MessageID,
+			"id":        data.PartID,
+			"type":      data.PartType,
+		} {
+			if _, ok := part[key]; !ok && value != "" {
+				part[key] = value
+			}
+		}
+		if _, ok := part["sessionID"].(string); !ok {
+			return nil, fmt.Errorf("message.part.updated without sessionId")
+		}
+
+		evt := &opencode.EventMessagePartUpdated{Type: "message.part.updated"}
+		evt.Properties.Part = part
+		evt.Properties.Delta = data.Delta
+
+		return &opencode.Event{
+			Type:       "message.part.updated",
+			Properties: evt,
+			Timestamp:  eventTime(webhook.Timestamp),
+		}, nil
+
 	case "session.idle":
 		var data struct {
 			SessionID    string  `json:"sessionId"`
@@ -161,7 +317,7 @@ func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error
 		}
 
 		if data.Content != nil {
-			log.Printf("[WEBHOOK] Session idle content received, session=%s, length=%d", data.SessionID, len(*data.Content))
+			logger.Debug("Session idle content received", "session", data.SessionID, "length", len(*data.Content))
 		}
 
 		return &opencode.Event{
@@ -176,7 +332,7 @@ func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error
 					Content:   data.Content,
 				},
 			},
-			Timestamp: time.Unix(0, webhook.Timestamp*1e6),
+			Timestamp: eventTime(webhook.Timestamp),
 		}, nil
 
 	case "question.asked":
@@ -185,13 +341,12 @@ func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error
 			return nil, fmt.Errorf("unmarshal question.asked: %w", err)
 		}
 
-		log.Printf("[WEBHOOK] question.asked received, requestID=%s, sessionID=%s, questions=%d",
-			evt.Properties.ID, evt.Properties.SessionID, len(evt.Properties.Questions))
+		logger.Info("question.asked received", "request", evt.Properties.ID, "session", evt.Properties.SessionID, "questions", len(evt.Properties.Questions))
 
 		return &opencode.Event{
 			Type:       "question.asked",
 			Properties: &evt,
-			Timestamp:  time.Unix(0, webhook.Timestamp*1e6),
+			Timestamp:  eventTime(webhook.Timestamp),
 		}, nil
 
 	case "permission.asked":
@@ -200,12 +355,33 @@ func (s *Server) convertToSSEEvent(webhook WebhookEvent) (*opencode.Event, error
 			return nil, fmt.Errorf("unmarshal permission.asked: %w", err)
 		}
 
-		log.Printf("[WEBHOOK] permission.asked received, permissionID=%s", evt.Properties.ID)
+		logger.Info("permission.asked received", "permission", evt.Properties.ID, "session", evt.Properties.SessionID)
 
 		return &opencode.Event{
 			Type:       "permission.asked",
 			Properties: &evt,
-			Timestamp:  time.Unix(0, webhook.Timestamp*1e6),
+			Timestamp:  eventTime(webhook.Timestamp),
+		}, nil
+
+	case "permission.replied", "question.replied", "question.rejected":
+		// Answered outside Telegram: closes the prompt in the chat
+		var props interface{}
+		switch webhook.Type {
+		case "permission.replied":
+			props = &opencode.EventPermissionReplied{}
+		case "question.replied":
+			props = &opencode.EventQuestionReplied{}
+		default:
+			props = &opencode.EventQuestionRejected{}
+		}
+		if err := json.Unmarshal(webhook.Data, props); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", webhook.Type, err)
+		}
+
+		return &opencode.Event{
+			Type:       webhook.Type,
+			Properties: props,
+			Timestamp:  eventTime(webhook.Timestamp),
 		}, nil
 
 	default:
@@ -222,25 +398,53 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) Start(ctx context.Context) error {
+	s.startWorkers(ctx)
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook", s.handleWebhook)
+	mux.HandleFunc("/webhook", s.limit(s.authorize(s.handleWebhook)))
+	mux.HandleFunc("/webhook/replay", s.limit(s.authorize(s.handleReplay)))
 	mux.HandleFunc("/health", s.handleHealth)
 
-	s.server = &http.Server{
-		Addr:    s.addr,
-		Handler: mux,
+	server := &http.Server{
+		Addr:      s.addr,
+		Handler:   httplog.Handler("webhook", mux),
+		TLSConfig: s.tlsConfig,
 	}
+	s.serverMu.Lock()
+	s.server = server
+	s.serverMu.Unlock()
 
 	go func() {
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		s.server.Shutdown(shutdownCtx)
+		server.Shutdown(shutdownCtx)
 	}()
 
-	log.Printf("[WEBHOOK] Starting webhook server on %s", s.addr)
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	var err error
+	if s.tlsConfig != nil {
+		logger.Info("Starting webhook server (HTTPS)", "addr", s.addr)
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		logger.Info("Starting webhook server", "addr", s.addr)
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("webhook server error: %w", err)
 	}
 	return nil
 }
+
+// Drain stops accepting requests, waits for those in progress, and lets the
+// workers publish every queued event
+func (s *Server) Drain(ctx context.Context) error {
+	s.serverMu.Lock()
+	server := s.server
+	s.serverMu.Unlock()
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			return fmt.Errorf("stop webhook server: %w", err)
+		}
+	}
+	return s.stopWorkers(ctx)
+}
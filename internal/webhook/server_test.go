@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+func TestConvertMessagePartUpdated(t *testing.T) {
+	s := NewServer(":0", nil)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"fields", `{"sessionId":"ses_1","messageId":"msg_1","partId":"prt_1","partType":"text","delta":"Hel"}`},
+		{"part", `{"part":{"id":"prt_1","sessionID":"ses_1","messageID":"msg_1","type":"text","text":"Hel"},"delta":"Hel"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := s.convertToSSEEvent(WebhookEvent{Type: "message.part.updated", Data: json.RawMessage(tt.data)})
+			if err != nil {
+				t.Fatalf("convertToSSEEvent() error = %v", err)
+			}
+			evt, ok := event.Properties.(*opencode.EventMessagePartUpdated)
+			if !ok {
+				t.Fatalf("Expected *EventMessagePartUpdated, got %T", event.Properties)
+			}
+			if evt.Properties.Delta == nil || *evt.Properties.Delta != "Hel" {
+				t.Errorf("Expected delta %q, got %v", "Hel", evt.Properties.Delta)
+			}
+			part := evt.Properties.Part.(map[string]interface{})
+			if part["type"] != "text" || part["messageID"] != "msg_1" || part["id"] != "prt_1" {
+				t.Errorf("Unexpected part %v", part)
+			}
+			if got := event.SessionID(); got != "ses_1" {
+				t.Errorf("Expected session ses_1, got %q", got)
+			}
+		})
+	}
+
+	if _, err := s.convertToSSEEvent(WebhookEvent{Type: "message.part.updated", Data: json.RawMessage(`{"delta":"x"}`)}); err == nil {
+		t.Error("Expected an error without a session")
+	}
+}
+
+func TestConvertAnsweredElsewhere(t *testing.T) {
+	s := NewServer(":0", nil)
+
+	event, err := s.convertToSSEEvent(WebhookEvent{Type: "permission.replied", Data: json.RawMessage(`{"properties":{"sessionID":"ses_1","requestID":"perm_1","reply":"once"}}`)})
+	if err != nil {
+		t.Fatalf("convertToSSEEvent() error = %v", err)
+	}
+	replied, ok := event.Properties.(*opencode.EventPermissionReplied)
+	if !ok || replied.Properties.RequestID != "perm_1" || replied.Properties.Reply != opencode.PermissionOnce {
+		t.Errorf("Unexpected permission.replied %#v", event.Properties)
+	}
+
+	event, err = s.convertToSSEEvent(WebhookEvent{Type: "question.rejected", Data: json.RawMessage(`{"properties":{"sessionID":"ses_1","requestID":"que_1"}}`)})
+	if err != nil {
+		t.Fatalf("convertToSSEEvent() error = %v", err)
+	}
+	if got := event.SessionID(); got != "ses_1" {
+		t.Errorf("Expected session ses_1, got %q", got)
+	}
+}
+
+func TestConvertSessionLifecycle(t *testing.T) {
+	s := NewServer(":0", nil)
+
+	event, err := s.convertToSSEEvent(WebhookEvent{Type: "session.deleted", Data: json.RawMessage(`{"sessionId":"ses_1","title":"Old"}`)})
+	if err != nil {
+		t.Fatalf("convertToSSEEvent() error = %v", err)
+	}
+	if got := event.SessionID(); got != "ses_1" {
+		t.Errorf("Expected session ses_1, got %q", got)
+	}
+
+	event, err = s.convertToSSEEvent(WebhookEvent{Type: "session.updated", Data: json.RawMessage(`{"info":{"id":"ses_2","parentID":"ses_1"}}`)})
+	if err != nil {
+		t.Fatalf("convertToSSEEvent() error = %v", err)
+	}
+	if got := event.ParentSessionID(); got != "ses_1" {
+		t.Errorf("Expected parent ses_1, got %q", got)
+	}
+
+	if _, err := s.convertToSSEEvent(WebhookEvent{Type: "session.updated", Data: json.RawMessage(`{"title":"x"}`)}); err == nil {
+		t.Error("Expected an error without a session")
+	}
+}
+
+func TestConvertTimestamp(t *testing.T) {
+	s := NewServer(":0", nil)
+	data := json.RawMessage(`{"sessionId":"ses_1","title":"Old"}`)
+
+	event, err := s.convertToSSEEvent(WebhookEvent{Type: "session.deleted", Data: data})
+	if err != nil {
+		t.Fatalf("convertToSSEEvent() error = %v", err)
+	}
+	if !event.Timestamp.IsZero() {
+		t.Errorf("Expected no timestamp without one in the payload, got %v", event.Timestamp)
+	}
+
+	event, err = s.convertToSSEEvent(WebhookEvent{Type: "session.deleted", Timestamp: 1700000000123, Data: data})
+	if err != nil {
+		t.Fatalf("convertToSSEEvent() error = %v", err)
+	}
+	if got := event.Timestamp.UnixMilli(); got != 1700000000123 {
+		t.Errorf("Expected timestamp 1700000000123, got %d", got)
+	}
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// NewServerTLSConfig serves the certificate in certFile/keyFile (PEM). The
+// files are read again when certFile changes, so renewed certificates are
+// picked up without a restart.
+func NewServerTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("certificate and key must be set together")
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}, nil
+}
+
+// SetTLSConfig serves the webhook over HTTPS (nil: plain HTTP)
+func (s *Server) SetTLSConfig(config *tls.Config) {
+	s.tlsConfig = config
+}
+
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (r *certReloader) load() error {
+	info, err := os.Stat(r.certFile)
+	if err != nil {
+		return fmt.Errorf("read certificate: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+	r.cert, r.modTime = &cert, info.ModTime()
+	return nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info, err := os.Stat(r.certFile); err == nil && !info.ModTime().Equal(r.modTime) {
+		// Keep serving the old certificate if the new one is half-written
+		if err := r.load(); err != nil {
+			logger.Error("Failed to reload certificate, keeping the previous one", "file", r.certFile, "error", err)
+		} else {
+			logger.Info("Reloaded certificate", "file", r.certFile)
+		}
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 with the
+// given common name and serial number
+func writeTestCert(t *testing.T, certFile, keyFile, name string, serial int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerTLSConfigReloadsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeTestCert(t, certFile, keyFile, "first", 1)
+
+	config, err := NewServerTLSConfig(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewServerTLSConfig() error = %v", err)
+	}
+	serial := func() int64 {
+		cert, err := config.GetCertificate(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatalf("GetCertificate() error = %v", err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+	if got := serial(); got != 1 {
+		t.Fatalf("Expected certificate 1, got %d", got)
+	}
+
+	// A renewal replaces the files
+	writeTestCert(t, certFile, keyFile, "second", 2)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	if got := serial(); got != 2 {
+		t.Errorf("Expected the renewed certificate 2, got %d", got)
+	}
+
+	// A broken file keeps the previous certificate
+	os.WriteFile(certFile, []byte("garbage"), 0o600)
+	os.Chtimes(certFile, later.Add(time.Minute), later.Add(time.Minute))
+	if got := serial(); got != 2 {
+		t.Errorf("Expected certificate 2 to stay in use, got %d", got)
+	}
+}
+
+func TestNewServerTLSConfigErrors(t *testing.T) {
+	if _, err := NewServerTLSConfig("cert.pem", ""); err == nil {
+		t.Error("Expected an error for a certificate without key")
+	}
+	if _, err := NewServerTLSConfig(filepath.Join(t.TempDir(), "missing.pem"), "key.pem"); err == nil {
+		t.Error("Expected an error for a missing certificate")
+	}
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+const (
+	// DefaultWorkers is how many events are published concurrently
+	DefaultWorkers = 4
+
+	// workerQueueSize is how many events wait per worker before the
+	// webhook answers 503
+	workerQueueSize = 256
+)
+
+var (
+	errNoWorkers = errors.New("no workers running")
+	errQueueFull = errors.New("event queue full")
+)
+
+// SetWorkers sets how many workers publish webhook events (0: publish on
+// the request goroutine). Call before Start.
+func (s *Server) SetWorkers(n int) {
+	s.workers = n
+}
+
+// startWorkers starts the publishing workers until ctx is done. Events of
+// one session always go to the same worker, so they stay in order.
+func (s *Server) startWorkers(ctx context.Context) {
+	if s.workers <= 0 {
+		return
+	}
+	queues := make([]chan opencode.Event, s.workers)
+	for i := range queues {
+		queue := make(chan opencode.Event, workerQueueSize)
+		queues[i] = queue
+		s.workersWG.Add(1)
+		go func() {
+			defer s.workersWG.Done()
+			for {
+				select {
+				case event, ok := <-queue:
+					if !ok {
+						return
+					}
+					s.publisher.Publish(event)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	s.queuesMu.Lock()
+	s.queues = queues
+	s.queuesMu.Unlock()
+}
+
+// enqueue hands event to its session's worker without waiting
+func (s *Server) enqueue(event opencode.Event) error {
+	s.queuesMu.RLock()
+	queues := s.queues
+	s.queuesMu.RUnlock()
+	if len(queues) == 0 {
+		return errNoWorkers
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(event.SessionID()))
+	select {
+	case queues[hash.Sum32()%uint32(len(queues))] <- event:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// stopWorkers lets the workers publish what is queued and waits for them.
+// No request may be enqueuing any more.
+func (s *Server) stopWorkers(ctx context.Context) error {
+	s.queuesMu.Lock()
+	queues := s.queues
+	s.queues = nil
+	s.queuesMu.Unlock()
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.workersWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook workers still publishing: %w", ctx.Err())
+	}
+}
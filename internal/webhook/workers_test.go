@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/opencode-telegram/internal/opencode"
+)
+
+func TestWorkersKeepSessionOrder(t *testing.T) {
+	var mu sync.Mutex
+	got := make(map[string][]string)
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) {
+		evt := event.Properties.(*opencode.EventMessagePartUpdated)
+		mu.Lock()
+		defer mu.Unlock()
+		got[event.SessionID()] = append(got[event.SessionID()], *evt.Properties.Delta)
+	}))
+	s.SetWorkers(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.startWorkers(ctx)
+
+	for i := 0; i < 20; i++ {
+		for _, session := range []string{"ses_a", "ses_b"} {
+			body := fmt.Sprintf(`{"type":"message.part.updated","data":{"sessionId":%q,"delta":"%d"}}`, session, i)
+			rec := httptest.NewRecorder()
+			s.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("Expected 202, got %d", rec.Code)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		done := len(got["ses_a"]) == 20 && len(got["ses_b"]) == 20
+		mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for the workers")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for session, deltas := range got {
+		for i, delta := range deltas {
+			if delta != fmt.Sprint(i) {
+				t.Fatalf("Session %s out of order: %v", session, deltas)
+			}
+		}
+	}
+}
+
+func TestWorkersRefuseWhenFull(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	taken := make(chan struct{}, 1)
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) {
+		taken <- struct{}{}
+		<-block
+	}))
+	s.SetWorkers(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.startWorkers(ctx)
+
+	body := `{"type":"session.idle","data":{"sessionId":"ses_a"}}`
+	var codes []int
+	for i := 0; i < workerQueueSize+2; i++ {
+		if i == 1 {
+			// The worker holds the first event, the queue takes the rest
+			<-taken
+		}
+		rec := httptest.NewRecorder()
+		s.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+		codes = append(codes, rec.Code)
+	}
+	if last := codes[len(codes)-1]; last != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once the queue is full, got %d", last)
+	}
+}
+
+func TestDrainPublishesQueuedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var published int
+	s := NewServer(":0", publisherFunc(func(event opencode.Event) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		published++
+	}))
+	s.SetWorkers(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.startWorkers(ctx)
+
+	for i := 0; i < 30; i++ {
+		body := fmt.Sprintf(`{"type":"session.idle","data":{"sessionId":"ses_%d"}}`, i)
+		rec := httptest.NewRecorder()
+		s.handleWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d", rec.Code)
+		}
+	}
+
+	drainCtx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stop()
+	if err := s.Drain(drainCtx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if published != 30 {
+		t.Errorf("Expected 30 events published before Drain returned, got %d", published)
+	}
+}
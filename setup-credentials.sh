@@ -6,10 +6,35 @@ echo " OpenCode Telegram Bridge - 憑證設定"
 echo "=========================================="
 echo ""
 
+# 取得當前使用者
+USERNAME=$(whoami)
+PROJECT_DIR="/Users/$USERNAME/opencode-telegram"
+CONFIG_DIR="/Users/$USERNAME/.config/opencode"
+CREDENTIALS=~/.opencode-telegram-credentials
+
 # 檢查是否已有憑證
-if [ -f ~/.opencode-telegram-credentials ]; then
+if [ -f "$CREDENTIALS" ]; then
     echo "發現現有憑證檔案，載入中..."
-    source ~/.opencode-telegram-credentials
+    # 以 opencode-telegram encrypt 加密的檔案不能直接 source，需由 bridge 解密
+    if [ "$(head -c 12 "$CREDENTIALS")" = "OCTG-AESGCM1" ]; then
+        if [ -z "$STATE_ENCRYPTION_KEY" ] && [ -z "$STATE_ENCRYPTION_KEY_FILE" ]; then
+            echo "錯誤: 憑證檔案已加密，請設定 STATE_ENCRYPTION_KEY 或 STATE_ENCRYPTION_KEY_FILE 後再執行"
+            echo "      或刪除 $CREDENTIALS 重新設定"
+            exit 1
+        fi
+        if [ ! -x "$PROJECT_DIR/opencode-telegram" ]; then
+            echo "錯誤: 憑證檔案已加密，需要 $PROJECT_DIR/opencode-telegram 解密，請先編譯"
+            exit 1
+        fi
+        if ! DECRYPTED=$("$PROJECT_DIR/opencode-telegram" decrypt "$CREDENTIALS"); then
+            echo "錯誤: 無法解密 $CREDENTIALS，請確認金鑰是否正確"
+            exit 1
+        fi
+        eval "$DECRYPTED"
+        ENCRYPTED=true
+    else
+        source "$CREDENTIALS"
+    fi
     echo "目前設定："
     echo "  Bot Token: ${TELEGRAM_BOT_TOKEN:0:10}..."
     echo "  Chat ID: $TELEGRAM_CHAT_ID"
@@ -17,7 +42,7 @@ if [ -f ~/.opencode-telegram-credentials ]; then
     read -p "要使用現有憑證嗎？(y/n) " -n 1 -r
     echo ""
     if [[ ! $REPLY =~ ^[Yy]$ ]]; then
-        rm ~/.opencode-telegram-credentials
+        rm "$CREDENTIALS"
     else
         USE_EXISTING=true
     fi
@@ -61,17 +86,20 @@ if [ -z "$USE_EXISTING" ]; then
     fi
 
     # 儲存憑證
-    cat > ~/.opencode-telegram-credentials << EOF
+    cat > "$CREDENTIALS" << EOF
 export TELEGRAM_BOT_TOKEN="$BOT_TOKEN"
 export TELEGRAM_CHAT_ID="$CHAT_ID"
 EOF
-    chmod 600 ~/.opencode-telegram-credentials
+    chmod 600 "$CREDENTIALS"
 
     TELEGRAM_BOT_TOKEN="$BOT_TOKEN"
     TELEGRAM_CHAT_ID="$CHAT_ID"
 
     echo ""
-    echo "✅ 憑證已儲存到 ~/.opencode-telegram-credentials"
+    echo "✅ 憑證已儲存到 $CREDENTIALS"
+    if [ -n "$ENCRYPTED" ]; then
+        echo "   新檔案為明文，可執行 opencode-telegram encrypt 重新加密"
+    fi
 fi
 
 echo ""
@@ -80,11 +108,6 @@ echo " 設定 LaunchAgent"
 echo "=========================================="
 echo ""
 
-# 取得當前使用者
-USERNAME=$(whoami)
-PROJECT_DIR="/Users/$USERNAME/opencode-telegram"
-CONFIG_DIR="/Users/$USERNAME/.config/opencode"
-
 # 更新 plist 檔案
 PLIST_PATH="$PROJECT_DIR/configs/com.opencode.telegram-bridge.plist"
 